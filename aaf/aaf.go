@@ -0,0 +1,326 @@
+// Package aaf reads and writes the Astrological Exchange Format (AAF), the
+// plain-text format in which Astroplan and other astrology programs
+// exchange birth data. A chart is a pair of lines:
+//
+//	#A93:Einstein,Albert,m,14.3.1879g,11:30,Ulm,D
+//	#B93:2407422.93750,48n24,10e00,1hE00,0
+//
+// The #A93 line holds the surname, first name, sex, local date (with g or
+// j for the Gregorian or Julian calendar), local time, place and country;
+// the #B93 line the Julian Day (UT), latitude, longitude, standard time
+// zone and hours of daylight saving time. An unknown value is written *.
+// Other lines, such as #: comments, are ignored.
+package aaf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Record is one chart of an AAF file.
+type Record struct {
+	Name      string // the surname, or the whole name of an event
+	FirstName string
+	Sex       string    // "m", "f", or empty if unknown
+	Time      time.Time // the moment, in UTC
+	// Zone is the standard time zone, east of Greenwich positive, and DST
+	// the daylight saving time in force, usually 0 or 1 hour. Together
+	// they give the local time of the #A93 line.
+	Zone, DST time.Duration
+	Place     string
+	Country   string // as the file gives it, often a code such as D or USA
+	Lat, Lon  float64
+}
+
+// Local returns the moment on the local clock of the record.
+func (r Record) Local() time.Time {
+	return r.Time.In(time.FixedZone("", int((r.Zone + r.DST).Seconds())))
+}
+
+// FullName returns the first name and surname joined, e.g. "Albert
+// Einstein".
+func (r Record) FullName() string {
+	return strings.TrimSpace(r.FirstName + " " + r.Name)
+}
+
+// Error reports a malformed line of an AAF file.
+type Error struct {
+	Line   int // 1-based
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("AAF line %d: %s", e.Line, e.Reason)
+}
+
+// Read reads the charts of an AAF file. Each #A93 line must be followed by
+// its #B93 line. The moment is taken from the local date, time and zone
+// where all are known, and otherwise from the Julian Day.
+func Read(r io.Reader) ([]Record, error) {
+	sc := bufio.NewScanner(r)
+	var recs []Record
+	var a []string // the fields of a pending #A93 line
+	aLine, n := 0, 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		tag, rest, _ := strings.Cut(line, ":")
+		switch strings.ToUpper(tag) {
+		case "#A93":
+			if a != nil {
+				return nil, &Error{aLine, "#A93 line without a #B93 line"}
+			}
+			if a = fields(rest); len(a) < 7 {
+				return nil, &Error{n, fmt.Sprintf("#A93 line has %d fields, expected 7", len(a))}
+			}
+			aLine = n
+		case "#B93":
+			if a == nil {
+				return nil, &Error{n, "#B93 line without a #A93 line"}
+			}
+			b := fields(rest)
+			if len(b) < 5 {
+				return nil, &Error{n, fmt.Sprintf("#B93 line has %d fields, expected 5", len(b))}
+			}
+			rec, err := parse(a, b)
+			if err != nil {
+				return nil, &Error{n, err.Error()}
+			}
+			recs = append(recs, rec)
+			a = nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if a != nil {
+		return nil, &Error{aLine, "#A93 line without a #B93 line"}
+	}
+	return recs, nil
+}
+
+// fields splits the values of a line, with * for an unknown value made
+// empty.
+func fields(s string) []string {
+	f := strings.Split(s, ",")
+	for i := range f {
+		if f[i] = strings.TrimSpace(f[i]); f[i] == "*" {
+			f[i] = ""
+		}
+	}
+	return f
+}
+
+// parse builds a record from the fields of its #A93 and #B93 lines.
+func parse(a, b []string) (Record, error) {
+	rec := Record{Name: a[0], FirstName: a[1], Place: a[5], Country: a[6]}
+	switch s := strings.ToLower(a[2]); s {
+	case "m", "f":
+		rec.Sex = s
+	}
+	var err error
+	if rec.Lat, err = parseCoord(b[1], "n", "s", 90); err != nil {
+		return Record{}, fmt.Errorf("invalid latitude %q: %v", b[1], err)
+	}
+	if rec.Lon, err = parseCoord(b[2], "e", "w", 180); err != nil {
+		return Record{}, fmt.Errorf("invalid longitude %q: %v", b[2], err)
+	}
+	zoneKnown := b[3] != ""
+	if zoneKnown {
+		if rec.Zone, err = parseZone(b[3]); err != nil {
+			return Record{}, fmt.Errorf("invalid time zone %q: %v", b[3], err)
+		}
+	}
+	if b[4] != "" {
+		h, err := strconv.ParseFloat(b[4], 64)
+		if err != nil || math.Abs(h) > 3 {
+			return Record{}, fmt.Errorf("invalid daylight saving time %q: expected hours, e.g. 0 or 1", b[4])
+		}
+		rec.DST = time.Duration(h * float64(time.Hour))
+	}
+
+	if a[3] != "" && a[4] != "" && zoneKnown {
+		local, err := parseLocal(a[3], a[4])
+		if err != nil {
+			return Record{}, err
+		}
+		rec.Time = local.Add(-rec.Zone - rec.DST)
+		return rec, nil
+	}
+	if b[0] == "" {
+		return Record{}, fmt.Errorf("neither a Julian Day nor a local date, time and zone")
+	}
+	jd, err := strconv.ParseFloat(b[0], 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid Julian Day %q", b[0])
+	}
+	// The Julian Day is given to about a second; round to it.
+	rec.Time = ephemeris.TimeOf(jd).Round(time.Second)
+	if !zoneKnown {
+		// Without a zone, report the local time as UT.
+		rec.DST = 0
+	}
+	return rec, nil
+}
+
+// parseLocal parses a local date such as 14.3.1879g and time such as
+// 11:30, returned as a time whose clock reads them in UTC. A date marked
+// j is in the Julian calendar; unmarked, it is Gregorian.
+func parseLocal(date, clock string) (time.Time, error) {
+	julian := false
+	switch {
+	case strings.HasSuffix(strings.ToLower(date), "j"):
+		julian = true
+		fallthrough
+	case strings.HasSuffix(strings.ToLower(date), "g"):
+		date = date[:len(date)-1]
+	}
+	d := strings.Split(date, ".")
+	if len(d) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected day.month.year, e.g. 14.3.1879g", date)
+	}
+	day, err1 := strconv.Atoi(d[0])
+	month, err2 := strconv.Atoi(d[1])
+	year, err3 := strconv.Atoi(d[2])
+	if err1 != nil || err2 != nil || err3 != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected day.month.year, e.g. 14.3.1879g", date)
+	}
+	c := strings.Split(clock, ":")
+	hms := make([]float64, 3)
+	for i, s := range c {
+		v, err := strconv.ParseFloat(s, 64)
+		if i >= 3 || err != nil || v < 0 || v >= []float64{24, 60, 60}[i] {
+			return time.Time{}, fmt.Errorf("invalid time %q: expected hours:minutes, e.g. 11:30", clock)
+		}
+		hms[i] = v
+	}
+	if len(c) < 2 {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected hours:minutes, e.g. 11:30", clock)
+	}
+	if julian {
+		g := ephemeris.TimeOf(julianCalendarDay(year, month, day))
+		year, month, day = g.Year(), int(g.Month()), g.Day()
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	secs := hms[0]*3600 + hms[1]*60 + hms[2]
+	return t.Add(time.Duration(math.Round(secs * float64(time.Second)))), nil
+}
+
+// julianCalendarDay returns the Julian Day at 0h UT of a date in the
+// Julian calendar (Meeus, Astronomical Algorithms, ch. 7).
+func julianCalendarDay(year, month, day int) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + float64(day) - 1524.5
+}
+
+var (
+	coordRE = regexp.MustCompile(`^(\d{1,3})([nsew])(\d{1,2})(?:[:'](\d{1,2}))?$`)
+	zoneRE  = regexp.MustCompile(`^(\d{1,2})h([ew])(\d{1,2})$`)
+)
+
+// parseCoord parses a coordinate such as 48n24 or 0w07:39: degrees, the
+// hemisphere, minutes and optionally seconds.
+func parseCoord(s, pos, neg string, limit float64) (float64, error) {
+	m := coordRE.FindStringSubmatch(strings.ToLower(s))
+	if m == nil || (m[2] != pos && m[2] != neg) {
+		return 0, fmt.Errorf("expected degrees, %s or %s, and minutes, e.g. 48%s24", pos, neg, pos)
+	}
+	deg, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[3])
+	secs, _ := strconv.Atoi(m[4]) // 0 if absent
+	v := float64(deg) + float64(mins)/60 + float64(secs)/3600
+	if mins >= 60 || secs >= 60 || v > limit {
+		return 0, fmt.Errorf("out of range")
+	}
+	if m[2] == neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// parseZone parses a time zone such as 1hE00 (an hour east of Greenwich)
+// or 5hW00.
+func parseZone(s string) (time.Duration, error) {
+	m := zoneRE.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, fmt.Errorf("expected hours, E or W, and minutes, e.g. 1hE00")
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[3])
+	if mins >= 60 {
+		return 0, fmt.Errorf("out of range")
+	}
+	z := time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute
+	if m[2] == "w" {
+		z = -z
+	}
+	return z, nil
+}
+
+// Write writes the charts to w in AAF. Dates are written in the Gregorian
+// calendar, and commas, which cannot be escaped, in a value are replaced
+// by spaces.
+func Write(w io.Writer, recs []Record) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range recs {
+		local := r.Local()
+		clock := local.Format("15:04")
+		if local.Second() != 0 {
+			clock = local.Format("15:04:05")
+		}
+		fmt.Fprintf(bw, "#A93:%s,%s,%s,%d.%d.%dg,%s,%s,%s\n",
+			value(r.Name), value(r.FirstName), value(r.Sex),
+			local.Day(), local.Month(), local.Year(), clock,
+			value(r.Place), value(r.Country))
+		fmt.Fprintf(bw, "#B93:%.5f,%s,%s,%s,%s\n",
+			ephemeris.JulianDay(r.Time), formatCoord(r.Lat, "n", "s"), formatCoord(r.Lon, "e", "w"),
+			formatZone(r.Zone), strconv.FormatFloat(r.DST.Hours(), 'f', -1, 64))
+	}
+	return bw.Flush()
+}
+
+// value returns s as an AAF value: * if empty, and without commas.
+func value(s string) string {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, ",", " ")), " ")
+	if s == "" {
+		return "*"
+	}
+	return s
+}
+
+// formatCoord formats a coordinate as degrees, hemisphere and minutes,
+// with seconds where they are not zero, e.g. 48n24 or 0w07:39.
+func formatCoord(v float64, pos, neg string) string {
+	h := pos
+	if v < 0 {
+		h, v = neg, -v
+	}
+	secs := int(math.Round(v * 3600))
+	s := fmt.Sprintf("%d%s%02d", secs/3600, h, secs/60%60)
+	if secs%60 != 0 {
+		s += fmt.Sprintf(":%02d", secs%60)
+	}
+	return s
+}
+
+// formatZone formats a time zone as hours, E or W, and minutes, e.g.
+// 1hE00.
+func formatZone(z time.Duration) string {
+	h := "E"
+	if z < 0 {
+		h, z = "W", -z
+	}
+	mins := int(z.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%dh%s%02d", mins/60, h, mins%60)
+}
@@ -0,0 +1,119 @@
+package aaf_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/aaf"
+)
+
+const sample = `#: charts exported for a test
+#A93:Einstein,Albert,m,14.3.1879g,11:30,Ulm,D
+#B93:2407422.93750,48n24,10e00,1hE00,0
+#COM:no zone, so the Julian Day gives the moment
+#A93:*,New York,*,*,*,New York,USA
+#B93:2451545.00000,40n42:51,74w00:21,*,*
+#A93:Kepler,Johannes,m,27.12.1571j,14:30,Weil der Stadt,D
+#B93:*,48n45,8e52,0hE36,0
+`
+
+func TestRead(t *testing.T) {
+	recs, err := aaf.Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("read %d charts, want 3", len(recs))
+	}
+
+	e := recs[0]
+	if e.FullName() != "Albert Einstein" || e.Sex != "m" || e.Place != "Ulm" || e.Country != "D" {
+		t.Errorf("Einstein = %+v", e)
+	}
+	if want := time.Date(1879, 3, 14, 10, 30, 0, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Einstein at %s, want %s", e.Time, want)
+	}
+	if e.Zone != time.Hour || e.Local().Format("15:04") != "11:30" {
+		t.Errorf("Einstein zone %s, local %s; want 1h, 11:30", e.Zone, e.Local())
+	}
+	if math.Abs(e.Lat-48.4) > 1e-9 || e.Lon != 10 {
+		t.Errorf("Einstein at %g, %g; want 48.4, 10", e.Lat, e.Lon)
+	}
+
+	ny := recs[1]
+	if ny.FullName() != "New York" || ny.Sex != "" {
+		t.Errorf("New York = %+v", ny)
+	}
+	if want := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC); !ny.Time.Equal(want) {
+		t.Errorf("New York at %s, want %s from the Julian Day", ny.Time, want)
+	}
+	if math.Abs(ny.Lat-(40+42.0/60+51.0/3600)) > 1e-9 || math.Abs(ny.Lon+(74+21.0/3600)) > 1e-9 {
+		t.Errorf("New York at %g, %g", ny.Lat, ny.Lon)
+	}
+
+	// 27 December 1571 in the Julian calendar is 6 January 1572 in the
+	// Gregorian.
+	if want := time.Date(1572, 1, 6, 13, 54, 0, 0, time.UTC); !recs[2].Time.Equal(want) {
+		t.Errorf("Kepler at %s, want %s", recs[2].Time, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"#A93:a,b,m,1.1.2000g,12:00,x,y\n", "line 1: #A93 line without a #B93 line"},
+		{"#B93:2451545,0n0,0e0,0hE00,0\n", "line 1: #B93 line without a #A93 line"},
+		{"#A93:a,b,m\n", "line 1: #A93 line has 3 fields"},
+		{"#A93:a,b,m,1.1.2000g,12:00,x,y\n#B93:*,91n00,0e0,0hE00,0\n", `line 2: invalid latitude "91n00"`},
+		{"#A93:a,b,m,1.1.2000g,12:00,x,y\n#B93:*,0n0,0x0,0hE00,0\n", `line 2: invalid longitude "0x0"`},
+		{"#A93:a,b,m,1.13.2000g,12:00,x,y\n#B93:*,0n0,0e0,0hE00,0\n", `line 2: invalid date "1.13.2000"`},
+		{"#A93:a,b,m,*,*,x,y\n#B93:*,0n0,0e0,0hE00,0\n", "line 2: neither a Julian Day"},
+	} {
+		_, err := aaf.Read(strings.NewReader(tc.in))
+		var e *aaf.Error
+		if !errors.As(err, &e) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Read(%q) = %v, want an *aaf.Error containing %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	recs := []aaf.Record{{
+		Name: "Doe", FirstName: "Jane", Sex: "f",
+		Time: time.Date(1990, 7, 1, 12, 15, 30, 0, time.UTC),
+		Zone: -5 * time.Hour, DST: time.Hour,
+		Place: "New York, NY", Country: "USA",
+		Lat: 40.7128, Lon: -74.006,
+	}, {
+		Name: "Event", Time: time.Date(2024, 3, 20, 3, 6, 0, 0, time.UTC),
+	}}
+	var buf bytes.Buffer
+	if err := aaf.Write(&buf, recs); err != nil {
+		t.Fatal(err)
+	}
+	want := `#A93:Doe,Jane,f,1.7.1990g,08:15:30,New York NY,USA
+#B93:2448074.01076,40n42:46,74w00:22,5hW00,1
+#A93:Event,*,*,20.3.2024g,03:06,*,*
+#B93:2460389.62917,0n00,0e00,0hE00,0
+`
+	if buf.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	got, err := aaf.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range recs {
+		if !got[i].Time.Equal(recs[i].Time) || got[i].Zone != recs[i].Zone || got[i].DST != recs[i].DST {
+			t.Errorf("chart %d read back at %s (zone %s, DST %s), want %s (%s, %s)",
+				i, got[i].Time, got[i].Zone, got[i].DST, recs[i].Time, recs[i].Zone, recs[i].DST)
+		}
+		if math.Abs(got[i].Lat-recs[i].Lat) > 1.0/3600 || math.Abs(got[i].Lon-recs[i].Lon) > 1.0/3600 {
+			t.Errorf("chart %d read back at %g, %g; want %g, %g to the second", i, got[i].Lat, got[i].Lon, recs[i].Lat, recs[i].Lon)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package almuten finds the almuten figuris, or victor of the chart: the
+// planet with the most essential dignity summed over the hylegical points
+// (the Sun, the Moon, the Ascendant, the Part of Fortune and the prenatal
+// syzygy).
+package almuten
+
+import (
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/cycles"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Point is a longitude whose dignities are counted.
+type Point struct {
+	Name      string
+	Longitude float64
+}
+
+// Row is one planet's dignity at each point, in the order of the points.
+type Row struct {
+	Body   int
+	Scores []int
+	Total  int
+}
+
+// Table is the dignity of the classical planets over a set of points.
+type Table struct {
+	Points  []Point
+	Rows    []Row // one per planet of dignity.Classical, in that order
+	Winners []int // the planets with the highest total, several if they tie
+	Best    int   // their total
+}
+
+// Figuris sums the essential dignity scores of the classical planets over
+// points in a day or night chart.
+func Figuris(points []Point, day bool) Table {
+	t := Table{Points: points}
+	for _, body := range dignity.Classical {
+		row := Row{Body: body, Scores: make([]int, len(points))}
+		for i, pt := range points {
+			row.Scores[i] = dignity.Score(body, pt.Longitude, day)
+			row.Total += row.Scores[i]
+		}
+		t.Rows = append(t.Rows, row)
+		switch {
+		case row.Total > t.Best:
+			t.Winners, t.Best = []int{body}, row.Total
+		case row.Total == t.Best && row.Total > 0:
+			t.Winners = append(t.Winners, body)
+		}
+	}
+	return t
+}
+
+// Fortune returns the longitude of the Part of Fortune: the Ascendant plus
+// the distance from the Sun to the Moon by day, or from the Moon to the
+// Sun by night.
+func Fortune(asc, sun, moon float64, day bool) float64 {
+	if !day {
+		sun, moon = moon, sun
+	}
+	return angle.Norm360(asc + moon - sun)
+}
+
+// Syzygy is the new or full Moon before a birth.
+type Syzygy struct {
+	JD        float64
+	Full      bool
+	Longitude float64 // of the Moon
+}
+
+// PrenatalSyzygy finds the last new or full Moon at or before jd. Its
+// degree is taken as the Moon's, for a full Moon as well as a new one.
+func PrenatalSyzygy(p ephemeris.Provider, jd float64) (Syzygy, error) {
+	// A syzygy falls every half synodic month, about 14.8 days.
+	events, err := cycles.Scan(p, ephemeris.Sun, ephemeris.Moon, jd-16, jd, 0.5)
+	if err != nil {
+		return Syzygy{}, err
+	}
+	var s Syzygy
+	for _, e := range events {
+		if e.Phase.Angle == 0 || e.Phase.Angle == 180 {
+			// The Sun has the lower body ID, so it is the "fast" planet.
+			s = Syzygy{JD: e.JD, Full: e.Phase.Angle == 180, Longitude: e.SlowLon}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,58 @@
+package almuten_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/almuten"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+func TestFiguris(t *testing.T) {
+	// Day chart. Saturn rules both signs; Mars is exalted in Capricorn and
+	// holds the term of its last degrees.
+	points := []almuten.Point{
+		{"Sun", 297},  // 27° Capricorn: Saturn 5, Mars 4+2, Venus 3, Sun 1
+		{"Moon", 327}, // 27° Aquarius: Saturn 5+3+2, Moon 1
+	}
+	tab := almuten.Figuris(points, true)
+	if len(tab.Winners) != 1 || tab.Winners[0] != ephemeris.Saturn {
+		t.Fatalf("winners %v, want Saturn", tab.Winners)
+	}
+	for _, r := range tab.Rows {
+		if r.Body == ephemeris.Saturn && (r.Scores[0] != 5 || r.Scores[1] != 10 || r.Total != 15 || tab.Best != 15) {
+			t.Errorf("Saturn row %+v, best %d; want 5, 10, total 15", r, tab.Best)
+		}
+	}
+	if len(tab.Rows) != 7 {
+		t.Errorf("got %d rows, want 7", len(tab.Rows))
+	}
+}
+
+func TestFortune(t *testing.T) {
+	if got := almuten.Fortune(100, 10, 40, true); got != 130 {
+		t.Errorf("day Fortune = %v, want 130", got)
+	}
+	if got := almuten.Fortune(100, 10, 40, false); got != 70 {
+		t.Errorf("night Fortune = %v, want 70", got)
+	}
+	if got := almuten.Fortune(350, 40, 10, true); got != 320 {
+		t.Errorf("Fortune wrapping = %v, want 320", got)
+	}
+}
+
+func TestPrenatalSyzygy(t *testing.T) {
+	// The Moon 10° past opposition to the Sun, gaining 12°/day: full Moon
+	// 10/12 of a day ago.
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:  {Longitude: 0, SpeedLon: 1},
+		ephemeris.Moon: {Longitude: 190, SpeedLon: 13},
+	}}
+	s, err := almuten.PrenatalSyzygy(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Full || math.Abs(s.JD+10.0/12) > 1e-4 || math.Abs(s.Longitude-(190-13*10.0/12)) > 1e-3 {
+		t.Errorf("syzygy %+v, want full Moon at JD %v", s, -10.0/12)
+	}
+}
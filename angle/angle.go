@@ -0,0 +1,103 @@
+// Package angle holds the arithmetic of angles on the circle, in degrees,
+// that the rest of the module shares: normalising a longitude, the signed
+// difference between two, their midpoint, whether an aspect is applying,
+// and writing and reading degrees, minutes and seconds.
+package angle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Norm360 returns x normalised to [0, 360).
+func Norm360(x float64) float64 {
+	x = math.Mod(x, 360)
+	if x < 0 {
+		x += 360
+		// A tiny negative x rounds up to 360 itself.
+		if x >= 360 {
+			x = 0
+		}
+	}
+	return x
+}
+
+// Diff returns a - b wrapped to [-180, 180): how far a lies ahead of b the
+// short way round, negative if it lies behind.
+func Diff(a, b float64) float64 {
+	return Norm360(a-b+180) - 180
+}
+
+// Midpoint returns the midpoint of longitudes a and b on the shorter arc
+// between them, in [0, 360). For points exactly opposite, it is the one
+// 90° ahead of a.
+func Midpoint(a, b float64) float64 {
+	d := Diff(b, a)
+	if d == -180 {
+		d = 180
+	}
+	return Norm360(a + d/2)
+}
+
+// IsApplying reports whether a body offset degrees from an exact point, as
+// Diff gives the body's longitude less the point's, and moving at speed
+// degrees a day relative to it, is closing on the point: the orb is
+// shrinking. A body on the point, or stationary, is not applying.
+func IsApplying(offset, speed float64) bool {
+	return offset*speed < 0
+}
+
+// FormatDMS formats an angle as degrees, minutes and seconds, rounded to
+// the second, e.g. 24°29′48″ or -0°30′00″.
+func FormatDMS(x float64) string {
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	s := int(math.Round(x * 3600))
+	return fmt.Sprintf("%s%d°%02d′%02d″", sign, s/3600, s/60%60, s%60)
+}
+
+// ErrRange is the error ParseDMS wraps when the minutes or seconds are 60
+// or more.
+var ErrRange = errors.New("minutes and seconds must be under 60")
+
+// ParseDMS parses an angle written as degrees, minutes and seconds, the
+// minutes and seconds optional: as FormatDMS writes it, with ASCII marks
+// (24°29'48"), with colons (24:29:48) or with spaces (24 29 48). Only the
+// last part may have a fraction, and minutes and seconds must be under 60.
+// A leading minus sign negates the whole angle.
+func ParseDMS(s string) (float64, error) {
+	syntax := fmt.Errorf("angle: %q is not degrees, minutes and seconds", s)
+	t := strings.TrimSpace(s)
+	sign := 1.0
+	if rest, ok := strings.CutPrefix(t, "-"); ok {
+		t, sign = rest, -1
+	} else {
+		t = strings.TrimPrefix(t, "+")
+	}
+	parts := strings.FieldsFunc(t, func(r rune) bool {
+		return strings.ContainsRune("°′″'\": \t", r)
+	})
+	if len(parts) == 0 || len(parts) > 3 {
+		return 0, syntax
+	}
+	v := 0.0
+	for i, p := range parts {
+		if strings.Trim(p, "0123456789.") != "" || (i < len(parts)-1 && strings.Contains(p, ".")) {
+			return 0, syntax
+		}
+		x, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, syntax
+		}
+		if i > 0 && x >= 60 {
+			return 0, fmt.Errorf("angle: %q has %g %s: %w", s, x, [3]string{"", "minutes", "seconds"}[i], ErrRange)
+		}
+		v += x / math.Pow(60, float64(i))
+	}
+	return sign * v, nil
+}
@@ -0,0 +1,96 @@
+package angle_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+func TestNorm360(t *testing.T) {
+	for _, c := range []struct{ x, want float64 }{
+		{0, 0}, {359.5, 359.5}, {360, 0}, {725, 5}, {-10, 350}, {-720, 0},
+		{-1e-14, 0}, {-1e-300, 0}, {-math.SmallestNonzeroFloat64, 0}, {-360.00000000000001, 0},
+	} {
+		if got := angle.Norm360(c.x); got != c.want {
+			t.Errorf("Norm360(%v) = %v, want %v", c.x, got, c.want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, c := range []struct{ a, b, want float64 }{
+		{20, 10, 10}, {10, 20, -10}, {5, 355, 10}, {355, 5, -10}, {190, 10, -180}, {10, 190, -180},
+	} {
+		if got := angle.Diff(c.a, c.b); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Diff(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	for _, c := range []struct{ a, b, want float64 }{
+		{10, 20, 15}, {20, 10, 15}, {350, 10, 0}, {340, 20, 0}, {0, 180, 90}, {180, 0, 270}, {100, 300, 20},
+	} {
+		if got := angle.Midpoint(c.a, c.b); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Midpoint(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestIsApplying(t *testing.T) {
+	for _, c := range []struct {
+		offset, speed float64
+		want          bool
+	}{
+		{-2, 1, true},   // behind the point and catching up
+		{2, -0.1, true}, // ahead and retrograde
+		{2, 1, false},
+		{-2, -1, false},
+		{0, 1, false},
+		{2, 0, false},
+	} {
+		if got := angle.IsApplying(c.offset, c.speed); got != c.want {
+			t.Errorf("IsApplying(%v, %v) = %v, want %v", c.offset, c.speed, got, c.want)
+		}
+	}
+}
+
+func TestDMS(t *testing.T) {
+	for _, c := range []struct {
+		x    float64
+		want string
+	}{
+		{24.49666667, "24°29′48″"}, {-0.5, "-0°30′00″"}, {359.99999, "360°00′00″"},
+	} {
+		if got := angle.FormatDMS(c.x); got != c.want {
+			t.Errorf("FormatDMS(%v) = %q, want %q", c.x, got, c.want)
+		}
+	}
+	for _, c := range []struct {
+		s    string
+		want float64
+	}{
+		{"24°29′48″", 24 + 29.0/60 + 48.0/3600},
+		{`24°29'48"`, 24 + 29.0/60 + 48.0/3600},
+		{"24:29:48", 24 + 29.0/60 + 48.0/3600},
+		{" 24 30 ", 24.5},
+		{"-0°30′", -0.5},
+		{"+12.25", 12.25},
+		{"10 7.5", 10.125},
+	} {
+		got, err := angle.ParseDMS(c.s)
+		if err != nil || math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("ParseDMS(%q) = %v, %v; want %v", c.s, got, err, c.want)
+		}
+	}
+	for _, s := range []string{"", "°", "24.5 30", "24 60", "1 2 3 4", "N24", "24°x"} {
+		if _, err := angle.ParseDMS(s); err == nil {
+			t.Errorf("ParseDMS(%q): no error", s)
+		}
+	}
+	if _, err := angle.ParseDMS("24 30 60"); !errors.Is(err, angle.ErrRange) {
+		t.Errorf("ParseDMS(\"24 30 60\") = %v, want ErrRange", err)
+	}
+}
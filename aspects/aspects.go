@@ -0,0 +1,82 @@
+// Package aspects defines the angular relationships between points on the
+// ecliptic that astrologers interpret, with their customary orbs.
+package aspects
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// Aspect is an angle between two ecliptic longitudes, allowed to be
+// inexact by up to Orb degrees either way.
+type Aspect struct {
+	Name   string
+	Abbrev string // three-letter form for grids
+	Angle  float64
+	Orb    float64
+}
+
+// Major are the five Ptolemaic aspects with the orbs commonly used between
+// natal planets.
+var Major = []Aspect{
+	{"conjunction", "Cnj", 0, 8},
+	{"sextile", "Sxt", 60, 6},
+	{"square", "Sqr", 90, 7},
+	{"trine", "Tri", 120, 8},
+	{"opposition", "Opp", 180, 8},
+}
+
+// Quincunx is the minor aspect of 150°, which with a sextile forms a yod.
+// It is not among Major.
+var Quincunx = Aspect{"quincunx", "Qnx", 150, 3}
+
+// Parse parses a comma-separated list of aspect names, or "all", into
+// aspects from Major.
+func Parse(s string) ([]Aspect, error) {
+	if strings.ToLower(strings.TrimSpace(s)) == "all" {
+		return append([]Aspect(nil), Major...), nil
+	}
+	var out []Aspect
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, a := range Major {
+			if a.Name == name {
+				out = append(out, a)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown aspect %q: valid values are all, conjunction, sextile, square, trine, opposition", name)
+		}
+	}
+	return out, nil
+}
+
+// Between returns the closest aspect in as formed by longitudes a and b
+// within its orb, and the orb: the separation's distance from exact, in
+// degrees, always non-negative. ok is false if no aspect is in orb.
+func Between(a, b float64, as []Aspect) (asp Aspect, orb float64, ok bool) {
+	sep := math.Abs(angle.Diff(a, b))
+	best := math.Inf(1)
+	for _, x := range as {
+		if d := math.Abs(sep - x.Angle); d <= x.Orb && d < best {
+			asp, best, ok = x, d, true
+		}
+	}
+	return asp, best, ok
+}
+
+// WithOrb returns a copy of as with every orb set to orb.
+func WithOrb(as []Aspect, orb float64) []Aspect {
+	out := make([]Aspect, len(as))
+	for i, a := range as {
+		a.Orb = orb
+		out[i] = a
+	}
+	return out
+}
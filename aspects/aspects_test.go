@@ -0,0 +1,66 @@
+package aspects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/aspects"
+)
+
+func TestParse(t *testing.T) {
+	all, err := aspects.Parse("all")
+	if err != nil || len(all) != len(aspects.Major) {
+		t.Fatalf("Parse(all) = %v, %v; want all %d major aspects", all, err, len(aspects.Major))
+	}
+
+	got, err := aspects.Parse("Square, trine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Angle != 90 || got[1].Angle != 120 {
+		t.Errorf("Parse(\"Square, trine\") = %+v", got)
+	}
+
+	if _, err := aspects.Parse("quincunx"); err == nil {
+		t.Error("Parse(\"quincunx\"): expected error")
+	}
+}
+
+func TestWithOrb(t *testing.T) {
+	got := aspects.WithOrb(aspects.Major, 1)
+	for _, a := range got {
+		if a.Orb != 1 {
+			t.Errorf("%s orb = %v, want 1", a.Name, a.Orb)
+		}
+	}
+	if aspects.Major[0].Orb == 1 {
+		t.Error("WithOrb modified Major")
+	}
+}
+
+func TestBetween(t *testing.T) {
+	cases := []struct {
+		a, b float64
+		want string // "" for no aspect in orb
+		orb  float64
+	}{
+		{10, 12, "conjunction", 2},
+		{356, 4, "conjunction", 8}, // across 0° Aries, at the edge of orb
+		{350, 5, "", 0},            // 15° apart
+		{0, 95, "square", 5},
+		{200, 80, "trine", 0},
+		{0, 150, "", 0},
+	}
+	for _, tc := range cases {
+		asp, orb, ok := aspects.Between(tc.a, tc.b, aspects.Major)
+		if tc.want == "" {
+			if ok {
+				t.Errorf("Between(%v, %v) = %s, want none", tc.a, tc.b, asp.Name)
+			}
+			continue
+		}
+		if !ok || asp.Name != tc.want || math.Abs(orb-tc.orb) > 1e-9 {
+			t.Errorf("Between(%v, %v) = %s %.4f %v, want %s %.4f", tc.a, tc.b, asp.Name, orb, ok, tc.want, tc.orb)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package astrocartography computes where on Earth each planet was on an
+// angle of the chart at a given moment: the ASC and DSC lines, where it
+// was rising or setting, and the MC and IC lines, where it was culminating
+// or anticulminating. It also finds where two lines cross, which is where
+// both planets were angular at once (a paran).
+package astrocartography
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// Angle is one of the four chart angles a line follows.
+type Angle int
+
+const (
+	ASC Angle = iota // rising
+	DSC              // setting
+	MC               // culminating
+	IC               // anticulminating
+)
+
+func (a Angle) String() string {
+	switch a {
+	case ASC:
+		return "ASC"
+	case DSC:
+		return "DSC"
+	case MC:
+		return "MC"
+	case IC:
+		return "IC"
+	}
+	return fmt.Sprintf("Angle(%d)", int(a))
+}
+
+// Angles lists the four angles in the order lines are returned.
+var Angles = []Angle{ASC, DSC, MC, IC}
+
+// MaxLatitude bounds the lines. Near the poles rising and setting lines
+// sweep through every longitude and stop being useful on a map.
+const MaxLatitude = 85.0
+
+// Planet is a body's equatorial position, in degrees.
+type Planet struct {
+	Body int
+	RA   float64 // right ascension
+	Dec  float64 // declination
+}
+
+// Equatorial converts ecliptic longitude and latitude to right ascension
+// and declination for obliquity eps, all in degrees.
+func Equatorial(lon, lat, eps float64) (ra, dec float64) {
+	l, b, e := rad(lon), rad(lat), rad(eps)
+	ra = deg(math.Atan2(math.Sin(l)*math.Cos(e)-math.Tan(b)*math.Sin(e), math.Cos(l)))
+	dec = deg(math.Asin(math.Sin(b)*math.Cos(e) + math.Cos(b)*math.Sin(e)*math.Sin(l)))
+	return angle.Norm360(ra), dec
+}
+
+// Longitude returns the geographic longitude, in [-180, 180), at which pl
+// is on angle at geographic latitude lat, given the Greenwich sidereal time
+// gst in degrees. ok is false where pl never rises or sets at lat
+// (circumpolar or never above the horizon) and for MC and IC beyond
+// MaxLatitude.
+func Longitude(pl Planet, angle Angle, lat, gst float64) (lon float64, ok bool) {
+	if math.Abs(lat) > MaxLatitude {
+		return 0, false
+	}
+	switch angle {
+	case MC:
+		return lonnorm(pl.RA - gst), true
+	case IC:
+		return lonnorm(pl.RA + 180 - gst), true
+	}
+	// The semi-diurnal arc: cos H0 = -tan φ tan δ.
+	x := -math.Tan(rad(lat)) * math.Tan(rad(pl.Dec))
+	if x < -1 || x > 1 {
+		return 0, false
+	}
+	h0 := deg(math.Acos(x))
+	if angle == ASC {
+		return lonnorm(pl.RA - h0 - gst), true
+	}
+	return lonnorm(pl.RA + h0 - gst), true
+}
+
+// Line is one planet's line for one angle, as [longitude, latitude] points
+// in GeoJSON order. A line is split into segments wherever it crosses the
+// antimeridian or runs out (see Longitude), so each segment can be drawn
+// as is.
+type Line struct {
+	Body     int
+	Angle    Angle
+	Segments [][][2]float64
+}
+
+// Lines returns the four lines of every planet, sampling latitude every
+// step degrees between -MaxLatitude and MaxLatitude.
+func Lines(planets []Planet, gst, step float64) []Line {
+	var lines []Line
+	for _, pl := range planets {
+		for _, a := range Angles {
+			lines = append(lines, Line{Body: pl.Body, Angle: a, Segments: trace(pl, a, gst, step)})
+		}
+	}
+	return lines
+}
+
+// trace samples one line, starting a new segment at each gap or
+// antimeridian crossing.
+func trace(pl Planet, a Angle, gst, step float64) [][][2]float64 {
+	var segs [][][2]float64
+	var cur [][2]float64
+	flush := func() {
+		if len(cur) > 1 {
+			segs = append(segs, cur)
+		}
+		cur = nil
+	}
+	for _, lat := range latitudes(step) {
+		lon, ok := Longitude(pl, a, lat, gst)
+		if !ok {
+			flush()
+			continue
+		}
+		if n := len(cur); n > 0 && math.Abs(lon-cur[n-1][0]) > 180 {
+			flush()
+		}
+		cur = append(cur, [2]float64{lon, lat})
+	}
+	flush()
+	return segs
+}
+
+// latitudes returns the sample latitudes from -MaxLatitude to MaxLatitude
+// inclusive.
+func latitudes(step float64) []float64 {
+	n := int(math.Ceil(2 * MaxLatitude / step))
+	lats := make([]float64, n+1)
+	for i := range lats {
+		lats[i] = math.Min(-MaxLatitude+float64(i)*step, MaxLatitude)
+	}
+	return lats
+}
+
+// Crossing is a place where lines of two planets meet: at that latitude,
+// both planets are on their angles at the same moment of the day, so the
+// pair is a paran along the whole parallel.
+type Crossing struct {
+	A, B           int // bodies
+	AngleA, AngleB Angle
+	Lat, Lon       float64
+}
+
+// crossStep is the latitude sampling interval used to find crossings.
+const crossStep = 0.5
+
+// Crossings returns every crossing between lines of different planets,
+// ordered by latitude from north to south.
+func Crossings(planets []Planet, gst float64) []Crossing {
+	var found []Crossing
+	for i, pa := range planets {
+		for _, pb := range planets[i+1:] {
+			for _, aa := range Angles {
+				for _, ab := range Angles {
+					found = append(found, cross(pa, aa, pb, ab, gst)...)
+				}
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Lat > found[j].Lat })
+	return found
+}
+
+// cross finds the latitudes where the two lines have the same longitude,
+// by sampling their difference and bisecting each change of sign.
+func cross(pa Planet, aa Angle, pb Planet, ab Angle, gst float64) []Crossing {
+	diff := func(lat float64) (float64, bool) {
+		la, okA := Longitude(pa, aa, lat, gst)
+		lb, okB := Longitude(pb, ab, lat, gst)
+		return angle.Diff(la, lb), okA && okB
+	}
+
+	var found []Crossing
+	lats := latitudes(crossStep)
+	d0, ok0 := diff(lats[0])
+	for _, lat1 := range lats[1:] {
+		lat0 := lat1 - crossStep
+		d1, ok1 := diff(lat1)
+		// Differences far from zero can wrap from +180 to -180; only sign
+		// changes near zero are crossings.
+		if ok0 && ok1 && (d0 < 0) != (d1 < 0) && math.Abs(d1-d0) < 180 {
+			lo, hi, dLo := lat0, lat1, d0
+			for hi-lo > 1e-7 {
+				mid := (lo + hi) / 2
+				if d, _ := diff(mid); (d < 0) == (dLo < 0) {
+					lo, dLo = mid, d
+				} else {
+					hi = mid
+				}
+			}
+			lat := (lo + hi) / 2
+			lon, _ := Longitude(pa, aa, lat, gst)
+			found = append(found, Crossing{A: pa.Body, B: pb.Body, AngleA: aa, AngleB: ab, Lat: lat, Lon: lon})
+		}
+		d0, ok0 = d1, ok1
+	}
+	return found
+}
+
+// Parans returns the crossings within orb degrees of latitude lat.
+func Parans(crossings []Crossing, lat, orb float64) []Crossing {
+	var out []Crossing
+	for _, c := range crossings {
+		if math.Abs(c.Lat-lat) <= orb {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func rad(d float64) float64 { return d * math.Pi / 180 }
+func deg(r float64) float64 { return r * 180 / math.Pi }
+
+// lonnorm normalises a longitude to [-180, 180).
+func lonnorm(a float64) float64 {
+	return angle.Diff(a, 0)
+}
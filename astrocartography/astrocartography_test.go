@@ -0,0 +1,98 @@
+package astrocartography_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/astrocartography"
+)
+
+func TestEquatorial(t *testing.T) {
+	const eps = 23.44
+	cases := []struct{ lon, ra, dec float64 }{
+		{0, 0, 0},
+		{90, 90, eps},
+		{180, 180, 0},
+		{270, 270, -eps},
+	}
+	for _, tc := range cases {
+		ra, dec := astrocartography.Equatorial(tc.lon, 0, eps)
+		if math.Abs(ra-tc.ra) > 1e-9 || math.Abs(dec-tc.dec) > 1e-9 {
+			t.Errorf("Equatorial(%v) = %.4f, %.4f; want %v, %v", tc.lon, ra, dec, tc.ra, tc.dec)
+		}
+	}
+}
+
+func TestLongitude(t *testing.T) {
+	pl := astrocartography.Planet{RA: 100, Dec: 20}
+	const gst = 30
+	cases := []struct {
+		angle astrocartography.Angle
+		lat   float64
+		want  float64
+	}{
+		{astrocartography.MC, 51, 70},
+		{astrocartography.IC, 51, -110},
+		// On the equator every body is up for twelve hours.
+		{astrocartography.ASC, 0, -20},
+		{astrocartography.DSC, 0, 160},
+	}
+	for _, tc := range cases {
+		got, ok := astrocartography.Longitude(pl, tc.angle, tc.lat, gst)
+		if !ok || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%v at %v° = %.4f (ok %v), want %v", tc.angle, tc.lat, got, ok, tc.want)
+		}
+	}
+	// At 75°N a body at +20° declination never sets.
+	if _, ok := astrocartography.Longitude(pl, astrocartography.ASC, 75, gst); ok {
+		t.Error("circumpolar body should have no ASC line")
+	}
+}
+
+func TestLines_SplitAtAntimeridian(t *testing.T) {
+	// The ASC line meets the antimeridian at the equator.
+	pl := astrocartography.Planet{RA: 90, Dec: 20}
+	lines := astrocartography.Lines([]astrocartography.Planet{pl}, 180, 1)
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4", len(lines))
+	}
+	for _, l := range lines {
+		for _, seg := range l.Segments {
+			for i := 1; i < len(seg); i++ {
+				if math.Abs(seg[i][0]-seg[i-1][0]) > 180 {
+					t.Errorf("%v segment jumps from %v to %v", l.Angle, seg[i-1], seg[i])
+				}
+			}
+		}
+	}
+	asc := lines[0]
+	if asc.Angle != astrocartography.ASC || len(asc.Segments) < 2 {
+		t.Errorf("ASC line has %d segments, want it split at the antimeridian", len(asc.Segments))
+	}
+}
+
+func TestCrossings(t *testing.T) {
+	// A rises where B culminates when α_A - H0 = α_B, i.e. H0 = 60°:
+	// tan φ = -cos 60° / tan δ_A.
+	a := astrocartography.Planet{Body: 1, RA: 100, Dec: -15}
+	b := astrocartography.Planet{Body: 2, RA: 40, Dec: 10}
+	want := math.Atan(-math.Cos(math.Pi/3)/math.Tan(-15*math.Pi/180)) * 180 / math.Pi
+
+	var found bool
+	for _, c := range astrocartography.Crossings([]astrocartography.Planet{a, b}, 0) {
+		if c.AngleA == astrocartography.ASC && c.AngleB == astrocartography.MC {
+			found = true
+			if math.Abs(c.Lat-want) > 1e-5 || math.Abs(c.Lon-40) > 1e-5 {
+				t.Errorf("ASC/MC crossing at %.5f, %.5f; want %.5f, 40", c.Lat, c.Lon, want)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no ASC/MC crossing found")
+	}
+
+	parans := astrocartography.Parans(astrocartography.Crossings([]astrocartography.Planet{a, b}, 0), want+0.5, 1)
+	if len(parans) == 0 {
+		t.Error("Parans within 1° should include the ASC/MC crossing")
+	}
+}
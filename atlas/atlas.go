@@ -0,0 +1,484 @@
+// Package atlas resolves place names such as "Berlin, Germany" to
+// coordinates and a time zone.
+//
+// The built-in atlas has the capitals and large cities of the world, with
+// their English names and common other spellings (München, Bombay). A
+// larger gazetteer can be read from a GeoNames cities file, such as
+// cities15000.txt from https://download.geonames.org/export/dump/.
+//
+// Names match whatever their case and diacritics, so "zurich" finds
+// Zürich. A query may name the region or country after a comma:
+//
+//	Springfield, Illinois
+//	Paris, TX
+//	London, Ontario, Canada
+//
+// Among places of the same name, the most populous wins.
+package atlas
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/dcccxiii/astro/geo"
+)
+
+//go:embed cities.tsv
+var citiesFile string
+
+//go:embed countries.tsv
+var countriesFile string
+
+// Place is a populated place.
+type Place struct {
+	Name       string
+	Region     string // first-level division, e.g. a state; may be empty
+	Country    string // ISO 3166-1 alpha-2 code
+	Lat, Lon   float64
+	Zone       string // IANA time zone name
+	Population int
+
+	names   []string // normalized Name and other names
+	regions []string // normalized Region and other names or codes
+}
+
+// CountryName returns the English name of the place's country, or its
+// code if the atlas has no name for it.
+func (p Place) CountryName() string {
+	if c, ok := countries()[p.Country]; ok {
+		return c.name
+	}
+	return p.Country
+}
+
+// String returns the place as "Name, Region, Country", which Lookup
+// resolves back to it.
+func (p Place) String() string {
+	parts := []string{p.Name}
+	if p.Region != "" && p.Region != p.Name {
+		parts = append(parts, p.Region)
+	}
+	return strings.Join(append(parts, p.CountryName()), ", ")
+}
+
+// Error describes a malformed line of an atlas file.
+type Error struct {
+	Line   int
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("atlas line %d: %s", e.Line, e.Reason)
+}
+
+// Atlas is a set of places, searched by name.
+type Atlas struct {
+	places []Place // most populous first
+}
+
+// Default returns the built-in atlas.
+var Default = sync.OnceValue(func() *Atlas {
+	a, err := Load(strings.NewReader(citiesFile))
+	if err != nil {
+		panic(err)
+	}
+	return a
+})
+
+// Load reads an atlas from r: either the tab-separated form of the
+// built-in atlas or a GeoNames cities file. Lines starting with # are
+// skipped.
+func Load(r io.Reader) (*Atlas, error) {
+	a := &Atlas{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20) // GeoNames lines with many other names are long
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, err := parseLine(strings.Split(line, "\t"))
+		if err != nil {
+			return nil, &Error{Line: n, Reason: err.Error()}
+		}
+		a.places = append(a.places, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(a.places, func(p, q Place) int { return q.Population - p.Population })
+	return a, nil
+}
+
+// parseLine parses the fields of a line of the built-in atlas (8 fields)
+// or of a GeoNames file (19 fields).
+func parseLine(f []string) (Place, error) {
+	var p Place
+	var lat, lon, pop string
+	var others []string
+	switch len(f) {
+	case 8:
+		p.Name, p.Country, lat, lon, p.Zone, pop = f[0], f[3], f[4], f[5], f[6], f[7]
+		others = splitList(f[1], ";")
+		regions := splitList(f[2], ";")
+		if len(regions) > 0 {
+			p.Region = regions[0]
+		}
+		for _, r := range regions {
+			p.regions = append(p.regions, normalize(r))
+		}
+	case 19:
+		// geonameid, name, asciiname, alternatenames, latitude, longitude,
+		// feature class, feature code, country code, cc2, admin1 code, ...,
+		// population (14), elevation, dem, timezone (17), modification date.
+		p.Name, p.Country, lat, lon, p.Zone, pop = f[1], f[8], f[4], f[5], f[17], f[14]
+		others = append([]string{f[2]}, splitList(f[3], ",")...)
+		if f[10] != "" {
+			p.regions = []string{normalize(f[10])}
+		}
+	default:
+		return Place{}, fmt.Errorf("expected 8 tab-separated fields, or 19 in a GeoNames file, got %d", len(f))
+	}
+	if p.Name == "" {
+		return Place{}, fmt.Errorf("missing name")
+	}
+	if p.Zone == "" {
+		return Place{}, fmt.Errorf("%s has no time zone", p.Name)
+	}
+	var err error
+	if p.Lat, err = geo.ParseLatitude(lat); err != nil {
+		return Place{}, fmt.Errorf("invalid latitude %q: %v", lat, err)
+	}
+	if p.Lon, err = geo.ParseLongitude(lon); err != nil {
+		return Place{}, fmt.Errorf("invalid longitude %q: %v", lon, err)
+	}
+	if pop != "" {
+		if p.Population, err = strconv.Atoi(pop); err != nil {
+			return Place{}, fmt.Errorf("invalid population %q", pop)
+		}
+	}
+	p.names = []string{normalize(p.Name)}
+	for _, o := range others {
+		if n := normalize(o); n != "" && !slices.Contains(p.names, n) {
+			p.names = append(p.names, n)
+		}
+	}
+	return p, nil
+}
+
+// Len returns the number of places in the atlas.
+func (a *Atlas) Len() int { return len(a.places) }
+
+// Lookup returns the most populous place called query, which may name
+// the region and country after commas.
+func (a *Atlas) Lookup(query string) (Place, bool) {
+	name, quals := parseQuery(query)
+	if name == "" {
+		return Place{}, false
+	}
+	for _, p := range a.places {
+		if slices.Contains(p.names, name) && p.within(quals) {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// Search returns up to limit places matching query, best first: those
+// called query, then those whose name starts with it, then those with a
+// word of the name starting with it, then names within a typo or two.
+// Each group is ordered by population.
+func (a *Atlas) Search(query string, limit int) []Place {
+	name, quals := parseQuery(query)
+	if name == "" || limit <= 0 {
+		return nil
+	}
+	const groups = 4
+	var found [groups][]Place
+	for _, p := range a.places {
+		if !p.within(quals) {
+			continue
+		}
+		if rank := p.match(name); rank < groups {
+			found[rank] = append(found[rank], p)
+		}
+	}
+	var res []Place
+	for _, g := range found {
+		res = append(res, g...)
+	}
+	return res[:min(limit, len(res))]
+}
+
+// Nearest returns the place closest to the given coordinates and its
+// distance in kilometres. It returns false if the atlas is empty.
+func (a *Atlas) Nearest(lat, lon float64) (Place, float64, bool) {
+	best, dist := -1, math.Inf(1)
+	for i, p := range a.places {
+		if d := geo.Distance(lat, lon, p.Lat, p.Lon); d < dist {
+			best, dist = i, d
+		}
+	}
+	if best < 0 {
+		return Place{}, 0, false
+	}
+	return a.places[best], dist, true
+}
+
+// MaxZoneDistance is how far, in kilometres, ZoneAt takes the zone of the
+// nearest place. Farther out, at sea or in empty land, it falls back to
+// the nautical zone of the longitude.
+const MaxZoneDistance = 1500
+
+// ZoneMargin is how many times as far as the nearest place every place in
+// another zone must be for ZoneAt to take the nearest place's zone.
+const ZoneMargin = 1.5
+
+// ZoneError is the error ZoneAt returns for coordinates that may lie in
+// the zone of either of two places, as near a border.
+type ZoneError struct {
+	Lat, Lon    float64
+	Near, Other Place      // the nearest place, and the nearest in another zone
+	Distances   [2]float64 // their distances in kilometres
+}
+
+func (e *ZoneError) Error() string {
+	return fmt.Sprintf("the time zone at %g %g is unclear: %s, as at %s (%.0f km), or %s, as at %s (%.0f km)",
+		e.Lat, e.Lon, e.Near.Zone, e.Near, e.Distances[0], e.Other.Zone, e.Other, e.Distances[1])
+}
+
+// ZoneAt returns the IANA time zone at the given coordinates: that of the
+// nearest place, if it is within MaxZoneDistance, along with the place.
+// Otherwise it returns the nautical zone, Etc/GMT-1 for UTC+01:00 and so
+// on, of the 15° band of longitude, and no place.
+//
+// The atlas has no zone boundaries, so near a border the nearest place may
+// lie in another zone. Unless every place in a zone with other clocks is
+// at least ZoneMargin times as far as the nearest, ZoneAt does not guess
+// but returns a *ZoneError naming both zones.
+func (a *Atlas) ZoneAt(lat, lon float64) (string, *Place, error) {
+	p, d, ok := a.Nearest(lat, lon)
+	if !ok || d > MaxZoneDistance {
+		h := int(math.Round(lon / 15))
+		if h == 0 {
+			return "Etc/GMT", nil, nil
+		}
+		// The signs of the Etc zones are inverted, as in POSIX TZ strings.
+		return fmt.Sprintf("Etc/GMT%+d", -h), nil, nil
+	}
+	same := map[string]bool{p.Zone: true}
+	other, dist := -1, ZoneMargin*d
+	for i, q := range a.places {
+		e := geo.Distance(lat, lon, q.Lat, q.Lon)
+		if e >= dist {
+			continue
+		}
+		if _, ok := same[q.Zone]; !ok {
+			same[q.Zone] = sameClocks(p.Zone, q.Zone)
+		}
+		if !same[q.Zone] {
+			other, dist = i, e
+		}
+	}
+	if other >= 0 {
+		return "", nil, &ZoneError{Lat: lat, Lon: lon, Near: p, Other: a.places[other], Distances: [2]float64{d, dist}}
+	}
+	return p.Zone, &p, nil
+}
+
+// sameClocks reports whether the IANA zones a and b have always kept the
+// same offset from UTC, as a link such as Europe/Vatican does with its
+// target, Europe/Rome. A zone that does not load is only the same as
+// itself.
+func sameClocks(a, b string) bool {
+	if a == b {
+		return true
+	}
+	la, err := time.LoadLocation(a)
+	if err != nil {
+		return false
+	}
+	lb, err := time.LoadLocation(b)
+	if err != nil {
+		return false
+	}
+	// Walk the transitions of both zones into the years their rules repeat.
+	end := time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+	for t := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC); t.Before(end); {
+		ta, tb := t.In(la), t.In(lb)
+		_, oa := ta.Zone()
+		_, ob := tb.Zone()
+		if oa != ob {
+			return false
+		}
+		_, na := ta.ZoneBounds()
+		_, nb := tb.ZoneBounds()
+		next := na
+		switch {
+		case na.IsZero() && nb.IsZero():
+			return true
+		case na.IsZero() || (!nb.IsZero() && nb.Before(na)):
+			next = nb
+		}
+		// Under the rules that follow a zone's table, a period may end at
+		// the turn of the year where the rules are applied afresh.
+		if !next.After(t) {
+			next = t.Add(24 * time.Hour)
+		}
+		t = next
+	}
+	return true
+}
+
+// match ranks how well name matches the place, as Search orders them: 0
+// to 3, or 4 for no match.
+func (p Place) match(name string) int {
+	rank := 4
+	for _, n := range p.names {
+		switch {
+		case n == name:
+			return 0
+		case strings.HasPrefix(n, name):
+			rank = min(rank, 1)
+		case strings.Contains(" "+n, " "+name):
+			rank = min(rank, 2)
+		case len(name) >= 4 && distance(n, name) <= len(name)/4:
+			rank = min(rank, 3)
+		}
+	}
+	return rank
+}
+
+// within reports whether each qualifier names the place's region or
+// country.
+func (p Place) within(quals []string) bool {
+	c := countries()[p.Country]
+	for _, q := range quals {
+		if !slices.Contains(p.regions, q) && !slices.Contains(c.names, q) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseQuery splits a query at its commas into the normalized place name
+// and the qualifiers after it.
+func parseQuery(query string) (name string, quals []string) {
+	parts := strings.Split(query, ",")
+	for _, q := range parts[1:] {
+		if q = normalize(q); q != "" {
+			quals = append(quals, q)
+		}
+	}
+	return normalize(parts[0]), quals
+}
+
+// country is an entry of the country table.
+type country struct {
+	name  string
+	names []string // normalized code, name and other names
+}
+
+// countries returns the built-in country table, by ISO code.
+var countries = sync.OnceValue(func() map[string]country {
+	m := map[string]country{}
+	for _, line := range strings.Split(countriesFile, "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) != 3 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		c := country{name: f[1], names: []string{normalize(f[0]), normalize(f[1])}}
+		for _, o := range splitList(f[2], ";") {
+			c.names = append(c.names, normalize(o))
+		}
+		m[f[0]] = c
+	}
+	return m
+})
+
+// splitList splits s at sep, dropping empty items.
+func splitList(s, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// folds spells letters with diacritics without them.
+var folds = map[rune]string{}
+
+func init() {
+	for _, f := range []struct{ from, to string }{
+		{"àáâãäåāăąạảấầẩẫậắằẳẵặ", "a"}, {"æ", "ae"}, {"çćĉčċ", "c"}, {"ďđð", "d"},
+		{"èéêëēĕėęěẹẻẽếềểễệ", "e"}, {"ĝğġģ", "g"}, {"ĥħ", "h"},
+		{"ìíîïĩīĭįıịỉ", "i"}, {"ĵ", "j"}, {"ķ", "k"}, {"ĺļľŀł", "l"}, {"ñńņňŉ", "n"},
+		{"òóôõöøōŏőơọỏốồổỗộớờởỡợ", "o"}, {"œ", "oe"}, {"ŕŗř", "r"}, {"śŝşšș", "s"},
+		{"ß", "ss"}, {"ţťŧț", "t"}, {"þ", "th"}, {"ùúûüũūŭůűųưụủứừửữự", "u"},
+		{"ŵ", "w"}, {"ýÿŷỳỵỷỹ", "y"}, {"źżž", "z"},
+	} {
+		for _, r := range f.from {
+			folds[r] = f.to
+		}
+	}
+}
+
+// normalize lower-cases s, drops its diacritics and reduces everything
+// but letters and digits to single spaces, so that "Saint-Étienne" and
+// "saint etienne" compare equal.
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		letter := folds[r]
+		switch {
+		case letter != "":
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			letter = string(r)
+		case unicode.Is(unicode.Mn, r):
+			continue
+		default:
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteString(letter)
+	}
+	return b.String()
+}
+
+// distance returns the Levenshtein distance between a and b, in runes.
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range s {
+		cur[0] = i + 1
+		for j := range t {
+			cost := 1
+			if s[i] == t[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(t)]
+}
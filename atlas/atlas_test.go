@@ -0,0 +1,210 @@
+package atlas
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // Place.String()
+	}{
+		{"Berlin, Germany", "Berlin, Germany"},
+		{"berlin", "Berlin, Germany"},
+		{"München", "Munich, Bavaria, Germany"},
+		{"muenchen, de", "Munich, Bavaria, Germany"},
+		{"ZURICH", "Zurich, Switzerland"},
+		{"Zürich, Schweiz", "Zurich, Switzerland"},
+		{"Paris", "Paris, Île-de-France, France"},
+		{"Paris, TX", "Paris, Texas, United States"},
+		{"Paris, Texas, USA", "Paris, Texas, United States"},
+		{"Springfield", "Springfield, Missouri, United States"},
+		{"Springfield, Illinois", "Springfield, Illinois, United States"},
+		{"London", "London, England, United Kingdom"},
+		{"London, Ontario", "London, Ontario, Canada"},
+		{"Hyderabad, Pakistan", "Hyderabad, Sindh, Pakistan"},
+		{"St Louis", "St. Louis, Missouri, United States"},
+		{"  new york city ,  us ", "New York, United States"},
+		{"Rostov on Don", "Rostov-on-Don, Russia"},
+		{"Ndjamena", "N'Djamena, Chad"},
+	}
+	for _, tt := range tests {
+		p, ok := Default().Lookup(tt.query)
+		if !ok {
+			t.Errorf("Lookup(%q) found nothing, want %s", tt.query, tt.want)
+			continue
+		}
+		if p.String() != tt.want {
+			t.Errorf("Lookup(%q) = %s, want %s", tt.query, p, tt.want)
+		}
+		// String resolves back to the same place.
+		if q, _ := Default().Lookup(p.String()); q.Lat != p.Lat || q.Lon != p.Lon {
+			t.Errorf("Lookup(%q) = %s, not %s", p.String(), q, p)
+		}
+	}
+
+	for _, q := range []string{"", ",", "Berln", "Berlin, France", "Atlantis"} {
+		if p, ok := Default().Lookup(q); ok {
+			t.Errorf("Lookup(%q) = %s, want nothing", q, p)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		limit int
+		want  []string // Place.Name
+	}{
+		{"Springfield", 10, []string{"Springfield", "Springfield", "Springfield"}},
+		{"springfield, il", 10, []string{"Springfield"}},
+		{"San Fr", 3, []string{"San Francisco"}},
+		{"Sant", 3, []string{"Santiago", "Santo Domingo", "Santa Cruz de la Sierra"}},
+		// Exact names first, then prefixes, then words, then typos.
+		{"york", 5, []string{"New York", "Cork"}},
+		{"Berln", 5, []string{"Berlin", "Bern"}},
+		{"Victoria", 5, []string{"Victoria", "Victoria"}},
+		{"Frankfurt", 5, []string{"Frankfurt am Main"}},
+		{"Atlantis", 5, []string{"Atlanta"}},
+		{"Xanadu", 5, nil},
+		{"Berlin", 0, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range Default().Search(tt.query, tt.limit) {
+			got = append(got, p.Name)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Search(%q, %d) = %q, want %q", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if Default().Len() < 500 {
+		t.Errorf("built-in atlas has %d places, want at least 500", Default().Len())
+	}
+	for _, p := range Default().places {
+		if _, err := time.LoadLocation(p.Zone); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+		if p.CountryName() == p.Country {
+			t.Errorf("%s: no name for country %s", p, p.Country)
+		}
+	}
+}
+
+func TestLoadGeoNames(t *testing.T) {
+	line := strings.Join([]string{
+		"2950159", "Berlin", "Berlin", "Berlin,Berlino,Berlín,Berlyn",
+		"52.52437", "13.41053", "P", "PPLC", "DE", "", "16", "00", "11000", "11000000",
+		"3426354", "", "74", "Europe/Berlin", "2024-01-01",
+	}, "\t")
+	a, err := Load(strings.NewReader("# comment\n" + line + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := a.Lookup("Berlín, Germany")
+	if !ok {
+		t.Fatal("Lookup(Berlín, Germany) found nothing")
+	}
+	if p.Lat != 52.52437 || p.Lon != 13.41053 || p.Zone != "Europe/Berlin" || p.Population != 3426354 {
+		t.Errorf("Lookup(Berlín, Germany) = %+v", p)
+	}
+
+	_, err = Load(strings.NewReader("Nowhere\tXX\n"))
+	var e *Error
+	if !errors.As(err, &e) || e.Line != 1 {
+		t.Errorf("Load(2 fields) error = %v, want an *Error on line 1", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Saint-Étienne":  "saint etienne",
+		"  Ürümqi ":      "urumqi",
+		"Łódź":           "lodz",
+		"N'Djamena":      "n djamena",
+		"Đà Nẵng":        "da nang",
+		"Straße":         "strasse",
+		"São Paulo, SP":  "sao paulo sp",
+		"İzmir":          "izmir",
+		"Rostov-on-Don.": "rostov on don",
+	}
+	for in, want := range tests {
+		if got := normalize(in); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestZoneAt(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		zone     string
+		near     string // Place.Name, or "" for a nautical zone
+	}{
+		{52.52, 13.405, "Europe/Berlin", "Berlin"},
+		{48.1, 11.6, "Europe/Berlin", "Munich"},
+		{40.7, -74.0, "America/New_York", "New York"},
+		{-33.87, 151.21, "Australia/Sydney", "Sydney"},
+		{27.7, 85.3, "Asia/Kathmandu", "Kathmandu"},
+		{64.15, -21.94, "Atlantic/Reykjavik", "Reykjavík"},
+		{-31.95, 141.45, "Australia/Broken_Hill", "Broken Hill"},
+		{10, -40, "Etc/GMT+3", ""},      // mid-Atlantic
+		{-50, 0, "Etc/GMT", ""},         // South Atlantic
+		{-40, -140, "Etc/GMT+9", ""},    // South Pacific
+		{-60, 172.5, "Etc/GMT-12", ""},  // Southern Ocean
+		{-60, -172.6, "Etc/GMT+12", ""}, // across the date line
+		{-75, 100, "Etc/GMT-7", ""},     // Antarctica
+	}
+	for _, tt := range tests {
+		zone, p, err := Default().ZoneAt(tt.lat, tt.lon)
+		if err != nil {
+			t.Errorf("ZoneAt(%v, %v): %v", tt.lat, tt.lon, err)
+			continue
+		}
+		near := ""
+		if p != nil {
+			near = p.Name
+		}
+		if zone != tt.zone || near != tt.near {
+			t.Errorf("ZoneAt(%v, %v) = %s near %q, want %s near %q", tt.lat, tt.lon, zone, near, tt.zone, tt.near)
+		}
+		if _, err := time.LoadLocation(zone); err != nil {
+			t.Errorf("ZoneAt(%v, %v): %v", tt.lat, tt.lon, err)
+		}
+	}
+
+	if _, _, ok := (&Atlas{}).Nearest(0, 0); ok {
+		t.Error("Nearest in an empty atlas found a place")
+	}
+
+	// Near a border, where the nearest place may be across it, ZoneAt does
+	// not guess.
+	for _, tt := range []struct {
+		lat, lon    float64
+		near, other string // the zones of the places the error names
+	}{
+		{42.55, -6.6, "Europe/Lisbon", "Europe/Madrid"},    // Ponferrada, Spain
+		{52.03, 113.5, "Asia/Irkutsk", "Asia/Ulaanbaatar"}, // Chita, Russia, in Asia/Chita
+		{40, -100, "America/Chicago", "America/Denver"},    // Kansas and Nebraska
+	} {
+		zone, _, err := Default().ZoneAt(tt.lat, tt.lon)
+		ze, ok := err.(*ZoneError)
+		if !ok || ze.Near.Zone != tt.near || ze.Other.Zone != tt.other {
+			t.Errorf("ZoneAt(%v, %v) = %s, %v; want an error naming %s and %s", tt.lat, tt.lon, zone, err, tt.near, tt.other)
+		}
+	}
+
+	// A link keeps the clocks of its target, so is no other zone.
+	if zone, _, err := Default().ZoneAt(41.9, 12.46); zone != "Europe/Vatican" && zone != "Europe/Rome" || err != nil {
+		t.Errorf("ZoneAt(Rome, by the Vatican) = %s, %v; want Europe/Rome or Europe/Vatican", zone, err)
+	}
+	if !sameClocks("Europe/Vatican", "Europe/Rome") || sameClocks("Europe/Berlin", "Europe/Paris") || sameClocks("Europe/Lisbon", "Europe/Madrid") {
+		t.Error("sameClocks does not tell links from zones of their own")
+	}
+}
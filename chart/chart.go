@@ -0,0 +1,153 @@
+// Package chart is the way to use astro from Go: a natal chart cast once
+// from an ephemeris.Provider, with its aspects, the transits to it at a
+// moment, and its synastry with another chart. It gathers what the CLI
+// does through the aspects, transits and synastry packages behind one
+// value, so a program needs neither the cmd package nor the swisseph
+// bindings; pass it a swiss.Provider for real positions.
+package chart
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/synastry"
+	"github.com/dcccxiii/astro/transits"
+)
+
+// Planets are the bodies of a chart cast without a list of its own: the
+// Sun to Pluto.
+var Planets = []int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars,
+	ephemeris.Jupiter, ephemeris.Saturn, ephemeris.Uranus, ephemeris.Neptune, ephemeris.Pluto,
+}
+
+// Placidus is the Swiss Ephemeris code of the Placidus house system, the
+// CLI's default; the other codes are those of ephemeris.Provider.CalcHouses.
+const Placidus = 'P'
+
+// Planet is a body of a chart with its position and house.
+type Planet struct {
+	Body int
+	Name string // from names.Default
+	ephemeris.PlanetPos
+	House int // 1-12
+}
+
+// Chart is a natal chart: the positions of its planets and its houses at a
+// moment and place.
+type Chart struct {
+	Time        time.Time // UTC
+	JD          float64   // Julian Day (UT) of Time
+	Lat, Lon    float64
+	HouseSystem byte
+	Planets     []Planet // in the order of the bodies it was cast with
+	Houses      ephemeris.HouseResult
+
+	p ephemeris.Provider // for Transits
+}
+
+// New casts the chart of bodies at t and the place, with houses in the
+// house system hsys. With no bodies it has the Planets. The chart keeps p
+// to find transits with.
+func New(p ephemeris.Provider, t time.Time, lat, lon float64, hsys byte, bodies ...int) (*Chart, error) {
+	if len(bodies) == 0 {
+		bodies = Planets
+	}
+	jd := ephemeris.JulianDay(t)
+	pos, err := ephemeris.CalcPlanets(p, jd, bodies)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating planets: %w", err)
+	}
+	h, err := p.CalcHouses(jd, lat, lon, hsys)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating houses: %w", err)
+	}
+	c := &Chart{Time: t.UTC(), JD: jd, Lat: lat, Lon: lon, HouseSystem: hsys, Houses: h, p: p}
+	for i, body := range bodies {
+		c.Planets = append(c.Planets, Planet{Body: body, Name: names.Body(body), PlanetPos: pos[i], House: h.HouseOf(pos[i].Longitude)})
+	}
+	return c, nil
+}
+
+// Planet returns the chart's position of body, and whether it has one.
+func (c *Chart) Planet(body int) (Planet, bool) {
+	for _, pl := range c.Planets {
+		if pl.Body == body {
+			return pl, true
+		}
+	}
+	return Planet{}, false
+}
+
+// Aspect is an aspect between two planets of a chart.
+type Aspect struct {
+	A, B   Planet // A before B in the chart's order
+	Aspect aspects.Aspect
+	Orb    float64 // distance from exact, in degrees
+}
+
+// Aspects returns the aspects between the chart's planets, each pair once,
+// within the aspects as with their orbs, or aspects.Major if none are
+// given.
+func (c *Chart) Aspects(as ...aspects.Aspect) []Aspect {
+	if len(as) == 0 {
+		as = aspects.Major
+	}
+	var found []Aspect
+	for i, a := range c.Planets {
+		for _, b := range c.Planets[i+1:] {
+			if asp, orb, ok := aspects.Between(a.Longitude, b.Longitude, as); ok {
+				found = append(found, Aspect{A: a, B: b, Aspect: asp, Orb: orb})
+			}
+		}
+	}
+	return found
+}
+
+// Points returns the chart's planets, Ascendant and MC as the natal points
+// of the transits package.
+func (c *Chart) Points() []transits.Point {
+	points := make([]transits.Point, 0, len(c.Planets)+2)
+	for _, pl := range c.Planets {
+		points = append(points, transits.Point{Name: pl.Name, Longitude: pl.Longitude})
+	}
+	return append(points,
+		transits.Point{Name: names.Point(names.Ascendant), Longitude: c.Houses.Ascendant},
+		transits.Point{Name: names.Point(names.MC), Longitude: c.Houses.MC})
+}
+
+// Transits returns the transits in orb at t of the chart's bodies to its
+// Points, within the aspects as, or aspects.Major if none are given;
+// ordered as transits.Snapshot orders them.
+func (c *Chart) Transits(t time.Time, as ...aspects.Aspect) ([]transits.Active, error) {
+	if len(as) == 0 {
+		as = aspects.Major
+	}
+	bodies := make([]int, len(c.Planets))
+	for i, pl := range c.Planets {
+		bodies[i] = pl.Body
+	}
+	return transits.Snapshot(c.p, bodies, c.Points(), as, ephemeris.JulianDay(t))
+}
+
+// Synastry compares the chart, as chart A, with other: their inter-aspects
+// within the aspects as, or aspects.Major if none are given, and each
+// one's planets in the other's houses.
+func (c *Chart) Synastry(other *Chart, as ...aspects.Aspect) synastry.Result {
+	if len(as) == 0 {
+		as = aspects.Major
+	}
+	return synastry.Compare(c.synastryChart(), other.synastryChart(), as)
+}
+
+// synastryChart returns the part of the chart synastry compares.
+func (c *Chart) synastryChart() synastry.Chart {
+	sc := synastry.Chart{Cusps: c.Houses.Cusps}
+	for _, pl := range c.Planets {
+		sc.Bodies = append(sc.Bodies, synastry.Body{Name: pl.Name, Longitude: pl.Longitude})
+	}
+	return sc
+}
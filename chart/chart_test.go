@@ -0,0 +1,111 @@
+package chart_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/chart"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// equalHouses returns 30° houses with the Ascendant at asc and the MC a
+// quadrant behind it.
+func equalHouses(asc float64) ephemeris.HouseResult {
+	h := ephemeris.HouseResult{Ascendant: asc, MC: math.Mod(asc+270, 360)}
+	for i := 1; i <= 12; i++ {
+		h.Cusps[i] = math.Mod(asc+30*float64(i-1), 360)
+	}
+	return h
+}
+
+var epoch = time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newChart(t *testing.T, sun, asc float64) *chart.Chart {
+	t.Helper()
+	p := &ephemeris.MockProvider{
+		Epoch: ephemeris.JulianDay(epoch),
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: sun, SpeedLon: 1},
+			ephemeris.Moon: {Longitude: sun + 92, SpeedLon: 13},
+			ephemeris.Mars: {Longitude: sun + 200, SpeedLon: 0.5},
+		},
+		Houses: equalHouses(asc),
+	}
+	c, err := chart.New(p, epoch, 51.5, 0, 'A', ephemeris.Sun, ephemeris.Moon, ephemeris.Mars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNew(t *testing.T) {
+	c := newChart(t, 0, 0)
+	if c.JD != 2451545 || len(c.Planets) != 3 {
+		t.Fatalf("chart = %+v", c)
+	}
+	for i, want := range []struct {
+		name  string
+		house int
+	}{{"Sun", 1}, {"Moon", 4}, {"Mars", 7}} {
+		if pl := c.Planets[i]; pl.Name != want.name || pl.House != want.house {
+			t.Errorf("planet %d = %s in house %d, want %s in house %d", i, pl.Name, pl.House, want.name, want.house)
+		}
+	}
+	if moon, ok := c.Planet(ephemeris.Moon); !ok || moon.Longitude != 92 {
+		t.Errorf("Planet(Moon) = %+v, %v", moon, ok)
+	}
+	if _, ok := c.Planet(ephemeris.Venus); ok {
+		t.Error("Planet(Venus) found in a chart without Venus")
+	}
+}
+
+func TestAspects(t *testing.T) {
+	// The Moon squares the Sun within 2°; Mars, 160° from the Sun and 108°
+	// from the Moon, aspects neither.
+	got := newChart(t, 0, 0).Aspects()
+	if len(got) != 1 {
+		t.Fatalf("Aspects = %+v, want one", got)
+	}
+	if a := got[0]; a.A.Name != "Sun" || a.B.Name != "Moon" || a.Aspect.Name != "square" || math.Abs(a.Orb-2) > 1e-9 {
+		t.Errorf("aspect = %+v, want Sun square Moon, orb 2", a)
+	}
+}
+
+func TestTransits(t *testing.T) {
+	// A month on, the Sun at 30° is sextile the natal Moon at 92° and trine
+	// the MC at 270°.
+	active, err := newChart(t, 0, 0).Transits(epoch.Add(30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, a := range active {
+		if a.Body == ephemeris.Sun {
+			found[a.Aspect.Name+" "+a.Point.Name] = true
+		}
+	}
+	for _, want := range []string{"sextile Moon", "trine MC"} {
+		if !found[want] {
+			t.Errorf("no transiting Sun %s among %+v", want, active)
+		}
+	}
+}
+
+func TestSynastry(t *testing.T) {
+	a, b := newChart(t, 0, 0), newChart(t, 180, 90)
+	r := a.Synastry(b)
+	opposed := false
+	for _, ia := range r.Aspects {
+		if ia.A.Name == "Sun" && ia.B.Name == "Sun" {
+			opposed = ia.Aspect.Name == "opposition"
+		}
+	}
+	if !opposed {
+		t.Errorf("Suns not in opposition: %+v", r.Aspects)
+	}
+	// A's Sun at 0° falls in the 10th of B's houses, which begin at 90°.
+	if r.AInB[0].Body.Name != "Sun" || r.AInB[0].House != 10 {
+		t.Errorf("A's Sun in B's houses = %+v, want house 10", r.AInB[0])
+	}
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/aaf"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+)
+
+// runAAF implements "astro aaf": conversion of chart collections from and
+// to the Astrological Exchange Format.
+func runAAF(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "import":
+			return runAAFImport(args[1:])
+		case "export":
+			return runAAFExport(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Usage: astro aaf import <file.aaf> [flags]   (see astro aaf import --help)\n")
+	fmt.Fprintf(os.Stderr, "       astro aaf export <file.csv> [flags]   (see astro aaf export --help)\n")
+	if len(args) > 0 && isHelp(args[0]) {
+		return nil
+	}
+	return fmt.Errorf("expected import or export")
+}
+
+// runAAFImport implements "astro aaf import": the charts of an AAF file,
+// listed with the datetime, latitude and longitude astro takes.
+func runAAFImport(args []string) error {
+	fs := flag.NewFlagSet("astro aaf import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro aaf import <file.aaf|-> [--format text|csv|json|ndjson | --json [--compact] | --ndjson] [--output <file>]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the charts of an AAF file, as written by Astroplan and other\n")
+		fmt.Fprintf(fs.Output(), "  programs, each with its local datetime, latitude and longitude in the\n")
+		fmt.Fprintf(fs.Output(), "  form astro takes them. As CSV, the list can be edited and converted\n")
+		fmt.Fprintf(fs.Output(), "  back with astro aaf export. - reads the file from stdin.\n\n")
+		fs.PrintDefaults()
+	}
+
+	formatFlag := fs.String("format", "", "Output format: text, csv, json or ndjson (default from the --output extension, else text)")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per chart, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	outputFlag := fs.String("output", "", "File to write the list to (default stdout)")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected 1 positional argument (<file.aaf>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	short := ""
+	switch {
+	case *jsonFlag:
+		short = "json"
+	case *ndjsonFlag:
+		short = "ndjson"
+	}
+	format := strings.ToLower(*formatFlag)
+	switch {
+	case format == "" && short != "":
+		format = short
+	case format == "":
+		format = formatOfFile(*outputFlag)
+	case short != "" && short != format:
+		return fmt.Errorf("--%s and --format %s cannot be combined", short, *formatFlag)
+	}
+	switch format {
+	case "text", "csv", "json", "ndjson":
+	default:
+		return fmt.Errorf("astro aaf import cannot write %s: valid formats are text, csv, json, ndjson", format)
+	}
+
+	recs, err := readFile(pos[0], aaf.Read)
+	if err != nil {
+		return err
+	}
+	charts := output.BuildAAFCharts(recs)
+
+	err = writeOutput(*outputFlag, func(w io.Writer) error {
+		switch format {
+		case "csv":
+			return output.WriteAAFChartsCSV(w, charts)
+		case "json":
+			return output.WriteAAFChartsJSON(w, charts, output.JSONOptions{Compact: *compactFlag})
+		case "ndjson":
+			return output.WriteAAFChartsNDJSON(w, charts)
+		default:
+			return output.WriteAAFChartsText(w, charts)
+		}
+	})
+	return internal(err)
+}
+
+// runAAFExport implements "astro aaf export": charts written as an AAF
+// file, from a CSV file or the command line.
+func runAAFExport(args []string) error {
+	fs := flag.NewFlagSet("astro aaf export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro aaf export <file.csv|-> [--output <file>]\n")
+		fmt.Fprintf(fs.Output(), "       astro aaf export <datetime> <lat> <lon> [--name <name>] [--place <place>] [--country <code>] [--dst <hours>] [--output <file>]\n")
+		fmt.Fprintf(fs.Output(), "  Writes charts as an AAF file for Astroplan and other programs: the\n")
+		fmt.Fprintf(fs.Output(), "  rows of a CSV file with the columns astro aaf import writes (datetime,\n")
+		fmt.Fprintf(fs.Output(), "  latitude and longitude are required), or one chart. The offset of each\n")
+		fmt.Fprintf(fs.Output(), "  datetime, less --dst or the dst column, is written as its time zone.\n\n")
+		fs.PrintDefaults()
+	}
+
+	nameFlag := fs.String("name", "", "With a chart on the command line, its name")
+	placeFlag := fs.String("place", "", "With a chart on the command line, its place")
+	countryFlag := fs.String("country", "", "With a chart on the command line, its country")
+	dstFlag := fs.Float64("dst", 0, "With a chart on the command line, the hours of daylight saving time in the datetime's offset")
+	outputFlag := fs.String("output", "", "File to write the AAF to (default stdout)")
+	tz := addZone(fs)
+	tz.coordinates = true
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	var recs []aaf.Record
+	switch len(pos) {
+	case 1:
+		if *nameFlag != "" || *placeFlag != "" || *countryFlag != "" || *dstFlag != 0 {
+			return fmt.Errorf("--name, --place, --country and --dst describe a chart on the command line, not a CSV file")
+		}
+		recs, err = readFile(pos[0], func(r io.Reader) ([]aaf.Record, error) { return readChartsCSV(r, tz) })
+		if err != nil {
+			return err
+		}
+	case 3:
+		rec, err := aafRecord(tz, pos[0], pos[1], pos[2], *dstFlag)
+		if err != nil {
+			return err
+		}
+		rec.Name, rec.Place, rec.Country = *nameFlag, *placeFlag, *countryFlag
+		recs = []aaf.Record{rec}
+	default:
+		fs.Usage()
+		return fmt.Errorf("expected <file.csv> or <datetime> <lat> <lon>, got %d arguments: %s", len(pos), strings.Join(pos, " "))
+	}
+
+	err = writeOutput(*outputFlag, func(w io.Writer) error {
+		return aaf.Write(w, recs)
+	})
+	return internal(err)
+}
+
+// readFile reads the file at path, or stdin if path is "-", with read.
+func readFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	if path == "-" {
+		return read(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+// readChartsCSV reads charts from CSV with a header row naming its
+// columns, as output.AAFCSVHeader does. datetime, latitude and longitude
+// are required; the other columns may be missing or empty.
+func readChartsCSV(r io.Reader, tz *zoneFlag) ([]aaf.Record, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"datetime", "latitude", "longitude"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column", name)
+		}
+	}
+	var recs []aaf.Record
+	for i, row := range rows[1:] {
+		get := func(name string) string {
+			if j, ok := col[name]; ok && j < len(row) {
+				return strings.TrimSpace(row[j])
+			}
+			return ""
+		}
+		dst := 0.0
+		if s := get("dst"); s != "" {
+			if dst, err = strconv.ParseFloat(s, 64); err != nil {
+				return nil, fmt.Errorf("CSV row %d: invalid dst %q: expected hours, e.g. 0 or 1", i+2, s)
+			}
+		}
+		rec, err := aafRecord(tz, get("datetime"), get("latitude"), get("longitude"), dst)
+		if err != nil {
+			return nil, fmt.Errorf("CSV row %d: %w", i+2, err)
+		}
+		rec.Name, rec.FirstName, rec.Sex = get("name"), get("first_name"), get("sex")
+		rec.Place, rec.Country = get("place"), get("country")
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// aafRecord builds an AAF record from a chart as astro takes it. The
+// datetime's offset, less dst hours of daylight saving time, is the
+// record's time zone. A local datetime is read as tz.locate says.
+func aafRecord(tz *zoneFlag, datetime, lat, lon string, dst float64) (aaf.Record, error) {
+	if err := tz.locate(lat, lon, datetime); err != nil {
+		return aaf.Record{}, err
+	}
+	t, err := tz.opts.ParseLocalDateTime(datetime)
+	if err != nil {
+		return aaf.Record{}, err
+	}
+	_, offset := t.Zone()
+	rec := aaf.Record{Time: t.UTC(), DST: time.Duration(dst * float64(time.Hour))}
+	rec.Zone = time.Duration(offset)*time.Second - rec.DST
+	if rec.Lat, err = input.ParseLatitude(lat); err != nil {
+		return aaf.Record{}, err
+	}
+	if rec.Lon, err = input.ParseLongitude(lon); err != nil {
+		return aaf.Record{}, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/almuten"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/sect"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runAlmuten implements "astro almuten": the almuten figuris of a chart, or
+// the almuten of one degree.
+func runAlmuten(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro almuten", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro almuten <datetime> (<lat> <lon> | --place <place>) [--degree <longitude>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Reports the almuten figuris: the planet with the most essential\n")
+		fmt.Fprintf(fs.Output(), "  dignity over the Sun, Moon, Ascendant, Part of Fortune and prenatal\n")
+		fmt.Fprintf(fs.Output(), "  syzygy, with the score of every planet at every point. With --degree,\n")
+		fmt.Fprintf(fs.Output(), "  reports the almuten of that ecliptic longitude instead, using the\n")
+		fmt.Fprintf(fs.Output(), "  chart's sect for the triplicity.\n\n")
+		fs.PrintDefaults()
+	}
+
+	degreeFlag := fs.String("degree", "", "Ecliptic longitude in degrees (0-360) to find the almuten of")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = expandSaved(pos); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected 3 positional arguments (<datetime> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0]); err != nil {
+		return err
+	}
+	t, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	degree := -1.0
+	if *degreeFlag != "" {
+		degree, err = strconv.ParseFloat(*degreeFlag, 64)
+		if err != nil || degree < 0 || degree >= 360 {
+			return fmt.Errorf("invalid --degree %q: must be a longitude from 0 up to 360", *degreeFlag)
+		}
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	jd := ephemeris.JulianDay(t)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	sun, err := p.CalcPlanet(jd, ephemeris.Sun)
+	if err != nil {
+		return fmt.Errorf("error calculating Sun: %w", err)
+	}
+	moon, err := p.CalcPlanet(jd, ephemeris.Moon)
+	if err != nil {
+		return fmt.Errorf("error calculating Moon: %w", err)
+	}
+	h, err := p.CalcHouses(jd, lat, lon, swisseph.HousePlacidus)
+	if err != nil {
+		return fmt.Errorf("error calculating houses: %w", err)
+	}
+	day := sect.Of(sun.Longitude, h.Ascendant) == sect.Day
+
+	var points []almuten.Point
+	if degree >= 0 {
+		points = []almuten.Point{{Name: "Degree", Longitude: degree}}
+	} else {
+		syz, err := almuten.PrenatalSyzygy(p, jd)
+		if err != nil {
+			return err
+		}
+		points = []almuten.Point{
+			{Name: names.Body(ephemeris.Sun), Longitude: sun.Longitude},
+			{Name: names.Body(ephemeris.Moon), Longitude: moon.Longitude},
+			{Name: names.Point(names.Ascendant), Longitude: h.Ascendant},
+			{Name: names.Point(names.Fortune), Longitude: almuten.Fortune(h.Ascendant, sun.Longitude, moon.Longitude, day)},
+			{Name: names.Point(names.Syzygy), Longitude: syz.Longitude},
+		}
+	}
+	rep := output.BuildAlmuten(almuten.Figuris(points, day), day, degree >= 0)
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintAlmutenJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.PrintAlmutenText(rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "almuten", backend)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/astrocartography"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runAstrocartography implements "astro astrocartography": the ASC, DSC,
+// MC and IC lines of each planet for a moment, as GeoJSON, or the parans
+// near one latitude.
+func runAstrocartography(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro astrocartography", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro astrocartography <datetime> [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Prints, as GeoJSON, the lines on which each planet was rising (ASC),\n")
+		fmt.Fprintf(fs.Output(), "  setting (DSC), culminating (MC) or anticulminating (IC) at <datetime>,\n")
+		fmt.Fprintf(fs.Output(), "  and the points where two planets' lines cross. With --parans, lists\n")
+		fmt.Fprintf(fs.Output(), "  instead the crossings within --orb of that latitude.\n\n")
+		fs.PrintDefaults()
+	}
+
+	bodiesFlag := fs.String("bodies", "sun,moon,mercury,venus,mars,jupiter,saturn,uranus,neptune,pluto", "Comma-separated planets to draw")
+	paransFlag := fs.String("parans", "", "List the parans near this latitude instead of printing the map")
+	orbFlag := fs.Float64("orb", 1, "With --parans, the latitude orb in degrees")
+	stepFlag := fs.Float64("step", 1, "Latitude interval in degrees between points on each line")
+	jsonFlag := fs.Bool("json", false, "With --parans, output the list as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected 1 positional argument (<datetime>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	t, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	bodies, err := parseBodies(*bodiesFlag)
+	if err != nil {
+		return err
+	}
+	paranLat, parans := 0.0, *paransFlag != ""
+	if parans {
+		if paranLat, err = input.ParseLatitude(*paransFlag); err != nil {
+			return err
+		}
+	}
+	if *orbFlag < 0 {
+		return fmt.Errorf("--orb must not be negative, got %v", *orbFlag)
+	}
+	if *stepFlag <= 0 || *stepFlag > 10 {
+		return fmt.Errorf("--step must be greater than 0 and at most 10, got %v", *stepFlag)
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	jd := ephemeris.JulianDay(t)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	eps, err := swisseph.Obliquity(jd)
+	if err != nil {
+		return fmt.Errorf("error calculating obliquity: %w", err)
+	}
+	// The ARMC at Greenwich is the Greenwich sidereal time.
+	h, err := p.CalcHouses(jd, 0, 0, swisseph.HouseEqual)
+	if err != nil {
+		return fmt.Errorf("error calculating sidereal time: %w", err)
+	}
+	var planets []astrocartography.Planet
+	for _, body := range bodies {
+		pp, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		ra, dec := swisseph.Cotrans(pp.Longitude, pp.Latitude, -eps)
+		planets = append(planets, astrocartography.Planet{Body: body, RA: ra, Dec: dec})
+	}
+	crossings := astrocartography.Crossings(planets, h.ARMC)
+	rec.Mark("compute")
+
+	switch {
+	case parans:
+		rep := output.BuildParans(t, paranLat, *orbFlag, astrocartography.Parans(crossings, paranLat, *orbFlag))
+		if *jsonFlag {
+			err = output.PrintParansJSON(rep, output.JSONOptions{Compact: *compactFlag})
+		} else {
+			err = output.PrintParansText(rep)
+		}
+	default:
+		lines := astrocartography.Lines(planets, h.ARMC, *stepFlag)
+		err = output.PrintGeoJSON(output.BuildGeoJSON(t, lines, crossings))
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "astrocartography", backend)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dcccxiii/astro/output"
+)
+
+// runAtlas implements "astro atlas": searching the atlas of places that
+// --place draws on.
+func runAtlas(args []string) error {
+	if len(args) > 0 && args[0] == "search" {
+		return runAtlasSearch(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Usage: astro atlas search <query> [flags]   (see astro atlas search --help)\n")
+	if len(args) > 0 && isHelp(args[0]) {
+		return nil
+	}
+	return fmt.Errorf("expected search")
+}
+
+// runAtlasSearch implements "astro atlas search": the places matching a
+// name, with their coordinates and time zone.
+func runAtlasSearch(args []string) error {
+	fs := flag.NewFlagSet("astro atlas search", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro atlas search <query> [--limit <n>] [--json [--compact] | --ndjson]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the places of the atlas matching a name, which may be followed\n")
+		fmt.Fprintf(fs.Output(), "  by the region or country after a comma (\"Springfield, Illinois\"):\n")
+		fmt.Fprintf(fs.Output(), "  those of that name, then those whose name begins with it, then near\n")
+		fmt.Fprintf(fs.Output(), "  misspellings. The first column is the name --place resolves to that\n")
+		fmt.Fprintf(fs.Output(), "  place. Set ASTRO_ATLAS to a GeoNames cities file to search it instead\n")
+		fmt.Fprintf(fs.Output(), "  of the built-in atlas.\n\n")
+		fs.PrintDefaults()
+	}
+
+	limitFlag := fs.Int("limit", 10, "Maximum number of places to list")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per place, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	// An unquoted query such as Berlin, Germany arrives as two arguments.
+	query := strings.Join(pos, " ")
+	if strings.TrimSpace(query) == "" {
+		fs.Usage()
+		return fmt.Errorf("expected a place name to search for")
+	}
+	if *limitFlag < 1 {
+		return fmt.Errorf("invalid --limit %d: expected at least 1", *limitFlag)
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+
+	a, err := loadAtlas()
+	if err != nil {
+		return err
+	}
+	places := output.BuildAtlasPlaces(a.Search(query, *limitFlag))
+
+	switch {
+	case *jsonFlag:
+		err = output.WriteAtlasJSON(os.Stdout, places, output.JSONOptions{Compact: *compactFlag})
+	case *ndjsonFlag:
+		err = output.WriteAtlasNDJSON(os.Stdout, places)
+	default:
+		err = output.WriteAtlasText(os.Stdout, query, places)
+	}
+	return internal(err)
+}
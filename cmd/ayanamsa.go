@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runAyanamsa implements "astro ayanamsa": the values of several
+// ayanamsas at one moment, side by side.
+func runAyanamsa(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro ayanamsa", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro ayanamsa <datetime> [--ayanamsas <list>] [--custom t0=<jd>,value=<degrees>] [--ephemeris <backend>] [--timings] [--json [--compact]]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the ayanamsas, the tropical longitude less the sidereal, at the\n")
+		fmt.Fprintf(fs.Output(), "  datetime, each with its difference from the first.\n\n")
+		fs.PrintDefaults()
+	}
+
+	ayanamsasFlag := fs.String("ayanamsas", "lahiri,fagan-bradley,raman,krishnamurti", "Comma-separated ayanamsas to compare, the first the reference")
+	customFlag := fs.String("custom", "", "Add an ayanamsa of your own: its value in degrees at a Julian Day (TT), e.g. t0=2451545.0,value=24.83")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected 1 positional argument (<datetime>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	t, err := tz.opts.ParseLocalDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	type named struct {
+		a    ayanamsa
+		name string
+	}
+	var modes []named
+	for _, name := range strings.Split(*ayanamsasFlag, ",") {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(strings.ToLower(name), "custom:") {
+			return fmt.Errorf("--ayanamsas takes named ayanamsas; give a custom one with --custom")
+		}
+		a, display, err := parseAyanamsa(name)
+		if err != nil {
+			return err
+		}
+		modes = append(modes, named{a, display})
+	}
+	if *customFlag != "" {
+		a, display, err := parseCustomAyanamsa(strings.ToLower(*customFlag))
+		if err != nil {
+			return err
+		}
+		modes = append(modes, named{a, display})
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	jd := ephemeris.JulianDay(t)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	rep := output.AyanamsaReport{Time: t, JulianDay: jd}
+	for _, m := range modes {
+		m.a.set()
+		deg, err := swisseph.Ayanamsa(jd, backendFlag(backend))
+		if err != nil {
+			return fmt.Errorf("error calculating the %s ayanamsa: %w", m.name, err)
+		}
+		rep.Ayanamsas = append(rep.Ayanamsas, output.SiderealInfo{Ayanamsa: m.name, Degrees: deg})
+	}
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintAyanamsaJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.WriteAyanamsaText(os.Stdout, rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "ayanamsa", backend)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/ephemeris/swiss"
+	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/timing"
+)
+
+const (
+	ephemerisUsage = "Ephemeris backend: swiss (.se1 files), moshier (built in), jpl (de431.eph in ephe/)"
+	timingsUsage   = "Write per-phase durations as JSON to stderr"
+)
+
+// parseBackend validates the --ephemeris flag and returns its canonical name.
+func parseBackend(name string) (string, error) {
+	switch b := strings.ToLower(name); b {
+	case "swiss", "moshier", "jpl":
+		return b, nil
+	default:
+		return "", fmt.Errorf("unknown ephemeris %q: valid values are swiss, moshier, jpl", name)
+	}
+}
+
+// newProvider returns the provider for a backend from parseBackend, with
+// flags (e.g. swisseph.FlagHeliocentric) added to every calculation.
+func newProvider(backend string, flags int) ephemeris.Provider {
+	switch backend {
+	case "moshier":
+		return swiss.MoshierProvider{Flags: flags}
+	case "jpl":
+		return swiss.JPLProvider{Flags: flags}
+	default:
+		return swiss.Provider{Flags: flags}
+	}
+}
+
+// backendFlag returns the swisseph flag selecting backend, for providers
+// such as swiss.CentricProvider that take the choice as a flag.
+func backendFlag(backend string) int {
+	switch backend {
+	case "moshier":
+		return swisseph.FlagMoshier
+	case "jpl":
+		return swisseph.FlagJPL
+	default:
+		return swisseph.FlagSwissEph
+	}
+}
+
+// checkDate returns the error of the first of the Julian Days jds, such as
+// a chart's or the ends of a range, beyond the range of backend's
+// ephemeris, before any position is computed, pointing a date beyond the
+// Moshier ephemeris to the wider range of the files.
+func checkDate(backend string, jds ...float64) error {
+	for _, jd := range jds {
+		err := swisseph.CheckDate(jd, backendFlag(backend))
+		if err != nil && backend == "moshier" && swisseph.CheckDate(jd, swisseph.FlagSwissEph) == nil {
+			return fmt.Errorf("%w; --ephemeris swiss covers the date with the .se1 files", err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRecorder returns a timing.Recorder running from start when --timings
+// is set, and nil (which records nothing) otherwise.
+func newRecorder(on bool, start time.Time) *timing.Recorder {
+	if !on {
+		return nil
+	}
+	return timing.New(start)
+}
+
+// writeCacheStats prints the hits, misses and evictions of cache to stderr
+// as a JSON object, as writeTimings does the timings. A nil cache prints
+// nothing.
+func writeCacheStats(cache *ephemeris.CachedProvider) error {
+	if cache == nil {
+		return nil
+	}
+	data, err := json.Marshal(struct {
+		Cache ephemeris.CacheStats `json:"cache"`
+	}{cache.Stats()})
+	if err == nil {
+		_, err = fmt.Fprintln(os.Stderr, string(data))
+	}
+	return internal(err)
+}
+
+// writeWarnings prints the ephemeris's warnings to stderr for --verbose,
+// one to a line, keeping stdout free for the command's own output.
+func writeWarnings(warnings []string) error {
+	for _, w := range warnings {
+		if _, err := fmt.Fprintf(os.Stderr, "warning: %s\n", w); err != nil {
+			return internal(err)
+		}
+	}
+	return nil
+}
+
+// writeTimings prints the --timings report to stderr, keeping stdout free
+// for the command's own output.
+func writeTimings(rec *timing.Recorder, command, backend string) error {
+	if rec == nil {
+		return nil
+	}
+	return internal(rec.WriteJSON(os.Stderr, command, backend))
+}
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/nodes"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/parallel"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runBatch implements "astro batch": the charts of a CSV or JSON file,
+// computed by parallel workers and written as one stream or a file each.
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("astro batch", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro batch --input <file|-> [--output <file> | --output-dir <dir>] [--format <format>] [--workers <n>] [--cache <n>] [--planets <list>] [--points <list>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Computes the chart of every record of a CSV file with the columns name,\n")
+		fmt.Fprintf(fs.Output(), "  datetime, lat, lon and tz (name and tz may be missing or empty), or of a\n")
+		fmt.Fprintf(fs.Output(), "  JSON array or NDJSON stream of objects with those keys. The charts are\n")
+		fmt.Fprintf(fs.Output(), "  written in input order as NDJSON, one per line, or with --output-dir as\n")
+		fmt.Fprintf(fs.Output(), "  a file each. A record's tz applies to its datetime in place of --tz.\n\n")
+		fs.PrintDefaults()
+	}
+
+	inputFlag := fs.String("input", "", "CSV or JSON file of charts; - reads stdin")
+	dirFlag := fs.String("output-dir", "", "Directory to write each chart to as a file of its own, named by its record number and name (default json)")
+	workersFlag := fs.Int("workers", runtime.NumCPU(), "Number of charts to compute at once")
+	cacheFlag := fs.Int("cache", 0, "Keep up to this many positions and house results in a cache the workers share, and report its hits on stderr (default: no cache)")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	polar := addPolarFallback(fs)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	pointsFlag := fs.String("points", "", pointsUsage)
+	nodesFlag := fs.String("nodes", "none", "Lunar nodes to show: none, mean, true, both")
+	out := addChartOutput(fs)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	tz.coordinates = true
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no positional arguments, got %d: %s; give the charts with --input", len(pos), strings.Join(pos, " "))
+	}
+	if *inputFlag == "" {
+		fs.Usage()
+		return fmt.Errorf("--input is required: a CSV or JSON file of charts, or - for stdin")
+	}
+	if *workersFlag < 1 {
+		return fmt.Errorf("--workers must be at least 1, got %d", *workersFlag)
+	}
+	if *cacheFlag < 0 {
+		return fmt.Errorf("--cache must not be negative, got %d", *cacheFlag)
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if err := out.resolve(); err != nil {
+		return err
+	}
+	if *dirFlag != "" && *out.file != "" {
+		return fmt.Errorf("--output and --output-dir cannot be combined")
+	}
+	if out.resolved == "text" && *out.format == "" {
+		// No format was asked for.
+		out.resolved = "ndjson"
+		if *dirFlag != "" {
+			out.resolved = "json"
+		}
+	}
+	if *dirFlag == "" && out.tmpl == nil && out.resolved != "ndjson" && out.resolved != "oneline" {
+		return fmt.Errorf("astro batch writes one stream as ndjson or oneline; for %s, write a file per chart with --output-dir", out.resolved)
+	}
+
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	nodeBodies, err := parseNodes(*nodesFlag)
+	if err != nil {
+		return err
+	}
+	planets, err := chartBodies(*planetsFlag, nodeBodies)
+	if err != nil {
+		return err
+	}
+	points, err := parsePoints(*pointsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+
+	rows, err := readFile(*inputFlag, func(r io.Reader) ([]batchRow, error) { return readBatch(r, tz) })
+	if err != nil {
+		return err
+	}
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+
+	p := newProvider(backend, 0)
+	var cache *ephemeris.CachedProvider
+	if *cacheFlag > 0 {
+		// Records often share a moment or a place, as a group of
+		// twins or a mundane study does.
+		cache = ephemeris.NewLRUProvider(p, *cacheFlag)
+		p = cache
+	}
+	chart := func(row batchRow) ([]byte, error) {
+		jd := swisseph.JulDayFromTime(row.time)
+		if err := checkDate(backend, jd); err != nil {
+			return nil, err
+		}
+		r, err := polar.build(p, jd, planets, row.lat, row.lon, hsys, hsysName)
+		if err != nil {
+			return nil, err
+		}
+		if err := output.AddPoints(&r, p, points); err != nil {
+			return nil, err
+		}
+		if len(nodeBodies) == 2 {
+			if err := output.AddNodeDivergence(&r, p, nodes.DefaultThreshold); err != nil {
+				return nil, err
+			}
+		}
+		r.Local = row.local
+		r.Metadata = chartMetadata("batch", row.args, backend)
+		r.Metadata.Input.Name = row.name
+		var b bytes.Buffer
+		err = out.print(&b, r)
+		return b.Bytes(), internal(err)
+	}
+	render := func(i int) ([]byte, error) {
+		b, err := chart(rows[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rows[i].where, err)
+		}
+		return b, nil
+	}
+
+	if *dirFlag != "" {
+		if err := os.MkdirAll(*dirFlag, 0o755); err != nil {
+			return err
+		}
+		err = parallel.Ordered(len(rows), *workersFlag, render, func(i int, b []byte) error {
+			name := batchFileName(i, len(rows), rows[i].name, out.extension())
+			return internal(os.WriteFile(filepath.Join(*dirFlag, name), b, 0o644))
+		})
+	} else {
+		err = writeOutput(*out.file, func(w io.Writer) error {
+			return parallel.Ordered(len(rows), *workersFlag, render, func(i int, b []byte) error {
+				_, err := w.Write(b)
+				return internal(err)
+			})
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return writeCacheStats(cache)
+}
+
+// batchRow is a chart of astro batch, with its datetime and coordinates
+// parsed.
+type batchRow struct {
+	where string // "row 2" or "record 1", for errors
+	name  string
+	time  time.Time
+	lat   float64
+	lon   float64
+	local *output.LocalInfo
+	args  []string // the chart's datetime, coordinates and zone, for its metadata
+}
+
+// batchRecord is a chart of astro batch as its input gives it.
+type batchRecord struct {
+	Name     string    `json:"name"`
+	Datetime string    `json:"datetime"`
+	Lat      jsonCoord `json:"lat"`
+	Lon      jsonCoord `json:"lon"`
+	TZ       string    `json:"tz"`
+}
+
+// jsonCoord is a coordinate given in JSON as a number, or as a string in
+// any notation input.ParseLatitude takes, such as "51N30".
+type jsonCoord string
+
+func (c *jsonCoord) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*c = jsonCoord(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("expected a number or a string, got %s", b)
+	}
+	*c = jsonCoord(n)
+	return nil
+}
+
+// readBatch reads the charts of astro batch: a JSON array or NDJSON stream
+// of objects if the input starts with [ or {, and CSV otherwise. Each
+// local datetime is read in its record's tz, or else as tz.locate says.
+func readBatch(r io.Reader, tz *zoneFlag) ([]batchRow, error) {
+	br := bufio.NewReader(r)
+	var recs []batchRecord
+	var where func(i int) string
+	var err error
+	switch first, _ := firstByte(br); first {
+	case '[', '{':
+		recs, err = readBatchJSON(br)
+		where = func(i int) string { return fmt.Sprintf("record %d", i+1) }
+	default:
+		recs, err = readBatchCSV(br)
+		where = func(i int) string { return fmt.Sprintf("row %d", i+2) }
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]batchRow, len(recs))
+	for i, rec := range recs {
+		if rows[i], err = parseBatchRecord(rec, tz); err != nil {
+			return nil, fmt.Errorf("%s: %w", where(i), err)
+		}
+		rows[i].where = where(i)
+	}
+	return rows, nil
+}
+
+// firstByte returns the first byte of br that is not white space, leaving
+// it unread.
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// readBatchJSON reads a JSON array of chart objects, or a stream of them.
+func readBatchJSON(r io.Reader) ([]batchRecord, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var recs []batchRecord
+	for {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err == io.EOF {
+			return recs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read JSON: %w", err)
+		}
+		var more []batchRecord
+		if bytes.HasPrefix(v, []byte("[")) {
+			if err := strictJSON(v, &more); err != nil {
+				return nil, fmt.Errorf("could not read JSON: %w", err)
+			}
+		} else {
+			var rec batchRecord
+			if err := strictJSON(v, &rec); err != nil {
+				return nil, fmt.Errorf("could not read JSON record %d: %w", len(recs)+1, err)
+			}
+			more = []batchRecord{rec}
+		}
+		recs = append(recs, more...)
+	}
+}
+
+// strictJSON decodes b into v, rejecting keys v has no field for, so that
+// a misspelt key is not silently ignored.
+func strictJSON(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// readBatchCSV reads charts from CSV with a header row naming its columns.
+// datetime, lat and lon are required, and may also be called latitude and
+// longitude, as astro aaf import writes them.
+func readBatchCSV(r io.Reader) ([]batchRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "latitude":
+			name = "lat"
+		case "longitude":
+			name = "lon"
+		}
+		col[name] = i
+	}
+	for _, name := range []string{"datetime", "lat", "lon"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column", name)
+		}
+	}
+	recs := make([]batchRecord, len(rows)-1)
+	for i, row := range rows[1:] {
+		get := func(name string) string {
+			if j, ok := col[name]; ok && j < len(row) {
+				return strings.TrimSpace(row[j])
+			}
+			return ""
+		}
+		recs[i] = batchRecord{Name: get("name"), Datetime: get("datetime"), Lat: jsonCoord(get("lat")), Lon: jsonCoord(get("lon")), TZ: get("tz")}
+	}
+	return recs, nil
+}
+
+// parseBatchRecord parses a record's datetime and coordinates. A local
+// datetime is read in the record's tz if it has one, and else in --tz or
+// the zone at the coordinates.
+func parseBatchRecord(rec batchRecord, tz *zoneFlag) (batchRow, error) {
+	zone := rec.TZ
+	if zone == "" {
+		zone = *tz.name
+	}
+	z := &zoneFlag{name: &zone, defaultTime: tz.defaultTime, coordinates: true}
+	if err := z.apply(); err != nil {
+		return batchRow{}, err
+	}
+	lat, lon := string(rec.Lat), string(rec.Lon)
+	if err := z.locate(lat, lon, rec.Datetime); err != nil {
+		return batchRow{}, err
+	}
+	row := batchRow{name: rec.Name, local: z.local(rec.Datetime), args: []string{rec.Datetime, lat, lon}}
+	if zone != "" {
+		row.args = append(row.args, "--tz", zone)
+	}
+	var err error
+	if row.time, err = z.opts.ParseDateTime(rec.Datetime); err != nil {
+		return batchRow{}, err
+	}
+	if row.lat, row.lon, err = input.ParseCoordinates(lat, lon); err != nil {
+		return batchRow{}, err
+	}
+	return row, nil
+}
+
+// batchFileName returns the name of the file of the i'th of n charts under
+// --output-dir: its record number, padded so that the files sort in input
+// order, and its name made safe for a file name.
+func batchFileName(i, n int, name, ext string) string {
+	width := len(fmt.Sprint(n))
+	base := fmt.Sprintf("%0*d", width, i+1)
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if slug.Len() > 0 {
+		base += "-" + slug.String()
+	}
+	return base + ext
+}
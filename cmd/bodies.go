@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// bodyNames maps the lower-case names accepted on the command line to body IDs.
+var bodyNames = map[string]int{
+	"sun":     swisseph.Sun,
+	"moon":    swisseph.Moon,
+	"mercury": swisseph.Mercury,
+	"venus":   swisseph.Venus,
+	"mars":    swisseph.Mars,
+	"jupiter": swisseph.Jupiter,
+	"saturn":  swisseph.Saturn,
+	"uranus":  swisseph.Uranus,
+	"neptune": swisseph.Neptune,
+	"pluto":   swisseph.Pluto,
+}
+
+// minorBodyNames maps the names of the bodies beyond the planets that a
+// list of bodies may also name.
+var minorBodyNames = map[string]int{
+	"chiron":    swisseph.Chiron,
+	"pholus":    swisseph.Pholus,
+	"ceres":     swisseph.Ceres,
+	"pallas":    swisseph.Pallas,
+	"juno":      swisseph.Juno,
+	"vesta":     swisseph.Vesta,
+	"mean-node": swisseph.MeanNode,
+	"true-node": swisseph.TrueNode,
+}
+
+// bodySets are the named sets of bodies a list may give: classical, the
+// planets of a traditional chart; modern, with the outer planets; and all,
+// with Chiron, Pholus and the four main asteroids too.
+var bodySets = map[string][]int{
+	"classical": {swisseph.Sun, swisseph.Moon, swisseph.Mercury, swisseph.Venus, swisseph.Mars, swisseph.Jupiter, swisseph.Saturn},
+	"modern":    {swisseph.Sun, swisseph.Moon, swisseph.Mercury, swisseph.Venus, swisseph.Mars, swisseph.Jupiter, swisseph.Saturn, swisseph.Uranus, swisseph.Neptune, swisseph.Pluto},
+	"all": {swisseph.Sun, swisseph.Moon, swisseph.Mercury, swisseph.Venus, swisseph.Mars, swisseph.Jupiter, swisseph.Saturn, swisseph.Uranus, swisseph.Neptune, swisseph.Pluto,
+		swisseph.Chiron, swisseph.Pholus, swisseph.Ceres, swisseph.Pallas, swisseph.Juno, swisseph.Vesta},
+}
+
+// planetsUsage describes the --planets flag of the commands that cast a
+// chart.
+const planetsUsage = "Bodies to compute: classical, modern or all, or a comma-separated list such as sun,moon,mars..pluto,chiron,ceres,433 (an asteroid number)"
+
+func parseBody(name string) (int, error) {
+	if id, ok := bodyNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("unknown body %q: valid values are sun, moon, mercury, venus, mars, jupiter, saturn, uranus, neptune, pluto", name)
+}
+
+// parseBodies parses a comma-separated list of bodies, leaving out any
+// repeats. An item is a planet, a minor body such as chiron or mean-node,
+// the number of an asteroid such as 433 for Eros, or a set of bodySets. An
+// item a..b stands for the planets from a to b in the order sun, moon,
+// mercury, ..., pluto, e.g. sun..pluto for all of them.
+func parseBodies(s string) ([]int, error) {
+	var ids []int
+	add := func(id int) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, item := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(item, "..")
+		if !isRange {
+			more, err := parseListedBody(item)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range more {
+				add(id)
+			}
+			continue
+		}
+		first, err := parseBody(from)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parseBody(to)
+		if err != nil {
+			return nil, err
+		}
+		if last < first {
+			return nil, fmt.Errorf("invalid body range %q: %s comes after %s", strings.TrimSpace(item), strings.TrimSpace(from), strings.TrimSpace(to))
+		}
+		for id := first; id <= last; id++ {
+			add(id)
+		}
+	}
+	return ids, nil
+}
+
+// parseListedBody parses an item of a list of bodies other than a range.
+func parseListedBody(item string) ([]int, error) {
+	name := strings.ToLower(strings.TrimSpace(item))
+	if id, ok := bodyNames[name]; ok {
+		return []int{id}, nil
+	}
+	if id, ok := minorBodyNames[name]; ok {
+		return []int{id}, nil
+	}
+	if set, ok := bodySets[name]; ok {
+		return set, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return []int{swisseph.AstOffset + n}, nil
+	}
+	return nil, fmt.Errorf("unknown body %q: valid values are sun, moon, mercury, venus, mars, jupiter, saturn, uranus, neptune, pluto, chiron, pholus, ceres, pallas, juno, vesta, mean-node, true-node, the number of an asteroid such as 433, or classical, modern or all", item)
+}
+
+// chartBodies returns the bodies of a chart: those --planets lists, then
+// the nodes --nodes asks for that it does not list.
+func chartBodies(planets string, nodes []int) ([]int, error) {
+	bodies, err := parseBodies(planets)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range nodes {
+		if !slices.Contains(bodies, id) {
+			bodies = append(bodies, id)
+		}
+	}
+	return bodies, nil
+}
+
+// nameAsteroids names the numbered asteroids among bodies as their
+// ephemeris files do, e.g. Eros for 433, unless the names in use already
+// name them. Asteroids whose file is missing keep their number. The
+// ephemeris path must be set.
+func nameAsteroids(bodies []int) {
+	for _, id := range bodies {
+		if id <= swisseph.AstOffset || names.Body(id) != ephemeris.BodyName(id) {
+			continue
+		}
+		if name := swisseph.PlanetName(id); name != "" && !strings.Contains(name, "not found") {
+			names.Default.SetBody(id, name)
+		}
+	}
+}
+
+// pointNames maps the names --points accepts to the chart points they add.
+// node adds the mean North Node and the South Node opposite as points.
+var pointNames = map[string]string{
+	"vertex":          names.Vertex,
+	"east-point":      names.EastPoint,
+	"co-ascendant":    names.CoAscendant,
+	"polar-ascendant": names.PolarAscendant,
+	"node":            names.NorthNode,
+	"lilith":          names.Lilith,
+	"fortune":         names.Fortune,
+	"cupido":          names.Cupido,
+	"hades":           names.Hades,
+	"zeus":            names.Zeus,
+	"kronos":          names.Kronos,
+	"apollon":         names.Apollon,
+	"admetos":         names.Admetos,
+	"vulkanus":        names.Vulkanus,
+	"poseidon":        names.Poseidon,
+	"transpluto":      names.Transpluto,
+}
+
+// pointSets are the named sets of chart points --points may give: uranian,
+// the eight hypothetical planets of the Hamburg school and Transpluto.
+var pointSets = map[string][]string{
+	"uranian": {names.Cupido, names.Hades, names.Zeus, names.Kronos, names.Apollon, names.Admetos, names.Vulkanus, names.Poseidon, names.Transpluto},
+}
+
+// pointsUsage describes the --points flag of the commands that cast a
+// chart.
+const pointsUsage = "Chart points to add, placed in houses and counted in aspect patterns: a comma-separated list of vertex, east-point, co-ascendant, polar-ascendant, node, lilith, fortune, the Uranian planets cupido to poseidon and transpluto, or uranian for all nine (default none)"
+
+// parsePoints parses a comma-separated list of chart points into the
+// names point keys of output.AddPoints, leaving out any repeats.
+func parsePoints(s string) ([]string, error) {
+	var keys []string
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		name := strings.ToLower(strings.TrimSpace(item))
+		more, ok := pointSets[name]
+		if key, found := pointNames[name]; found {
+			more, ok = []string{key}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown chart point %q: valid values are vertex, east-point, co-ascendant, polar-ascendant, node, lilith, fortune, cupido, hades, zeus, kronos, apollon, admetos, vulkanus, poseidon, transpluto, uranian", item)
+		}
+		for _, key := range more {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
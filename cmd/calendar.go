@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/transits"
+)
+
+const defaultCalendarBodies = "sun,moon,mercury,venus,mars,jupiter,saturn,uranus,neptune,pluto"
+
+// runCalendar implements "astro calendar": the lunations, eclipses,
+// ingresses and stations of a month, day by day, and the exact transits to
+// a natal chart.
+func runCalendar(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro calendar", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro calendar [<YYYY-MM>] [--chart <chart>] [--format text|markdown|ics|json] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists, day by day, the month's new, quarter and full moons and the\n")
+		fmt.Fprintf(fs.Output(), "  eclipses among them, the planets' sign ingresses, and their stations.\n")
+		fmt.Fprintf(fs.Output(), "  With --chart, adds the exact transits to that natal chart. The days\n")
+		fmt.Fprintf(fs.Output(), "  and times are those of --tz, else UTC; the month defaults to this one.\n\n")
+		fs.PrintDefaults()
+	}
+
+	chartFlag := fs.String("chart", "", "Natal chart to list the exact transits to: <datetime>,<lat>,<lon> or a saved chart's name")
+	bodiesFlag := fs.String("bodies", defaultCalendarBodies, "Comma-separated bodies whose ingresses, stations and transits to list; the Moon's transits are left out")
+	aspectsFlag := fs.String("aspects", "all", "Aspects of the transits: all, or any of conjunction, sextile, square, trine, opposition")
+	formatFlag := fs.String("format", "text", "Output format: text, markdown (md), ics (iCalendar, for calendar applications) or json")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON; short for --format json")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one month (<YYYY-MM>), got %d arguments: %s", len(pos), strings.Join(pos, " "))
+	}
+	// The calendar keeps the zone of --tz; a local natal datetime may
+	// change tz.opts.Zone for its own reading.
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	now := time.Now().In(loc)
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if len(pos) == 1 {
+		m, err := time.Parse("2006-01", pos[0])
+		if err != nil {
+			return &input.Error{Kind: "month", Value: pos[0], Reason: "expected YYYY-MM, e.g. 2025-07"}
+		}
+		first = time.Date(m.Year(), m.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	format := *formatFlag
+	if *jsonFlag {
+		format = "json"
+	}
+	switch format {
+	case "text", "markdown", "md", "ics", "json":
+	default:
+		return &input.Error{Kind: "format", Value: format, Reason: "must be text, markdown, ics or json"}
+	}
+	bodies, err := parseBodies(*bodiesFlag)
+	if err != nil {
+		return err
+	}
+	as, err := aspects.Parse(*aspectsFlag)
+	if err != nil {
+		return err
+	}
+	var natal *chartSpec
+	if *chartFlag != "" {
+		specs, err := parseChartSpecs(tz, []string{*chartFlag}, 1)
+		if err != nil {
+			return err
+		}
+		natal = &specs[0]
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	from, to := ephemeris.JulianDay(first), ephemeris.JulianDay(first.AddDate(0, 1, 0))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	sky, err := mundane.Scan(p, bodies, from, to)
+	if err != nil {
+		return err
+	}
+	var natalJD float64
+	var hits []transits.Event
+	if natal != nil {
+		natalJD = ephemeris.JulianDay(natal.Time)
+		points, err := natalPoints(p, natalJD, &[2]float64{natal.Lat, natal.Lon})
+		if err != nil {
+			return err
+		}
+		movers := slices.DeleteFunc(slices.Clone(bodies), func(b int) bool { return b == ephemeris.Moon })
+		if hits, err = transits.Scan(p, movers, points, as, from, to); err != nil {
+			return err
+		}
+	}
+	cal := output.BuildCalendar(first, natalJD, sky, hits)
+	rec.Mark("compute")
+
+	switch format {
+	case "markdown", "md":
+		err = output.WriteCalendarMarkdown(os.Stdout, cal)
+	case "ics":
+		err = output.WriteCalendarICS(os.Stdout, cal, time.Now())
+	case "json":
+		err = output.PrintCalendarJSON(cal, output.JSONOptions{Compact: *compactFlag})
+	default:
+		err = output.WriteCalendarText(os.Stdout, cal)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "calendar", backend)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/wheel"
+)
+
+// compactUsage describes the --compact flag of every command with JSON
+// output.
+const compactUsage = "With JSON output, write it on one line without indentation"
+
+// chartOutput holds the output flags shared by the commands that print a
+// chart: the main chart, return and composite.
+type chartOutput struct {
+	json, yaml, compact, glyphs, oneline *bool
+	format, file, templateFile           *string
+
+	// sources lists the ephemeris that computed each planet in the text
+	// output, for --verbose.
+	sources bool
+
+	// Set by resolve.
+	resolved string
+	tmpl     *template.Template
+}
+
+// addChartOutput defines the chart output flags on fs.
+func addChartOutput(fs *flag.FlagSet) *chartOutput {
+	return &chartOutput{
+		json:         fs.Bool("json", false, "Output results as JSON"),
+		yaml:         fs.Bool("yaml", false, "Output results as YAML, with the same structure as --json"),
+		compact:      fs.Bool("compact", false, compactUsage),
+		format:       fs.String("format", "", "Output format: text, oneline, json, ndjson (one line), yaml, markdown, csv, or svg or png for a wheel (default from the --output extension, else text)"),
+		file:         fs.String("output", "", "File to write the results to (default stdout)"),
+		templateFile: fs.String("template", "", "Render the chart through this Go text/template file instead"),
+		glyphs:       fs.Bool("glyphs", false, "Show planet and sign glyphs in text output, if the terminal's locale is UTF-8"),
+		oneline:      fs.Bool("oneline", false, "Print the chart on one line, e.g. for a status bar; shorthand for --format oneline"),
+	}
+}
+
+// resolve checks the flags once they are parsed, working out the format
+// and parsing any template.
+func (o *chartOutput) resolve() error {
+	if *o.templateFile != "" {
+		if *o.json || *o.yaml || *o.oneline || *o.format != "" {
+			return fmt.Errorf("--template cannot be combined with --format, --json, --yaml or --oneline")
+		}
+		t, err := output.ParseTemplate(*o.templateFile)
+		if err != nil {
+			return err
+		}
+		o.tmpl = t
+		return nil
+	}
+	if *o.oneline {
+		if *o.json || *o.yaml {
+			return fmt.Errorf("--oneline cannot be combined with --json or --yaml")
+		}
+		if f := strings.ToLower(*o.format); f != "" && f != "oneline" {
+			return fmt.Errorf("--oneline and --format %s cannot be combined", *o.format)
+		}
+		o.resolved = "oneline"
+		return nil
+	}
+	f, err := chartFormat(*o.format, *o.file, *o.json, *o.yaml)
+	o.resolved = f
+	return err
+}
+
+// write writes the chart to the --output file or stdout.
+func (o *chartOutput) write(r output.Result) error {
+	return writeOutput(*o.file, func(w io.Writer) error { return o.print(w, r) })
+}
+
+// print writes the chart to w in the format resolve chose, or through the
+// template. svg and png draw it as a wheel.
+func (o *chartOutput) print(w io.Writer, r output.Result) error {
+	if o.tmpl != nil {
+		return output.WriteTemplate(w, o.tmpl, r)
+	}
+	switch o.resolved {
+	case "json":
+		return output.WriteJSON(w, r, output.JSONOptions{Compact: *o.compact})
+	case "ndjson":
+		return output.NewNDJSON(w).WriteChart(r)
+	case "yaml":
+		return output.WriteYAML(w, r)
+	case "markdown":
+		return output.WriteMarkdown(w, r)
+	case "csv":
+		return output.WriteCSV(w, r)
+	case "svg", "png":
+		d := wheel.Draw(output.Wheel(r), defaultWheelSize, wheel.Light)
+		if o.resolved == "png" {
+			return d.PNG(w)
+		}
+		return d.SVG(w)
+	}
+	// Files get glyphs as asked; stdout only if the terminal can show them.
+	glyphs := *o.glyphs && (*o.file != "" || unicodeLocale(os.Getenv))
+	if o.resolved == "oneline" {
+		return output.WriteOneLine(w, r, output.TextOptions{Glyphs: glyphs})
+	}
+	return output.WriteText(w, r, output.TextOptions{Glyphs: glyphs, Sources: o.sources})
+}
+
+// chartMetadata returns the metadata a chart command reports with its
+// JSON: the backend, the library version and the command line, whose args
+// follow the command name.
+func chartMetadata(command string, args []string, backend string) *output.Metadata {
+	return &output.Metadata{
+		Ephemeris:       backend,
+		SwissEphVersion: swisseph.Version(),
+		Input:           &output.InputInfo{Command: command, Args: args},
+	}
+}
+
+// unicodeLocale reports whether the environment, read through getenv,
+// describes a terminal that can show the astrological glyphs: a UTF-8
+// locale, by the first of LC_ALL, LC_CTYPE and LANG that is set, and a
+// terminal other than the Linux console or a dumb one.
+func unicodeLocale(getenv func(string) string) bool {
+	switch getenv("TERM") {
+	case "dumb", "linux":
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// chartFormat resolves a chart command's --format flag and its --json and
+// --yaml shorthands to one of text, oneline, json, ndjson, yaml, markdown,
+// csv, svg or png. Without any of them, the format follows the extension of the
+// --output file, and is text for stdout or an unknown extension.
+func chartFormat(format, file string, asJSON, asYAML bool) (string, error) {
+	short := ""
+	switch {
+	case asJSON && asYAML:
+		return "", fmt.Errorf("--json and --yaml cannot be combined")
+	case asJSON:
+		short = "json"
+	case asYAML:
+		short = "yaml"
+	}
+	switch f := strings.ToLower(format); f {
+	case "":
+		if short != "" {
+			return short, nil
+		}
+		return formatOfFile(file), nil
+	case "text", "oneline", "json", "ndjson", "yaml", "markdown", "md", "csv", "svg", "png":
+		if f == "md" {
+			f = "markdown"
+		}
+		if short != "" && short != f {
+			return "", fmt.Errorf("--%s and --format %s cannot be combined", short, format)
+		}
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q: valid values are text, oneline, json, ndjson, yaml, markdown, csv, svg, png", format)
+}
+
+// formatOfFile returns the chart format implied by file's extension.
+func formatOfFile(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".md", ".markdown":
+		return "markdown"
+	case ".csv":
+		return "csv"
+	case ".svg":
+		return "svg"
+	case ".png":
+		return "png"
+	}
+	return "text"
+}
+
+// extension returns the file extension of the chosen format, the inverse
+// of formatOfFile.
+func (o *chartOutput) extension() string {
+	switch o.resolved {
+	case "json", "ndjson", "yaml", "csv", "svg", "png":
+		return "." + o.resolved
+	case "markdown":
+		return ".md"
+	}
+	return ".txt"
+}
+
+// writeOutput calls write with the file at path, created or truncated, or
+// with stdout if path is empty.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/input"
+)
+
+// chartSpec is a birth moment and place given on the command line.
+type chartSpec struct {
+	Time     time.Time
+	Lat, Lon float64
+}
+
+// parseChartSpecs parses n charts, each given as <datetime>,<lat>,<lon>, as
+// three separate arguments, or as the name of a saved chart; the forms may
+// be mixed. A local datetime is read as tz.locate says for its chart's
+// coordinates.
+func parseChartSpecs(tz *zoneFlag, pos []string, n int) ([]chartSpec, error) {
+	pos, err := expandSaved(pos)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, tok := range pos {
+		for _, f := range strings.Split(tok, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				fields = append(fields, f)
+			}
+		}
+	}
+	if len(fields) != 3*n {
+		return nil, fmt.Errorf("expected %d charts, each <datetime>,<lat>,<lon>, <datetime> <lat> <lon> or the name of a saved chart; got %q", n, strings.Join(pos, " "))
+	}
+
+	specs := make([]chartSpec, n)
+	for i := range specs {
+		f := fields[3*i : 3*i+3]
+		if err := tz.locate(f[1], f[2], f[0]); err != nil {
+			return nil, err
+		}
+		var err error
+		if specs[i].Time, err = tz.opts.ParseDateTime(f[0]); err != nil {
+			return nil, err
+		}
+		if specs[i].Lat, specs[i].Lon, err = input.ParseCoordinates(f[1], f[2]); err != nil {
+			return nil, err
+		}
+	}
+	return specs, nil
+}
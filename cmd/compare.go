@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dcccxiii/astro/input"
+)
+
+// runCompare implements "astro compare": the synastry, composite or
+// Davison chart of two charts saved with astro save.
+func runCompare(args []string) error {
+	if len(args) == 0 || isHelp(args[0]) {
+		fmt.Fprintf(os.Stderr, "Usage: astro compare <name> <name> [--mode synastry|composite|davison] [flags]\n")
+		fmt.Fprintf(os.Stderr, "  Compares two charts saved with astro save: --mode synastry (the\n")
+		fmt.Fprintf(os.Stderr, "  default) as astro synastry does, composite as astro composite does,\n")
+		fmt.Fprintf(os.Stderr, "  and davison as astro composite --method davison does. The other\n")
+		fmt.Fprintf(os.Stderr, "  flags are those of that command. The chart store is\n")
+		fmt.Fprintf(os.Stderr, "  %s.\n", chartsPathUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("expected two chart names")
+		}
+		return nil
+	}
+	if len(args) < 2 || isFlag(args[1]) {
+		return fmt.Errorf("expected two chart names, as in astro compare Alice Bob")
+	}
+	mode, rest, err := takeFlag(args[2:], "mode", "synastry")
+	if err != nil {
+		return err
+	}
+	run := runSynastry
+	switch mode {
+	case "synastry":
+	case "composite":
+		run = runComposite
+	case "davison":
+		run = runComposite
+		rest = append(rest, "--method", "davison")
+	default:
+		return &input.Error{Kind: "comparison mode", Value: mode, Reason: "must be synastry, composite or davison"}
+	}
+	for _, name := range args[:2] {
+		c, err := savedChart(name)
+		if err != nil {
+			return err
+		}
+		rest = append(rest, strings.Join(chartArgs(c), ","))
+	}
+	return run(rest)
+}
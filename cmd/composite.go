@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dcccxiii/astro/composite"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/ephemeris/swiss"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/timing"
+)
+
+// runComposite implements "astro composite": the midpoint composite of two
+// natal charts, or their Davison chart, rendered like an ordinary chart.
+func runComposite(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro composite", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro composite <chartA> <chartB> [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Each chart is <datetime>,<lat>,<lon>, or three separate arguments.\n")
+		fmt.Fprintf(fs.Output(), "  Every planet is placed at the midpoint of its positions in A and B.\n")
+		fmt.Fprintf(fs.Output(), "  The composite MC is the midpoint of the two MCs; the Ascendant and\n")
+		fmt.Fprintf(fs.Output(), "  other cusps are cast from it at the reference --latitude.\n")
+		fmt.Fprintf(fs.Output(), "  With --method davison, the chart is instead cast for the moment\n")
+		fmt.Fprintf(fs.Output(), "  halfway between the births, at the place halfway between them.\n\n")
+		fs.PrintDefaults()
+	}
+
+	methodFlag := fs.String("method", "midpoint", "Composite method: midpoint (midpoints of the positions) or davison (chart of the midpoint in time and place)")
+	latitudeFlag := fs.String("latitude", "", "Reference latitude for the composite houses; default the midpoint of the birth latitudes")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	out := addChartOutput(fs)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	tz.coordinates = true
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if err := out.resolve(); err != nil {
+		return err
+	}
+
+	davison := false
+	switch *methodFlag {
+	case "midpoint":
+	case "davison":
+		davison = true
+		if *latitudeFlag != "" {
+			return fmt.Errorf("--latitude applies to midpoint composites; a Davison chart is cast at the midpoint of the birth places")
+		}
+	default:
+		return &input.Error{Kind: "composite method", Value: *methodFlag, Reason: "must be midpoint or davison"}
+	}
+
+	specs, err := parseChartSpecs(tz, pos, 2)
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	refLat := (specs[0].Lat + specs[1].Lat) / 2
+	if *latitudeFlag != "" {
+		if refLat, err = input.ParseLatitude(*latitudeFlag); err != nil {
+			return err
+		}
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	a := composite.Moment{JD: ephemeris.JulianDay(specs[0].Time), Lat: specs[0].Lat, Lon: specs[0].Lon}
+	b := composite.Moment{JD: ephemeris.JulianDay(specs[1].Time), Lat: specs[1].Lat, Lon: specs[1].Lon}
+	if err := checkDate(backend, a.JD, b.JD); err != nil {
+		return err
+	}
+	if davison {
+		m := composite.Davison(a, b)
+		r, err := output.Build(rec.Wrap(newProvider(backend, 0)), m.JD, chartPlanets, m.Lat, m.Lon, hsys, hsysName)
+		if err != nil {
+			return err
+		}
+		r.Composite = &output.CompositeInfo{A: chartRef(specs[0]), B: chartRef(specs[1]), ReferenceLatitude: m.Lat, Method: "davison"}
+		return writeComposite(r, out, rec, args, backend)
+	}
+	midJD := (a.JD + b.JD) / 2
+	eps, err := swisseph.Obliquity(midJD)
+	if err != nil {
+		return fmt.Errorf("error calculating obliquity: %w", err)
+	}
+	c := composite.Provider{
+		P:         rec.Wrap(newProvider(backend, 0)),
+		A:         a,
+		B:         b,
+		Obliquity: eps,
+		Houses:    swiss.HousesARMC,
+	}
+	// The midpoint longitude only labels the chart; composite houses
+	// depend on the reference latitude alone. It is the Davison chart's.
+	midLon := composite.Davison(a, b).Lon
+
+	r, err := output.Build(c, midJD, chartPlanets, refLat, midLon, hsys, hsysName)
+	if err != nil {
+		return err
+	}
+	r.Composite = &output.CompositeInfo{A: chartRef(specs[0]), B: chartRef(specs[1]), ReferenceLatitude: refLat, Method: "midpoint"}
+	return writeComposite(r, out, rec, args, backend)
+}
+
+// writeComposite renders the composite r, as "astro composite" does.
+func writeComposite(r output.Result, out *chartOutput, rec *timing.Recorder, args []string, backend string) error {
+	rec.Mark("compute")
+
+	r.Metadata = chartMetadata("composite", args, backend)
+	if err := out.write(r); err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "composite", backend)
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/cycles"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+const defaultCyclePairs = "jupiter-saturn,jupiter-uranus,jupiter-neptune,jupiter-pluto," +
+	"saturn-uranus,saturn-neptune,saturn-pluto,uranus-neptune,uranus-pluto,neptune-pluto"
+
+// runCycles implements "astro cycles": a timeline of outer-planet cycle
+// phases over a span of years.
+func runCycles(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro cycles", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro cycles [--from <year>] [--to <year>] [--pairs <a-b,...>] [--phases <list>] [--ephemeris <backend>] [--timings] [--json [--compact] | --ndjson]\n")
+		fmt.Fprintf(fs.Output(), "  Lists every exact conjunction, waxing square, opposition and waning square\n")
+		fmt.Fprintf(fs.Output(), "  between the chosen planet pairs, in chronological order.\n\n")
+		fs.PrintDefaults()
+	}
+
+	fromFlag := fs.Int("from", 1900, "First year to scan")
+	toFlag := fs.Int("to", 2100, "Last year to scan (inclusive)")
+	pairsFlag := fs.String("pairs", defaultCyclePairs, "Comma-separated planet pairs, e.g. jupiter-saturn,saturn-pluto")
+	phasesFlag := fs.String("phases", "all", "Phases to list: all, or any of conjunction, waxing-square, opposition, waning-square")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per phase, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d", fs.NArg())
+	}
+	if *toFlag < *fromFlag {
+		return fmt.Errorf("--to year %d is before --from year %d", *toFlag, *fromFlag)
+	}
+
+	pairs, err := parsePairs(*pairsFlag)
+	if err != nil {
+		return err
+	}
+	phases, err := parsePhases(*phasesFlag)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+
+	from := swisseph.JulDay(*fromFlag, 1, 1, 0)
+	to := swisseph.JulDay(*toFlag+1, 1, 1, 0)
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	p := rec.Wrap(newProvider(backend, 0))
+	rec.Mark("parse")
+
+	var events []cycles.Event
+	for _, pair := range pairs {
+		found, err := cycles.Scan(p, pair[0], pair[1], from, to, 0)
+		if err != nil {
+			return err
+		}
+		for _, e := range found {
+			if phases[e.Phase.Name] {
+				events = append(events, e)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].JD < events[j].JD })
+
+	tl := output.BuildCycles(from, to, events)
+	rec.Mark("compute")
+
+	switch {
+	case *jsonFlag:
+		err = output.PrintCyclesJSON(tl, output.JSONOptions{Compact: *compactFlag})
+	case *ndjsonFlag:
+		err = output.PrintNDJSON(tl.Entries)
+	default:
+		err = output.PrintCyclesText(tl)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "cycles", backend)
+}
+
+// parsePairs parses "jupiter-saturn,saturn-pluto" into body ID pairs.
+func parsePairs(s string) ([][2]int, error) {
+	var pairs [][2]int
+	for _, item := range strings.Split(s, ",") {
+		a, b, ok := strings.Cut(strings.TrimSpace(item), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid planet pair %q: expected <planet>-<planet>, e.g. jupiter-saturn", item)
+		}
+		ida, err := parseBody(a)
+		if err != nil {
+			return nil, err
+		}
+		idb, err := parseBody(b)
+		if err != nil {
+			return nil, err
+		}
+		if ida == idb {
+			return nil, fmt.Errorf("invalid planet pair %q: planets must differ", item)
+		}
+		if ida < swisseph.Mars || idb < swisseph.Mars {
+			return nil, fmt.Errorf("invalid planet pair %q: cycles are supported for mars through pluto", item)
+		}
+		pairs = append(pairs, [2]int{ida, idb})
+	}
+	return pairs, nil
+}
+
+// parsePhases parses the --phases flag into a set of cycles.Phase names.
+func parsePhases(s string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	if strings.ToLower(s) == "all" {
+		for _, ph := range cycles.Phases {
+			set[ph.Name] = true
+		}
+		return set, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(item)), "-", " ")
+		found := false
+		for _, ph := range cycles.Phases {
+			if ph.Name == name {
+				set[name], found = true, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown phase %q: valid values are all, conjunction, waxing-square, opposition, waning-square", item)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/vedic"
+)
+
+// runDasha implements "astro dasha": the Vimshottari dasha timeline from
+// the sidereal Moon of a natal chart.
+func runDasha(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro dasha", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro dasha <chart> [--levels <n>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  <chart> is <datetime> or <datetime>,<lat>,<lon>; only the moment\n")
+		fmt.Fprintf(fs.Output(), "  matters. Lists the Vimshottari mahadashas from birth, starting with the\n")
+		fmt.Fprintf(fs.Output(), "  balance of the dasha of the Moon's nakshatra lord, with antardashas\n")
+		fmt.Fprintf(fs.Output(), "  (--levels 2) and pratyantardashas (--levels 3).\n\n")
+		fs.PrintDefaults()
+	}
+
+	levelsFlag := fs.Int("levels", 2, "Depth of the timeline: 1 mahadashas, 2 antardashas, 3 pratyantardashas")
+	siderealFlag := fs.String("sidereal", "lahiri", "Ayanamsa: lahiri, fagan-bradley, raman, krishnamurti, or custom:t0=<jd>,value=<degrees>")
+	atFlag := fs.String("at", "", "Mark the periods in force at this datetime (RFC 3339); default now")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	tz.coordinates = true
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	natal, err := parseChartMoment(tz, pos)
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	if *levelsFlag < 1 || *levelsFlag > 3 {
+		return fmt.Errorf("--levels must be 1, 2 or 3, got %d", *levelsFlag)
+	}
+	sidMode, sidName, err := parseAyanamsa(*siderealFlag)
+	if err != nil {
+		return err
+	}
+	at := time.Now().UTC()
+	if *atFlag != "" {
+		if at, err = tz.opts.ParseDateTime(*atFlag); err != nil {
+			return err
+		}
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	sidMode.set()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, swisseph.FlagSidereal))
+	jd := ephemeris.JulianDay(natal)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	moon, err := p.CalcPlanet(jd, ephemeris.Moon)
+	if err != nil {
+		return fmt.Errorf("error calculating %s: %w", p.PlanetName(ephemeris.Moon), err)
+	}
+	aya, err := swisseph.Ayanamsa(jd, backendFlag(backend))
+	if err != nil {
+		return fmt.Errorf("error calculating ayanamsa: %w", err)
+	}
+	dashas := vedic.Vimshottari(natal, moon.Longitude, *levelsFlag)
+	tl := output.BuildDasha(natal, output.SiderealInfo{Ayanamsa: sidName, Degrees: aya}, moon.Longitude, at, dashas)
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintDashaJSON(tl, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.PrintDashaText(tl)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "dasha", backend)
+}
+
+// parseChartMoment parses a chart given as <datetime> alone or as a full
+// chart (see parseChartSpecs), for commands that need only the moment.
+func parseChartMoment(tz *zoneFlag, pos []string) (time.Time, error) {
+	pos, err := expandSaved(pos)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(pos) == 1 && !strings.Contains(pos[0], ",") {
+		if tz.meanTime() && tz.opts.Zone == nil {
+			return time.Time{}, &input.Error{Kind: "time zone", Value: *tz.name, Reason: "local mean time needs a longitude: give the chart as <datetime>,<lat>,<lon>"}
+		}
+		return tz.opts.ParseDateTime(pos[0])
+	}
+	specs, err := parseChartSpecs(tz, pos, 1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return specs[0].Time, nil
+}
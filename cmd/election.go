@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/election"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+)
+
+// runElection implements "astro election": a search of a date range for
+// moments meeting electional criteria.
+func runElection(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro election", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro election <from> <to> (<lat> <lon> | --place <place>) --where <criteria> | --criteria <file> [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Samples the sky over the range and lists the windows in which every\n")
+		fmt.Fprintf(fs.Output(), "  required criterion holds, best first. Criteria are separated by commas\n")
+		fmt.Fprintf(fs.Output(), "  or new lines, e.g. \"moon not voc, moon waxing, jupiter angular, asc\n")
+		fmt.Fprintf(fs.Output(), "  ruler benefic\". Criteria starting with \"prefer [<weight>]\" are not\n")
+		fmt.Fprintf(fs.Output(), "  required; windows meeting more of them rank higher. A --criteria file\n")
+		fmt.Fprintf(fs.Output(), "  holds the same text, or a JSON array of criteria.\n\n")
+		fs.PrintDefaults()
+	}
+
+	whereFlag := fs.String("where", "", "Criteria, e.g. \"moon not voc, jupiter angular, prefer venus in house 1,10\"")
+	criteriaFlag := fs.String("criteria", "", "File of criteria, as text or a JSON array (- for stdin)")
+	stepFlag := fs.Float64("step", 10, "Sampling interval in minutes")
+	limitFlag := fs.Int("limit", 10, "Number of windows to list (0 for all)")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per window, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 2); err != nil {
+		return err
+	}
+	if len(pos) != 4 {
+		fs.Usage()
+		return fmt.Errorf("expected 4 positional arguments (<from> <to> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[2], pos[3])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[2], pos[3], pos[0], pos[1]); err != nil {
+		return err
+	}
+	from, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	to, err := tz.opts.ParseDateTime(pos[1])
+	if err != nil {
+		return err
+	}
+	if !to.After(from) {
+		return fmt.Errorf("<to> %s must be after <from> %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	criteria, err := loadCriteria(*whereFlag, *criteriaFlag)
+	if err != nil {
+		return err
+	}
+	if *stepFlag <= 0 {
+		return fmt.Errorf("--step must be positive, got %v", *stepFlag)
+	}
+	if *limitFlag < 0 {
+		return fmt.Errorf("--limit must not be negative, got %d", *limitFlag)
+	}
+	hsys, _, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	fromJD, toJD := ephemeris.JulianDay(from), ephemeris.JulianDay(to)
+	if err := checkDate(backend, fromJD, toJD); err != nil {
+		return err
+	}
+	step := *stepFlag / (24 * 60)
+	matches, err := election.Search(p, criteria, fromJD, toJD, step, lat, lon, hsys)
+	if err != nil {
+		return err
+	}
+	rep := output.BuildElection(criteria, matches, fromJD, toJD, step, lat, lon, *limitFlag)
+	rec.Mark("search")
+
+	switch {
+	case *jsonFlag:
+		err = output.PrintElectionJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	case *ndjsonFlag:
+		err = output.PrintNDJSON(rep.Matches)
+	default:
+		err = output.PrintElectionText(rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "election", backend)
+}
+
+// loadCriteria parses the criteria given inline with --where or in a
+// --criteria file. A file whose text starts with "[" is read as JSON.
+func loadCriteria(where, file string) ([]election.Criterion, error) {
+	switch {
+	case where != "" && file != "":
+		return nil, fmt.Errorf("--where and --criteria cannot be combined")
+	case where != "":
+		return election.Parse(where)
+	case file == "":
+		return nil, fmt.Errorf("no criteria: give --where or --criteria")
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read criteria: %w", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return election.ParseJSON(data)
+	}
+	return election.Parse(string(data))
+}
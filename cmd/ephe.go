@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runEphe implements "astro ephe": the files of the ephemeris directory.
+func runEphe(args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		return runEpheList(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Usage: astro ephe list [flags]   (see astro ephe list --help)\n")
+	if len(args) > 0 && isHelp(args[0]) {
+		return nil
+	}
+	return fmt.Errorf("expected list")
+}
+
+// runEpheList implements "astro ephe list": the numbered-asteroid files
+// in the ephemeris directory, with the asteroids they hold.
+func runEpheList(args []string) error {
+	fs := flag.NewFlagSet("astro ephe list", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro ephe list [--json [--compact]]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the numbered-asteroid files, such as se00433s.se1 for Eros, in\n")
+		fmt.Fprintf(fs.Output(), "  the ephemeris directory ephe/ beside astro and in its astN\n")
+		fmt.Fprintf(fs.Output(), "  subdirectories, with the asteroid each holds. --planets takes an\n")
+		fmt.Fprintf(fs.Output(), "  asteroid listed by its number.\n\n")
+		fs.PrintDefaults()
+	}
+
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no positional arguments, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+
+	dir, err := epheDir()
+	if err != nil {
+		return err
+	}
+	files, err := swisseph.ListAsteroids(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no ephemeris directory %s", dir)
+	}
+	if err != nil {
+		return err
+	}
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+
+	rep := output.EpheReport{Directory: dir, Asteroids: []output.EpheAsteroid{}}
+	for _, f := range files {
+		id := swisseph.AstOffset + f.Number
+		name := swisseph.PlanetName(id)
+		if name == "" || strings.Contains(name, "not found") {
+			name = ephemeris.BodyName(id)
+		}
+		rep.Asteroids = append(rep.Asteroids, output.EpheAsteroid{Number: f.Number, Name: name, File: f.Path, Short: f.Short, Used: f.Used})
+	}
+
+	if *jsonFlag {
+		err = output.WriteEpheJSON(os.Stdout, rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.WriteEpheText(os.Stdout, rep)
+	}
+	return internal(err)
+}
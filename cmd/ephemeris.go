@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/wheel"
+)
+
+// maxEphemerisRows bounds the table, which a step of a few minutes over
+// years would otherwise make enormous.
+const maxEphemerisRows = 100000
+
+// Size of the graphic ephemeris, in pixels.
+const (
+	graphWidth  = 1200
+	graphHeight = 700
+)
+
+// runEphemeris implements "astro ephemeris": a table of planetary positions
+// at regular steps over a range of dates.
+func runEphemeris(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro ephemeris", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro ephemeris [--from <date|datetime>] [--to <date|datetime>] [--step <duration>] [--planets <list>] [--format <format> | --json [--compact] | --ndjson] [--output <file>] [--ephemeris <backend>] [--workers <n>] [--timings]\n")
+		fmt.Fprintf(fs.Output(), "       astro ephemeris --format svg|png [--modulus 360|90|45] [--natal <datetime>] [--theme light|dark] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Prints the longitude of each planet every step from --from up to and\n")
+		fmt.Fprintf(fs.Output(), "  including --to, like a printed ephemeris. In text, R marks a retrograde\n")
+		fmt.Fprintf(fs.Output(), "  planet and * one that entered its sign since the previous row. As svg\n")
+		fmt.Fprintf(fs.Output(), "  or png, draws a graphic ephemeris: longitude against time, optionally\n")
+		fmt.Fprintf(fs.Output(), "  folded to a 90° or 45° modulus, with any natal planets across it.\n\n")
+		fs.PrintDefaults()
+	}
+
+	fromFlag := fs.String("from", "", "First date (YYYY-MM-DD, midnight UTC) or datetime (RFC 3339); default today")
+	toFlag := fs.String("to", "", "Last date or datetime, inclusive; default one month after --from")
+	stepFlag := fs.String("step", "1d", "Interval between rows, e.g. 1d, 12h or 1w")
+	planetsFlag := fs.String("planets", "sun..pluto", "Comma-separated planets, or ranges such as sun..saturn")
+	formatFlag := fs.String("format", "", "Output format: text, csv, json, ndjson (one row per line), or svg or png for a graphic ephemeris (default from the --output extension, else text)")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per row, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	outputFlag := fs.String("output", "", "File to write the table to (default stdout)")
+	modulusFlag := fs.Float64("modulus", 360, "With svg or png, the range of the longitude axis: 360, 90 or 45")
+	natalFlag := fs.String("natal", "", "With svg or png, draw the planets of the chart at this datetime across the graph")
+	themeFlag := fs.String("theme", "light", "With svg or png, the colour theme: light or dark")
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	workersFlag := fs.Int("workers", runtime.NumCPU(), "Number of rows to compute at once")
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d", fs.NArg())
+	}
+
+	if *workersFlag < 1 {
+		return fmt.Errorf("--workers must be at least 1, got %d", *workersFlag)
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	short := ""
+	switch {
+	case *jsonFlag:
+		short = "json"
+	case *ndjsonFlag:
+		short = "ndjson"
+	}
+	format := strings.ToLower(*formatFlag)
+	switch {
+	case format == "" && short != "":
+		format = short
+	case format == "":
+		format = formatOfFile(*outputFlag)
+	case short != "" && short != format:
+		return fmt.Errorf("--%s and --format %s cannot be combined", short, *formatFlag)
+	}
+	graphic := format == "svg" || format == "png"
+	switch format {
+	case "text", "csv", "json", "ndjson", "svg", "png":
+	default:
+		return fmt.Errorf("astro ephemeris cannot write %s: valid formats are text, csv, json, ndjson, svg, png", format)
+	}
+	if !graphic && (*modulusFlag != 360 || *natalFlag != "") {
+		return fmt.Errorf("--modulus and --natal need --format svg or png")
+	}
+	switch *modulusFlag {
+	case 360, 90, 45:
+	default:
+		return fmt.Errorf("invalid --modulus %g: valid values are 360, 90, 45", *modulusFlag)
+	}
+	theme, err := wheel.ParseTheme(*themeFlag)
+	if err != nil {
+		return err
+	}
+	var natal *time.Time
+	if *natalFlag != "" {
+		at, err := parseChartMoment(tz, []string{*natalFlag})
+		if err != nil {
+			return fmt.Errorf("invalid --natal: %w", err)
+		}
+		natal = &at
+	}
+
+	step, err := input.ParseDuration(*stepFlag)
+	if err != nil {
+		return err
+	}
+	if step <= 0 {
+		return fmt.Errorf("--step must be positive, got %s", *stepFlag)
+	}
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if *fromFlag != "" {
+		if from, err = parseDateOrTime(*fromFlag, tz.opts); err != nil {
+			return err
+		}
+	}
+	to := from.AddDate(0, 1, 0)
+	if *toFlag != "" {
+		if to, err = parseDateOrTime(*toFlag, tz.opts); err != nil {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to %s is before --from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	if rows := to.Sub(from)/step + 1; rows > maxEphemerisRows {
+		return fmt.Errorf("--step %s gives %d rows from %s to %s, more than the limit of %d: use a longer step or a shorter range",
+			*stepFlag, rows, from.Format(time.RFC3339), to.Format(time.RFC3339), maxEphemerisRows)
+	}
+	bodies, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	fromJD, toJD, stepDays := ephemeris.JulianDay(from), ephemeris.JulianDay(to), step.Hours()/24
+	if err := checkDate(backend, fromJD, toJD); err != nil {
+		return err
+	}
+	if format == "ndjson" {
+		// Each row is written as soon as it is computed, so compute and
+		// render are timed as one.
+		if err := writeEphemerisRows(*outputFlag, p, bodies, fromJD, toJD, stepDays, *workersFlag); err != nil {
+			return err
+		}
+		rec.Mark("compute")
+		return writeTimings(rec, "ephemeris", backend)
+	}
+	table, err := output.BuildEphemeris(p, bodies, fromJD, toJD, stepDays, *workersFlag)
+	if err != nil {
+		return err
+	}
+	var graph wheel.Drawing
+	if graphic {
+		var chart *output.Result
+		if natal != nil {
+			r, err := output.BuildSky(p, ephemeris.JulianDay(*natal), bodies)
+			if err != nil {
+				return err
+			}
+			chart = &r
+		}
+		graph = wheel.DrawGraph(output.EphemerisGraph(table, *modulusFlag, chart), graphWidth, graphHeight, theme)
+	}
+	rec.Mark("compute")
+
+	err = writeOutput(*outputFlag, func(w io.Writer) error {
+		switch format {
+		case "csv":
+			return output.WriteEphemerisCSV(w, table)
+		case "json":
+			return output.WriteEphemerisJSON(w, table, output.JSONOptions{Compact: *compactFlag})
+		case "svg":
+			return graph.SVG(w)
+		case "png":
+			return graph.PNG(w)
+		default:
+			return output.WriteEphemerisText(w, table)
+		}
+	})
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "ephemeris", backend)
+}
+
+// writeEphemerisRows writes the rows of an ephemeris to the file path, or
+// stdout if it is empty, as NDJSON, each as soon as it is computed. An
+// error of the calculation is returned as it is, and one of writing as
+// internal.
+func writeEphemerisRows(path string, p ephemeris.Provider, bodies []int, from, to, step float64, workers int) error {
+	var calcErr error
+	err := writeOutput(path, func(w io.Writer) error {
+		n := output.NewNDJSON(w)
+		var writeErr error
+		err := output.EphemerisRows(p, bodies, from, to, step, workers, func(row output.EphemerisRow) error {
+			writeErr = n.Write(row)
+			return writeErr
+		})
+		if writeErr == nil {
+			calcErr = err
+			return nil
+		}
+		return writeErr
+	})
+	if err != nil {
+		return internal(err)
+	}
+	return calcErr
+}
+
+// parseDateOrTime parses a date (YYYY-MM-DD), taken as midnight UTC, or
+// a datetime as opts read it.
+func parseDateOrTime(s string, opts input.Options) (time.Time, error) {
+	if len(s) == len("2006-01-02") {
+		return input.ParseDate(s)
+	}
+	return opts.ParseDateTime(s)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// Exit codes of the astro command, by the kind of error that stopped it.
+const (
+	ExitInternal  = 1 // a failure of the program itself, e.g. writing the output
+	ExitInput     = 2 // bad arguments, flags or input files
+	ExitEphemeris = 3 // the ephemeris could not compute a position
+)
+
+// Error codes of the JSON error object, one per exit code.
+const (
+	CodeInternal  = "internal"
+	CodeInput     = "invalid_input"
+	CodeEphemeris = "ephemeris"
+)
+
+// internalError marks an error that is not the user's doing.
+type internalError struct{ err error }
+
+func (e internalError) Error() string { return e.err.Error() }
+func (e internalError) Unwrap() error { return e.err }
+
+// internal marks err, unless nil, as an internal error. Commands mark the
+// errors of rendering their results, after the computation succeeded.
+func internal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return internalError{err}
+}
+
+// Classify returns the error code and exit code for an error from Run.
+// Library failures, including house systems that cannot be cast within a
+// polar circle, are ephemeris errors, errors marked by internal are
+// internal, and everything else, from a malformed date to an unknown flag,
+// is bad input.
+func Classify(err error) (code string, exit int) {
+	var se *swisseph.Error
+	var pe *swisseph.PolarError
+	var ie internalError
+	var te template.ExecError
+	switch {
+	case errors.As(err, &se), errors.As(err, &pe):
+		return CodeEphemeris, ExitEphemeris
+	case errors.As(err, &te):
+		// The user's template failed, not the program.
+		return CodeInput, ExitInput
+	case errors.As(err, &ie):
+		return CodeInternal, ExitInternal
+	}
+	return CodeInput, ExitInput
+}
+
+// Main runs the command and reports an error on stderr: as a JSON object
+// if args ask for JSON output, else as a line of text. It returns the
+// process exit code.
+func Main(args []string) int {
+	return report(os.Stderr, args, Run(args))
+}
+
+// report writes the error, if any, of the command run with args to w, as
+// Main does, and returns the exit code.
+func report(w io.Writer, args []string, err error) int {
+	if err == nil {
+		return 0
+	}
+	code, exit := Classify(err)
+	if jsonOutput(args) {
+		writeErrorJSON(w, err, code, exit)
+	} else {
+		fmt.Fprintln(w, err)
+	}
+	return exit
+}
+
+// errorJSON is the object Main writes for an error, under an "error" key.
+type errorJSON struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+	// For a rejected input value, as parsed by the input package.
+	Field      string `json:"field,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func writeErrorJSON(w io.Writer, err error, code string, exit int) {
+	e := errorJSON{Code: code, Message: err.Error(), ExitCode: exit}
+	var ie *input.Error
+	if errors.As(err, &ie) {
+		e.Field, e.Value, e.Suggestion = ie.Kind, ie.Value, ie.Suggestion
+	}
+	data, _ := json.Marshal(struct {
+		Error errorJSON `json:"error"`
+	}{e})
+	fmt.Fprintln(w, string(data))
+}
+
+// jsonOutput reports whether args ask for JSON output: --json, --ndjson or
+// --format json or ndjson, before any "--".
+func jsonOutput(args []string) bool {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		switch name {
+		case "json", "ndjson":
+			if on, err := strconv.ParseBool(value); !hasValue || (err == nil && on) {
+				return true
+			}
+		case "format":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			if v := strings.ToLower(value); v == "json" || v == "ndjson" {
+				return true
+			}
+		}
+	}
+	return false
+}
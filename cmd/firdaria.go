@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/firdaria"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/sect"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runFirdaria implements "astro firdaria": the timeline of firdaria periods
+// for a natal chart.
+func runFirdaria(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro firdaria", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro firdaria <natal-datetime> (<lat> <lon> | --place <place>) [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the firdaria major periods and sub-periods with their rulers and\n")
+		fmt.Fprintf(fs.Output(), "  dates. The birthplace decides the sect: a day birth (Sun above the\n")
+		fmt.Fprintf(fs.Output(), "  horizon) starts with the Sun, a night birth with the Moon.\n\n")
+		fs.PrintDefaults()
+	}
+
+	atFlag := fs.String("at", "", "Mark the periods in force at this datetime (RFC 3339); default now")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = expandSaved(pos); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected 3 positional arguments (<natal-datetime> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0], *atFlag); err != nil {
+		return err
+	}
+	natal, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	at := time.Now().UTC()
+	if *atFlag != "" {
+		if at, err = tz.opts.ParseDateTime(*atFlag); err != nil {
+			return err
+		}
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	jd := ephemeris.JulianDay(natal)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	sun, err := p.CalcPlanet(jd, ephemeris.Sun)
+	if err != nil {
+		return fmt.Errorf("error calculating %s: %w", p.PlanetName(ephemeris.Sun), err)
+	}
+	// The Ascendant does not depend on the house system.
+	h, err := p.CalcHouses(jd, lat, lon, swisseph.HouseEqual)
+	if err != nil {
+		return fmt.Errorf("error calculating houses: %w", err)
+	}
+	day := sect.Of(sun.Longitude, h.Ascendant) == sect.Day
+	tl := output.BuildFirdaria(natal, day, at, firdaria.Periods(natal, day))
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintFirdariaJSON(tl, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.PrintFirdariaText(tl)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "firdaria", backend)
+}
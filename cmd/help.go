@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dcccxiii/astro/input"
+)
+
+// command is a subcommand of astro. Each parses its own flags and prints
+// its own help with --help.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands are the subcommands of astro, in the order astro help lists
+// them. "help" itself is handled by Run.
+var commands = []command{
+	{"chart", "a chart for a moment and place (the default command)", runChart},
+	{"return", "a solar or planetary return chart", runReturn},
+	{"composite", "the midpoint composite of two charts", runComposite},
+	{"synastry", "inter-aspects, house overlays and an aspect grid of two charts", runSynastry},
+	{"transits", "transits to a natal chart over a range, or in orb at a moment", runTransits},
+	{"wheel", "the chart drawn as a wheel, as SVG or PNG", runWheel},
+	{"watch", "the chart of the moment, redrawn on a timer", runWatch},
+	{"sky", "the planets now, the Moon's phase and course, and the day's aspects", runSky},
+	{"moon", "the new, quarter and full moons of a year, and its eclipses", runMoon},
+	{"retrogrades", "the retrograde periods of a year, with their shadows", runRetrogrades},
+	{"ingresses", "the moments the planets enter the signs in a year, the equinoxes and solstices among them", runIngresses},
+	{"seasons", "the charts of the equinoxes and solstices of a year, for a place", runSeasons},
+	{"calendar", "a month's lunations, eclipses, ingresses and stations, and transits", runCalendar},
+	{"hours", "the planetary day and hours for a date and place", runHours},
+	{"election", "moments in a range that meet electional criteria", runElection},
+	{"rectify", "the candidate birth times of a date, ranked against life events", runRectify},
+	{"almuten", "the almuten figuris of a chart, or of one degree", runAlmuten},
+	{"firdaria", "the firdaria periods of a natal chart", runFirdaria},
+	{"dasha", "the Vimshottari dasha timeline of a natal chart", runDasha},
+	{"ayanamsa", "the values of several ayanamsas at a moment, side by side", runAyanamsa},
+	{"ephemeris", "a table of positions at regular steps over a range", runEphemeris},
+	{"cycles", "outer-planet cycle phases over a span of years", runCycles},
+	{"nodes", "periods when the true and mean nodes diverge", runNodes},
+	{"astrocartography", "planetary angle lines on the globe, or parans", runAstrocartography},
+	{"batch", "the charts of a CSV or JSON file, computed in parallel", runBatch},
+	{"save", "save a chart under a name, for the other commands to take", runSave},
+	{"show", "the chart saved under a name, or the list of saved charts", runShow},
+	{"compare", "the synastry, composite or Davison chart of two saved charts", runCompare},
+	{"aaf", "import and export of AAF chart files", runAAF},
+	{"atlas", "search the atlas of places that --place draws on", runAtlas},
+	{"ephe", "list the numbered-asteroid files of the ephemeris directory", runEphe},
+}
+
+// lookupCommand returns the subcommand named name.
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// isHelp reports whether arg asks for help.
+func isHelp(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help", "help":
+		return true
+	}
+	return false
+}
+
+// runHelp implements "astro help": the list of commands, or with a
+// command name, that command's help.
+func runHelp(args []string) error {
+	switch len(args) {
+	case 0:
+		printUsage(os.Stdout)
+		return nil
+	case 1:
+		c, ok := lookupCommand(args[0])
+		if !ok {
+			return unknownCommand(args[0])
+		}
+		return c.run([]string{"--help"})
+	}
+	return fmt.Errorf("expected at most one command, got %d: %s", len(args), strings.Join(args, " "))
+}
+
+// printUsage writes the list of commands to w.
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: astro <command> [arguments] [flags]\n")
+	fmt.Fprintf(w, "       astro [flags] <datetime> (<lat> <lon> | --place <place>)   (astro chart)\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	width := 0
+	for _, c := range commands {
+		width = max(width, len(c.name))
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s  %s\n", pad(c.name, width), c.summary)
+	}
+	fmt.Fprintf(w, "\nRun astro help <command> or astro <command> --help for its arguments and flags.\n")
+	fmt.Fprintf(w, "astro --stdio serves the commands as JSON-RPC methods on stdin and stdout.\n")
+}
+
+// pad right-pads s with spaces to width bytes.
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-len(s)))
+}
+
+// isCommandWord reports whether arg looks like a command name rather than
+// the datetime of a chart: a lower-case word that is no datetime.
+func isCommandWord(arg string) bool {
+	if arg == "" || arg[0] < 'a' || arg[0] > 'z' || strings.Trim(arg, "abcdefghijklmnopqrstuvwxyz-") != "" {
+		return false
+	}
+	_, err := input.ParseDateTime(arg)
+	return err != nil
+}
+
+// unknownCommand returns the error for an unknown command name, suggesting
+// the command it is closest to.
+func unknownCommand(name string) error {
+	best, dist := "", 3
+	for _, c := range commands {
+		if d := editDistance(name, c.name); d < dist || strings.HasPrefix(c.name, name) && len(name) >= 3 && best == "" {
+			best, dist = c.name, d
+		}
+	}
+	if best != "" {
+		return fmt.Errorf("unknown command %q (did you mean %s?); run astro help for the list", name, best)
+	}
+	return fmt.Errorf("unknown command %q; run astro help for the list", name)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/hours"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runHours implements "astro hours": the planetary day and hours for a date
+// and place.
+func runHours(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro hours", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro hours <date|datetime> (<lat> <lon> | --place <place>) [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the 24 planetary hours of the day, from sunrise to the next\n")
+		fmt.Fprintf(fs.Output(), "  sunrise, with the day ruler. Given a datetime rather than a date\n")
+		fmt.Fprintf(fs.Output(), "  (YYYY-MM-DD), it uses the planetary day containing that moment and\n")
+		fmt.Fprintf(fs.Output(), "  reports the hour ruling it.\n\n")
+		fs.PrintDefaults()
+	}
+
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected 3 positional arguments (<date|datetime> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0]); err != nil {
+		return err
+	}
+	var date, moment time.Time
+	if len(pos[0]) == len("2006-01-02") {
+		date, err = input.ParseDate(pos[0])
+	} else {
+		moment, err = tz.opts.ParseDateTime(pos[0])
+	}
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	// Local midnight, by mean solar time at the longitude, or the moment.
+	jd := ephemeris.JulianDay(date) - lon/360
+	if !moment.IsZero() {
+		jd = ephemeris.JulianDay(moment)
+	}
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	var day hours.Day
+	if moment.IsZero() {
+		day, err = planetaryDay(jd, lat, lon, backendFlag(backend))
+	} else {
+		day, err = planetaryDayAt(jd, lat, lon, backendFlag(backend))
+	}
+	if err != nil {
+		return err
+	}
+	rep := output.BuildHours(day, lat, lon, moment)
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintHoursJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.PrintHoursText(rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "hours", backend)
+}
+
+// planetaryDay returns the planetary day beginning at the first sunrise
+// after jd.
+func planetaryDay(jd, lat, lon float64, flags int) (hours.Day, error) {
+	rise, err := sunEvent(jd, lat, lon, swisseph.CalcRise, flags)
+	if err != nil {
+		return hours.Day{}, err
+	}
+	set, err := sunEvent(rise, lat, lon, swisseph.CalcSet, flags)
+	if err != nil {
+		return hours.Day{}, err
+	}
+	next, err := sunEvent(set, lat, lon, swisseph.CalcRise, flags)
+	if err != nil {
+		return hours.Day{}, err
+	}
+	// The weekday is that of the local date at sunrise.
+	return hours.Compute(rise, set, next, localTime(rise, lon).Weekday()), nil
+}
+
+// planetaryDayAt returns the planetary day containing jd, which began at
+// the last sunrise at or before it.
+func planetaryDayAt(jd, lat, lon float64, flags int) (hours.Day, error) {
+	d, err := planetaryDay(jd-1, lat, lon, flags)
+	if err != nil {
+		return hours.Day{}, err
+	}
+	if d.NextSunrise <= jd {
+		return planetaryDay(d.NextSunrise-1e-4, lat, lon, flags)
+	}
+	if d.Sunrise > jd {
+		return planetaryDay(jd-2, lat, lon, flags)
+	}
+	return d, nil
+}
+
+// sunEvent finds the next sunrise or sunset after jd.
+func sunEvent(jd, lat, lon float64, event, flags int) (float64, error) {
+	t, err := swisseph.RiseTrans(jd, swisseph.Sun, lat, lon, event, flags)
+	if errors.Is(err, swisseph.ErrNoRiseSet) {
+		return 0, noHoursError{lat: lat, date: localTime(jd, lon).Format("2006-01-02")}
+	}
+	return t, err
+}
+
+// noHoursError explains that a day has no planetary hours. It wraps
+// swisseph.ErrNoRiseSet.
+type noHoursError struct {
+	lat  float64
+	date string
+}
+
+func (e noHoursError) Error() string {
+	return fmt.Sprintf("the Sun does not rise and set at latitude %.4f° on %s, so there are no planetary hours", e.lat, e.date)
+}
+
+func (noHoursError) Unwrap() error { return swisseph.ErrNoRiseSet }
+
+// hourRuler returns the ruler of the planetary hour containing jd, or -1
+// if the Sun does not rise and set there that day.
+func hourRuler(jd, lat, lon float64, flags int) (int, error) {
+	d, err := planetaryDayAt(jd, lat, lon, flags)
+	if errors.Is(err, swisseph.ErrNoRiseSet) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	h, ok := d.At(jd)
+	if !ok {
+		return -1, nil
+	}
+	return h.Ruler, nil
+}
+
+// localTime returns the local mean time at longitude lon for jd, as a
+// time.Time whose clock reads local time.
+func localTime(jd, lon float64) time.Time {
+	return ephemeris.TimeOf(jd).Add(time.Duration(lon / 15 * float64(time.Hour)))
+}
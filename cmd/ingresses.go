@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+)
+
+// allIngressBodies are the planets whose ingresses --all lists: all but the
+// Moon, which changes sign every two or three days.
+const allIngressBodies = "sun,mercury..pluto"
+
+// runIngresses implements "astro ingresses": the moments the planets enter
+// the signs in a year.
+func runIngresses(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro ingresses", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro ingresses [--planet <list> | --all] [--year <year>] [--format text|ics|json] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the exact moments in the year that each planet enters a sign,\n")
+		fmt.Fprintf(fs.Output(), "  by default the Sun's, whose entries into the cardinal signs are the\n")
+		fmt.Fprintf(fs.Output(), "  equinoxes and solstices. A planet re-entering a sign retrograde is\n")
+		fmt.Fprintf(fs.Output(), "  marked so. The year and times are those of --tz, else UTC.\n\n")
+		fs.PrintDefaults()
+	}
+
+	planetFlag := fs.String("planet", "sun", "Comma-separated planets whose ingresses to list")
+	allFlag := fs.Bool("all", false, "List the ingresses of the Sun and the planets Mercury to Pluto")
+	yearFlag := fs.Int("year", 0, "Year to list (default this one)")
+	formatFlag := fs.String("format", "text", "Output format: text, ics (iCalendar, for calendar applications) or json")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON; short for --format json")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	planets := *planetFlag
+	if *allFlag {
+		if given["planet"] {
+			return fmt.Errorf("--planet and --all cannot be combined")
+		}
+		planets = allIngressBodies
+	}
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	year := time.Now().In(loc).Year()
+	if given["year"] {
+		if year = *yearFlag; year < -5000 || year > 5000 {
+			return &input.Error{Kind: "year", Value: fmt.Sprint(year), Reason: "must be within 5000 years of year 0"}
+		}
+	}
+	format := *formatFlag
+	if *jsonFlag {
+		format = "json"
+	}
+	switch format {
+	case "text", "ics", "json":
+	default:
+		return &input.Error{Kind: "format", Value: format, Reason: "must be text, ics or json"}
+	}
+	bodies, err := parseBodies(planets)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(bodies)
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	from, to := ephemeris.JulianDay(first), ephemeris.JulianDay(first.AddDate(1, 0, 0))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	var events []mundane.Event
+	for _, body := range bodies {
+		found, err := mundane.Ingresses(p, body, from, to)
+		if err != nil {
+			return err
+		}
+		events = append(events, found...)
+	}
+	y := output.BuildIngressYear(year, loc, events)
+	rec.Mark("compute")
+
+	switch format {
+	case "ics":
+		err = output.WriteIngressYearICS(os.Stdout, y, time.Now())
+	case "json":
+		err = output.PrintIngressYearJSON(y, output.JSONOptions{Compact: *compactFlag})
+	default:
+		err = output.WriteIngressYearText(os.Stdout, y)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "ingresses", backend)
+}
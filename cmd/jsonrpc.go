@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when it has no
+// id.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse answers a request: its Result, or its Error.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error object of a response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// rpcHandler answers the method of a request with its params, which may be
+// empty. The result must not be nil.
+type rpcHandler func(method string, params json.RawMessage) (any, *rpcError)
+
+// maxRPCMessage bounds the length of one message, a line of input.
+const maxRPCMessage = 16 << 20
+
+// serveRPC reads JSON-RPC messages from in, one a line, and writes the
+// response to each request to out as a line, until the end of in. A line
+// holding an array is a batch, answered with an array. Notifications are
+// passed to handle but get no response.
+func serveRPC(in io.Reader, out io.Writer, handle rpcHandler) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64<<10), maxRPCMessage)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var reply any
+		if line[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(line, &batch); err != nil || len(batch) == 0 {
+				reply = rpcFailure(nil, rpcInvalidRequest, "invalid batch: expected a non-empty array of requests")
+			} else {
+				var replies []rpcResponse
+				for _, msg := range batch {
+					if r := dispatchRPC(msg, handle); r != nil {
+						replies = append(replies, *r)
+					}
+				}
+				if len(replies) > 0 {
+					reply = replies
+				}
+			}
+		} else if r := dispatchRPC(line, handle); r != nil {
+			reply = r
+		}
+		if reply == nil {
+			continue
+		}
+		if err := enc.Encode(reply); err != nil {
+			return fmt.Errorf("error writing response: %w", err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("error reading requests: %w", err)
+	}
+	return nil
+}
+
+// dispatchRPC handles one message, returning the response, or nil for a
+// notification.
+func dispatchRPC(msg []byte, handle rpcHandler) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		if !json.Valid(msg) {
+			return rpcFailure(nil, rpcParseError, fmt.Sprintf("parse error: %v", err))
+		}
+		return rpcFailure(nil, rpcInvalidRequest, "invalid request: expected an object with jsonrpc, method and id")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, rpcInvalidRequest, `invalid request: expected "jsonrpc": "2.0" and a method`)
+	}
+	result, rerr := handle(req.Method, req.Params)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if rerr != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// rpcFailure returns an error response to the request with id, which is
+// null when the request could not be read.
+func rpcFailure(id json.RawMessage, code int, message string) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
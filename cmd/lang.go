@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dcccxiii/astro/names"
+)
+
+// langFlags holds the --lang and --names flags, which every command
+// accepts to translate the names in its output.
+type langFlags struct {
+	lang, file *string
+}
+
+// addLang defines the language flags on fs.
+func addLang(fs *flag.FlagSet) *langFlags {
+	return &langFlags{
+		lang: fs.String("lang", "", fmt.Sprintf("Language of sign, planet, aspect and house system names: %s (default en)", strings.Join(names.Languages(), ", "))),
+		file: fs.String("names", "", "JSON file of names to use on top of --lang (see the README)"),
+	}
+}
+
+// apply loads the chosen language, then the --names file, into
+// names.Default. Without either flag it leaves the registry alone.
+func (l *langFlags) apply() error {
+	if *l.lang != "" {
+		if err := names.Default.Load(*l.lang); err != nil {
+			return err
+		}
+	}
+	if *l.file != "" {
+		return names.Default.LoadFile(*l.file)
+	}
+	return nil
+}
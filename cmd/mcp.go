@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dcccxiii/astro/names"
+)
+
+// mcp runs the other commands, so it joins the table in an init, as repl
+// does.
+func init() {
+	commands = append(commands, command{"mcp", "serve charts, transits and ephemeris tables to AI assistants over the Model Context Protocol", runMCP})
+}
+
+// mcpVersions are the revisions of the Model Context Protocol astro mcp
+// speaks, latest last.
+var mcpVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// runMCP implements "astro mcp": a Model Context Protocol server on stdin
+// and stdout, whose tools run astro commands with the ephemeris kept open.
+func runMCP(args []string) error {
+	fs := flag.NewFlagSet("astro mcp", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro mcp\n")
+		fmt.Fprintf(fs.Output(), "  Serves the Model Context Protocol over stdin and stdout, for AI\n")
+		fmt.Fprintf(fs.Output(), "  assistants to call astro's calculations as tools: chart, transits and\n")
+		fmt.Fprintf(fs.Output(), "  ephemeris, which take the arguments and flags of those commands as\n")
+		fmt.Fprintf(fs.Output(), "  named values and answer with their JSON. The ephemeris is opened once\n")
+		fmt.Fprintf(fs.Output(), "  and kept open. Register it with an MCP client as the command astro mcp.\n\n")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	closeSession, err := openSession()
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+	return serveRPC(os.Stdin, os.Stdout, mcpHandle)
+}
+
+// mcpHandle answers the MCP methods astro mcp implements.
+func mcpHandle(method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(params, &p)
+		version := mcpVersions[len(mcpVersions)-1]
+		if slices.Contains(mcpVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "astro", "version": moduleVersion()},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := make([]map[string]any, len(mcpTools))
+		for i, t := range mcpTools {
+			tools[i] = map[string]any{"name": t.name, "description": t.desc, "inputSchema": t.schema()}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string                     `json:"name"`
+			Arguments map[string]json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+		}
+		i := slices.IndexFunc(mcpTools, func(t mcpTool) bool { return t.name == p.Name })
+		if i < 0 {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("unknown tool %q", p.Name)}
+		}
+		args, err := mcpTools[i].args(p.Arguments)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		out, err := runCaptured(args)
+		if err != nil {
+			// The command's failure is the tool's result, for the
+			// assistant to read, not an error of the protocol.
+			var b bytes.Buffer
+			code, exit := Classify(err)
+			writeErrorJSON(&b, err, code, exit)
+			return mcpText(b.String(), true), nil
+		}
+		return mcpText(string(out), false), nil
+	}
+	if strings.HasPrefix(method, "notifications/") {
+		return map[string]any{}, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+// mcpText is the result of a tool call that answered with text.
+func mcpText(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": strings.TrimRight(text, "\n")}},
+		"isError": isError,
+	}
+}
+
+// mcpParam is an argument of a tool: a positional argument of its command,
+// or one of its flags.
+type mcpParam struct {
+	name     string
+	kind     string // the JSON Schema type: string, number, boolean, or coordinate (a number or a string)
+	flag     string // e.g. --house-system; empty for a positional argument
+	desc     string
+	required bool
+}
+
+// mcpTool is a tool of astro mcp, which runs command with its arguments
+// and the fixed flags.
+type mcpTool struct {
+	name, command, desc string
+	params              []mcpParam
+	fixed               []string
+}
+
+// mcpTools are the tools astro mcp offers. Their params follow the
+// commands' own positional arguments and flags.
+var mcpTools = []mcpTool{
+	{
+		name: "chart", command: "chart",
+		desc: "The natal or event chart of a moment and place: the planets' longitudes, signs, speeds and houses, the Ascendant, MC and house cusps, aspects, dignities and patterns, as JSON.",
+		params: []mcpParam{
+			{name: "datetime", kind: "string", desc: "The moment: RFC 3339 such as 1990-01-09T14:30:00Z, a local time such as 1990-01-09T14:30 with tz or the place's zone, or now", required: true},
+			{name: "lat", kind: "coordinate", desc: "Latitude, north positive, e.g. 51.5074 or 51N30; with lon, or give place"},
+			{name: "lon", kind: "coordinate", desc: "Longitude, east positive, e.g. -0.1278 or 0W07"},
+			{name: "place", kind: "string", flag: "--place", desc: "A place from the atlas in place of lat and lon, e.g. London or Paris, France"},
+			{name: "tz", kind: "string", flag: "--tz", desc: "IANA time zone of a local datetime, e.g. Europe/London"},
+			{name: "house_system", kind: "string", flag: "--house-system", desc: "placidus (default), koch, whole-sign, regiomontanus, equal, campanus, or another the Swiss Ephemeris casts, such as porphyry"},
+			{name: "planets", kind: "string", flag: "--planets", desc: "Comma-separated bodies, e.g. sun..pluto,chiron; default the classical seven"},
+			{name: "points", kind: "string", flag: "--points", desc: "Comma-separated chart points: vertex, east-point, node, lilith, fortune, ..."},
+		},
+		fixed: []string{"--json", "--compact"},
+	},
+	{
+		name: "transits", command: "transits",
+		desc: "The transits of the planets to a natal chart: over a range, each ingress into orb, exact hit and egress; or with now or at, those in orb at one moment, applying or separating. As JSON.",
+		params: []mcpParam{
+			{name: "natal_datetime", kind: "string", desc: "The birth moment, as for the chart tool", required: true},
+			{name: "lat", kind: "coordinate", desc: "Birth latitude, for transits to the Ascendant and MC too"},
+			{name: "lon", kind: "coordinate", desc: "Birth longitude"},
+			{name: "place", kind: "string", flag: "--place", desc: "Birth place from the atlas in place of lat and lon"},
+			{name: "tz", kind: "string", flag: "--tz", desc: "IANA time zone of local datetimes"},
+			{name: "from", kind: "string", flag: "--from", desc: "Start of the range (default now)"},
+			{name: "to", kind: "string", flag: "--to", desc: "End of the range (default a year after from)"},
+			{name: "now", kind: "boolean", flag: "--now", desc: "List the transits in orb now instead of searching a range"},
+			{name: "at", kind: "string", flag: "--at", desc: "List the transits in orb at this moment instead of searching a range"},
+			{name: "bodies", kind: "string", flag: "--bodies", desc: "Comma-separated transiting planets (default sun, mercury..pluto)"},
+			{name: "aspects", kind: "string", flag: "--aspects", desc: "Comma-separated aspects: conjunction, sextile, square, trine, opposition (default all)"},
+			{name: "orb", kind: "number", flag: "--orb", desc: "Orb in degrees (default 1)"},
+		},
+		fixed: []string{"--json", "--compact"},
+	},
+	{
+		name: "ephemeris", command: "ephemeris",
+		desc: "A table of the planets' longitudes at regular steps over a range of dates, as JSON.",
+		params: []mcpParam{
+			{name: "from", kind: "string", flag: "--from", desc: "First date (YYYY-MM-DD) or datetime (default today)"},
+			{name: "to", kind: "string", flag: "--to", desc: "Last date or datetime (default a month after from)"},
+			{name: "step", kind: "string", flag: "--step", desc: "Interval between rows, e.g. 1d, 12h or 1w (default 1d)"},
+			{name: "planets", kind: "string", flag: "--planets", desc: "Comma-separated bodies (default sun..pluto)"},
+		},
+		fixed: []string{"--json", "--compact"},
+	},
+}
+
+// schema returns the JSON Schema of the tool's arguments.
+func (t mcpTool) schema() map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range t.params {
+		var typ any = p.kind
+		if p.kind == "coordinate" {
+			typ = []string{"number", "string"}
+		}
+		props[p.name] = map[string]any{"type": typ, "description": p.desc}
+		if p.required {
+			required = append(required, p.name)
+		}
+	}
+	return map[string]any{"type": "object", "properties": props, "required": required, "additionalProperties": false}
+}
+
+// args returns the command line of a call of the tool with the given
+// arguments: the flags, then after -- the positional arguments, so that no
+// value can be read as a flag.
+func (t mcpTool) args(given map[string]json.RawMessage) ([]string, error) {
+	var unknown []string
+	for name := range given {
+		if !slices.ContainsFunc(t.params, func(p mcpParam) bool { return p.name == name }) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown arguments of %s: %s", t.name, strings.Join(unknown, ", "))
+	}
+	args := append([]string{t.command}, t.fixed...)
+	var positional []string
+	for _, p := range t.params {
+		raw, ok := given[p.name]
+		if !ok || string(raw) == "null" {
+			if p.required {
+				return nil, fmt.Errorf("%s needs the argument %s", t.name, p.name)
+			}
+			continue
+		}
+		v, err := p.value(raw)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p.kind == "boolean":
+			if v == "true" {
+				args = append(args, p.flag)
+			}
+		case p.flag != "":
+			args = append(args, p.flag+"="+v)
+		default:
+			positional = append(positional, v)
+		}
+	}
+	return append(append(args, "--"), positional...), nil
+}
+
+// value returns the argument raw, of the param's kind, as it is written on
+// the command line.
+func (p mcpParam) value(raw json.RawMessage) (string, error) {
+	var s string
+	var f float64
+	var b bool
+	switch {
+	case p.kind != "number" && p.kind != "boolean" && json.Unmarshal(raw, &s) == nil:
+		return s, nil
+	case (p.kind == "number" || p.kind == "coordinate") && json.Unmarshal(raw, &f) == nil:
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case p.kind == "boolean" && json.Unmarshal(raw, &b) == nil:
+		return strconv.FormatBool(b), nil
+	}
+	want := p.kind
+	if want == "coordinate" {
+		want = "number or string"
+	}
+	return "", fmt.Errorf("invalid %s: expected a %s, got %s", p.name, want, raw)
+}
+
+// runCaptured runs a command line as astro repl does, from the default
+// names, and returns what it wrote to stdout. The command reads an empty
+// stdin, which belongs to the session.
+func runCaptured(args []string) ([]byte, error) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		return nil, internal(err)
+	}
+	defer null.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, internal(err)
+	}
+	done := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- b
+	}()
+	stdin, stdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = null, w
+	names.Default = names.New()
+	err = Run(args)
+	os.Stdin, os.Stdout = stdin, stdout
+	w.Close()
+	out := <-done
+	r.Close()
+	return out, err
+}
+
+// moduleVersion returns the version astro was built as, or "devel".
+func moduleVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+	return "devel"
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runMoon implements "astro moon": the new, quarter and full moons of a
+// year, and the eclipses among them.
+func runMoon(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro moon", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro moon [<year>] [--phases <list>] [--supermoon] [--format text|csv|ics|json] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the new moons, first quarters, full moons and last quarters of\n")
+		fmt.Fprintf(fs.Output(), "  the year, this one by default, with the Moon's sign and degree and\n")
+		fmt.Fprintf(fs.Output(), "  the eclipses among them. The year and times are those of --tz, else\n")
+		fmt.Fprintf(fs.Output(), "  UTC. With --supermoon, the new and full moons near perigee are\n")
+		fmt.Fprintf(fs.Output(), "  marked as supermoons.\n\n")
+		fs.PrintDefaults()
+	}
+
+	phasesFlag := fs.String("phases", "all", "Phases to list: all, or any of new, first-quarter, full, last-quarter")
+	supermoonFlag := fs.Bool("supermoon", false, "Mark the new and full moons within 90% of the way from apogee to perigee as supermoons")
+	formatFlag := fs.String("format", "text", "Output format: text, csv, ics (iCalendar, for calendar applications) or json")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON; short for --format json")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one year, got %d arguments: %s", len(pos), strings.Join(pos, " "))
+	}
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	year := time.Now().In(loc).Year()
+	if len(pos) == 1 {
+		if year, err = strconv.Atoi(pos[0]); err != nil || year < -5000 || year > 5000 {
+			return &input.Error{Kind: "year", Value: pos[0], Reason: "expected a year, e.g. 2025"}
+		}
+	}
+	format := *formatFlag
+	if *jsonFlag {
+		format = "json"
+	}
+	switch format {
+	case "text", "csv", "ics", "json":
+	default:
+		return &input.Error{Kind: "format", Value: format, Reason: "must be text, csv, ics or json"}
+	}
+	phases, err := parseLunarPhases(*phasesFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	from, to := ephemeris.JulianDay(first), ephemeris.JulianDay(first.AddDate(1, 0, 0))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	events, err := mundane.Lunations(p, from, to)
+	if err != nil {
+		return err
+	}
+	events = slices.DeleteFunc(events, func(e mundane.Event) bool { return !slices.Contains(phases, e.Phase) })
+	if *supermoonFlag {
+		if err := mundane.Supermoons(events, moonOrbit(backendFlag(backend))); err != nil {
+			return err
+		}
+	}
+	y := output.BuildMoonYear(year, loc, events)
+	y.Supermoons = *supermoonFlag
+	rec.Mark("compute")
+
+	switch format {
+	case "csv":
+		err = output.WriteMoonYearCSV(os.Stdout, y)
+	case "ics":
+		err = output.WriteMoonYearICS(os.Stdout, y, time.Now())
+	case "json":
+		err = output.PrintMoonYearJSON(y, output.JSONOptions{Compact: *compactFlag})
+	default:
+		err = output.WriteMoonYearText(os.Stdout, y)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "moon", backend)
+}
+
+// moonOrbit returns the Moon's distance and the perigee and apogee of its
+// orbit from the library, for mundane.Supermoons.
+func moonOrbit(flags int) mundane.Orbit {
+	return func(jd float64) (dist, perigee, apogee float64, err error) {
+		d, err := swisseph.OrbitDistances(jd, swisseph.Moon, flags)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("error calculating the Moon's orbit: %w", err)
+		}
+		return d.True, d.Min, d.Max, nil
+	}
+}
+
+// parseLunarPhases reads the comma-separated list of --phases: all, or any of
+// new, first-quarter, full and last-quarter.
+func parseLunarPhases(s string) ([]mundane.Phase, error) {
+	if strings.EqualFold(strings.TrimSpace(s), "all") {
+		return []mundane.Phase{mundane.NewMoon, mundane.FirstQuarter, mundane.FullMoon, mundane.LastQuarter}, nil
+	}
+	var phases []mundane.Phase
+	for _, name := range strings.Split(s, ",") {
+		var ph mundane.Phase
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "new":
+			ph = mundane.NewMoon
+		case "first-quarter":
+			ph = mundane.FirstQuarter
+		case "full":
+			ph = mundane.FullMoon
+		case "last-quarter":
+			ph = mundane.LastQuarter
+		default:
+			return nil, &input.Error{Kind: "phase", Value: name, Reason: "must be new, first-quarter, full or last-quarter"}
+		}
+		if !slices.Contains(phases, ph) {
+			phases = append(phases, ph)
+		}
+	}
+	return phases, nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/nodes"
+	"github.com/dcccxiii/astro/output"
+)
+
+// runNodes implements "astro nodes": periods when the true and mean lunar
+// nodes diverge by more than a threshold.
+func runNodes(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro nodes", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro nodes [--from <datetime>] [--to <datetime>] [--threshold <degrees>] [--ephemeris <backend>] [--timings] [--json [--compact] | --ndjson]\n")
+		fmt.Fprintf(fs.Output(), "  Lists the periods when the true node is more than --threshold degrees\n")
+		fmt.Fprintf(fs.Output(), "  from the mean node, with the peak divergence of each.\n\n")
+		fs.PrintDefaults()
+	}
+
+	fromFlag := fs.String("from", "", "Start of the range (RFC 3339); default now")
+	toFlag := fs.String("to", "", "End of the range (RFC 3339); default one year after --from")
+	thresholdFlag := fs.Float64("threshold", nodes.DefaultThreshold, "Divergence in degrees considered large")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per period, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d", len(pos))
+	}
+
+	from := time.Now().UTC()
+	if *fromFlag != "" {
+		if from, err = tz.opts.ParseDateTime(*fromFlag); err != nil {
+			return err
+		}
+	}
+	to := from.AddDate(1, 0, 0)
+	if *toFlag != "" {
+		if to, err = tz.opts.ParseDateTime(*toFlag); err != nil {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to %s is before --from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	if *thresholdFlag <= 0 {
+		return fmt.Errorf("--threshold must be positive, got %v", *thresholdFlag)
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+
+	fromJD, toJD := ephemeris.JulianDay(from), ephemeris.JulianDay(to)
+	if err := checkDate(backend, fromJD, toJD); err != nil {
+		return err
+	}
+	rec.Mark("parse")
+
+	periods, err := nodes.FindPeriods(rec.Wrap(newProvider(backend, 0)), fromJD, toJD, *thresholdFlag)
+	if err != nil {
+		return err
+	}
+	rep := output.BuildNodeReport(fromJD, toJD, *thresholdFlag, periods)
+	rec.Mark("compute")
+
+	switch {
+	case *jsonFlag:
+		err = output.PrintNodeReportJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	case *ndjsonFlag:
+		err = output.PrintNDJSON(rep.Periods)
+	default:
+		err = output.PrintNodeReportText(rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "nodes", backend)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dcccxiii/astro/atlas"
+	"github.com/dcccxiii/astro/input"
+)
+
+// placeFlag holds the --place flag, which commands taking <lat> <lon>
+// accept in their stead.
+type placeFlag struct {
+	query *string
+	tz    *zoneFlag
+}
+
+// addPlace defines --place on fs. The place's time zone applies unless
+// tz is set.
+func addPlace(fs *flag.FlagSet, tz *zoneFlag) *placeFlag {
+	tz.coordinates = true
+	return &placeFlag{
+		query: fs.String("place", "", "Place to use instead of <lat> <lon>, e.g. \"Berlin, Germany\"; its time zone applies to datetimes without an offset unless --tz is given (see astro atlas search)"),
+		tz:    tz,
+	}
+}
+
+// apply looks the place up in the atlas and inserts its latitude and
+// longitude into pos after the first n arguments, where the command takes
+// <lat> <lon>. Unless --tz was given, the place's time zone becomes
+// tz.opts.Zone. Without the flag it returns pos as it is.
+func (p *placeFlag) apply(pos []string, n int) ([]string, error) {
+	if *p.query == "" {
+		return pos, nil
+	}
+	if len(pos) > n {
+		return nil, fmt.Errorf("--place gives the latitude and longitude: leave out <lat> <lon>")
+	}
+	a, err := loadAtlas()
+	if err != nil {
+		return nil, err
+	}
+	place, ok := a.Lookup(*p.query)
+	if !ok {
+		e := &input.Error{Kind: "place", Value: *p.query, Reason: "not found in the atlas; list close matches with astro atlas search"}
+		if found := a.Search(*p.query, 1); len(found) > 0 {
+			e.Suggestion = found[0].String()
+		}
+		return nil, e
+	}
+	if *p.tz.name == "" {
+		loc, err := time.LoadLocation(place.Zone)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an unknown time zone %q", place, place.Zone)
+		}
+		p.tz.opts.Zone, p.tz.source = loc, "place"
+	}
+	if len(pos) < n {
+		return pos, nil
+	}
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	return append(pos[:n:n], num(place.Lat), num(place.Lon)), nil
+}
+
+// loadAtlas returns the atlas in the file named by $ASTRO_ATLAS, such as
+// a GeoNames cities file, or else the built-in one.
+func loadAtlas() (*atlas.Atlas, error) {
+	path := os.Getenv("ASTRO_ATLAS")
+	if path == "" {
+		return atlas.Default(), nil
+	}
+	a, err := readFile(path, atlas.Load)
+	if err != nil {
+		return nil, fmt.Errorf("error reading atlas %s (ASTRO_ATLAS): %w", path, err)
+	}
+	return a, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// polarFallbacks are the house systems --polar-fallback may give: both
+// can be cast at any latitude.
+var polarFallbacks = []string{"porphyry", "whole-sign"}
+
+// polarFallback holds the --polar-fallback flag of the commands that cast
+// a chart's houses: the system cast in place of one, such as Placidus or
+// Koch, that fails within a polar circle.
+type polarFallback struct {
+	name     *string
+	hsys     byte // set by resolve; 0 for no fallback
+	hsysName string
+}
+
+// addPolarFallback defines --polar-fallback on fs.
+func addPolarFallback(fs *flag.FlagSet) *polarFallback {
+	return &polarFallback{
+		name: fs.String("polar-fallback", "", "House system to cast in place of one, such as placidus or koch, that fails within the polar circles: porphyry or whole-sign (default none: such a chart is an error)"),
+	}
+}
+
+// resolve reads the flag's house system, once the flags are parsed.
+func (f *polarFallback) resolve() error {
+	f.hsys, f.hsysName = 0, ""
+	if *f.name == "" {
+		return nil
+	}
+	if !slices.Contains(polarFallbacks, strings.ToLower(*f.name)) {
+		return fmt.Errorf("unknown --polar-fallback %q: valid values are %s", *f.name, strings.Join(polarFallbacks, ", "))
+	}
+	var err error
+	f.hsys, f.hsysName, err = parseHouseSystem(*f.name)
+	return err
+}
+
+// build is output.Build, but when hsys cannot be cast at lat it casts the
+// fallback system instead and records in the result the system asked for.
+// Without a fallback, the error says how to ask for one.
+func (f *polarFallback) build(p ephemeris.Provider, jd float64, planets []int, lat, lon float64, hsys byte, hsysName string) (output.Result, error) {
+	r, err := output.Build(p, jd, planets, lat, lon, hsys, hsysName)
+	var polar *swisseph.PolarError
+	if !errors.As(err, &polar) {
+		return r, err
+	}
+	if f.hsys == 0 {
+		return r, fmt.Errorf("%w; --polar-fallback porphyry or whole-sign casts another system in its place", err)
+	}
+	if r, err = output.Build(p, jd, planets, lat, lon, f.hsys, f.hsysName); err != nil {
+		return r, err
+	}
+	output.SetHouseFallback(&r, hsysName)
+	return r, nil
+}
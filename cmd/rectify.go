@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/rectify"
+)
+
+// runRectify implements "astro rectify": a sweep of the candidate times of
+// a birth date, optionally ranked against dated life events.
+func runRectify(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro rectify", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro rectify <date> (<lat> <lon> | --place <place>) [--window <HH:MM-HH:MM>] [--step <duration>] [--events <file>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Casts the chart at every --step through the --window of the birth\n")
+		fmt.Fprintf(fs.Output(), "  date, in the place's local time, and lists the spans of times that\n")
+		fmt.Fprintf(fs.Output(), "  share the signs of the Ascendant and MC, the planets' houses and the\n")
+		fmt.Fprintf(fs.Output(), "  house rulers, with what changes from one to the next. With --events,\n")
+		fmt.Fprintf(fs.Output(), "  a file of dated life events, one \"<date> <description>\" a line, the\n")
+		fmt.Fprintf(fs.Output(), "  candidates are ranked by the transits and solar arc directions to\n")
+		fmt.Fprintf(fs.Output(), "  their angles that time the events.\n\n")
+		fs.PrintDefaults()
+	}
+
+	windowFlag := fs.String("window", "00:00-24:00", "Span of the day to sweep, local time; an end before the start runs into the next day")
+	stepFlag := fs.String("step", "1m", "Time between candidates, e.g. 1m or 4m")
+	eventsFlag := fs.String("events", "", "File of dated life events to rank the candidates by, one \"<date> <description>\" a line (- for stdin)")
+	orbFlag := fs.Float64("orb", 1, "Orb in degrees of the transits and directions that time the events")
+	limitFlag := fs.Int("limit", 10, "Number of ranked candidates to list (0 for all)")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected 3 positional arguments (<date> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	// The window is the place's clock, from the atlas unless --tz or
+	// --place gave it.
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0]); err != nil {
+		return err
+	}
+	date, err := input.ParseDate(pos[0])
+	if err != nil {
+		return err
+	}
+	begin, end, err := parseWindow(*windowFlag)
+	if err != nil {
+		return err
+	}
+	step, err := input.ParseDuration(*stepFlag)
+	if err != nil {
+		return err
+	}
+	if step < time.Second {
+		return &input.Error{Kind: "step", Value: *stepFlag, Reason: "must be at least 1s"}
+	}
+	if *orbFlag <= 0 || *orbFlag > 10 {
+		return &input.Error{Kind: "orb", Value: fmt.Sprint(*orbFlag), Reason: "must be more than 0° and at most 10°"}
+	}
+	if *limitFlag < 0 {
+		return fmt.Errorf("--limit must not be negative, got %d", *limitFlag)
+	}
+	var events []rectify.Event
+	if *eventsFlag != "" {
+		if events, err = loadLifeEvents(*eventsFlag, tz.opts); err != nil {
+			return err
+		}
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	planets, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+	rec.Mark("parse")
+
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	from, to := ephemeris.JulianDay(midnight.Add(begin)), ephemeris.JulianDay(midnight.Add(end))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	days := step.Hours() / 24
+
+	p := rec.Wrap(newProvider(backend, 0))
+	charts, err := rectify.Sweep(p, planets, from, to, days, lat, lon, hsys)
+	if err != nil {
+		return err
+	}
+	var candidates []rectify.Candidate
+	if len(events) > 0 {
+		if candidates, err = rectify.Rank(p, charts, events, *orbFlag); err != nil {
+			return err
+		}
+	}
+	rep := output.BuildRectify(rectify.Spans(charts, planets), candidates, events, planets, *orbFlag, loc, from, to, days, lat, lon, hsysName, *limitFlag)
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintRectifyJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.WriteRectifyText(os.Stdout, rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "rectify", backend)
+}
+
+// parseWindow parses a span of the day given as HH:MM-HH:MM, returning its
+// start and end as offsets from midnight. An end before the start falls on
+// the next day; 24:00 is the end of the day.
+func parseWindow(s string) (begin, end time.Duration, err error) {
+	a, b, ok := strings.Cut(s, "-")
+	if ok {
+		begin, ok = clockTime(a)
+	}
+	if ok {
+		end, ok = clockTime(b)
+	}
+	if !ok || begin == end {
+		return 0, 0, &input.Error{Kind: "window", Value: s, Reason: "expected two different times of day, HH:MM-HH:MM, e.g. 06:00-12:00"}
+	}
+	if end < begin {
+		end += 24 * time.Hour
+	}
+	return begin, end, nil
+}
+
+// clockTime parses a time of day, HH:MM, as an offset from midnight, up
+// to 24:00.
+func clockTime(s string) (time.Duration, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hours, err1 := strconv.Atoi(h)
+	minutes, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, true
+}
+
+// loadLifeEvents reads a file of dated life events, one "<date>
+// <description>" a line, such as "2012-06-15 married". The date is any
+// datetime opts read, so a local one is in the chart's zone; a date alone
+// stands for --default-time. Blank lines and those starting with # are
+// skipped.
+func loadLifeEvents(file string, opts input.Options) ([]rectify.Event, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read events: %w", err)
+	}
+	var events []rectify.Event
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		when, name, _ := strings.Cut(line, " ")
+		t, err := opts.ParseDateTime(when)
+		if err != nil {
+			return nil, fmt.Errorf("%s, line %d: %w", file, n, err)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = when
+		}
+		events = append(events, rectify.Event{JD: ephemeris.JulianDay(t), Name: name})
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%s lists no events", file)
+	}
+	return events, nil
+}
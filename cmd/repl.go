@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// repl runs the other commands, so it joins the table here rather than in
+// its literal, which would make an initialization cycle.
+func init() {
+	commands = append(commands, command{"repl", "run commands one after another with the ephemeris kept open", runRepl})
+}
+
+// replPrompt is shown before each command when stdin is a terminal.
+const replPrompt = "astro> "
+
+// runRepl implements "astro repl": astro commands read line by line from
+// stdin and run in one process, with the ephemeris opened once.
+func runRepl(args []string) error {
+	fs := flag.NewFlagSet("astro repl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro repl\n")
+		fmt.Fprintf(fs.Output(), "  Reads astro commands from stdin, one a line, as they are typed after\n")
+		fmt.Fprintf(fs.Output(), "  astro, e.g. chart now 51.5 -0.13 or transits 1990-01-09T14:30:00Z\n")
+		fmt.Fprintf(fs.Output(), "  51.5 -0.13 --at now, and runs each in turn. The ephemeris is opened\n")
+		fmt.Fprintf(fs.Output(), "  once and kept open, so each command answers at once. Arguments may be\n")
+		fmt.Fprintf(fs.Output(), "  quoted as in a shell. A failed command reports its error and the next\n")
+		fmt.Fprintf(fs.Output(), "  is read. exit, quit or the end of input ends the session.\n\n")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	closeSession, err := openSession()
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+
+	prompt := ""
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		prompt = replPrompt
+		fmt.Fprintf(os.Stdout, "astro %s. Type a command, e.g. chart now 51.5 -0.13, help for the list, or exit.\n", swisseph.Version())
+	}
+	return repl(os.Stdin, os.Stdout, os.Stderr, prompt)
+}
+
+// repl runs the commands read from in until exit or the end of input. It
+// writes prompt, if any, to out before each line, and the errors of the
+// commands to errw, as Main does.
+func repl(in io.Reader, out, errw io.Writer, prompt string) error {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, prompt)
+		if !sc.Scan() {
+			if prompt != "" {
+				fmt.Fprintln(out)
+			}
+			return sc.Err()
+		}
+		args, err := splitLine(sc.Text())
+		if err != nil {
+			fmt.Fprintln(errw, err)
+			continue
+		}
+		if len(args) > 0 && args[0] == "astro" {
+			args = args[1:]
+		}
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+		// Each command starts from the default names, as in a process of
+		// its own.
+		names.Default = names.New()
+		report(errw, args, Run(args))
+	}
+}
+
+// splitLine splits a command line into arguments at unquoted white space.
+// As in a POSIX shell, single quotes keep everything between them, double
+// quotes keep all but a backslash before " or \, and a backslash outside
+// quotes escapes the next character.
+func splitLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("line ends with a backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
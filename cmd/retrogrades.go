@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+)
+
+// runRetrogrades implements "astro retrogrades": the retrograde periods of
+// a year, with their shadows.
+func runRetrogrades(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro retrogrades", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro retrogrades [<year>] [--planets <list>] [--format text|ics|json] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists each planet's retrograde periods in the year, this one by\n")
+		fmt.Fprintf(fs.Output(), "  default: when it enters the shadow, stations retrograde, stations\n")
+		fmt.Fprintf(fs.Output(), "  direct and leaves the shadow, with the degrees. A period running\n")
+		fmt.Fprintf(fs.Output(), "  into the year or out of it is listed whole. The times are those of\n")
+		fmt.Fprintf(fs.Output(), "  --tz, else UTC.\n\n")
+		fs.PrintDefaults()
+	}
+
+	planetsFlag := fs.String("planets", "mercury..pluto", "Comma-separated planets whose retrogrades to list")
+	formatFlag := fs.String("format", "text", "Output format: text, ics (iCalendar, for calendar applications) or json")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON; short for --format json")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if len(pos) > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one year, got %d arguments: %s", len(pos), strings.Join(pos, " "))
+	}
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	year := time.Now().In(loc).Year()
+	if len(pos) == 1 {
+		if year, err = strconv.Atoi(pos[0]); err != nil || year < -5000 || year > 5000 {
+			return &input.Error{Kind: "year", Value: pos[0], Reason: "expected a year, e.g. 2025"}
+		}
+	}
+	format := *formatFlag
+	if *jsonFlag {
+		format = "json"
+	}
+	switch format {
+	case "text", "ics", "json":
+	default:
+		return &input.Error{Kind: "format", Value: format, Reason: "must be text, ics or json"}
+	}
+	planets, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	for _, body := range planets {
+		if body == ephemeris.Sun || body == ephemeris.Moon {
+			return &input.Error{Kind: "planet", Value: *planetsFlag, Reason: "the Sun and Moon are never retrograde"}
+		}
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	from, to := ephemeris.JulianDay(first), ephemeris.JulianDay(first.AddDate(1, 0, 0))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	var periods []mundane.Retrograde
+	for _, body := range planets {
+		found, err := mundane.Retrogrades(p, body, from, to)
+		if err != nil {
+			return err
+		}
+		periods = append(periods, found...)
+	}
+	y := output.BuildRetrogradeYear(year, loc, periods)
+	rec.Mark("compute")
+
+	switch format {
+	case "ics":
+		err = output.WriteRetrogradeYearICS(os.Stdout, y, time.Now())
+	case "json":
+		err = output.PrintRetrogradeYearJSON(y, output.JSONOptions{Compact: *compactFlag})
+	default:
+		err = output.WriteRetrogradeYearText(os.Stdout, y)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "retrogrades", backend)
+}
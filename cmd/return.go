@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/returns"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// runReturn implements "astro return": finds a planetary return and renders
+// the chart for that moment.
+func runReturn(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro return", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro return solar <natal-datetime> (<lat> <lon> | --place <place>) [--year <year>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "       astro return --planet <planet> <natal-datetime> (<lat> <lon> | --place <place>) [--after <datetime>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Finds the moment a transiting planet returns to its natal longitude and\n")
+		fmt.Fprintf(fs.Output(), "  prints the chart for that moment. \"solar\" finds the Sun's return in\n")
+		fmt.Fprintf(fs.Output(), "  --year; --planet finds the next return of any planet after --after. When\n")
+		fmt.Fprintf(fs.Output(), "  retrograde motion makes a planet cross its natal degree three times, all\n")
+		fmt.Fprintf(fs.Output(), "  passes are listed and the chart is cast for the first.\n\n")
+		fs.PrintDefaults()
+	}
+
+	yearFlag := fs.Int("year", time.Now().UTC().Year(), "Year of the solar return")
+	planetFlag := fs.String("planet", "", "Planet whose return to find, e.g. saturn or jupiter")
+	afterFlag := fs.String("after", "", "With --planet, find the first return after this datetime (RFC 3339); default now")
+	relocatedFlag := fs.String("relocated", "", "Cast the return chart for another location, given as <lat> <lon> or <lat>,<lon>")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	polar := addPolarFallback(fs)
+	out := addChartOutput(fs)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, joinCoordFlag(args, "relocated"))
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+
+	if err := out.resolve(); err != nil {
+		return err
+	}
+
+	solar := len(pos) > 0 && pos[0] == "solar"
+	if solar {
+		pos = pos[1:]
+	}
+	if pos, err = expandSaved(pos); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	switch {
+	case solar && *planetFlag != "":
+		return fmt.Errorf("use either \"solar\" or --planet, not both")
+	case !solar && *planetFlag == "" || len(pos) != 3:
+		fs.Usage()
+		return fmt.Errorf("expected: solar <natal-datetime> <lat> <lon>, or --planet <planet> <natal-datetime> <lat> <lon>")
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	switch {
+	case !solar && given["year"]:
+		return fmt.Errorf("--year applies to solar returns; with --planet, give the start of the search with --after")
+	case solar && given["after"]:
+		return fmt.Errorf("--after applies to --planet; with \"solar\", give the year with --year")
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0], *afterFlag); err != nil {
+		return err
+	}
+
+	body := swisseph.Sun
+	if !solar {
+		if body, err = parseBody(*planetFlag); err != nil {
+			return err
+		}
+		if !returns.Supported(body) {
+			return fmt.Errorf("returns are not supported for %s", *planetFlag)
+		}
+	}
+
+	natal, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	relocated := *relocatedFlag != ""
+	if relocated {
+		if lat, lon, err = parseLatLon(*relocatedFlag); err != nil {
+			return err
+		}
+	}
+	after := time.Now().UTC()
+	if *afterFlag != "" {
+		if after, err = tz.opts.ParseDateTime(*afterFlag); err != nil {
+			return err
+		}
+	}
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+
+	rec.Mark("parse")
+
+	natalJD := ephemeris.JulianDay(natal)
+	if err := checkDate(backend, natalJD); err != nil {
+		return err
+	}
+	p := rec.Wrap(newProvider(backend, 0))
+	natalPos, err := p.CalcPlanet(natalJD, body)
+	if err != nil {
+		return fmt.Errorf("error calculating natal %s: %w", p.PlanetName(body), err)
+	}
+
+	var passes []float64
+	if solar {
+		// Start a few days before the birthday so a return that falls on
+		// the eve of the anniversary (the Sun's year is not a whole number
+		// of days) is still found in the requested year.
+		anniversary := time.Date(*yearFlag, natal.Month(), natal.Day(), natal.Hour(), natal.Minute(), natal.Second(), 0, time.UTC)
+		from := ephemeris.JulianDay(anniversary) - 5
+		if err := checkDate(backend, from); err != nil {
+			return err
+		}
+		jd, err := returns.Solar(p, natalPos.Longitude, from)
+		if err != nil {
+			return err
+		}
+		passes = []float64{jd}
+	} else {
+		from := ephemeris.JulianDay(after)
+		if err := checkDate(backend, from); err != nil {
+			return err
+		}
+		if passes, err = returns.Find(p, body, natalPos.Longitude, from); err != nil {
+			return err
+		}
+	}
+
+	r, err := polar.build(p, passes[0], chartPlanets, lat, lon, hsys, hsysName)
+	if err != nil {
+		return err
+	}
+	sign, deg := names.SignOf(natalPos.Longitude)
+	r.Return = &output.ReturnInfo{
+		Kind:      returnKind(body),
+		Planet:    names.Body(body),
+		Natal:     output.AngleEntry{Longitude: natalPos.Longitude, Sign: sign, SignDegree: deg},
+		Time:      ephemeris.TimeOf(passes[0]),
+		Relocated: relocated,
+	}
+	if len(passes) > 1 {
+		for _, jd := range passes {
+			r.Return.Passes = append(r.Return.Passes, ephemeris.TimeOf(jd))
+		}
+	}
+
+	rec.Mark("compute")
+
+	r.Metadata = chartMetadata("return", args, backend)
+	if err := out.write(r); err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "return", backend)
+}
+
+// returnKind names the return of body as astrologers do: solar, lunar, or
+// after the planet (e.g. "saturn").
+func returnKind(body int) string {
+	switch body {
+	case swisseph.Sun:
+		return "solar"
+	case swisseph.Moon:
+		return "lunar"
+	}
+	for name, id := range bodyNames {
+		if id == body {
+			return name
+		}
+	}
+	return swisseph.PlanetName(body)
+}
+
+// parseLatLon parses "<lat>,<lon>".
+func parseLatLon(s string) (lat, lon float64, err error) {
+	a, b, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid location %q: expected <lat>,<lon>", s)
+	}
+	return input.ParseCoordinates(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// joinCoordFlag rewrites "--name <lat> <lon>" into "--name=<lat>,<lon>" so a
+// coordinate pair can be given as two tokens, as users naturally type it.
+func joinCoordFlag(args []string, name string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if (a == "-"+name || a == "--"+name) && i+2 < len(args) && !strings.Contains(args[i+1], ",") {
+			out = append(out, a+"="+args[i+1]+","+args[i+2])
+			i += 2
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -1,32 +1,105 @@
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/ephemeris/swiss"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/nodes"
 	"github.com/dcccxiii/astro/output"
 	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/timing"
+	"github.com/dcccxiii/astro/vedic"
 )
 
-// Run is the CLI entry point. It parses args, sets up the ephemeris, and
-// delegates rendering to the output package.
+// chartPlanets are the bodies shown in a chart unless --planets says
+// otherwise, and by the commands without it.
+var chartPlanets = []int{
+	swisseph.Sun, swisseph.Moon, swisseph.Mercury,
+	swisseph.Venus, swisseph.Mars, swisseph.Jupiter,
+	swisseph.Saturn,
+}
+
+// Run is the CLI entry point. If the first argument names a command it
+// runs that command; otherwise the arguments are those of astro chart.
 func Run(args []string) error {
-	fs := flag.NewFlagSet("astro", flag.ContinueOnError)
+	if len(args) == 0 || len(args) == 1 && isHelp(args[0]) {
+		printUsage(os.Stderr)
+		if len(args) == 0 {
+			return fmt.Errorf("expected a command, or <datetime> <lat> <lon> for a chart")
+		}
+		return nil
+	}
+	if args[0] == "help" {
+		return runHelp(args[1:])
+	}
+	if args[0] == "--stdio" {
+		return runStdio(args[1:])
+	}
+	if c, ok := lookupCommand(args[0]); ok {
+		return c.run(args[1:])
+	}
+	if isCommandWord(args[0]) {
+		return unknownCommand(args[0])
+	}
+	return runChart(args)
+}
+
+// runChart implements "astro chart", which is also what astro runs when
+// the first argument names no command: the chart for a moment and place.
+func runChart(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro chart", flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Fprintf(fs.Output(), "Usage: astro [--house-system <system>] [--json] <datetime> <lat> <lon>\n")
-		fmt.Fprintf(fs.Output(), "  <datetime>  ISO 8601 date/time in UTC, e.g. 2024-03-20T12:00:00Z\n")
-		fmt.Fprintf(fs.Output(), "  <lat>       geographic latitude in decimal degrees (north = positive)\n")
-		fmt.Fprintf(fs.Output(), "  <lon>       geographic longitude in decimal degrees (east = positive)\n\n")
+		fmt.Fprintf(fs.Output(), "Usage: astro chart [--house-system <system>] [--polar-fallback <system>] [--format <format> | --json | --yaml | --oneline | --template <file>] [--compact] [--output <file>] [--glyphs] [--planets <list>] [--points <list>] [--tychonic] [--nodes <which>] [--observer <planet>] [--horary] [--visibility] [--rulers <scheme>] [--solar-time] [--weighted-balance] [--vedic] [--sidereal <ayanamsa> [--varga <dN>]] [--lang <code>] [--names <file>] [--tz <zone>] [--default-time <HH:MM>] [--ephemeris <backend>] [--timings] [--verbose] <datetime> (<lat> <lon> | --place <place>)\n")
+		fmt.Fprintf(fs.Output(), "       astro [flags] <datetime> (<lat> <lon> | --place <place>)\n")
+		fmt.Fprintf(fs.Output(), "  Computes the chart for a moment and place: the planets, houses, aspects\n")
+		fmt.Fprintf(fs.Output(), "  and summary. \"chart\" may be left out. For the other commands, see\n")
+		fmt.Fprintf(fs.Output(), "  astro help.\n\n")
+		fmt.Fprintf(fs.Output(), "  <datetime>  ISO 8601 date/time in UTC, e.g. 2024-03-20T12:00:00Z, or local\n")
+		fmt.Fprintf(fs.Output(), "              time in a zone, e.g. 2024-03-20T13:00:00[Europe/Paris] (see --tz);\n")
+		fmt.Fprintf(fs.Output(), "              a date alone (see --default-time); or now or today, e.g. now+3d\n")
+		fmt.Fprintf(fs.Output(), "  <lat>       geographic latitude in decimal degrees (north = positive),\n")
+		fmt.Fprintf(fs.Output(), "              or with the hemisphere, e.g. 51N30 or 51°30'N\n")
+		fmt.Fprintf(fs.Output(), "  <lon>       geographic longitude in decimal degrees (east = positive),\n")
+		fmt.Fprintf(fs.Output(), "              or with the hemisphere, e.g. 0W07 or 0°07'W\n\n")
 		fs.PrintDefaults()
 	}
 
-	houseSystemFlag := fs.String("house-system", "placidus", "House system: placidus, koch, whole-sign, regiomontanus, equal, campanus")
-	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage+", or all to compare the houses of every system the library casts (text or JSON)")
+	polar := addPolarFallback(fs)
+	out := addChartOutput(fs)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	pointsFlag := fs.String("points", "", pointsUsage)
+	tychonicFlag := fs.Bool("tychonic", false, "Also report the heliocentric position of Earth (Tychonic hybrid frame)")
+	nodesFlag := fs.String("nodes", "none", "Lunar nodes to show: none, mean, true, both")
+	siderealFlag := fs.String("sidereal", "", siderealUsage)
+	vedicFlag := fs.Bool("vedic", false, "Jyotish preset: --sidereal lahiri --house-system whole-sign --nodes mean, unless given otherwise")
+	vargaFlag := fs.String("varga", "", "With --sidereal, show a divisional chart in whole-sign houses, e.g. d9 (navamsha), d10, d12")
+	horaryFlag := fs.Bool("horary", false, "Append the horary considerations: early or late Ascendant, void Moon, Saturn in the 7th, via combusta, and agreement of the hour")
+	rulersFlag := fs.String("rulers", "", "Append the chart ruler and house rulers, by traditional or modern rulerships")
+	visibilityFlag := fs.Bool("visibility", false, "Append which planets can be seen with the naked eye: their altitude, elongation from the Sun and magnitude against the sky's")
+	solarTimeFlag := fs.Bool("solar-time", false, "Also give the moment as local mean and apparent (sundial) time at the longitude, with the equation of time")
+	weightedFlag := fs.Bool("weighted-balance", false, "Count the Sun, Moon and Ascendant double in the element and modality balance")
+	observerFlag := fs.String("observer", "", "Experimental: show the sky as seen from another planet, e.g. mars (houses are omitted)")
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	verboseFlag := fs.Bool("verbose", false, "Print the ephemeris's warnings to stderr, such as a .se1 file it could not find and so computed with the Moshier ephemeris, and list in the text output the ephemeris and file that computed each planet")
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
 
 	if err := fs.Parse(args); err != nil {
 		if err == flag.ErrHelp {
@@ -34,75 +107,464 @@ func Run(args []string) error {
 		}
 		return err
 	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+
+	if err := out.resolve(); err != nil {
+		return err
+	}
+
+	if *vedicFlag {
+		if err := applyVedicPreset(fs); err != nil {
+			return err
+		}
+	}
 
-	if fs.NArg() != 3 {
+	pos, err := expandSaved(fs.Args())
+	if err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
 		fs.Usage()
-		return fmt.Errorf("expected 3 arguments, got %d", fs.NArg())
+		return fmt.Errorf("expected 3 arguments, got %d", len(pos))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0]); err != nil {
+		return err
 	}
 
-	t, err := time.Parse(time.RFC3339, fs.Arg(0))
+	t, err := tz.opts.ParseDateTime(pos[0])
 	if err != nil {
-		return fmt.Errorf("invalid datetime %q: %w", fs.Arg(0), err)
+		return err
 	}
-	t = t.UTC()
 
-	lat, err := strconv.ParseFloat(fs.Arg(1), 64)
+	// --house-system all builds the chart without houses, then compares
+	// the houses of every system that can be cast.
+	compareHouses := strings.EqualFold(*houseSystemFlag, "all")
+	systemName := *houseSystemFlag
+	if compareHouses {
+		systemName = houseSystems[0]
+	}
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(systemName)
 	if err != nil {
-		return fmt.Errorf("invalid latitude %q: %w", fs.Arg(1), err)
+		return err
 	}
 
-	lon, err := strconv.ParseFloat(fs.Arg(2), 64)
+	nodeBodies, err := parseNodes(*nodesFlag)
 	if err != nil {
-		return fmt.Errorf("invalid longitude %q: %w", fs.Arg(2), err)
+		return err
+	}
+	planets, err := chartBodies(*planetsFlag, nodeBodies)
+	if err != nil {
+		return err
+	}
+	points, err := parsePoints(*pointsFlag)
+	if err != nil {
+		return err
+	}
+	if len(points) > 0 && *observerFlag != "" {
+		return fmt.Errorf("--points needs a terrestrial chart; it cannot be combined with --observer")
+	}
+
+	var sidMode ayanamsa
+	sidName, sidFlags := "", 0
+	if *siderealFlag != "" {
+		if sidMode, sidName, err = parseAyanamsa(*siderealFlag); err != nil {
+			return err
+		}
+		sidFlags = swisseph.FlagSidereal
+	}
+	var varga vedic.Varga
+	if *vargaFlag != "" {
+		if sidFlags == 0 {
+			return fmt.Errorf("--varga needs the sidereal zodiac, e.g. --sidereal lahiri")
+		}
+		if varga, err = vedic.ParseVarga(*vargaFlag); err != nil {
+			return err
+		}
+	}
+
+	if *horaryFlag && (*observerFlag != "" || varga != 0) {
+		return fmt.Errorf("--horary needs a terrestrial chart; it cannot be combined with --observer or --varga")
+	}
+	if *visibilityFlag && (*observerFlag != "" || varga != 0) {
+		return fmt.Errorf("--visibility needs a terrestrial chart; it cannot be combined with --observer or --varga")
+	}
+
+	var modernRulers bool
+	switch *rulersFlag {
+	case "", "traditional":
+	case "modern":
+		modernRulers = true
+	default:
+		return fmt.Errorf("unknown rulership scheme %q: valid values are traditional, modern", *rulersFlag)
+	}
+	if *rulersFlag != "" && (*observerFlag != "" || varga != 0) {
+		return fmt.Errorf("--rulers needs a terrestrial chart; it cannot be combined with --observer or --varga")
+	}
+	if compareHouses {
+		if *observerFlag != "" || varga != 0 || *horaryFlag || *rulersFlag != "" || *tychonicFlag || *solarTimeFlag || *visibilityFlag || len(points) > 0 {
+			return fmt.Errorf("--house-system all compares the houses alone; it cannot be combined with --observer, --varga, --horary, --rulers, --tychonic, --solar-time, --visibility or --points")
+		}
+		if out.tmpl != nil || out.resolved != "text" && out.resolved != "json" {
+			return fmt.Errorf("--house-system all writes text or JSON only")
+		}
 	}
 
-	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	backend, err := parseBackend(*ephemerisFlag)
 	if err != nil {
 		return err
 	}
+	rec := newRecorder(*timingsFlag, start)
 
-	exe, err := os.Executable()
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	if sidFlags != 0 {
+		sidMode.set()
+	}
+	nameAsteroids(planets)
+
+	jd := swisseph.JulDayFromTime(t)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, sidFlags))
+	var r output.Result
+	if *observerFlag != "" {
+		r, err = buildObserverSky(*observerFlag, jd, planets, backend, sidFlags, rec)
+	} else if compareHouses {
+		r, err = output.BuildSky(p, jd, planets)
+		if err == nil {
+			err = output.AddSources(&r, p)
+		}
+	} else {
+		r, err = polar.build(p, jd, planets, lat, lon, hsys, hsysName)
+		if err == nil {
+			err = output.AddSources(&r, p)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	r.Local = tz.local(pos[0])
+	if *solarTimeFlag {
+		if r.SolarTime, err = solarTime(jd, lon); err != nil {
+			return err
+		}
+	}
+	if len(points) > 0 {
+		if err := output.AddPoints(&r, p, points); err != nil {
+			return err
+		}
+	}
+
+	if len(nodeBodies) == 2 && *observerFlag == "" {
+		if err := output.AddNodeDivergence(&r, p, nodes.DefaultThreshold); err != nil {
+			return err
+		}
+	}
+
+	if *tychonicFlag {
+		helio := rec.Wrap(newProvider(backend, swisseph.FlagHeliocentric|sidFlags))
+		if err := output.AddHeliocentric(&r, helio, []int{swisseph.Earth}); err != nil {
+			return err
+		}
+	}
+
+	if sidFlags != 0 {
+		aya, err := swisseph.Ayanamsa(jd, backendFlag(backend))
+		if err != nil {
+			return fmt.Errorf("error calculating ayanamsa: %w", err)
+		}
+		r.Sidereal = &output.SiderealInfo{Ayanamsa: sidName, Degrees: aya}
+		output.AddNakshatras(&r)
+	}
+	if *horaryFlag {
+		ruler, err := hourRuler(jd, lat, lon, backendFlag(backend))
+		if err != nil {
+			return err
+		}
+		if err := output.AddHorary(&r, p, ruler); err != nil {
+			return err
+		}
+	}
+	if *visibilityFlag {
+		if err := output.AddVisibility(&r, p, sight(jd, lat, lon, backendFlag(backend))); err != nil {
+			return err
+		}
+	}
+	if *rulersFlag != "" {
+		if err := output.AddRulers(&r, p, modernRulers); err != nil {
+			return err
+		}
+	}
+	if *weightedFlag && r.Balance != nil {
+		output.AddBalance(&r, true)
+	}
+	if varga != 0 {
+		output.ApplyVarga(&r, varga)
+	}
+	if *verboseFlag {
+		if err := writeWarnings(r.Warnings); err != nil {
+			return err
+		}
+		out.sources = true
+	}
+	if compareHouses {
+		return writeHouseComparison(r, p, planets, lat, lon, polar, out, rec, args, backend)
+	}
+	rec.Mark("compute")
+
+	r.Metadata = chartMetadata("chart", args, backend)
+	if err := out.write(r); err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "chart", backend)
+}
+
+// writeHouseComparison builds chart r, which has no houses, in each house
+// system of comparedHouseSystems and writes the houses of all side by
+// side. Within a polar circle, the systems that cannot be cast there are
+// cast in the --polar-fallback system or, without one, left out and named.
+func writeHouseComparison(r output.Result, p ephemeris.Provider, planets []int, lat, lon float64, polar *polarFallback, out *chartOutput, rec *timing.Recorder, args []string, backend string) error {
+	var charts []output.Result
+	var skipped []string
+	for _, hsys := range comparedHouseSystems() {
+		hsysName := houseDisplayName(hsys)
+		c, err := polar.build(p, r.JulianDay, planets, lat, lon, hsys, hsysName)
+		var pe *swisseph.PolarError
+		if errors.As(err, &pe) {
+			skipped = append(skipped, hsysName)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s houses: %w", hsysName, err)
+		}
+		charts = append(charts, c)
+	}
+	charts[0].Local, charts[0].Sidereal, charts[0].Sources = r.Local, r.Sidereal, r.Sources
+	charts[0].Metadata = chartMetadata("chart", args, backend)
+	c := output.CompareHouses(charts)
+	c.Skipped = skipped
+	rec.Mark("compute")
+
+	err := writeOutput(*out.file, func(w io.Writer) error {
+		if out.resolved == "json" {
+			return output.WriteHouseComparisonJSON(w, c, output.JSONOptions{Compact: *out.compact})
+		}
+		return output.WriteHouseComparisonText(w, c)
+	})
 	if err != nil {
-		return fmt.Errorf("could not resolve executable path: %w", err)
+		return internal(err)
 	}
-	swisseph.SetEphePath(filepath.Join(filepath.Dir(exe), "ephe"))
-	defer swisseph.Close()
+	rec.Mark("render")
+	return writeTimings(rec, "chart", backend)
+}
 
-	decimalHour := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
-	jd := swisseph.JulDay(t.Year(), int(t.Month()), t.Day(), decimalHour)
+// buildObserverSky computes the planetocentric sky seen from the named
+// body: the chart's bodies but the nodes, with Earth in place of the
+// observer. flags are added to the backend's, as for newProvider.
+func buildObserverSky(name string, jd float64, bodies []int, backend string, flags int, rec *timing.Recorder) (output.Result, error) {
+	center, err := parseBody(name)
+	if err != nil {
+		return output.Result{}, err
+	}
+	if center == swisseph.Sun {
+		return output.Result{}, fmt.Errorf("invalid observer %q: use --tychonic for heliocentric positions", name)
+	}
 
-	planets := []int{
-		swisseph.Sun, swisseph.Moon, swisseph.Mercury,
-		swisseph.Venus, swisseph.Mars, swisseph.Jupiter,
-		swisseph.Saturn,
+	planets := []int{swisseph.Earth}
+	for _, body := range bodies {
+		if body != center && body != swisseph.MeanNode && body != swisseph.TrueNode {
+			planets = append(planets, body)
+		}
 	}
+	p := rec.Wrap(swiss.CentricProvider{Center: center, Flags: backendFlag(backend) | flags})
+	r, err := output.BuildSky(p, jd, planets)
+	if err != nil {
+		return output.Result{}, err
+	}
+	r.Observer = names.Body(center)
+	return r, nil
+}
 
-	r, err := output.Build(jd, planets, lat, lon, hsys, hsysName)
+// sight returns the output.Sight of the bodies at jd from the place at
+// lat, lon, for --visibility: their apparent altitude, and above the
+// horizon their magnitude and the limit of the sky there, from the
+// library's visibility model.
+func sight(jd, lat, lon float64, flags int) output.Sight {
+	return func(body int) (output.Sighting, error) {
+		_, alt, err := swisseph.AzAlt(jd, body, lat, lon, flags)
+		if err != nil {
+			return output.Sighting{}, err
+		}
+		v, err := swisseph.VisLimitMag(jd, body, lat, lon, flags)
+		if errors.Is(err, swisseph.ErrBelowHorizon) {
+			return output.Sighting{Altitude: alt}, nil
+		}
+		if err != nil {
+			return output.Sighting{}, err
+		}
+		return output.Sighting{Altitude: alt, Risen: true, Magnitude: v.Magnitude, Limit: v.LimitMag}, nil
+	}
+}
+
+// keepOpen is set while astro repl or astro mcp runs commands: it opens
+// the ephemeris once, and setEphePath and closeEphemeris leave it alone.
+var keepOpen bool
+
+// openSession opens the ephemeris for a session of commands run through
+// Run, and sets keepOpen until the returned func closes it. Sessions do
+// not nest.
+func openSession() (func(), error) {
+	if keepOpen {
+		return nil, fmt.Errorf("already in a session of astro commands")
+	}
+	if err := setEphePath(); err != nil {
+		return nil, err
+	}
+	keepOpen = true
+	return func() {
+		keepOpen = false
+		swisseph.Close()
+	}, nil
+}
+
+// setEphePath points the library at the ephe/ directory next to the
+// executable. Callers must defer closeEphemeris.
+func setEphePath() error {
+	if keepOpen {
+		return nil
+	}
+	dir, err := epheDir()
 	if err != nil {
 		return err
 	}
+	swisseph.SetEphePath(dir)
+	return nil
+}
 
-	if *jsonFlag {
-		return output.PrintJSON(r)
+// epheDir returns the ephemeris directory, ephe/ beside the executable.
+func epheDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", internal(fmt.Errorf("could not resolve executable path: %w", err))
 	}
-	return output.PrintText(r)
+	return filepath.Join(filepath.Dir(exe), "ephe"), nil
 }
 
-func parseHouseSystem(name string) (code byte, displayName string, err error) {
+// closeEphemeris closes the ephemeris files and frees the library's
+// memory, unless astro repl keeps them open for its next command.
+func closeEphemeris() {
+	if !keepOpen {
+		swisseph.Close()
+	}
+}
+
+// parseNodes maps the --nodes flag to the node bodies to include.
+func parseNodes(name string) ([]int, error) {
 	switch strings.ToLower(name) {
-	case "placidus":
-		return swisseph.HousePlacidus, "Placidus", nil
-	case "koch":
-		return swisseph.HouseKoch, "Koch", nil
-	case "whole-sign":
-		return swisseph.HouseWholeSign, "Whole Sign", nil
-	case "regiomontanus":
-		return swisseph.HouseRegiomontanus, "Regiomontanus", nil
-	case "equal":
-		return swisseph.HouseEqual, "Equal", nil
-	case "campanus":
-		return swisseph.HouseCampanus, "Campanus", nil
+	case "none":
+		return nil, nil
+	case "mean":
+		return []int{swisseph.MeanNode}, nil
+	case "true":
+		return []int{swisseph.TrueNode}, nil
+	case "both":
+		return []int{swisseph.MeanNode, swisseph.TrueNode}, nil
 	default:
-		return 0, "", fmt.Errorf("unknown house system %q: valid values are placidus, koch, whole-sign, regiomontanus, equal, campanus", name)
+		return nil, fmt.Errorf("unknown nodes option %q: valid values are none, mean, true, both", name)
+	}
+}
+
+// houseSystems are the common house systems, which --house-system all
+// compares first, in this order.
+var houseSystems = []string{"placidus", "koch", "whole-sign", "regiomontanus", "equal", "campanus"}
+
+// comparedHouseSystems returns the codes of the systems --house-system all
+// compares: those of houseSystems, then every other system the library
+// casts, in the order of swisseph.HouseSystems.
+func comparedHouseSystems() []byte {
+	var common, others []byte
+	for _, c := range swisseph.HouseSystems() {
+		if slices.Contains(houseSystems, houseKeyword(c)) {
+			common = append(common, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	slices.SortFunc(common, func(a, b byte) int {
+		return slices.Index(houseSystems, houseKeyword(a)) - slices.Index(houseSystems, houseKeyword(b))
+	})
+	return append(common, others...)
+}
+
+// houseSystemUsage describes --house-system for the commands' flags.
+const houseSystemUsage = "House system: placidus, koch, whole-sign, regiomontanus, equal, campanus, or another the Swiss Ephemeris casts, such as porphyry or alcabitius"
+
+// houseKeywords and houseNames are the --house-system names and display
+// names of the systems whose names predate swisseph.HouseName, where they
+// differ from the library's.
+var (
+	houseKeywords = map[byte]string{swisseph.HouseWholeSign: "whole-sign"}
+	houseNames    = map[byte]string{swisseph.HouseWholeSign: "Whole Sign", swisseph.HouseEqual: "Equal"}
+)
+
+// houseKeyword returns the --house-system name of a house system: its
+// library name in lower case, with hyphens for spaces and punctuation, so
+// "Polich/Page" is polich-page.
+func houseKeyword(code byte) string {
+	if k, ok := houseKeywords[code]; ok {
+		return k
+	}
+	words := strings.FieldsFunc(strings.ToLower(swisseph.HouseName(code)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// houseDisplayName returns the English display name of a house system,
+// the library's with a capital.
+func houseDisplayName(code byte) string {
+	if n, ok := houseNames[code]; ok {
+		return n
+	}
+	name := swisseph.HouseName(code)
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// parseHouseSystem looks a --house-system name up among the systems the
+// library casts.
+func parseHouseSystem(name string) (code byte, displayName string, err error) {
+	var others []string
+	for _, c := range swisseph.HouseSystems() {
+		k := houseKeyword(c)
+		if strings.EqualFold(name, k) {
+			return c, houseDisplayName(c), nil
+		}
+		if !slices.Contains(houseSystems, k) {
+			others = append(others, k)
+		}
 	}
+	sort.Strings(others)
+	valid := append(slices.Clone(houseSystems), others...)
+	return 0, "", fmt.Errorf("unknown house system %q: valid values are %s", name, strings.Join(valid, ", "))
 }
@@ -1,11 +1,79 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"text/template"
+	"time"
 
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/names"
 	"github.com/dcccxiii/astro/swisseph"
 )
 
+func TestParseCardinalSigns(t *testing.T) {
+	signs, err := parseCardinalSigns("Libra, aries,libra")
+	if err != nil || !slices.Equal(signs, []int{0, 6}) {
+		t.Errorf("parseCardinalSigns = %v, %v; want [0 6]", signs, err)
+	}
+	if _, err := parseCardinalSigns("aries,leo"); err == nil {
+		t.Error("parseCardinalSigns(aries,leo) succeeded, want an error")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	cases := []struct {
+		in         string
+		begin, end time.Duration
+	}{
+		{"06:00-12:00", 6 * time.Hour, 12 * time.Hour},
+		{"00:00-24:00", 0, 24 * time.Hour},
+		{"22:30-01:15", 22*time.Hour + 30*time.Minute, 25*time.Hour + 15*time.Minute},
+	}
+	for _, c := range cases {
+		begin, end, err := parseWindow(c.in)
+		if err != nil || begin != c.begin || end != c.end {
+			t.Errorf("parseWindow(%q) = %v, %v, %v; want %v, %v", c.in, begin, end, err, c.begin, c.end)
+		}
+	}
+	for _, in := range []string{"06:00", "06:00-06:00", "6-12", "06:60-07:00", "23:00-24:30"} {
+		if _, _, err := parseWindow(in); err == nil {
+			t.Errorf("parseWindow(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestLoadLifeEvents(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.txt")
+	if err := os.WriteFile(file, []byte("# life\n2012-06-15 married in Rome\n\n2015-03-01T09:00:00Z\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	events, err := loadLifeEvents(file, input.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Name != "married in Rome" || events[1].Name != "2015-03-01T09:00:00Z" {
+		t.Fatalf("events = %+v", events)
+	}
+	if want := ephemeris.JulianDay(time.Date(2012, 6, 15, 12, 0, 0, 0, time.UTC)); events[0].JD != want {
+		t.Errorf("married at JD %v, want %v", events[0].JD, want)
+	}
+	os.WriteFile(file, []byte("15/06/2012 married\n"), 0o644)
+	if _, err := loadLifeEvents(file, input.Default()); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("bad date: err = %v, want one naming line 1", err)
+	}
+}
+
 func TestParseHouseSystem(t *testing.T) {
 	cases := []struct {
 		input       string
@@ -28,7 +96,12 @@ func TestParseHouseSystem(t *testing.T) {
 		// Invalid inputs
 		{"", 0, "", true},
 		{"unknown", 0, "", true},
-		{"porphyry", 0, "", true},
+		// Systems named by the Swiss Ephemeris
+		{"porphyry", 'O', "Porphyry", false},
+		{"polich-page", 'T', "Polich/Page", false},
+		{"equal-mc", 'D', "Equal (MC)", false},
+		// all is a mode of astro chart, not a system
+		{"all", 0, "", true},
 	}
 
 	for _, tc := range cases {
@@ -51,4 +124,1089 @@ func TestParseHouseSystem(t *testing.T) {
 			}
 		})
 	}
+
+	// --house-system all compares every system parseHouseSystem knows,
+	// the common ones first.
+	for _, name := range houseSystems {
+		if _, _, err := parseHouseSystem(name); err != nil {
+			t.Errorf("houseSystems: %v", err)
+		}
+	}
+	compared := comparedHouseSystems()
+	if len(compared) != len(swisseph.HouseSystems()) {
+		t.Errorf("comparedHouseSystems() = %q, want the %d of swisseph.HouseSystems()", compared, len(swisseph.HouseSystems()))
+	}
+	for i, name := range houseSystems {
+		if k := houseKeyword(compared[i]); k != name {
+			t.Errorf("comparedHouseSystems()[%d] = %s, want %s", i, k, name)
+		}
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	pairs, err := parsePairs("jupiter-saturn, Uranus-Pluto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][2]int{{swisseph.Jupiter, swisseph.Saturn}, {swisseph.Uranus, swisseph.Pluto}}
+	if len(pairs) != len(want) || pairs[0] != want[0] || pairs[1] != want[1] {
+		t.Errorf("pairs = %v, want %v", pairs, want)
+	}
+
+	for _, bad := range []string{"jupiter", "jupiter-jupiter", "sun-saturn", "jupiter-vulcan", ""} {
+		if _, err := parsePairs(bad); err == nil {
+			t.Errorf("parsePairs(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParsePhases(t *testing.T) {
+	set, err := parsePhases("conjunction,waning-square")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(set) != 2 || !set["conjunction"] || !set["waning square"] {
+		t.Errorf("set = %v", set)
+	}
+	if all, _ := parsePhases("all"); len(all) != 4 {
+		t.Errorf("all phases = %v", all)
+	}
+	if _, err := parsePhases("trine"); err == nil {
+		t.Error("expected error for unknown phase")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	year := fs.Int("year", 0, "")
+	js := fs.Bool("json", false, "")
+	reloc := fs.String("relocated", "", "")
+
+	pos, err := parseArgs(fs, []string{"solar", "--json", "1990-01-01T00:00:00Z", "40.7", "-74.006", "--year", "2025", "--relocated", "-33.9,151.2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"solar", "1990-01-01T00:00:00Z", "40.7", "-74.006"}
+	if strings.Join(pos, " ") != strings.Join(want, " ") {
+		t.Errorf("positional = %q, want %q", pos, want)
+	}
+	if *year != 2025 || !*js || *reloc != "-33.9,151.2" {
+		t.Errorf("flags: year=%d json=%v relocated=%q", *year, *js, *reloc)
+	}
+
+	if _, err := parseArgs(fs, []string{"--bogus"}); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestJoinCoordFlag(t *testing.T) {
+	cases := []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"--relocated", "40.7", "-74.0", "--json"}, []string{"--relocated=40.7,-74.0", "--json"}},
+		{[]string{"-relocated", "40.7,-74.0"}, []string{"-relocated", "40.7,-74.0"}},
+		{[]string{"--json", "--relocated"}, []string{"--json", "--relocated"}},
+	}
+	for _, tc := range cases {
+		got := joinCoordFlag(tc.in, "relocated")
+		if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+			t.Errorf("joinCoordFlag(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTakeFlag(t *testing.T) {
+	cases := []struct {
+		in        []string
+		wantValue string
+		wantRest  []string
+	}{
+		{[]string{"--json"}, "synastry", []string{"--json"}},
+		{[]string{"--mode", "davison", "--json"}, "davison", []string{"--json"}},
+		{[]string{"--json", "-mode=composite"}, "composite", []string{"--json"}},
+		{[]string{"--orb", "-3", "--", "--mode"}, "synastry", []string{"--orb", "-3", "--", "--mode"}},
+	}
+	for _, tc := range cases {
+		value, rest, err := takeFlag(tc.in, "mode", "synastry")
+		if err != nil || value != tc.wantValue || !slices.Equal(rest, tc.wantRest) {
+			t.Errorf("takeFlag(%q) = %q, %q, %v; want %q, %q", tc.in, value, rest, err, tc.wantValue, tc.wantRest)
+		}
+	}
+	if _, _, err := takeFlag([]string{"--mode"}, "mode", ""); err == nil {
+		t.Error("takeFlag(--mode): expected error for the missing value")
+	}
+}
+
+func TestParseNodes(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"mean", []int{swisseph.MeanNode}, false},
+		{"True", []int{swisseph.TrueNode}, false},
+		{"both", []int{swisseph.MeanNode, swisseph.TrueNode}, false},
+		{"osculating", nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parseNodes(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	for _, name := range []string{"swiss", "Moshier", "JPL"} {
+		b, err := parseBackend(name)
+		if err != nil {
+			t.Errorf("parseBackend(%q): %v", name, err)
+		}
+		if b != strings.ToLower(name) {
+			t.Errorf("parseBackend(%q) = %q, want %q", name, b, strings.ToLower(name))
+		}
+	}
+	if _, err := parseBackend("vsop87"); err == nil {
+		t.Error("parseBackend(\"vsop87\"): expected error")
+	}
+}
+
+func TestParseAyanamsa(t *testing.T) {
+	a, name, err := parseAyanamsa("Lahiri")
+	if err != nil || a.mode != swisseph.SidmLahiri || name != "Lahiri" {
+		t.Errorf("parseAyanamsa(\"Lahiri\") = %+v, %q, %v", a, name, err)
+	}
+	if _, _, err := parseAyanamsa("tropical"); err == nil {
+		t.Error("parseAyanamsa(\"tropical\"): expected error")
+	}
+
+	a, name, err = parseAyanamsa("custom:t0=2451545.0,value=24.83")
+	if want := (ayanamsa{swisseph.SidmUser, 2451545, 24.83}); err != nil || a != want || name != "Custom (24.83° at JD 2451545)" {
+		t.Errorf("parseAyanamsa(custom) = %+v, %q, %v; want %+v", a, name, err, want)
+	}
+	for _, bad := range []string{"custom:", "custom:t0=2451545", "custom:value=24", "custom:t0=x,value=24", "custom:t0=1,value=2,t0=3", "custom:t0=1,value=2,epoch=3"} {
+		if _, _, err := parseAyanamsa(bad); err == nil {
+			t.Errorf("parseAyanamsa(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParseBodies(t *testing.T) {
+	got, err := parseBodies("sun, Saturn,pluto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{swisseph.Sun, swisseph.Saturn, swisseph.Pluto}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseBodies = %v, want %v", got, want)
+	}
+	if _, err := parseBodies("sun,vulcan"); err == nil {
+		t.Error("parseBodies(\"sun,vulcan\"): expected error")
+	}
+
+	got, err = parseBodies("mars..saturn,sun..sun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []int{swisseph.Mars, swisseph.Jupiter, swisseph.Saturn, swisseph.Sun}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseBodies = %v, want %v", got, want)
+	}
+	if _, err := parseBodies("pluto..sun"); err == nil {
+		t.Error("parseBodies(\"pluto..sun\"): expected error")
+	}
+
+	got, err = parseBodies("classical, Chiron,433,mean-node,sun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(append([]int(nil), chartPlanets...), swisseph.Chiron, swisseph.AstOffset+433, swisseph.MeanNode)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseBodies = %v, want %v", got, want)
+	}
+	for set, n := range map[string]int{"classical": 7, "modern": 10, "all": 16} {
+		if got, err := parseBodies(set); err != nil || len(got) != n {
+			t.Errorf("parseBodies(%q) = %v, %v; want %d bodies", set, got, err, n)
+		}
+	}
+	for _, bad := range []string{"0", "-433", "chiron..vesta", "sun..all"} {
+		if _, err := parseBodies(bad); err == nil {
+			t.Errorf("parseBodies(%q): expected error", bad)
+		}
+	}
+
+	got, err = chartBodies("sun,moon,true-node", []int{swisseph.MeanNode, swisseph.TrueNode})
+	want = []int{swisseph.Sun, swisseph.Moon, swisseph.TrueNode, swisseph.MeanNode}
+	if err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("chartBodies = %v, %v; want %v", got, err, want)
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	got, err := parsePoints("vertex, Lilith,node,vertex")
+	want := []string{names.Vertex, names.Lilith, names.NorthNode}
+	if err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parsePoints = %v, %v; want %v", got, err, want)
+	}
+	if got, err := parsePoints(""); err != nil || got != nil {
+		t.Errorf("parsePoints(\"\") = %v, %v; want none", got, err)
+	}
+	got, err = parsePoints("hades,uranian")
+	want = []string{names.Hades, names.Cupido, names.Zeus, names.Kronos, names.Apollon, names.Admetos, names.Vulkanus, names.Poseidon, names.Transpluto}
+	if err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parsePoints = %v, %v; want %v", got, err, want)
+	}
+	if _, err := parsePoints("vertex,antivertex"); err == nil {
+		t.Error("parsePoints(\"vertex,antivertex\"): expected error")
+	}
+}
+
+func TestParseChartSpecs(t *testing.T) {
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	joined := []string{"1990-01-09T14:30:00Z,51.5,-0.12", "1992-06-01T08:00:00Z, 40.7, -74"}
+	split := []string{"1990-01-09T14:30:00Z", "51.5", "-0.12", "1992-06-01T08:00:00Z", "40.7", "-74"}
+	mixed := []string{"1990-01-09T14:30:00Z,51.5,-0.12", "1992-06-01T08:00:00Z", "40.7", "-74"}
+	for _, pos := range [][]string{joined, split, mixed} {
+		specs, err := parseChartSpecs(tz, pos, 2)
+		if err != nil {
+			t.Fatalf("parseChartSpecs(%q): %v", pos, err)
+		}
+		if specs[0].Lat != 51.5 || specs[1].Lon != -74 || specs[1].Time.Year() != 1992 {
+			t.Errorf("parseChartSpecs(%q) = %+v", pos, specs)
+		}
+	}
+
+	// Local times are read in the zone of each chart's coordinates.
+	specs, err := parseChartSpecs(tz, []string{"1990-07-01T12:00:00,52.52,13.405", "1990-07-01T12:00:00,40.7,-74"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if specs[0].Time.Hour() != 10 || specs[1].Time.Hour() != 16 {
+		t.Errorf("parseChartSpecs(local times) = %+v; want 10:00 and 16:00 UTC", specs)
+	}
+
+	bad := [][]string{
+		{"1990-01-09T14:30:00Z,51.5"},
+		{"1990-01-09T14:30:00Z", "51.5", "-0.12"},
+		{"1990-01-09T14:30:00Z,north,-0.12", "1992-06-01T08:00:00Z,40.7,-74"},
+	}
+	for _, pos := range bad {
+		if _, err := parseChartSpecs(tz, pos, 2); err == nil {
+			t.Errorf("parseChartSpecs(%q): expected error", pos)
+		}
+	}
+}
+
+func TestSaveCharts(t *testing.T) {
+	t.Setenv("ASTRO_CHARTS", filepath.Join(t.TempDir(), "charts.json"))
+	if err := runSave([]string{"Alice", "1990-01-09T15:30:00", "52.52", "13.405"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSave([]string{"Bob", "1992-06-01T08:00:00Z", "40.7", "-74"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSave([]string{"alice", "now", "0", "0"}); err == nil {
+		t.Error("saving alice again without --force: expected error")
+	}
+	c, err := savedChart("alice")
+	if err != nil || c.DateTime != "1990-01-09T15:30:00+01:00[Europe/Berlin]" {
+		t.Errorf("savedChart(alice) = %+v, %v; want the local time in Europe/Berlin", c, err)
+	}
+	if _, err := savedChart("Alise"); err == nil || !strings.Contains(err.Error(), "did you mean Alice?") {
+		t.Errorf("savedChart(Alise) = %v; want a suggestion of Alice", err)
+	}
+
+	pos, err := expandSaved([]string{"Alice", "1992-06-01T08:00:00Z,40.7,-74", "Carol"})
+	want := []string{"1990-01-09T15:30:00+01:00[Europe/Berlin]", "52.52", "13.405", "1992-06-01T08:00:00Z,40.7,-74", "Carol"}
+	if err != nil || !slices.Equal(pos, want) {
+		t.Errorf("expandSaved = %q, %v; want %q", pos, err, want)
+	}
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	specs, err := parseChartSpecs(tz, []string{"Alice", "bob"}, 2)
+	if err != nil || specs[0].Time.Hour() != 14 || specs[1].Lat != 40.7 {
+		t.Errorf("parseChartSpecs(Alice, bob) = %+v, %v", specs, err)
+	}
+
+	if err := runCompare([]string{"Alice", "Carol"}); err == nil || !strings.Contains(err.Error(), `"Carol"`) {
+		t.Errorf("runCompare(Alice, Carol) = %v; want an error for Carol", err)
+	}
+	if err := runCompare([]string{"Alice", "Bob", "--mode", "tarot"}); err == nil {
+		t.Error("runCompare with --mode tarot: expected error")
+	}
+
+	for _, name := range []string{"", "now", "2024-03-20", "51.5", "51N30", "-x", "Alice,Bob"} {
+		if checkChartName(name) == nil {
+			t.Errorf("checkChartName(%q): expected error", name)
+		}
+	}
+}
+
+func TestSavedDateTime(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	for _, tc := range []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(1990, 7, 1, 12, 0, 0, 0, berlin), "1990-07-01T12:00:00+02:00[Europe/Berlin]"},
+		{time.Date(1990, 7, 1, 12, 0, 0, 0, time.UTC), "1990-07-01T12:00:00Z"},
+		{time.Date(1990, 7, 1, 12, 0, 0, 0, time.FixedZone("", -5*3600)), "1990-07-01T12:00:00-05:00"},
+		{time.Date(1890, 7, 1, 12, 0, 0, 0, time.FixedZone("LMT", 3600+30)), "1890-07-01T10:59:30Z"},
+	} {
+		if got := savedDateTime(tc.t); got != tc.want {
+			t.Errorf("savedDateTime(%v) = %s, want %s", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestParseChartMoment(t *testing.T) {
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	want := time.Date(1990, 1, 9, 14, 30, 0, 0, time.UTC)
+	for _, pos := range [][]string{
+		{"1990-01-09T14:30:00Z"},
+		{"1990-01-09T14:30:00Z,51.5,-0.12"},
+		{"1990-01-09T14:30:00Z", "51.5", "-0.12"},
+	} {
+		got, err := parseChartMoment(tz, pos)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseChartMoment(%q) = %v, %v; want %v", pos, got, err, want)
+		}
+	}
+	if _, err := parseChartMoment(tz, []string{"1990-01-09T14:30:00Z", "51.5"}); err == nil {
+		t.Error("parseChartMoment with a latitude only: expected error")
+	}
+}
+
+func TestReadChartsCSV(t *testing.T) {
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	if err := tz.apply(); err != nil {
+		t.Fatal(err)
+	}
+	in := "Latitude,Longitude,datetime,name,dst\n" +
+		"51.5,-0.12,1990-07-01T15:30:00+01:00,Jane,1\n" +
+		"40.7,-74,2000-01-01T07:00:00-05:00,,\n"
+	recs, err := readChartsCSV(strings.NewReader(in), tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("read %d charts, want 2", len(recs))
+	}
+	if r := recs[0]; r.Name != "Jane" || r.Zone != 0 || r.DST != time.Hour || !r.Time.Equal(time.Date(1990, 7, 1, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("chart 1 = %+v; want Jane at 14:30 UTC, zone 0, DST 1h", r)
+	}
+	if r := recs[1]; r.Zone != -5*time.Hour || r.DST != 0 || r.Lon != -74 {
+		t.Errorf("chart 2 = %+v; want zone -5h at longitude -74", r)
+	}
+
+	// A local datetime takes the zone at the coordinates, and before
+	// standard time the local mean time of the longitude.
+	recs, err = readChartsCSV(strings.NewReader("datetime,latitude,longitude\n1990-07-01T15:30:00,40.7,-74\n1880-01-01T12:00:00,40.7,-74\n"), tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := recs[0]; r.Zone != -4*time.Hour || !r.Time.Equal(time.Date(1990, 7, 1, 19, 30, 0, 0, time.UTC)) {
+		t.Errorf("local chart = %+v; want 19:30 UTC, zone -4h", r)
+	}
+	if r := recs[1]; r.Zone != -(4*time.Hour+56*time.Minute) || !r.Time.Equal(time.Date(1880, 1, 1, 16, 56, 0, 0, time.UTC)) {
+		t.Errorf("LMT chart = %+v; want 16:56 UTC, zone -4h56m", r)
+	}
+
+	for _, bad := range []string{
+		"name,latitude,longitude\nx,1,2\n",
+		"datetime,latitude,longitude\n1990-07-01 noon,51.5,-0.12\n",
+		"datetime,latitude,longitude,dst\n1990-07-01T15:30:00Z,51.5,-0.12,summer\n",
+	} {
+		if _, err := readChartsCSV(strings.NewReader(bad), tz); err == nil {
+			t.Errorf("readChartsCSV(%q): expected error", bad)
+		}
+	}
+}
+
+func TestReadBatch(t *testing.T) {
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	tz.coordinates = true
+	if err := tz.apply(); err != nil {
+		t.Fatal(err)
+	}
+	csvIn := "name,datetime,Latitude,lon,tz\n" +
+		"Jane,1990-07-01T15:30:00,51N30,0W07,America/New_York\n" +
+		",1990-07-01T15:30:00,40.7,-74,\n" +
+		"UT,2000-01-01T12:00:00Z,0,0,\n"
+	jsonIn := `[{"name": "Jane", "datetime": "1990-07-01T15:30:00", "lat": "51N30", "lon": "0W07", "tz": "America/New_York"},
+		{"datetime": "1990-07-01T15:30:00", "lat": 40.7, "lon": -74}]
+		{"name": "UT", "datetime": "2000-01-01T12:00:00Z", "lat": 0, "lon": 0}`
+	for _, in := range []string{csvIn, jsonIn} {
+		rows, err := readBatch(strings.NewReader(in), tz)
+		if err != nil {
+			t.Fatalf("readBatch(%q): %v", in, err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("read %d charts, want 3", len(rows))
+		}
+		if r := rows[0]; r.name != "Jane" || r.lat != 51.5 || !r.time.Equal(time.Date(1990, 7, 1, 19, 30, 0, 0, time.UTC)) || r.local == nil || r.local.Source != "tz" {
+			t.Errorf("chart 1 = %+v; want Jane at 19:30 UTC in the record's tz", r)
+		}
+		if r := rows[1]; !r.time.Equal(time.Date(1990, 7, 1, 19, 30, 0, 0, time.UTC)) || r.local == nil || r.local.Source != "coordinates" {
+			t.Errorf("chart 2 = %+v; want 19:30 UTC in the zone at the coordinates", r)
+		}
+		if r := rows[2]; r.local != nil || strings.Join(r.args, " ") != "2000-01-01T12:00:00Z 0 0" {
+			t.Errorf("chart 3 = %+v; want UT with no local time", r)
+		}
+	}
+
+	for _, c := range []struct{ in, where string }{
+		{"name,lat,lon\nx,1,2\n", "datetime column"},
+		{"datetime,lat,lon\n2000-01-01T12:00:00Z,1,2\n2000-01-01T12:00:00Z,91,2\n", "row 3"},
+		{"datetime,lat,lon,tz\n2000-01-01T12:00:00,1,2,Mars/Olympus\n", "row 2"},
+		{`{"datetime": "2000-01-01T12:00:00Z", "lat": 1, "long": 2}`, "long"},
+		{`[{"datetime": "noon", "lat": 1, "lon": 2}]`, "record 1"},
+	} {
+		if _, err := readBatch(strings.NewReader(c.in), tz); err == nil || !strings.Contains(err.Error(), c.where) {
+			t.Errorf("readBatch(%q) = %v, want an error about %s", c.in, err, c.where)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	var in strings.Builder
+	in.WriteString("name,datetime,lat,lon\n")
+	for day := 1; day <= 12; day++ {
+		fmt.Fprintf(&in, "Chart %d,2024-03-%02dT12:00:00Z,51.5,-0.13\n", day, day)
+	}
+	file := filepath.Join(dir, "charts.csv")
+	if err := os.WriteFile(file, []byte(in.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := filepath.Join(dir, "charts.ndjson")
+	if err := Run([]string{"batch", "--input", file, "--output", stream, "--workers", "4", "--ephemeris", "moshier"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("wrote %d lines, want 12", len(lines))
+	}
+	for i, line := range lines {
+		if want := fmt.Sprintf(`"name":"Chart %d"`, i+1); !strings.Contains(line, want) {
+			t.Errorf("line %d does not have %s, in input order", i+1, want)
+		}
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := Run([]string{"batch", "--input", file, "--output-dir", out, "--format", "yaml", "--ephemeris", "moshier"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "07-chart-7.yaml")); err != nil {
+		t.Error(err)
+	}
+	if err := Run([]string{"batch", "--input", file, "--format", "text"}); err == nil {
+		t.Error("text to one stream: expected an error")
+	}
+}
+
+func TestPolarFallback(t *testing.T) {
+	args := []string{"2024-03-20T12:00:00Z", "70", "20"}
+	err := Run(append([]string{"--ephemeris", "moshier", "--json"}, args...))
+	var polar *swisseph.PolarError
+	if !errors.As(err, &polar) || polar.System != swisseph.HousePlacidus {
+		t.Fatalf("Placidus at 70°N: got %v, want a *swisseph.PolarError", err)
+	}
+	if code, exit := Classify(err); code != CodeEphemeris || exit != ExitEphemeris {
+		t.Errorf("Classify = %s %d, want %s %d", code, exit, CodeEphemeris, ExitEphemeris)
+	}
+
+	file := filepath.Join(t.TempDir(), "chart.json")
+	if err := Run(append([]string{"--ephemeris", "moshier", "--polar-fallback", "whole-sign", "--output", file}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"system": "Whole Sign",
+    "fallback_from": "Placidus"`) {
+		t.Errorf("houses do not name the fallback:\n%s", b)
+	}
+	if err := Run(append([]string{"--polar-fallback", "koch"}, args...)); err == nil {
+		t.Error("--polar-fallback koch: expected error")
+	}
+
+	// --house-system all leaves out the systems that cannot be cast.
+	if err := Run(append([]string{"--ephemeris", "moshier", "--house-system", "all", "--json", "--compact", "--output", file}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	var all struct {
+		HouseSystems map[string]json.RawMessage `json:"house_systems"`
+		Skipped      []string                   `json:"skipped"`
+	}
+	if b, err = os.ReadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		t.Fatal(err)
+	}
+	if all.HouseSystems["placidus"] != nil || all.HouseSystems["whole-sign"] == nil || !slices.Contains(all.Skipped, "placidus") || !slices.Contains(all.Skipped, "koch") {
+		t.Errorf("--house-system all at 70°N: systems %d, skipped %q; want Placidus and Koch skipped", len(all.HouseSystems), all.Skipped)
+	}
+	if len(all.HouseSystems)+len(all.Skipped) != len(comparedHouseSystems()) {
+		t.Errorf("--house-system all at 70°N: %d systems and %d skipped, want %d in all", len(all.HouseSystems), len(all.Skipped), len(comparedHouseSystems()))
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	// Swapped coordinates are caught before the local datetime, whose
+	// zone they would give, is read.
+	err := Run([]string{"2024-03-20T12:00:00", "100", "40"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean 40 100?") {
+		t.Errorf("swapped coordinates: got %v", err)
+	}
+	if code, exit := Classify(err); code != CodeInput || exit != ExitInput {
+		t.Errorf("Classify = %s %d, want %s %d", code, exit, CodeInput, ExitInput)
+	}
+
+	err = Run([]string{"--ephemeris", "moshier", "5000-01-01T12:00:00Z", "40", "20"})
+	if err == nil || !strings.Contains(err.Error(), "outside the range of the Moshier ephemeris") || !strings.Contains(err.Error(), "--ephemeris swiss") {
+		t.Errorf("year 5000 with Moshier: got %v", err)
+	}
+	if code, exit := Classify(err); code != CodeEphemeris || exit != ExitEphemeris {
+		t.Errorf("Classify = %s %d, want %s %d", code, exit, CodeEphemeris, ExitEphemeris)
+	}
+
+	// Every command that takes a date checks it before computing.
+	for _, args := range [][]string{
+		{"ephemeris", "--ephemeris", "moshier", "--from", "5000-01-01", "--to", "5000-01-03"},
+		{"transits", "--ephemeris", "moshier", "5000-01-01T12:00:00Z", "40", "20"},
+		{"return", "--ephemeris", "moshier", "solar", "5000-01-01T12:00:00Z", "40", "20"},
+		{"sky", "--ephemeris", "moshier", "--at", "5000-01-01T12:00:00Z"},
+		{"hours", "--ephemeris", "moshier", "5000-01-01", "40", "20"},
+		{"election", "--ephemeris", "moshier", "--where", "moon waxing", "5000-01-01", "5000-01-02", "40", "20"},
+		{"nodes", "--ephemeris", "moshier", "--from", "5000-01-01T00:00:00Z", "--to", "5000-02-01T00:00:00Z"},
+		{"moon", "--ephemeris", "moshier", "5000"},
+	} {
+		err := Run(args)
+		if code, _ := Classify(err); err == nil || code != CodeEphemeris || !strings.Contains(err.Error(), "outside the range of the Moshier ephemeris") {
+			t.Errorf("%s in the year 5000 with Moshier: got %v", args[0], err)
+		}
+	}
+}
+
+func TestReturnFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"return", "--planet", "saturn", "--year", "2030", "2000-01-01T12:00:00Z", "40", "20"},
+		{"return", "--after", "2030-01-01T00:00:00Z", "solar", "2000-01-01T12:00:00Z", "40", "20"},
+	} {
+		err := Run(args)
+		if err == nil || !strings.Contains(err.Error(), "applies to") {
+			t.Errorf("%s: got %v, want a usage error", strings.Join(args, " "), err)
+		}
+		if code, _ := Classify(err); code != CodeInput {
+			t.Errorf("%s: Classify = %s, want %s", strings.Join(args, " "), code, CodeInput)
+		}
+	}
+}
+
+func TestBatchFileName(t *testing.T) {
+	cases := []struct {
+		i, n       int
+		name, want string
+	}{
+		{0, 9, "Jane Doe", "1-jane-doe.json"},
+		{4, 120, "  Dr. Müller (1903)  ", "005-dr-müller-1903.json"},
+		{99, 120, "", "100.json"},
+		{0, 1, "../../etc", "1-etc.json"},
+	}
+	for _, c := range cases {
+		if got := batchFileName(c.i, c.n, c.name, ".json"); got != c.want {
+			t.Errorf("batchFileName(%d, %d, %q) = %q, want %q", c.i, c.n, c.name, got, c.want)
+		}
+	}
+}
+
+func TestZoneLocate(t *testing.T) {
+	tests := []struct {
+		name, lat, lon, datetime string
+		zone, source, near       string // "" zone: opts.Zone left unset
+	}{
+		{"", "52.52", "13.405", "1990-01-09T15:30:00", "Europe/Berlin", "coordinates", "Berlin, Germany"},
+		{"", "10", "-40", "1990-01-09T15:30:00", "Etc/GMT+3", "coordinates", ""},
+		{"Asia/Tokyo", "52.52", "13.405", "1990-01-09T15:30:00", "Asia/Tokyo", "tz", ""},
+		{"", "52.52", "13.405", "1990-01-09T15:30:00Z", "", "", ""},
+		{"", "52.52", "13.405", "1990-01-09T15:30:00[Asia/Tokyo]", "", "", ""},
+		{"", "north", "13.405", "1990-01-09T15:30:00", "", "", ""},
+		{"lmt", "52.52", "13.405", "1990-01-09T15:30:00", "LMT", "tz", ""},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		tz := addZone(fs)
+		*tz.name, tz.coordinates = tt.name, true
+		if err := tz.apply(); err != nil {
+			t.Fatal(err)
+		}
+		if err := tz.locate(tt.lat, tt.lon, tt.datetime); err != nil {
+			t.Fatal(err)
+		}
+		zone := ""
+		if tz.opts.Zone != nil {
+			zone = tz.opts.Zone.String()
+		}
+		if zone != tt.zone || tz.source != tt.source || tz.near != tt.near {
+			t.Errorf("locate(%s, %s, %s) with --tz %q: zone %q from %q near %q, want %q from %q near %q",
+				tt.lat, tt.lon, tt.datetime, tt.name, zone, tz.source, tz.near, tt.zone, tt.source, tt.near)
+		}
+	}
+
+	// Near a border the zone is not guessed: --tz must give it.
+	border := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	border.coordinates = true
+	if err := border.apply(); err != nil {
+		t.Fatal(err)
+	}
+	if err := border.locate("42.55", "-6.6", "1990-01-09T15:30:00"); err == nil || !strings.Contains(err.Error(), "--tz") {
+		t.Errorf("locate(42.55, -6.6) = %v, want an error asking for --tz", err)
+	}
+	*border.name = "Europe/Madrid"
+	if err := border.apply(); err != nil {
+		t.Fatal(err)
+	}
+	if err := border.locate("42.55", "-6.6", "1990-01-09T15:30:00"); err != nil || border.opts.Zone.String() != "Europe/Madrid" {
+		t.Errorf("locate(42.55, -6.6) with --tz Europe/Madrid = %v in %v", err, border.opts.Zone)
+	}
+
+	// The echo names the zone and its source; UTC is not echoed. Another
+	// command's zone does not carry over to this one.
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	if err := tz.apply(); err != nil {
+		t.Fatal(err)
+	}
+	if tz.opts.Zone != nil || tz.opts.MeanTime != nil {
+		t.Errorf("a new --tz has zone %v and mean time %v, want neither", tz.opts.Zone, tz.opts.MeanTime)
+	}
+	if l := tz.local("1990-01-09T15:30:00Z"); l != nil {
+		t.Errorf("local(UTC) = %+v, want nil", l)
+	}
+	if l := tz.local("1990-07-09T15:30:00[Europe/Paris]"); l == nil || l.Zone != "Europe/Paris" || l.Source != "datetime" || l.Time.Format("-07:00") != "+02:00" {
+		t.Errorf("local(bracketed) = %+v, want Europe/Paris at +02:00 from the datetime", l)
+	}
+	if err := tz.locate("48.1374", "11.5755", "1880-01-09T15:30:00"); err != nil {
+		t.Fatal(err)
+	}
+	if l := tz.local("1880-01-09T15:30:00"); l == nil || l.Zone != "Europe/Berlin" || !l.MeanTime || l.Offset != "+00:46:18" {
+		t.Errorf("local(1880 in Munich) = %+v, want local mean time +00:46:18 in Europe/Berlin", l)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	cases := []struct {
+		offset int
+		want   string
+	}{
+		{0, "+00:00"},
+		{3600, "+01:00"},
+		{-5 * 3600, "-05:00"},
+		{2778, "+00:46:18"},
+		{-31, "-00:00:31"},
+		{-75, "-00:01:15"},
+	}
+	for _, c := range cases {
+		tm := time.Date(1850, 1, 1, 12, 0, 0, 0, time.FixedZone("LMT", c.offset))
+		if got := formatOffset(tm); got != c.want {
+			t.Errorf("formatOffset(%d s) = %q, want %q", c.offset, got, c.want)
+		}
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	for _, c := range commands {
+		if err := c.run([]string{"--help"}); err != nil {
+			t.Errorf("astro %s --help: %v", c.name, err)
+		}
+	}
+	for _, tt := range []struct {
+		arg  string
+		word bool
+	}{
+		{"tranists", true}, {"foo", true}, {"now", false}, {"today", false},
+		{"--json", false}, {"2024-03-20", false}, {"now+3d", false},
+	} {
+		if got := isCommandWord(tt.arg); got != tt.word {
+			t.Errorf("isCommandWord(%q) = %v, want %v", tt.arg, got, tt.word)
+		}
+	}
+	if err := Run([]string{"tranists"}); err == nil || !strings.Contains(err.Error(), "did you mean transits?") {
+		t.Errorf("Run(tranists) = %v, want a suggestion of transits", err)
+	}
+	if err := Run([]string{"help", "nosuch"}); err == nil {
+		t.Error("Run(help nosuch): expected error")
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  chart now\t51.5  -0.13 ", []string{"chart", "now", "51.5", "-0.13"}},
+		{`chart --place "Berlin, Germany" today`, []string{"chart", "--place", "Berlin, Germany", "today"}},
+		{`aaf import 'my charts.aaf'`, []string{"aaf", "import", "my charts.aaf"}},
+		{`a\ b "x\"y" 'it''s' "" 51°30\'N`, []string{"a b", `x"y`, "its", "", "51°30'N"}},
+		{`"a\b"`, []string{`a\b`}},
+	}
+	for _, c := range cases {
+		got, err := splitLine(c.line)
+		if err != nil || !slices.Equal(got, c.want) {
+			t.Errorf("splitLine(%q) = %q, %v; want %q", c.line, got, err, c.want)
+		}
+	}
+	for _, bad := range []string{`chart "now`, "chart 'now", `chart now\`} {
+		if _, err := splitLine(bad); err == nil {
+			t.Errorf("splitLine(%q): expected error", bad)
+		}
+	}
+}
+
+func TestRepl(t *testing.T) {
+	in := "# a comment\n\ntranists\nastro nosuch\nchart \"now\nquit\ntranists\n"
+	var out, errw strings.Builder
+	if err := repl(strings.NewReader(in), &out, &errw, "> "); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(errw.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "did you mean transits?") || !strings.Contains(lines[1], `"nosuch"`) || !strings.Contains(lines[2], "unterminated") {
+		t.Errorf("errors = %q, want those of the three commands before quit", lines)
+	}
+	if got := strings.Count(out.String(), "> "); got != 6 {
+		t.Errorf("prompted %d times, want 6", got)
+	}
+}
+
+func TestServeRPC(t *testing.T) {
+	echo := func(method string, params json.RawMessage) (any, *rpcError) {
+		if method == "fail" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "no"}
+		}
+		return map[string]string{"method": method}, nil
+	}
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"a"}`,
+		`{"jsonrpc":"2.0","method":"quiet"}`,
+		`not json`,
+		`{"jsonrpc":"1.0","id":"x","method":"a"}`,
+		`[{"jsonrpc":"2.0","id":2,"method":"fail"},{"jsonrpc":"2.0","method":"quiet"}]`,
+	}, "\n")
+	var out strings.Builder
+	if err := serveRPC(strings.NewReader(in), &out, echo); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"method":"a"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error: invalid character 'o' in literal null (expecting 'u')"}}`,
+		`{"jsonrpc":"2.0","id":"x","error":{"code":-32600,"message":"invalid request: expected \"jsonrpc\": \"2.0\" and a method"}}`,
+		`[{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"no"}}]`,
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); !slices.Equal(got, want) {
+		t.Errorf("responses =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMCPToolArgs(t *testing.T) {
+	chart := mcpTools[slices.IndexFunc(mcpTools, func(t mcpTool) bool { return t.name == "chart" })]
+	args, err := chart.args(map[string]json.RawMessage{
+		"datetime": json.RawMessage(`"-1990"`),
+		"lat":      json.RawMessage(`"51N30"`),
+		"lon":      json.RawMessage(`-0.1278`),
+		"planets":  json.RawMessage(`"sun,moon"`),
+	})
+	want := []string{"chart", "--json", "--compact", "--planets=sun,moon", "--", "-1990", "51N30", "-0.1278"}
+	if err != nil || !slices.Equal(args, want) {
+		t.Errorf("args = %q, %v; want %q", args, err, want)
+	}
+	for _, bad := range []map[string]json.RawMessage{
+		{"lat": json.RawMessage(`1`)},                                            // no datetime
+		{"datetime": json.RawMessage(`"now"`), "output": json.RawMessage(`"x"`)}, // unknown
+		{"datetime": json.RawMessage(`"now"`), "lat": json.RawMessage(`true`)},   // wrong type
+	} {
+		if _, err := chart.args(bad); err == nil {
+			t.Errorf("args(%s) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestMCPHandle(t *testing.T) {
+	res, rerr := mcpHandle("initialize", json.RawMessage(`{"protocolVersion":"2024-11-05"}`))
+	if rerr != nil || res.(map[string]any)["protocolVersion"] != "2024-11-05" {
+		t.Errorf("initialize = %v, %v; want the client's version", res, rerr)
+	}
+	res, _ = mcpHandle("initialize", json.RawMessage(`{"protocolVersion":"1999-01-01"}`))
+	if v := res.(map[string]any)["protocolVersion"]; v != mcpVersions[len(mcpVersions)-1] {
+		t.Errorf("initialize with an unknown version = %v, want the latest", v)
+	}
+	res, _ = mcpHandle("tools/list", nil)
+	if tools := res.(map[string]any)["tools"].([]map[string]any); len(tools) != len(mcpTools) {
+		t.Errorf("tools/list = %v", tools)
+	}
+	// A command's own failure is a tool result marked as an error.
+	res, rerr = mcpHandle("tools/call", json.RawMessage(`{"name":"chart","arguments":{"datetime":"1990-13-09","lat":51.5,"lon":0}}`))
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	r := res.(map[string]any)
+	if text := r["content"].([]map[string]string)[0]["text"]; r["isError"] != true || !strings.Contains(text, `"code":"invalid_input"`) {
+		t.Errorf("tools/call with a bad datetime = %v", r)
+	}
+	if _, rerr := mcpHandle("tools/call", json.RawMessage(`{"name":"nosuch"}`)); rerr == nil || rerr.Code != rpcInvalidParams {
+		t.Errorf("tools/call of an unknown tool = %v, want invalid params", rerr)
+	}
+	if _, rerr := mcpHandle("resources/list", nil); rerr == nil || rerr.Code != rpcMethodNotFound {
+		t.Errorf("resources/list = %v, want method not found", rerr)
+	}
+}
+
+func TestStdioHandle(t *testing.T) {
+	chart := `"--ephemeris", "moshier", "1990-01-09T14:30:00Z", "51.5", "-0.13"]`
+	res, rerr := stdioHandle("chart", json.RawMessage(`["--json", `+chart))
+	if raw, ok := res.(json.RawMessage); rerr != nil || !ok || !json.Valid(raw) {
+		t.Errorf("chart --json = %v, %v; want its JSON", res, rerr)
+	}
+	res, rerr = stdioHandle("chart", json.RawMessage(`["--oneline", `+chart))
+	if s, ok := res.(string); rerr != nil || !ok || !strings.HasPrefix(s, "Sun 19Cp04") {
+		t.Errorf("chart --oneline = %v, %v; want its line of text", res, rerr)
+	}
+	_, rerr = stdioHandle("chart", json.RawMessage(`["1990-13-01", "51.5", "-0.13"]`))
+	if rerr == nil || rerr.Code != rpcCommandFailed || !strings.Contains(string(rerr.Data.(json.RawMessage)), `"field":"datetime"`) {
+		t.Errorf("chart with a bad datetime = %+v, want the command's error", rerr)
+	}
+	// The example astro --stdio -h shows runs as it stands.
+	var req rpcRequest
+	if err := json.Unmarshal([]byte(stdioExample), &req); err != nil {
+		t.Fatal(err)
+	}
+	if res, rerr := stdioHandle(req.Method, req.Params); rerr != nil {
+		t.Errorf("the usage example = %+v, want its chart", rerr)
+	} else if raw, ok := res.(json.RawMessage); !ok || !json.Valid(raw) {
+		t.Errorf("the usage example = %v, want its JSON", res)
+	}
+	for method, code := range map[string]int{"watch": rpcMethodNotFound, "nosuch": rpcMethodNotFound, "sky": rpcInvalidParams} {
+		params := json.RawMessage(`{"at": "now"}`)
+		if _, rerr := stdioHandle(method, params); rerr == nil || rerr.Code != code {
+			t.Errorf("%s = %+v, want code %d", method, rerr, code)
+		}
+	}
+}
+
+func TestApplyVedicPreset(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	sidereal := fs.String("sidereal", "", "")
+	houses := fs.String("house-system", "placidus", "")
+	nodes := fs.String("nodes", "none", "")
+	if err := fs.Parse([]string{"--house-system", "koch"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyVedicPreset(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *sidereal != "lahiri" || *nodes != "mean" {
+		t.Errorf("preset gave --sidereal %q --nodes %q, want lahiri, mean", *sidereal, *nodes)
+	}
+	if *houses != "koch" {
+		t.Errorf("--house-system = %q, want the explicit koch to win", *houses)
+	}
+}
+
+func TestWheelFormat(t *testing.T) {
+	for _, tc := range []struct{ format, file, want string }{
+		{"", "", "svg"},
+		{"", "chart.PNG", "png"},
+		{"", "chart.svg", "svg"},
+		{"PNG", "chart.svg", "png"},
+	} {
+		if got, err := wheelFormat(tc.format, tc.file); err != nil || got != tc.want {
+			t.Errorf("wheelFormat(%q, %q) = %q, %v; want %q", tc.format, tc.file, got, err, tc.want)
+		}
+	}
+	if _, err := wheelFormat("jpeg", ""); err == nil {
+		t.Error("wheelFormat(jpeg): expected error")
+	}
+}
+
+func TestParseRings(t *testing.T) {
+	tz := addZone(flag.NewFlagSet("test", flag.ContinueOnError))
+	rings, err := parseRings(tz, "2024-01-01T00:00:00Z", "", "2024-03-20T12:00:00Z,51.5,-0.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rings) != 2 || rings[0].kind != "progressed" || rings[1].kind != "transits" || rings[1].at.Month() != 3 {
+		t.Errorf("parseRings = %+v", rings)
+	}
+	if _, err := parseRings(tz, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z"); err == nil {
+		t.Error("three rings: expected error")
+	}
+	if rings, err := parseRings(tz, "", "", ""); err != nil || len(rings) != 0 {
+		t.Errorf("no rings: got %v, %v", rings, err)
+	}
+}
+
+func TestChartFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format, file   string
+		asJSON, asYAML bool
+		want           string
+	}{
+		{"", "", false, false, "text"},
+		{"", "", true, false, "json"},
+		{"", "", false, true, "yaml"},
+		{"md", "", false, false, "markdown"},
+		{"Markdown", "", false, false, "markdown"},
+		{"json", "", true, false, "json"},
+		{"NDJSON", "", false, false, "ndjson"},
+		{"", "chart.JSON", false, false, "json"},
+		{"", "chart.yml", false, false, "yaml"},
+		{"", "chart.md", false, false, "markdown"},
+		{"", "chart.csv", false, false, "csv"},
+		{"", "chart.svg", false, false, "svg"},
+		{"", "chart.jsonl", false, false, "ndjson"},
+		{"", "chart.out", false, false, "text"},
+		{"csv", "chart.json", false, false, "csv"},
+		{"", "chart.csv", true, false, "json"},
+		{"OneLine", "chart.json", false, false, "oneline"},
+	} {
+		if got, err := chartFormat(tc.format, tc.file, tc.asJSON, tc.asYAML); err != nil || got != tc.want {
+			t.Errorf("chartFormat(%q, %q, %v, %v) = %q, %v; want %q", tc.format, tc.file, tc.asJSON, tc.asYAML, got, err, tc.want)
+		}
+	}
+	for _, tc := range []struct {
+		format         string
+		asJSON, asYAML bool
+	}{
+		{"html", false, false},
+		{"", true, true},
+		{"markdown", true, false},
+	} {
+		if _, err := chartFormat(tc.format, "", tc.asJSON, tc.asYAML); err == nil {
+			t.Errorf("chartFormat(%q, %v, %v): expected error", tc.format, tc.asJSON, tc.asYAML)
+		}
+	}
+}
+
+func TestChartOutputTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "chart.tmpl")
+	if err := os.WriteFile(file, []byte("{{.JulianDay}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	out := addChartOutput(fs)
+	if err := fs.Parse([]string{"--template", file}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.resolve(); err != nil || out.tmpl == nil {
+		t.Errorf("resolve = %v, template %v", err, out.tmpl)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	out = addChartOutput(fs)
+	if err := fs.Parse([]string{"--template", file, "--json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.resolve(); err == nil {
+		t.Error("--template with --json: expected error")
+	}
+}
+
+func TestUnicodeLocale(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"LANG": "en_GB.UTF-8"}, true},
+		{map[string]string{"LC_CTYPE": "C.utf8", "LANG": "C"}, true},
+		{map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, false},
+		{map[string]string{"LANG": "en_US.UTF-8", "TERM": "linux"}, false},
+		{map[string]string{"LANG": "en_US.UTF-8", "TERM": "xterm-256color"}, true},
+		{map[string]string{}, false},
+	} {
+		if got := unicodeLocale(func(k string) string { return tc.env[k] }); got != tc.want {
+			t.Errorf("unicodeLocale(%v) = %v, want %v", tc.env, got, tc.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	_, inputErr := input.ParseLatitude("51,5")
+	_, ephemerisErr := swisseph.CalcPlanetFlags(2451545, swisseph.Sun, swisseph.FlagJPL)
+	execErr := template.Must(template.New("t").Parse("{{.Missing}}")).Execute(io.Discard, 0)
+	for _, tc := range []struct {
+		err  error
+		code string
+		exit int
+	}{
+		{inputErr, CodeInput, ExitInput},
+		{fmt.Errorf("expected 3 arguments, got 2"), CodeInput, ExitInput},
+		{fmt.Errorf("error calculating Sun: %w", ephemerisErr), CodeEphemeris, ExitEphemeris},
+		{internal(fmt.Errorf("write: broken pipe")), CodeInternal, ExitInternal},
+		{internal(fmt.Errorf("error executing template: %w", execErr)), CodeInput, ExitInput},
+	} {
+		if code, exit := Classify(tc.err); code != tc.code || exit != tc.exit {
+			t.Errorf("Classify(%v) = %s %d, want %s %d", tc.err, code, exit, tc.code, tc.exit)
+		}
+	}
+
+	var b strings.Builder
+	writeErrorJSON(&b, inputErr, CodeInput, ExitInput)
+	want := `{"error":{"code":"invalid_input","message":"invalid latitude \"51,5\": expected degrees, e.g. 51.5074, 51N30 or 51°30'N (did you mean 51.5?)","exit_code":2,"field":"latitude","value":"51,5","suggestion":"51.5"}}` + "\n"
+	if b.String() != want {
+		t.Errorf("writeErrorJSON = %s, want %s", b.String(), want)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	for _, tc := range []struct {
+		args string
+		want bool
+	}{
+		{"--json 2024-03-20T12:00:00Z 51.5 -0.1", true},
+		{"transits 2024-03-20T12:00:00Z --ndjson", true},
+		{"--format json 2024-03-20T12:00:00Z 51.5 -0.1", true},
+		{"--format=ndjson 2024-03-20T12:00:00Z 51.5 -0.1", true},
+		{"-json=1 2024-03-20T12:00:00Z 51.5 -0.1", true},
+		{"--json=false 2024-03-20T12:00:00Z 51.5 -0.1", false},
+		{"--format yaml 2024-03-20T12:00:00Z 51.5 -0.1", false},
+		{"-- --json", false},
+	} {
+		if got := jsonOutput(strings.Fields(tc.args)); got != tc.want {
+			t.Errorf("jsonOutput(%q) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	n := 0
+	draw := func(time.Time) error { n++; return nil }
+	if err := watch(context.Background(), time.Millisecond, 3, draw); err != nil || n != 3 {
+		t.Errorf("watch with count 3: %d draws, err %v", n, err)
+	}
+
+	// Once interrupted, it stops after the chart it has drawn.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n = 0
+	if err := watch(ctx, time.Hour, 0, draw); err != nil || n != 1 {
+		t.Errorf("interrupted watch: %d draws, err %v", n, err)
+	}
+
+	fail := fmt.Errorf("no ephemeris")
+	if err := watch(context.Background(), time.Millisecond, 0, func(time.Time) error { return fail }); err != fail {
+		t.Errorf("watch = %v, want the draw error", err)
+	}
 }
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/store"
+)
+
+// runSave implements "astro save": a chart saved under a name, for the
+// other commands to take in place of its birth data.
+func runSave(args []string) error {
+	fs := flag.NewFlagSet("astro save", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro save <name> <datetime> (<lat> <lon> | --place <place>) [--force] [--tz <zone>] [--default-time <HH:MM>]\n")
+		fmt.Fprintf(fs.Output(), "  Saves the chart under name in the chart store,\n")
+		fmt.Fprintf(fs.Output(), "  %s.\n", chartsPathUsage)
+		fmt.Fprintf(fs.Output(), "  The commands that take a chart's <datetime> <lat> <lon> then take its\n")
+		fmt.Fprintf(fs.Output(), "  name in their stead, e.g. astro transits Alice --now, and astro show\n")
+		fmt.Fprintf(fs.Output(), "  Alice shows its chart. The datetime is kept with its time zone.\n\n")
+		fs.PrintDefaults()
+	}
+	forceFlag := fs.Bool("force", false, "Replace a chart already saved under the name")
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 2); err != nil {
+		return err
+	}
+	if len(pos) != 4 {
+		fs.Usage()
+		return fmt.Errorf("expected <name> <datetime> <lat> <lon>, got %d arguments", len(pos))
+	}
+	name := strings.TrimSpace(pos[0])
+	if err := checkChartName(name); err != nil {
+		return err
+	}
+	lat, lon, err := input.ParseCoordinates(pos[2], pos[3])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[2], pos[3], pos[1]); err != nil {
+		return err
+	}
+	t, err := tz.opts.ParseLocalDateTime(pos[1])
+	if err != nil {
+		return err
+	}
+
+	path, err := chartsPath()
+	if err != nil {
+		return err
+	}
+	s, err := store.Load(path)
+	if err != nil {
+		return err
+	}
+	if old, ok := s.Lookup(name); ok && !*forceFlag {
+		return fmt.Errorf("a chart named %q is already saved (%s); give --force to replace it", old.Name, old.DateTime)
+	}
+	c := store.Chart{Name: name, DateTime: savedDateTime(t), Lat: lat, Lon: lon}
+	s.Put(c)
+	if err := s.Save(path); err != nil {
+		return fmt.Errorf("error saving chart: %w", err)
+	}
+	fmt.Printf("Saved %s: %s, %s in %s\n", c.Name, c.DateTime, formatCoords(c.Lat, c.Lon), path)
+	return nil
+}
+
+// runShow implements "astro show": the chart saved under a name, or the
+// list of saved charts.
+func runShow(args []string) error {
+	if len(args) == 0 {
+		return listCharts()
+	}
+	if isHelp(args[0]) {
+		fmt.Fprintf(os.Stderr, "Usage: astro show [<name> [flags]]\n")
+		fmt.Fprintf(os.Stderr, "  Shows the chart saved as name with astro save, as astro chart would;\n")
+		fmt.Fprintf(os.Stderr, "  the flags are those of astro chart (see astro chart --help). Without\n")
+		fmt.Fprintf(os.Stderr, "  a name, lists the saved charts. The chart store is\n")
+		fmt.Fprintf(os.Stderr, "  %s.\n", chartsPathUsage)
+		return nil
+	}
+	c, err := savedChart(args[0])
+	if err != nil {
+		return err
+	}
+	return runChart(append(args[1:len(args):len(args)], chartArgs(c)...))
+}
+
+// listCharts writes the saved charts to stdout.
+func listCharts() error {
+	path, err := chartsPath()
+	if err != nil {
+		return err
+	}
+	s, err := store.Load(path)
+	if err != nil {
+		return err
+	}
+	charts := s.Charts()
+	if len(charts) == 0 {
+		fmt.Printf("No charts saved in %s; save one with astro save <name> <datetime> <lat> <lon>\n", path)
+		return nil
+	}
+	width := 4
+	for _, c := range charts {
+		width = max(width, len(c.Name))
+	}
+	for _, c := range charts {
+		fmt.Printf("%s  %s  %s\n", pad(c.Name, width), c.DateTime, formatCoords(c.Lat, c.Lon))
+	}
+	return nil
+}
+
+// chartsPathUsage describes where the chart store is.
+const chartsPathUsage = "$ASTRO_CHARTS, or else astro/charts.json in the user's configuration directory"
+
+// chartsPath returns the path of the chart store: the file named by
+// $ASTRO_CHARTS, or else astro/charts.json under os.UserConfigDir.
+func chartsPath() (string, error) {
+	if path := os.Getenv("ASTRO_CHARTS"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no place for the chart store (set ASTRO_CHARTS): %w", err)
+	}
+	return filepath.Join(dir, "astro", "charts.json"), nil
+}
+
+// savedChart returns the chart saved under name.
+func savedChart(name string) (store.Chart, error) {
+	path, err := chartsPath()
+	if err != nil {
+		return store.Chart{}, err
+	}
+	s, err := store.Load(path)
+	if err != nil {
+		return store.Chart{}, err
+	}
+	if c, ok := s.Lookup(name); ok {
+		return c, nil
+	}
+	e := &input.Error{Kind: "chart", Value: name, Reason: "no chart is saved under this name; list them with astro show"}
+	best, dist := "", 3
+	for _, c := range s.Charts() {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(c.Name)); d < dist {
+			best, dist = c.Name, d
+		}
+	}
+	e.Suggestion = best
+	return store.Chart{}, e
+}
+
+// expandSaved replaces each argument of pos that names a saved chart with
+// the chart's datetime, latitude and longitude, so that the commands that
+// take <datetime> <lat> <lon> take a name in their stead. The store is read
+// only if some argument could be a name (see checkChartName).
+func expandSaved(pos []string) ([]string, error) {
+	var s *store.Store
+	var out []string
+	for _, arg := range pos {
+		if checkChartName(arg) != nil {
+			out = append(out, arg)
+			continue
+		}
+		if s == nil {
+			path, err := chartsPath()
+			if err != nil {
+				return nil, err
+			}
+			if s, err = store.Load(path); err != nil {
+				return nil, err
+			}
+		}
+		if c, ok := s.Lookup(arg); ok {
+			out = append(out, chartArgs(c)...)
+		} else {
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// chartArgs returns the arguments <datetime> <lat> <lon> of c.
+func chartArgs(c store.Chart) []string {
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	return []string{c.DateTime, num(c.Lat), num(c.Lon)}
+}
+
+// checkChartName reports why name cannot name a saved chart: it must not
+// be empty, read as a datetime, a number or a coordinate, or hold a comma,
+// so that it never stands for anything else on the command line.
+func checkChartName(name string) error {
+	reason := ""
+	switch {
+	case name == "":
+		reason = "a chart name cannot be empty"
+	case strings.HasPrefix(name, "-"):
+		reason = "a chart name cannot start with -"
+	case strings.Contains(name, ","):
+		reason = "a chart name cannot hold a comma"
+	default:
+		_, latErr := input.ParseLatitude(name)
+		_, lonErr := input.ParseLongitude(name)
+		if _, err := strconv.ParseFloat(name, 64); err == nil || latErr == nil || lonErr == nil {
+			reason = "a chart name cannot be a number or coordinate"
+		} else if _, err := input.ParseDateTime(name); err == nil {
+			reason = "a chart name cannot be a datetime"
+		}
+	}
+	if reason != "" {
+		return &input.Error{Kind: "chart name", Value: name, Reason: reason}
+	}
+	return nil
+}
+
+// savedDateTime formats t, as given, for the store: in RFC 3339 with its
+// IANA zone in brackets, if it has one, so that the chart keeps its local
+// time; otherwise with its offset, or in UTC if the offset is not a whole
+// number of minutes, as with local mean time.
+func savedDateTime(t time.Time) string {
+	_, off := t.Zone()
+	if off%60 != 0 {
+		return t.UTC().Format(time.RFC3339)
+	}
+	s := t.Format(time.RFC3339)
+	if name := t.Location().String(); name != "UTC" && name != "Local" && name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			s += "[" + name + "]"
+		}
+	}
+	return s
+}
+
+// formatCoords returns a latitude and longitude for display.
+func formatCoords(lat, lon float64) string {
+	return fmt.Sprintf("%.4f, %.4f", lat, lon)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// cardinalSigns maps the --ingress names to the signs, 0 for Aries.
+var cardinalSigns = map[string]int{"aries": 0, "cancer": 3, "libra": 6, "capricorn": 9}
+
+// runSeasons implements "astro seasons": the charts of the Sun's ingresses
+// into the cardinal signs in a year, cast for a place.
+func runSeasons(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro seasons", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro seasons [<year>] (<lat> <lon> | --place <place>) [--ingress <list>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Casts the cardinal ingress charts of the year, this one by default,\n")
+		fmt.Fprintf(fs.Output(), "  for the place: the charts of the moments the Sun enters Aries, Cancer,\n")
+		fmt.Fprintf(fs.Output(), "  Libra and Capricorn, at the equinoxes and solstices, which mundane\n")
+		fmt.Fprintf(fs.Output(), "  astrology reads for the season, or the Aries ingress for the year,\n")
+		fmt.Fprintf(fs.Output(), "  ahead. The charts follow one another; JSON output is an array of them.\n\n")
+		fs.PrintDefaults()
+	}
+
+	ingressFlag := fs.String("ingress", "aries,cancer,libra,capricorn", "Comma-separated ingresses to cast: aries, cancer, libra, capricorn")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	polar := addPolarFallback(fs)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	out := addChartOutput(fs)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if err := out.resolve(); err != nil {
+		return err
+	}
+	n := 0
+	if len(pos) == 1 || len(pos) == 3 {
+		n = 1
+	}
+	if pos, err = place.apply(pos, n); err != nil {
+		return err
+	}
+	if len(pos) != 2 && len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected [<year>] <lat> <lon>, got %d arguments: %s", len(pos), strings.Join(pos, " "))
+	}
+	year := 0
+	if len(pos) == 3 {
+		if year, err = strconv.Atoi(pos[0]); err != nil || year < -5000 || year > 5000 {
+			return &input.Error{Kind: "year", Value: pos[0], Reason: "expected a year, e.g. 2025"}
+		}
+		pos = pos[1:]
+	}
+	// The charts show the place's clock, from the atlas unless --tz or
+	// --place gave it.
+	lat, lon, err := input.ParseCoordinates(pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[0], pos[1], "today"); err != nil {
+		return err
+	}
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	if year == 0 {
+		year = time.Now().In(loc).Year()
+	}
+	signs, err := parseCardinalSigns(*ingressFlag)
+	if err != nil {
+		return err
+	}
+	if len(signs) > 1 {
+		switch out.resolved {
+		case "yaml", "csv", "svg", "png":
+			return fmt.Errorf("--format %s writes one chart: choose one with --ingress, e.g. --ingress aries", out.resolved)
+		}
+	}
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	planets, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	from, to := ephemeris.JulianDay(first), ephemeris.JulianDay(first.AddDate(1, 0, 0))
+	if err := checkDate(backend, from, to); err != nil {
+		return err
+	}
+	events, err := mundane.Ingresses(p, swisseph.Sun, from, to)
+	if err != nil {
+		return err
+	}
+	var charts []output.Result
+	for _, e := range events {
+		in := output.CardinalIngress(e)
+		if in == nil || !slices.Contains(signs, e.Sign) {
+			continue
+		}
+		r, err := polar.build(p, e.JD, planets, lat, lon, hsys, hsysName)
+		if err != nil {
+			return err
+		}
+		r.Ingress = in
+		if tz.opts.Zone != nil {
+			t := in.Time.In(loc)
+			r.Local = &output.LocalInfo{Time: t, Offset: formatOffset(t), Zone: loc.String(), Source: tz.source, Near: tz.near}
+			r.Local.MeanTime = loc == tz.opts.MeanTime
+		}
+		r.Metadata = chartMetadata("seasons", args, backend)
+		charts = append(charts, r)
+	}
+	rec.Mark("compute")
+
+	err = writeOutput(*out.file, func(w io.Writer) error {
+		if out.resolved == "json" && out.tmpl == nil {
+			return output.WriteJSONCharts(w, charts, output.JSONOptions{Compact: *out.compact})
+		}
+		for i, r := range charts {
+			if i > 0 && out.resolved != "oneline" && out.resolved != "ndjson" {
+				fmt.Fprintln(w)
+			}
+			if err := out.print(w, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "seasons", backend)
+}
+
+// parseCardinalSigns parses a comma-separated list of the names of
+// cardinalSigns, returning the signs in the order of the zodiac.
+func parseCardinalSigns(s string) ([]int, error) {
+	var signs []int
+	for _, item := range strings.Split(s, ",") {
+		sign, ok := cardinalSigns[strings.ToLower(strings.TrimSpace(item))]
+		if !ok {
+			return nil, &input.Error{Kind: "ingress", Value: item, Reason: "valid values are aries, cancer, libra and capricorn"}
+		}
+		if !slices.Contains(signs, sign) {
+			signs = append(signs, sign)
+		}
+	}
+	slices.Sort(signs)
+	return signs, nil
+}
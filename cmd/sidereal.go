@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+const siderealUsage = "Use the sidereal zodiac with this ayanamsa: lahiri, fagan-bradley, raman, krishnamurti, or custom:t0=<jd>,value=<degrees> for one of your own"
+
+// ayanamsa is a sidereal mode as --sidereal selects it.
+type ayanamsa struct {
+	mode int
+	// t0 and value define a custom ayanamsa (swisseph.SidmUser): value
+	// degrees at the Julian Day t0 (TT).
+	t0, value float64
+}
+
+// set makes a the library's sidereal mode.
+func (a ayanamsa) set() {
+	if a.mode == swisseph.SidmUser {
+		swisseph.SetSidModeUser(a.t0, a.value)
+		return
+	}
+	swisseph.SetSidMode(a.mode)
+}
+
+// parseAyanamsa maps the --sidereal flag to a swisseph sidereal mode and
+// its display name.
+func parseAyanamsa(name string) (a ayanamsa, displayName string, err error) {
+	if def, ok := strings.CutPrefix(strings.ToLower(name), "custom:"); ok {
+		return parseCustomAyanamsa(def)
+	}
+	switch strings.ToLower(name) {
+	case "lahiri":
+		return ayanamsa{mode: swisseph.SidmLahiri}, "Lahiri", nil
+	case "fagan-bradley":
+		return ayanamsa{mode: swisseph.SidmFaganBradley}, "Fagan-Bradley", nil
+	case "raman":
+		return ayanamsa{mode: swisseph.SidmRaman}, "Raman", nil
+	case "krishnamurti":
+		return ayanamsa{mode: swisseph.SidmKrishnamurti}, "Krishnamurti", nil
+	default:
+		return ayanamsa{}, "", fmt.Errorf("unknown ayanamsa %q: valid values are lahiri, fagan-bradley, raman, krishnamurti, custom:t0=<jd>,value=<degrees>", name)
+	}
+}
+
+// parseCustomAyanamsa parses the definition after "custom:", e.g.
+// t0=2451545.0,value=24.83: the ayanamsa in degrees at a Julian Day (TT).
+// Its display name carries the definition, so the chart records it.
+func parseCustomAyanamsa(def string) (ayanamsa, string, error) {
+	syntax := fmt.Errorf("invalid custom ayanamsa %q: expected custom:t0=<julian day>,value=<degrees>, e.g. custom:t0=2451545.0,value=24.83", def)
+	a := ayanamsa{mode: swisseph.SidmUser}
+	seen := map[string]bool{}
+	for _, field := range strings.Split(def, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok || seen[key] {
+			return ayanamsa{}, "", syntax
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+			return ayanamsa{}, "", syntax
+		}
+		switch key {
+		case "t0":
+			a.t0 = x
+		case "value":
+			a.value = x
+		default:
+			return ayanamsa{}, "", syntax
+		}
+		seen[key] = true
+	}
+	if !seen["t0"] || !seen["value"] {
+		return ayanamsa{}, "", syntax
+	}
+	f := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	return a, fmt.Sprintf("Custom (%s° at JD %s)", f(a.value), f(a.t0)), nil
+}
+
+// vedicPreset holds the flag values --vedic stands for. Flags given
+// explicitly on the command line win over the preset.
+var vedicPreset = map[string]string{
+	"sidereal":     "lahiri",
+	"house-system": "whole-sign",
+	"nodes":        "mean",
+}
+
+// applyVedicPreset sets every flag of vedicPreset that was not given on the
+// command line.
+func applyVedicPreset(fs *flag.FlagSet) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, value := range vedicPreset {
+		if given[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/hours"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/output"
+)
+
+// runSky implements "astro sky": the planets now, the Moon's phase and
+// course, the planetary hour, and the day's exact aspects.
+func runSky(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro sky", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro sky [<lat> <lon> | --place <place>] [--at <datetime>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Shows the sky now: where the planets are and which are retrograde, the\n")
+		fmt.Fprintf(fs.Output(), "  Moon's phase, its next aspect or whether it is void of course, and the\n")
+		fmt.Fprintf(fs.Output(), "  exact aspects of the day. Given a place, also the planetary hour, with\n")
+		fmt.Fprintf(fs.Output(), "  the times in the place's time zone.\n\n")
+		fs.PrintDefaults()
+	}
+
+	atFlag := fs.String("at", "", "Show the sky at this datetime instead of now")
+	planetsFlag := fs.String("planets", defaultCalendarBodies, "Comma-separated planets to show, and whose aspects to list")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 0); err != nil {
+		return err
+	}
+	if len(pos) != 0 && len(pos) != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, or <lat> <lon>, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	var lat, lon *float64
+	if len(pos) == 2 {
+		// The day and the times are those of the place.
+		la, lo, err := input.ParseCoordinates(pos[0], pos[1])
+		if err != nil {
+			return err
+		}
+		if err := tz.locate(pos[0], pos[1], "today", *atFlag); err != nil {
+			return err
+		}
+		lat, lon = &la, &lo
+	} else if tz.meanTime() {
+		return &input.Error{Kind: "time zone", Value: *tz.name, Reason: "local mean time needs a longitude: give <lat> <lon> or --place"}
+	}
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	at := time.Now()
+	if *atFlag != "" {
+		if at, err = tz.opts.ParseDateTime(*atFlag); err != nil {
+			return err
+		}
+	}
+	at = at.In(loc)
+	planets, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	jd := ephemeris.JulianDay(at)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	var day *hours.Day
+	if lat != nil {
+		d, err := planetaryDayAt(jd, *lat, *lon, backendFlag(backend))
+		if err != nil {
+			return err
+		}
+		day = &d
+	}
+	midnight := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, loc)
+	today, err := mundane.Aspects(p, planets, aspects.Major,
+		ephemeris.JulianDay(midnight), ephemeris.JulianDay(midnight.AddDate(0, 0, 1)))
+	if err != nil {
+		return err
+	}
+	rep, err := output.BuildSkyReport(p, at, planets, day, today)
+	if err != nil {
+		return err
+	}
+	rep.Lat, rep.Lon = lat, lon
+	rec.Mark("compute")
+
+	if *jsonFlag {
+		err = output.PrintSkyJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.WriteSkyText(os.Stdout, rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "sky", backend)
+}
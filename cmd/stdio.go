@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// rpcCommandFailed is the JSON-RPC error code of a command that failed;
+// the error's data is the command's JSON error object.
+const rpcCommandFailed = -32000
+
+// stdioExcluded are the commands astro --stdio does not run: sessions of
+// their own, and watch, which runs until interrupted.
+var stdioExcluded = []string{"repl", "mcp", "watch"}
+
+// stdioExample is the request astro --stdio -h shows; flags come before
+// the positional arguments, as on the command line.
+const stdioExample = `{"jsonrpc": "2.0", "id": 1, "method": "chart", "params": ["--json", "now", "51.5", "-0.13"]}`
+
+// runStdio implements "astro --stdio": astro commands as JSON-RPC 2.0
+// methods, read from stdin and answered on stdout, with the ephemeris kept
+// open.
+func runStdio(args []string) error {
+	fs := flag.NewFlagSet("astro --stdio", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro --stdio\n")
+		fmt.Fprintf(fs.Output(), "  Reads JSON-RPC 2.0 requests from stdin, one a line, and writes each\n")
+		fmt.Fprintf(fs.Output(), "  response to stdout as a line. The method is a command's name and the\n")
+		fmt.Fprintf(fs.Output(), "  params the array of its arguments, flags first, e.g.\n")
+		fmt.Fprintf(fs.Output(), "    %s\n", stdioExample)
+		fmt.Fprintf(fs.Output(), "  The result is the command's output: its JSON, or else its text as a\n")
+		fmt.Fprintf(fs.Output(), "  string. The ephemeris is opened once and kept open.\n\n")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(pos) != 0 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	closeSession, err := openSession()
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+	return serveRPC(os.Stdin, os.Stdout, stdioHandle)
+}
+
+// stdioHandle runs the command named by method with the arguments params,
+// a JSON array of strings.
+func stdioHandle(method string, params json.RawMessage) (any, *rpcError) {
+	if _, ok := lookupCommand(method); !ok || slices.Contains(stdioExcluded, method) {
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s; the methods are the commands of astro help but %s", method, strings.Join(stdioExcluded, ", "))}
+	}
+	var args []string
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid params: expected an array of the command's arguments, as strings"}
+		}
+	}
+	out, err := runCaptured(append([]string{method}, args...))
+	if err != nil {
+		code, exit := Classify(err)
+		var b bytes.Buffer
+		writeErrorJSON(&b, err, code, exit)
+		var data struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if jerr := json.Unmarshal(b.Bytes(), &data); jerr != nil || data.Error == nil {
+			return nil, &rpcError{Code: rpcCommandFailed, Message: err.Error(), Data: strings.TrimSpace(b.String())}
+		}
+		return nil, &rpcError{Code: rpcCommandFailed, Message: err.Error(), Data: data.Error}
+	}
+	if out = bytes.TrimSpace(out); json.Valid(out) && len(out) > 0 {
+		return json.RawMessage(out), nil
+	}
+	return string(out), nil
+}
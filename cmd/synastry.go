@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/synastry"
+)
+
+// runSynastry implements "astro synastry": inter-aspects, house overlays
+// and an aspect grid between two charts.
+func runSynastry(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro synastry", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro synastry <chartA> <chartB> [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Each chart is <datetime>,<lat>,<lon>, or three separate arguments.\n")
+		fmt.Fprintf(fs.Output(), "  Lists the aspects between A's and B's planets and angles, where each\n")
+		fmt.Fprintf(fs.Output(), "  person's planets fall in the other's houses, and an aspect grid.\n\n")
+		fs.PrintDefaults()
+	}
+
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	aspectsFlag := fs.String("aspects", "all", "Aspects to find: all, or any of conjunction, sextile, square, trine, opposition")
+	orbFlag := fs.Float64("orb", 0, "Orb in degrees for every aspect; 0 uses each aspect's customary orb")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	tz.coordinates = true
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	specs, err := parseChartSpecs(tz, pos, 2)
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	hsys, _, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	as, err := aspects.Parse(*aspectsFlag)
+	if err != nil {
+		return err
+	}
+	if *orbFlag < 0 {
+		return fmt.Errorf("--orb must not be negative, got %v", *orbFlag)
+	}
+	if *orbFlag > 0 {
+		as = aspects.WithOrb(as, *orbFlag)
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	if err := checkDate(backend, ephemeris.JulianDay(specs[0].Time), ephemeris.JulianDay(specs[1].Time)); err != nil {
+		return err
+	}
+	p := rec.Wrap(newProvider(backend, 0))
+	a, err := synastryChart(p, specs[0], hsys)
+	if err != nil {
+		return err
+	}
+	b, err := synastryChart(p, specs[1], hsys)
+	if err != nil {
+		return err
+	}
+	res := synastry.Compare(a, b, as)
+	rep := output.BuildSynastry(chartRef(specs[0]), chartRef(specs[1]), a, b, res)
+	rec.Mark("aspects")
+
+	if *jsonFlag {
+		err = output.PrintSynastryJSON(rep, output.JSONOptions{Compact: *compactFlag})
+	} else {
+		err = output.PrintSynastryText(rep)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "synastry", backend)
+}
+
+// synastryChart computes the natal planets, angles and house cusps of spec.
+func synastryChart(p ephemeris.Provider, spec chartSpec, hsys byte) (synastry.Chart, error) {
+	jd := ephemeris.JulianDay(spec.Time)
+	var c synastry.Chart
+	for _, body := range natalBodies {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return synastry.Chart{}, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		c.Bodies = append(c.Bodies, synastry.Body{Name: names.Body(body), Longitude: pos.Longitude})
+	}
+	h, err := p.CalcHouses(jd, spec.Lat, spec.Lon, hsys)
+	if err != nil {
+		return synastry.Chart{}, fmt.Errorf("error calculating houses: %w", err)
+	}
+	c.Bodies = append(c.Bodies,
+		synastry.Body{Name: names.Point(names.Ascendant), Longitude: h.Ascendant},
+		synastry.Body{Name: names.Point(names.MC), Longitude: h.MC})
+	c.Cusps = h.Cusps
+	return c, nil
+}
+
+func chartRef(spec chartSpec) output.ChartRef {
+	return output.ChartRef{Time: spec.Time, Lat: spec.Lat, Lon: spec.Lon}
+}
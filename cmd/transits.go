@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/transits"
+)
+
+const (
+	defaultTransitBodies = "sun,mercury,venus,mars,jupiter,saturn,uranus,neptune,pluto"
+	defaultTransitOrb    = 1.0
+)
+
+// natalBodies are the natal planets transits are measured against.
+var natalBodies = []int{
+	swisseph.Sun, swisseph.Moon, swisseph.Mercury, swisseph.Venus, swisseph.Mars,
+	swisseph.Jupiter, swisseph.Saturn, swisseph.Uranus, swisseph.Neptune, swisseph.Pluto,
+}
+
+// runTransits implements "astro transits": a chronological list of exact
+// transiting-to-natal aspects over a date range.
+func runTransits(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro transits", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro transits <natal-datetime> [<lat> <lon> | --place <place>] [--from <datetime>] [--to <datetime>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "       astro transits <natal-datetime> [<lat> <lon>] --now | --at <datetime> [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Lists, in order, when each transiting planet comes within orb of an\n")
+		fmt.Fprintf(fs.Output(), "  aspect to a natal planet, perfects it, and leaves orb. With --now or\n")
+		fmt.Fprintf(fs.Output(), "  --at, lists instead the transits in orb at that moment, whether each is\n")
+		fmt.Fprintf(fs.Output(), "  applying or separating, and how long until it is exact. Given the birth\n")
+		fmt.Fprintf(fs.Output(), "  place, the natal Ascendant and MC are included.\n\n")
+		fs.PrintDefaults()
+	}
+
+	fromFlag := fs.String("from", "", "Start of the range (RFC 3339); default now")
+	toFlag := fs.String("to", "", "End of the range (RFC 3339); default one year after --from")
+	nowFlag := fs.Bool("now", false, "List the transits in orb now instead of searching a range")
+	atFlag := fs.String("at", "", "List the transits in orb at this datetime (RFC 3339) instead of searching a range")
+	bodiesFlag := fs.String("bodies", defaultTransitBodies, "Comma-separated transiting planets")
+	aspectsFlag := fs.String("aspects", "all", "Aspects to find: all, or any of conjunction, sextile, square, trine, opposition")
+	orbFlag := fs.Float64("orb", defaultTransitOrb, "Orb in degrees for ingress and egress")
+	jsonFlag := fs.Bool("json", false, "Output results as JSON")
+	ndjsonFlag := fs.Bool("ndjson", false, "Output one JSON object per transit, line by line (NDJSON)")
+	compactFlag := fs.Bool("compact", false, compactUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = expandSaved(pos); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 1 && len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected <natal-datetime> [<lat> <lon>], got %d arguments", len(pos))
+	}
+	if len(pos) == 1 && tz.meanTime() {
+		return &input.Error{Kind: "time zone", Value: *tz.name, Reason: "local mean time needs a longitude: give <lat> <lon> or --place"}
+	}
+	if len(pos) == 3 {
+		if err := tz.locate(pos[1], pos[2], pos[0], *atFlag, *fromFlag, *toFlag); err != nil {
+			return err
+		}
+	}
+
+	natal, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	var loc *[2]float64
+	if len(pos) == 3 {
+		lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+		if err != nil {
+			return err
+		}
+		loc = &[2]float64{lat, lon}
+	}
+	snapshot := *nowFlag || *atFlag != ""
+	if snapshot && (*fromFlag != "" || *toFlag != "") {
+		return fmt.Errorf("--now and --at list a single moment; they cannot be combined with --from or --to")
+	}
+	at := time.Now().UTC()
+	if *atFlag != "" {
+		if at, err = tz.opts.ParseDateTime(*atFlag); err != nil {
+			return err
+		}
+	}
+	from := time.Now().UTC()
+	if *fromFlag != "" {
+		if from, err = tz.opts.ParseDateTime(*fromFlag); err != nil {
+			return err
+		}
+	}
+	to := from.AddDate(1, 0, 0)
+	if *toFlag != "" {
+		if to, err = tz.opts.ParseDateTime(*toFlag); err != nil {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to %s is before --from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	bodies, err := parseBodies(*bodiesFlag)
+	if err != nil {
+		return err
+	}
+	as, err := aspects.Parse(*aspectsFlag)
+	if err != nil {
+		return err
+	}
+	if *orbFlag <= 0 {
+		return fmt.Errorf("--orb must be positive, got %v", *orbFlag)
+	}
+	if *jsonFlag && *ndjsonFlag {
+		return fmt.Errorf("--json and --ndjson cannot be combined")
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	natalJD := ephemeris.JulianDay(natal)
+	if err := checkDate(backend, natalJD); err != nil {
+		return err
+	}
+	points, err := natalPoints(p, natalJD, loc)
+	if err != nil {
+		return err
+	}
+	as = aspects.WithOrb(as, *orbFlag)
+
+	if snapshot {
+		jd := ephemeris.JulianDay(at)
+		if err := checkDate(backend, jd); err != nil {
+			return err
+		}
+		active, err := transits.Snapshot(p, bodies, points, as, jd)
+		if err != nil {
+			return err
+		}
+		snap := output.BuildTransitSnapshot(natalJD, jd, active)
+		rec.Mark("aspects")
+		switch {
+		case *jsonFlag:
+			err = output.PrintTransitSnapshotJSON(snap, output.JSONOptions{Compact: *compactFlag})
+		case *ndjsonFlag:
+			err = output.PrintNDJSON(snap.Entries)
+		default:
+			err = output.PrintTransitSnapshotText(snap)
+		}
+		if err != nil {
+			return internal(err)
+		}
+		rec.Mark("render")
+		return writeTimings(rec, "transits", backend)
+	}
+
+	fromJD, toJD := ephemeris.JulianDay(from), ephemeris.JulianDay(to)
+	if err := checkDate(backend, fromJD, toJD); err != nil {
+		return err
+	}
+	events, err := transits.Scan(p, bodies, points, as, fromJD, toJD)
+	if err != nil {
+		return err
+	}
+	tl := output.BuildTransits(natalJD, fromJD, toJD, events)
+	rec.Mark("aspects")
+
+	switch {
+	case *jsonFlag:
+		err = output.PrintTransitsJSON(tl, output.JSONOptions{Compact: *compactFlag})
+	case *ndjsonFlag:
+		err = output.PrintNDJSON(tl.Entries)
+	default:
+		err = output.PrintTransitsText(tl)
+	}
+	if err != nil {
+		return internal(err)
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "transits", backend)
+}
+
+// natalPoints returns the natal planets at jd and, when loc (latitude,
+// longitude) is given, the Ascendant and MC.
+func natalPoints(p ephemeris.Provider, jd float64, loc *[2]float64) ([]transits.Point, error) {
+	var points []transits.Point
+	for _, body := range natalBodies {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating natal %s: %w", p.PlanetName(body), err)
+		}
+		points = append(points, transits.Point{Name: names.Body(body), Longitude: pos.Longitude})
+	}
+	if loc != nil {
+		h, err := p.CalcHouses(jd, loc[0], loc[1], swisseph.HousePlacidus)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating natal houses: %w", err)
+		}
+		points = append(points,
+			transits.Point{Name: names.Point(names.Ascendant), Longitude: h.Ascendant},
+			transits.Point{Name: names.Point(names.MC), Longitude: h.MC})
+	}
+	return points, nil
+}
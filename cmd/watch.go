@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+)
+
+// clearScreen moves the cursor home and clears the terminal, before each
+// redraw of astro watch.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// runWatch implements "astro watch": the chart of the moment, redrawn on a
+// timer with the ephemeris kept open.
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("astro watch", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro watch [<lat> <lon> | --place <place>] [--interval <duration>] [--count <n>] [--oneline] [--round-houses <duration>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Shows the chart of the current moment and redraws it every --interval\n")
+		fmt.Fprintf(fs.Output(), "  until interrupted. Given a place, the chart has houses, with the\n")
+		fmt.Fprintf(fs.Output(), "  Ascendant and MC, which move a degree in about four minutes. On a\n")
+		fmt.Fprintf(fs.Output(), "  terminal each chart replaces the last; with --oneline, or when the\n")
+		fmt.Fprintf(fs.Output(), "  output is not a terminal, they follow one another.\n")
+		fmt.Fprintf(fs.Output(), "  --round-houses lets redraws within that time of one another share\n")
+		fmt.Fprintf(fs.Output(), "  their houses, cast at the rounded moment.\n\n")
+		fs.PrintDefaults()
+	}
+
+	intervalFlag := fs.Duration("interval", time.Minute, "Time between redraws, e.g. 10s or 5m; at least 1s")
+	countFlag := fs.Int("count", 0, "Stop after this many charts (default: until interrupted)")
+	onelineFlag := fs.Bool("oneline", false, "Print each chart on one line, e.g. for a status bar")
+	roundHousesFlag := fs.Duration("round-houses", 0, "Cast the houses at the time rounded to this, e.g. 1m, and reuse them until it changes (default: exact)")
+	glyphsFlag := fs.Bool("glyphs", false, "Show planet and sign glyphs, if the terminal's locale is UTF-8")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	polar := addPolarFallback(fs)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 0); err != nil {
+		return err
+	}
+	if len(pos) != 0 && len(pos) != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected no arguments, or <lat> <lon>, got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	if *intervalFlag < time.Second {
+		return &input.Error{Kind: "interval", Value: intervalFlag.String(), Reason: "must be at least 1s"}
+	}
+	if *countFlag < 0 {
+		return &input.Error{Kind: "count", Value: fmt.Sprint(*countFlag), Reason: "must not be negative"}
+	}
+	if *roundHousesFlag < 0 {
+		return &input.Error{Kind: "round-houses", Value: roundHousesFlag.String(), Reason: "must not be negative"}
+	}
+	var lat, lon *float64
+	if len(pos) == 2 {
+		// The clock shown is the place's.
+		la, lo, err := input.ParseCoordinates(pos[0], pos[1])
+		if err != nil {
+			return err
+		}
+		if err := tz.locate(pos[0], pos[1], "today"); err != nil {
+			return err
+		}
+		lat, lon = &la, &lo
+	} else if tz.meanTime() {
+		return &input.Error{Kind: "time zone", Value: *tz.name, Reason: "local mean time needs a longitude: give <lat> <lon> or --place"}
+	}
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	planets, err := parseBodies(*planetsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+
+	// The ephemeris stays open from one chart to the next.
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+	p := newProvider(backend, 0)
+	if *roundHousesFlag > 0 {
+		// The last house result is the only one a later moment can ask for
+		// again.
+		p = ephemeris.NewLRUProvider(p, 1).RoundHouses(*roundHousesFlag, 0)
+	}
+
+	fi, err := os.Stdout.Stat()
+	redraw := err == nil && fi.Mode()&os.ModeCharDevice != 0 && !*onelineFlag
+	opt := output.TextOptions{Glyphs: *glyphsFlag && unicodeLocale(os.Getenv)}
+
+	loc := time.UTC
+	if tz.opts.Zone != nil {
+		loc = tz.opts.Zone
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return watch(ctx, *intervalFlag, *countFlag, func(now time.Time) error {
+		jd := ephemeris.JulianDay(now)
+		var r output.Result
+		var err error
+		if lat != nil {
+			r, err = polar.build(p, jd, planets, *lat, *lon, hsys, hsysName)
+		} else {
+			r, err = output.BuildSky(p, jd, planets)
+		}
+		if err != nil {
+			return err
+		}
+		if *onelineFlag {
+			return output.WriteOneLine(os.Stdout, r, opt)
+		}
+		if redraw {
+			fmt.Fprint(os.Stdout, clearScreen)
+		}
+		fmt.Fprintf(os.Stdout, "astro watch: %s, every %s (Ctrl-C to stop)\n\n", now.In(loc).Format("2006-01-02 15:04:05 MST"), *intervalFlag)
+		if err := output.WriteText(os.Stdout, r, opt); err != nil {
+			return internal(err)
+		}
+		if !redraw {
+			fmt.Fprintln(os.Stdout)
+		}
+		return nil
+	})
+}
+
+// watch calls draw with the time now, then again every interval until ctx
+// is done or, if count is positive, draw has been called count times. An
+// error from draw ends it.
+func watch(ctx context.Context, interval time.Duration, count int, draw func(now time.Time) error) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for n := 1; ; n++ {
+		if err := draw(time.Now()); err != nil {
+			return err
+		}
+		if n == count {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
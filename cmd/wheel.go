@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/progressions"
+	"github.com/dcccxiii/astro/wheel"
+)
+
+// Bounds and default of --size, in pixels.
+const (
+	minWheelSize     = 200
+	maxWheelSize     = 4096
+	defaultWheelSize = 800
+)
+
+// runWheel implements "astro wheel": the chart drawn as a wheel, as SVG or
+// PNG.
+func runWheel(args []string) error {
+	start := time.Now()
+	fs := flag.NewFlagSet("astro wheel", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: astro wheel <datetime> (<lat> <lon> | --place <place>) [--progressed <datetime>] [--synastry <chart>] [--transits <datetime>] [--format svg|png] [--size <px>] [--theme light|dark] [--output <file>] [flags]\n")
+		fmt.Fprintf(fs.Output(), "  Draws the chart as a wheel: signs, house cusps, planets and the major\n")
+		fmt.Fprintf(fs.Output(), "  aspects. SVG uses the glyphs of the names registry; PNG, drawn without\n")
+		fmt.Fprintf(fs.Output(), "  a font library, labels the signs and planets with their initials.\n")
+		fmt.Fprintf(fs.Output(), "  Up to two of --progressed, --synastry and --transits add rings of\n")
+		fmt.Fprintf(fs.Output(), "  planets around the chart, in that order outwards, for a biwheel or\n")
+		fmt.Fprintf(fs.Output(), "  triwheel; the aspects drawn are then those to the outermost ring.\n\n")
+		fs.PrintDefaults()
+	}
+
+	progressedFlag := fs.String("progressed", "", "Add a ring of the secondary progressed planets for this datetime")
+	synastryFlag := fs.String("synastry", "", "Add a ring of another chart's planets, given as <datetime> or <datetime>,<lat>,<lon>")
+	transitsFlag := fs.String("transits", "", "Add a ring of the transiting planets at this datetime")
+	formatFlag := fs.String("format", "", "Image format: svg or png (default from the --output extension, else svg)")
+	sizeFlag := fs.Int("size", defaultWheelSize, fmt.Sprintf("Width and height in pixels, %d-%d", minWheelSize, maxWheelSize))
+	themeFlag := fs.String("theme", "light", "Colour theme: light or dark")
+	outputFlag := fs.String("output", "", "File to write the image to (default stdout)")
+	houseSystemFlag := fs.String("house-system", "placidus", houseSystemUsage)
+	polar := addPolarFallback(fs)
+	planetsFlag := fs.String("planets", "classical", planetsUsage)
+	pointsFlag := fs.String("points", "", pointsUsage)
+	nodesFlag := fs.String("nodes", "none", "Lunar nodes to show: none, mean, true, both")
+	ephemerisFlag := fs.String("ephemeris", "swiss", ephemerisUsage)
+	timingsFlag := fs.Bool("timings", false, timingsUsage)
+	lang := addLang(fs)
+	tz := addZone(fs)
+	place := addPlace(fs, tz)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := lang.apply(); err != nil {
+		return err
+	}
+	if err := tz.apply(); err != nil {
+		return err
+	}
+	if pos, err = expandSaved(pos); err != nil {
+		return err
+	}
+	if pos, err = place.apply(pos, 1); err != nil {
+		return err
+	}
+	if len(pos) != 3 {
+		fs.Usage()
+		return fmt.Errorf("expected 3 positional arguments (<datetime> <lat> <lon>), got %d: %s", len(pos), strings.Join(pos, " "))
+	}
+	lat, lon, err := input.ParseCoordinates(pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := tz.locate(pos[1], pos[2], pos[0]); err != nil {
+		return err
+	}
+	t, err := tz.opts.ParseDateTime(pos[0])
+	if err != nil {
+		return err
+	}
+	format, err := wheelFormat(*formatFlag, *outputFlag)
+	if err != nil {
+		return err
+	}
+	if *sizeFlag < minWheelSize || *sizeFlag > maxWheelSize {
+		return fmt.Errorf("invalid --size %d: must be %d-%d", *sizeFlag, minWheelSize, maxWheelSize)
+	}
+	theme, err := wheel.ParseTheme(*themeFlag)
+	if err != nil {
+		return err
+	}
+	if err := polar.resolve(); err != nil {
+		return err
+	}
+	hsys, hsysName, err := parseHouseSystem(*houseSystemFlag)
+	if err != nil {
+		return err
+	}
+	nodeBodies, err := parseNodes(*nodesFlag)
+	if err != nil {
+		return err
+	}
+	planets, err := chartBodies(*planetsFlag, nodeBodies)
+	if err != nil {
+		return err
+	}
+	points, err := parsePoints(*pointsFlag)
+	if err != nil {
+		return err
+	}
+	rings, err := parseRings(tz, *progressedFlag, *synastryFlag, *transitsFlag)
+	if err != nil {
+		return err
+	}
+	backend, err := parseBackend(*ephemerisFlag)
+	if err != nil {
+		return err
+	}
+	rec := newRecorder(*timingsFlag, start)
+
+	if err := setEphePath(); err != nil {
+		return err
+	}
+	defer closeEphemeris()
+	nameAsteroids(planets)
+	rec.Mark("parse")
+
+	p := rec.Wrap(newProvider(backend, 0))
+	jd := ephemeris.JulianDay(t)
+	if err := checkDate(backend, jd); err != nil {
+		return err
+	}
+	r, err := polar.build(p, jd, planets, lat, lon, hsys, hsysName)
+	if err != nil {
+		return err
+	}
+	if err := output.AddPoints(&r, p, points); err != nil {
+		return err
+	}
+	c := output.Wheel(r)
+	if len(rings) > 0 {
+		c.Name = "Natal"
+	}
+	for _, ring := range rings {
+		at := ephemeris.JulianDay(ring.at)
+		if ring.kind == "progressed" {
+			at = progressions.Secondary(jd, at)
+		}
+		sky, err := output.BuildSky(p, at, planets)
+		if err != nil {
+			return err
+		}
+		c.Outer = append(c.Outer, output.WheelRing(ring.name(), sky))
+	}
+	d := wheel.Draw(c, *sizeFlag, theme)
+	rec.Mark("compute")
+
+	err = writeOutput(*outputFlag, func(w io.Writer) error {
+		if format == "png" {
+			return d.PNG(w)
+		}
+		return d.SVG(w)
+	})
+	if err != nil {
+		return internal(fmt.Errorf("error writing %s: %w", format, err))
+	}
+	rec.Mark("render")
+	return writeTimings(rec, "wheel", backend)
+}
+
+// wheelRing is an outer ring asked for on the command line.
+type wheelRing struct {
+	kind string // "progressed", "synastry" or "transits"
+	at   time.Time
+}
+
+func (r wheelRing) name() string {
+	return strings.ToUpper(r.kind[:1]) + r.kind[1:]
+}
+
+// parseRings returns the outer rings given by --progressed, --synastry and
+// --transits, from the inside out; empty flags add no ring.
+func parseRings(tz *zoneFlag, progressed, synastry, transits string) ([]wheelRing, error) {
+	var rings []wheelRing
+	for _, f := range []struct{ kind, value string }{
+		{"progressed", progressed}, {"synastry", synastry}, {"transits", transits},
+	} {
+		if f.value == "" {
+			continue
+		}
+		at, err := parseChartMoment(tz, []string{f.value})
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", f.kind, err)
+		}
+		rings = append(rings, wheelRing{f.kind, at})
+	}
+	if len(rings) > wheel.MaxOuter {
+		return nil, fmt.Errorf("at most %d of --progressed, --synastry and --transits can be drawn", wheel.MaxOuter)
+	}
+	return rings, nil
+}
+
+// wheelFormat returns the image format named by --format, or else implied
+// by the extension of the --output file.
+func wheelFormat(format, file string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+		if format != "png" {
+			format = "svg"
+		}
+	}
+	switch format = strings.ToLower(format); format {
+	case "svg", "png":
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q: valid values are svg, png", format)
+}
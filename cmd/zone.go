@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/input"
+	"github.com/dcccxiii/astro/output"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// zoneFlag holds the --tz and --default-time flags, which every command
+// taking a datetime accepts so that datetimes can be given in local civil
+// time, or as a date alone.
+type zoneFlag struct {
+	name        *string
+	defaultTime *string
+	// opts are what the flags make of them, for the command to read its
+	// datetimes with. Each command has its own, so sessions and batch
+	// workers do not share a zone.
+	opts input.Options
+	// source is where opts.Zone came from: "tz", "place" or
+	// "coordinates", or empty while it is unset.
+	source string
+	near   string // the atlas place whose zone the coordinates took
+	// coordinates is set for commands that take <lat> <lon>, as only they
+	// can read --tz LMT.
+	coordinates bool
+}
+
+// addZone defines --tz and --default-time on fs.
+func addZone(fs *flag.FlagSet) *zoneFlag {
+	return &zoneFlag{
+		opts:        input.Default(),
+		defaultTime: fs.String("default-time", "12:00", "Time of day of datetimes given as a date alone, e.g. 2025-06-01, or as today: HH:MM or HH:MM:SS, local like the date"),
+		name:        fs.String("tz", "", "IANA time zone of datetimes given without an offset, e.g. Europe/London, or LMT for the local mean time of the longitude; converted to UT with the zone's historical rules (default: looked up from the coordinates)"),
+	}
+}
+
+// apply sets opts.DefaultTime, and opts.Zone to the chosen zone.
+// Without --tz it clears the zone, for --place or locate to set. --tz LMT
+// is left for locate, which knows the longitude.
+func (z *zoneFlag) apply() error {
+	z.opts, z.source, z.near = input.Default(), "", ""
+	t, err := time.Parse("15:04:05", *z.defaultTime)
+	if err != nil {
+		t, err = time.Parse("15:04", *z.defaultTime)
+	}
+	if err != nil {
+		return &input.Error{Kind: "time of day", Value: *z.defaultTime, Reason: "expected HH:MM or HH:MM:SS, e.g. 12:00"}
+	}
+	z.opts.DefaultTime = t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+	if *z.name == "" {
+		return nil
+	}
+	if z.meanTime() {
+		if !z.coordinates {
+			return &input.Error{Kind: "time zone", Value: *z.name, Reason: "local mean time needs a longitude, and this command takes no <lat> <lon>"}
+		}
+		z.source = "tz"
+		return nil
+	}
+	loc, err := time.LoadLocation(*z.name)
+	if err != nil || *z.name == "Local" {
+		return &input.Error{Kind: "time zone", Value: *z.name, Reason: "expected an IANA time zone name, e.g. Europe/London, or LMT"}
+	}
+	z.opts.Zone, z.source = loc, "tz"
+	return nil
+}
+
+// meanTime reports whether --tz asks for local mean time.
+func (z *zoneFlag) meanTime() bool {
+	return strings.EqualFold(*z.name, "LMT")
+}
+
+// locate sets opts.MeanTime to the local mean time at lon, and opts.Zone
+// to it under --tz LMT. Otherwise, when any of the datetimes is local and
+// neither --tz nor --place gave a zone, it sets opts.Zone to the time zone
+// at lat and lon, looked up in the atlas, or fails if that is unclear, as
+// near a border. Coordinates that do not parse are left for the command to
+// report.
+func (z *zoneFlag) locate(lat, lon string, datetimes ...string) error {
+	la, err := input.ParseLatitude(lat)
+	if err != nil {
+		return nil
+	}
+	lo, err := input.ParseLongitude(lon)
+	if err != nil {
+		return nil
+	}
+	z.opts.MeanTime = input.MeanTimeAt(lo)
+	if z.meanTime() {
+		z.opts.Zone = z.opts.MeanTime
+		return nil
+	}
+	if z.source != "" && z.source != "coordinates" {
+		return nil
+	}
+	local := false
+	for _, s := range datetimes {
+		local = local || input.IsLocal(s)
+	}
+	if !local {
+		return nil
+	}
+	a, err := loadAtlas()
+	if err != nil {
+		return err
+	}
+	zone, near, err := a.ZoneAt(la, lo)
+	if err != nil {
+		return fmt.Errorf("%w; give it with --tz", err)
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return fmt.Errorf("the time zone at %s %s, %q, is unknown", lat, lon, zone)
+	}
+	z.opts.Zone, z.source, z.near = loc, "coordinates", ""
+	if near != nil {
+		z.near = near.String()
+	}
+	return nil
+}
+
+// local describes the local time datetime s was given in, for the output
+// to echo, or returns nil if it was given in UT or with an offset.
+func (z *zoneFlag) local(s string) *output.LocalInfo {
+	zone := ""
+	switch {
+	case strings.HasSuffix(s, "]"):
+		zone = s[strings.LastIndexByte(s, '[')+1 : len(s)-1]
+	case input.IsLocal(s) && z.opts.Zone != nil:
+		zone = z.opts.Zone.String()
+	default:
+		return nil
+	}
+	t, err := z.opts.ParseLocalDateTime(s)
+	if err != nil {
+		return nil
+	}
+	l := &output.LocalInfo{Time: t, Offset: formatOffset(t), Zone: zone, Source: z.source, Near: z.near}
+	if strings.HasSuffix(s, "]") {
+		l.Source, l.Near = "datetime", ""
+	}
+	l.MeanTime = z.opts.MeanTime != nil && t.Location() == z.opts.MeanTime
+	return l
+}
+
+// solarTime returns jd (UT) as the local mean and apparent times of east
+// longitude lon, for --solar-time.
+func solarTime(jd, lon float64) (*output.SolarTimeInfo, error) {
+	mean := jd + lon/360
+	apparent, err := swisseph.LMTToLAT(mean, lon)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating the equation of time: %w", err)
+	}
+	return output.SolarTime(mean, apparent), nil
+}
+
+// formatOffset returns t's offset from UTC as ±hh:mm, or ±hh:mm:ss if it
+// is not a whole number of minutes, as local mean time seldom is. (The
+// time package signs an offset of less than a minute west as +00:00:-ss.)
+func formatOffset(t time.Time) string {
+	_, off := t.Zone()
+	sign := '+'
+	if off < 0 {
+		sign, off = '-', -off
+	}
+	s := fmt.Sprintf("%c%02d:%02d", sign, off/3600, off/60%60)
+	if off%60 != 0 {
+		s += fmt.Sprintf(":%02d", off%60)
+	}
+	return s
+}
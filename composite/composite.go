@@ -0,0 +1,105 @@
+// Package composite builds midpoint composite charts: the relationship
+// chart whose every planet lies at the midpoint of the two partners'
+// positions. It also finds the moment and place of a Davison chart.
+package composite
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Moment is one partner's birth, as a Julian Day (UT) and place.
+type Moment struct {
+	JD       float64
+	Lat, Lon float64
+}
+
+// HousesFunc casts houses from sidereal time (armc), latitude and
+// obliquity, like swisseph.CalcHousesARMC.
+type HousesFunc func(armc, lat, eps float64, hsys byte) (ephemeris.HouseResult, error)
+
+// Provider serves a composite chart through the ephemeris.Provider
+// interface, so the usual chart pipeline can render it. The jd passed to
+// its methods is ignored: a composite has no moment of its own.
+//
+// CalcHouses takes the composite MC as the midpoint of the partners' MCs
+// and casts the other cusps from it at the latitude it is given (the
+// reference latitude), using Houses and Obliquity.
+type Provider struct {
+	P         ephemeris.Provider // computes the partners' charts
+	A, B      Moment
+	Obliquity float64 // degrees
+	Houses    HousesFunc
+}
+
+// CalcPlanet implements ephemeris.Provider: the midpoint of the body's
+// positions in the two charts, with averaged speeds.
+func (c Provider) CalcPlanet(_ float64, body int) (ephemeris.PlanetPos, error) {
+	a, err := c.P.CalcPlanet(c.A.JD, body)
+	if err != nil {
+		return ephemeris.PlanetPos{}, err
+	}
+	b, err := c.P.CalcPlanet(c.B.JD, body)
+	if err != nil {
+		return ephemeris.PlanetPos{}, err
+	}
+	return ephemeris.PlanetPos{
+		Longitude:     Midpoint(a.Longitude, b.Longitude),
+		Latitude:      (a.Latitude + b.Latitude) / 2,
+		Distance:      (a.Distance + b.Distance) / 2,
+		SpeedLon:      (a.SpeedLon + b.SpeedLon) / 2,
+		SpeedLat:      (a.SpeedLat + b.SpeedLat) / 2,
+		SpeedDistance: (a.SpeedDistance + b.SpeedDistance) / 2,
+	}, nil
+}
+
+// CalcHouses implements ephemeris.Provider. lat is the reference latitude;
+// lon is ignored.
+func (c Provider) CalcHouses(_ float64, lat, _ float64, hsys byte) (ephemeris.HouseResult, error) {
+	if c.Houses == nil {
+		return ephemeris.HouseResult{}, fmt.Errorf("composite: no house function")
+	}
+	a, err := c.P.CalcHouses(c.A.JD, c.A.Lat, c.A.Lon, hsys)
+	if err != nil {
+		return ephemeris.HouseResult{}, err
+	}
+	b, err := c.P.CalcHouses(c.B.JD, c.B.Lat, c.B.Lon, hsys)
+	if err != nil {
+		return ephemeris.HouseResult{}, err
+	}
+	mc := Midpoint(a.MC, b.MC)
+	return c.Houses(ARMC(mc, c.Obliquity), lat, c.Obliquity, hsys)
+}
+
+// PlanetName implements ephemeris.Provider.
+func (c Provider) PlanetName(body int) string {
+	return c.P.PlanetName(body)
+}
+
+// Davison returns the moment and place of the Davison relationship chart
+// of a and b: the Julian Day halfway between theirs, at the latitude
+// halfway between theirs and the longitude halfway between theirs on the
+// shorter arc, in (-180, 180]. The chart is then cast as a natal chart.
+func Davison(a, b Moment) Moment {
+	// Diff is in [-180, 180), so the negated difference is in (-180, 180].
+	lon := -angle.Diff(0, Midpoint(a.Lon, b.Lon))
+	return Moment{JD: (a.JD + b.JD) / 2, Lat: (a.Lat + b.Lat) / 2, Lon: lon}
+}
+
+// Midpoint returns the midpoint of longitudes a and b on the shorter arc
+// between them, in [0, 360). For points exactly opposite, it is the one
+// 90° ahead of a. It is angle.Midpoint.
+func Midpoint(a, b float64) float64 {
+	return angle.Midpoint(a, b)
+}
+
+// ARMC returns the right ascension of an MC at ecliptic longitude mc, for
+// obliquity eps, in degrees. The MC lies on the ecliptic, so its right
+// ascension follows from the longitude alone.
+func ARMC(mc, eps float64) float64 {
+	l, e := mc*math.Pi/180, eps*math.Pi/180
+	return angle.Norm360(math.Atan2(math.Sin(l)*math.Cos(e), math.Cos(l)) * 180 / math.Pi)
+}
@@ -0,0 +1,87 @@
+package composite_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/composite"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+func TestMidpoint(t *testing.T) {
+	cases := []struct{ a, b, want float64 }{
+		{10, 20, 15},
+		{20, 10, 15},
+		{350, 10, 0},
+		{340, 20, 0},
+		{0, 180, 90},
+		{180, 0, 270},
+		{100, 300, 20},
+	}
+	for _, tc := range cases {
+		if got := composite.Midpoint(tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Midpoint(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestARMC(t *testing.T) {
+	// The cardinal points have equal longitude and right ascension.
+	for _, mc := range []float64{0, 90, 180, 270} {
+		if got := composite.ARMC(mc, 23.44); math.Abs(got-mc) > 1e-9 && math.Abs(got-mc-360) > 1e-9 {
+			t.Errorf("ARMC(%v) = %v, want %v", mc, got, mc)
+		}
+	}
+	// tan(RA) = tan(λ)·cos(ε): 45° of longitude is about 42.54° of RA.
+	if got := composite.ARMC(45, 23.44); math.Abs(got-42.5357) > 1e-3 {
+		t.Errorf("ARMC(45) = %.4f, want 42.5357", got)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 350, SpeedLon: 1}},
+		Houses:  ephemeris.HouseResult{MC: 350},
+	}
+	var gotARMC, gotLat float64
+	c := composite.Provider{
+		P:         p,
+		A:         composite.Moment{JD: 0},
+		B:         composite.Moment{JD: 20}, // Sun at 10°
+		Obliquity: 23.44,
+		Houses: func(armc, lat, eps float64, hsys byte) (ephemeris.HouseResult, error) {
+			gotARMC, gotLat = armc, lat
+			return ephemeris.HouseResult{}, nil
+		},
+	}
+
+	sun, err := c.CalcPlanet(12345, ephemeris.Sun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(sun.Longitude) > 1e-9 || sun.SpeedLon != 1 {
+		t.Errorf("composite Sun = %.4f° at %v°/day, want 0° at 1°/day", sun.Longitude, sun.SpeedLon)
+	}
+
+	if _, err := c.CalcHouses(12345, 40, 0, 'P'); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(gotARMC-composite.ARMC(350, 23.44)) > 1e-9 || gotLat != 40 {
+		t.Errorf("houses cast from ARMC %.4f at latitude %v, want ARMC of 350° at 40", gotARMC, gotLat)
+	}
+}
+
+func TestDavison(t *testing.T) {
+	a := composite.Moment{JD: 2447000, Lat: 52.52, Lon: 13.405}
+	b := composite.Moment{JD: 2448000, Lat: 40.7128, Lon: -74.006}
+	got := composite.Davison(a, b)
+	if got.JD != 2447500 || math.Abs(got.Lat-46.6164) > 1e-9 || math.Abs(got.Lon+30.3005) > 1e-9 {
+		t.Errorf("Davison = %+v, want JD 2447500 at (46.6164, -30.3005)", got)
+	}
+	// Across the antimeridian the midpoint takes the shorter arc.
+	a.Lon, b.Lon = 170, -170
+	if got := composite.Davison(a, b); math.Abs(got.Lon-180) > 1e-9 {
+		t.Errorf("Davison longitude of 170 and -170 = %v, want 180", got.Lon)
+	}
+}
@@ -0,0 +1,107 @@
+// Package cycles finds the phases of the synodic cycle between two planets —
+// conjunction, waxing square, opposition and waning square — over long
+// spans of time, for mundane (historical) research.
+package cycles
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// Phase is a named angle of the faster planet ahead of the slower one.
+type Phase struct {
+	Name  string
+	Angle float64
+}
+
+// Phases are the four principal phases of a synodic cycle, in order.
+var Phases = []Phase{
+	{"conjunction", 0},
+	{"waxing square", 90},
+	{"opposition", 180},
+	{"waning square", 270},
+}
+
+// Event is one exact phase between two planets. Retrograde motion can make
+// the same phase occur up to three times in succession (e.g. a triple
+// conjunction); each pass is a separate Event.
+type Event struct {
+	JD    float64
+	Fast  int // the planet with the shorter orbital period
+	Slow  int
+	Phase Phase
+	// Longitudes of both planets at the exact phase.
+	FastLon float64
+	SlowLon float64
+}
+
+// DefaultStep is the sampling interval in days used when Scan is given a
+// zero step. It is short enough not to miss a phase near a station.
+const DefaultStep = 5.0
+
+// Scan returns every exact phase between planets a and b in the Julian Day
+// range [from, to], in chronological order. The planets may be given in
+// either order; the one with the lower body ID is treated as the faster.
+// step is the sampling interval in days (DefaultStep if zero).
+func Scan(p ephemeris.Provider, a, b int, from, to, step float64) ([]Event, error) {
+	if a == b {
+		return nil, fmt.Errorf("cycle needs two different planets")
+	}
+	if step <= 0 {
+		step = DefaultStep
+	}
+	fast, slow := a, b
+	if fast > slow {
+		fast, slow = slow, fast
+	}
+
+	sep := func(jd float64) (float64, error) {
+		f, err := p.CalcPlanet(jd, fast)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating %s: %w", p.PlanetName(fast), err)
+		}
+		s, err := p.CalcPlanet(jd, slow)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating %s: %w", p.PlanetName(slow), err)
+		}
+		return angle.Norm360(f.Longitude - s.Longitude), nil
+	}
+
+	var events []Event
+	err := search.Scan(sep, from, to, step, func(t0, d0, t1, d1 float64) error {
+		for _, ph := range Phases {
+			o0, o1 := angle.Diff(d0, ph.Angle), angle.Diff(d1, ph.Angle)
+			if !search.Crossed(o0, o1) {
+				continue
+			}
+			jd, err := search.Root(search.Offset(sep, ph.Angle), t0, t1, o0, o1)
+			if err != nil {
+				return err
+			}
+			e := Event{JD: jd, Fast: fast, Slow: slow, Phase: ph}
+			if e.FastLon, err = lon(p, jd, fast); err != nil {
+				return err
+			}
+			if e.SlowLon, err = lon(p, jd, slow); err != nil {
+				return err
+			}
+			events = append(events, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func lon(p ephemeris.Provider, jd float64, body int) (float64, error) {
+	pos, err := p.CalcPlanet(jd, body)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+	}
+	return pos.Longitude, nil
+}
@@ -0,0 +1,89 @@
+package cycles_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/cycles"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// TestScan_UniformMotion uses a mock where Jupiter gains 1°/day on a
+// stationary Saturn, so each phase falls on a whole number of days.
+func TestScan_UniformMotion(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch: 0,
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Jupiter: {Longitude: 350, SpeedLon: 1},
+			ephemeris.Saturn:  {Longitude: 0},
+		},
+	}
+
+	// Passing the slower planet first must not change the result.
+	events, err := cycles.Scan(p, ephemeris.Saturn, ephemeris.Jupiter, 0, 400, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		jd    float64
+		phase string
+	}{
+		{10, "conjunction"},
+		{100, "waxing square"},
+		{190, "opposition"},
+		{280, "waning square"},
+		{370, "conjunction"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if math.Abs(e.JD-w.jd) > 1e-4 || e.Phase.Name != w.phase {
+			t.Errorf("event %d = %s at %.5f, want %s at %.0f", i, e.Phase.Name, e.JD, w.phase, w.jd)
+		}
+		if e.Fast != ephemeris.Jupiter || e.Slow != ephemeris.Saturn {
+			t.Errorf("event %d fast/slow = %d/%d, want Jupiter/Saturn", i, e.Fast, e.Slow)
+		}
+	}
+}
+
+// TestScan_Retrograde checks that a retrograde loop produces three passes
+// of the same phase.
+func TestScan_Retrograde(t *testing.T) {
+	p := &loopProvider{}
+	events, err := cycles.Scan(p, ephemeris.Jupiter, ephemeris.Saturn, -5, 25, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, e := range events {
+		if e.Phase.Name == "conjunction" {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d conjunctions, want 3: %+v", n, events)
+	}
+}
+
+// loopProvider puts Saturn at 0° and moves Jupiter forward, back and
+// forward again through it: +1°/day to jd 5, -1°/day to jd 15, then +1°/day.
+type loopProvider struct{ ephemeris.MockProvider }
+
+func (loopProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	if body == ephemeris.Saturn {
+		return ephemeris.PlanetPos{}, nil
+	}
+	var lon float64
+	switch {
+	case jd < 5:
+		lon = jd - 0.5
+	case jd < 15:
+		lon = 4.5 - (jd - 5)
+	default:
+		lon = -5.5 + (jd - 15)
+	}
+	return ephemeris.PlanetPos{Longitude: math.Mod(lon+360, 360)}, nil
+}
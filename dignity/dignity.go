@@ -0,0 +1,240 @@
+// Package dignity holds the essential dignities of the seven classical
+// planets (domicile, exaltation, triplicity, term and face), their scores,
+// and the natures of the planets.
+package dignity
+
+import (
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// rulers gives the domicile ruler of each sign, from Aries.
+var rulers = [12]int{
+	ephemeris.Mars, ephemeris.Venus, ephemeris.Mercury, ephemeris.Moon,
+	ephemeris.Sun, ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars,
+	ephemeris.Jupiter, ephemeris.Saturn, ephemeris.Saturn, ephemeris.Jupiter,
+}
+
+// Sign returns the index of the sign containing ecliptic longitude lon,
+// 0 (Aries) to 11 (Pisces).
+func Sign(lon float64) int {
+	return int(angle.Norm360(lon)/30) % 12
+}
+
+// Ruler returns the traditional ruler of sign, 0 (Aries) to 11 (Pisces).
+// The outer planets rule no signs.
+func Ruler(sign int) int { return rulers[sign] }
+
+// RulerOf returns the ruler of the sign containing ecliptic longitude lon.
+func RulerOf(lon float64) int { return rulers[Sign(lon)] }
+
+// ModernRuler returns the modern ruler of sign: the traditional ruler,
+// except that Uranus rules Aquarius, Neptune Pisces and Pluto Scorpio.
+func ModernRuler(sign int) int {
+	switch sign {
+	case 7:
+		return ephemeris.Pluto
+	case 10:
+		return ephemeris.Uranus
+	case 11:
+		return ephemeris.Neptune
+	}
+	return rulers[sign]
+}
+
+// Benefic reports whether body is one of the benefics, Venus and Jupiter.
+func Benefic(body int) bool {
+	return body == ephemeris.Venus || body == ephemeris.Jupiter
+}
+
+// Malefic reports whether body is one of the malefics, Mars and Saturn.
+func Malefic(body int) bool {
+	return body == ephemeris.Mars || body == ephemeris.Saturn
+}
+
+// triplicity gives the Dorothean lords of each element, by day, by night
+// and participating, indexed by sign modulo 4: fire, earth, air, water.
+var triplicity = [4][3]int{
+	{ephemeris.Sun, ephemeris.Jupiter, ephemeris.Saturn},
+	{ephemeris.Venus, ephemeris.Moon, ephemeris.Mars},
+	{ephemeris.Saturn, ephemeris.Mercury, ephemeris.Jupiter},
+	{ephemeris.Venus, ephemeris.Mars, ephemeris.Moon},
+}
+
+// TriplicityLords returns the Dorothean triplicity lords of sign's element:
+// the lord by day, the lord by night and the participating lord.
+func TriplicityLords(sign int) [3]int { return triplicity[sign%4] }
+
+// Points of each essential dignity, after Lilly.
+const (
+	DomicilePoints   = 5
+	ExaltationPoints = 4
+	TriplicityPoints = 3
+	TermPoints       = 2
+	FacePoints       = 1
+)
+
+// Classical are the seven planets that hold essential dignities.
+var Classical = []int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mercury, ephemeris.Venus,
+	ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn,
+}
+
+// exaltations gives the planet exalted in each sign, or -1.
+var exaltations = [12]int{
+	ephemeris.Sun, ephemeris.Moon, -1, ephemeris.Jupiter, -1, ephemeris.Mercury,
+	ephemeris.Saturn, -1, -1, ephemeris.Mars, -1, ephemeris.Venus,
+}
+
+// Exaltation returns the planet exalted in sign, or -1 if none is.
+func Exaltation(sign int) int { return exaltations[sign] }
+
+// bound is one term: its lord rules up to the given degree of the sign.
+type bound struct {
+	lord int
+	end  float64
+}
+
+// terms are the Egyptian terms of each sign.
+var terms = [12][5]bound{
+	{{ephemeris.Jupiter, 6}, {ephemeris.Venus, 12}, {ephemeris.Mercury, 20}, {ephemeris.Mars, 25}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Venus, 8}, {ephemeris.Mercury, 14}, {ephemeris.Jupiter, 22}, {ephemeris.Saturn, 27}, {ephemeris.Mars, 30}},
+	{{ephemeris.Mercury, 6}, {ephemeris.Jupiter, 12}, {ephemeris.Venus, 17}, {ephemeris.Mars, 24}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Mars, 7}, {ephemeris.Venus, 13}, {ephemeris.Mercury, 19}, {ephemeris.Jupiter, 26}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Jupiter, 6}, {ephemeris.Venus, 11}, {ephemeris.Saturn, 18}, {ephemeris.Mercury, 24}, {ephemeris.Mars, 30}},
+	{{ephemeris.Mercury, 7}, {ephemeris.Venus, 17}, {ephemeris.Jupiter, 21}, {ephemeris.Mars, 28}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Saturn, 6}, {ephemeris.Mercury, 14}, {ephemeris.Jupiter, 21}, {ephemeris.Venus, 28}, {ephemeris.Mars, 30}},
+	{{ephemeris.Mars, 7}, {ephemeris.Venus, 11}, {ephemeris.Mercury, 19}, {ephemeris.Jupiter, 24}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Jupiter, 12}, {ephemeris.Venus, 17}, {ephemeris.Mercury, 21}, {ephemeris.Saturn, 26}, {ephemeris.Mars, 30}},
+	{{ephemeris.Mercury, 7}, {ephemeris.Jupiter, 14}, {ephemeris.Venus, 22}, {ephemeris.Saturn, 26}, {ephemeris.Mars, 30}},
+	{{ephemeris.Mercury, 7}, {ephemeris.Venus, 13}, {ephemeris.Jupiter, 20}, {ephemeris.Mars, 25}, {ephemeris.Saturn, 30}},
+	{{ephemeris.Venus, 12}, {ephemeris.Jupiter, 16}, {ephemeris.Mercury, 19}, {ephemeris.Mars, 28}, {ephemeris.Saturn, 30}},
+}
+
+// TermLord returns the lord of the Egyptian term containing lon.
+func TermLord(lon float64) int {
+	deg := math.Mod(angle.Norm360(lon), 30)
+	for _, b := range terms[Sign(lon)] {
+		if deg < b.end {
+			return b.lord
+		}
+	}
+	return terms[Sign(lon)][4].lord
+}
+
+// faceOrder is the Chaldean order starting from Mars, lord of the first
+// face of Aries; the faces cycle through it.
+var faceOrder = [7]int{
+	ephemeris.Mars, ephemeris.Sun, ephemeris.Venus, ephemeris.Mercury,
+	ephemeris.Moon, ephemeris.Saturn, ephemeris.Jupiter,
+}
+
+// FaceLord returns the lord of the 10° face (decan) containing lon.
+func FaceLord(lon float64) int {
+	return faceOrder[int(angle.Norm360(lon)/10)%7]
+}
+
+// Score returns the essential dignity points body holds at lon: its
+// domicile, exaltation, triplicity (the lord of the chart's sect only),
+// term and face. day is true for a day chart.
+func Score(body int, lon float64, day bool) int {
+	sign, score := Sign(lon), 0
+	if rulers[sign] == body {
+		score += DomicilePoints
+	}
+	if exaltations[sign] == body {
+		score += ExaltationPoints
+	}
+	lords := TriplicityLords(sign)
+	if (day && lords[0] == body) || (!day && lords[1] == body) {
+		score += TriplicityPoints
+	}
+	if TermLord(lon) == body {
+		score += TermPoints
+	}
+	if FaceLord(lon) == body {
+		score += FacePoints
+	}
+	return score
+}
+
+// Almuten returns the classical planets with the most essential dignity
+// at lon, several if they tie, and their score.
+func Almuten(lon float64, day bool) (bodies []int, score int) {
+	for _, body := range Classical {
+		switch s := Score(body, lon, day); {
+		case s > score:
+			bodies, score = []int{body}, s
+		case s == score && s > 0:
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies, score
+}
+
+// Dignity is an essential dignity by which one planet can receive another.
+type Dignity int
+
+const (
+	Domicile Dignity = iota
+	Exalted
+)
+
+func (d Dignity) String() string {
+	if d == Domicile {
+		return "domicile"
+	}
+	return "exaltation"
+}
+
+// Reception is a mutual reception: each planet is in a sign where the
+// other has dignity. A is in B's AIn, and B in A's BIn; when the two
+// differ, the reception is mixed.
+type Reception struct {
+	A, B     int
+	AIn, BIn Dignity
+}
+
+// Mixed reports whether the reception joins a domicile with an exaltation.
+func (r Reception) Mixed() bool { return r.AIn != r.BIn }
+
+// Receptions returns the mutual receptions among the classical planets at
+// the given longitudes, pairs ordered as in Classical. Planets missing from
+// lons are skipped.
+func Receptions(lons map[int]float64) []Reception {
+	var out []Reception
+	for i, a := range Classical {
+		la, ok := lons[a]
+		if !ok {
+			continue
+		}
+		for _, b := range Classical[i+1:] {
+			lb, ok := lons[b]
+			if !ok {
+				continue
+			}
+			aIn, ok := hosts(b, Sign(la))
+			if !ok {
+				continue
+			}
+			if bIn, ok := hosts(a, Sign(lb)); ok {
+				out = append(out, Reception{A: a, B: b, AIn: aIn, BIn: bIn})
+			}
+		}
+	}
+	return out
+}
+
+// hosts returns the dignity body has in sign, if it rules or is exalted
+// there.
+func hosts(body, sign int) (Dignity, bool) {
+	switch body {
+	case rulers[sign]:
+		return Domicile, true
+	case exaltations[sign]:
+		return Exalted, true
+	}
+	return 0, false
+}
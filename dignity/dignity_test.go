@@ -0,0 +1,150 @@
+package dignity_test
+
+import (
+	"testing"
+
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+func TestRulerOf(t *testing.T) {
+	tests := []struct {
+		lon  float64
+		want int
+	}{
+		{0, ephemeris.Mars},
+		{45, ephemeris.Venus},
+		{125, ephemeris.Sun},
+		{299.9, ephemeris.Saturn},
+		{300, ephemeris.Saturn},
+		{359, ephemeris.Jupiter},
+		{-1, ephemeris.Jupiter},
+		{360, ephemeris.Mars},
+	}
+	for _, tt := range tests {
+		if got := dignity.RulerOf(tt.lon); got != tt.want {
+			t.Errorf("RulerOf(%v) = %d, want %d", tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestModernRuler(t *testing.T) {
+	want := map[int]int{0: ephemeris.Mars, 7: ephemeris.Pluto, 9: ephemeris.Saturn, 10: ephemeris.Uranus, 11: ephemeris.Neptune}
+	for sign, body := range want {
+		if got := dignity.ModernRuler(sign); got != body {
+			t.Errorf("ModernRuler(%d) = %d, want %d", sign, got, body)
+		}
+	}
+}
+
+func TestNatures(t *testing.T) {
+	for body := ephemeris.Sun; body <= ephemeris.Pluto; body++ {
+		b, m := dignity.Benefic(body), dignity.Malefic(body)
+		wantB := body == ephemeris.Venus || body == ephemeris.Jupiter
+		wantM := body == ephemeris.Mars || body == ephemeris.Saturn
+		if b != wantB || m != wantM {
+			t.Errorf("body %d: benefic %v malefic %v, want %v %v", body, b, m, wantB, wantM)
+		}
+	}
+}
+
+func TestTriplicityLords(t *testing.T) {
+	// Leo is fire; Pisces is water.
+	if got := dignity.TriplicityLords(4); got != [3]int{ephemeris.Sun, ephemeris.Jupiter, ephemeris.Saturn} {
+		t.Errorf("Leo triplicity lords %v", got)
+	}
+	if got := dignity.TriplicityLords(11); got != [3]int{ephemeris.Venus, ephemeris.Mars, ephemeris.Moon} {
+		t.Errorf("Pisces triplicity lords %v", got)
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		body int
+		lon  float64
+		day  bool
+		want int
+	}{
+		{ephemeris.Sun, 19, true, 8},     // exaltation, day triplicity, face
+		{ephemeris.Sun, 19, false, 5},    // no triplicity by night
+		{ephemeris.Mars, 5, false, 6},    // domicile, face
+		{ephemeris.Jupiter, 5, false, 5}, // night triplicity, term
+		{ephemeris.Venus, 355, true, 7},  // exaltation, day triplicity; Saturn's term, Mars's face
+		{ephemeris.Moon, 200, true, 0},
+	}
+	for _, tt := range tests {
+		if got := dignity.Score(tt.body, tt.lon, tt.day); got != tt.want {
+			t.Errorf("Score(%d, %v, %v) = %d, want %d", tt.body, tt.lon, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestTermAndFace(t *testing.T) {
+	if got := dignity.TermLord(40); got != ephemeris.Mercury { // 10° Taurus
+		t.Errorf("TermLord(10° Taurus) = %d, want Mercury", got)
+	}
+	if got := dignity.TermLord(359.99); got != ephemeris.Saturn {
+		t.Errorf("TermLord(29° Pisces) = %d, want Saturn", got)
+	}
+	faces := []struct {
+		lon  float64
+		want int
+	}{
+		{0, ephemeris.Mars}, {35, ephemeris.Mercury}, {285, ephemeris.Mars}, {359, ephemeris.Mars},
+	}
+	for _, f := range faces {
+		if got := dignity.FaceLord(f.lon); got != f.want {
+			t.Errorf("FaceLord(%v) = %d, want %d", f.lon, got, f.want)
+		}
+	}
+}
+
+func TestAlmuten(t *testing.T) {
+	// 5° Aries: by day the Sun's exaltation and triplicity outweigh Mars's
+	// domicile and face; by night Jupiter takes the triplicity.
+	bodies, score := dignity.Almuten(5, true)
+	if len(bodies) != 1 || bodies[0] != ephemeris.Sun || score != 7 {
+		t.Errorf("Almuten(5° Aries, day) = %v, %d; want the Sun, 7", bodies, score)
+	}
+	bodies, score = dignity.Almuten(5, false)
+	if len(bodies) != 1 || bodies[0] != ephemeris.Mars || score != 6 {
+		t.Errorf("Almuten(5° Aries, night) = %v, %d; want Mars, 6", bodies, score)
+	}
+	// 10° Capricorn by night: Saturn by domicile ties Mars by exaltation
+	// and face.
+	bodies, score = dignity.Almuten(280, false)
+	if len(bodies) != 2 || bodies[0] != ephemeris.Mars || bodies[1] != ephemeris.Saturn || score != 5 {
+		t.Errorf("Almuten(10° Capricorn, night) = %v, %d; want Mars and Saturn, 5", bodies, score)
+	}
+}
+
+func TestReceptions(t *testing.T) {
+	lons := map[int]float64{
+		ephemeris.Venus:   15,  // Aries, Mars's domicile
+		ephemeris.Mars:    45,  // Taurus, Venus's domicile
+		ephemeris.Moon:    100, // Cancer, Jupiter's exaltation
+		ephemeris.Jupiter: 40,  // Taurus, the Moon's exaltation
+		ephemeris.Sun:     290, // Capricorn, Mars's exaltation; Mars is not in Leo
+		ephemeris.Saturn:  190, // Libra, its own exaltation
+	}
+	got := dignity.Receptions(lons)
+	want := []dignity.Reception{
+		{A: ephemeris.Moon, B: ephemeris.Jupiter, AIn: dignity.Exalted, BIn: dignity.Exalted},
+		{A: ephemeris.Venus, B: ephemeris.Mars, AIn: dignity.Domicile, BIn: dignity.Domicile},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Receptions = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("reception %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Mercury in Pisces (Venus exalted) and Venus in Virgo (Mercury's
+	// domicile and exaltation): mixed.
+	got = dignity.Receptions(map[int]float64{ephemeris.Mercury: 340, ephemeris.Venus: 160})
+	if len(got) != 1 || !got[0].Mixed() || got[0].AIn != dignity.Exalted || got[0].BIn != dignity.Domicile {
+		t.Errorf("Receptions = %+v, want Mercury in Venus's exaltation, Venus in Mercury's domicile", got)
+	}
+}
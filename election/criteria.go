@@ -0,0 +1,276 @@
+package election
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/zodiac"
+)
+
+// Criterion is one condition a moment must meet. It is written either as a
+// line of the criteria language (see Parse) or as a JSON object with the
+// same fields (see ParseJSON).
+type Criterion struct {
+	// Subject is a planet ("moon"), an angle ("asc", "mc"), or the ruler
+	// of the sign on an angle ("asc ruler", "mc ruler").
+	Subject string `json:"subject"`
+	// Is is the condition: angular, succedent, cadent, house, in, direct,
+	// retrograde, benefic, malefic, combust, voc, waxing or waning.
+	Is     string `json:"is"`
+	Sign   string `json:"sign,omitempty"`   // for "in"
+	Houses []int  `json:"houses,omitempty"` // for "house"
+	Not    bool   `json:"not,omitempty"`
+	// Prefer makes the criterion count towards a match's score instead of
+	// being required.
+	Prefer bool    `json:"prefer,omitempty"`
+	Weight float64 `json:"weight,omitempty"` // score of a preference; default 1
+}
+
+// bodies maps the planet names accepted as subjects to body IDs.
+var bodies = map[string]int{
+	"sun": ephemeris.Sun, "moon": ephemeris.Moon, "mercury": ephemeris.Mercury,
+	"venus": ephemeris.Venus, "mars": ephemeris.Mars, "jupiter": ephemeris.Jupiter,
+	"saturn": ephemeris.Saturn, "uranus": ephemeris.Uranus, "neptune": ephemeris.Neptune,
+	"pluto": ephemeris.Pluto,
+}
+
+// conditions lists the valid values of Criterion.Is.
+const conditions = "angular, succedent, cadent, house, in, direct, retrograde, benefic, malefic, combust, voc, waxing, waning"
+
+// CombustOrb is the distance from the Sun, in degrees, within which a
+// planet is combust.
+const CombustOrb = 8.5
+
+// Parse parses criteria written one per line or separated by commas or
+// semicolons; text after # is a comment. Each criterion reads
+//
+//	[prefer [<weight>]] <subject> [is] [not] <condition>
+//
+// for example "moon not voc", "jupiter angular", "venus in house 1,10",
+// "mars not in scorpio" or "prefer 2 asc ruler benefic". "waxing moon",
+// "asc ruled by a benefic" and "void of course" are also understood.
+func Parse(s string) ([]Criterion, error) {
+	var out []Criterion
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, clause := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' }) {
+			if strings.TrimSpace(clause) == "" {
+				continue
+			}
+			// "house 1,10" is split at its commas; rejoin the numbers.
+			if n := len(out); n > 0 && out[n-1].Is == "house" && isNumber(clause) {
+				h, _ := strconv.Atoi(strings.TrimSpace(clause))
+				out[n-1].Houses = append(out[n-1].Houses, h)
+				continue
+			}
+			c, err := parseClause(clause)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, c)
+		}
+	}
+	if err := validate(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ParseJSON parses a JSON array of Criterion objects.
+func ParseJSON(data []byte) ([]Criterion, error) {
+	var out []Criterion
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid criteria JSON: %w", err)
+	}
+	for i := range out {
+		out[i].Subject = strings.ToLower(strings.TrimSpace(out[i].Subject))
+		out[i].Is = strings.ToLower(strings.TrimSpace(out[i].Is))
+		out[i].Sign = strings.ToLower(strings.TrimSpace(out[i].Sign))
+	}
+	if err := validate(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseClause(clause string) (Criterion, error) {
+	words := strings.Fields(strings.ToLower(clause))
+	bad := func() (Criterion, error) {
+		return Criterion{}, fmt.Errorf("cannot parse criterion %q: expected [prefer [<weight>]] <subject> [not] <condition>", strings.TrimSpace(clause))
+	}
+	var c Criterion
+	if len(words) > 0 && words[0] == "prefer" {
+		c.Prefer, words = true, words[1:]
+		if len(words) > 0 && isNumber(words[0]) {
+			w, err := strconv.ParseFloat(words[0], 64)
+			if err != nil || w <= 0 {
+				return Criterion{}, fmt.Errorf("invalid weight %q in criterion %q: must be positive", words[0], strings.TrimSpace(clause))
+			}
+			c.Weight, words = w, words[1:]
+		}
+	}
+	if len(words) == 2 && (words[0] == "waxing" || words[0] == "waning") {
+		words[0], words[1] = words[1], words[0] // "waxing moon"
+	}
+	if len(words) < 2 {
+		return bad()
+	}
+
+	c.Subject, words = words[0], words[1:]
+	switch {
+	case words[0] == "ruler":
+		c.Subject, words = c.Subject+" ruler", words[1:]
+	case len(words) >= 3 && words[0] == "ruled" && words[1] == "by":
+		c.Subject, words = c.Subject+" ruler", words[2:]
+		if words[0] == "a" {
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && words[0] == "is" {
+		words = words[1:]
+	}
+	if len(words) > 0 && words[0] == "not" {
+		c.Not, words = true, words[1:]
+	}
+	if len(words) > 1 && words[0] == "in" && words[1] == "house" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return bad()
+	}
+
+	switch c.Is, words = words[0], words[1:]; c.Is {
+	case "in":
+		if len(words) != 1 {
+			return bad()
+		}
+		c.Sign = words[0]
+	case "house":
+		if len(words) != 1 {
+			return bad()
+		}
+		h, err := strconv.Atoi(words[0])
+		if err != nil {
+			return bad()
+		}
+		c.Houses = []int{h}
+	case "void":
+		if strings.Join(words, " ") != "of course" {
+			return bad()
+		}
+		c.Is = "voc"
+	default:
+		if len(words) != 0 {
+			return bad()
+		}
+	}
+	return c, nil
+}
+
+// validate checks that each criterion's subject and condition are known
+// and fit together, and fills in default weights.
+func validate(cs []Criterion) error {
+	if len(cs) == 0 {
+		return fmt.Errorf("no criteria given")
+	}
+	for i := range cs {
+		c := &cs[i]
+		if c.Prefer && c.Weight == 0 {
+			c.Weight = 1
+		}
+		if c.Weight < 0 {
+			return fmt.Errorf("criterion %q: weight must be positive", c)
+		}
+		_, isBody := bodies[c.Subject]
+		isRuler := c.Subject == "asc ruler" || c.Subject == "mc ruler"
+		isAngle := c.Subject == "asc" || c.Subject == "mc"
+		if !isBody && !isRuler && !isAngle {
+			return fmt.Errorf("criterion %q: unknown subject %q: valid values are a planet, asc, mc, asc ruler, mc ruler", c, c.Subject)
+		}
+		switch c.Is {
+		case "in":
+			if signIndex(c.Sign) < 0 {
+				return fmt.Errorf("criterion %q: unknown sign %q", c, c.Sign)
+			}
+			continue // the only condition that applies to angles
+		case "house":
+			if len(c.Houses) == 0 {
+				return fmt.Errorf("criterion %q: no houses given", c)
+			}
+			for _, h := range c.Houses {
+				if h < 1 || h > 12 {
+					return fmt.Errorf("criterion %q: invalid house %d: must be 1-12", c, h)
+				}
+			}
+		case "angular", "succedent", "cadent", "direct", "retrograde", "benefic", "malefic":
+		case "combust":
+			if c.Subject == "sun" {
+				return fmt.Errorf("criterion %q: the Sun cannot be combust", c)
+			}
+		case "voc", "waxing", "waning":
+			if c.Subject != "moon" {
+				return fmt.Errorf("criterion %q: %s applies only to the moon", c, c.Is)
+			}
+		default:
+			return fmt.Errorf("criterion %q: unknown condition %q: valid values are %s", c, c.Is, conditions)
+		}
+		if isAngle {
+			return fmt.Errorf("criterion %q: the only condition for %s is in <sign>", c, c.Subject)
+		}
+	}
+	return nil
+}
+
+// String returns the criterion in the criteria language.
+func (c Criterion) String() string {
+	if !c.Prefer {
+		return c.Condition()
+	}
+	if c.Weight != 0 && c.Weight != 1 {
+		return "prefer " + strconv.FormatFloat(c.Weight, 'g', -1, 64) + " " + c.Condition()
+	}
+	return "prefer " + c.Condition()
+}
+
+// Condition returns the criterion in the criteria language without its
+// preference and weight, e.g. "venus in house 1,10".
+func (c Criterion) Condition() string {
+	var b strings.Builder
+	b.WriteString(c.Subject)
+	if c.Not {
+		b.WriteString(" not")
+	}
+	switch c.Is {
+	case "in":
+		b.WriteString(" in " + c.Sign)
+	case "house":
+		hs := make([]string, len(c.Houses))
+		for i, h := range c.Houses {
+			hs[i] = strconv.Itoa(h)
+		}
+		b.WriteString(" in house " + strings.Join(hs, ","))
+	default:
+		b.WriteString(" " + c.Is)
+	}
+	return b.String()
+}
+
+// signIndex returns the index of the named sign, or -1.
+func signIndex(name string) int {
+	for i, s := range zodiac.Signs {
+		if strings.EqualFold(s, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
@@ -0,0 +1,192 @@
+// Package election searches a span of time for moments that meet a set of
+// electional criteria, such as "moon not voc" and "jupiter angular", and
+// ranks the windows it finds.
+package election
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/lunar"
+)
+
+// Sky is the chart of one candidate moment.
+type Sky struct {
+	JD      float64
+	Planets map[int]ephemeris.PlanetPos
+	Houses  ephemeris.HouseResult
+}
+
+// classical are the planets every Sky includes: the criteria need them for
+// rulers and the void of course Moon.
+var classical = []int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mercury, ephemeris.Venus,
+	ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn,
+}
+
+// Holds reports whether the criterion is met in s, ignoring Prefer.
+func (c Criterion) Holds(s Sky) bool {
+	return c.holds(s) != c.Not
+}
+
+func (c Criterion) holds(s Sky) bool {
+	switch c.Subject {
+	case "asc":
+		return dignity.Sign(s.Houses.Ascendant) == signIndex(c.Sign)
+	case "mc":
+		return dignity.Sign(s.Houses.MC) == signIndex(c.Sign)
+	}
+	body := bodies[c.Subject]
+	switch c.Subject {
+	case "asc ruler":
+		body = dignity.RulerOf(s.Houses.Ascendant)
+	case "mc ruler":
+		body = dignity.RulerOf(s.Houses.MC)
+	}
+	pos := s.Planets[body]
+
+	switch c.Is {
+	case "angular", "succedent", "cadent", "house":
+		h := s.Houses.HouseOf(pos.Longitude)
+		switch c.Is {
+		case "angular":
+			return h%3 == 1
+		case "succedent":
+			return h%3 == 2
+		case "cadent":
+			return h%3 == 0
+		}
+		for _, want := range c.Houses {
+			if h == want {
+				return true
+			}
+		}
+		return false
+	case "in":
+		return dignity.Sign(pos.Longitude) == signIndex(c.Sign)
+	case "direct":
+		return pos.SpeedLon >= 0
+	case "retrograde":
+		return pos.SpeedLon < 0
+	case "benefic":
+		return dignity.Benefic(body)
+	case "malefic":
+		return dignity.Malefic(body)
+	case "combust":
+		if body == ephemeris.Sun {
+			return false
+		}
+		return math.Abs(angle.Diff(pos.Longitude, s.Planets[ephemeris.Sun].Longitude)) < CombustOrb
+	case "voc":
+		others := make(map[int]ephemeris.PlanetPos, len(lunar.VoidPlanets))
+		for _, b := range lunar.VoidPlanets {
+			others[b] = s.Planets[b]
+		}
+		return lunar.VoidOfCourse(pos, others)
+	case "waxing":
+		return lunar.Waxing(pos.Longitude, s.Planets[ephemeris.Sun].Longitude)
+	case "waning":
+		return !lunar.Waxing(pos.Longitude, s.Planets[ephemeris.Sun].Longitude)
+	}
+	return false
+}
+
+// Match is a window of consecutive candidate moments that meet every
+// required criterion and the same preferences.
+type Match struct {
+	Start, End float64 // Julian Days of the first and last matching moments
+	Score      float64 // the total weight of Preferred
+	Preferred  []Criterion
+}
+
+// Search samples the sky every step days from from to to, at the given
+// place and house system, and returns the windows in which every required
+// criterion holds. Matches are ranked by score, then by length, then by
+// start; their edges are accurate to within step.
+func Search(p ephemeris.Provider, criteria []Criterion, from, to, step, lat, lon float64, hsys byte) ([]Match, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %v", step)
+	}
+	planets := append([]int(nil), classical...)
+	for _, c := range criteria {
+		if body, ok := bodies[c.Subject]; ok && body > ephemeris.Saturn {
+			planets = append(planets, body)
+		}
+	}
+
+	var matches []Match
+	var cur *Match
+	var curKey string
+	for i := 0; ; i++ {
+		jd := from + float64(i)*step
+		if jd > to {
+			break
+		}
+		s, err := sky(p, jd, planets, lat, lon, hsys)
+		if err != nil {
+			return nil, err
+		}
+		ok, key := true, ""
+		var preferred []Criterion
+		for _, c := range criteria {
+			holds := c.Holds(s)
+			switch {
+			case !c.Prefer && !holds:
+				ok = false
+			case c.Prefer && holds:
+				preferred = append(preferred, c)
+				key += "1"
+			case c.Prefer:
+				key += "0"
+			}
+		}
+		if !ok {
+			cur = nil
+			continue
+		}
+		if cur != nil && key == curKey {
+			cur.End = jd
+			continue
+		}
+		m := Match{Start: jd, End: jd, Preferred: preferred}
+		for _, c := range preferred {
+			m.Score += c.Weight
+		}
+		matches = append(matches, m)
+		cur, curKey = &matches[len(matches)-1], key
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if la, lb := a.End-a.Start, b.End-b.Start; la != lb {
+			return la > lb
+		}
+		return a.Start < b.Start
+	})
+	return matches, nil
+}
+
+// sky computes the chart of one candidate moment.
+func sky(p ephemeris.Provider, jd float64, planets []int, lat, lon float64, hsys byte) (Sky, error) {
+	s := Sky{JD: jd, Planets: make(map[int]ephemeris.PlanetPos, len(planets))}
+	for _, body := range planets {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return Sky{}, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		s.Planets[body] = pos
+	}
+	h, err := p.CalcHouses(jd, lat, lon, hsys)
+	if err != nil {
+		return Sky{}, fmt.Errorf("error calculating houses: %w", err)
+	}
+	s.Houses = h
+	return s, nil
+}
@@ -0,0 +1,113 @@
+package election_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dcccxiii/astro/election"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+func TestParse(t *testing.T) {
+	got, err := election.Parse(`
+		Moon not VOC, Jupiter angular, waxing Moon  # the usual
+		ASC ruled by a benefic; prefer 2 venus in house 1,10
+		mars is not in Scorpio`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"moon not voc",
+		"jupiter angular",
+		"moon waxing",
+		"asc ruler benefic",
+		"prefer 2 venus in house 1,10",
+		"mars not in scorpio",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d criteria %v, want %d", len(got), got, len(want))
+	}
+	for i, c := range got {
+		if c.String() != want[i] {
+			t.Errorf("criterion %d = %q, want %q", i, c, want[i])
+		}
+	}
+	if !got[4].Prefer || got[4].Weight != 2 || got[0].Prefer {
+		t.Errorf("preferences parsed wrongly: %+v, %+v", got[0], got[4])
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"pluto voc",
+		"asc angular",
+		"venus in house 13",
+		"moon in lemuria",
+		"chiron angular",
+		"jupiter lucky",
+		"prefer -1 venus angular",
+	} {
+		if _, err := election.Parse(s); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	got, err := election.ParseJSON([]byte(`[
+		{"subject": "Moon", "is": "voc", "not": true},
+		{"subject": "venus", "is": "house", "houses": [1, 10], "prefer": true}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].String() != "moon not voc" || got[1].Weight != 1 {
+		t.Errorf("ParseJSON = %+v", got)
+	}
+	if _, err := election.ParseJSON([]byte(`[{"subject": "moon", "is": "angular", "houses": [`)); err == nil ||
+		!strings.Contains(err.Error(), "JSON") {
+		t.Errorf("ParseJSON of bad JSON: err = %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	// The Sun still at 0° and the Moon leaving it at 12°/day: waxing for
+	// the first 15 days, in Aries for the first 2.5.
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:     {Longitude: 0},
+		ephemeris.Moon:    {Longitude: 0, SpeedLon: 12},
+		ephemeris.Mercury: {Longitude: 10},
+		ephemeris.Venus:   {Longitude: 100},
+		ephemeris.Mars:    {Longitude: 200},
+		ephemeris.Jupiter: {Longitude: 250},
+		ephemeris.Saturn:  {Longitude: 330},
+	}}
+	for i := 1; i <= 12; i++ {
+		p.Houses.Cusps[i] = float64(i-1) * 30
+	}
+	criteria, err := election.Parse("waxing moon, venus angular, prefer moon in aries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := election.Search(p, criteria, 0, 20, 0.5, 0, 0, 'E')
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []election.Match{
+		{Start: 0.5, End: 2, Score: 1},
+		{Start: 2.5, End: 14.5},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("got %d matches %+v, want %d", len(matches), matches, len(want))
+	}
+	for i, m := range matches {
+		if m.Start != want[i].Start || m.End != want[i].End || m.Score != want[i].Score {
+			t.Errorf("match %d = %v-%v score %v, want %v-%v score %v",
+				i, m.Start, m.End, m.Score, want[i].Start, want[i].End, want[i].Score)
+		}
+	}
+	if len(matches[0].Preferred) != 1 || matches[0].Preferred[0].Sign != "aries" {
+		t.Errorf("best match preferences %v, want moon in aries", matches[0].Preferred)
+	}
+}
@@ -0,0 +1,89 @@
+package ephemeris
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Body identifiers, numbered as in the Swiss Ephemeris so they can be passed
+// to any Provider interchangeably with the swisseph constants.
+const (
+	Sun     = 0
+	Moon    = 1
+	Mercury = 2
+	Venus   = 3
+	Mars    = 4
+	Jupiter = 5
+	Saturn  = 6
+	Uranus  = 7
+	Neptune = 8
+	Pluto   = 9
+	Earth   = 14
+
+	MeanNode = 10
+	TrueNode = 11
+	// MeanApogee is the mean lunar apogee, the Black Moon Lilith.
+	MeanApogee = 12
+
+	Chiron = 15
+	Pholus = 16
+	Ceres  = 17
+	Pallas = 18
+	Juno   = 19
+	Vesta  = 20
+
+	// The hypothetical planets of the Hamburg school, the Uranian planets,
+	// and Transpluto (Isis).
+	Cupido     = 40
+	Hades      = 41
+	Zeus       = 42
+	Kronos     = 43
+	Apollon    = 44
+	Admetos    = 45
+	Vulkanus   = 46
+	Poseidon   = 47
+	Transpluto = 48
+
+	// AstOffset plus its number identifies a numbered asteroid, e.g.
+	// AstOffset+433 for Eros.
+	AstOffset = 10000
+)
+
+var bodyNames = map[int]string{
+	0: "Sun", 1: "Moon", 2: "Mercury", 3: "Venus", 4: "Mars",
+	5: "Jupiter", 6: "Saturn", 7: "Uranus", 8: "Neptune", 9: "Pluto",
+	10: "mean Node", 11: "true Node", 12: "mean Apogee", 13: "osc. Apogee",
+	14: "Earth", 15: "Chiron", 16: "Pholus", 17: "Ceres", 18: "Pallas",
+	19: "Juno", 20: "Vesta", 21: "intp. Apogee", 22: "intp. Perigee",
+	40: "Cupido", 41: "Hades", 42: "Zeus", 43: "Kronos", 44: "Apollon",
+	45: "Admetos", 46: "Vulkanus", 47: "Poseidon", 48: "Isis-Transpluto",
+}
+
+// BodyName returns the Swiss Ephemeris display name for a body ID, the
+// number in parentheses for a numbered asteroid, as in (433), or the
+// number itself for other bodies it does not know.
+func BodyName(body int) string {
+	if name, ok := bodyNames[body]; ok {
+		return name
+	}
+	if body > AstOffset {
+		return "(" + strconv.Itoa(body-AstOffset) + ")"
+	}
+	return strconv.Itoa(body)
+}
+
+// BodyByName returns the ID of the body BodyName calls name, including
+// numbered asteroids such as (433). ok is false for names it does not use.
+func BodyByName(name string) (id int, ok bool) {
+	for id, n := range bodyNames {
+		if n == name {
+			return id, true
+		}
+	}
+	if s, found := strings.CutPrefix(name, "("); found && strings.HasSuffix(s, ")") {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, ")")); err == nil && n > 0 {
+			return AstOffset + n, true
+		}
+	}
+	return 0, false
+}
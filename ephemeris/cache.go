@@ -0,0 +1,249 @@
+package ephemeris
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+type planetKey struct {
+	jd   float64
+	body int
+}
+
+type housesKey struct {
+	jd, lat, lon float64
+	hsys         byte
+}
+
+// CacheStats counts the requests a CachedProvider answered from its cache
+// (Hits) and passed on (Misses), and the results it dropped to stay within
+// its size (Evictions). A batch of bodies counts a hit or miss per body.
+type CacheStats struct {
+	Hits      int `json:"hits"`
+	Misses    int `json:"misses"`
+	Evictions int `json:"evictions"`
+}
+
+// CachedProvider memoises the results of another Provider. Errors are not
+// cached. The wrapped provider's flags are fixed, so a result is keyed by
+// the Julian Day and body, or the Julian Day, place and house system.
+//
+// A cache made with NewCachedProvider is unbounded, which suits short-lived
+// workloads that revisit the same instants, such as a single search; one
+// made with NewLRUProvider keeps a fixed number of results, dropping the
+// least recently used, for long runs. RoundHouses lets nearby house
+// requests share a result.
+type CachedProvider struct {
+	p Provider
+
+	// Steps to round house requests to, in days and degrees; 0 is exact.
+	roundJD, roundPlace float64
+
+	mu     sync.Mutex
+	planet *lru[planetKey, PlanetPos]
+	houses *lru[housesKey, HouseResult]
+	stats  CacheStats
+}
+
+// NewCachedProvider returns an unbounded CachedProvider in front of p.
+func NewCachedProvider(p Provider) *CachedProvider {
+	return NewLRUProvider(p, 0)
+}
+
+// NewLRUProvider returns a CachedProvider in front of p that keeps at most
+// size planet positions and size house results, or all of them if size is
+// 0.
+func NewLRUProvider(p Provider, size int) *CachedProvider {
+	return &CachedProvider{
+		p:      p,
+		planet: newLRU[planetKey, PlanetPos](size),
+		houses: newLRU[housesKey, HouseResult](size),
+	}
+}
+
+// RoundHouses makes c round the time of each house request to a multiple
+// of step, and its latitude and longitude to multiples of deg degrees, and
+// compute the houses there, so that requests a moment or a few metres
+// apart, as from a redrawn chart, share a result. A step or deg of 0 keeps
+// that part exact. The houses move about a degree in four minutes, so a
+// step of a second moves the angles by well under a minute of arc. Call it
+// before c is used; it returns c.
+func (c *CachedProvider) RoundHouses(step time.Duration, deg float64) *CachedProvider {
+	c.roundJD = step.Hours() / 24
+	c.roundPlace = deg
+	return c
+}
+
+// Stats returns the cache's counts so far.
+func (c *CachedProvider) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// CalcPlanet implements Provider.
+func (c *CachedProvider) CalcPlanet(jd float64, body int) (PlanetPos, error) {
+	k := planetKey{jd, body}
+	c.mu.Lock()
+	pos, ok := c.planet.get(k)
+	c.count(ok, 1)
+	c.mu.Unlock()
+	if ok {
+		return pos, nil
+	}
+
+	pos, err := c.p.CalcPlanet(jd, body)
+	if err != nil {
+		return PlanetPos{}, err
+	}
+	c.mu.Lock()
+	c.stats.Evictions += c.planet.put(k, pos)
+	c.mu.Unlock()
+	return pos, nil
+}
+
+// CalcPlanets implements BatchProvider. The bodies not in the cache are
+// computed together, in one call if the wrapped provider is a
+// BatchProvider.
+func (c *CachedProvider) CalcPlanets(jd float64, bodies []int) ([]PlanetPos, error) {
+	pos := make([]PlanetPos, len(bodies))
+	var missing []int
+	var at []int // indexes of missing in bodies
+	c.mu.Lock()
+	for i, body := range bodies {
+		var ok bool
+		if pos[i], ok = c.planet.get(planetKey{jd, body}); !ok {
+			missing = append(missing, body)
+			at = append(at, i)
+		}
+	}
+	c.count(true, len(bodies)-len(missing))
+	c.count(false, len(missing))
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return pos, nil
+	}
+
+	got, err := CalcPlanets(c.p, jd, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	for j, body := range missing {
+		pos[at[j]] = got[j]
+		c.stats.Evictions += c.planet.put(planetKey{jd, body}, got[j])
+	}
+	c.mu.Unlock()
+	return pos, nil
+}
+
+// CalcHouses implements Provider, rounding the request as RoundHouses set.
+func (c *CachedProvider) CalcHouses(jd, lat, lon float64, hsys byte) (HouseResult, error) {
+	jd, lat, lon = roundTo(jd, c.roundJD), roundTo(lat, c.roundPlace), roundTo(lon, c.roundPlace)
+	k := housesKey{jd, lat, lon, hsys}
+	c.mu.Lock()
+	h, ok := c.houses.get(k)
+	c.count(ok, 1)
+	c.mu.Unlock()
+	if ok {
+		return h, nil
+	}
+
+	h, err := c.p.CalcHouses(jd, lat, lon, hsys)
+	if err != nil {
+		return HouseResult{}, err
+	}
+	c.mu.Lock()
+	c.stats.Evictions += c.houses.put(k, h)
+	c.mu.Unlock()
+	return h, nil
+}
+
+// Crossing implements CrossingProvider, uncached, reporting ok false when
+// the wrapped provider is not one.
+func (c *CachedProvider) Crossing(jd float64, body int, lon float64) (float64, bool, error) {
+	cp, ok := c.p.(CrossingProvider)
+	if !ok {
+		return 0, false, nil
+	}
+	return cp.Crossing(jd, body, lon)
+}
+
+// Source implements SourceProvider, uncached, reporting ok false when the
+// wrapped provider cannot tell.
+func (c *CachedProvider) Source(jd float64, body int) (Source, bool, error) {
+	sp, ok := c.p.(SourceProvider)
+	if !ok {
+		return Source{}, false, nil
+	}
+	return sp.Source(jd, body)
+}
+
+// PlanetName implements Provider.
+func (c *CachedProvider) PlanetName(body int) string {
+	return c.p.PlanetName(body)
+}
+
+// roundTo returns x rounded to a multiple of step, or x if step is 0.
+func roundTo(x, step float64) float64 {
+	if step <= 0 {
+		return x
+	}
+	return math.Round(x/step) * step
+}
+
+// count adds n requests to the hits or the misses. c.mu must be held.
+func (c *CachedProvider) count(hit bool, n int) {
+	if hit {
+		c.stats.Hits += n
+	} else {
+		c.stats.Misses += n
+	}
+}
+
+// lru is a map that keeps at most size entries, or any number if size is
+// 0, dropping the least recently used. It is not safe for concurrent use.
+type lru[K comparable, V any] struct {
+	size  int
+	order *list.List // of *entry[K, V], most recently used first
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{size: size, order: list.New(), items: make(map[K]*list.Element)}
+}
+
+// get returns the value of k and marks it used.
+func (l *lru[K, V]) get(k K) (V, bool) {
+	e, ok := l.items[k]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// put stores v under k and returns the number of entries it evicted.
+func (l *lru[K, V]) put(k K, v V) int {
+	if e, ok := l.items[k]; ok {
+		e.Value.(*entry[K, V]).val = v
+		l.order.MoveToFront(e)
+		return 0
+	}
+	l.items[k] = l.order.PushFront(&entry[K, V]{k, v})
+	if l.size == 0 || l.order.Len() <= l.size {
+		return 0
+	}
+	last := l.order.Back()
+	l.order.Remove(last)
+	delete(l.items, last.Value.(*entry[K, V]).key)
+	return 1
+}
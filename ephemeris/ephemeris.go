@@ -0,0 +1,122 @@
+// Package ephemeris defines the Provider interface through which chart code
+// obtains planetary positions and house cusps, together with providers that
+// need no C library: CachedProvider, which memoises another provider, and
+// MockProvider, which serves deterministic fake data for tests.
+//
+// The Swiss Ephemeris–backed providers live in the ephemeris/swiss
+// sub-package so that this package, and everything built only on top of it,
+// compiles without cgo.
+package ephemeris
+
+import "github.com/dcccxiii/astro/angle"
+
+// PlanetPos holds the result of a planetary position calculation.
+type PlanetPos struct {
+	Longitude     float64 // ecliptic longitude in degrees (0-360)
+	Latitude      float64 // ecliptic latitude in degrees
+	Distance      float64 // distance from Earth in AU
+	SpeedLon      float64 // daily speed in longitude (degrees/day)
+	SpeedLat      float64 // daily speed in latitude (degrees/day)
+	SpeedDistance float64 // daily speed in distance (AU/day)
+	// Warning is the ephemeris's message about a position it computed all
+	// the same, such as a fallback to a less precise ephemeris; empty if
+	// none.
+	Warning string
+}
+
+// HouseResult holds the result of a house calculation.
+type HouseResult struct {
+	Cusps     [13]float64 // house cusps in degrees; index 1-12 are houses 1-12 (index 0 is unused)
+	Ascendant float64     // Ascendant in degrees
+	MC        float64     // Midheaven (Medium Coeli) in degrees
+	ARMC      float64     // sidereal time in degrees
+	Vertex    float64     // Vertex in degrees
+	// EastPoint is the equatorial Ascendant, the degree rising at the
+	// equator.
+	EastPoint float64
+	// CoAscendant is Walter Koch's co-Ascendant and PolarAscendant the
+	// Ascendant of the polar house circle, Michael Munkasey's.
+	CoAscendant    float64
+	PolarAscendant float64
+}
+
+// HouseOf returns the house, 1-12, containing ecliptic longitude lon: the
+// house whose cusp lon is at or past, before the next cusp.
+func (h HouseResult) HouseOf(lon float64) int {
+	for i := 1; i <= 12; i++ {
+		next := h.Cusps[i%12+1]
+		if angle.Norm360(lon-h.Cusps[i]) < angle.Norm360(next-h.Cusps[i]) {
+			return i
+		}
+	}
+	return 12 // unreachable unless the cusps are degenerate
+}
+
+// Provider computes planetary positions and houses. Implementations must be
+// safe for concurrent use.
+type Provider interface {
+	// CalcPlanet returns the position of body at Julian Day jd (UT).
+	CalcPlanet(jd float64, body int) (PlanetPos, error)
+	// CalcHouses returns house cusps and angles for a time and location.
+	// hsys is a Swiss Ephemeris house system code such as 'P'.
+	CalcHouses(jd, lat, lon float64, hsys byte) (HouseResult, error)
+	// PlanetName returns the display name for body.
+	PlanetName(body int) string
+}
+
+// BatchProvider is a Provider that can compute several bodies at the same
+// time in one call, which is cheaper than a call per body when the cost is
+// mostly in reaching the ephemeris.
+type BatchProvider interface {
+	Provider
+	// CalcPlanets returns the positions of bodies at jd, in their order.
+	CalcPlanets(jd float64, bodies []int) ([]PlanetPos, error)
+}
+
+// CrossingProvider is a Provider that can find directly when the Sun or the
+// Moon next reaches a longitude, rather than by sampling their positions.
+type CrossingProvider interface {
+	Provider
+	// Crossing returns the first Julian Day (UT) after jd when body
+	// reaches longitude lon. ok is false when body is neither the Sun nor
+	// the Moon, or the provider cannot search for it.
+	Crossing(jd float64, body int, lon float64) (t float64, ok bool, err error)
+}
+
+// Source says which ephemeris computed a body's position.
+type Source struct {
+	// Ephemeris is "swiss", "moshier" or "jpl": the ephemeris that served
+	// the position. Fallback is set when it is not the one asked for, as
+	// when the Swiss Ephemeris files are missing and the library computes
+	// with the less precise Moshier ephemeris instead.
+	Ephemeris string
+	Fallback  bool
+	// File is the ephemeris file read, if any, and Start and End the
+	// Julian Days it covers.
+	File       string
+	Start, End float64
+}
+
+// SourceProvider is a Provider that can say which ephemeris serves a body.
+type SourceProvider interface {
+	Provider
+	// Source returns the source of body's position at jd. ok is false
+	// when the provider cannot tell.
+	Source(jd float64, body int) (s Source, ok bool, err error)
+}
+
+// CalcPlanets returns the positions of bodies at jd, in their order: in one
+// call if p is a BatchProvider, else with a CalcPlanet call per body.
+func CalcPlanets(p Provider, jd float64, bodies []int) ([]PlanetPos, error) {
+	if b, ok := p.(BatchProvider); ok {
+		return b.CalcPlanets(jd, bodies)
+	}
+	pos := make([]PlanetPos, len(bodies))
+	for i, body := range bodies {
+		var err error
+		if pos[i], err = p.CalcPlanet(jd, body); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
@@ -0,0 +1,282 @@
+package ephemeris_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// countingProvider counts the calls that reach it.
+type countingProvider struct {
+	ephemeris.MockProvider
+	planetCalls, houseCalls int
+	lastHouses              [3]float64 // the jd, lat and lon of the last houses
+}
+
+func (c *countingProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	c.planetCalls++
+	return c.MockProvider.CalcPlanet(jd, body)
+}
+
+func (c *countingProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	c.houseCalls++
+	c.lastHouses = [3]float64{jd, lat, lon}
+	return c.MockProvider.CalcHouses(jd, lat, lon, hsys)
+}
+
+func TestMockProvider_LinearMotion(t *testing.T) {
+	m := &ephemeris.MockProvider{
+		Epoch: 2451545.0,
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 359.5, SpeedLon: 1.0},
+			ephemeris.Mars: {Longitude: 0.5, SpeedLon: -0.5},
+		},
+	}
+
+	cases := []struct {
+		name string
+		body int
+		jd   float64
+		want float64
+	}{
+		{"at epoch", ephemeris.Sun, 2451545.0, 359.5},
+		{"wraps forward", ephemeris.Sun, 2451546.0, 0.5},
+		{"wraps backward", ephemeris.Mars, 2451547.0, 359.5},
+		{"before epoch", ephemeris.Sun, 2451544.0, 358.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pos, err := m.CalcPlanet(tc.jd, tc.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(pos.Longitude-tc.want) > 1e-9 {
+				t.Errorf("longitude = %v, want %v", pos.Longitude, tc.want)
+			}
+		})
+	}
+
+	if _, err := m.CalcPlanet(2451545.0, ephemeris.Moon); err == nil {
+		t.Error("expected error for body without data")
+	}
+}
+
+func TestMockProvider_Err(t *testing.T) {
+	want := errors.New("boom")
+	m := &ephemeris.MockProvider{Err: want}
+	if _, err := m.CalcPlanet(0, ephemeris.Sun); !errors.Is(err, want) {
+		t.Errorf("CalcPlanet error = %v, want %v", err, want)
+	}
+	if _, err := m.CalcHouses(0, 0, 0, 'P'); !errors.Is(err, want) {
+		t.Errorf("CalcHouses error = %v, want %v", err, want)
+	}
+}
+
+func TestCachedProvider(t *testing.T) {
+	inner := &countingProvider{MockProvider: ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 10}},
+	}}
+	c := ephemeris.NewCachedProvider(inner)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.CalcPlanet(100, ephemeris.Sun); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.CalcHouses(100, 51.5, -0.1, 'P'); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.CalcPlanet(101, ephemeris.Sun); err != nil {
+		t.Fatal(err)
+	}
+	if inner.planetCalls != 2 {
+		t.Errorf("planet calls = %d, want 2", inner.planetCalls)
+	}
+	if inner.houseCalls != 1 {
+		t.Errorf("house calls = %d, want 1", inner.houseCalls)
+	}
+
+	// Errors are passed through and not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := c.CalcPlanet(100, ephemeris.Moon); err == nil {
+			t.Error("expected error for body without data")
+		}
+	}
+	if inner.planetCalls != 4 {
+		t.Errorf("planet calls after errors = %d, want 4", inner.planetCalls)
+	}
+	if got, want := c.Stats(), (ephemeris.CacheStats{Hits: 4, Misses: 5}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestLRUProvider(t *testing.T) {
+	inner := &countingProvider{MockProvider: ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 10, SpeedLon: 1},
+			ephemeris.Moon: {Longitude: 20, SpeedLon: 13},
+		},
+	}}
+	c := ephemeris.NewLRUProvider(inner, 2)
+	for _, jd := range []float64{100, 101, 100, 102, 101} {
+		if _, err := c.CalcPlanet(jd, ephemeris.Sun); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 100 and 101 fill the cache; 100 is a hit, 102 drops 101, and 101
+	// is computed again, dropping 100.
+	if inner.planetCalls != 4 {
+		t.Errorf("planet calls = %d, want 4", inner.planetCalls)
+	}
+	if got, want := c.Stats(), (ephemeris.CacheStats{Hits: 1, Misses: 4, Evictions: 2}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+
+	// A batch computes only the bodies it misses, and keeps their order.
+	pos, err := c.CalcPlanets(102, []int{ephemeris.Moon, ephemeris.Sun})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos[0].Longitude != 266 || pos[1].Longitude != 112 {
+		t.Errorf("CalcPlanets = %+v, want the Moon at 266 and the Sun at 112", pos)
+	}
+	if inner.planetCalls != 5 {
+		t.Errorf("planet calls after batch = %d, want 5", inner.planetCalls)
+	}
+	if got := c.Stats(); got.Hits != 2 || got.Misses != 5 {
+		t.Errorf("Stats after batch = %+v, want 2 hits and 5 misses", got)
+	}
+}
+
+func TestRoundHouses(t *testing.T) {
+	inner := &countingProvider{}
+	c := ephemeris.NewCachedProvider(inner).RoundHouses(time.Minute, 0.01)
+	const minute = 1.0 / 1440
+	base := 2460000.5 + 10*minute
+	// Moments within half a minute, and places within half the step, of
+	// the first share its houses, computed at the rounded request.
+	for _, req := range [][3]float64{
+		{base, 51.5012, -0.1249},
+		{base + 0.4*minute, 51.5012, -0.1249},
+		{base - 0.3*minute, 51.5049, -0.1201},
+	} {
+		if _, err := c.CalcHouses(req[0], req[1], req[2], 'P'); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inner.houseCalls != 1 {
+		t.Errorf("house calls = %d, want 1", inner.houseCalls)
+	}
+	got := inner.lastHouses
+	if math.Abs(got[0]-base) > 1e-9 || math.Abs(got[1]-51.50) > 1e-9 || math.Abs(got[2]+0.12) > 1e-9 {
+		t.Errorf("houses computed at %v, want the request rounded to %v, 51.50, -0.12", got, base)
+	}
+	// The next minute is another request.
+	if _, err := c.CalcHouses(base+0.6*minute, 51.5012, -0.1249, 'P'); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Stats(); got.Hits != 2 || got.Misses != 2 {
+		t.Errorf("Stats = %+v, want 2 hits and 2 misses", got)
+	}
+}
+
+// batchProvider answers CalcPlanets itself and counts the batches.
+type batchProvider struct {
+	countingProvider
+	batches int
+}
+
+func (b *batchProvider) CalcPlanets(jd float64, bodies []int) ([]ephemeris.PlanetPos, error) {
+	b.batches++
+	return make([]ephemeris.PlanetPos, len(bodies)), nil
+}
+
+func TestCalcPlanets(t *testing.T) {
+	inner := &countingProvider{MockProvider: ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 10},
+			ephemeris.Mars: {Longitude: 20},
+		},
+	}}
+	pos, err := ephemeris.CalcPlanets(inner, 0, []int{ephemeris.Mars, ephemeris.Sun})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pos) != 2 || pos[0].Longitude != 20 || pos[1].Longitude != 10 {
+		t.Errorf("CalcPlanets = %+v, want Mars at 20° then the Sun at 10°", pos)
+	}
+	if inner.planetCalls != 2 {
+		t.Errorf("planet calls = %d, want 2", inner.planetCalls)
+	}
+	if _, err := ephemeris.CalcPlanets(inner, 0, []int{ephemeris.Moon}); err == nil {
+		t.Error("expected error for body without data")
+	}
+
+	b := &batchProvider{}
+	if _, err := ephemeris.CalcPlanets(b, 0, []int{ephemeris.Mars, ephemeris.Sun}); err != nil {
+		t.Fatal(err)
+	}
+	if b.batches != 1 || b.planetCalls != 0 {
+		t.Errorf("batches = %d, planet calls = %d, want 1 and 0", b.batches, b.planetCalls)
+	}
+}
+
+func TestBodyName(t *testing.T) {
+	if got := ephemeris.BodyName(ephemeris.Saturn); got != "Saturn" {
+		t.Errorf("BodyName(Saturn) = %q", got)
+	}
+	if got := ephemeris.BodyName(9999); got != "9999" {
+		t.Errorf("BodyName(9999) = %q", got)
+	}
+	if got := ephemeris.BodyName(ephemeris.AstOffset + 433); got != "(433)" {
+		t.Errorf("BodyName(AstOffset+433) = %q, want (433)", got)
+	}
+	if id, ok := ephemeris.BodyByName("(433)"); !ok || id != ephemeris.AstOffset+433 {
+		t.Errorf("BodyByName((433)) = %d, %v", id, ok)
+	}
+	if id, ok := ephemeris.BodyByName("Chiron"); !ok || id != ephemeris.Chiron {
+		t.Errorf("BodyByName(Chiron) = %d, %v", id, ok)
+	}
+}
+
+func TestJulianDay(t *testing.T) {
+	cases := []struct {
+		t  time.Time
+		jd float64
+	}{
+		{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 2451545.0},
+		{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 2440587.5},
+		{time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), 2305447.5},
+		{time.Date(2024, 3, 20, 13, 0, 0, 0, time.FixedZone("CET", 3600)), 2460390.0},
+	}
+	for _, tc := range cases {
+		if got := ephemeris.JulianDay(tc.t); math.Abs(got-tc.jd) > 1e-9 {
+			t.Errorf("JulianDay(%v) = %.9f, want %.9f", tc.t, got, tc.jd)
+		}
+		if got := ephemeris.TimeOf(tc.jd); !got.Equal(tc.t) {
+			t.Errorf("TimeOf(%.1f) = %v, want %v", tc.jd, got, tc.t)
+		}
+	}
+}
+
+func TestHouseOf(t *testing.T) {
+	var h ephemeris.HouseResult
+	// Equal houses from an Ascendant at 350°, so that house 1 spans 0°.
+	for i := 1; i <= 12; i++ {
+		h.Cusps[i] = math.Mod(350+float64(i-1)*30, 360)
+	}
+	tests := []struct {
+		lon  float64
+		want int
+	}{
+		{350, 1}, {359.9, 1}, {5, 1}, {19.99, 1}, {20, 2}, {170, 7}, {349.9, 12},
+	}
+	for _, tt := range tests {
+		if got := h.HouseOf(tt.lon); got != tt.want {
+			t.Errorf("HouseOf(%v) = %d, want %d", tt.lon, got, tt.want)
+		}
+	}
+}
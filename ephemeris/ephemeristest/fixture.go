@@ -0,0 +1,186 @@
+// Package ephemeristest provides utilities for testing code that consumes an
+// ephemeris.Provider, in the spirit of net/http/httptest.
+//
+// A Recorder wraps a real provider and captures every answer it gives; the
+// captured Fixture can be saved as JSON and later replayed by a
+// FixtureProvider, so tests run without ephemeris files or cgo:
+//
+//	rec := ephemeristest.NewRecorder(swiss.Provider{})
+//	chart, _ := output.Build(rec, jd, planets, lat, lon, 'P', "Placidus")
+//	rec.Fixture().WriteFile("testdata/chart.json")
+//
+//	// later, in a test:
+//	p, _ := ephemeristest.LoadFixture("testdata/chart.json")
+//	chart, _ := output.Build(p, jd, planets, lat, lon, 'P', "Placidus")
+package ephemeristest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// jdTolerance is how close a requested Julian Day must be to a recorded one
+// to match (about 1 ms), absorbing rounding in callers' time arithmetic.
+const jdTolerance = 1e-8
+
+// PlanetRecord is one recorded CalcPlanet answer.
+type PlanetRecord struct {
+	JD       float64             `json:"jd"`
+	Body     int                 `json:"body"`
+	Name     string              `json:"name,omitempty"`
+	Position ephemeris.PlanetPos `json:"position"`
+}
+
+// HouseRecord is one recorded CalcHouses answer.
+type HouseRecord struct {
+	JD     float64               `json:"jd"`
+	Lat    float64               `json:"lat"`
+	Lon    float64               `json:"lon"`
+	System string                `json:"system"` // single-character house system code
+	Houses ephemeris.HouseResult `json:"houses"`
+}
+
+// Fixture is a set of recorded provider answers.
+type Fixture struct {
+	Planets []PlanetRecord `json:"planets"`
+	Houses  []HouseRecord  `json:"houses"`
+}
+
+// WriteFile saves f as indented JSON.
+func (f Fixture) WriteFile(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling fixture: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// FixtureProvider is an ephemeris.Provider that replays a Fixture. Requests
+// that were not recorded fail with an error naming the missing entry.
+type FixtureProvider struct {
+	f Fixture
+}
+
+// NewFixtureProvider returns a provider replaying f.
+func NewFixtureProvider(f Fixture) *FixtureProvider {
+	return &FixtureProvider{f: f}
+}
+
+// LoadFixture reads a fixture written by Fixture.WriteFile.
+func LoadFixture(path string) (*FixtureProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error parsing fixture %s: %w", path, err)
+	}
+	return NewFixtureProvider(f), nil
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (p *FixtureProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	for _, r := range p.f.Planets {
+		if r.Body == body && math.Abs(r.JD-jd) <= jdTolerance {
+			return r.Position, nil
+		}
+	}
+	return ephemeris.PlanetPos{}, fmt.Errorf("fixture: no position recorded for %s at JD %.6f", p.PlanetName(body), jd)
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (p *FixtureProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	for _, r := range p.f.Houses {
+		if r.System == string(hsys) && r.Lat == lat && r.Lon == lon && math.Abs(r.JD-jd) <= jdTolerance {
+			return r.Houses, nil
+		}
+	}
+	return ephemeris.HouseResult{}, fmt.Errorf("fixture: no %c houses recorded for (%.4f, %.4f) at JD %.6f", hsys, lat, lon, jd)
+}
+
+// PlanetName implements ephemeris.Provider. Names captured by a Recorder
+// take precedence over ephemeris.BodyName.
+func (p *FixtureProvider) PlanetName(body int) string {
+	for _, r := range p.f.Planets {
+		if r.Body == body && r.Name != "" {
+			return r.Name
+		}
+	}
+	return ephemeris.BodyName(body)
+}
+
+// Recorder is an ephemeris.Provider that forwards to another provider and
+// remembers every successful answer.
+type Recorder struct {
+	p ephemeris.Provider
+
+	mu      sync.Mutex
+	planets []PlanetRecord
+	houses  []HouseRecord
+}
+
+// NewRecorder returns a Recorder in front of p.
+func NewRecorder(p ephemeris.Provider) *Recorder {
+	return &Recorder{p: p}
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (r *Recorder) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	pos, err := r.p.CalcPlanet(jd, body)
+	if err != nil {
+		return pos, err
+	}
+	rec := PlanetRecord{JD: jd, Body: body, Name: r.p.PlanetName(body), Position: pos}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.planets {
+		if existing == rec {
+			return pos, nil
+		}
+	}
+	r.planets = append(r.planets, rec)
+	return pos, nil
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (r *Recorder) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	h, err := r.p.CalcHouses(jd, lat, lon, hsys)
+	if err != nil {
+		return h, err
+	}
+	rec := HouseRecord{JD: jd, Lat: lat, Lon: lon, System: string(hsys), Houses: h}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.houses {
+		if existing == rec {
+			return h, nil
+		}
+	}
+	r.houses = append(r.houses, rec)
+	return h, nil
+}
+
+// PlanetName implements ephemeris.Provider.
+func (r *Recorder) PlanetName(body int) string {
+	return r.p.PlanetName(body)
+}
+
+// Fixture returns everything recorded so far, ordered by time.
+func (r *Recorder) Fixture() Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := Fixture{
+		Planets: append([]PlanetRecord(nil), r.planets...),
+		Houses:  append([]HouseRecord(nil), r.houses...),
+	}
+	sort.SliceStable(f.Planets, func(i, j int) bool { return f.Planets[i].JD < f.Planets[j].JD })
+	sort.SliceStable(f.Houses, func(i, j int) bool { return f.Houses[i].JD < f.Houses[j].JD })
+	return f
+}
@@ -0,0 +1,88 @@
+package ephemeristest_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/ephemeris/ephemeristest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var houses ephemeris.HouseResult
+	houses.Ascendant, houses.MC = 123.4, 33.3
+	src := &ephemeris.MockProvider{
+		Epoch: 2451545.0,
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 280.46, SpeedLon: 1.019},
+			ephemeris.Moon: {Longitude: 223.32, SpeedLon: 13.2},
+		},
+		Houses: houses,
+	}
+
+	rec := ephemeristest.NewRecorder(src)
+	jds := []float64{2451545.0, 2451545.25, 2451546.0}
+	for _, jd := range jds {
+		for _, body := range []int{ephemeris.Sun, ephemeris.Moon} {
+			if _, err := rec.CalcPlanet(jd, body); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Repeated calls are recorded once.
+	if _, err := rec.CalcPlanet(jds[0], ephemeris.Sun); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.CalcHouses(jds[0], 51.5, -0.1, 'P'); err != nil {
+		t.Fatal(err)
+	}
+
+	f := rec.Fixture()
+	if len(f.Planets) != 6 || len(f.Houses) != 1 {
+		t.Fatalf("recorded %d planets, %d houses; want 6, 1", len(f.Planets), len(f.Houses))
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := f.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ephemeristest.LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, jd := range jds {
+		for _, body := range []int{ephemeris.Sun, ephemeris.Moon} {
+			want, _ := src.CalcPlanet(jd, body)
+			got, err := replay.CalcPlanet(jd+1e-10, body)
+			if err != nil {
+				t.Fatalf("replay CalcPlanet(%v, %d): %v", jd, body, err)
+			}
+			if got != want {
+				t.Errorf("replay CalcPlanet(%v, %d) = %+v, want %+v", jd, body, got, want)
+			}
+		}
+	}
+	h, err := replay.CalcHouses(jds[0], 51.5, -0.1, 'P')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != houses {
+		t.Errorf("replay CalcHouses = %+v, want %+v", h, houses)
+	}
+	if got := replay.PlanetName(ephemeris.Moon); got != "Moon" {
+		t.Errorf("PlanetName(Moon) = %q", got)
+	}
+}
+
+func TestFixtureProvider_Missing(t *testing.T) {
+	p := ephemeristest.NewFixtureProvider(ephemeristest.Fixture{})
+	_, err := p.CalcPlanet(2451545.0, ephemeris.Mars)
+	if err == nil || !strings.Contains(err.Error(), "Mars") {
+		t.Errorf("CalcPlanet error = %v, want one naming Mars", err)
+	}
+	if _, err := p.CalcHouses(2451545.0, 0, 0, 'K'); err == nil {
+		t.Error("expected error for unrecorded houses")
+	}
+}
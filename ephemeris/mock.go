@@ -0,0 +1,48 @@
+package ephemeris
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// MockProvider serves fixed, deterministic data, for tests that need chart
+// input without an ephemeris.
+//
+// Each body in Planets is taken to be at the given position at Julian Day
+// Epoch and to move uniformly at its SpeedLon, so callers that search over
+// time (returns, transits) see plausible motion. Houses is returned
+// unchanged for every time and location. Requests for bodies missing from
+// Planets fail, as does every call when Err is set.
+type MockProvider struct {
+	Epoch   float64
+	Planets map[int]PlanetPos
+	Houses  HouseResult
+	Err     error
+}
+
+// CalcPlanet implements Provider.
+func (m *MockProvider) CalcPlanet(jd float64, body int) (PlanetPos, error) {
+	if m.Err != nil {
+		return PlanetPos{}, m.Err
+	}
+	pos, ok := m.Planets[body]
+	if !ok {
+		return PlanetPos{}, fmt.Errorf("mock: no data for %s", BodyName(body))
+	}
+	pos.Longitude = angle.Norm360(pos.Longitude + pos.SpeedLon*(jd-m.Epoch))
+	return pos, nil
+}
+
+// CalcHouses implements Provider.
+func (m *MockProvider) CalcHouses(jd, lat, lon float64, hsys byte) (HouseResult, error) {
+	if m.Err != nil {
+		return HouseResult{}, m.Err
+	}
+	return m.Houses, nil
+}
+
+// PlanetName implements Provider.
+func (m *MockProvider) PlanetName(body int) string {
+	return BodyName(body)
+}
@@ -0,0 +1,238 @@
+// Package swiss provides ephemeris.Provider implementations backed by the
+// Swiss Ephemeris C library.
+package swiss
+
+import (
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+// Provider reads positions from the .se1 files configured with
+// swisseph.SetEphePath, silently falling back to the Moshier approximation
+// when they are absent.
+type Provider struct {
+	// Flags are extra swisseph.Flag* values OR'd into every planet
+	// calculation, e.g. swisseph.FlagHeliocentric. swisseph.FlagSidereal
+	// makes the houses sidereal too.
+	Flags int
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (p Provider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	return calc(jd, body, swisseph.FlagSwissEph|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcPlanets implements ephemeris.BatchProvider.
+func (p Provider) CalcPlanets(jd float64, bodies []int) ([]ephemeris.PlanetPos, error) {
+	return calcMany(jd, bodies, swisseph.FlagSwissEph|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (p Provider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	return houses(jd, lat, lon, hsys, p.Flags)
+}
+
+// Crossing implements ephemeris.CrossingProvider.
+func (p Provider) Crossing(jd float64, body int, lon float64) (float64, bool, error) {
+	return cross(jd, body, lon, swisseph.FlagSwissEph|p.Flags)
+}
+
+// Source implements ephemeris.SourceProvider.
+func (p Provider) Source(jd float64, body int) (ephemeris.Source, bool, error) {
+	return source(jd, body, swisseph.FlagSwissEph|p.Flags)
+}
+
+// PlanetName implements ephemeris.Provider.
+func (Provider) PlanetName(body int) string {
+	return swisseph.PlanetName(body)
+}
+
+// MoshierProvider always uses the Moshier analytical ephemeris built into
+// the library. It needs no data files and is accurate to about an arcsecond
+// for the planets over several millennia. It calls the C library like the
+// other providers here; there is no pure-Go Moshier ephemeris.
+type MoshierProvider struct {
+	Flags int // as for Provider
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (p MoshierProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	return calc(jd, body, swisseph.FlagMoshier|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcPlanets implements ephemeris.BatchProvider.
+func (p MoshierProvider) CalcPlanets(jd float64, bodies []int) ([]ephemeris.PlanetPos, error) {
+	return calcMany(jd, bodies, swisseph.FlagMoshier|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (p MoshierProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	return houses(jd, lat, lon, hsys, p.Flags)
+}
+
+// Crossing implements ephemeris.CrossingProvider.
+func (p MoshierProvider) Crossing(jd float64, body int, lon float64) (float64, bool, error) {
+	return cross(jd, body, lon, swisseph.FlagMoshier|p.Flags)
+}
+
+// Source implements ephemeris.SourceProvider.
+func (p MoshierProvider) Source(jd float64, body int) (ephemeris.Source, bool, error) {
+	return source(jd, body, swisseph.FlagMoshier|p.Flags)
+}
+
+// PlanetName implements ephemeris.Provider.
+func (MoshierProvider) PlanetName(body int) string {
+	return swisseph.PlanetName(body)
+}
+
+// JPLProvider reads a JPL Development Ephemeris file (de431.eph by default)
+// from the ephemeris path. Unlike Provider it does not fall back: when the
+// file is missing or does not cover the date, CalcPlanet returns an error.
+type JPLProvider struct {
+	Flags int // as for Provider
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (p JPLProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	return calc(jd, body, swisseph.FlagJPL|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcPlanets implements ephemeris.BatchProvider.
+func (p JPLProvider) CalcPlanets(jd float64, bodies []int) ([]ephemeris.PlanetPos, error) {
+	return calcMany(jd, bodies, swisseph.FlagJPL|swisseph.FlagSpeed|p.Flags)
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (p JPLProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	return houses(jd, lat, lon, hsys, p.Flags)
+}
+
+// Crossing implements ephemeris.CrossingProvider.
+func (p JPLProvider) Crossing(jd float64, body int, lon float64) (float64, bool, error) {
+	return cross(jd, body, lon, swisseph.FlagJPL|p.Flags)
+}
+
+// Source implements ephemeris.SourceProvider.
+func (p JPLProvider) Source(jd float64, body int) (ephemeris.Source, bool, error) {
+	return source(jd, body, swisseph.FlagJPL|p.Flags)
+}
+
+// PlanetName implements ephemeris.Provider.
+func (JPLProvider) PlanetName(body int) string {
+	return swisseph.PlanetName(body)
+}
+
+// CentricProvider computes planetocentric positions: the sky as seen from
+// the centre of the Center body (e.g. swisseph.Mars) instead of from Earth.
+// It is experimental. Houses are those of the given terrestrial location,
+// which have no meaning for an observer elsewhere; callers should not
+// present them.
+type CentricProvider struct {
+	Center int
+	// Flags are as for Provider, except that swisseph.FlagMoshier or
+	// swisseph.FlagJPL here selects that ephemeris instead of the .se1 files.
+	Flags int
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (p CentricProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	src := swisseph.FlagSwissEph
+	if p.Flags&(swisseph.FlagMoshier|swisseph.FlagJPL) != 0 {
+		src = 0 // the caller chose another ephemeris
+	}
+	pos, err := swisseph.CalcPlanetCentric(jd, body, p.Center, src|swisseph.FlagSpeed|p.Flags)
+	if err != nil {
+		return ephemeris.PlanetPos{}, err
+	}
+	return ephemeris.PlanetPos(pos), nil
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (p CentricProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	return houses(jd, lat, lon, hsys, p.Flags)
+}
+
+// PlanetName implements ephemeris.Provider.
+func (CentricProvider) PlanetName(body int) string {
+	return swisseph.PlanetName(body)
+}
+
+func calc(jd float64, body, flags int) (ephemeris.PlanetPos, error) {
+	pos, err := swisseph.CalcPlanetFlags(jd, body, flags)
+	if err != nil {
+		return ephemeris.PlanetPos{}, err
+	}
+	return ephemeris.PlanetPos(pos), nil
+}
+
+func calcMany(jd float64, bodies []int, flags int) ([]ephemeris.PlanetPos, error) {
+	pos, err := swisseph.CalcPlanets(jd, bodies, flags)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ephemeris.PlanetPos, len(pos))
+	for i, p := range pos {
+		out[i] = ephemeris.PlanetPos(p)
+	}
+	return out, nil
+}
+
+// houses casts houses; of the provider's flags only swisseph.FlagSidereal
+// applies.
+func houses(jd, lat, lon float64, hsys byte, flags int) (ephemeris.HouseResult, error) {
+	h, err := swisseph.CalcHousesFlags(jd, lat, lon, hsys, flags)
+	if err != nil {
+		return ephemeris.HouseResult{}, err
+	}
+	return ephemeris.HouseResult(h), nil
+}
+
+// HousesARMC casts houses from sidereal time rather than a moment, for
+// charts such as composites that have no moment of their own. It matches
+// composite.HousesFunc.
+func HousesARMC(armc, lat, eps float64, hsys byte) (ephemeris.HouseResult, error) {
+	h, err := swisseph.CalcHousesARMC(armc, lat, eps, hsys)
+	if err != nil {
+		return ephemeris.HouseResult{}, err
+	}
+	return ephemeris.HouseResult(h), nil
+}
+
+// cross finds the Sun's or Moon's next crossing of lon after jd with the
+// library's own search. Heliocentric flags leave it to the caller.
+func cross(jd float64, body int, lon float64, flags int) (float64, bool, error) {
+	if flags&swisseph.FlagHeliocentric != 0 {
+		return 0, false, nil
+	}
+	var t float64
+	var err error
+	switch body {
+	case swisseph.Sun:
+		t, err = swisseph.SolCross(lon, jd, flags)
+	case swisseph.Moon:
+		t, err = swisseph.MoonCross(lon, jd, flags)
+	default:
+		return 0, false, nil
+	}
+	return t, err == nil, err
+}
+
+// backends name the ephemeris of each source flag as --ephemeris does.
+var backends = map[int]string{swisseph.FlagSwissEph: "swiss", swisseph.FlagMoshier: "moshier", swisseph.FlagJPL: "jpl"}
+
+// source computes body at jd with flags, whose ephemeris flag is the one
+// asked for, and reports the ephemeris that served it.
+func source(jd float64, body, flags int) (ephemeris.Source, bool, error) {
+	_, src, err := swisseph.CalcPlanetSource(jd, body, flags)
+	if err != nil {
+		return ephemeris.Source{}, false, err
+	}
+	asked := flags & (swisseph.FlagSwissEph | swisseph.FlagMoshier | swisseph.FlagJPL)
+	return ephemeris.Source{
+		Ephemeris: backends[src.Ephemeris],
+		Fallback:  src.Ephemeris&asked == 0,
+		File:      src.File,
+		Start:     src.Start,
+		End:       src.End,
+	}, true, nil
+}
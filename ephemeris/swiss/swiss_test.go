@@ -0,0 +1,79 @@
+package swiss_test
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/ephemeris/swiss"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/swisseph"
+)
+
+func TestMain(m *testing.M) {
+	swisseph.SetEphePath("../../ephe")
+	code := m.Run()
+	swisseph.Close()
+	os.Exit(code)
+}
+
+// TestPlanetNames checks the pure-Go name table in the ephemeris package
+// agrees with the C library for every body it lists.
+func TestPlanetNames(t *testing.T) {
+	var p swiss.Provider
+	for body := 0; body <= 22; body++ {
+		if got, want := ephemeris.BodyName(body), p.PlanetName(body); got != want {
+			t.Errorf("BodyName(%d) = %q, library says %q", body, got, want)
+		}
+	}
+}
+
+// TestMoshierAgreesWithSwiss compares both providers at J2000.0. The Moshier
+// theory is good to well under an arcminute for the classical planets.
+func TestMoshierAgreesWithSwiss(t *testing.T) {
+	const jd = 2451545.0
+	for body := ephemeris.Sun; body <= ephemeris.Saturn; body++ {
+		s, err := swiss.Provider{}.CalcPlanet(jd, body)
+		if err != nil {
+			t.Fatalf("Swiss CalcPlanet(%d): %v", body, err)
+		}
+		m, err := swiss.MoshierProvider{}.CalcPlanet(jd, body)
+		if err != nil {
+			t.Fatalf("Moshier CalcPlanet(%d): %v", body, err)
+		}
+		if d := math.Abs(s.Longitude - m.Longitude); d > 1.0/60 {
+			t.Errorf("body %d: Swiss %.6f° vs Moshier %.6f° (diff %.2e°)", body, s.Longitude, m.Longitude, d)
+		}
+	}
+}
+
+// TestCrossing checks the library's search for the Sun's and Moon's
+// ingresses agrees with mundane's sampling, which a provider without
+// Crossing gets.
+func TestCrossing(t *testing.T) {
+	var p swiss.Provider
+	sampled := struct{ ephemeris.Provider }{p}
+	const from, to = 2460676.5, 2460736.5 // January and February 2025
+	for _, body := range []int{ephemeris.Sun, ephemeris.Moon} {
+		got, err := mundane.Ingresses(p, body, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := mundane.Ingresses(sampled, body, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) || len(got) == 0 {
+			t.Fatalf("%s: %d ingresses by Crossing, %d by sampling", p.PlanetName(body), len(got), len(want))
+		}
+		for i := range got {
+			if got[i].Sign != want[i].Sign || math.Abs(got[i].JD-want[i].JD) > 2.0/86400 {
+				t.Errorf("%s ingress %d: %+v, sampled %+v", p.PlanetName(body), i, got[i], want[i])
+			}
+		}
+	}
+	if _, ok, err := p.Crossing(from, ephemeris.Mars, 0); ok || err != nil {
+		t.Errorf("Crossing for Mars: ok %v, err %v; want neither", ok, err)
+	}
+}
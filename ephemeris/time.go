@@ -0,0 +1,22 @@
+package ephemeris
+
+import (
+	"math"
+	"time"
+)
+
+// unixEpochJD is the Julian Day of 1970-01-01T00:00:00Z.
+const unixEpochJD = 2440587.5
+
+// JulianDay converts t to a Julian Day number in UT, using the proleptic
+// Gregorian calendar like swisseph.JulDay.
+func JulianDay(t time.Time) float64 {
+	return unixEpochJD + (float64(t.Unix())+float64(t.Nanosecond())/1e9)/86400
+}
+
+// TimeOf converts a Julian Day number (UT) to a UTC time, rounded to the
+// nearest millisecond.
+func TimeOf(jd float64) time.Time {
+	ms := math.Round((jd - unixEpochJD) * 86400e3)
+	return time.UnixMilli(int64(ms)).UTC()
+}
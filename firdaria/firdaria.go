@@ -0,0 +1,130 @@
+// Package firdaria computes the Persian firdaria: the 75-year sequence of
+// planetary periods that divides a life, each planet's period split into
+// seven sub-periods. A day birth starts the sequence with the Sun, a night
+// birth with the Moon; see package sect for which a chart is.
+package firdaria
+
+import (
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Ruler is the lord of a period: a planet's body ID, or NorthNode or
+// SouthNode.
+type Ruler int
+
+const (
+	NorthNode Ruler = ephemeris.MeanNode
+	SouthNode Ruler = -1 // has no body ID of its own
+)
+
+// major is one entry in the sequence of major periods.
+type major struct {
+	ruler Ruler
+	years int
+}
+
+// The major periods. The nodes close both sequences, as in Abu Ma'shar;
+// some authors place them after Mars in night births instead.
+var (
+	dayOrder = []major{
+		{ephemeris.Sun, 10}, {ephemeris.Venus, 8}, {ephemeris.Mercury, 13},
+		{ephemeris.Moon, 9}, {ephemeris.Saturn, 11}, {ephemeris.Jupiter, 12},
+		{ephemeris.Mars, 7}, {NorthNode, 3}, {SouthNode, 2},
+	}
+	nightOrder = []major{
+		{ephemeris.Moon, 9}, {ephemeris.Saturn, 11}, {ephemeris.Jupiter, 12},
+		{ephemeris.Mars, 7}, {ephemeris.Sun, 10}, {ephemeris.Venus, 8},
+		{ephemeris.Mercury, 13}, {NorthNode, 3}, {SouthNode, 2},
+	}
+)
+
+// chaldean is the order of the planets from slowest to fastest, which the
+// sub-periods follow from the major lord onwards.
+var chaldean = []Ruler{
+	ephemeris.Saturn, ephemeris.Jupiter, ephemeris.Mars, ephemeris.Sun,
+	ephemeris.Venus, ephemeris.Mercury, ephemeris.Moon,
+}
+
+// Cycle is the length of the whole sequence in years.
+const Cycle = 75
+
+// Period is one major period or sub-period, running from Start up to End.
+type Period struct {
+	Ruler      Ruler
+	Start, End time.Time
+	Sub        []Period // the seven sub-periods of a planet's major period; none for the nodes
+}
+
+// Periods returns the nine major periods of the firdaria for a birth at
+// birth. Major periods begin on birthdays; each planet's period is divided
+// into seven equal sub-periods ruled in Chaldean order starting with the
+// major lord.
+func Periods(birth time.Time, day bool) []Period {
+	order := nightOrder
+	if day {
+		order = dayOrder
+	}
+	var periods []Period
+	years := 0
+	for _, m := range order {
+		p := Period{
+			Ruler: m.ruler,
+			Start: birth.AddDate(years, 0, 0),
+			End:   birth.AddDate(years+m.years, 0, 0),
+		}
+		if i := chaldeanIndex(m.ruler); i >= 0 {
+			span := p.End.Sub(p.Start) / 7
+			for k := 0; k < 7; k++ {
+				start := p.Start.Add(time.Duration(k) * span)
+				end := start.Add(span)
+				if k == 6 {
+					end = p.End
+				}
+				p.Sub = append(p.Sub, Period{Ruler: chaldean[(i+k)%7], Start: start, End: end})
+			}
+		}
+		periods = append(periods, p)
+		years += m.years
+	}
+	return periods
+}
+
+// At returns the major period and sub-period in force at t, searching
+// periods as returned by Periods. sub is nil during a node's period. ok is
+// false if t falls outside the sequence.
+func At(periods []Period, t time.Time) (major, sub *Period, ok bool) {
+	for i := range periods {
+		p := &periods[i]
+		if t.Before(p.Start) || !t.Before(p.End) {
+			continue
+		}
+		for j := range p.Sub {
+			if s := &p.Sub[j]; !t.Before(s.Start) && t.Before(s.End) {
+				return p, s, true
+			}
+		}
+		return p, nil, true
+	}
+	return nil, nil, false
+}
+
+func chaldeanIndex(r Ruler) int {
+	for i, c := range chaldean {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r Ruler) String() string {
+	switch r {
+	case NorthNode:
+		return "North Node"
+	case SouthNode:
+		return "South Node"
+	}
+	return ephemeris.BodyName(int(r))
+}
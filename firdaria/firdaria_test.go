@@ -0,0 +1,71 @@
+package firdaria_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/firdaria"
+)
+
+var birth = time.Date(1990, 1, 9, 14, 30, 0, 0, time.UTC)
+
+func TestPeriods_Day(t *testing.T) {
+	ps := firdaria.Periods(birth, true)
+	want := []firdaria.Ruler{
+		ephemeris.Sun, ephemeris.Venus, ephemeris.Mercury, ephemeris.Moon, ephemeris.Saturn,
+		ephemeris.Jupiter, ephemeris.Mars, firdaria.NorthNode, firdaria.SouthNode,
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("got %d periods, want %d", len(ps), len(want))
+	}
+	for i, p := range ps {
+		if p.Ruler != want[i] {
+			t.Errorf("period %d ruled by %v, want %v", i, p.Ruler, want[i])
+		}
+		if i > 0 && !p.Start.Equal(ps[i-1].End) {
+			t.Errorf("period %d starts %v, previous ends %v", i, p.Start, ps[i-1].End)
+		}
+	}
+	if end := ps[len(ps)-1].End; !end.Equal(birth.AddDate(firdaria.Cycle, 0, 0)) {
+		t.Errorf("sequence ends %v, want %d years after birth", end, firdaria.Cycle)
+	}
+
+	// Sun (10 years): Sun, Venus, Mercury, Moon, Saturn, Jupiter, Mars.
+	sun := ps[0]
+	if len(sun.Sub) != 7 || sun.Sub[0].Ruler != ephemeris.Sun || sun.Sub[1].Ruler != ephemeris.Venus || sun.Sub[4].Ruler != ephemeris.Saturn {
+		t.Errorf("Sun sub-periods = %v", sun.Sub)
+	}
+	if !sun.Sub[6].End.Equal(sun.End) {
+		t.Errorf("last sub-period ends %v, want %v", sun.Sub[6].End, sun.End)
+	}
+	if len(ps[7].Sub) != 0 {
+		t.Error("the North Node's period should have no sub-periods")
+	}
+}
+
+func TestPeriods_Night(t *testing.T) {
+	ps := firdaria.Periods(birth, false)
+	if ps[0].Ruler != ephemeris.Moon || ps[1].Ruler != ephemeris.Saturn || ps[4].Ruler != ephemeris.Sun {
+		t.Errorf("night order starts %v, %v, ..., %v", ps[0].Ruler, ps[1].Ruler, ps[4].Ruler)
+	}
+	// Moon: Moon, then back to the top of the Chaldean order.
+	if ps[0].Sub[1].Ruler != ephemeris.Saturn {
+		t.Errorf("second Moon sub-period ruled by %v, want Saturn", ps[0].Sub[1].Ruler)
+	}
+}
+
+func TestAt(t *testing.T) {
+	ps := firdaria.Periods(birth, true)
+	major, sub, ok := firdaria.At(ps, birth.AddDate(2, 0, 0))
+	if !ok || major.Ruler != ephemeris.Sun || sub.Ruler != ephemeris.Venus {
+		t.Errorf("At(+2y) = %v/%v, want Sun/Venus", major, sub)
+	}
+	major, sub, ok = firdaria.At(ps, birth.AddDate(71, 0, 0))
+	if !ok || major.Ruler != firdaria.NorthNode || sub != nil {
+		t.Errorf("At(+71y) = %v/%v, want North Node with no sub-period", major, sub)
+	}
+	if _, _, ok := firdaria.At(ps, birth.AddDate(-1, 0, 0)); ok {
+		t.Error("At before birth should not be ok")
+	}
+}
@@ -0,0 +1,130 @@
+// Package geo parses geographic coordinates in the notations astrologers
+// write them in, and measures distances between them.
+//
+// A coordinate may be given in decimal degrees, signed or with the
+// hemisphere, or in degrees, minutes and seconds:
+//
+//	51.5074   -0.1278   51.5074N   0.1278 W
+//	51N30     0W07      51n30:26   0w07'40
+//	51°30'26"N          N 51° 30.4'          2°21′E
+//
+// In the compact notation of the chart tables, 51N30, the hemisphere
+// letter stands between the degrees and the minutes.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// EarthRadius is the mean radius of the Earth, in kilometres.
+const EarthRadius = 6371.0
+
+// axis describes latitude or longitude for the parser.
+type axis struct {
+	name     string
+	pos, neg byte // hemisphere letters, upper case
+	limit    float64
+	example  string // in each notation, for error messages
+}
+
+var (
+	latitude  = axis{"latitude", 'N', 'S', 90, "51.5074, 51N30 or 51°30'N"}
+	longitude = axis{"longitude", 'E', 'W', 180, "-0.1278, 0W07 or 0°07'W"}
+)
+
+// ParseLatitude parses a latitude, north positive, in any of the
+// notations of the package documentation. It must lie within ±90°.
+func ParseLatitude(s string) (float64, error) {
+	return parse(s, latitude, longitude)
+}
+
+// ParseLongitude parses a longitude, east positive, in any of the
+// notations of the package documentation. It must lie within ±180°.
+func ParseLongitude(s string) (float64, error) {
+	return parse(s, longitude, latitude)
+}
+
+func parse(s string, a, other axis) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		if v, err = parseDMS(s, a, other); err != nil {
+			return 0, err
+		}
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("expected degrees, e.g. %s", a.example)
+	}
+	if math.Abs(v) > a.limit {
+		return 0, fmt.Errorf("out of range: a %s is at most %g°%c or %c", a.name, a.limit, a.pos, a.neg)
+	}
+	return v, nil
+}
+
+// parseDMS parses degrees, minutes and seconds, with or without the
+// hemisphere.
+func parseDMS(s string, a, other axis) (float64, error) {
+	syntax := fmt.Errorf("expected degrees, e.g. %s", a.example)
+	s = strings.ToUpper(strings.TrimSpace(s))
+	sign := 1.0
+	i := strings.IndexFunc(s, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	var parts []string
+	switch {
+	case i < 0:
+		if rest, ok := strings.CutPrefix(s, "-"); ok {
+			s, sign = rest, -1
+		} else {
+			s = strings.TrimPrefix(s, "+")
+		}
+		parts = fields(s)
+	case s[i] == other.pos || s[i] == other.neg:
+		return 0, fmt.Errorf("%c marks a %s, not a %s", s[i], other.name, a.name)
+	case s[i] != a.pos && s[i] != a.neg:
+		return 0, syntax
+	default:
+		if s[i] == a.neg {
+			sign = -1
+		}
+		before, after := fields(s[:i]), fields(s[i+1:])
+		if len(before) > 0 && len(after) > 0 && len(before) != 1 {
+			// Only the degrees may precede the letter in 51N30.
+			return 0, syntax
+		}
+		parts = append(before, after...)
+	}
+	// The sign is the hemisphere's or the one cut above; another is an error.
+	dms := strings.Join(parts, " ")
+	if strings.ContainsAny(dms, "+-") {
+		return 0, syntax
+	}
+	v, err := angle.ParseDMS(dms)
+	if errors.Is(err, angle.ErrRange) {
+		return 0, angle.ErrRange
+	} else if err != nil {
+		return 0, syntax
+	}
+	return sign * v, nil
+}
+
+// fields splits s at spaces and the symbols for degrees, minutes and
+// seconds.
+func fields(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("°º'\"′″:", r)
+	})
+}
+
+// Distance returns the great-circle distance between two points, in
+// kilometres.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dlat, dlon := (lat2-lat1)*rad, (lon2-lon1)*rad
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin(dlon/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
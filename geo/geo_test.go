@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		lon  bool
+		want float64
+		err  string // part of the error, or "" for none
+	}{
+		{"51.5074", false, 51.5074, ""},
+		{"-0.1278", true, -0.1278, ""},
+		{"+2.35", true, 2.35, ""},
+		{"51.5074N", false, 51.5074, ""},
+		{"33.87 s", false, -33.87, ""},
+		{"0.1278W", true, -0.1278, ""},
+		{"51N30", false, 51.5, ""},
+		{"0W07", true, -7.0 / 60, ""},
+		{"0w07:39", true, -(7.0/60 + 39.0/3600), ""},
+		{"33s52'", false, -(33 + 52.0/60), ""},
+		{"48°51'N", false, 48.85, ""},
+		{"2°21'E", true, 2.35, ""},
+		{`48°51'24"N`, false, 48 + 51.0/60 + 24.0/3600, ""},
+		{"48° 51′ 24″ N", false, 48 + 51.0/60 + 24.0/3600, ""},
+		{"N 48° 51.4'", false, 48 + 51.4/60, ""},
+		{"W 74º 0' 21\"", true, -(74 + 21.0/3600), ""},
+		{"-33°52'", false, -(33 + 52.0/60), ""},
+		{"40.7°", false, 40.7, ""},
+		{"180W", true, -180, ""},
+		{"95", false, 0, "out of range"},
+		{"91N00", false, 0, "out of range"},
+		{"181E", true, 0, "out of range"},
+		{"51N60", false, 0, "under 60"},
+		{"51N30:75", false, 0, "under 60"},
+		{"0W07", false, 0, "W marks a longitude"},
+		{"51N30", true, 0, "N marks a latitude"},
+		{"51.5N30", false, 0, "expected degrees"},
+		{"51N30'15\"20", false, 0, "expected degrees"},
+		{"-51N30", false, 0, "expected degrees"},
+		{"51 30 N 15", false, 0, "expected degrees"},
+		{"51,5074", false, 0, "expected degrees"},
+		{"north", false, 0, "expected degrees"},
+		{"NaN", false, 0, "expected degrees"},
+		{"", false, 0, "expected degrees"},
+	}
+	for _, tt := range tests {
+		parse := ParseLatitude
+		if tt.lon {
+			parse = ParseLongitude
+		}
+		got, err := parse(tt.in)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("parse(%q): %v", tt.in, err)
+		case tt.err == "" && math.Abs(got-tt.want) > 1e-12:
+			t.Errorf("parse(%q) = %v, want %v", tt.in, got, tt.want)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parse(%q) = %v, %v; want an error with %q", tt.in, got, err, tt.err)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		lat1, lon1, lat2, lon2 float64
+		want                   float64 // km
+	}{
+		{51.5074, -0.1278, 48.8566, 2.3522, 344}, // London to Paris
+		{40.7128, -74.006, 51.5074, -0.1278, 5570},
+		{0, 0, 0, 180, math.Pi * EarthRadius},
+		{10, 20, 10, 20, 0},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2); math.Abs(got-tt.want) > 1 {
+			t.Errorf("Distance(%v, %v, %v, %v) = %.1f km, want %.0f", tt.lat1, tt.lon1, tt.lat2, tt.lon2, got, tt.want)
+		}
+	}
+}
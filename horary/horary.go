@@ -0,0 +1,133 @@
+// Package horary weighs the considerations before judgement of a horary
+// chart: the strictures that may make it unfit to judge, and whether the
+// lord of the planetary hour agrees with the lord of the Ascendant, which
+// shows the chart to be radical.
+package horary
+
+import (
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/lunar"
+)
+
+// Degrees of the rising sign before and after which the Ascendant is too
+// early or too late to judge.
+const (
+	EarlyAscendant = 3.0
+	LateAscendant  = 27.0
+)
+
+// The via combusta runs from 15° Libra to 15° Scorpio.
+const (
+	viaCombustaStart = 195.0
+	viaCombustaEnd   = 225.0
+)
+
+// Agreement is how the lord of the hour agrees with the lord of the
+// Ascendant.
+type Agreement int
+
+const (
+	Disagrees  Agreement = iota
+	Same                 // the same planet
+	Triplicity           // the hour lord is a triplicity lord of the rising sign
+	Nature               // the two share a temperament, e.g. both hot and dry
+	NoHour               // there are no planetary hours (no sunrise or sunset)
+)
+
+func (a Agreement) String() string {
+	switch a {
+	case Disagrees:
+		return "disagrees"
+	case Same:
+		return "same planet"
+	case Triplicity:
+		return "triplicity"
+	case Nature:
+		return "nature"
+	case NoHour:
+		return "no hour"
+	}
+	return "Agreement(?)"
+}
+
+// nature gives the temperament of each classical planet as (hot, moist),
+// after Lilly; Mercury, convertible, is taken as cold and dry.
+var nature = map[int][2]bool{
+	ephemeris.Sun: {true, false}, ephemeris.Moon: {false, true},
+	ephemeris.Mercury: {false, false}, ephemeris.Venus: {false, true},
+	ephemeris.Mars: {true, false}, ephemeris.Jupiter: {true, true},
+	ephemeris.Saturn: {false, false},
+}
+
+// Report holds the considerations for one chart.
+type Report struct {
+	AscDegree       float64 // degree of the Ascendant within its sign
+	Early, Late     bool
+	MoonVoid        bool
+	MoonNext        lunar.Perfection // the Moon's next aspect, unless void
+	SaturnHouse     int
+	SaturnInSeventh bool
+	ViaCombusta     bool
+	AscRuler        int
+	HourRuler       int // -1 if there are no planetary hours
+	Agreement       Agreement
+}
+
+// Radical reports whether no stricture applies and the hour agrees.
+func (r Report) Radical() bool {
+	return !r.Early && !r.Late && !r.MoonVoid && !r.SaturnInSeventh && !r.ViaCombusta &&
+		r.Agreement != Disagrees && r.Agreement != NoHour
+}
+
+// Consider weighs the chart with the given classical planets and houses,
+// cast for a moment in the hour ruled by hourRuler (-1 if none).
+func Consider(planets map[int]ephemeris.PlanetPos, h ephemeris.HouseResult, hourRuler int) Report {
+	asc := angle.Norm360(h.Ascendant)
+	r := Report{
+		AscDegree: math.Mod(asc, 30),
+		AscRuler:  dignity.RulerOf(asc),
+		HourRuler: hourRuler,
+	}
+	r.Early, r.Late = r.AscDegree < EarlyAscendant, r.AscDegree > LateAscendant
+
+	moon := planets[ephemeris.Moon]
+	others := make(map[int]ephemeris.PlanetPos, len(lunar.VoidPlanets))
+	for _, b := range lunar.VoidPlanets {
+		others[b] = planets[b]
+	}
+	var ok bool
+	r.MoonNext, ok = lunar.NextAspect(moon, others)
+	r.MoonVoid = !ok
+	m := angle.Norm360(moon.Longitude)
+	r.ViaCombusta = m >= viaCombustaStart && m < viaCombustaEnd
+
+	r.SaturnHouse = h.HouseOf(planets[ephemeris.Saturn].Longitude)
+	r.SaturnInSeventh = r.SaturnHouse == 7
+
+	r.Agreement = agreement(hourRuler, r.AscRuler, dignity.Sign(asc))
+	return r
+}
+
+// agreement judges the hour lord against the lord of the Ascendant, whose
+// sign is asc.
+func agreement(hour, lord, asc int) Agreement {
+	switch {
+	case hour < 0:
+		return NoHour
+	case hour == lord:
+		return Same
+	}
+	for _, l := range dignity.TriplicityLords(asc) {
+		if l == hour {
+			return Triplicity
+		}
+	}
+	if nature[hour] == nature[lord] {
+		return Nature
+	}
+	return Disagrees
+}
@@ -0,0 +1,93 @@
+package horary_test
+
+import (
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/horary"
+)
+
+// chart returns equal houses from asc and the classical planets at the
+// given longitudes, moving at typical speeds.
+func chart(asc float64, lons map[int]float64) (map[int]ephemeris.PlanetPos, ephemeris.HouseResult) {
+	var h ephemeris.HouseResult
+	h.Ascendant = asc
+	for i := 1; i <= 12; i++ {
+		h.Cusps[i] = asc + float64(i-1)*30
+	}
+	speeds := map[int]float64{
+		ephemeris.Sun: 1, ephemeris.Moon: 13, ephemeris.Mercury: 1.2, ephemeris.Venus: 1.1,
+		ephemeris.Mars: 0.6, ephemeris.Jupiter: 0.1, ephemeris.Saturn: 0.05,
+	}
+	planets := map[int]ephemeris.PlanetPos{}
+	for body, lon := range lons {
+		planets[body] = ephemeris.PlanetPos{Longitude: lon, SpeedLon: speeds[body]}
+	}
+	return planets, h
+}
+
+func TestConsider_Radical(t *testing.T) {
+	// 15° Leo rising, in the hour of the Sun; the Moon at 10° Aries applies
+	// to a trine of Jupiter at 12° Leo.
+	planets, h := chart(135, map[int]float64{
+		ephemeris.Sun: 20, ephemeris.Moon: 10, ephemeris.Mercury: 40, ephemeris.Venus: 60,
+		ephemeris.Mars: 105, ephemeris.Jupiter: 132, ephemeris.Saturn: 260,
+	})
+	r := horary.Consider(planets, h, ephemeris.Sun)
+	if !r.Radical() {
+		t.Errorf("chart not radical: %+v", r)
+	}
+	if r.AscRuler != ephemeris.Sun || r.Agreement != horary.Same {
+		t.Errorf("ascendant ruler %d, agreement %v; want the Sun, same planet", r.AscRuler, r.Agreement)
+	}
+	if r.MoonNext.Body != ephemeris.Jupiter || r.MoonNext.Aspect.Name != "trine" {
+		t.Errorf("Moon's next aspect %s to %d, want trine to Jupiter", r.MoonNext.Aspect.Name, r.MoonNext.Body)
+	}
+}
+
+func TestConsider_Strictures(t *testing.T) {
+	// 28° Aries rising (late); Saturn at 215° in the 7th; the Moon at 222°
+	// in the via combusta, with nothing to aspect before leaving Scorpio.
+	planets, h := chart(28, map[int]float64{
+		ephemeris.Sun: 90, ephemeris.Moon: 222, ephemeris.Mercury: 91, ephemeris.Venus: 92,
+		ephemeris.Mars: 93, ephemeris.Jupiter: 94, ephemeris.Saturn: 215,
+	})
+	r := horary.Consider(planets, h, ephemeris.Venus)
+	if !r.Late || r.Early {
+		t.Errorf("early %v late %v, want late", r.Early, r.Late)
+	}
+	if !r.SaturnInSeventh || !r.ViaCombusta || !r.MoonVoid {
+		t.Errorf("Saturn in 7th %v, via combusta %v, Moon void %v; want all", r.SaturnInSeventh, r.ViaCombusta, r.MoonVoid)
+	}
+	// Mars rules Aries; Venus is neither a fire triplicity lord nor hot
+	// and dry.
+	if r.Agreement != horary.Disagrees {
+		t.Errorf("agreement %v, want disagrees", r.Agreement)
+	}
+	if r.Radical() {
+		t.Error("chart radical, want not")
+	}
+}
+
+func TestConsider_Agreement(t *testing.T) {
+	planets, h := chart(15, map[int]float64{
+		ephemeris.Sun: 0, ephemeris.Moon: 50, ephemeris.Mercury: 0, ephemeris.Venus: 0,
+		ephemeris.Mars: 0, ephemeris.Jupiter: 0, ephemeris.Saturn: 0,
+	})
+	tests := []struct {
+		hour int
+		want horary.Agreement
+	}{
+		{ephemeris.Mars, horary.Same},
+		{ephemeris.Jupiter, horary.Triplicity}, // fire lord by night
+		{ephemeris.Saturn, horary.Triplicity},  // participating
+		{ephemeris.Mercury, horary.Disagrees},
+		{ephemeris.Moon, horary.Disagrees},
+		{-1, horary.NoHour},
+	}
+	for _, tt := range tests {
+		if got := horary.Consider(planets, h, tt.hour).Agreement; got != tt.want {
+			t.Errorf("hour of %d: agreement %v, want %v", tt.hour, got, tt.want)
+		}
+	}
+}
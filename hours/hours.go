@@ -0,0 +1,90 @@
+// Package hours computes planetary days and hours. The day runs from
+// sunrise to the next sunrise and is ruled by the planet of its weekday.
+// Daylight and night are each divided into twelve unequal hours, ruled in
+// turn by the planets in Chaldean order starting with the day ruler.
+package hours
+
+import (
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Chaldean is the order of the planets from slowest to fastest, which the
+// hours follow.
+var Chaldean = []int{
+	ephemeris.Saturn, ephemeris.Jupiter, ephemeris.Mars, ephemeris.Sun,
+	ephemeris.Venus, ephemeris.Mercury, ephemeris.Moon,
+}
+
+// dayRulers gives the ruler of each weekday, indexed by time.Weekday.
+var dayRulers = [7]int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mars, ephemeris.Mercury,
+	ephemeris.Jupiter, ephemeris.Venus, ephemeris.Saturn,
+}
+
+// DayRuler returns the planet ruling a weekday.
+func DayRuler(w time.Weekday) int { return dayRulers[w] }
+
+// Hour is one planetary hour.
+type Hour struct {
+	Number     int     // 1 to 12 for the day hours, 13 to 24 for the night hours
+	Ruler      int     // body ID
+	Start, End float64 // Julian Days (UT)
+}
+
+// Night reports whether the hour falls between sunset and sunrise.
+func (h Hour) Night() bool { return h.Number > 12 }
+
+// Day is one planetary day.
+type Day struct {
+	Weekday     time.Weekday
+	Ruler       int     // body ID of the day ruler
+	Sunrise     float64 // Julian Days (UT)
+	Sunset      float64
+	NextSunrise float64
+	Hours       [24]Hour
+}
+
+// Compute divides the planetary day beginning at sunrise, on the local
+// weekday w, into its 24 hours.
+func Compute(sunrise, sunset, nextSunrise float64, w time.Weekday) Day {
+	d := Day{Weekday: w, Ruler: DayRuler(w), Sunrise: sunrise, Sunset: sunset, NextSunrise: nextSunrise}
+	first := chaldeanIndex(d.Ruler)
+	dayLen := (sunset - sunrise) / 12
+	nightLen := (nextSunrise - sunset) / 12
+	for i := range d.Hours {
+		h := Hour{Number: i + 1, Ruler: Chaldean[(first+i)%7]}
+		if i < 12 {
+			h.Start = sunrise + float64(i)*dayLen
+			h.End = h.Start + dayLen
+		} else {
+			h.Start = sunset + float64(i-12)*nightLen
+			h.End = h.Start + nightLen
+		}
+		d.Hours[i] = h
+	}
+	// Close the last hours exactly on sunset and the next sunrise.
+	d.Hours[11].End, d.Hours[23].End = sunset, nextSunrise
+	return d
+}
+
+// At returns the hour in force at Julian Day jd, or false if jd lies
+// outside the day.
+func (d Day) At(jd float64) (Hour, bool) {
+	for _, h := range d.Hours {
+		if jd >= h.Start && jd < h.End {
+			return h, true
+		}
+	}
+	return Hour{}, false
+}
+
+func chaldeanIndex(body int) int {
+	for i, b := range Chaldean {
+		if b == body {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package hours_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/hours"
+)
+
+func TestCompute(t *testing.T) {
+	// A Saturday with 14 hours of daylight.
+	rise, set, next := 0.25, 0.25+14.0/24, 1.25
+	d := hours.Compute(rise, set, next, time.Saturday)
+
+	if d.Ruler != ephemeris.Saturn {
+		t.Errorf("Saturday ruled by %d, want Saturn", d.Ruler)
+	}
+	want := []int{ephemeris.Saturn, ephemeris.Jupiter, ephemeris.Mars, ephemeris.Sun, ephemeris.Venus, ephemeris.Mercury, ephemeris.Moon, ephemeris.Saturn}
+	for i, body := range want {
+		if d.Hours[i].Ruler != body {
+			t.Errorf("hour %d ruled by %d, want %d", i+1, d.Hours[i].Ruler, body)
+		}
+	}
+	// The 25th hour would be the first of Sunday: Sun.
+	if got := hours.Chaldean[(0+24)%7]; got != ephemeris.Sun {
+		t.Errorf("hour 25 ruler %d, want the Sun for Sunday", got)
+	}
+
+	if l := (d.Hours[0].End - d.Hours[0].Start) * 24 * 60; math.Abs(l-70) > 1e-6 {
+		t.Errorf("day hour lasts %.2f minutes, want 70", l)
+	}
+	if l := (d.Hours[12].End - d.Hours[12].Start) * 24 * 60; math.Abs(l-50) > 1e-6 {
+		t.Errorf("night hour lasts %.2f minutes, want 50", l)
+	}
+	if d.Hours[11].End != set || d.Hours[12].Start != set || d.Hours[23].End != next {
+		t.Error("hours should meet sunset and the next sunrise exactly")
+	}
+}
+
+func TestDayAt(t *testing.T) {
+	d := hours.Compute(0.25, 0.75, 1.25, time.Sunday)
+	h, ok := d.At(0.76)
+	if !ok || h.Number != 13 || !h.Night() {
+		t.Errorf("At(0.76) = %+v, %v; want the first night hour", h, ok)
+	}
+	if _, ok := d.At(0.2); ok {
+		t.Error("At before sunrise should not be ok")
+	}
+}
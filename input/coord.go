@@ -0,0 +1,106 @@
+package input
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dcccxiii/astro/geo"
+)
+
+// ParseLatitude parses a geographic latitude (north positive) in decimal
+// degrees, or in degrees and minutes with the hemisphere, such as 51N30 or
+// 51°30'N (see package geo).
+func ParseLatitude(s string) (float64, error) {
+	return parseCoord("latitude", s, "N", "S", geo.ParseLatitude)
+}
+
+// ParseLongitude parses a geographic longitude (east positive) in decimal
+// degrees, or in degrees and minutes with the hemisphere, such as 0W07 or
+// 0°07'W (see package geo). A longitude counted east from 180° to 360°, as
+// some sources give them, fails with the longitude west it means as the
+// suggestion.
+func ParseLongitude(s string) (float64, error) {
+	v, err := parseCoord("longitude", s, "E", "W", geo.ParseLongitude)
+	if e, ok := err.(*Error); ok && e.Suggestion == "" {
+		if east, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && east > 180 && east <= 360 {
+			e.Suggestion = strconv.FormatFloat(east-360, 'f', -1, 64)
+		}
+	}
+	return v, err
+}
+
+func parseCoord(kind, s, pos, neg string, parse func(string) (float64, error)) (float64, error) {
+	v, err := parse(s)
+	if err == nil {
+		return v, nil
+	}
+	e := &Error{Kind: kind, Value: s, Reason: err.Error()}
+	e.Suggestion = suggestCoord(s, pos, neg, parse)
+	return 0, e
+}
+
+// suggestCoord repairs decimal-comma notation, Unicode minus signs, stray
+// degree symbols and a trailing hemisphere letter.
+func suggestCoord(s, pos, neg string, parse func(string) (float64, error)) string {
+	fixed := strings.TrimSpace(s)
+	fixed = strings.ReplaceAll(fixed, "−", "-") // Unicode minus sign
+	fixed = strings.TrimSuffix(fixed, "°")
+	if strings.Count(fixed, ",") == 1 && !strings.Contains(fixed, ".") {
+		fixed = strings.Replace(fixed, ",", ".", 1)
+	}
+
+	upper := strings.ToUpper(fixed)
+	switch {
+	case strings.HasSuffix(upper, pos):
+		fixed = strings.TrimSpace(strings.TrimSuffix(fixed[:len(fixed)-1], "°"))
+	case strings.HasSuffix(upper, neg):
+		fixed = strings.TrimSpace(strings.TrimSuffix(fixed[:len(fixed)-1], "°"))
+		if !strings.HasPrefix(fixed, "-") {
+			fixed = "-" + fixed
+		}
+	}
+
+	if fixed == s {
+		return ""
+	}
+	v, err := strconv.ParseFloat(fixed, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	if _, err := parse(fixed); err != nil {
+		return ""
+	}
+	return fixed
+}
+
+// ParseCoordinates parses a latitude and a longitude as ParseLatitude and
+// ParseLongitude do. When the pair fails but would parse the other way
+// round, as 100 40 or 0W07 51N30 do, the error says so and, unless either
+// holds a space, suggests the pair swapped.
+func ParseCoordinates(lat, lon string) (float64, float64, error) {
+	la, latErr := ParseLatitude(lat)
+	lo, lonErr := ParseLongitude(lon)
+	if latErr == nil && lonErr == nil {
+		return la, lo, nil
+	}
+	err := latErr
+	if err == nil {
+		err = lonErr
+	}
+	_, swapLatErr := ParseLatitude(lon)
+	_, swapLonErr := ParseLongitude(lat)
+	if swapLatErr != nil || swapLonErr != nil {
+		return 0, 0, err
+	}
+	e := &Error{
+		Kind:   "coordinates",
+		Value:  lat + " " + lon,
+		Reason: err.(*Error).Reason + "; the latitude comes first, then the longitude",
+	}
+	if !strings.ContainsFunc(lat+lon, unicode.IsSpace) {
+		e.Suggestion = lon + " " + lat
+	}
+	return 0, 0, e
+}
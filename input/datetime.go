@@ -0,0 +1,321 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	_ "time/tzdata" // zone names resolve without a system zoneinfo database
+)
+
+// Options say how to read the datetimes given in local time: with neither
+// a zone designator nor an [Area/City] suffix, or as a date alone. Each
+// command, or each request of a session, has its own.
+type Options struct {
+	// Zone is the time zone of datetimes given as local civil time. If it
+	// is nil, such datetimes are rejected.
+	Zone *time.Location
+	// MeanTime, if set, is the local mean time of the chart's longitude,
+	// as MeanTimeAt returns it. A local time from before its zone kept
+	// standard time, when the time zone database has for it only the
+	// local mean time of the zone's main city (abbreviated LMT), is read
+	// in MeanTime instead.
+	MeanTime *time.Location
+	// DefaultTime is the time of day of a datetime given as a date alone,
+	// such as 2025-06-01, or as today.
+	DefaultTime time.Duration
+}
+
+// Default are the Options of ParseDateTime and the other functions of the
+// package: no zone, so a local time is rejected, and a date alone is read
+// at noon UTC.
+func Default() Options { return Options{DefaultTime: 12 * time.Hour} }
+
+// MeanTimeAt returns the local mean time at east longitude lon: UT plus
+// four minutes a degree, to the second.
+func MeanTimeAt(lon float64) *time.Location {
+	return time.FixedZone("LMT", int(math.Round(lon*240)))
+}
+
+// now returns the current time; tests replace it.
+var now = time.Now
+
+// localLayout is a datetime without a zone designator. When parsing, the
+// seconds may carry a fraction.
+const localLayout = "2006-01-02T15:04:05"
+
+// dateLayout is a date alone, read at Options.DefaultTime.
+const dateLayout = "2006-01-02"
+
+// ParseDateTime is Options.ParseDateTime with the Default options.
+func ParseDateTime(s string) (time.Time, error) { return Default().ParseDateTime(s) }
+
+// ParseLocalDateTime is Options.ParseLocalDateTime with the Default
+// options.
+func ParseLocalDateTime(s string) (time.Time, error) { return Default().ParseLocalDateTime(s) }
+
+// ParseDateTime parses an ISO 8601 / RFC 3339 datetime such as
+// 2024-03-20T12:00:00Z or 2024-03-20T13:00:00+01:00 and returns it in UTC.
+// A local time may instead name its IANA time zone in brackets, as in
+// 2024-03-20T13:00:00[Europe/Paris], or take o.Zone if it is set. A local
+// time that the zone's clocks skipped, or showed twice when they went
+// back, is rejected with a suggestion that settles it. One from before the
+// zone kept standard time is read in o.MeanTime, if it is set.
+//
+// A date alone, such as 2024-03-20, is read as a local time at
+// o.DefaultTime, or in UTC if o.Zone is not set. now is the current moment
+// and today is today's date; either may be followed by + or - and a
+// duration as ParseDuration takes it, as in now+3d or today-1w.
+func (o Options) ParseDateTime(s string) (time.Time, error) {
+	t, err := o.ParseLocalDateTime(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// ParseLocalDateTime is ParseDateTime without the conversion to UTC: the
+// time keeps the offset or zone it was given in.
+func (o Options) ParseLocalDateTime(s string) (time.Time, error) {
+	t, err := o.parseDateTime(s)
+	if err != nil {
+		if err.Suggestion == "" && err.Kind == "datetime" {
+			err.Suggestion = o.suggestDateTime(s)
+		}
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// IsLocal reports whether s is a local time with neither an offset nor a
+// bracketed zone, such as 2024-03-20T13:00:00, a date or today, which are
+// read in Options.Zone.
+func IsLocal(s string) bool {
+	if _, name := splitZone(s); name != "" {
+		return false
+	}
+	if _, err := time.Parse(localLayout, s); err == nil {
+		return true
+	}
+	if _, err := time.Parse(dateLayout, s); err == nil {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(s), "today")
+}
+
+// parseDateTime does the work of ParseLocalDateTime, without suggesting
+// repairs of the format.
+func (o Options) parseDateTime(s string) (time.Time, *Error) {
+	body, name := splitZone(s)
+	loc := o.Zone
+	if name != "" {
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil || name == "Local" {
+			return time.Time{}, &Error{Kind: "time zone", Value: name, Reason: "expected an IANA time zone name, e.g. Europe/Paris"}
+		}
+	}
+	if t, ok, err := o.parseRelative(s, body, loc, name); ok {
+		return t, err
+	}
+	if t, err := time.Parse(time.RFC3339, body); err == nil {
+		if name == "" {
+			return t, nil
+		}
+		// An offset given with a zone must be the zone's at that moment.
+		z := t.In(loc)
+		if _, off := z.Zone(); off != offsetOf(t) {
+			return time.Time{}, &Error{
+				Kind: "datetime", Value: s,
+				Reason:     fmt.Sprintf("offset %s is not that of %s at that time", t.Format("-07:00"), name),
+				Suggestion: z.Format(localLayout) + "[" + name + "]",
+			}
+		}
+		return z, nil
+	}
+	if wall, err := time.Parse(dateLayout, body); err == nil {
+		return o.readWall(s, wall.Add(o.DefaultTime), loc, name)
+	}
+	if loc != nil {
+		if wall, err := time.Parse(localLayout, body); err == nil {
+			return o.readWall(s, wall, loc, name)
+		}
+	}
+	reason := "expected RFC 3339 format, e.g. 2024-03-20T12:00:00Z, or a local time and its zone, e.g. 2024-03-20T13:00:00[Europe/Paris]"
+	if loc != nil {
+		reason = "expected RFC 3339 format, e.g. 2024-03-20T12:00:00Z, or a local time, e.g. 2024-03-20T12:00:00"
+	}
+	return time.Time{}, &Error{Kind: "datetime", Value: s, Reason: reason}
+}
+
+// parseRelative parses now and today, alone or followed by + or - and a
+// duration. ok is false if body is neither.
+func (o Options) parseRelative(s, body string, loc *time.Location, name string) (t time.Time, ok bool, e *Error) {
+	lower := strings.ToLower(body)
+	word := ""
+	for _, w := range []string{"now", "today"} {
+		if strings.HasPrefix(lower, w) {
+			word = w
+		}
+	}
+	if word == "" {
+		return time.Time{}, false, nil
+	}
+	var d time.Duration
+	if rest := lower[len(word):]; rest != "" {
+		var err error
+		if rest[0] == '+' || rest[0] == '-' {
+			d, err = parseDuration(rest[1:])
+		} else {
+			err = errDurationFormat
+		}
+		if err != nil {
+			e := &Error{Kind: "datetime", Value: s, Reason: "expected now or today, alone or followed by + or - and a duration, e.g. now+3d or today-1w"}
+			if fix := suggestDuration(rest[1:]); fix != "" && (rest[0] == '+' || rest[0] == '-') {
+				e.Suggestion = body[:len(word)] + rest[:1] + fix + zoneSuffix(name)
+			}
+			return time.Time{}, true, e
+		}
+		if rest[0] == '-' {
+			d = -d
+		}
+	}
+	if word == "now" {
+		return now().UTC().Add(d), true, nil
+	}
+	in := loc
+	if in == nil {
+		in = time.UTC
+	}
+	y, m, day := now().In(in).Date()
+	t, e = o.readWall(s, time.Date(y, m, day, 0, 0, 0, 0, time.UTC).Add(o.DefaultTime+d), loc, name)
+	return t, true, e
+}
+
+// readWall returns the instant at which the clocks of loc showed wall,
+// given in UTC, as resolveWall does, but in o.MeanTime before loc kept
+// standard time. With no loc, wall is UTC.
+func (o Options) readWall(s string, wall time.Time, loc *time.Location, name string) (time.Time, *Error) {
+	if loc == nil {
+		return wall, nil
+	}
+	t, err := resolveWall(s, wall, loc, name)
+	if abbr, _ := t.Zone(); err == nil && abbr == "LMT" && o.MeanTime != nil {
+		t = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), o.MeanTime)
+	}
+	return t, err
+}
+
+// zoneSuffix returns name in brackets, or "" if it is empty.
+func zoneSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "[" + name + "]"
+}
+
+// splitZone splits a trailing [Area/City] suffix from s.
+func splitZone(s string) (body, name string) {
+	if i := strings.LastIndexByte(s, '['); i >= 0 && strings.HasSuffix(s, "]") {
+		return s[:i], s[i+1 : len(s)-1]
+	}
+	return s, ""
+}
+
+// offsetOf returns t's offset from UTC in seconds.
+func offsetOf(t time.Time) int {
+	_, off := t.Zone()
+	return off
+}
+
+// resolveWall returns the instant at which the clocks of loc showed wall,
+// given in UTC. name is the zone's name if s gave it in brackets.
+func resolveWall(s string, wall time.Time, loc *time.Location, name string) (time.Time, *Error) {
+	suffix, zone := "", loc.String()
+	if name != "" {
+		suffix = "[" + name + "]"
+	}
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	if t.Format(localLayout) != wall.Format(localLayout) {
+		// time.Date moved a time in the gap forward by its length.
+		return time.Time{}, &Error{
+			Kind: "datetime", Value: s,
+			Reason:     fmt.Sprintf("%s does not exist in %s: the clocks went forward", wall.Format(localLayout), zone),
+			Suggestion: t.Format(localLayout) + suffix,
+		}
+	}
+	// The clocks may have shown wall twice, under the zone's offsets a day
+	// either side.
+	var at []time.Time
+	for _, d := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		u := wall.Add(-time.Duration(offsetOf(t.Add(d))) * time.Second).In(loc)
+		if u.Format(localLayout) == wall.Format(localLayout) && (len(at) == 0 || !u.Equal(at[len(at)-1])) {
+			at = append(at, u)
+		}
+	}
+	if len(at) > 1 {
+		return time.Time{}, &Error{
+			Kind: "datetime", Value: s,
+			Reason: fmt.Sprintf("%s is ambiguous in %s: the clocks went back and showed it at %s and at %s",
+				wall.Format(localLayout), zone, at[0].Format("-07:00"), at[1].Format("-07:00")),
+			Suggestion: at[0].Format(time.RFC3339) + suffix,
+		}
+	}
+	return t, nil
+}
+
+// suggestDateTime tries to repair the most common datetime mistakes: a
+// space or lowercase letter instead of the T separator, a missing seconds
+// field, a missing zone designator, or a bare date. A zone in brackets is
+// kept, and with one, or with o.Zone set, no designator is added.
+func (o Options) suggestDateTime(s string) string {
+	fixed, name := splitZone(strings.TrimSpace(s))
+	local := name != "" || o.Zone != nil
+	if len(fixed) >= 11 && (fixed[10] == ' ' || fixed[10] == 't') {
+		fixed = fixed[:10] + "T" + fixed[11:]
+	}
+	if strings.HasSuffix(fixed, "z") {
+		fixed = fixed[:len(fixed)-1] + "Z"
+	}
+	if len(fixed) == len("2006-01-02") {
+		fixed += "T12:00:00"
+	}
+
+	// Separate the zone designator (if any) from the clock part so a
+	// missing seconds field can be filled in.
+	body, zone := fixed, ""
+	if strings.HasSuffix(body, "Z") {
+		body, zone = body[:len(body)-1], "Z"
+	} else if len(body) > len("2006-01-02T15:04") {
+		if i := strings.LastIndexAny(body, "+-"); i > len("2006-01-02") {
+			body, zone = body[:i], body[i:]
+		}
+	}
+	if len(body) == len("2006-01-02T15:04") {
+		body += ":00"
+	}
+	if zone == "" && !local {
+		zone = "Z"
+	}
+	fixed = body + zone
+	if name != "" {
+		fixed += "[" + name + "]"
+	}
+
+	if fixed == s {
+		return ""
+	}
+	if _, err := o.parseDateTime(fixed); err != nil {
+		return ""
+	}
+	return fixed
+}
+
+// ParseDate parses a calendar date such as 2024-03-20 and returns midnight
+// UTC of that date.
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, &Error{Kind: "date", Value: s, Reason: "expected YYYY-MM-DD, e.g. 2024-03-20"}
+	}
+	return t, nil
+}
@@ -0,0 +1,113 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unitWords maps spelled-out units to the suffix ParseDuration expects.
+var unitWords = map[string]string{
+	"sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"min": "m", "mins": "m", "minute": "m", "minutes": "m",
+	"hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+	"day": "d", "days": "d",
+	"week": "w", "weeks": "w",
+}
+
+// ParseDuration parses a duration such as 1d, 6h, 90m or 1h30m. On top of
+// the units understood by time.ParseDuration it accepts d (24h) and w (7d)
+// as a leading component, e.g. 1w2d6h.
+func ParseDuration(s string) (time.Duration, error) {
+	d, err := parseDuration(s)
+	if err == nil {
+		return d, nil
+	}
+	e := &Error{Kind: "duration", Value: s, Reason: err.Error()}
+	e.Suggestion = suggestDuration(s)
+	return 0, e
+}
+
+type durationError string
+
+func (e durationError) Error() string { return string(e) }
+
+const (
+	errDurationFormat = durationError("expected a number followed by a unit (s, m, h, d, w), e.g. 1d or 90m")
+	errDurationRange  = durationError("duration too large")
+)
+
+const maxDuration = time.Duration(1<<63 - 1)
+
+func parseDuration(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	rest := strings.TrimPrefix(s, "-")
+	if rest == "" {
+		return 0, errDurationFormat
+	}
+
+	var total time.Duration
+	for _, unit := range []struct {
+		suffix byte
+		size   time.Duration
+	}{{'w', 7 * 24 * time.Hour}, {'d', 24 * time.Hour}} {
+		i := strings.IndexByte(rest, unit.suffix)
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil || n < 0 {
+			return 0, errDurationFormat
+		}
+		if n > int64(maxDuration/unit.size) {
+			return 0, errDurationRange
+		}
+		total += time.Duration(n) * unit.size
+		rest = rest[i+1:]
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return 0, errDurationFormat
+		}
+		if d > maxDuration-total {
+			return 0, errDurationRange
+		}
+		total += d
+	}
+	if neg {
+		total = -total
+	}
+	return total, nil
+}
+
+// suggestDuration repairs durations with spelled-out or upper-case units,
+// e.g. "2 days" or "1D".
+func suggestDuration(s string) string {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 1 {
+		// Split "2days" into number and unit.
+		f := fields[0]
+		i := strings.IndexFunc(f, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != '-' })
+		if i <= 0 {
+			fields = nil
+		} else {
+			fields = []string{f[:i], f[i:]}
+		}
+	}
+	if len(fields) != 2 {
+		return ""
+	}
+	unit, ok := unitWords[fields[1]]
+	if !ok {
+		unit = fields[1]
+	}
+	fixed := fields[0] + unit
+	if fixed == s {
+		return ""
+	}
+	if _, err := parseDuration(fixed); err != nil {
+		return ""
+	}
+	return fixed
+}
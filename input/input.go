@@ -0,0 +1,27 @@
+// Package input parses the free-form values accepted on the command line:
+// datetimes, geographic coordinates, durations and time ranges.
+//
+// Every parser returns an *Error on failure. Where the mistake is a common
+// one (a comma used as the decimal separator, a missing time zone, a unit
+// spelled out in full) the error carries a corrected Suggestion that is
+// itself guaranteed to parse. The parsers never panic on arbitrary input
+// and are covered by fuzz tests.
+package input
+
+import "fmt"
+
+// Error describes why an input value was rejected.
+type Error struct {
+	Kind       string // what was being parsed, e.g. "latitude"
+	Value      string // the raw input
+	Reason     string // why it was rejected
+	Suggestion string // a corrected value, if one could be guessed
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Value, e.Reason)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %s?)", e.Suggestion)
+	}
+	return msg
+}
@@ -0,0 +1,406 @@
+package input
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDateTime(t *testing.T) {
+	cases := []struct {
+		in         string
+		want       time.Time
+		wantErr    bool
+		suggestion string
+	}{
+		{"2024-03-20T12:00:00Z", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), false, ""},
+		{"2024-03-20T13:00:00+01:00", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), false, ""},
+		{"2024-03-20 12:00:00Z", time.Time{}, true, "2024-03-20T12:00:00Z"},
+		{"2024-03-20T12:00:00", time.Time{}, true, "2024-03-20T12:00:00Z"},
+		{"2024-03-20T12:00Z", time.Time{}, true, "2024-03-20T12:00:00Z"},
+		{"2024-03-20t12:00:00z", time.Time{}, true, "2024-03-20T12:00:00Z"},
+		{"2024-03-20", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), false, ""},
+		{"2024-03-20T12:00+01:00", time.Time{}, true, "2024-03-20T12:00:00+01:00"},
+		{"yesterday", time.Time{}, true, ""},
+		{"", time.Time{}, true, ""},
+		{"2024-03-20T13:00:00[Europe/Paris]", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), false, ""},
+		{"2024-07-01T13:00:00+02:00[Europe/Paris]", time.Date(2024, 7, 1, 11, 0, 0, 0, time.UTC), false, ""},
+		{"2024-07-01T13:00:00+01:00[Europe/Paris]", time.Time{}, true, "2024-07-01T14:00:00[Europe/Paris]"},
+		{"2024-03-20 13:00[Europe/Paris]", time.Time{}, true, "2024-03-20T13:00:00[Europe/Paris]"},
+		{"2024-03-20T13:00:00[Mars/Olympus]", time.Time{}, true, ""},
+		// The clocks of London skipped 01:30 on 31 March 2024 and showed it
+		// twice on 27 October.
+		{"2024-03-31T01:30:00[Europe/London]", time.Time{}, true, "2024-03-31T02:30:00[Europe/London]"},
+		{"2024-10-27T01:30:00[Europe/London]", time.Time{}, true, "2024-10-27T01:30:00+01:00[Europe/London]"},
+		{"2024-10-27T01:30:00+00:00[Europe/London]", time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC), false, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseDateTime(tc.in)
+			checkResult(t, err, tc.wantErr, tc.suggestion)
+			if !tc.wantErr && !got.Equal(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDateTimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := Options{Zone: ny, DefaultTime: 12 * time.Hour}
+
+	for _, tc := range []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-03-20T08:00:00", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)},
+		{"2024-01-20T08:00:00.5", time.Date(2024, 1, 20, 13, 0, 0, 5e8, time.UTC)},
+		// A zone designator or a zone in brackets wins over o.Zone.
+		{"2024-03-20T08:00:00Z", time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC)},
+		{"2024-03-20T08:00:00[Asia/Tokyo]", time.Date(2024, 3, 19, 23, 0, 0, 0, time.UTC)},
+	} {
+		got, err := o.ParseDateTime(tc.in)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseDateTime(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+		}
+	}
+	local, err := o.ParseLocalDateTime("2024-03-20T08:00:00")
+	if err != nil || local.Location() != ny || local.Hour() != 8 {
+		t.Errorf("ParseLocalDateTime = %v, %v; want 08:00 in New York", local, err)
+	}
+
+	// Repairs keep the time local rather than adding Z.
+	_, err = o.ParseDateTime("2024-03-20 08:00")
+	checkResult(t, err, true, "2024-03-20T08:00:00")
+
+	// Other Options, and the package's functions, are not affected.
+	if _, err := ParseDateTime("2024-03-20T08:00:00"); err == nil {
+		t.Error("ParseDateTime read a local time without a zone")
+	}
+}
+
+func TestParseDateTimeMeanTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := Options{Zone: berlin}
+
+	// Without MeanTime, the database's local mean time of Berlin applies.
+	got, err := o.ParseDateTime("1880-01-01T12:00:00")
+	if want := time.Date(1880, 1, 1, 11, 6, 32, 0, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("ParseDateTime = %v, %v; want %v", got, err, want)
+	}
+
+	o.MeanTime = MeanTimeAt(11.575) // Munich, 0:46:18 east
+	for _, tc := range []struct {
+		in   string
+		want time.Time
+	}{
+		{"1880-01-01T12:00:00", time.Date(1880, 1, 1, 11, 13, 42, 0, time.UTC)},
+		{"1880-01-01T12:00:00[America/New_York]", time.Date(1880, 1, 1, 11, 13, 42, 0, time.UTC)},
+		// Standard time, and a historical offset the database names, stand.
+		{"1900-01-01T12:00:00", time.Date(1900, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"1900-01-01T12:00:00[Europe/Dublin]", time.Date(1900, 1, 1, 12, 25, 21, 0, time.UTC)},
+		{"1880-01-01T12:00:00+01:00", time.Date(1880, 1, 1, 11, 0, 0, 0, time.UTC)},
+	} {
+		got, err := o.ParseDateTime(tc.in)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseDateTime(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseDateTimeRelative(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = func() time.Time { return time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC) } // 01:30 on 2 June in Paris
+	defer func() { now = time.Now }()
+
+	for _, tc := range []struct {
+		in         string
+		zone       *time.Location
+		want       time.Time
+		suggestion string // "" for no error
+	}{
+		{"now", nil, time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC), ""},
+		{"NOW+3d", nil, time.Date(2025, 6, 4, 23, 30, 0, 0, time.UTC), ""},
+		{"now-1h30m", paris, time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC), ""},
+		{"today", nil, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), ""},
+		{"today", paris, time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC), ""},
+		{"today+1w", paris, time.Date(2025, 6, 9, 10, 0, 0, 0, time.UTC), ""},
+		{"today[Asia/Tokyo]", nil, time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC), ""},
+		{"2025-01-15", paris, time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC), ""},
+		{"2025-01-15[Asia/Tokyo]", nil, time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC), ""},
+		{"now+3days", nil, time.Time{}, "now+3d"},
+		{"Today - 2 weeks", nil, time.Time{}, ""},
+		{"nowish", nil, time.Time{}, ""},
+	} {
+		got, err := Options{Zone: tc.zone, DefaultTime: 12 * time.Hour}.ParseDateTime(tc.in)
+		if tc.want.IsZero() {
+			checkResult(t, err, true, tc.suggestion)
+		} else if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseDateTime(%q) in %v = %v, %v; want %v", tc.in, tc.zone, got, err, tc.want)
+		}
+	}
+
+	o := Options{DefaultTime: 6*time.Hour + 30*time.Minute}
+	if got, err := o.ParseDateTime("2025-01-15"); err != nil || !got.Equal(time.Date(2025, 1, 15, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseDateTime(2025-01-15) at 06:30 = %v, %v", got, err)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	for in, want := range map[string]bool{
+		"2024-03-20T13:00:00":               true,
+		"2024-03-20T13:00:00.25":            true,
+		"2024-03-20T13:00:00Z":              false,
+		"2024-03-20T13:00:00+01:00":         false,
+		"2024-03-20T13:00:00[Europe/Paris]": false,
+		"2024-03-20":                        true,
+		"today":                             true,
+		"Today-1d":                          true,
+		"now":                               false,
+		"2024-03-20[Europe/Paris]":          false,
+		"2024-03-20 13:00":                  false,
+		"":                                  false,
+	} {
+		if got := IsLocal(in); got != want {
+			t.Errorf("IsLocal(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	got, err := ParseDate("2024-03-20")
+	if err != nil || !got.Equal(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDate(\"2024-03-20\") = %v, %v", got, err)
+	}
+	for _, in := range []string{"2024-03-20T12:00:00Z", "20/03/2024", ""} {
+		if _, err := ParseDate(in); err == nil {
+			t.Errorf("ParseDate(%q): expected error", in)
+		}
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	cases := []struct {
+		in         string
+		lon        bool
+		want       float64
+		wantErr    bool
+		suggestion string
+	}{
+		{"51.5074", false, 51.5074, false, ""},
+		{"-0.1278", true, -0.1278, false, ""},
+		{"51,5074", false, 0, true, "51.5074"},
+		{"51.5074N", false, 51.5074, false, ""},
+		{"33.87S", false, -33.87, false, ""},
+		{"0.1278 W", true, -0.1278, false, ""},
+		{"51N30", false, 51.5, false, ""},
+		{"0W07:30", true, -0.125, false, ""},
+		{"48°51'N", false, 48.85, false, ""},
+		{"−74.006", true, 0, true, "-74.006"},
+		{"51,5074N", false, 0, true, "51.5074"},
+		{"0,1278W", true, 0, true, "-0.1278"},
+		{"95,5", false, 0, true, ""},
+		{"95.5", false, 0, true, ""},
+		{"0W07", false, 0, true, ""},
+		{"NaN", false, 0, true, ""},
+		{"Inf", true, 0, true, ""},
+		{"north", false, 0, true, ""},
+		{"200", true, 0, true, "-160"},
+		{"359.5", true, 0, true, "-0.5"},
+		{"360.5", true, 0, true, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			parse := ParseLatitude
+			if tc.lon {
+				parse = ParseLongitude
+			}
+			got, err := parse(tc.in)
+			checkResult(t, err, tc.wantErr, tc.suggestion)
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCoordinatesSwapped(t *testing.T) {
+	cases := []struct {
+		lat, lon   string
+		wantErr    bool
+		suggestion string
+	}{
+		{"51.5074", "-0.1278", false, ""},
+		{"100", "40", true, "40 100"},
+		{"0W07", "51N30", true, "51N30 0W07"},
+		{"0 W 07", "51N30", true, ""}, // a space: no suggestion
+		{"100", "200", true, ""},      // wrong either way
+		{"40", "200", true, "-160"},   // the longitude's own error
+	}
+	for _, tc := range cases {
+		t.Run(tc.lat+" "+tc.lon, func(t *testing.T) {
+			lat, lon, err := ParseCoordinates(tc.lat, tc.lon)
+			checkResult(t, err, tc.wantErr, tc.suggestion)
+			if !tc.wantErr && (lat != 51.5074 || lon != -0.1278) {
+				t.Errorf("got %v, %v", lat, lon)
+			}
+			if tc.suggestion != "" && strings.Contains(tc.suggestion, " ") {
+				f := strings.Fields(tc.suggestion)
+				if _, _, err := ParseCoordinates(f[0], f[1]); err != nil {
+					t.Errorf("suggestion %q does not parse: %v", tc.suggestion, err)
+				}
+				if !strings.Contains(err.Error(), "the latitude comes first") {
+					t.Errorf("error %q does not say which comes first", err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		in         string
+		want       time.Duration
+		wantErr    bool
+		suggestion string
+	}{
+		{"1d", 24 * time.Hour, false, ""},
+		{"1w2d", 9 * 24 * time.Hour, false, ""},
+		{"1d12h", 36 * time.Hour, false, ""},
+		{"90m", 90 * time.Minute, false, ""},
+		{"-2h", -2 * time.Hour, false, ""},
+		{"0", 0, false, ""},
+		{"2 days", 0, true, "2d"},
+		{"1D", 0, true, "1d"},
+		{"30mins", 0, true, "30m"},
+		{"", 0, true, ""},
+		{"-", 0, true, ""},
+		{"d", 0, true, ""},
+		{"99999999w", 0, true, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseDuration(tc.in)
+			checkResult(t, err, tc.wantErr, tc.suggestion)
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantErr    bool
+		suggestion string
+	}{
+		{"2025-01-01T00:00:00Z..2025-12-31T00:00:00Z", false, ""},
+		{"2025-01-01T00:00:00Z/2025-12-31T00:00:00Z", false, ""},
+		{"2025-12-31T00:00:00Z..2025-01-01T00:00:00Z", true, "2025-01-01T00:00:00Z..2025-12-31T00:00:00Z"},
+		{"2025-01-01..2025-12-31T00:00:00Z", false, ""},
+		{"2025-01-01..2025-12-31T00:00Z", true, "2025-01-01..2025-12-31T00:00:00Z"},
+		{"2025-01-01T00:00:00Z", true, ""},
+		{"2025-01-01T00:00:00[Europe/Paris]/2025-12-31T00:00:00[Europe/Paris]", false, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := ParseTimeRange(tc.in)
+			checkResult(t, err, tc.wantErr, tc.suggestion)
+		})
+	}
+}
+
+func checkResult(t *testing.T, err error, wantErr bool, suggestion string) {
+	t.Helper()
+	if !wantErr {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("error = %v, want *Error", err)
+	}
+	if e.Suggestion != suggestion {
+		t.Errorf("suggestion = %q, want %q", e.Suggestion, suggestion)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Fuzz tests
+//
+// Each parser must never panic, must only return *Error on failure, and any
+// suggestion it offers must itself parse cleanly.
+// ---------------------------------------------------------------------------
+
+func FuzzParseDateTime(f *testing.F) {
+	for _, s := range []string{"2024-03-20T12:00:00Z", "2024-03-20 12:00", "2024-03-20", "2024-03-20T12:00+01:00", "z", "2024-10-27T01:30[Europe/London]"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		_, err := ParseDateTime(s)
+		checkSuggestion(t, err, func(fix string) error { _, err := ParseDateTime(fix); return err })
+	})
+}
+
+func FuzzParseLatitude(f *testing.F) {
+	for _, s := range []string{"51.5074", "51,5074", "33.87S", "−1", "40.7°N", "1e308", "0x1p-2"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := ParseLatitude(s)
+		if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			t.Fatalf("ParseLatitude(%q) = %v, want finite", s, v)
+		}
+		checkSuggestion(t, err, func(fix string) error { _, err := ParseLatitude(fix); return err })
+	})
+}
+
+func FuzzParseDuration(f *testing.F) {
+	for _, s := range []string{"1d", "1w2d3h", "2 days", "1D", "-90m", "5000000w"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		_, err := ParseDuration(s)
+		checkSuggestion(t, err, func(fix string) error { _, err := ParseDuration(fix); return err })
+	})
+}
+
+func FuzzParseTimeRange(f *testing.F) {
+	for _, s := range []string{"2025-01-01T00:00:00Z..2025-12-31T00:00:00Z", "2025-01-01/2025-02-01", ".."} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		_, err := ParseTimeRange(s)
+		checkSuggestion(t, err, func(fix string) error { _, err := ParseTimeRange(fix); return err })
+	})
+}
+
+func checkSuggestion(t *testing.T, err error, parse func(string) error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("error %v is %T, want *Error", err, err)
+	}
+	if e.Suggestion != "" {
+		if perr := parse(e.Suggestion); perr != nil {
+			t.Fatalf("suggestion %q for %q does not parse: %v", e.Suggestion, e.Value, perr)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package input
+
+import (
+	"strings"
+	"time"
+)
+
+// TimeRange is a closed interval of UTC instants.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseTimeRange parses two datetimes separated by ".." or "/" (the ISO 8601
+// interval separator), e.g. 2025-01-01T00:00:00Z..2025-12-31T00:00:00Z.
+// The end must not precede the start. The datetimes are read with the
+// Default options.
+func ParseTimeRange(s string) (TimeRange, error) { return Default().ParseTimeRange(s) }
+
+// ParseTimeRange is the package's ParseTimeRange, reading the datetimes
+// with o.
+func (o Options) ParseTimeRange(s string) (TimeRange, error) {
+	from, to, sep, ok := cutRange(s)
+	if !ok {
+		return TimeRange{}, &Error{Kind: "time range", Value: s, Reason: "expected <from>..<to>"}
+	}
+
+	var r TimeRange
+	var err error
+	if r.From, err = o.ParseDateTime(from); err != nil {
+		return TimeRange{}, o.rangeError(s, "start", err, func(fix string) string { return fix + sep + to })
+	}
+	if r.To, err = o.ParseDateTime(to); err != nil {
+		return TimeRange{}, o.rangeError(s, "end", err, func(fix string) string { return from + sep + fix })
+	}
+	if r.To.Before(r.From) {
+		return TimeRange{}, &Error{
+			Kind:       "time range",
+			Value:      s,
+			Reason:     "end is before start",
+			Suggestion: to + sep + from,
+		}
+	}
+	return r, nil
+}
+
+// rangeError wraps an endpoint parse error, lifting its suggestion into a
+// suggestion for the whole range when the repaired range is valid.
+func (o Options) rangeError(s, which string, err error, rebuild func(string) string) error {
+	ie := err.(*Error)
+	e := &Error{Kind: "time range", Value: s, Reason: which + ": " + ie.Reason}
+	if ie.Suggestion != "" && o.validRange(rebuild(ie.Suggestion)) {
+		e.Suggestion = rebuild(ie.Suggestion)
+	}
+	return e
+}
+
+// cutRange splits s at "..", or else at the first "/" outside the
+// brackets of a time zone such as [Europe/Paris].
+func cutRange(s string) (from, to, sep string, ok bool) {
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		return from, to, "..", true
+	}
+	depth := 0
+	for i, c := range s {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case c == '/' && depth == 0:
+			return s[:i], s[i+1:], "/", true
+		}
+	}
+	return "", "", "", false
+}
+
+// validRange reports whether s parses as a range without any repair.
+func (o Options) validRange(s string) bool {
+	from, to, _, ok := cutRange(s)
+	if !ok {
+		return false
+	}
+	f, err := o.parseDateTime(from)
+	if err != nil {
+		return false
+	}
+	t, err := o.parseDateTime(to)
+	return err == nil && !t.Before(f)
+}
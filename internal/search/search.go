@@ -0,0 +1,195 @@
+// Package search finds when things happen: the moments a function of time,
+// such as a planet's distance from a degree or its speed, passes through
+// zero. A scan samples the function at steps short enough that no event
+// can fall between two samples unseen, and Brent's method narrows each
+// bracketed sign change to Precision.
+//
+// Angular functions are offsets wrapped to [-180, 180), as Offset makes
+// them, so their sign also flips on the far side of the circle, where the
+// offset jumps by nearly 360°; Crossed tells the two apart.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// Precision is the width in days to which Root narrows an event (about a
+// second).
+const Precision = 1.0 / 86400
+
+// maxIter bounds Root; Brent's method never needs more than a bisection
+// would, about 40 steps from a day to Precision.
+const maxIter = 100
+
+// Func is a function of the Julian Day (UT), such as a longitude.
+type Func func(jd float64) (float64, error)
+
+// Stop, returned by the function passed to Scan, ends the scan early
+// without an error.
+var Stop = errors.New("stop the scan")
+
+// Offset returns the function f minus target, wrapped to [-180, 180): zero
+// when f reaches target.
+func Offset(f Func, target float64) Func {
+	return func(jd float64) (float64, error) {
+		v, err := f(jd)
+		if err != nil {
+			return 0, err
+		}
+		return angle.Diff(v, target), nil
+	}
+}
+
+// Crossed reports whether a function sampled v0 then v1 passed through
+// zero between them: their signs differ, and, for an angular offset, not
+// by the jump of nearly 360° at the far side of the circle.
+func Crossed(v0, v1 float64) bool {
+	return (v0 < 0) != (v1 < 0) && math.Abs(v1-v0) < 180
+}
+
+// Root returns the moment between a and b at which f passes through zero,
+// to within Precision, given its values fa and fb there, whose signs must
+// differ. It uses Brent's method, which interpolates where f is smooth and
+// bisects where it is not, so it needs fewer calls of f than bisection.
+func Root(f Func, a, b, fa, fb float64) (float64, error) {
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if (fa < 0) == (fb < 0) {
+		return 0, fmt.Errorf("search: no sign change between JD %.6f and %.6f", a, b)
+	}
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for range maxIter {
+		if (fb < 0) == (fc < 0) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol := 2*0x1p-52*math.Abs(b) + Precision/2
+		m := (c - b) / 2
+		if math.Abs(m) <= tol || fb == 0 {
+			return b, nil
+		}
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// Secant or inverse quadratic interpolation, if it lands well
+			// inside the bracket.
+			s := fb / fa
+			var p, q float64
+			if a == c {
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d, e = m, m
+			}
+		} else {
+			d, e = m, m
+		}
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		var err error
+		if fb, err = f(b); err != nil {
+			return 0, err
+		}
+	}
+	return b, nil
+}
+
+// Scan samples x every step days from from to to, the last step cut short
+// at to, and calls interval with each pair of neighbouring samples, so that
+// one sample serves every event a caller looks for in it. It stops at the
+// first error, or when interval returns Stop.
+func Scan(x Func, from, to, step float64, interval func(t0, x0, t1, x1 float64) error) error {
+	t0 := from
+	x0, err := x(t0)
+	if err != nil {
+		return err
+	}
+	for t0 < to {
+		t1 := math.Min(t0+step, to)
+		x1, err := x(t1)
+		if err != nil {
+			return err
+		}
+		if err := interval(t0, x0, t1, x1); err != nil {
+			if err == Stop {
+				return nil
+			}
+			return err
+		}
+		t0, x0 = t1, x1
+	}
+	return nil
+}
+
+// Roots returns the moments within [from, to] when f passes through zero,
+// sampling it every step days, in order.
+func Roots(f Func, from, to, step float64) ([]float64, error) {
+	var roots []float64
+	err := Scan(f, from, to, step, func(t0, v0, t1, v1 float64) error {
+		if !Crossed(v0, v1) {
+			return nil
+		}
+		jd, err := Root(f, t0, t1, v0, v1)
+		roots = append(roots, jd)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// Next returns the first moment after from when f passes through zero,
+// stepping step days at a time, backwards if step is negative, for at most
+// limit days. ok is false if there is none within the limit.
+func Next(f Func, from, step, limit float64) (jd float64, ok bool, err error) {
+	t0 := from
+	v0, err := f(t0)
+	if err != nil {
+		return 0, false, err
+	}
+	for range int(math.Ceil(limit / math.Abs(step))) {
+		t1 := t0 + step
+		v1, err := f(t1)
+		if err != nil {
+			return 0, false, err
+		}
+		if Crossed(v0, v1) {
+			jd, err := Root(f, t0, t1, v0, v1)
+			return jd, err == nil, err
+		}
+		t0, v0 = t1, v1
+	}
+	return 0, false, nil
+}
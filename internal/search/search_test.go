@@ -0,0 +1,104 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	calls := 0
+	f := func(x float64) (float64, error) {
+		calls++
+		return x*x*x - 2*x - 5, nil // root near 2.0945515
+	}
+	fa, _ := f(2)
+	fb, _ := f(3)
+	calls = 0
+	got, err := Root(f, 2, 3, fa, fb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2.0945514815423265; math.Abs(got-want) > Precision {
+		t.Errorf("Root = %v, want %v", got, want)
+	}
+	// Bisection would take 17 steps to narrow a day to a second.
+	if calls > 10 {
+		t.Errorf("Root made %d calls, want few", calls)
+	}
+
+	if _, err := Root(f, 3, 4, 16, 51); err == nil {
+		t.Error("Root without a sign change: no error")
+	}
+	bad := errors.New("bad")
+	if _, err := Root(func(float64) (float64, error) { return 0, bad }, 2, 3, fa, fb); !errors.Is(err, bad) {
+		t.Errorf("Root error %v, want %v", err, bad)
+	}
+}
+
+// lon moves a degree a day from 350°, wrapping past 0° on day 10.
+func lon(jd float64) (float64, error) { return math.Mod(350+jd, 360), nil }
+
+func TestRoots(t *testing.T) {
+	// Through 0° on day 10 and 90° on day 100; the offset from 90° wraps
+	// on day 280 without a root.
+	for _, c := range []struct {
+		target float64
+		want   []float64
+	}{
+		{0, []float64{10}},
+		{90, []float64{100}},
+	} {
+		got, err := Roots(Offset(lon, c.target), 0, 300, 1.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("Roots of %v° = %v, want %v", c.target, got, c.want)
+		}
+		for i := range got {
+			if math.Abs(got[i]-c.want[i]) > Precision {
+				t.Errorf("Roots of %v° = %v, want %v", c.target, got, c.want)
+			}
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	f := Offset(lon, 40)
+	if jd, ok, err := Next(f, 0, 1, 100); err != nil || !ok || math.Abs(jd-50) > Precision {
+		t.Errorf("Next forwards = %v, %v, %v; want 50", jd, ok, err)
+	}
+	if jd, ok, err := Next(f, 80, -1, 100); err != nil || !ok || math.Abs(jd-50) > Precision {
+		t.Errorf("Next backwards = %v, %v, %v; want 50", jd, ok, err)
+	}
+	if _, ok, err := Next(f, 0, 1, 20); ok || err != nil {
+		t.Errorf("Next within 20 days: ok %v, err %v; want neither", ok, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	var steps [][2]float64
+	err := Scan(lon, 0, 10, 4, func(t0, x0, t1, x1 float64) error {
+		steps = append(steps, [2]float64{t0, t1})
+		if t1 >= 8 {
+			return Stop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(steps) != 2 || steps[1] != [2]float64{4, 8} {
+		t.Errorf("Scan intervals = %v, want [0 4] [4 8] then stop", steps)
+	}
+
+	steps = nil
+	Scan(lon, 0, 10, 4, func(t0, x0, t1, x1 float64) error {
+		steps = append(steps, [2]float64{t0, t1})
+		return nil
+	})
+	if len(steps) != 3 || steps[2] != [2]float64{8, 10} {
+		t.Errorf("Scan intervals = %v, want the last cut short at 10", steps)
+	}
+}
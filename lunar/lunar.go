@@ -0,0 +1,101 @@
+// Package lunar describes the condition of the Moon that electional and
+// horary astrology weigh: its phase, and the next aspect it will perfect
+// before leaving its sign.
+package lunar
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// VoidPlanets are the planets whose aspects count against a void of course
+// Moon: the classical planets other than the Moon itself.
+var VoidPlanets = []int{
+	ephemeris.Sun, ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars,
+	ephemeris.Jupiter, ephemeris.Saturn,
+}
+
+// Waxing reports whether a Moon at longitude moon is waxing, from new to
+// full, when the Sun is at sun.
+func Waxing(moon, sun float64) bool {
+	d := angle.Norm360(moon - sun)
+	return d > 0 && d < 180
+}
+
+// phaseNames are the eight phases of the Moon, each centred on a multiple
+// of 45° of elongation.
+var phaseNames = [8]string{
+	"new moon", "waxing crescent", "first quarter", "waxing gibbous",
+	"full moon", "waning gibbous", "last quarter", "waning crescent",
+}
+
+// PhaseName returns the name of the phase of a Moon at longitude moon when
+// the Sun is at sun, one of eight: new moon within 22.5° of the Sun, then
+// waxing crescent, first quarter, and so on.
+func PhaseName(moon, sun float64) string {
+	return phaseNames[int(angle.Norm360(moon-sun+22.5)/45)%8]
+}
+
+// Illumination returns the fraction of the Moon's disc that is lit, 0 at
+// new moon to 1 at full, from its elongation from the Sun.
+func Illumination(moon, sun float64) float64 {
+	return (1 - math.Cos((moon-sun)*math.Pi/180)) / 2
+}
+
+// Perfection is an aspect the Moon is applying to.
+type Perfection struct {
+	Body   int
+	Aspect aspects.Aspect
+	Days   float64 // until the aspect is exact
+}
+
+// NextAspect returns the first major aspect the Moon will perfect with any
+// of planets before it leaves its sign. ok is false if there is none: the
+// Moon is void of course. Motions are extrapolated linearly from the given
+// positions, which over the two and a half days at most the Moon spends in
+// a sign is good to a few minutes.
+func NextAspect(moon ephemeris.PlanetPos, planets map[int]ephemeris.PlanetPos) (p Perfection, ok bool) {
+	if moon.SpeedLon <= 0 {
+		return Perfection{}, false
+	}
+	left := (30 - math.Mod(angle.Norm360(moon.Longitude), 30)) / moon.SpeedLon
+
+	bodies := make([]int, 0, len(planets))
+	for body := range planets {
+		bodies = append(bodies, body)
+	}
+	sort.Ints(bodies)
+
+	p.Days = math.Inf(1)
+	for _, body := range bodies {
+		pos := planets[body]
+		rel := moon.SpeedLon - pos.SpeedLon
+		if rel <= 0 {
+			continue
+		}
+		sep := angle.Norm360(moon.Longitude - pos.Longitude)
+		for _, a := range aspects.Major {
+			for _, target := range []float64{a.Angle, 360 - a.Angle} {
+				days := angle.Norm360(target-sep) / rel
+				if days < left && days < p.Days {
+					p, ok = Perfection{Body: body, Aspect: a, Days: days}, true
+				}
+			}
+		}
+	}
+	if !ok {
+		return Perfection{}, false
+	}
+	return p, true
+}
+
+// VoidOfCourse reports whether the Moon will perfect no major aspect with
+// any of planets before it leaves its sign; see NextAspect.
+func VoidOfCourse(moon ephemeris.PlanetPos, planets map[int]ephemeris.PlanetPos) bool {
+	_, ok := NextAspect(moon, planets)
+	return !ok
+}
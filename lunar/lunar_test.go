@@ -0,0 +1,80 @@
+package lunar_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/lunar"
+)
+
+func TestWaxing(t *testing.T) {
+	tests := []struct {
+		moon, sun float64
+		want      bool
+	}{
+		{10, 0, true},
+		{179, 0, true},
+		{181, 0, false},
+		{350, 0, false},
+		{5, 350, true},
+		{0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := lunar.Waxing(tt.moon, tt.sun); got != tt.want {
+			t.Errorf("Waxing(%v, %v) = %v, want %v", tt.moon, tt.sun, got, tt.want)
+		}
+	}
+}
+
+func TestNextAspect(t *testing.T) {
+	// The Moon at 20° Aries, 10 degrees from leaving the sign at 12.5°/day:
+	// 0.8 days.
+	moon := ephemeris.PlanetPos{Longitude: 20, SpeedLon: 12.5}
+
+	// Saturn at 22° Cancer, slow: the square perfects after 2/12.5 days.
+	planets := map[int]ephemeris.PlanetPos{
+		ephemeris.Saturn: {Longitude: 112, SpeedLon: 0},
+		ephemeris.Venus:  {Longitude: 195, SpeedLon: 1.2}, // opposition already past
+	}
+	p, ok := lunar.NextAspect(moon, planets)
+	if !ok {
+		t.Fatal("NextAspect found nothing, want the square to Saturn")
+	}
+	if p.Body != ephemeris.Saturn || p.Aspect.Name != "square" {
+		t.Errorf("next aspect %s to %d, want square to Saturn", p.Aspect.Name, p.Body)
+	}
+	if math.Abs(p.Days-2/12.5) > 1e-9 {
+		t.Errorf("Days = %v, want %v", p.Days, 2/12.5)
+	}
+
+	// Saturn beyond reach before the sign change: void of course.
+	planets[ephemeris.Saturn] = ephemeris.PlanetPos{Longitude: 125, SpeedLon: 0}
+	if !lunar.VoidOfCourse(moon, planets) {
+		p, _ := lunar.NextAspect(moon, planets)
+		t.Errorf("not void of course: %s to %d in %v days", p.Aspect.Name, p.Body, p.Days)
+	}
+}
+
+func TestPhase(t *testing.T) {
+	tests := []struct {
+		moon, sun float64
+		name      string
+		lit       float64
+	}{
+		{10, 0, "new moon", 0.0076},
+		{45, 0, "waxing crescent", 0.1464},
+		{95, 5, "first quarter", 0.5},
+		{180, 0, "full moon", 1},
+		{185, 275, "last quarter", 0.5},
+		{350, 20, "waning crescent", 0.0670},
+	}
+	for _, tt := range tests {
+		if got := lunar.PhaseName(tt.moon, tt.sun); got != tt.name {
+			t.Errorf("PhaseName(%v, %v) = %q, want %q", tt.moon, tt.sun, got, tt.name)
+		}
+		if got := lunar.Illumination(tt.moon, tt.sun); math.Abs(got-tt.lit) > 1e-4 {
+			t.Errorf("Illumination(%v, %v) = %.4f, want %.4f", tt.moon, tt.sun, got, tt.lit)
+		}
+	}
+}
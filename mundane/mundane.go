@@ -0,0 +1,480 @@
+// Package mundane finds the events of the sky itself, without reference to
+// any chart: the lunations and the eclipses among them, the planets'
+// ingresses into the signs, their stations, and the aspects between them.
+package mundane
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// Kind distinguishes mundane events.
+type Kind int
+
+const (
+	Lunation Kind = iota // the Sun and Moon at a phase angle of a quarter
+	Ingress              // a body enters a sign
+	Station              // a planet turns retrograde or direct
+	Aspect               // two bodies form an exact aspect
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Lunation:
+		return "lunation"
+	case Ingress:
+		return "ingress"
+	case Station:
+		return "station"
+	case Aspect:
+		return "aspect"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Phase is one of the four lunar phases a lunation marks.
+type Phase int
+
+const (
+	NewMoon Phase = iota
+	FirstQuarter
+	FullMoon
+	LastQuarter
+)
+
+func (p Phase) String() string {
+	switch p {
+	case NewMoon:
+		return "new moon"
+	case FirstQuarter:
+		return "first quarter"
+	case FullMoon:
+		return "full moon"
+	case LastQuarter:
+		return "last quarter"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// Eclipse is the kind of eclipse at a new or full moon.
+type Eclipse int
+
+const (
+	NoEclipse Eclipse = iota
+	PartialSolar
+	AnnularSolar
+	TotalSolar
+	PenumbralLunar
+	PartialLunar
+	TotalLunar
+)
+
+func (e Eclipse) String() string {
+	switch e {
+	case NoEclipse:
+		return ""
+	case PartialSolar:
+		return "partial solar eclipse"
+	case AnnularSolar:
+		return "annular solar eclipse"
+	case TotalSolar:
+		return "total solar eclipse"
+	case PenumbralLunar:
+		return "penumbral lunar eclipse"
+	case PartialLunar:
+		return "partial lunar eclipse"
+	case TotalLunar:
+		return "total lunar eclipse"
+	}
+	return fmt.Sprintf("Eclipse(%d)", int(e))
+}
+
+// Event is one mundane event.
+type Event struct {
+	JD   float64
+	Kind Kind
+	// Body is the body that enters a sign, stations or forms an aspect;
+	// the Moon for a lunation.
+	Body    int
+	Other   int            // of an aspect: the other body
+	Aspect  aspects.Aspect // of an aspect
+	Phase   Phase          // of a lunation
+	Eclipse Eclipse        // at a new or full moon, if any
+	Sign    int            // of an ingress: the sign entered, 0 for Aries to 11 for Pisces
+	// Retrograde is set for an ingress made moving backwards, and for a
+	// station at which the body turns retrograde.
+	Retrograde bool
+	// Supermoon is set for a new or full moon near perigee, by Supermoons.
+	Supermoon bool
+	Longitude float64 // of Body at JD
+}
+
+// Scan returns the lunations, and the ingresses and stations of bodies,
+// within the Julian Day range [from, to], in chronological order. The Sun
+// and Moon never station and are skipped for stations; lunations are
+// always included.
+func Scan(p ephemeris.Provider, bodies []int, from, to float64) ([]Event, error) {
+	events, err := Lunations(p, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, body := range bodies {
+		found, err := Ingresses(p, body, from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, found...)
+		if body == ephemeris.Sun || body == ephemeris.Moon {
+			continue
+		}
+		if found, err = Stations(p, body, from, to); err != nil {
+			return nil, err
+		}
+		events = append(events, found...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].JD < events[j].JD })
+	return events, nil
+}
+
+// Lunations returns the new moons, first quarters, full moons and last
+// quarters within [from, to], each new and full moon with the eclipse it
+// brings, if any.
+func Lunations(p ephemeris.Provider, from, to float64) ([]Event, error) {
+	elongation := func(jd float64) (float64, error) {
+		sun, err := calc(p, jd, ephemeris.Sun)
+		if err != nil {
+			return 0, err
+		}
+		moon, err := calc(p, jd, ephemeris.Moon)
+		if err != nil {
+			return 0, err
+		}
+		return angle.Norm360(moon.Longitude - sun.Longitude), nil
+	}
+
+	// The elongation grows by about 12° a day; half a day cannot pass two
+	// phases.
+	const h = 0.5
+	var events []Event
+	err := search.Scan(elongation, from, to, h, func(t0, e0, t1, e1 float64) error {
+		for phase := NewMoon; phase <= LastQuarter; phase++ {
+			target := float64(phase) * 90
+			d0, d1 := angle.Diff(e0, target), angle.Diff(e1, target)
+			if !search.Crossed(d0, d1) {
+				continue
+			}
+			jd, err := search.Root(search.Offset(elongation, target), t0, t1, d0, d1)
+			if err != nil {
+				return err
+			}
+			ev, err := lunation(p, jd, phase)
+			if err != nil {
+				return err
+			}
+			events = append(events, ev)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// lunation returns the lunation of phase at jd, with its eclipse.
+func lunation(p ephemeris.Provider, jd float64, phase Phase) (Event, error) {
+	sun, err := calc(p, jd, ephemeris.Sun)
+	if err != nil {
+		return Event{}, err
+	}
+	moon, err := calc(p, jd, ephemeris.Moon)
+	if err != nil {
+		return Event{}, err
+	}
+	ev := Event{JD: jd, Kind: Lunation, Body: ephemeris.Moon, Phase: phase, Longitude: moon.Longitude}
+	switch phase {
+	case NewMoon:
+		ev.Eclipse = SolarEclipse(sun, moon)
+	case FullMoon:
+		ev.Eclipse = LunarEclipse(sun, moon)
+	}
+	return ev, nil
+}
+
+const (
+	earthRadius = 6378.137      // km
+	moonRadius  = 1737.4        // km
+	sunRadius   = 695700        // km
+	au          = 1.495978707e8 // km
+	// cosInclination is the cosine of the inclination of the Moon's path
+	// relative to the Sun, about 5.3°, which turns the Moon's latitude at
+	// syzygy into the least distance between the centres.
+	cosInclination = 0.9957
+	// shadowEnlargement widens the Earth's shadow for its atmosphere, as
+	// Danjon's rule has it.
+	shadowEnlargement = 1.02
+)
+
+// apparent returns the Sun's and Moon's horizontal parallaxes and
+// semidiameters, in degrees, from their distances in AU.
+func apparent(sun, moon ephemeris.PlanetPos) (piSun, piMoon, sSun, sMoon float64) {
+	angle := func(radius, dist float64) float64 { return math.Asin(radius/(dist*au)) * 180 / math.Pi }
+	return angle(earthRadius, sun.Distance), angle(earthRadius, moon.Distance),
+		angle(sunRadius, sun.Distance), angle(moonRadius, moon.Distance)
+}
+
+// SolarEclipse returns the solar eclipse, seen from somewhere on the Earth,
+// at a new moon with the Sun and Moon at sun and moon, or NoEclipse. It
+// compares the least distance between their centres, from the Moon's
+// latitude, with the parallaxes and semidiameters from their distances: the
+// eclipse is central, total or annular, when the axis of the Moon's shadow
+// meets the Earth, and partial otherwise. It can misjudge an eclipse that
+// barely grazes the Earth.
+func SolarEclipse(sun, moon ephemeris.PlanetPos) Eclipse {
+	piSun, piMoon, sSun, sMoon := apparent(sun, moon)
+	sep := math.Abs(moon.Latitude-sun.Latitude) * cosInclination
+	switch {
+	case sep >= piMoon-piSun+sMoon+sSun:
+		return NoEclipse
+	case sep >= piMoon-piSun:
+		return PartialSolar
+	case sMoon >= sSun:
+		return TotalSolar
+	}
+	return AnnularSolar
+}
+
+// LunarEclipse returns the lunar eclipse at a full moon with the Sun and
+// Moon at sun and moon, or NoEclipse, from the least distance between the
+// Moon and the centre of the Earth's shadow and the radii of the umbra and
+// penumbra.
+func LunarEclipse(sun, moon ephemeris.PlanetPos) Eclipse {
+	piSun, piMoon, sSun, sMoon := apparent(sun, moon)
+	sep := math.Abs(moon.Latitude+sun.Latitude) * cosInclination
+	umbra := shadowEnlargement * (piMoon + piSun - sSun)
+	penumbra := shadowEnlargement * (piMoon + piSun + sSun)
+	switch {
+	case sep >= penumbra+sMoon:
+		return NoEclipse
+	case sep >= umbra+sMoon:
+		return PenumbralLunar
+	case sep >= umbra-sMoon:
+		return PartialLunar
+	}
+	return TotalLunar
+}
+
+// supermoonShare is how far a new or full moon must fall from apogee
+// towards perigee to be a supermoon: within 90% of the Moon's closest
+// approach in its orbit, as Richard Nolle defined it.
+const supermoonShare = 0.9
+
+// Orbit returns the Moon's distance at jd, and the least and greatest
+// distances of its orbit then, as swisseph.OrbitDistances does.
+type Orbit func(jd float64) (dist, perigee, apogee float64, err error)
+
+// IsSupermoon reports whether a new or full moon at distance dist, on an
+// orbit between perigee and apogee, is a supermoon.
+func IsSupermoon(dist, perigee, apogee float64) bool {
+	return apogee > perigee && apogee-dist >= supermoonShare*(apogee-perigee)
+}
+
+// Supermoons sets Supermoon for the new and full moons among events that
+// are supermoons, with the Moon's orbit taken from orbit.
+func Supermoons(events []Event, orbit Orbit) error {
+	for i, e := range events {
+		if e.Kind != Lunation || (e.Phase != NewMoon && e.Phase != FullMoon) {
+			continue
+		}
+		dist, perigee, apogee, err := orbit(e.JD)
+		if err != nil {
+			return err
+		}
+		events[i].Supermoon = IsSupermoon(dist, perigee, apogee)
+	}
+	return nil
+}
+
+// step returns the sampling interval in days for body: short enough that
+// it cannot pass through a sign, or turn twice, between samples.
+func step(body int) float64 {
+	if body == ephemeris.Moon {
+		return 0.25
+	}
+	return 1
+}
+
+// Ingresses returns the moments within [from, to] when body enters a sign.
+// The Sun's and Moon's are found with the provider's own search when it is
+// an ephemeris.CrossingProvider.
+func Ingresses(p ephemeris.Provider, body int, from, to float64) ([]Event, error) {
+	if c, ok := p.(ephemeris.CrossingProvider); ok {
+		if events, ok, err := crossIngresses(c, body, from, to); ok || err != nil {
+			return events, err
+		}
+	}
+	lon := func(jd float64) (float64, error) {
+		pos, err := calc(p, jd, body)
+		return pos.Longitude, err
+	}
+
+	var events []Event
+	err := search.Scan(lon, from, to, step(body), func(t0, l0, t1, l1 float64) error {
+		s0, s1 := sign(l0), sign(l1)
+		if s0 == s1 {
+			return nil
+		}
+		// The sign entered, and the cusp crossed into it: the later sign's
+		// own cusp going forwards, the earlier sign's going backwards.
+		retro := angle.Diff(l1, l0) < 0
+		cusp := float64(s1) * 30
+		if retro {
+			cusp = float64(s0) * 30
+		}
+		jd, err := search.Root(search.Offset(lon, cusp), t0, t1, angle.Diff(l0, cusp), angle.Diff(l1, cusp))
+		if err != nil {
+			return err
+		}
+		events = append(events, Event{JD: jd, Kind: Ingress, Body: body, Sign: s1, Retrograde: retro, Longitude: angle.Norm360(cusp)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// crossIngresses returns the ingresses of body within [from, to] by
+// c.Crossing, or ok false if c cannot search for body. It serves the Sun
+// and Moon, which are never retrograde, so each sign follows the last.
+func crossIngresses(c ephemeris.CrossingProvider, body int, from, to float64) (events []Event, ok bool, err error) {
+	pos, err := calc(c, from, body)
+	if err != nil {
+		return nil, false, err
+	}
+	s, jd := sign(pos.Longitude), from
+	for {
+		s = (s + 1) % 12
+		cusp := float64(s) * 30
+		if jd, ok, err = c.Crossing(jd, body, cusp); err != nil || !ok {
+			return nil, ok, err
+		}
+		if jd > to {
+			return events, true, nil
+		}
+		events = append(events, Event{JD: jd, Kind: Ingress, Body: body, Sign: s, Longitude: cusp})
+	}
+}
+
+// Stations returns the moments within [from, to] when body turns
+// retrograde or direct: when its speed in longitude changes sign.
+func Stations(p ephemeris.Provider, body int, from, to float64) ([]Event, error) {
+	speed := func(jd float64) (float64, error) {
+		pos, err := calc(p, jd, body)
+		return pos.SpeedLon, err
+	}
+
+	var events []Event
+	err := search.Scan(speed, from, to, step(body), func(t0, v0, t1, v1 float64) error {
+		if (v0 < 0) == (v1 < 0) {
+			return nil
+		}
+		jd, err := search.Root(speed, t0, t1, v0, v1)
+		if err != nil {
+			return err
+		}
+		pos, err := calc(p, jd, body)
+		if err != nil {
+			return err
+		}
+		events = append(events, Event{JD: jd, Kind: Station, Body: body, Retrograde: v0 >= 0, Longitude: pos.Longitude})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Aspects returns the moments within [from, to] when any two of bodies
+// form one of as exactly, in chronological order. An aspect other than
+// the conjunction and opposition is found on either side: a square with
+// the first body 90° ahead or behind. Of each pair, Body is the one given
+// first in bodies.
+func Aspects(p ephemeris.Provider, bodies []int, as []aspects.Aspect, from, to float64) ([]Event, error) {
+	var events []Event
+	for i, a := range bodies {
+		for _, b := range bodies[i+1:] {
+			found, err := pairAspects(p, a, b, as, from, to)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, found...)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].JD < events[j].JD })
+	return events, nil
+}
+
+// pairAspects returns the exact aspects between a and b within [from, to].
+func pairAspects(p ephemeris.Provider, a, b int, as []aspects.Aspect, from, to float64) ([]Event, error) {
+	sep := func(jd float64) (float64, error) {
+		pa, err := calc(p, jd, a)
+		if err != nil {
+			return 0, err
+		}
+		pb, err := calc(p, jd, b)
+		if err != nil {
+			return 0, err
+		}
+		return angle.Norm360(pa.Longitude - pb.Longitude), nil
+	}
+
+	var events []Event
+	err := search.Scan(sep, from, to, math.Min(step(a), step(b)), func(t0, s0, t1, s1 float64) error {
+		for _, asp := range as {
+			angles := []float64{asp.Angle}
+			if asp.Angle != 0 && asp.Angle != 180 {
+				angles = append(angles, 360-asp.Angle)
+			}
+			for _, target := range angles {
+				d0, d1 := angle.Diff(s0, target), angle.Diff(s1, target)
+				if !search.Crossed(d0, d1) {
+					continue
+				}
+				jd, err := search.Root(search.Offset(sep, target), t0, t1, d0, d1)
+				if err != nil {
+					return err
+				}
+				pos, err := calc(p, jd, a)
+				if err != nil {
+					return err
+				}
+				events = append(events, Event{JD: jd, Kind: Aspect, Body: a, Other: b, Aspect: asp, Longitude: pos.Longitude})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// calc returns body's position at jd.
+func calc(p ephemeris.Provider, jd float64, body int) (ephemeris.PlanetPos, error) {
+	pos, err := p.CalcPlanet(jd, body)
+	if err != nil {
+		return ephemeris.PlanetPos{}, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+	}
+	return pos, nil
+}
+
+// sign returns the sign of a longitude, 0 for Aries to 11 for Pisces.
+func sign(lon float64) int { return int(angle.Norm360(lon)/30) % 12 }
@@ -0,0 +1,213 @@
+package mundane_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+)
+
+func TestLunations(t *testing.T) {
+	// The elongation starts at 350° and grows 12° a day: new moon at 10/12
+	// days, then a phase every 7.5 days.
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 0, SpeedLon: 1, Distance: 1},
+			ephemeris.Moon: {Longitude: 350, SpeedLon: 13, Distance: 0.0024},
+		},
+	}
+	events, err := mundane.Lunations(p, 0, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []mundane.Phase{mundane.NewMoon, mundane.FirstQuarter, mundane.FullMoon, mundane.LastQuarter}
+	if len(events) != len(want) {
+		t.Fatalf("got %d lunations, want %d: %+v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		jd := 10.0/12 + 7.5*float64(i)
+		if e.Phase != want[i] || math.Abs(e.JD-jd) > 1e-4 {
+			t.Errorf("lunation %d = %v at %.5f, want %v at %.5f", i, e.Phase, e.JD, want[i], jd)
+		}
+	}
+	// The Moon on the ecliptic and near: a total eclipse each time.
+	if events[0].Eclipse != mundane.TotalSolar || events[2].Eclipse != mundane.TotalLunar {
+		t.Errorf("eclipses %v and %v, want total solar and total lunar", events[0].Eclipse, events[2].Eclipse)
+	}
+	if events[1].Eclipse != mundane.NoEclipse {
+		t.Errorf("eclipse at first quarter: %v", events[1].Eclipse)
+	}
+}
+
+func TestEclipses(t *testing.T) {
+	sun := ephemeris.PlanetPos{Distance: 1}
+	near := 0.0024 // AU, about 359,000 km
+	far := 0.0027  // about 404,000 km
+	cases := []struct {
+		lat, dist    float64
+		solar, lunar mundane.Eclipse
+	}{
+		{0, near, mundane.TotalSolar, mundane.TotalLunar},
+		{0, far, mundane.AnnularSolar, mundane.TotalLunar},
+		{0.7, near, mundane.TotalSolar, mundane.PartialLunar},
+		{1, far, mundane.PartialSolar, mundane.PenumbralLunar},
+		{1.2, near, mundane.PartialSolar, mundane.PenumbralLunar},
+		{2, near, mundane.NoEclipse, mundane.NoEclipse},
+	}
+	for _, tc := range cases {
+		moon := ephemeris.PlanetPos{Latitude: tc.lat, Distance: tc.dist}
+		if got := mundane.SolarEclipse(sun, moon); got != tc.solar {
+			t.Errorf("SolarEclipse(lat %v, dist %v) = %v, want %v", tc.lat, tc.dist, got, tc.solar)
+		}
+		if got := mundane.LunarEclipse(sun, moon); got != tc.lunar {
+			t.Errorf("LunarEclipse(lat %v, dist %v) = %v, want %v", tc.lat, tc.dist, got, tc.lunar)
+		}
+	}
+}
+
+func TestSupermoons(t *testing.T) {
+	events := []mundane.Event{
+		{JD: 1, Kind: mundane.Lunation, Phase: mundane.NewMoon},
+		{JD: 2, Kind: mundane.Lunation, Phase: mundane.FirstQuarter},
+		{JD: 3, Kind: mundane.Lunation, Phase: mundane.FullMoon},
+		{JD: 4, Kind: mundane.Lunation, Phase: mundane.FullMoon},
+		{JD: 5, Kind: mundane.Ingress},
+	}
+	// Full moons of September and December 2024, in thousands of km.
+	dist := map[float64]float64{1: 357.5, 2: 357.5, 3: 357.5, 4: 370.4}
+	err := mundane.Supermoons(events, func(jd float64) (float64, float64, float64, error) {
+		if jd == 4 {
+			return dist[jd], 364.7, 408.8, nil
+		}
+		return dist[jd], 357.3, 415.1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, false, true, false, false} {
+		if events[i].Supermoon != want {
+			t.Errorf("event %d: supermoon %v, want %v", i, events[i].Supermoon, want)
+		}
+	}
+}
+
+func TestIngresses(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Mars:    {Longitude: 25, SpeedLon: 1},
+			ephemeris.Mercury: {Longitude: 35, SpeedLon: -1},
+		},
+	}
+	events, err := mundane.Ingresses(p, ephemeris.Mars, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Sign != 1 || events[0].Retrograde || math.Abs(events[0].JD-5) > 1e-4 {
+		t.Errorf("Mars ingresses = %+v, want Taurus at 5", events)
+	}
+	events, err = mundane.Ingresses(p, ephemeris.Mercury, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Sign != 0 || !events[0].Retrograde || math.Abs(events[0].JD-5) > 1e-4 {
+		t.Errorf("Mercury ingresses = %+v, want Aries retrograde at 5", events)
+	}
+}
+
+// swing is a body that moves back and forth: longitude 100 + 10 sin(t/10),
+// stationing whenever cos(t/10) is 0.
+type swing struct{}
+
+func (swing) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	return ephemeris.PlanetPos{Longitude: 100 + 10*math.Sin(jd/10), SpeedLon: math.Cos(jd / 10)}, nil
+}
+
+func (swing) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	return ephemeris.HouseResult{}, nil
+}
+
+func (swing) PlanetName(body int) string { return ephemeris.BodyName(body) }
+
+func TestStations(t *testing.T) {
+	events, err := mundane.Stations(swing{}, ephemeris.Mars, 0, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		jd    float64
+		retro bool
+	}{{5 * math.Pi, true}, {15 * math.Pi, false}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d stations, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if e := events[i]; e.Retrograde != w.retro || math.Abs(e.JD-w.jd) > 1e-4 {
+			t.Errorf("station %d: retrograde %v at %.5f, want %v at %.5f", i, e.Retrograde, e.JD, w.retro, w.jd)
+		}
+	}
+}
+
+// drift is a body that moves forwards at a degree a day with a swing of
+// 15 sin(t/10) on top, retrograde while cos(t/10) < -2/3.
+type drift struct{ swing }
+
+func (drift) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	return ephemeris.PlanetPos{Longitude: jd + 15*math.Sin(jd/10), SpeedLon: 1 + 1.5*math.Cos(jd/10)}, nil
+}
+
+func TestRetrogrades(t *testing.T) {
+	// The retrograde periods are centred on 10π(2k+1), the first from
+	// about 23.0 to 39.8; the one centred on 30π ends at about 102.6.
+	periods, err := mundane.Retrogrades(drift{}, ephemeris.Mars, 30, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods, want 2: %+v", len(periods), periods)
+	}
+	lon := func(jd float64) float64 { p, _ := drift{}.CalcPlanet(jd, 0); return p.Longitude }
+	for _, r := range periods {
+		if !r.Station.Retrograde || r.Direct.Retrograde || r.Station.JD >= r.Direct.JD {
+			t.Errorf("stations %+v, %+v", r.Station, r.Direct)
+		}
+		if r.ShadowStart.JD >= r.Station.JD || math.Abs(lon(r.ShadowStart.JD)-r.Direct.Longitude) > 1e-3 {
+			t.Errorf("shadow starts at %.4f (%.4f°), want the direct station's %.4f° before %.4f",
+				r.ShadowStart.JD, lon(r.ShadowStart.JD), r.Direct.Longitude, r.Station.JD)
+		}
+		if r.ShadowEnd.JD <= r.Direct.JD || math.Abs(lon(r.ShadowEnd.JD)-r.Station.Longitude) > 1e-3 {
+			t.Errorf("shadow ends at %.4f (%.4f°), want the retrograde station's %.4f° after %.4f",
+				r.ShadowEnd.JD, lon(r.ShadowEnd.JD), r.Station.Longitude, r.Direct.JD)
+		}
+	}
+	if _, err := mundane.Retrogrades(drift{}, ephemeris.Sun, 0, 100); err == nil {
+		t.Error("Retrogrades of the Sun: want an error")
+	}
+}
+
+func TestAspects(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Mars:   {Longitude: 0, SpeedLon: 1},
+			ephemeris.Saturn: {Longitude: 100},
+		},
+	}
+	events, err := mundane.Aspects(p, []int{ephemeris.Mars, ephemeris.Saturn}, aspects.Major, 0, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		jd     float64
+		aspect string
+	}{{10, "square"}, {40, "sextile"}, {100, "conjunction"}, {160, "sextile"}, {190, "square"}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d aspects, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Aspect.Name != w.aspect || math.Abs(e.JD-w.jd) > 1e-4 || e.Body != ephemeris.Mars || e.Other != ephemeris.Saturn {
+			t.Errorf("aspect %d = %s at %.5f, want %s at %v", i, e.Aspect.Name, e.JD, w.aspect, w.jd)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package mundane
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// Retrograde is one of a planet's retrograde periods with its shadow, the
+// arc between the degrees of its two stations, which the planet crosses
+// three times: direct, retrograde, and direct again.
+type Retrograde struct {
+	Body int
+	// ShadowStart is when the planet, still direct, first reaches the
+	// degree of its coming station direct: the pre-retrograde shadow.
+	ShadowStart Event
+	Station     Event // the station retrograde
+	Direct      Event // the station direct
+	// ShadowEnd is when the planet, direct again, passes the degree of
+	// its station retrograde, leaving the post-retrograde shadow.
+	ShadowEnd Event
+}
+
+// stationMargin is how far outside a range to look for stations, in days:
+// longer than the longest retrograde period, Pluto's of about 160 days.
+const stationMargin = 200
+
+// shadowLimit is how far from a station to look for the shadow's ends, in
+// days. Pluto, the slowest, takes about a year.
+const shadowLimit = 800
+
+// Retrogrades returns the retrograde periods of body whose retrograde
+// motion falls at least partly within [from, to], in chronological order.
+// The ShadowStart and ShadowEnd events have the Kind Station, and Retrograde
+// set for the start; their Longitude is the degree reached.
+func Retrogrades(p ephemeris.Provider, body int, from, to float64) ([]Retrograde, error) {
+	if body == ephemeris.Sun || body == ephemeris.Moon {
+		return nil, fmt.Errorf("the %s is never retrograde", p.PlanetName(body))
+	}
+	stations, err := Stations(p, body, from-stationMargin, to+stationMargin)
+	if err != nil {
+		return nil, err
+	}
+	lon := func(jd float64) (float64, error) {
+		pos, err := calc(p, jd, body)
+		return pos.Longitude, err
+	}
+
+	var periods []Retrograde
+	for i := 0; i+1 < len(stations); i++ {
+		sr, sd := stations[i], stations[i+1]
+		if !sr.Retrograde || sd.Retrograde || sd.JD < from || sr.JD > to {
+			continue
+		}
+		start, err := crossing(lon, sd.Longitude, sr.JD, -step(body))
+		if err != nil {
+			return nil, err
+		}
+		end, err := crossing(lon, sr.Longitude, sd.JD, step(body))
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, Retrograde{
+			Body:        body,
+			ShadowStart: Event{JD: start, Kind: Station, Body: body, Retrograde: true, Longitude: sd.Longitude},
+			Station:     sr,
+			Direct:      sd,
+			ShadowEnd:   Event{JD: end, Kind: Station, Body: body, Longitude: sr.Longitude},
+		})
+	}
+	return periods, nil
+}
+
+// crossing returns the first moment from start, stepping by h days
+// (backwards if h is negative), when lon passes target, within
+// shadowLimit days.
+func crossing(lon search.Func, target, start, h float64) (float64, error) {
+	jd, ok, err := search.Next(search.Offset(lon, target), start, h, shadowLimit)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("no crossing of %.4f° within %d days", target, shadowLimit)
+	}
+	return jd, nil
+}
@@ -0,0 +1,136 @@
+package names
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/zodiac"
+)
+
+//go:embed lang/*.json
+var langFiles embed.FS
+
+// Table is a translation of the display names, in the JSON form of the
+// embedded language tables and of LoadFile. Every part is optional; names
+// it leaves out are kept.
+type Table struct {
+	// Signs are the twelve sign names from Aries.
+	Signs []string `json:"signs,omitempty"`
+	// The maps are keyed by the English name: "Sun", "Ascendant",
+	// "trine", "Whole Sign".
+	Bodies       map[string]string `json:"bodies,omitempty"`
+	Points       map[string]string `json:"points,omitempty"`
+	Aspects      map[string]string `json:"aspects,omitempty"`
+	HouseSystems map[string]string `json:"house_systems,omitempty"`
+}
+
+// Languages returns the codes Load accepts, sorted: en and those with an
+// embedded table.
+func Languages() []string {
+	langs := []string{"en"}
+	entries, _ := langFiles.ReadDir("lang")
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Load resets the registry's names to English and then, unless lang is
+// "en", applies the embedded table for lang. Glyphs are left as they are.
+func (r *Registry) Load(lang string) error {
+	lang = strings.ToLower(lang)
+	var t Table
+	if lang != "en" {
+		data, err := langFiles.ReadFile("lang/" + lang + ".json")
+		if err != nil {
+			return fmt.Errorf("unknown language %q: valid values are %s", lang, strings.Join(Languages(), ", "))
+		}
+		if t, err = decodeTable(data); err != nil {
+			return fmt.Errorf("error reading language %s: %w", lang, err)
+		}
+	}
+	r.resetNames()
+	return r.Apply(t)
+}
+
+// LoadFile applies the Table in the JSON file at path on top of the
+// current names, for example to adjust a few names of a language.
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading names file: %w", err)
+	}
+	t, err := decodeTable(data)
+	if err == nil {
+		err = r.Apply(t)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading names file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Apply sets the names in t, keeping the others. It changes nothing if t
+// has other than twelve signs or names a body BodyName does not know.
+func (r *Registry) Apply(t Table) error {
+	if t.Signs != nil && len(t.Signs) != 12 {
+		return fmt.Errorf("got %d sign names, want 12", len(t.Signs))
+	}
+	bodies := make(map[int]string, len(t.Bodies))
+	for name, display := range t.Bodies {
+		id, ok := ephemeris.BodyByName(name)
+		if !ok {
+			return fmt.Errorf("unknown body %q", name)
+		}
+		bodies[id] = display
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.Signs != nil {
+		copy(r.signs[:], t.Signs)
+	}
+	for id, name := range bodies {
+		r.bodies[id] = name
+	}
+	for k, name := range t.Points {
+		r.points[k] = name
+	}
+	for k, name := range t.Aspects {
+		r.aspects[k] = name
+	}
+	for k, name := range t.HouseSystems {
+		r.houses[k] = name
+	}
+	return nil
+}
+
+// resetNames restores the English names, leaving the glyphs.
+func (r *Registry) resetNames() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signs = zodiac.Signs
+	clear(r.bodies)
+	clear(r.points)
+	clear(r.aspects)
+	clear(r.houses)
+}
+
+// decodeTable parses a Table, rejecting unknown keys so that a misspelt
+// section is not silently ignored.
+func decodeTable(data []byte) (Table, error) {
+	var t Table
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return Table{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,266 @@
+// Package names holds the display names and glyphs of zodiac signs, bodies,
+// chart points, aspects and house systems used by every renderer. Embedders
+// can override entries, for example to call the lunar node Rahu in a Vedic
+// chart or to translate the sign names, and all output picks up the change:
+//
+//	names.Default.SetBody(ephemeris.TrueNode, "Rahu")
+//	names.Default.SetSign(0, "Mesha")
+//
+// Whole translations are loaded with Load, from the tables embedded for
+// Languages, or with LoadFile.
+package names
+
+import (
+	"sync"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/zodiac"
+)
+
+// Chart point keys for Point and SetPoint.
+const (
+	Ascendant = "Ascendant"
+	MC        = "MC"
+	NorthNode = "North Node"
+	SouthNode = "South Node"
+	Rahu      = "Rahu" // the north node as a Vedic graha
+	Ketu      = "Ketu" // the south node as a Vedic graha
+	Fortune   = "Part of Fortune"
+	Syzygy    = "Prenatal Syzygy" // the new or full Moon before birth
+
+	Vertex         = "Vertex"
+	EastPoint      = "East Point"
+	CoAscendant    = "Co-Ascendant"
+	PolarAscendant = "Polar Ascendant"
+	Lilith         = "Lilith" // the mean lunar apogee, the Black Moon
+
+	// The Uranian planets of the Hamburg school, and Transpluto.
+	Cupido     = "Cupido"
+	Hades      = "Hades"
+	Zeus       = "Zeus"
+	Kronos     = "Kronos"
+	Apollon    = "Apollon"
+	Admetos    = "Admetos"
+	Vulkanus   = "Vulkanus"
+	Poseidon   = "Poseidon"
+	Transpluto = "Transpluto"
+)
+
+var signGlyphs = [12]string{"♈", "♉", "♊", "♋", "♌", "♍", "♎", "♏", "♐", "♑", "♒", "♓"}
+
+var bodyGlyphs = map[int]string{
+	ephemeris.Sun: "☉", ephemeris.Moon: "☽", ephemeris.Mercury: "☿", ephemeris.Venus: "♀",
+	ephemeris.Mars: "♂", ephemeris.Jupiter: "♃", ephemeris.Saturn: "♄", ephemeris.Uranus: "♅",
+	ephemeris.Neptune: "♆", ephemeris.Pluto: "♇", ephemeris.Earth: "⊕",
+	ephemeris.MeanNode: "☊", ephemeris.TrueNode: "☊",
+	ephemeris.Chiron: "⚷", ephemeris.Ceres: "⚳", ephemeris.Pallas: "⚴", ephemeris.Juno: "⚵", ephemeris.Vesta: "⚶",
+}
+
+var pointGlyphs = map[string]string{Ascendant: "Asc", MC: "MC", NorthNode: "☊", SouthNode: "☋", Rahu: "☊", Ketu: "☋", Fortune: "⊗",
+	Vertex: "Vx", EastPoint: "EP", CoAscendant: "cA", PolarAscendant: "pA", Lilith: "⚸",
+	Cupido: "Cu", Hades: "Ha", Zeus: "Ze", Kronos: "Kr", Apollon: "Ap", Admetos: "Ad", Vulkanus: "Vu", Poseidon: "Po", Transpluto: "TP",
+}
+
+// Registry maps signs, bodies, chart points, aspects and house systems to
+// display names, and the first three to glyphs. It is safe for concurrent use. Entries that have not been set
+// fall back to the English names and Unicode astrological symbols.
+type Registry struct {
+	mu         sync.RWMutex
+	signs      [12]string
+	signGlyphs [12]string
+	bodies     map[int]string
+	bodyGlyphs map[int]string
+	points     map[string]string
+	pointGlyph map[string]string
+	aspects    map[string]string
+	houses     map[string]string
+}
+
+// Default is the registry the output package renders with.
+var Default = New()
+
+// New returns a registry holding the default names and glyphs.
+func New() *Registry {
+	r := &Registry{
+		signs:      zodiac.Signs,
+		signGlyphs: signGlyphs,
+		bodies:     make(map[int]string),
+		bodyGlyphs: make(map[int]string),
+		points:     make(map[string]string),
+		pointGlyph: make(map[string]string),
+		aspects:    make(map[string]string),
+		houses:     make(map[string]string),
+	}
+	for id, g := range bodyGlyphs {
+		r.bodyGlyphs[id] = g
+	}
+	for k, g := range pointGlyphs {
+		r.pointGlyph[k] = g
+	}
+	return r
+}
+
+// Sign returns the name of sign i, where 0 is Aries and 11 Pisces.
+func (r *Registry) Sign(i int) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signs[signIndex(i)]
+}
+
+// SetSign renames sign i.
+func (r *Registry) SetSign(i int, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signs[signIndex(i)] = name
+}
+
+// SignGlyph returns the glyph of sign i.
+func (r *Registry) SignGlyph(i int) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signGlyphs[signIndex(i)]
+}
+
+// SetSignGlyph replaces the glyph of sign i.
+func (r *Registry) SetSignGlyph(i int, glyph string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signGlyphs[signIndex(i)] = glyph
+}
+
+// SignOf returns the sign name and the degree within the sign for an
+// ecliptic longitude, like zodiac.Sign but with this registry's names.
+func (r *Registry) SignOf(longitude float64) (string, float64) {
+	longitude = angle.Norm360(longitude)
+	i := min(int(longitude/30), 11)
+	return r.Sign(i), longitude - float64(i)*30
+}
+
+// Body returns the display name of a body ID.
+func (r *Registry) Body(id int) string {
+	r.mu.RLock()
+	name, ok := r.bodies[id]
+	r.mu.RUnlock()
+	if ok {
+		return name
+	}
+	return ephemeris.BodyName(id)
+}
+
+// SetBody renames a body.
+func (r *Registry) SetBody(id int, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies[id] = name
+}
+
+// BodyGlyph returns the glyph of a body, or its name if it has none.
+func (r *Registry) BodyGlyph(id int) string {
+	r.mu.RLock()
+	g, ok := r.bodyGlyphs[id]
+	r.mu.RUnlock()
+	if ok {
+		return g
+	}
+	return r.Body(id)
+}
+
+// SetBodyGlyph replaces the glyph of a body.
+func (r *Registry) SetBodyGlyph(id int, glyph string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodyGlyphs[id] = glyph
+}
+
+// Point returns the display name of a chart point such as Ascendant.
+func (r *Registry) Point(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name, ok := r.points[key]; ok {
+		return name
+	}
+	return key
+}
+
+// SetPoint renames a chart point.
+func (r *Registry) SetPoint(key, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points[key] = name
+}
+
+// PointGlyph returns the glyph of a chart point, or its name if it has none.
+func (r *Registry) PointGlyph(key string) string {
+	r.mu.RLock()
+	g, ok := r.pointGlyph[key]
+	r.mu.RUnlock()
+	if ok {
+		return g
+	}
+	return r.Point(key)
+}
+
+// SetPointGlyph replaces the glyph of a chart point.
+func (r *Registry) SetPointGlyph(key, glyph string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pointGlyph[key] = glyph
+}
+
+// Aspect returns the display name of an aspect, by its name in
+// aspects.Major, e.g. "trine".
+func (r *Registry) Aspect(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n, ok := r.aspects[name]; ok {
+		return n
+	}
+	return name
+}
+
+// SetAspect renames an aspect.
+func (r *Registry) SetAspect(name, display string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aspects[name] = display
+}
+
+// HouseSystem returns the display name of a house system, by its English
+// name, e.g. "Whole Sign".
+func (r *Registry) HouseSystem(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n, ok := r.houses[name]; ok {
+		return n
+	}
+	return name
+}
+
+// SetHouseSystem renames a house system.
+func (r *Registry) SetHouseSystem(name, display string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.houses[name] = display
+}
+
+// signIndex wraps i into 0..11 so any integer names a sign.
+func signIndex(i int) int {
+	return ((i % 12) + 12) % 12
+}
+
+// Body returns the Default registry's name for a body ID.
+func Body(id int) string { return Default.Body(id) }
+
+// SignOf returns the Default registry's sign name and degree within the
+// sign for an ecliptic longitude.
+func SignOf(longitude float64) (string, float64) { return Default.SignOf(longitude) }
+
+// Point returns the Default registry's name for a chart point.
+func Point(key string) string { return Default.Point(key) }
+
+// Aspect returns the Default registry's name for an aspect.
+func Aspect(name string) string { return Default.Aspect(name) }
+
+// HouseSystem returns the Default registry's name for a house system.
+func HouseSystem(name string) string { return Default.HouseSystem(name) }
@@ -0,0 +1,176 @@
+package names_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+func TestDefaults(t *testing.T) {
+	r := names.New()
+	if got := r.Body(ephemeris.Saturn); got != "Saturn" {
+		t.Errorf("Body(Saturn) = %q", got)
+	}
+	if got := r.BodyGlyph(ephemeris.Saturn); got != "♄" {
+		t.Errorf("BodyGlyph(Saturn) = %q", got)
+	}
+	if got := r.BodyGlyph(ephemeris.Chiron); got != "⚷" {
+		t.Errorf("BodyGlyph(Chiron) = %q", got)
+	}
+	if got := r.BodyGlyph(ephemeris.Pholus); got != "Pholus" {
+		t.Errorf("BodyGlyph(Pholus) = %q, want the name as fallback", got)
+	}
+	if got := r.SignGlyph(11); got != "♓" {
+		t.Errorf("SignGlyph(11) = %q", got)
+	}
+	if got := r.Point(names.Ascendant); got != "Ascendant" {
+		t.Errorf("Point(Ascendant) = %q", got)
+	}
+}
+
+func TestSignOf(t *testing.T) {
+	r := names.New()
+	cases := []struct {
+		lon  float64
+		sign string
+		deg  float64
+	}{
+		{0, "Aries", 0},
+		{359.5, "Pisces", 29.5},
+		{-30, "Pisces", 0},
+		{725, "Aries", 5},
+	}
+	for _, tc := range cases {
+		sign, deg := r.SignOf(tc.lon)
+		if sign != tc.sign || deg != tc.deg {
+			t.Errorf("SignOf(%v) = %s %v, want %s %v", tc.lon, sign, deg, tc.sign, tc.deg)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	r := names.New()
+	r.SetBody(ephemeris.TrueNode, "Rahu")
+	r.SetBodyGlyph(ephemeris.TrueNode, "☊R")
+	r.SetSign(0, "Mesha")
+	r.SetSignGlyph(0, "M")
+	r.SetPoint(names.Ascendant, "Lagna")
+
+	if got := r.Body(ephemeris.TrueNode); got != "Rahu" {
+		t.Errorf("Body(TrueNode) = %q", got)
+	}
+	if got := r.BodyGlyph(ephemeris.TrueNode); got != "☊R" {
+		t.Errorf("BodyGlyph(TrueNode) = %q", got)
+	}
+	if sign, _ := r.SignOf(10); sign != "Mesha" {
+		t.Errorf("SignOf(10) = %q", sign)
+	}
+	if got := r.SignGlyph(12); got != "M" {
+		t.Errorf("SignGlyph(12) = %q, want index to wrap to Aries", got)
+	}
+	if got := r.Point(names.Ascendant); got != "Lagna" {
+		t.Errorf("Point(Ascendant) = %q", got)
+	}
+
+	if got := names.New().Body(ephemeris.TrueNode); got != "true Node" {
+		t.Errorf("override leaked into a new registry: %q", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	r := names.New()
+	r.SetBodyGlyph(ephemeris.Sun, "S")
+	if err := r.Load("de"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Body(ephemeris.Sun); got != "Sonne" {
+		t.Errorf("Body(Sun) = %q", got)
+	}
+	if sign, _ := r.SignOf(45); sign != "Stier" {
+		t.Errorf("SignOf(45) = %q", sign)
+	}
+	if got := r.Aspect("trine"); got != "Trigon" {
+		t.Errorf("Aspect(trine) = %q", got)
+	}
+	if got := r.HouseSystem("Whole Sign"); got != "Ganzzeichen" {
+		t.Errorf("HouseSystem(Whole Sign) = %q", got)
+	}
+	if got := r.BodyGlyph(ephemeris.Sun); got != "S" {
+		t.Errorf("BodyGlyph(Sun) = %q, want the glyph kept", got)
+	}
+
+	if err := r.Load("en"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Body(ephemeris.Sun); got != "Sun" {
+		t.Errorf("after Load(en), Body(Sun) = %q", got)
+	}
+	if err := r.Load("xx"); err == nil {
+		t.Error("Load(xx) succeeded")
+	}
+}
+
+// Every embedded table must load and translate the signs.
+func TestLanguages(t *testing.T) {
+	en := names.New()
+	for _, lang := range names.Languages() {
+		r := names.New()
+		if err := r.Load(lang); err != nil {
+			t.Errorf("Load(%s): %v", lang, err)
+			continue
+		}
+		same := 0
+		for i := range 12 {
+			if r.Sign(i) == en.Sign(i) {
+				same++
+			}
+		}
+		if lang != "en" && same == 12 {
+			t.Errorf("Load(%s) left the signs in English", lang)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	r := names.New()
+	if err := r.Load("es"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.LoadFile(write("ok.json", `{"bodies": {"Sun": "Sol Invictus"}, "aspects": {"square": "cuadrado"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Body(ephemeris.Sun); got != "Sol Invictus" {
+		t.Errorf("Body(Sun) = %q", got)
+	}
+	if got := r.Body(ephemeris.Moon); got != "Luna" {
+		t.Errorf("Body(Moon) = %q, want the language's name kept", got)
+	}
+	if got := r.Aspect("square"); got != "cuadrado" {
+		t.Errorf("Aspect(square) = %q", got)
+	}
+
+	for name, data := range map[string]string{
+		"body.json":    `{"bodies": {"Sol": "Sun"}, "signs": ["A","B","C","D","E","F","G","H","I","J","K","L"]}`,
+		"signs.json":   `{"signs": ["Widder"]}`,
+		"section.json": `{"planets": {"Sun": "Sonne"}}`,
+	} {
+		if err := r.LoadFile(write(name, data)); err == nil {
+			t.Errorf("LoadFile(%s) succeeded", name)
+		}
+	}
+	if sign, _ := r.SignOf(0); sign != "Aries" {
+		t.Errorf("a rejected file changed the signs: %q", sign)
+	}
+}
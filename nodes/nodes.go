@@ -0,0 +1,90 @@
+// Package nodes compares the true (osculating) lunar node with the mean
+// node. The two differ by up to about 1.7°, oscillating with the Moon's
+// and Sun's motion; node-sensitive techniques care which one is used when
+// the difference is large.
+package nodes
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// DefaultThreshold is the divergence in degrees above which the nodes are
+// considered to differ substantially.
+const DefaultThreshold = 1.5
+
+// step is the sampling interval in days for FindPeriods. The true node
+// oscillates with a period of about two weeks, so six-hour samples resolve
+// each swing.
+const step = 0.25
+
+// Divergence returns the true node's longitude minus the mean node's, in
+// degrees wrapped to [-180, 180).
+func Divergence(p ephemeris.Provider, jd float64) (float64, error) {
+	trueNode, err := p.CalcPlanet(jd, ephemeris.TrueNode)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating true node: %w", err)
+	}
+	meanNode, err := p.CalcPlanet(jd, ephemeris.MeanNode)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating mean node: %w", err)
+	}
+	return angle.Diff(trueNode.Longitude, meanNode.Longitude), nil
+}
+
+// Period is a stretch of time during which |Divergence| exceeds a threshold.
+type Period struct {
+	Start, End float64 // Julian Days (UT)
+	PeakJD     float64 // time of largest divergence within the period
+	Peak       float64 // divergence at PeakJD, signed
+}
+
+// FindPeriods returns the periods within [from, to] during which the nodes
+// diverge by more than threshold degrees. Periods already in progress at
+// from, or still running at to, are clipped to the range.
+func FindPeriods(p ephemeris.Provider, from, to, threshold float64) ([]Period, error) {
+	divergence := func(jd float64) (float64, error) { return Divergence(p, jd) }
+	// excess is positive while the nodes diverge by more than threshold.
+	excess := func(jd float64) (float64, error) {
+		d, err := Divergence(p, jd)
+		return math.Abs(d) - threshold, err
+	}
+
+	var periods []Period
+	var cur *Period
+	err := search.Scan(divergence, from, to, step, func(t0, d0, t1, d1 float64) error {
+		if t0 == from && math.Abs(d0) > threshold {
+			cur = &Period{Start: from, PeakJD: from, Peak: d0}
+		}
+		e0, e1 := math.Abs(d0)-threshold, math.Abs(d1)-threshold
+		if (e0 > 0) != (e1 > 0) {
+			edge, err := search.Root(excess, t0, t1, e0, e1)
+			if err != nil {
+				return err
+			}
+			if e1 > 0 {
+				cur = &Period{Start: edge, PeakJD: t1, Peak: d1}
+			} else {
+				cur.End = edge
+				periods = append(periods, *cur)
+				cur = nil
+			}
+		}
+		if cur != nil && math.Abs(d1) > math.Abs(cur.Peak) {
+			cur.PeakJD, cur.Peak = t1, d1
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cur != nil {
+		cur.End = to
+		periods = append(periods, *cur)
+	}
+	return periods, nil
+}
@@ -0,0 +1,65 @@
+package nodes_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/nodes"
+)
+
+// sineNodes models the true node swinging ±2° around a fixed mean node with
+// a 20-day period, starting at zero divergence.
+type sineNodes struct{ ephemeris.MockProvider }
+
+func (sineNodes) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	lon := 0.5 // near 0° Aries, to exercise wrap-around
+	if body == ephemeris.TrueNode {
+		lon += 2 * math.Sin(2*math.Pi*jd/20)
+	}
+	return ephemeris.PlanetPos{Longitude: math.Mod(lon+360, 360)}, nil
+}
+
+func TestDivergence(t *testing.T) {
+	d, err := nodes.Divergence(&sineNodes{}, 15) // sin(1.5π) = -1
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(d+2) > 1e-9 {
+		t.Errorf("Divergence = %v, want -2", d)
+	}
+}
+
+func TestFindPeriods(t *testing.T) {
+	// |2 sin(2πt/20)| > √2 when the phase is within 45° of a peak:
+	// t in (2.5, 7.5) and (12.5, 17.5) in each 20-day cycle.
+	periods, err := nodes.FindPeriods(&sineNodes{}, 0, 20, math.Sqrt2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ start, end, peakJD, peak float64 }{
+		{2.5, 7.5, 5, 2},
+		{12.5, 17.5, 15, -2},
+	}
+	if len(periods) != len(want) {
+		t.Fatalf("got %d periods, want %d: %+v", len(periods), len(want), periods)
+	}
+	for i, w := range want {
+		got := periods[i]
+		if math.Abs(got.Start-w.start) > 1e-3 || math.Abs(got.End-w.end) > 1e-3 {
+			t.Errorf("period %d = [%.4f, %.4f], want [%.1f, %.1f]", i, got.Start, got.End, w.start, w.end)
+		}
+		if math.Abs(got.PeakJD-w.peakJD) > 0.25 || math.Abs(got.Peak-w.peak) > 0.01 {
+			t.Errorf("period %d peak %.4f at %.2f, want %.1f at %.1f", i, got.Peak, got.PeakJD, w.peak, w.peakJD)
+		}
+	}
+
+	// A range starting inside a period is clipped.
+	periods, err = nodes.FindPeriods(&sineNodes{}, 4, 6, math.Sqrt2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 1 || periods[0].Start != 4 || periods[0].End != 6 {
+		t.Errorf("clipped periods = %+v", periods)
+	}
+}
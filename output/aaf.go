@@ -0,0 +1,106 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dcccxiii/astro/aaf"
+)
+
+// AAFChart is a chart of an AAF file, as "astro aaf import" lists it.
+type AAFChart struct {
+	Name      string `json:"name"`
+	FirstName string `json:"first_name,omitempty"`
+	Sex       string `json:"sex,omitempty"`
+	// Time is the moment on the chart's local clock, including daylight
+	// saving time; astro accepts it as it is.
+	Time      time.Time `json:"datetime"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Place     string    `json:"place,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	DST       float64   `json:"dst"` // hours of daylight saving time in Time's offset
+}
+
+// BuildAAFCharts converts the records of an AAF file into charts.
+func BuildAAFCharts(recs []aaf.Record) []AAFChart {
+	charts := []AAFChart{}
+	for _, r := range recs {
+		charts = append(charts, AAFChart{
+			Name:      r.Name,
+			FirstName: r.FirstName,
+			Sex:       r.Sex,
+			Time:      r.Local(),
+			Latitude:  r.Lat,
+			Longitude: r.Lon,
+			Place:     r.Place,
+			Country:   r.Country,
+			DST:       r.DST.Hours(),
+		})
+	}
+	return charts
+}
+
+// AAFCSVHeader names the columns WriteAAFChartsCSV writes, which "astro
+// aaf export" reads back.
+var AAFCSVHeader = []string{"name", "first_name", "sex", "datetime", "latitude", "longitude", "place", "country", "dst"}
+
+// WriteAAFChartsText writes the charts to w, one per line, with the
+// datetime, latitude and longitude as astro takes them.
+func WriteAAFChartsText(w io.Writer, charts []AAFChart) error {
+	fmt.Fprintf(w, "=== %d charts ===\n", len(charts))
+	names := make([]string, len(charts))
+	width := 0
+	for i, c := range charts {
+		names[i] = strings.TrimSpace(c.FirstName + " " + c.Name)
+		width = max(width, utf8.RuneCountInString(names[i]))
+	}
+	for i, c := range charts {
+		place := c.Place
+		if c.Country != "" {
+			place = strings.TrimPrefix(place+", "+c.Country, ", ")
+		}
+		line := fmt.Sprintf("%s  %-25s  %9.4f  %9.4f  %s", pad(names[i], width), c.Time.Format(time.RFC3339), c.Latitude, c.Longitude, place)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAAFChartsCSV writes the charts to w as CSV under AAFCSVHeader.
+func WriteAAFChartsCSV(w io.Writer, charts []AAFChart) error {
+	cw := csv.NewWriter(w)
+	cw.Write(AAFCSVHeader)
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	for _, c := range charts {
+		cw.Write([]string{
+			c.Name, c.FirstName, c.Sex, c.Time.Format(time.RFC3339),
+			num(c.Latitude), num(c.Longitude), c.Place, c.Country, num(c.DST),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteAAFChartsJSON writes the charts to w as a JSON array, laid out as
+// opt says.
+func WriteAAFChartsJSON(w io.Writer, charts []AAFChart, opt JSONOptions) error {
+	return writeJSON(w, charts, opt)
+}
+
+// WriteAAFChartsNDJSON writes the charts to w, one per line.
+func WriteAAFChartsNDJSON(w io.Writer, charts []AAFChart) error {
+	n := NewNDJSON(w)
+	for _, c := range charts {
+		if err := n.Write(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dcccxiii/astro/almuten"
+	"github.com/dcccxiii/astro/names"
+)
+
+// AlmutenPoint is one point whose dignities were counted.
+type AlmutenPoint struct {
+	Name       string   `json:"name"`
+	Longitude  float64  `json:"longitude"`
+	Sign       string   `json:"sign"`
+	SignDegree float64  `json:"sign_degree"`
+	Almuten    []string `json:"almuten"` // the planets most dignified at this point alone
+}
+
+// AlmutenScore is one planet's dignity at each point.
+type AlmutenScore struct {
+	Planet string `json:"planet"`
+	Scores []int  `json:"scores"` // in the order of AlmutenReport.Points
+	Total  int    `json:"total"`
+}
+
+// AlmutenReport is the almuten of a chart's hylegical points, or of a
+// single degree.
+type AlmutenReport struct {
+	Sect    string         `json:"sect"` // "day" or "night"
+	Degree  bool           `json:"degree"`
+	Points  []AlmutenPoint `json:"points"`
+	Scores  []AlmutenScore `json:"scores"` // highest total first
+	Almuten []string       `json:"almuten"`
+	Total   int            `json:"total"`
+}
+
+// BuildAlmuten converts a dignity table into a report. degree is true when
+// the table covers one arbitrary degree rather than the hylegical points.
+func BuildAlmuten(t almuten.Table, day, degree bool) AlmutenReport {
+	rep := AlmutenReport{Sect: "night", Degree: degree, Almuten: bodyNames(t.Winners), Total: t.Best}
+	if day {
+		rep.Sect = "day"
+	}
+	for i, pt := range t.Points {
+		sign, deg := names.SignOf(pt.Longitude)
+		// The almuten of this point alone is the table of its column.
+		col := almuten.Figuris(t.Points[i:i+1], day)
+		rep.Points = append(rep.Points, AlmutenPoint{
+			Name: pt.Name, Longitude: pt.Longitude, Sign: sign, SignDegree: deg,
+			Almuten: bodyNames(col.Winners),
+		})
+	}
+	for _, r := range t.Rows {
+		rep.Scores = append(rep.Scores, AlmutenScore{Planet: names.Body(r.Body), Scores: r.Scores, Total: r.Total})
+	}
+	sort.SliceStable(rep.Scores, func(i, j int) bool { return rep.Scores[i].Total > rep.Scores[j].Total })
+	return rep
+}
+
+func bodyNames(bodies []int) []string {
+	out := []string{}
+	for _, b := range bodies {
+		out = append(out, names.Body(b))
+	}
+	return out
+}
+
+// PrintAlmutenText writes the points and the score table to stdout.
+func PrintAlmutenText(rep AlmutenReport) error {
+	winner := strings.Join(rep.Almuten, ", ")
+	if winner == "" {
+		winner = "none"
+	}
+	if rep.Degree {
+		fmt.Printf("Almuten of %.2f° %s (%s chart): %s, %d points\n", rep.Points[0].SignDegree, rep.Points[0].Sign, rep.Sect, winner, rep.Total)
+	} else {
+		fmt.Printf("Almuten figuris (%s chart): %s, %d points\n", rep.Sect, winner, rep.Total)
+	}
+
+	fmt.Println("\n=== Points ===")
+	for _, pt := range rep.Points {
+		fmt.Printf("%-16s  %9.4f°  (%s %5.2f°)  almuten: %s\n",
+			pt.Name, pt.Longitude, pt.Sign, pt.SignDegree, strings.Join(pt.Almuten, ", "))
+	}
+
+	fmt.Println("\n=== Essential dignity ===")
+	fmt.Printf("%-16s", "")
+	for _, s := range rep.Scores {
+		fmt.Printf(" %5s", abbreviate(s.Planet))
+	}
+	fmt.Println()
+	for i, pt := range rep.Points {
+		fmt.Printf("%-16s", pt.Name)
+		for _, s := range rep.Scores {
+			fmt.Printf(" %5d", s.Scores[i])
+		}
+		fmt.Println()
+	}
+	if len(rep.Points) > 1 {
+		fmt.Printf("%-16s", "Total")
+		for _, s := range rep.Scores {
+			fmt.Printf(" %5d", s.Total)
+		}
+		fmt.Println()
+	}
+	fmt.Println("\nDomicile 5, exaltation 4, triplicity 3 (lord of the sect), term 2, face 1.")
+	return nil
+}
+
+// PrintAlmutenJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintAlmutenJSON(rep AlmutenReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
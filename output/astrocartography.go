@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/astrocartography"
+	"github.com/dcccxiii/astro/names"
+)
+
+// GeoJSON is an astrocartography map as a GeoJSON FeatureCollection
+// (RFC 7946): a MultiLineString feature per planetary line and a Point
+// feature per crossing, so the map can be loaded into standard GIS tools.
+type GeoJSON struct {
+	Type     string       `json:"type"` // always "FeatureCollection"
+	Time     time.Time    `json:"time"` // the chart moment; a foreign member
+	Features []GeoFeature `json:"features"`
+}
+
+// GeoFeature is one line or crossing.
+type GeoFeature struct {
+	Type       string        `json:"type"` // always "Feature"
+	Geometry   GeoGeometry   `json:"geometry"`
+	Properties GeoProperties `json:"properties"`
+}
+
+// GeoGeometry is a GeoJSON geometry. Coordinates are [longitude, latitude]
+// pairs, nested as the geometry type requires.
+type GeoGeometry struct {
+	Type        string `json:"type"` // "MultiLineString" or "Point"
+	Coordinates any    `json:"coordinates"`
+}
+
+// GeoProperties describes a feature. Lines set Planet and Angle; crossings
+// set the A and B fields and Latitude.
+type GeoProperties struct {
+	Kind     string   `json:"kind"` // "line" or "crossing"
+	Planet   string   `json:"planet,omitempty"`
+	Angle    string   `json:"angle,omitempty"`
+	PlanetA  string   `json:"planet_a,omitempty"`
+	AngleA   string   `json:"angle_a,omitempty"`
+	PlanetB  string   `json:"planet_b,omitempty"`
+	AngleB   string   `json:"angle_b,omitempty"`
+	Latitude *float64 `json:"latitude,omitempty"`
+}
+
+// BuildGeoJSON converts lines and crossings into a FeatureCollection.
+func BuildGeoJSON(t time.Time, lines []astrocartography.Line, crossings []astrocartography.Crossing) GeoJSON {
+	g := GeoJSON{Type: "FeatureCollection", Time: t, Features: []GeoFeature{}}
+	for _, l := range lines {
+		if len(l.Segments) == 0 {
+			continue
+		}
+		g.Features = append(g.Features, GeoFeature{
+			Type:       "Feature",
+			Geometry:   GeoGeometry{Type: "MultiLineString", Coordinates: l.Segments},
+			Properties: GeoProperties{Kind: "line", Planet: names.Body(l.Body), Angle: l.Angle.String()},
+		})
+	}
+	for _, c := range crossings {
+		g.Features = append(g.Features, GeoFeature{
+			Type:     "Feature",
+			Geometry: GeoGeometry{Type: "Point", Coordinates: [2]float64{c.Lon, c.Lat}},
+			Properties: GeoProperties{
+				Kind:     "crossing",
+				PlanetA:  names.Body(c.A),
+				AngleA:   c.AngleA.String(),
+				PlanetB:  names.Body(c.B),
+				AngleB:   c.AngleB.String(),
+				Latitude: &c.Lat,
+			},
+		})
+	}
+	return g
+}
+
+// PrintGeoJSON writes the map as compact JSON to stdout; line coordinates
+// make indented output very long.
+func PrintGeoJSON(g GeoJSON) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// ParanEntry is one pair of planets angular together near a latitude.
+type ParanEntry struct {
+	PlanetA   string  `json:"planet_a"`
+	AngleA    string  `json:"angle_a"`
+	PlanetB   string  `json:"planet_b"`
+	AngleB    string  `json:"angle_b"`
+	Latitude  float64 `json:"latitude"`  // where the lines cross exactly
+	Longitude float64 `json:"longitude"` // of the crossing
+}
+
+// ParanReport lists the parans within an orb of a latitude.
+type ParanReport struct {
+	Time     time.Time    `json:"time"`
+	Latitude float64      `json:"latitude"`
+	Orb      float64      `json:"orb"`
+	Parans   []ParanEntry `json:"parans"`
+}
+
+// BuildParans converts crossings selected by astrocartography.Parans into a
+// report.
+func BuildParans(t time.Time, lat, orb float64, parans []astrocartography.Crossing) ParanReport {
+	rep := ParanReport{Time: t, Latitude: lat, Orb: orb, Parans: []ParanEntry{}}
+	for _, c := range parans {
+		rep.Parans = append(rep.Parans, ParanEntry{
+			PlanetA:   names.Body(c.A),
+			AngleA:    c.AngleA.String(),
+			PlanetB:   names.Body(c.B),
+			AngleB:    c.AngleB.String(),
+			Latitude:  c.Lat,
+			Longitude: c.Lon,
+		})
+	}
+	return rep
+}
+
+// PrintParansText writes the report as a table to stdout.
+func PrintParansText(rep ParanReport) error {
+	fmt.Printf("=== Parans within %.2f° of latitude %.4f° for %s ===\n",
+		rep.Orb, rep.Latitude, rep.Time.Format("2006-01-02 15:04 MST"))
+	if len(rep.Parans) == 0 {
+		fmt.Println("No parans.")
+		return nil
+	}
+	for _, p := range rep.Parans {
+		fmt.Printf("%-10s %-3s  with  %-10s %-3s  crossing at %8.4f°, %9.4f°\n",
+			p.PlanetA, p.AngleA, p.PlanetB, p.AngleB, p.Latitude, p.Longitude)
+	}
+	return nil
+}
+
+// PrintParansJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintParansJSON(rep ParanReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dcccxiii/astro/atlas"
+)
+
+// AtlasPlace is a place of the atlas, as "astro atlas search" lists it.
+type AtlasPlace struct {
+	Name        string  `json:"name"`
+	Region      string  `json:"region,omitempty"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	TimeZone    string  `json:"timezone"`
+	Population  int     `json:"population"`
+	// Query is the name that --place resolves to this place.
+	Query string `json:"query"`
+}
+
+// BuildAtlasPlaces converts places found in the atlas.
+func BuildAtlasPlaces(places []atlas.Place) []AtlasPlace {
+	out := []AtlasPlace{}
+	for _, p := range places {
+		out = append(out, AtlasPlace{
+			Name:        p.Name,
+			Region:      p.Region,
+			Country:     p.CountryName(),
+			CountryCode: p.Country,
+			Latitude:    p.Lat,
+			Longitude:   p.Lon,
+			TimeZone:    p.Zone,
+			Population:  p.Population,
+			Query:       p.String(),
+		})
+	}
+	return out
+}
+
+// WriteAtlasText writes the places to w, one per line, with their
+// coordinates, time zone and population.
+func WriteAtlasText(w io.Writer, query string, places []AtlasPlace) error {
+	fmt.Fprintf(w, "=== %d places matching %q ===\n", len(places), query)
+	width, zoneWidth := 0, 0
+	for _, p := range places {
+		width = max(width, utf8.RuneCountInString(p.Query))
+		zoneWidth = max(zoneWidth, len(p.TimeZone))
+	}
+	for _, p := range places {
+		line := fmt.Sprintf("%s  %9.4f  %9.4f  %s  %10d", pad(p.Query, width), p.Latitude, p.Longitude, pad(p.TimeZone, zoneWidth), p.Population)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAtlasJSON writes the places to w as a JSON array, laid out as opt
+// says.
+func WriteAtlasJSON(w io.Writer, places []AtlasPlace, opt JSONOptions) error {
+	return writeJSON(w, places, opt)
+}
+
+// WriteAtlasNDJSON writes the places to w, one per line.
+func WriteAtlasNDJSON(w io.Writer, places []AtlasPlace) error {
+	n := NewNDJSON(w)
+	for _, p := range places {
+		if err := n.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
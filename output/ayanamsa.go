@@ -0,0 +1,42 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// AyanamsaReport compares ayanamsas at one moment.
+type AyanamsaReport struct {
+	Time      time.Time      `json:"datetime"`
+	JulianDay float64        `json:"julian_day"`
+	Ayanamsas []SiderealInfo `json:"ayanamsas"` // in the order asked for
+}
+
+// WriteAyanamsaText writes the report to w, one ayanamsa a line, each after
+// the first with its difference from the first.
+func WriteAyanamsaText(w io.Writer, rep AyanamsaReport) error {
+	fmt.Fprintf(w, "=== Ayanamsas at %s (JD %.6f) ===\n", rep.Time.Format("2006-01-02 15:04 MST"), rep.JulianDay)
+	width := 13
+	for _, a := range rep.Ayanamsas {
+		width = max(width, len([]rune(a.Ayanamsa)))
+	}
+	for i, a := range rep.Ayanamsas {
+		diff := ""
+		if i > 0 {
+			first := rep.Ayanamsas[0]
+			diff = fmt.Sprintf("  %+.4f° from %s", a.Degrees-first.Degrees, first.Ayanamsa)
+		}
+		fmt.Fprintf(w, "%-*s  %9.4f°  %s%s\n", width, a.Ayanamsa, a.Degrees, angle.FormatDMS(a.Degrees), diff)
+	}
+	return nil
+}
+
+// PrintAyanamsaJSON writes the report as JSON to stdout, laid out as opt
+// says.
+func PrintAyanamsaJSON(rep AyanamsaReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
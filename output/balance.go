@@ -0,0 +1,117 @@
+package output
+
+import (
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/zodiac"
+)
+
+// LuminaryWeight is what the Sun, the Moon and the Ascendant count for in
+// a weighted balance; every other planet counts 1.
+const LuminaryWeight = 2
+
+// ElementCounts tallies points by the element of their sign.
+type ElementCounts struct {
+	Fire  float64 `json:"fire"`
+	Earth float64 `json:"earth"`
+	Air   float64 `json:"air"`
+	Water float64 `json:"water"`
+}
+
+// ModalityCounts tallies points by the modality of their sign.
+type ModalityCounts struct {
+	Cardinal float64 `json:"cardinal"`
+	Fixed    float64 `json:"fixed"`
+	Mutable  float64 `json:"mutable"`
+}
+
+// BalanceInfo is the element and modality balance of a chart.
+type BalanceInfo struct {
+	Weighted   bool           `json:"weighted"`
+	Elements   ElementCounts  `json:"elements"`
+	Modalities ModalityCounts `json:"modalities"`
+}
+
+// AddBalance tallies r's planets, lunar nodes excluded, and its Ascendant
+// by element and modality. If weighted, the Sun, the Moon and the
+// Ascendant count LuminaryWeight. Build calls it unweighted.
+func AddBalance(r *Result, weighted bool) {
+	b := &BalanceInfo{Weighted: weighted}
+	add := func(lon, w float64) {
+		switch zodiac.Element(lon) {
+		case "fire":
+			b.Elements.Fire += w
+		case "earth":
+			b.Elements.Earth += w
+		case "air":
+			b.Elements.Air += w
+		case "water":
+			b.Elements.Water += w
+		}
+		switch zodiac.Modality(lon) {
+		case "cardinal":
+			b.Modalities.Cardinal += w
+		case "fixed":
+			b.Modalities.Fixed += w
+		case "mutable":
+			b.Modalities.Mutable += w
+		}
+	}
+	heavy := 1.0
+	if weighted {
+		heavy = LuminaryWeight
+	}
+	sun, moon := names.Body(ephemeris.Sun), names.Body(ephemeris.Moon)
+	for _, p := range r.Planets {
+		switch {
+		case isNode(p.Name):
+		case p.Name == sun || p.Name == moon:
+			add(p.Longitude, heavy)
+		default:
+			add(p.Longitude, 1)
+		}
+	}
+	if r.Cusps != nil {
+		add(r.Ascendant.Longitude, heavy)
+	}
+	r.Balance = b
+}
+
+// EmphasisInfo lists the planets, lunar nodes excluded, in each hemisphere
+// and quadrant of the chart by house placement.
+type EmphasisInfo struct {
+	East  []string `json:"east"`  // houses 10-3, around the Ascendant
+	West  []string `json:"west"`  // houses 4-9, around the Descendant
+	North []string `json:"north"` // houses 1-6, below the horizon
+	South []string `json:"south"` // houses 7-12, above the horizon
+	// Quadrants holds houses 1-3, 4-6, 7-9 and 10-12 in turn.
+	Quadrants [4][]string `json:"quadrants"`
+}
+
+// emphasis places r's planets in the hemispheres and quadrants.
+func emphasis(r *Result) *EmphasisInfo {
+	e := &EmphasisInfo{East: []string{}, West: []string{}, North: []string{}, South: []string{}}
+	for i := range e.Quadrants {
+		e.Quadrants[i] = []string{}
+	}
+	houses := houseResult(r)
+	for _, p := range r.Planets {
+		if isNode(p.Name) {
+			continue
+		}
+		h := houses.HouseOf(p.Longitude)
+		if h >= 4 && h <= 9 {
+			e.West = append(e.West, p.Name)
+		} else {
+			e.East = append(e.East, p.Name)
+		}
+		if h <= 6 {
+			e.North = append(e.North, p.Name)
+		} else {
+			e.South = append(e.South, p.Name)
+		}
+		q := (h - 1) / 3
+		e.Quadrants[q] = append(e.Quadrants[q], p.Name)
+	}
+	return e
+}
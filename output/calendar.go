@@ -0,0 +1,287 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/transits"
+)
+
+// CalendarEvent holds presentation-ready data for one event of a calendar.
+type CalendarEvent struct {
+	Time      time.Time `json:"time"` // in the calendar's time zone
+	JulianDay float64   `json:"julian_day"`
+	// Kind is lunation, ingress, station or aspect for the events of the
+	// sky, or transit for an exact transit to the natal chart.
+	Kind    string `json:"kind"`
+	Summary string `json:"summary"`
+	Body    string `json:"body"` // the Moon for a lunation; the transiting planet for a transit
+	Phase   string `json:"phase,omitempty"`
+	Eclipse string `json:"eclipse,omitempty"`
+	// Supermoon is set for a new or full moon near perigee, when the
+	// supermoons were looked for.
+	Supermoon bool   `json:"supermoon,omitempty"`
+	Station   string `json:"station,omitempty"` // retrograde or direct
+	// Season is set for the Sun's ingresses into the cardinal signs: the
+	// March equinox, June solstice, September equinox or December
+	// solstice.
+	Season   string     `json:"season,omitempty"`
+	Aspect   string     `json:"aspect,omitempty"`
+	Natal    string     `json:"natal,omitempty"` // the natal point aspected
+	Position AngleEntry `json:"position"`
+}
+
+// CalendarDay is one day of a calendar.
+type CalendarDay struct {
+	Date   string          `json:"date"` // YYYY-MM-DD
+	Events []CalendarEvent `json:"events"`
+}
+
+// Calendar is the month of events, day by day.
+type Calendar struct {
+	Month    string        `json:"month"` // YYYY-MM
+	TimeZone string        `json:"timezone"`
+	Natal    *time.Time    `json:"natal,omitempty"`
+	Days     []CalendarDay `json:"days"`
+}
+
+// BuildCalendar lays the events of the month beginning at first, midnight
+// in its time zone, out day by day: the mundane events, and the exact
+// transits among the transit events to a natal chart cast at natal, if
+// that is non-zero. Both lists should be in chronological order.
+func BuildCalendar(first time.Time, natal float64, sky []mundane.Event, hits []transits.Event) Calendar {
+	loc := first.Location()
+	c := Calendar{Month: first.Format("2006-01"), TimeZone: loc.String()}
+	if natal != 0 {
+		t := ephemeris.TimeOf(natal)
+		c.Natal = &t
+	}
+	index := map[string]int{}
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		index[d.Format("2006-01-02")] = len(c.Days)
+		c.Days = append(c.Days, CalendarDay{Date: d.Format("2006-01-02"), Events: []CalendarEvent{}})
+	}
+
+	var events []CalendarEvent
+	for _, e := range sky {
+		events = append(events, mundaneEvent(e))
+	}
+	for _, e := range hits {
+		if e.Kind != transits.Exact {
+			continue
+		}
+		events = append(events, CalendarEvent{
+			JulianDay: e.JD,
+			Kind:      "transit",
+			Summary:   fmt.Sprintf("%s %s natal %s", names.Body(e.Body), names.Aspect(e.Aspect.Name), e.Point.Name),
+			Body:      names.Body(e.Body),
+			Aspect:    names.Aspect(e.Aspect.Name),
+			Natal:     e.Point.Name,
+			Position:  angleEntry(e.Longitude),
+		})
+	}
+	for _, e := range events {
+		e.Time = ephemeris.TimeOf(e.JulianDay).In(loc)
+		if i, ok := index[e.Time.Format("2006-01-02")]; ok {
+			c.Days[i].Events = append(c.Days[i].Events, e)
+		}
+	}
+	for _, d := range c.Days {
+		sort.SliceStable(d.Events, func(i, j int) bool { return d.Events[i].JulianDay < d.Events[j].JulianDay })
+	}
+	return c
+}
+
+// mundaneEvent describes a mundane event.
+func mundaneEvent(e mundane.Event) CalendarEvent {
+	body := names.Body(e.Body)
+	pos := angleEntry(e.Longitude)
+	ce := CalendarEvent{JulianDay: e.JD, Kind: e.Kind.String(), Body: body, Position: pos}
+	switch e.Kind {
+	case mundane.Lunation:
+		phase := e.Phase.String()
+		ce.Phase = phase
+		ce.Summary = fmt.Sprintf("%s in %s %s", strings.ToUpper(phase[:1])+phase[1:], pos.Sign, degrees(pos.SignDegree))
+		if e.Eclipse != mundane.NoEclipse {
+			ce.Eclipse = e.Eclipse.String()
+			ce.Summary += ", " + ce.Eclipse
+		}
+		if e.Supermoon {
+			ce.Supermoon = true
+			ce.Summary += ", supermoon"
+		}
+	case mundane.Ingress:
+		sign := names.Default.Sign(e.Sign)
+		ce.Summary = fmt.Sprintf("%s enters %s", body, sign)
+		if e.Retrograde {
+			ce.Summary = fmt.Sprintf("%s re-enters %s, retrograde", body, sign)
+		}
+		if e.Body == ephemeris.Sun && e.Sign%3 == 0 {
+			ce.Season = seasons[e.Sign/3]
+			ce.Summary += ", the " + ce.Season
+		}
+	case mundane.Station:
+		ce.Station = "direct"
+		if e.Retrograde {
+			ce.Station = "retrograde"
+		}
+		ce.Summary = fmt.Sprintf("%s stations %s in %s %s", body, ce.Station, pos.Sign, degrees(pos.SignDegree))
+	case mundane.Aspect:
+		ce.Aspect = names.Aspect(e.Aspect.Name)
+		ce.Summary = fmt.Sprintf("%s %s %s", body, ce.Aspect, names.Body(e.Other))
+	}
+	return ce
+}
+
+// seasons names the Sun's ingresses into Aries, Cancer, Libra and
+// Capricorn by their months, which holds in either hemisphere.
+var seasons = [4]string{"March equinox", "June solstice", "September equinox", "December solstice"}
+
+// degrees formats a degree within a sign as degrees and minutes, e.g. 9°35'.
+func degrees(d float64) string {
+	m := int(d*60 + 0.5)
+	return fmt.Sprintf("%d°%02d'", m/60, m%60)
+}
+
+// title returns the heading of the calendar, e.g. "July 2025".
+func (c Calendar) title() string {
+	t, _ := time.Parse("2006-01", c.Month)
+	return t.Format("January 2006")
+}
+
+// WriteCalendarText writes the calendar to w, a line for each day and one
+// under it for each further event.
+func WriteCalendarText(w io.Writer, c Calendar) error {
+	fmt.Fprintf(w, "=== %s (%s) ===\n", c.title(), c.TimeZone)
+	if c.Natal != nil {
+		fmt.Fprintf(w, "With exact transits to the %s natal chart\n", c.Natal.Format("2006-01-02 15:04 MST"))
+	}
+	fmt.Fprintln(w)
+	for _, d := range c.Days {
+		t, _ := time.Parse("2006-01-02", d.Date)
+		day := t.Format("Mon _2")
+		if len(d.Events) == 0 {
+			fmt.Fprintln(w, day)
+		}
+		for i, e := range d.Events {
+			if i > 0 {
+				day = strings.Repeat(" ", len(day))
+			}
+			fmt.Fprintf(w, "%s  %s  %s\n", day, e.Time.Format("15:04"), e.Summary)
+		}
+	}
+	return nil
+}
+
+// WriteCalendarMarkdown writes the calendar to w as a Markdown table.
+func WriteCalendarMarkdown(w io.Writer, c Calendar) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.title())
+	fmt.Fprintf(&b, "- **Time zone:** %s\n", c.TimeZone)
+	if c.Natal != nil {
+		fmt.Fprintf(&b, "- **Transits to:** %s natal chart\n", c.Natal.Format("2006-01-02 15:04 MST"))
+	}
+	b.WriteString("\n| Date | Time | Event |\n|---|---|---|\n")
+	for _, d := range c.Days {
+		t, _ := time.Parse("2006-01-02", d.Date)
+		day := t.Format("Mon 2 Jan")
+		if len(d.Events) == 0 {
+			fmt.Fprintf(&b, "| %s | | |\n", day)
+		}
+		for i, e := range d.Events {
+			if i > 0 {
+				day = ""
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", day, e.Time.Format("15:04"), e.Summary)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCalendarICS writes the calendar's events to w as an iCalendar
+// (RFC 5545) file, each a moment in UTC, for importing into a calendar
+// application. stamp is the time the file is made.
+func WriteCalendarICS(w io.Writer, c Calendar, stamp time.Time) error {
+	var events []CalendarEvent
+	for _, d := range c.Days {
+		events = append(events, d.Events...)
+	}
+	return writeICS(w, events, stamp)
+}
+
+// writeICS writes events to w as an iCalendar file, as WriteCalendarICS
+// describes.
+func writeICS(w io.Writer, events []CalendarEvent, stamp time.Time) error {
+	const utc = "20060102T150405Z"
+	var b strings.Builder
+	line := func(s string) {
+		// Lines longer than 75 octets are folded onto lines starting with
+		// a space, without splitting a UTF-8 sequence.
+		for len(s) > 75 {
+			n := 75
+			for n > 0 && s[n]&0xC0 == 0x80 {
+				n--
+			}
+			b.WriteString(s[:n] + "\r\n")
+			s = " " + s[n:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//astro//calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, e := range events {
+		start := e.Time.UTC().Format(utc)
+		line("BEGIN:VEVENT")
+		line("UID:" + start + "-" + slug(e.Summary) + "@astro")
+		line("DTSTAMP:" + stamp.UTC().Format(utc))
+		line("DTSTART:" + start)
+		line("DTEND:" + start)
+		line("SUMMARY:" + icsEscape(e.Summary))
+		line("CATEGORIES:" + e.Kind)
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsEscape escapes the characters iCalendar text values reserve.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// slug returns s in lower case with each run of other characters than
+// ASCII letters and digits made a hyphen, for an identifier.
+func slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// PrintCalendarJSON writes the calendar as JSON to stdout, laid out as opt
+// says.
+func PrintCalendarJSON(c Calendar, opt JSONOptions) error {
+	return writeJSON(os.Stdout, c, opt)
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dcccxiii/astro/names"
+)
+
+// csvHeader names the columns WriteCSV writes.
+var csvHeader = []string{"kind", "name", "longitude", "sign", "sign_degree", "speed", "house"}
+
+// WriteCSV writes the chart's positions to w as CSV, one row per point
+// under csvHeader. kind is planet, point, heliocentric, angle or cusp;
+// speed is empty for points, angles and cusps, and house is empty for a
+// chart without houses.
+func WriteCSV(w io.Writer, r Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	house := func(lon float64) string { return "" }
+	if r.Cusps != nil {
+		h := houseResult(&r)
+		house = func(lon float64) string { return strconv.Itoa(h.HouseOf(lon)) }
+	}
+	for _, p := range r.Planets {
+		cw.Write([]string{"planet", p.Name, num(p.Longitude), p.Sign, num(p.SignDegree), num(p.Speed), house(p.Longitude)})
+	}
+	for _, p := range r.Points {
+		cw.Write([]string{"point", p.Name, num(p.Longitude), p.Sign, num(p.SignDegree), "", strconv.Itoa(p.House)})
+	}
+	for _, p := range r.Heliocentric {
+		cw.Write([]string{"heliocentric", p.Name, num(p.Longitude), p.Sign, num(p.SignDegree), num(p.Speed), ""})
+	}
+	if r.Cusps != nil {
+		for _, a := range []struct {
+			name string
+			e    AngleEntry
+		}{{names.Point(names.Ascendant), r.Ascendant}, {names.Point(names.MC), r.MC}} {
+			cw.Write([]string{"angle", a.name, num(a.e.Longitude), a.e.Sign, num(a.e.SignDegree), "", house(a.e.Longitude)})
+		}
+		for _, c := range r.Cusps {
+			cw.Write([]string{"cusp", fmt.Sprintf("House %d", c.House), num(c.Longitude), c.Sign, num(c.SignDegree), "", strconv.Itoa(c.House)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/cycles"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// CycleEntry holds presentation-ready data for one exact cycle phase.
+type CycleEntry struct {
+	Time      time.Time     `json:"time"`
+	JulianDay float64       `json:"julian_day"`
+	Planets   [2]string     `json:"planets"` // faster planet first
+	Phase     string        `json:"phase"`
+	Positions [2]AngleEntry `json:"positions"`
+}
+
+// CycleTimeline is the dated list of cycle phases produced by a scan.
+type CycleTimeline struct {
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	Entries []CycleEntry `json:"events"`
+}
+
+// BuildCycles converts scanned events into a timeline. events should already
+// be in chronological order.
+func BuildCycles(from, to float64, events []cycles.Event) CycleTimeline {
+	tl := CycleTimeline{From: ephemeris.TimeOf(from), To: ephemeris.TimeOf(to)}
+	for _, e := range events {
+		tl.Entries = append(tl.Entries, CycleEntry{
+			Time:      ephemeris.TimeOf(e.JD),
+			JulianDay: e.JD,
+			Planets:   [2]string{names.Body(e.Fast), names.Body(e.Slow)},
+			Phase:     e.Phase.Name,
+			Positions: [2]AngleEntry{angleEntry(e.FastLon), angleEntry(e.SlowLon)},
+		})
+	}
+	return tl
+}
+
+func angleEntry(lon float64) AngleEntry {
+	sign, deg := names.SignOf(lon)
+	return AngleEntry{Longitude: lon, Sign: sign, SignDegree: deg}
+}
+
+// PrintCyclesText writes the timeline as a table to stdout.
+func PrintCyclesText(tl CycleTimeline) error {
+	fmt.Printf("=== Cycle Timeline %s to %s ===\n", tl.From.Format("2006-01-02"), tl.To.Format("2006-01-02"))
+	for _, e := range tl.Entries {
+		fmt.Printf("%s  %-17s  %-13s  %s %5.2f° / %s %5.2f°\n",
+			e.Time.Format("2006-01-02 15:04"),
+			e.Planets[0]+"-"+e.Planets[1], e.Phase,
+			e.Positions[0].Sign, e.Positions[0].SignDegree,
+			e.Positions[1].Sign, e.Positions[1].SignDegree)
+	}
+	return nil
+}
+
+// PrintCyclesJSON writes the timeline as JSON to stdout, laid out as opt says.
+func PrintCyclesJSON(tl CycleTimeline, opt JSONOptions) error {
+	return writeJSON(os.Stdout, tl, opt)
+}
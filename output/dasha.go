@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/vedic"
+)
+
+// DashaPeriod is one dasha at any level.
+type DashaPeriod struct {
+	Lord    string        `json:"lord"`
+	Start   time.Time     `json:"start"`
+	End     time.Time     `json:"end"`
+	Current bool          `json:"current,omitempty"` // in force at the timeline's At time
+	Sub     []DashaPeriod `json:"sub_periods,omitempty"`
+}
+
+// DashaTimeline is the Vimshottari dasha sequence of a natal chart.
+type DashaTimeline struct {
+	Natal     time.Time      `json:"natal"`
+	Sidereal  SiderealInfo   `json:"sidereal"`
+	Moon      AngleEntry     `json:"moon"` // sidereal
+	Nakshatra NakshatraEntry `json:"nakshatra"`
+	At        time.Time      `json:"at"`
+	Periods   []DashaPeriod  `json:"periods"`
+}
+
+// BuildDasha converts dashas from vedic.Vimshottari into a timeline,
+// marking the periods in force at at.
+func BuildDasha(natal time.Time, sid SiderealInfo, moon float64, at time.Time, dashas []vedic.Dasha) DashaTimeline {
+	n := vedic.NakshatraOf(moon)
+	tl := DashaTimeline{
+		Natal:     natal,
+		Sidereal:  sid,
+		Moon:      angleEntry(moon),
+		Nakshatra: NakshatraEntry{Name: n.Name(), Pada: n.Pada, Lord: grahaName(n.Lord)},
+		At:        at,
+		Periods:   []DashaPeriod{},
+	}
+	for _, d := range dashas {
+		tl.Periods = append(tl.Periods, dashaPeriod(d, at))
+	}
+	return tl
+}
+
+func dashaPeriod(d vedic.Dasha, at time.Time) DashaPeriod {
+	dp := DashaPeriod{
+		Lord:    grahaName(d.Lord),
+		Start:   d.Start,
+		End:     d.End,
+		Current: !at.Before(d.Start) && at.Before(d.End),
+	}
+	for _, s := range d.Sub {
+		dp.Sub = append(dp.Sub, dashaPeriod(s, at))
+	}
+	return dp
+}
+
+// PrintDashaText writes the timeline to stdout, indenting each level under
+// its parent. Periods in force are marked.
+func PrintDashaText(tl DashaTimeline) error {
+	fmt.Printf("=== Vimshottari dasha for %s natal chart ===\n", tl.Natal.Format("2006-01-02 15:04"))
+	fmt.Printf("Moon %.4f° (%s %.2f°, %s ayanamsa) in %s pada %d, ruled by %s\n\n",
+		tl.Moon.Longitude, tl.Moon.Sign, tl.Moon.SignDegree, tl.Sidereal.Ayanamsa,
+		tl.Nakshatra.Name, tl.Nakshatra.Pada, tl.Nakshatra.Lord)
+	for _, p := range tl.Periods {
+		printDashaPeriod(p, 0)
+	}
+	return nil
+}
+
+func printDashaPeriod(p DashaPeriod, depth int) {
+	mark := ""
+	if p.Current {
+		mark = "  <- current"
+	}
+	fmt.Printf("%s%-8s  %s  to  %s%s\n", strings.Repeat("  ", depth), p.Lord,
+		p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"), mark)
+	for _, s := range p.Sub {
+		printDashaPeriod(s, depth+1)
+	}
+}
+
+// PrintDashaJSON writes the timeline as JSON to stdout, laid out as opt says.
+func PrintDashaJSON(tl DashaTimeline, opt JSONOptions) error {
+	return writeJSON(os.Stdout, tl, opt)
+}
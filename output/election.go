@@ -0,0 +1,125 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/election"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// ElectionMatch is one window found by an election search.
+type ElectionMatch struct {
+	Rank      int       `json:"rank"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Score     float64   `json:"score"`
+	Preferred []string  `json:"preferred"` // the preferences met
+}
+
+// ElectionReport is the ranked result of an election search.
+type ElectionReport struct {
+	Lat         float64         `json:"lat"`
+	Lon         float64         `json:"lon"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	StepMinutes float64         `json:"step_minutes"`
+	Required    []string        `json:"required"`
+	Preferred   []string        `json:"preferred"`
+	Found       int             `json:"found"` // windows found, before the limit
+	Matches     []ElectionMatch `json:"matches"`
+}
+
+// BuildElection converts ranked matches into a report, keeping the best
+// limit of them (all if limit is 0).
+func BuildElection(criteria []election.Criterion, matches []election.Match, fromJD, toJD, step, lat, lon float64, limit int) ElectionReport {
+	rep := ElectionReport{
+		Lat:         lat,
+		Lon:         lon,
+		From:        ephemeris.TimeOf(fromJD),
+		To:          ephemeris.TimeOf(toJD),
+		StepMinutes: step * 24 * 60,
+		Required:    []string{},
+		Preferred:   []string{},
+		Found:       len(matches),
+		Matches:     []ElectionMatch{},
+	}
+	for _, c := range criteria {
+		if c.Prefer {
+			rep.Preferred = append(rep.Preferred, preference(c))
+		} else {
+			rep.Required = append(rep.Required, c.String())
+		}
+	}
+	for i, m := range matches {
+		if limit > 0 && i == limit {
+			break
+		}
+		em := ElectionMatch{
+			Rank:      i + 1,
+			Start:     ephemeris.TimeOf(m.Start),
+			End:       ephemeris.TimeOf(m.End),
+			Score:     m.Score,
+			Preferred: []string{},
+		}
+		for _, c := range m.Preferred {
+			em.Preferred = append(em.Preferred, preference(c))
+		}
+		rep.Matches = append(rep.Matches, em)
+	}
+	return rep
+}
+
+// PrintElectionText writes the ranked windows to stdout.
+func PrintElectionText(rep ElectionReport) error {
+	fmt.Printf("=== Election search for (%.4f°, %.4f°) ===\n", rep.Lat, rep.Lon)
+	fmt.Printf("%s to %s, every %g minutes\n", rep.From.Format("2006-01-02 15:04"), rep.To.Format("2006-01-02 15:04"), rep.StepMinutes)
+	if len(rep.Required) > 0 {
+		fmt.Printf("Required:  %s\n", strings.Join(rep.Required, "; "))
+	}
+	if len(rep.Preferred) > 0 {
+		fmt.Printf("Preferred: %s\n", strings.Join(rep.Preferred, "; "))
+	}
+	fmt.Println()
+	if rep.Found == 0 {
+		fmt.Println("No moment meets the required criteria.")
+		return nil
+	}
+	if len(rep.Matches) < rep.Found {
+		fmt.Printf("%d windows found; the best %d:\n\n", rep.Found, len(rep.Matches))
+	} else {
+		fmt.Printf("%d windows found:\n\n", rep.Found)
+	}
+	for _, m := range rep.Matches {
+		fmt.Printf("%3d  %s - %s  (%s)  score %g", m.Rank,
+			m.Start.Format("2006-01-02 15:04"), m.End.Format("2006-01-02 15:04"),
+			formatSpan(m.End.Sub(m.Start)), m.Score)
+		if len(m.Preferred) > 0 {
+			fmt.Printf("  %s", strings.Join(m.Preferred, "; "))
+		}
+		fmt.Println()
+	}
+	fmt.Println("\nTimes are UTC; window edges are accurate to the step.")
+	return nil
+}
+
+// PrintElectionJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintElectionJSON(rep ElectionReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
+
+// preference describes a preferred criterion with its weight, if not 1.
+func preference(c election.Criterion) string {
+	if c.Weight != 1 {
+		return fmt.Sprintf("%s (×%g)", c.Condition(), c.Weight)
+	}
+	return c.Condition()
+}
+
+// formatSpan formats a duration as hours and minutes, e.g. "2h40m".
+func formatSpan(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// EpheAsteroid is the ephemeris file of a numbered asteroid, as "astro
+// ephe list" lists it.
+type EpheAsteroid struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	File   string `json:"file"`  // relative to the ephemeris directory
+	Short  bool   `json:"short"` // the short file, covering fewer centuries
+	// Used is false for a file passed over for another of the same
+	// asteroid that the library finds first.
+	Used bool `json:"used"`
+}
+
+// EpheReport lists the numbered-asteroid files of the ephemeris directory.
+type EpheReport struct {
+	Directory string         `json:"directory"`
+	Asteroids []EpheAsteroid `json:"asteroids"`
+}
+
+// WriteEpheText writes the report to w, one file a line with the
+// asteroid's number and name, noting the short files and those unused.
+func WriteEpheText(w io.Writer, rep EpheReport) error {
+	fmt.Fprintf(w, "=== %d asteroid files in %s ===\n", len(rep.Asteroids), rep.Directory)
+	width, fileWidth := 0, 0
+	for _, a := range rep.Asteroids {
+		width = max(width, utf8.RuneCountInString(a.Name))
+		fileWidth = max(fileWidth, len(a.File))
+	}
+	for i, a := range rep.Asteroids {
+		var notes []string
+		if a.Short {
+			notes = append(notes, "short")
+		}
+		if !a.Used {
+			// The file used comes first among those of its asteroid.
+			first := i
+			for first > 0 && rep.Asteroids[first-1].Number == a.Number {
+				first--
+			}
+			notes = append(notes, "unused: "+rep.Asteroids[first].File+" is found first")
+		}
+		line := fmt.Sprintf("%7d  %s  %s  %s", a.Number, pad(a.Name, width), pad(a.File, fileWidth), strings.Join(notes, "; "))
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEpheJSON writes the report to w as JSON, laid out as opt says.
+func WriteEpheJSON(w io.Writer, rep EpheReport, opt JSONOptions) error {
+	return writeJSON(w, rep, opt)
+}
@@ -0,0 +1,215 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/parallel"
+)
+
+// EphemerisPosition is one body's position in a row of an ephemeris table.
+type EphemerisPosition struct {
+	Body       string  `json:"body"`
+	Longitude  float64 `json:"longitude"`
+	Sign       string  `json:"sign"`
+	SignDegree float64 `json:"sign_degree"`
+	Speed      float64 `json:"speed"`
+	Retrograde bool    `json:"retrograde"`
+	// Ingress is set when the body is in another sign than at the previous
+	// row.
+	Ingress bool `json:"ingress"`
+}
+
+// EphemerisRow holds the positions of the table's bodies at one time.
+type EphemerisRow struct {
+	Time      time.Time           `json:"time"`
+	JulianDay float64             `json:"julian_day"`
+	Positions []EphemerisPosition `json:"positions"`
+}
+
+// EphemerisTable is a classic ephemeris: the positions of a set of bodies
+// at regular steps over a range of time.
+type EphemerisTable struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	StepDays float64        `json:"step_days"`
+	Bodies   []string       `json:"bodies"`
+	Rows     []EphemerisRow `json:"rows"`
+
+	bodies []int // the IDs of Bodies, for EphemerisGraph
+}
+
+// BuildEphemeris computes a row every step days from from up to and
+// including to, asking p for all of bodies at once at each row (see
+// ephemeris.CalcPlanets). The rows are computed on workers goroutines, or
+// one per CPU if workers is 0 (see parallel.Workers).
+func BuildEphemeris(p ephemeris.Provider, bodies []int, from, to, step float64, workers int) (EphemerisTable, error) {
+	t := EphemerisTable{
+		From:     ephemeris.TimeOf(from),
+		To:       ephemeris.TimeOf(to),
+		StepDays: step,
+		Bodies:   bodyNames(bodies),
+		Rows:     []EphemerisRow{},
+		bodies:   bodies,
+	}
+	err := EphemerisRows(p, bodies, from, to, step, workers, func(row EphemerisRow) error {
+		t.Rows = append(t.Rows, row)
+		return nil
+	})
+	if err != nil {
+		return EphemerisTable{}, err
+	}
+	return t, nil
+}
+
+// EphemerisRows computes the rows of the table BuildEphemeris builds and
+// passes each to emit, in order, as soon as it and the rows before it are
+// ready, rather than after the whole table. It stops at the first error,
+// of the calculation or of emit, and returns it.
+func EphemerisRows(p ephemeris.Provider, bodies []int, from, to, step float64, workers int, emit func(EphemerisRow) error) error {
+	// Multiplying rather than accumulating keeps long tables on the step.
+	jdAt := func(i int) float64 { return from + float64(i)*step }
+	n := 0
+	for jdAt(n) <= to {
+		n++
+	}
+	// Ingresses compare each row with the one before, so the rows are
+	// assembled in order, as Ordered emits them.
+	prev := make([]int, len(bodies))
+	return parallel.Ordered(n, workers, func(i int) ([]ephemeris.PlanetPos, error) {
+		pos, err := ephemeris.CalcPlanets(p, jdAt(i), bodies)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating positions at %s: %w", ephemeris.TimeOf(jdAt(i)).Format(time.RFC3339), err)
+		}
+		return pos, nil
+	}, func(i int, pos []ephemeris.PlanetPos) error {
+		jd := jdAt(i)
+		row := EphemerisRow{Time: ephemeris.TimeOf(jd), JulianDay: jd}
+		for j, body := range bodies {
+			sign, deg := names.SignOf(pos[j].Longitude)
+			idx := int(pos[j].Longitude / 30)
+			row.Positions = append(row.Positions, EphemerisPosition{
+				Body:       names.Body(body),
+				Longitude:  pos[j].Longitude,
+				Sign:       sign,
+				SignDegree: deg,
+				Speed:      pos[j].SpeedLon,
+				Retrograde: pos[j].SpeedLon < 0,
+				Ingress:    i > 0 && idx != prev[j],
+			})
+			prev[j] = idx
+		}
+		return emit(row)
+	})
+}
+
+// WriteEphemerisText writes the table to w with a column per body, each
+// position as degrees, a three-letter sign and minutes, e.g. 11°CAP02'.
+// R marks a retrograde body and * one that entered its sign since the
+// previous row.
+func WriteEphemerisText(w io.Writer, t EphemerisTable) error {
+	layout := "2006-01-02 15:04"
+	if t.StepDays == math.Trunc(t.StepDays) && t.From.Equal(t.From.Truncate(24*time.Hour)) {
+		layout = "2006-01-02"
+	}
+	const cellWidth = 11 // 11°CAP02'R*
+	widths := make([]int, len(t.Bodies))
+	for i, b := range t.Bodies {
+		widths[i] = max(cellWidth, utf8.RuneCountInString(b))
+	}
+
+	fmt.Fprintf(w, "=== Ephemeris %s to %s, every %s ===\n", t.From.Format(layout), t.To.Format(layout), stepText(t.StepDays))
+	var b strings.Builder
+	b.WriteString(pad("Date", len(layout)))
+	for i, name := range t.Bodies {
+		b.WriteString("  " + pad(name, widths[i]))
+	}
+	fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	for _, row := range t.Rows {
+		b.Reset()
+		b.WriteString(row.Time.Format(layout))
+		for i, p := range row.Positions {
+			b.WriteString("  " + pad(ephemerisCell(p), widths[i]))
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+	_, err := fmt.Fprintln(w, "R retrograde, * entered the sign since the previous row")
+	return err
+}
+
+// ephemerisCell formats a position for the text table. Minutes are
+// truncated, as in printed ephemerides, so that they never show 60.
+func ephemerisCell(p EphemerisPosition) string {
+	deg := int(p.SignDegree)
+	mins := int((p.SignDegree - float64(deg)) * 60)
+	s := fmt.Sprintf("%2d°%s%02d'", deg, label(p.Sign, 3), mins)
+	if p.Retrograde {
+		s += "R"
+	}
+	if p.Ingress {
+		s += "*"
+	}
+	return s
+}
+
+// pad pads s with spaces to n runes.
+func pad(s string, n int) string {
+	if c := utf8.RuneCountInString(s); c < n {
+		return s + strings.Repeat(" ", n-c)
+	}
+	return s
+}
+
+// stepText describes a step in days, e.g. "1d" or "6h00m".
+func stepText(days float64) string {
+	if days == math.Trunc(days) {
+		return fmt.Sprintf("%gd", days)
+	}
+	return formatSpan(time.Duration(days * float64(24*time.Hour)))
+}
+
+// ephemerisCSVHeader names the columns WriteEphemerisCSV writes.
+var ephemerisCSVHeader = []string{"time", "julian_day", "body", "longitude", "sign", "sign_degree", "speed", "retrograde", "ingress"}
+
+// WriteEphemerisCSV writes the table to w as CSV, one row per body and
+// time under ephemerisCSVHeader.
+func WriteEphemerisCSV(w io.Writer, t EphemerisTable) error {
+	cw := csv.NewWriter(w)
+	cw.Write(ephemerisCSVHeader)
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	for _, row := range t.Rows {
+		for _, p := range row.Positions {
+			cw.Write([]string{
+				row.Time.Format(time.RFC3339), num(row.JulianDay), p.Body,
+				num(p.Longitude), p.Sign, num(p.SignDegree), num(p.Speed),
+				strconv.FormatBool(p.Retrograde), strconv.FormatBool(p.Ingress),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEphemerisJSON writes the table to w as JSON, laid out as opt says.
+func WriteEphemerisJSON(w io.Writer, t EphemerisTable, opt JSONOptions) error {
+	return writeJSON(w, t, opt)
+}
+
+// WriteEphemerisNDJSON writes the table's rows to w, one per line.
+func WriteEphemerisNDJSON(w io.Writer, t EphemerisTable) error {
+	n := NewNDJSON(w)
+	for _, row := range t.Rows {
+		if err := n.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
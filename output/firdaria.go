@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/firdaria"
+	"github.com/dcccxiii/astro/names"
+)
+
+// FirdariaPeriod is one major period or sub-period.
+type FirdariaPeriod struct {
+	Ruler   string           `json:"ruler"`
+	Start   time.Time        `json:"start"`
+	End     time.Time        `json:"end"`
+	Current bool             `json:"current,omitempty"` // in force at the report's At time
+	Sub     []FirdariaPeriod `json:"sub_periods,omitempty"`
+}
+
+// FirdariaTimeline is the firdaria of a natal chart.
+type FirdariaTimeline struct {
+	Natal   time.Time        `json:"natal"`
+	Sect    string           `json:"sect"` // "day" or "night"
+	At      time.Time        `json:"at"`
+	Periods []FirdariaPeriod `json:"periods"`
+}
+
+// BuildFirdaria converts periods from firdaria.Periods into a timeline,
+// marking the periods in force at at.
+func BuildFirdaria(natal time.Time, day bool, at time.Time, periods []firdaria.Period) FirdariaTimeline {
+	tl := FirdariaTimeline{Natal: natal, Sect: "night", At: at, Periods: []FirdariaPeriod{}}
+	if day {
+		tl.Sect = "day"
+	}
+	for _, p := range periods {
+		tl.Periods = append(tl.Periods, firdariaPeriod(p, at))
+	}
+	return tl
+}
+
+func firdariaPeriod(p firdaria.Period, at time.Time) FirdariaPeriod {
+	fp := FirdariaPeriod{
+		Ruler:   rulerName(p.Ruler),
+		Start:   p.Start,
+		End:     p.End,
+		Current: !at.Before(p.Start) && at.Before(p.End),
+	}
+	for _, s := range p.Sub {
+		fp.Sub = append(fp.Sub, firdariaPeriod(s, at))
+	}
+	return fp
+}
+
+// rulerName returns the display name of a period lord.
+func rulerName(r firdaria.Ruler) string {
+	switch r {
+	case firdaria.NorthNode:
+		return names.Point(names.NorthNode)
+	case firdaria.SouthNode:
+		return names.Point(names.SouthNode)
+	}
+	return names.Body(int(r))
+}
+
+// PrintFirdariaText writes the timeline to stdout, each major period
+// followed by its indented sub-periods. Periods in force are marked.
+func PrintFirdariaText(tl FirdariaTimeline) error {
+	fmt.Printf("=== Firdaria for %s natal chart (%s birth) ===\n", tl.Natal.Format("2006-01-02 15:04"), tl.Sect)
+	for _, p := range tl.Periods {
+		printFirdariaPeriod(p, "")
+		for _, s := range p.Sub {
+			printFirdariaPeriod(s, "  ")
+		}
+	}
+	return nil
+}
+
+func printFirdariaPeriod(p FirdariaPeriod, indent string) {
+	mark := ""
+	if p.Current {
+		mark = "  <- current"
+	}
+	fmt.Printf("%s%-12s  %s  to  %s%s\n", indent, p.Ruler,
+		p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"), mark)
+}
+
+// PrintFirdariaJSON writes the timeline as JSON to stdout, laid out as opt says.
+func PrintFirdariaJSON(tl FirdariaTimeline, opt JSONOptions) error {
+	return writeJSON(os.Stdout, tl, opt)
+}
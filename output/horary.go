@@ -0,0 +1,126 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/horary"
+	"github.com/dcccxiii/astro/lunar"
+	"github.com/dcccxiii/astro/names"
+)
+
+// HoraryCheck is one consideration before judgement.
+type HoraryCheck struct {
+	Consideration string `json:"consideration"`
+	OK            bool   `json:"ok"`
+	Detail        string `json:"detail"`
+}
+
+// HoraryInfo is the checklist of horary considerations for a chart.
+type HoraryInfo struct {
+	Radical        bool          `json:"radical"`
+	AscendantRuler string        `json:"ascendant_ruler"`
+	HourRuler      string        `json:"hour_ruler,omitempty"` // empty if there are no planetary hours
+	HourAgreement  string        `json:"hour_agreement"`
+	Checks         []HoraryCheck `json:"checks"`
+}
+
+// horaryPlanets are the planets the considerations look at.
+var horaryPlanets = []int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mercury, ephemeris.Venus,
+	ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn,
+}
+
+// AddHorary weighs r's chart as a horary question asked in the planetary
+// hour of hourRuler (-1 if there are none) and attaches the checklist.
+// The houses are r's own; the planets are computed by p.
+func AddHorary(r *Result, p ephemeris.Provider, hourRuler int) error {
+	planets := make(map[int]ephemeris.PlanetPos, len(horaryPlanets))
+	for _, body := range horaryPlanets {
+		pos, err := p.CalcPlanet(r.JulianDay, body)
+		if err != nil {
+			return fmt.Errorf("error calculating %s: %w", names.Body(body), err)
+		}
+		planets[body] = pos
+	}
+	rep := horary.Consider(planets, houseResult(r), hourRuler)
+
+	info := &HoraryInfo{
+		Radical:        rep.Radical(),
+		AscendantRuler: names.Body(rep.AscRuler),
+		HourAgreement:  rep.Agreement.String(),
+	}
+	if hourRuler >= 0 {
+		info.HourRuler = names.Body(hourRuler)
+	}
+
+	asc := fmt.Sprintf("%.2f° %s", r.Ascendant.SignDegree, r.Ascendant.Sign)
+	switch {
+	case rep.Early:
+		info.add("ascendant", false, "Ascendant early at %s: too early to judge", asc)
+	case rep.Late:
+		info.add("ascendant", false, "Ascendant late at %s: too late to judge", asc)
+	default:
+		info.add("ascendant", true, "Ascendant at %s, neither early nor late", asc)
+	}
+
+	if rep.MoonVoid {
+		moon, _ := findPlanet(r, ephemeris.Moon)
+		info.add("moon_void_of_course", false, "Moon void of course: no aspect before it leaves %s", moon.Sign)
+	} else {
+		info.add("moon_void_of_course", true, "Moon not void of course: %s", perfection(rep.MoonNext))
+	}
+
+	saturn := names.Body(ephemeris.Saturn)
+	info.add("saturn_in_seventh", !rep.SaturnInSeventh, "%s in the %s house", saturn, ordinal(rep.SaturnHouse))
+
+	if rep.ViaCombusta {
+		info.add("via_combusta", false, "Moon in the via combusta (15° Libra to 15° Scorpio)")
+	} else {
+		info.add("via_combusta", true, "Moon outside the via combusta")
+	}
+
+	lord := names.Body(rep.AscRuler)
+	switch rep.Agreement {
+	case horary.NoHour:
+		info.add("hour_agreement", false, "No planetary hours: the Sun does not rise and set here today")
+	case horary.Same:
+		info.add("hour_agreement", true, "Hour of %s, lord of the Ascendant", lord)
+	case horary.Disagrees:
+		info.add("hour_agreement", false, "Hour of %s disagrees with %s, lord of the Ascendant", info.HourRuler, lord)
+	default:
+		info.add("hour_agreement", true, "Hour of %s agrees with %s, lord of the Ascendant, by %s", info.HourRuler, lord, rep.Agreement)
+	}
+
+	r.Horary = info
+	return nil
+}
+
+func (h *HoraryInfo) add(consideration string, ok bool, format string, args ...any) {
+	h.Checks = append(h.Checks, HoraryCheck{Consideration: consideration, OK: ok, Detail: fmt.Sprintf(format, args...)})
+}
+
+// perfection describes the Moon's next aspect, e.g. "next a trine to
+// Jupiter in 3.7 hours".
+func perfection(p lunar.Perfection) string {
+	when := fmt.Sprintf("%.1f hours", p.Days*24)
+	if p.Days >= 1 {
+		when = fmt.Sprintf("%.1f days", p.Days)
+	}
+	return fmt.Sprintf("next a %s to %s in %s", names.Aspect(p.Aspect.Name), names.Body(p.Body), when)
+}
+
+// ordinal returns n with its English suffix, e.g. "7th".
+func ordinal(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	}
+	return fmt.Sprintf("%dth", n)
+}
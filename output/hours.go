@@ -0,0 +1,106 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/hours"
+	"github.com/dcccxiii/astro/names"
+)
+
+// HourEntry is one planetary hour.
+type HourEntry struct {
+	Number  int       `json:"number"` // 1-12 day, 13-24 night
+	Ruler   string    `json:"ruler"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Night   bool      `json:"night"`
+	Current bool      `json:"current,omitempty"` // contains the report's moment
+}
+
+// HoursReport is the planetary day containing a date or moment.
+type HoursReport struct {
+	Lat         float64     `json:"lat"`
+	Lon         float64     `json:"lon"`
+	Weekday     string      `json:"weekday"`
+	DayRuler    string      `json:"day_ruler"`
+	Sunrise     time.Time   `json:"sunrise"`
+	Sunset      time.Time   `json:"sunset"`
+	NextSunrise time.Time   `json:"next_sunrise"`
+	Moment      *time.Time  `json:"moment,omitempty"`
+	HourRuler   string      `json:"hour_ruler,omitempty"` // ruler of the hour at Moment
+	Hours       []HourEntry `json:"hours"`
+}
+
+// BuildHours converts a planetary day into a report. If moment is non-zero,
+// the hour containing it is marked and its ruler reported.
+func BuildHours(d hours.Day, lat, lon float64, moment time.Time) HoursReport {
+	rep := HoursReport{
+		Lat:         lat,
+		Lon:         lon,
+		Weekday:     d.Weekday.String(),
+		DayRuler:    names.Body(d.Ruler),
+		Sunrise:     ephemeris.TimeOf(d.Sunrise),
+		Sunset:      ephemeris.TimeOf(d.Sunset),
+		NextSunrise: ephemeris.TimeOf(d.NextSunrise),
+		Hours:       []HourEntry{},
+	}
+	var current hours.Hour
+	var ok bool
+	if !moment.IsZero() {
+		rep.Moment = &moment
+		if current, ok = d.At(ephemeris.JulianDay(moment)); ok {
+			rep.HourRuler = names.Body(current.Ruler)
+		}
+	}
+	for _, h := range d.Hours {
+		rep.Hours = append(rep.Hours, HourEntry{
+			Number:  h.Number,
+			Ruler:   names.Body(h.Ruler),
+			Start:   ephemeris.TimeOf(h.Start),
+			End:     ephemeris.TimeOf(h.End),
+			Night:   h.Night(),
+			Current: ok && h.Number == current.Number,
+		})
+	}
+	return rep
+}
+
+// PrintHoursText writes the report as a table to stdout.
+func PrintHoursText(rep HoursReport) error {
+	fmt.Printf("=== Planetary hours for (%.4f°, %.4f°) ===\n", rep.Lat, rep.Lon)
+	fmt.Printf("Day of %s (%s), sunrise %s, sunset %s, next sunrise %s\n",
+		rep.DayRuler, rep.Weekday, rep.Sunrise.Format("2006-01-02 15:04"),
+		rep.Sunset.Format("15:04"), rep.NextSunrise.Format("2006-01-02 15:04"))
+	if rep.Moment != nil {
+		ruler := rep.HourRuler
+		if ruler == "" {
+			ruler = "none"
+		}
+		fmt.Printf("Hour ruler at %s: %s\n", rep.Moment.Format("2006-01-02 15:04 MST"), ruler)
+	}
+	fmt.Println()
+	for _, h := range rep.Hours {
+		if h.Number == 13 {
+			fmt.Println()
+		}
+		period, mark := "day", ""
+		if h.Night {
+			period = "night"
+		}
+		if h.Current {
+			mark = "  <- current"
+		}
+		fmt.Printf("%2d  %-5s  %-8s  %s - %s%s\n", h.Number, period, h.Ruler,
+			h.Start.Format("15:04:05"), h.End.Format("15:04:05"), mark)
+	}
+	fmt.Println("\nTimes are UTC.")
+	return nil
+}
+
+// PrintHoursJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintHoursJSON(rep HoursReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -0,0 +1,191 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dcccxiii/astro/names"
+)
+
+// HouseComparison is a chart's houses cast in several house systems, for
+// astro chart --house-system all.
+type HouseComparison struct {
+	Metadata  *Metadata
+	Local     *LocalInfo
+	Sidereal  *SiderealInfo
+	JulianDay float64
+	Lat, Lon  float64
+	Planets   []PlanetEntry
+	Systems   []HouseSystemEntry // in the order of the charts compared
+	// Skipped names the systems left out because they cannot be cast at
+	// the chart's latitude, within a polar circle.
+	Skipped []string
+}
+
+// HouseSystemEntry is one system's houses, and the house each planet falls
+// in.
+type HouseSystemEntry struct {
+	Key  string `json:"-"`    // the --house-system name, e.g. whole-sign
+	Name string `json:"name"` // in the language of the output
+	// FallbackFrom is the system asked for, which Key names, when Name's
+	// was cast in its place.
+	FallbackFrom string      `json:"fallback_from,omitempty"`
+	Ascendant    AngleEntry  `json:"ascendant"`
+	MC           AngleEntry  `json:"mc"`
+	Cusps        []CuspEntry `json:"cusps"`
+	// Houses maps each planet's name to its house, 1-12.
+	Houses map[string]int `json:"planet_houses"`
+}
+
+// CompareHouses lays side by side the houses of charts, which are the
+// same chart built (see Build) with different house systems. The first
+// chart gives the planets, local time and metadata.
+func CompareHouses(charts []Result) HouseComparison {
+	if len(charts) == 0 {
+		return HouseComparison{}
+	}
+	first := charts[0]
+	m := metadata(&first)
+	m.HouseSystem = ""
+	c := HouseComparison{
+		Metadata: m, Local: first.Local, Sidereal: first.Sidereal,
+		JulianDay: first.JulianDay, Lat: first.Lat, Lon: first.Lon,
+		Planets: first.Planets,
+	}
+	for _, r := range charts {
+		h := houseResult(&r)
+		system := r.houseSystem
+		if r.fallbackSystem != "" {
+			system = r.fallbackSystem
+		}
+		e := HouseSystemEntry{
+			Key:          systemKey(system),
+			Name:         r.HouseName,
+			FallbackFrom: r.HouseFallback,
+			Ascendant:    r.Ascendant,
+			MC:           r.MC,
+			Cusps:        r.Cusps,
+			Houses:       map[string]int{},
+		}
+		for _, p := range r.Planets {
+			e.Houses[p.Name] = h.HouseOf(p.Longitude)
+		}
+		c.Systems = append(c.Systems, e)
+	}
+	return c
+}
+
+// systemKey returns the --house-system name of the system named name in
+// English: in lower case, with hyphens for spaces and punctuation, so
+// "Polich/Page" is polich-page.
+func systemKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// WriteHouseComparisonText writes the comparison to w as two tables with a
+// column for each system: the angles and cusps, then the planets' houses.
+// Positions are in the compact zodiacal notation, e.g. 24Ta29.
+func WriteHouseComparisonText(w io.Writer, c HouseComparison) error {
+	if l := c.Local; l != nil {
+		fmt.Fprintf(w, "Local time: %s %s (%s)\n", l.Time.Format("2006-01-02 15:04:05"), l.Offset, l.describe())
+	}
+	fmt.Fprintf(w, "Julian Day: %.6f\n", c.JulianDay)
+	if sid := c.Sidereal; sid != nil {
+		fmt.Fprintf(w, "Zodiac: sidereal, %s ayanamsa %.4f°\n", sid.Ayanamsa, sid.Degrees)
+	}
+	fmt.Fprintf(w, "\n=== Houses by System for (%.4f°, %.4f°) ===\n", c.Lat, c.Lon)
+
+	width := 10
+	for _, p := range c.Planets {
+		width = max(width, utf8.RuneCountInString(p.Name)+7)
+	}
+	for _, key := range []string{names.Ascendant, names.MC} {
+		width = max(width, utf8.RuneCountInString(names.Point(key)))
+	}
+	// A system cast in place of another is headed by the other's name,
+	// marked and explained below the table.
+	header := func(e HouseSystemEntry) string {
+		if e.FallbackFrom != "" {
+			return e.FallbackFrom + "*"
+		}
+		return e.Name
+	}
+	cols := make([]int, len(c.Systems))
+	row := func(label string, cells func(e HouseSystemEntry) string) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%-*s", width, label)
+		for i, e := range c.Systems {
+			fmt.Fprintf(&b, "  %-*s", cols[i], cells(e))
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+	for i, e := range c.Systems {
+		cols[i] = max(6, utf8.RuneCountInString(header(e)))
+	}
+	row("", header)
+	row(names.Point(names.Ascendant), func(e HouseSystemEntry) string { return zodiacal(e.Ascendant.Longitude) })
+	row(names.Point(names.MC), func(e HouseSystemEntry) string { return zodiacal(e.MC.Longitude) })
+	for h := 1; h <= 12; h++ {
+		row(fmt.Sprintf("House %d", h), func(e HouseSystemEntry) string {
+			if h > len(e.Cusps) {
+				return ""
+			}
+			return zodiacal(e.Cusps[h-1].Longitude)
+		})
+	}
+
+	fmt.Fprintln(w, "\nHouse of each planet:")
+	for _, p := range c.Planets {
+		row(fmt.Sprintf("%-*s %s", width-7, p.Name, zodiacal(p.Longitude)), func(e HouseSystemEntry) string {
+			return fmt.Sprint(e.Houses[p.Name])
+		})
+	}
+	sep := "\n"
+	for _, e := range c.Systems {
+		if e.FallbackFrom != "" {
+			fmt.Fprintf(w, "%s* %s houses cannot be cast within the polar circle: %s in their place\n", sep, e.FallbackFrom, e.Name)
+			sep = ""
+		}
+	}
+	if len(c.Skipped) > 0 {
+		fmt.Fprintf(w, "%s(%s houses cannot be cast within the polar circle: left out; --polar-fallback porphyry or whole-sign casts another system in their place)\n", sep, strings.Join(c.Skipped, ", "))
+	}
+	return nil
+}
+
+type houseComparisonJSON struct {
+	Metadata     *Metadata                   `json:"metadata"`
+	Sidereal     *SiderealInfo               `json:"sidereal,omitempty"`
+	Local        *LocalInfo                  `json:"local_time,omitempty"`
+	JulianDay    float64                     `json:"julian_day"`
+	Lat          float64                     `json:"lat"`
+	Lon          float64                     `json:"lon"`
+	Planets      []PlanetEntry               `json:"planets"`
+	HouseSystems map[string]HouseSystemEntry `json:"house_systems"`
+	Skipped      []string                    `json:"skipped,omitempty"`
+}
+
+// WriteHouseComparisonJSON writes the comparison to w as JSON, laid out as
+// opt says, with the systems in an object keyed by their --house-system
+// names, and those that could not be cast listed by those names under
+// skipped.
+func WriteHouseComparisonJSON(w io.Writer, c HouseComparison, opt JSONOptions) error {
+	out := houseComparisonJSON{
+		Metadata: c.Metadata, Sidereal: c.Sidereal, Local: c.Local,
+		JulianDay: c.JulianDay, Lat: c.Lat, Lon: c.Lon, Planets: c.Planets,
+		HouseSystems: map[string]HouseSystemEntry{},
+	}
+	for _, e := range c.Systems {
+		out.HouseSystems[e.Key] = e
+	}
+	for _, name := range c.Skipped {
+		out.Skipped = append(out.Skipped, systemKey(name))
+	}
+	return writeJSON(w, out, opt)
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/names"
+)
+
+// IngressYear is the sign ingresses of a year, for "astro ingresses".
+type IngressYear struct {
+	Year      int             `json:"year"`
+	TimeZone  string          `json:"timezone"`
+	Ingresses []CalendarEvent `json:"ingresses"` // by body, then in time
+}
+
+// BuildIngressYear describes the ingresses among events, found for year,
+// with their times in loc.
+func BuildIngressYear(year int, loc *time.Location, events []mundane.Event) IngressYear {
+	y := IngressYear{Year: year, TimeZone: loc.String(), Ingresses: []CalendarEvent{}}
+	for _, e := range events {
+		if e.Kind != mundane.Ingress {
+			continue
+		}
+		ce := mundaneEvent(e)
+		ce.Time = ephemeris.TimeOf(e.JD).In(loc)
+		y.Ingresses = append(y.Ingresses, ce)
+	}
+	return y
+}
+
+// CardinalIngress describes e for an ingress chart, or returns nil unless
+// e is the Sun's ingress into a cardinal sign.
+func CardinalIngress(e mundane.Event) *IngressInfo {
+	if e.Kind != mundane.Ingress || e.Body != ephemeris.Sun || e.Sign%3 != 0 {
+		return nil
+	}
+	return &IngressInfo{
+		Sign:   names.Default.Sign(e.Sign),
+		Season: seasons[e.Sign/3],
+		Time:   ephemeris.TimeOf(e.JD),
+	}
+}
+
+// WriteIngressYearText writes the ingresses to w, under each body's name
+// a line for each.
+func WriteIngressYearText(w io.Writer, y IngressYear) error {
+	fmt.Fprintf(w, "=== Sign ingresses of %d (%s) ===\n", y.Year, y.TimeZone)
+	if len(y.Ingresses) == 0 {
+		fmt.Fprintln(w, "\nnone")
+	}
+	body := ""
+	for _, e := range y.Ingresses {
+		if e.Body != body {
+			body = e.Body
+			fmt.Fprintf(w, "\n%s\n", body)
+		}
+		fmt.Fprintf(w, "  %s  %s\n", e.Time.Format("Mon 02 Jan 2006 15:04"), e.Summary)
+	}
+	return nil
+}
+
+// WriteIngressYearICS writes the ingresses to w as an iCalendar file, as
+// WriteCalendarICS does a month's events.
+func WriteIngressYearICS(w io.Writer, y IngressYear, stamp time.Time) error {
+	return writeICS(w, y.Ingresses, stamp)
+}
+
+// PrintIngressYearJSON writes the ingresses as JSON to stdout, laid out as
+// opt says.
+func PrintIngressYearJSON(y IngressYear, opt JSONOptions) error {
+	return writeJSON(os.Stdout, y, opt)
+}
@@ -3,38 +3,126 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 )
 
 type housesJSON struct {
-	System    string      `json:"system"`
-	Ascendant AngleEntry  `json:"ascendant"`
-	MC        AngleEntry  `json:"mc"`
-	Cusps     []CuspEntry `json:"cusps"`
+	System string `json:"system"`
+	// FallbackFrom is the system asked for, when System was cast in its
+	// place.
+	FallbackFrom string      `json:"fallback_from,omitempty"`
+	Ascendant    AngleEntry  `json:"ascendant"`
+	MC           AngleEntry  `json:"mc"`
+	Cusps        []CuspEntry `json:"cusps"`
 }
 
 type resultJSON struct {
-	JulianDay float64       `json:"julian_day"`
-	Planets   []PlanetEntry `json:"planets"`
-	Houses    housesJSON    `json:"houses"`
+	Metadata       *Metadata         `json:"metadata"`
+	Return         *ReturnInfo       `json:"return,omitempty"`
+	Ingress        *IngressInfo      `json:"ingress,omitempty"`
+	Composite      *CompositeInfo    `json:"composite,omitempty"`
+	Sidereal       *SiderealInfo     `json:"sidereal,omitempty"`
+	Varga          *VargaInfo        `json:"varga,omitempty"`
+	Observer       string            `json:"observer,omitempty"`
+	Local          *LocalInfo        `json:"local_time,omitempty"`
+	SolarTime      *SolarTimeInfo    `json:"solar_time,omitempty"`
+	JulianDay      float64           `json:"julian_day"`
+	Planets        []PlanetEntry     `json:"planets"`
+	Heliocentric   []PlanetEntry     `json:"heliocentric,omitempty"`
+	Points         []PointEntry      `json:"points,omitempty"`
+	NodeDivergence *NodeDivergence   `json:"node_divergence,omitempty"`
+	Aspects        []AspectEntry     `json:"aspects,omitempty"`
+	Receptions     []ReceptionEntry  `json:"receptions,omitempty"`
+	Patterns       []PatternEntry    `json:"patterns,omitempty"`
+	Sect           string            `json:"sect,omitempty"`
+	SectPlanets    *SectInfo         `json:"sect_planets,omitempty"`
+	Houses         *housesJSON       `json:"houses,omitempty"`
+	Balance        *BalanceInfo      `json:"balance,omitempty"`
+	Emphasis       *EmphasisInfo     `json:"emphasis,omitempty"`
+	Rulers         *RulersInfo       `json:"rulers,omitempty"`
+	Horary         *HoraryInfo       `json:"horary,omitempty"`
+	Visibility     []VisibilityEntry `json:"visibility,omitempty"`
 }
 
-// PrintJSON writes planetary positions and house cusps as indented JSON to stdout.
-func PrintJSON(r Result) error {
-	out := resultJSON{
-		JulianDay: r.JulianDay,
-		Planets:   r.Planets,
-		Houses: housesJSON{
-			System:    r.HouseName,
-			Ascendant: r.Ascendant,
-			MC:        r.MC,
-			Cusps:     r.Cusps,
-		},
+// JSONOptions control how the JSON printers lay out their output.
+type JSONOptions struct {
+	// Compact writes the document on one line, without indentation, for
+	// piping to other tools.
+	Compact bool
+}
+
+// PrintJSON writes planetary positions and house cusps as JSON to stdout,
+// laid out as opt says.
+func PrintJSON(r Result, opt JSONOptions) error { return WriteJSON(os.Stdout, r, opt) }
+
+// WriteJSON writes the JSON PrintJSON prints to w.
+func WriteJSON(w io.Writer, r Result, opt JSONOptions) error { return writeJSON(w, wire(r), opt) }
+
+// WriteJSONCharts writes charts to w as one JSON array, each chart as
+// WriteJSON writes it.
+func WriteJSONCharts(w io.Writer, charts []Result, opt JSONOptions) error {
+	out := make([]resultJSON, len(charts))
+	for i, r := range charts {
+		out[i] = wire(r)
 	}
+	return writeJSON(w, out, opt)
+}
 
-	data, err := json.MarshalIndent(out, "", "  ")
+// writeJSON writes v to w as one JSON document and a newline, indented
+// unless opt.Compact is set.
+func writeJSON(w io.Writer, v any, opt JSONOptions) error {
+	var data []byte
+	var err error
+	if opt.Compact {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("error marshalling JSON: %w", err)
 	}
-	fmt.Println(string(data))
-	return nil
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// wire maps a chart to the structure PrintJSON and PrintYAML encode.
+func wire(r Result) resultJSON {
+	out := resultJSON{
+		Metadata:       metadata(&r),
+		Return:         r.Return,
+		Ingress:        r.Ingress,
+		Composite:      r.Composite,
+		Sidereal:       r.Sidereal,
+		Varga:          r.Varga,
+		Observer:       r.Observer,
+		Local:          r.Local,
+		SolarTime:      r.SolarTime,
+		JulianDay:      r.JulianDay,
+		Planets:        r.Planets,
+		Heliocentric:   r.Heliocentric,
+		Points:         r.Points,
+		NodeDivergence: r.NodeDivergence,
+		Rulers:         r.Rulers,
+		Horary:         r.Horary,
+		Visibility:     r.Visibility,
+		Aspects:        r.Aspects,
+		Receptions:     r.Receptions,
+		Patterns:       r.Patterns,
+		Balance:        r.Balance,
+		Emphasis:       r.Emphasis,
+	}
+	if r.Sect != nil {
+		out.Sect, out.SectPlanets = r.Sect.Sect, r.Sect
+	}
+	if r.Cusps != nil {
+		out.Houses = &housesJSON{
+			System:       r.HouseName,
+			FallbackFrom: r.HouseFallback,
+			Ascendant:    r.Ascendant,
+			MC:           r.MC,
+			Cusps:        r.Cusps,
+		}
+	}
+	return out
 }
@@ -0,0 +1,320 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// PrintMarkdown writes the chart as a Markdown report to stdout: tables of
+// the planets, houses, aspects and dignities, then whatever else the chart
+// carries, for pasting into a notes app or converting to PDF.
+func PrintMarkdown(r Result) error { return WriteMarkdown(os.Stdout, r) }
+
+// WriteMarkdown writes the report PrintMarkdown prints to w.
+func WriteMarkdown(w io.Writer, r Result) error {
+	var b strings.Builder
+	title := "Chart"
+	switch {
+	case r.Return != nil:
+		title = strings.ToUpper(r.Return.Kind[:1]) + r.Return.Kind[1:] + " return"
+	case r.Ingress != nil:
+		title = r.Ingress.Sign + " ingress"
+	case r.Composite != nil:
+		title = r.Composite.title()
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	fmt.Fprintf(&b, "- **Time:** %s (JD %.6f)\n", ephemeris.TimeOf(r.JulianDay).Format("2006-01-02 15:04:05 MST"), r.JulianDay)
+	if l := r.Local; l != nil {
+		fmt.Fprintf(&b, "- **Local time:** %s %s (%s)\n", l.Time.Format("2006-01-02 15:04:05"), l.Offset, l.describe())
+	}
+	if s := r.SolarTime; s != nil {
+		fmt.Fprintf(&b, "- **Solar time:** %s\n", s.describe())
+	}
+	if r.Cusps != nil {
+		fmt.Fprintf(&b, "- **Location:** %.4f°, %.4f°\n", r.Lat, r.Lon)
+	}
+	if ret := r.Return; ret != nil {
+		where := ""
+		if ret.Relocated {
+			where = " (relocated)"
+		}
+		fmt.Fprintf(&b, "- **Return:** %s to %s%s\n", ret.Planet, position(ret.Natal.Sign, ret.Natal.SignDegree), where)
+	}
+	if in := r.Ingress; in != nil {
+		fmt.Fprintf(&b, "- **Ingress:** the Sun enters %s, the %s\n", in.Sign, in.Season)
+	}
+	if c := r.Composite; c != nil {
+		of := "Composite of"
+		if c.Method == "davison" {
+			of = "Davison chart of"
+		}
+		fmt.Fprintf(&b, "- **%s:** %s (%.4f°, %.4f°) and %s (%.4f°, %.4f°)\n",
+			of, c.A.Time.Format("2006-01-02 15:04 MST"), c.A.Lat, c.A.Lon,
+			c.B.Time.Format("2006-01-02 15:04 MST"), c.B.Lat, c.B.Lon)
+	}
+	if sid := r.Sidereal; sid != nil {
+		fmt.Fprintf(&b, "- **Zodiac:** sidereal, %s ayanamsa %.4f°\n", sid.Ayanamsa, sid.Degrees)
+	}
+	if v := r.Varga; v != nil {
+		fmt.Fprintf(&b, "- **Divisional chart:** %s (%s)\n", v.Code, v.Name)
+	}
+	if r.Observer != "" {
+		fmt.Fprintf(&b, "- **Observer:** %s (experimental; houses omitted)\n", r.Observer)
+	}
+	if s := r.Sect; s != nil {
+		fmt.Fprintf(&b, "- **Sect:** %s\n", s.Sect)
+	}
+
+	var h ephemeris.HouseResult
+	head, align := []string{"Planet", "Sign", "Degree", "Longitude", "Speed"}, "llrrr"
+	if r.Cusps != nil {
+		h = houseResult(&r)
+		head, align = append(head, "House"), align+"r"
+	}
+	rows := make([][]string, 0, len(r.Planets))
+	for _, p := range r.Planets {
+		speed := fmt.Sprintf("%+.4f°/day", p.Speed)
+		if p.Speed < 0 {
+			speed += " ℞"
+		}
+		row := []string{p.Name, p.Sign, fmt.Sprintf("%.2f°", p.SignDegree), fmt.Sprintf("%.4f°", p.Longitude), speed}
+		if r.Cusps != nil {
+			row = append(row, fmt.Sprint(h.HouseOf(p.Longitude)))
+		}
+		rows = append(rows, row)
+	}
+	mdSection(&b, "Planets")
+	mdTable(&b, head, align, rows)
+
+	if len(r.Points) > 0 {
+		rows = nil
+		for _, p := range r.Points {
+			rows = append(rows, []string{p.Name, p.Sign, fmt.Sprintf("%.2f°", p.SignDegree), fmt.Sprintf("%.4f°", p.Longitude), fmt.Sprint(p.House)})
+		}
+		mdSection(&b, "Chart points")
+		mdTable(&b, []string{"Point", "Sign", "Degree", "Longitude", "House"}, "llrrr", rows)
+	}
+
+	if r.Cusps != nil {
+		mdSection(&b, fmt.Sprintf("Houses (%s)", r.HouseName))
+		if r.HouseFallback != "" {
+			fmt.Fprintf(&b, "%s houses cannot be cast within the polar circle: %s in their place.\n\n", mdEscape(r.HouseFallback), mdEscape(r.HouseName))
+		}
+		rows = [][]string{
+			{names.Point(names.Ascendant), position(r.Ascendant.Sign, r.Ascendant.SignDegree), fmt.Sprintf("%.4f°", r.Ascendant.Longitude)},
+			{names.Point(names.MC), position(r.MC.Sign, r.MC.SignDegree), fmt.Sprintf("%.4f°", r.MC.Longitude)},
+		}
+		for _, c := range r.Cusps {
+			rows = append(rows, []string{fmt.Sprint(c.House), position(c.Sign, c.SignDegree), fmt.Sprintf("%.4f°", c.Longitude)})
+		}
+		mdTable(&b, []string{"House", "Cusp", "Longitude"}, "llr", rows)
+	}
+
+	if len(r.Aspects) > 0 {
+		rows = nil
+		for _, a := range r.Aspects {
+			rows = append(rows, []string{a.A, a.Aspect, a.B, fmt.Sprintf("%.2f°", a.Orb)})
+		}
+		mdSection(&b, "Aspects")
+		mdTable(&b, []string{"Planet", "Aspect", "Planet", "Orb"}, "lllr", rows)
+	}
+
+	if rows = dignityRows(r); len(rows) > 0 {
+		mdSection(&b, "Dignities")
+		mdTable(&b, []string{"Planet", "Sign", "Dignities", "Debilities", "Term", "Face", "Score"}, "llllllr", rows)
+	}
+
+	if len(r.Receptions) > 0 {
+		mdSection(&b, "Mutual receptions")
+		for _, rec := range r.Receptions {
+			if rec.Kind == "mixed" {
+				fmt.Fprintf(&b, "- %s and %s: mixed (%s in %s's %s, %s in %s's %s)\n",
+					rec.A, rec.B, rec.A, rec.B, rec.AIn, rec.B, rec.A, rec.BIn)
+			} else {
+				fmt.Fprintf(&b, "- %s and %s: by %s\n", rec.A, rec.B, rec.Kind)
+			}
+		}
+	}
+
+	if len(r.Patterns) > 0 {
+		mdSection(&b, "Aspect patterns")
+		rows = nil
+		for _, pat := range r.Patterns {
+			var notes []string
+			for _, n := range []string{pat.Sign, pat.Element, pat.Modality} {
+				if n != "" {
+					notes = append(notes, n)
+				}
+			}
+			if pat.Apex != "" {
+				notes = append(notes, "apex "+pat.Apex)
+			}
+			rows = append(rows, []string{strings.ToUpper(pat.Kind[:1]) + pat.Kind[1:], strings.Join(pat.Members, ", "), strings.Join(notes, ", ")})
+		}
+		mdTable(&b, []string{"Pattern", "Planets", "Notes"}, "lll", rows)
+	}
+
+	if len(r.Heliocentric) > 0 {
+		mdSection(&b, "Heliocentric positions")
+		rows = nil
+		for _, p := range r.Heliocentric {
+			rows = append(rows, []string{p.Name, p.Sign, fmt.Sprintf("%.2f°", p.SignDegree), fmt.Sprintf("%.4f°", p.Longitude), fmt.Sprintf("%+.4f°/day", p.Speed)})
+		}
+		mdTable(&b, []string{"Planet", "Sign", "Degree", "Longitude", "Speed"}, "llrrr", rows)
+	}
+
+	if bal := r.Balance; bal != nil {
+		mdSection(&b, "Chart summary")
+		e, m := bal.Elements, bal.Modalities
+		mdTable(&b, []string{"Fire", "Earth", "Air", "Water", "Cardinal", "Fixed", "Mutable"}, "rrrrrrr", [][]string{{
+			fmt.Sprint(e.Fire), fmt.Sprint(e.Earth), fmt.Sprint(e.Air), fmt.Sprint(e.Water),
+			fmt.Sprint(m.Cardinal), fmt.Sprint(m.Fixed), fmt.Sprint(m.Mutable),
+		}})
+		if bal.Weighted {
+			fmt.Fprintf(&b, "\nThe Sun, Moon and Ascendant count %d.\n", LuminaryWeight)
+		}
+	}
+	if e := r.Emphasis; e != nil {
+		if r.Balance == nil {
+			mdSection(&b, "Chart summary")
+		} else {
+			b.WriteString("\n")
+		}
+		rows = [][]string{
+			{"East", fmt.Sprint(len(e.East)), strings.Join(e.East, ", ")},
+			{"West", fmt.Sprint(len(e.West)), strings.Join(e.West, ", ")},
+			{"North (below the horizon)", fmt.Sprint(len(e.North)), strings.Join(e.North, ", ")},
+			{"South (above the horizon)", fmt.Sprint(len(e.South)), strings.Join(e.South, ", ")},
+		}
+		for i, q := range e.Quadrants {
+			rows = append(rows, []string{ordinal(i+1) + " quadrant", fmt.Sprint(len(q)), strings.Join(q, ", ")})
+		}
+		mdTable(&b, []string{"Region", "Count", "Planets"}, "lrl", rows)
+	}
+
+	if ru := r.Rulers; ru != nil {
+		mdSection(&b, fmt.Sprintf("House rulers (%s)", ru.Scheme))
+		fmt.Fprintf(&b, "Chart ruler: **%s**\n\n", ru.ChartRuler)
+		rows = nil
+		for _, hr := range ru.Houses {
+			rows = append(rows, []string{fmt.Sprint(hr.House), hr.Sign, hr.Ruler, position(hr.RulerSign, hr.RulerSignDegree), fmt.Sprint(hr.RulerHouse)})
+		}
+		mdTable(&b, []string{"House", "Sign", "Ruler", "Ruler in", "Ruler's house"}, "lllll", rows)
+	}
+
+	if len(r.Visibility) > 0 {
+		mdSection(&b, "Visibility")
+		var rows [][]string
+		for _, v := range r.Visibility {
+			rows = append(rows, []string{v.Name, fmt.Sprintf("%+.1f°", v.Altitude), fmt.Sprintf("%.1f°", v.Elongation), v.describe()})
+		}
+		mdTable(&b, []string{"Planet", "Altitude", "Elongation", "Seen"}, "lrrl", rows)
+	}
+
+	if hor := r.Horary; hor != nil {
+		mdSection(&b, "Horary considerations")
+		for _, c := range hor.Checks {
+			mark := "x"
+			if !c.OK {
+				mark = " "
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, mdEscape(c.Detail))
+		}
+		if hor.Radical {
+			b.WriteString("\n**Radical:** yes\n")
+		} else {
+			b.WriteString("\n**Radical:** no; judge with caution\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dignityRows tabulates the essential dignities and debilities of the
+// chart's classical planets, with triplicity by the chart's sect.
+func dignityRows(r Result) [][]string {
+	day := r.Sect == nil || r.Sect.Sect == "day"
+	var rows [][]string
+	for _, p := range r.Planets {
+		if !slices.Contains(dignity.Classical, p.Body) {
+			continue
+		}
+		sign := dignity.Sign(p.Longitude)
+		var dig, deb []string
+		if dignity.Ruler(sign) == p.Body {
+			dig = append(dig, "domicile")
+		}
+		if dignity.Exaltation(sign) == p.Body {
+			dig = append(dig, "exaltation")
+		}
+		lords := dignity.TriplicityLords(sign)
+		if (day && lords[0] == p.Body) || (!day && lords[1] == p.Body) {
+			dig = append(dig, "triplicity")
+		}
+		if dignity.TermLord(p.Longitude) == p.Body {
+			dig = append(dig, "term")
+		}
+		if dignity.FaceLord(p.Longitude) == p.Body {
+			dig = append(dig, "face")
+		}
+		opposite := (sign + 6) % 12
+		if dignity.Ruler(opposite) == p.Body {
+			deb = append(deb, "detriment")
+		}
+		if dignity.Exaltation(opposite) == p.Body {
+			deb = append(deb, "fall")
+		}
+		if len(dig) == 0 {
+			dig = []string{"peregrine"}
+		}
+		rows = append(rows, []string{
+			p.Name, p.Sign, strings.Join(dig, ", "), strings.Join(deb, ", "),
+			names.Body(dignity.TermLord(p.Longitude)), names.Body(dignity.FaceLord(p.Longitude)),
+			fmt.Sprint(dignity.Score(p.Body, p.Longitude, day)),
+		})
+	}
+	return rows
+}
+
+// position formats a place in a sign, e.g. "Taurus 24.50°".
+func position(sign string, deg float64) string { return fmt.Sprintf("%s %.2f°", sign, deg) }
+
+func mdSection(b *strings.Builder, title string) { fmt.Fprintf(b, "\n## %s\n\n", title) }
+
+// mdTable writes a pipe table. align has one letter per column, 'l' or
+// 'r' for left or right alignment.
+func mdTable(b *strings.Builder, head []string, align string, rows [][]string) {
+	b.WriteString("|")
+	for _, h := range head {
+		b.WriteString(" " + mdEscape(h) + " |")
+	}
+	b.WriteString("\n|")
+	for i := range head {
+		if align[i] == 'r' {
+			b.WriteString("---:|")
+		} else {
+			b.WriteString("---|")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + mdEscape(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// mdEscape escapes the characters that would break a table cell or start
+// unintended formatting.
+var mdEscape = strings.NewReplacer(`|`, `\|`, `*`, `\*`, `_`, `\_`, "`", "\\`").Replace
@@ -0,0 +1,54 @@
+package output
+
+// SchemaVersion is the version of the chart's JSON and YAML structure,
+// reported as metadata.schema_version. Within a major version the structure
+// only grows: the minor version goes up when fields are added, and fields
+// are never removed, renamed or given another meaning. A change that would
+// break a consumer bumps the major version instead.
+const SchemaVersion = "1.12"
+
+// Metadata describes how a chart was computed. It is the "metadata"
+// object of the JSON output, which wire completes from the chart itself.
+type Metadata struct {
+	SchemaVersion   string `json:"schema_version"`
+	Ephemeris       string `json:"ephemeris,omitempty"`        // backend: swiss, moshier or jpl
+	SwissEphVersion string `json:"swisseph_version,omitempty"` // of the bundled library
+	Zodiac          string `json:"zodiac"`                     // tropical or sidereal
+	Ayanamsa        string `json:"ayanamsa,omitempty"`         // for the sidereal zodiac
+	// HouseSystem is in English whatever the language of the output, and
+	// empty for a chart without houses.
+	HouseSystem string     `json:"house_system,omitempty"`
+	Input       *InputInfo `json:"input,omitempty"`
+	// Sources say which ephemeris computed each planet, when the
+	// provider can tell (see AddSources).
+	Sources []SourceEntry `json:"sources,omitempty"`
+}
+
+// InputInfo echoes the command a chart was computed from.
+type InputInfo struct {
+	Command string   `json:"command"` // chart, return, composite or batch
+	Args    []string `json:"args"`    // as given, after the command name
+	// Name is the name of a chart of astro batch, whose Args are the
+	// chart's datetime, coordinates and --tz.
+	Name string `json:"name,omitempty"`
+}
+
+// metadata returns r's Metadata with the fields that follow from the chart
+// filled in.
+func metadata(r *Result) *Metadata {
+	var m Metadata
+	if r.Metadata != nil {
+		m = *r.Metadata
+	}
+	m.SchemaVersion = SchemaVersion
+	m.Zodiac, m.Ayanamsa = "tropical", ""
+	if r.Sidereal != nil {
+		m.Zodiac, m.Ayanamsa = "sidereal", r.Sidereal.Ayanamsa
+	}
+	m.HouseSystem = ""
+	if r.Cusps != nil {
+		m.HouseSystem = r.houseSystem
+	}
+	m.Sources = r.Sources
+	return &m
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+)
+
+// MoonYear is the lunations of a year, for "astro moon".
+type MoonYear struct {
+	Year      int             `json:"year"`
+	TimeZone  string          `json:"timezone"`
+	Lunations []CalendarEvent `json:"lunations"`
+	// Supermoons is set when the supermoons were looked for, and adds
+	// their column to the CSV.
+	Supermoons bool `json:"-"`
+}
+
+// BuildMoonYear lists the lunations among events, with their times in
+// loc: the events of mundane.Lunations for year, in that zone.
+func BuildMoonYear(year int, loc *time.Location, events []mundane.Event) MoonYear {
+	y := MoonYear{Year: year, TimeZone: loc.String(), Lunations: []CalendarEvent{}}
+	for _, e := range events {
+		if e.Kind != mundane.Lunation {
+			continue
+		}
+		ce := mundaneEvent(e)
+		ce.Time = ephemeris.TimeOf(e.JD).In(loc)
+		y.Lunations = append(y.Lunations, ce)
+	}
+	return y
+}
+
+// WriteMoonYearText writes the lunations to w as a table, a row each.
+func WriteMoonYearText(w io.Writer, y MoonYear) error {
+	fmt.Fprintf(w, "=== Lunations of %d (%s) ===\n", y.Year, y.TimeZone)
+	for _, e := range y.Lunations {
+		pos := fmt.Sprintf("%6s %s", degrees(e.Position.SignDegree), e.Position.Sign)
+		note := e.Eclipse
+		if e.Supermoon {
+			note = strings.TrimPrefix(note+", supermoon", ", ")
+		}
+		line := fmt.Sprintf("%s  %-13s  %-19s  %s", e.Time.Format("Mon 02 Jan 15:04"), e.Phase, pos, note)
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	return nil
+}
+
+// moonCSVHeader names the columns WriteMoonYearCSV writes.
+var moonCSVHeader = []string{"time", "utc", "phase", "longitude", "sign", "sign_degree", "eclipse"}
+
+// WriteMoonYearCSV writes the lunations to w as CSV under moonCSVHeader:
+// the local time with its offset, and UTC, both RFC 3339. eclipse is empty
+// for a lunation without one. If y.Supermoons is set, a last column,
+// supermoon, is true or false.
+func WriteMoonYearCSV(w io.Writer, y MoonYear) error {
+	cw := csv.NewWriter(w)
+	header := moonCSVHeader
+	if y.Supermoons {
+		header = append(slices.Clip(header), "supermoon")
+	}
+	cw.Write(header)
+	num := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	for _, e := range y.Lunations {
+		row := []string{
+			e.Time.Format(time.RFC3339), e.Time.UTC().Format(time.RFC3339), e.Phase,
+			num(e.Position.Longitude), e.Position.Sign, num(e.Position.SignDegree), e.Eclipse,
+		}
+		if y.Supermoons {
+			row = append(row, strconv.FormatBool(e.Supermoon))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMoonYearICS writes the lunations to w as an iCalendar file, as
+// WriteCalendarICS does a month's events.
+func WriteMoonYearICS(w io.Writer, y MoonYear, stamp time.Time) error {
+	return writeICS(w, y.Lunations, stamp)
+}
+
+// PrintMoonYearJSON writes the lunations as JSON to stdout, laid out as opt
+// says.
+func PrintMoonYearJSON(y MoonYear, opt JSONOptions) error {
+	return writeJSON(os.Stdout, y, opt)
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NDJSON writes newline-delimited JSON: one compact object per line, each
+// written out as soon as it is encoded, so that long runs can be streamed
+// into jq or a log pipeline without being held in memory.
+type NDJSON struct {
+	enc *json.Encoder
+}
+
+// NewNDJSON returns an NDJSON stream writing to w.
+func NewNDJSON(w io.Writer) *NDJSON {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &NDJSON{enc: enc}
+}
+
+// Write writes v as one line.
+func (n *NDJSON) Write(v any) error {
+	if err := n.enc.Encode(v); err != nil {
+		return fmt.Errorf("error writing NDJSON: %w", err)
+	}
+	return nil
+}
+
+// WriteChart writes a chart as one line, with the structure of PrintJSON.
+func (n *NDJSON) WriteChart(r Result) error { return n.Write(wire(r)) }
+
+// PrintNDJSON writes each item as a line of JSON to stdout.
+func PrintNDJSON[T any](items []T) error {
+	n := NewNDJSON(os.Stdout)
+	for _, it := range items {
+		if err := n.Write(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
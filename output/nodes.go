@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/nodes"
+)
+
+// NodePeriodEntry holds presentation-ready data for one period of large
+// divergence between the true and mean nodes.
+type NodePeriodEntry struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	PeakTime time.Time `json:"peak_time"`
+	Peak     float64   `json:"peak"` // true minus mean at the peak, signed
+}
+
+// NodeReport lists the periods of large node divergence within a range.
+type NodeReport struct {
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Threshold float64           `json:"threshold"`
+	Periods   []NodePeriodEntry `json:"periods"`
+}
+
+// BuildNodeReport converts divergence periods into a report.
+func BuildNodeReport(from, to, threshold float64, periods []nodes.Period) NodeReport {
+	rep := NodeReport{
+		From:      ephemeris.TimeOf(from),
+		To:        ephemeris.TimeOf(to),
+		Threshold: threshold,
+		Periods:   []NodePeriodEntry{},
+	}
+	for _, p := range periods {
+		rep.Periods = append(rep.Periods, NodePeriodEntry{
+			Start:    ephemeris.TimeOf(p.Start),
+			End:      ephemeris.TimeOf(p.End),
+			PeakTime: ephemeris.TimeOf(p.PeakJD),
+			Peak:     p.Peak,
+		})
+	}
+	return rep
+}
+
+// PrintNodeReportText writes the report as a table to stdout.
+func PrintNodeReportText(rep NodeReport) error {
+	fmt.Printf("=== True/Mean Node Divergence over %.2f° (%s to %s) ===\n",
+		rep.Threshold, rep.From.Format("2006-01-02"), rep.To.Format("2006-01-02"))
+	if len(rep.Periods) == 0 {
+		fmt.Println("No periods of large divergence.")
+		return nil
+	}
+	for _, p := range rep.Periods {
+		fmt.Printf("%s  to  %s   peak %+.4f° on %s\n",
+			p.Start.Format("2006-01-02 15:04"), p.End.Format("2006-01-02 15:04"),
+			p.Peak, p.PeakTime.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// PrintNodeReportJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintNodeReportJSON(rep NodeReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/almuten"
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/sect"
+)
+
+// PointEntry holds presentation-ready data for a chart point that is not a
+// body, such as the Vertex or the Part of Fortune.
+type PointEntry struct {
+	Key        string  `json:"-"` // the names point key, e.g. names.Vertex
+	Name       string  `json:"name"`
+	Longitude  float64 `json:"longitude"`
+	Sign       string  `json:"sign"`
+	SignDegree float64 `json:"sign_degree"`
+	House      int     `json:"house"`
+}
+
+// uranianBodies are the bodies of the Uranian points.
+var uranianBodies = map[string]int{
+	names.Cupido: ephemeris.Cupido, names.Hades: ephemeris.Hades, names.Zeus: ephemeris.Zeus,
+	names.Kronos: ephemeris.Kronos, names.Apollon: ephemeris.Apollon, names.Admetos: ephemeris.Admetos,
+	names.Vulkanus: ephemeris.Vulkanus, names.Poseidon: ephemeris.Poseidon, names.Transpluto: ephemeris.Transpluto,
+}
+
+// AddPoints adds the chart points keys name to r, in that order, placed in
+// r's houses and counted in its aspect patterns. A key is one of the
+// names point keys Vertex, EastPoint, CoAscendant, PolarAscendant, Lilith
+// (the mean apogee), Fortune (by the sect of the chart), NorthNode, which
+// adds the mean North Node and the South Node opposite, or one of the
+// Uranian planets Cupido to Poseidon and Transpluto. The bodies the points
+// need are computed by p, since they need not be among r's planets. r must
+// come from Build.
+func AddPoints(r *Result, p ephemeris.Provider, keys []string) error {
+	body := func(id int) (float64, error) {
+		pos, err := p.CalcPlanet(r.JulianDay, id)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating %s: %w", names.Body(id), err)
+		}
+		return pos.Longitude, nil
+	}
+	houses := houseResult(r)
+	add := func(key string, lon float64) {
+		sign, deg := names.SignOf(lon)
+		r.Points = append(r.Points, PointEntry{
+			Key: key, Name: names.Point(key), Longitude: lon,
+			Sign: sign, SignDegree: deg, House: houses.HouseOf(lon),
+		})
+	}
+	for _, key := range keys {
+		switch key {
+		case names.Vertex:
+			add(key, r.angles.Vertex)
+		case names.EastPoint:
+			add(key, r.angles.EastPoint)
+		case names.CoAscendant:
+			add(key, r.angles.CoAscendant)
+		case names.PolarAscendant:
+			add(key, r.angles.PolarAscendant)
+		case names.Lilith:
+			lon, err := body(ephemeris.MeanApogee)
+			if err != nil {
+				return err
+			}
+			add(key, lon)
+		case names.NorthNode:
+			lon, err := body(ephemeris.MeanNode)
+			if err != nil {
+				return err
+			}
+			add(names.NorthNode, lon)
+			add(names.SouthNode, angle.Norm360(lon+180))
+		case names.Fortune:
+			sun, err := body(ephemeris.Sun)
+			if err != nil {
+				return err
+			}
+			moon, err := body(ephemeris.Moon)
+			if err != nil {
+				return err
+			}
+			day := sect.Of(sun, r.Ascendant.Longitude) == sect.Day
+			add(key, almuten.Fortune(r.Ascendant.Longitude, sun, moon, day))
+		default:
+			id, ok := uranianBodies[key]
+			if !ok {
+				return fmt.Errorf("unknown chart point %q", key)
+			}
+			lon, err := body(id)
+			if err != nil {
+				return err
+			}
+			add(key, lon)
+		}
+	}
+	r.Aspects = findAspects(r)
+	r.Patterns = findPatterns(r)
+	return nil
+}
@@ -0,0 +1,216 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/rectify"
+)
+
+// RectifySpan is a run of candidate birth times whose charts agree on the
+// signs of the angles, the planets' houses and the house rulers.
+type RectifySpan struct {
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Ascendant AngleEntry     `json:"ascendant"` // at Start
+	MC        AngleEntry     `json:"mc"`        // at Start
+	Houses    map[string]int `json:"planet_houses"`
+	Rulers    []string       `json:"house_rulers"` // of houses 1-12, traditional
+	// Changes describe how the span differs from the one before.
+	Changes []string `json:"changes"`
+	// planets are the keys of Houses in the order to list them.
+	planets []string
+}
+
+// RectifyEvent is a dated life event the candidates were scored against.
+type RectifyEvent struct {
+	Time time.Time `json:"time"`
+	Name string    `json:"name"`
+}
+
+// RectifyHit is a contact timing an event in a candidate's chart.
+type RectifyHit struct {
+	Event     string  `json:"event"`
+	Technique string  `json:"technique"` // "transit", "directed angle" or "directed planet"
+	Contact   string  `json:"contact"`   // e.g. "transiting Saturn conjunction Ascendant"
+	Orb       float64 `json:"orb"`
+}
+
+// RectifyCandidate is a candidate birth time ranked against the events.
+type RectifyCandidate struct {
+	Rank      int          `json:"rank"`
+	Time      time.Time    `json:"time"`
+	Score     float64      `json:"score"`
+	Ascendant AngleEntry   `json:"ascendant"`
+	MC        AngleEntry   `json:"mc"`
+	Hits      []RectifyHit `json:"hits"`
+}
+
+// RectifyReport is the result of "astro rectify": the spans of a birth
+// date's candidate times and, with events, the best candidates.
+type RectifyReport struct {
+	Lat         float64            `json:"lat"`
+	Lon         float64            `json:"lon"`
+	TimeZone    string             `json:"timezone"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	StepMinutes float64            `json:"step_minutes"`
+	HouseSystem string             `json:"house_system"`
+	Spans       []RectifySpan      `json:"spans"`
+	Events      []RectifyEvent     `json:"events,omitempty"`
+	Orb         float64            `json:"orb,omitempty"`
+	Candidates  []RectifyCandidate `json:"candidates,omitempty"`
+}
+
+// BuildRectify describes spans and the best limit of candidates (all if
+// limit is 0), ranked against events with orb, with times in loc. bodies
+// are the planets of the charts, in the order to list them.
+func BuildRectify(spans []rectify.Span, candidates []rectify.Candidate, events []rectify.Event, bodies []int, orb float64, loc *time.Location, from, to, step, lat, lon float64, hsysName string, limit int) RectifyReport {
+	rep := RectifyReport{
+		Lat:         lat,
+		Lon:         lon,
+		TimeZone:    loc.String(),
+		From:        ephemeris.TimeOf(from).In(loc),
+		To:          ephemeris.TimeOf(to).In(loc),
+		StepMinutes: step * 24 * 60,
+		HouseSystem: hsysName,
+		Spans:       []RectifySpan{},
+	}
+	for _, s := range spans {
+		rs := RectifySpan{
+			Start:     ephemeris.TimeOf(s.First.JD).In(loc),
+			End:       ephemeris.TimeOf(s.Last.JD).In(loc),
+			Ascendant: angleEntry(s.First.Houses.Ascendant),
+			MC:        angleEntry(s.First.Houses.MC),
+			Houses:    map[string]int{},
+			Changes:   []string{},
+		}
+		for _, body := range bodies {
+			rs.Houses[names.Body(body)] = s.First.House(body)
+			rs.planets = append(rs.planets, names.Body(body))
+		}
+		for h := 1; h <= 12; h++ {
+			rs.Rulers = append(rs.Rulers, names.Body(s.First.Ruler(h)))
+		}
+		for _, c := range s.Changes {
+			rs.Changes = append(rs.Changes, describeChange(c))
+		}
+		rep.Spans = append(rep.Spans, rs)
+	}
+	if len(events) == 0 {
+		return rep
+	}
+
+	rep.Orb = orb
+	for _, e := range events {
+		rep.Events = append(rep.Events, RectifyEvent{Time: ephemeris.TimeOf(e.JD).In(loc), Name: e.Name})
+	}
+	for i, c := range candidates {
+		if limit > 0 && i == limit {
+			break
+		}
+		rc := RectifyCandidate{
+			Rank:      i + 1,
+			Time:      ephemeris.TimeOf(c.JD).In(loc),
+			Score:     c.Score,
+			Ascendant: angleEntry(c.Houses.Ascendant),
+			MC:        angleEntry(c.Houses.MC),
+			Hits:      []RectifyHit{},
+		}
+		for _, h := range c.Hits {
+			rc.Hits = append(rc.Hits, RectifyHit{
+				Event:     events[h.Event].Name,
+				Technique: h.Technique.String(),
+				Contact:   describeHit(h),
+				Orb:       h.Orb,
+			})
+		}
+		rep.Candidates = append(rep.Candidates, rc)
+	}
+	return rep
+}
+
+// describeChange describes a change between spans, e.g. "Mars moves to
+// house 12".
+func describeChange(c rectify.Change) string {
+	switch c.Kind {
+	case rectify.AscendantSign:
+		return fmt.Sprintf("%s enters %s", names.Point(names.Ascendant), names.Default.Sign(c.Sign))
+	case rectify.MCSign:
+		return fmt.Sprintf("%s enters %s", names.Point(names.MC), names.Default.Sign(c.Sign))
+	case rectify.PlanetHouse:
+		return fmt.Sprintf("%s moves to house %d", names.Body(c.Body), c.House)
+	}
+	return fmt.Sprintf("ruler of house %d becomes %s", c.House, names.Body(c.Body))
+}
+
+// describeHit describes a hit's contact, e.g. "directed MC square natal
+// Venus".
+func describeHit(h rectify.Hit) string {
+	angle := names.Point(names.Ascendant)
+	if h.Angle == rectify.MC {
+		angle = names.Point(names.MC)
+	}
+	body, aspect := names.Body(h.Body), names.Aspect(h.Aspect.Name)
+	switch h.Technique {
+	case rectify.DirectedAngle:
+		return fmt.Sprintf("directed %s %s natal %s", angle, aspect, body)
+	case rectify.DirectedBody:
+		return fmt.Sprintf("directed %s %s natal %s", body, aspect, angle)
+	}
+	return fmt.Sprintf("transiting %s %s %s", body, aspect, angle)
+}
+
+// WriteRectifyText writes the report to w: a line for each span with its
+// Ascendant, MC and changes, then the best candidates and their hits.
+func WriteRectifyText(w io.Writer, rep RectifyReport) error {
+	layout := "15:04"
+	if rep.From.YearDay() != rep.To.YearDay() || rep.From.Year() != rep.To.Year() {
+		layout = "02 Jan 15:04"
+	}
+	fmt.Fprintf(w, "=== Rectification for %s at (%.4f°, %.4f°) ===\n", rep.From.Format("2006-01-02"), rep.Lat, rep.Lon)
+	fmt.Fprintf(w, "%s to %s (%s), every %g min; %s houses\n\n", rep.From.Format(layout), rep.To.Format(layout), rep.TimeZone, rep.StepMinutes, rep.HouseSystem)
+
+	asc, mc := names.Point(names.Ascendant), names.Point(names.MC)
+	for i, s := range rep.Spans {
+		line := fmt.Sprintf("%s-%s  %s %-6s  %s %-6s  %s", s.Start.Format(layout), s.End.Format(layout),
+			asc, zodiacal(s.Ascendant.Longitude), mc, zodiacal(s.MC.Longitude), strings.Join(s.Changes, "; "))
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if i > 0 {
+			continue
+		}
+		indent := strings.Repeat(" ", 2*len(s.Start.Format(layout))+3)
+		var houses []string
+		for _, name := range s.planets {
+			houses = append(houses, fmt.Sprintf("%s %d", name, s.Houses[name]))
+		}
+		if len(houses) > 0 {
+			fmt.Fprintf(w, "%shouses: %s\n", indent, strings.Join(houses, ", "))
+		}
+		fmt.Fprintf(w, "%srulers: %s\n", indent, strings.Join(s.Rulers, ", "))
+	}
+
+	if len(rep.Events) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nBest candidates against %d events (orb %g°):\n", len(rep.Events), rep.Orb)
+	for _, c := range rep.Candidates {
+		fmt.Fprintf(w, "%3d  %s  score %.2f  %s %s  %s %s\n", c.Rank, c.Time.Format(layout), c.Score,
+			asc, zodiacal(c.Ascendant.Longitude), mc, zodiacal(c.MC.Longitude))
+		for _, h := range c.Hits {
+			fmt.Fprintf(w, "       %s: %s (%s)\n", h.Event, h.Contact, degrees(h.Orb))
+		}
+	}
+	return nil
+}
+
+// PrintRectifyJSON writes the report as JSON to stdout, laid out as opt
+// says.
+func PrintRectifyJSON(rep RectifyReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -2,17 +2,29 @@ package output
 
 import (
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
-	"github.com/dcccxiii/astro/swisseph"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/nodes"
+	"github.com/dcccxiii/astro/patterns"
+	"github.com/dcccxiii/astro/sect"
 )
 
 // PlanetEntry holds presentation-ready data for a single planet.
 type PlanetEntry struct {
+	Body       int     `json:"-"` // ephemeris body ID
 	Name       string  `json:"name"`
 	Longitude  float64 `json:"longitude"`
 	Sign       string  `json:"sign"`
 	SignDegree float64 `json:"sign_degree"`
 	Speed      float64 `json:"speed"`
+	// Nakshatra is set for sidereal charts (see AddNakshatras).
+	Nakshatra *NakshatraEntry `json:"nakshatra,omitempty"`
 }
 
 // AngleEntry holds presentation-ready data for a chart angle (Ascendant, MC).
@@ -30,52 +42,258 @@ type CuspEntry struct {
 	SignDegree float64 `json:"sign_degree"`
 }
 
+// LocalInfo describes the local time a chart's datetime was given in.
+type LocalInfo struct {
+	Time   time.Time `json:"datetime"`   // on the local clock
+	Offset string    `json:"utc_offset"` // ±hh:mm, or ±hh:mm:ss for local mean time
+	Zone   string    `json:"timezone"`   // IANA name, or LMT under --tz LMT
+	// Source is where the zone came from: "datetime" (in brackets), "tz",
+	// "place", or "coordinates" when it was looked up from them.
+	Source string `json:"source"`
+	// Near is the atlas place whose zone the coordinates took; empty for
+	// a nautical zone or another source.
+	Near string `json:"near,omitempty"`
+	// MeanTime is set when the time was read as the local mean time of
+	// the longitude, under --tz LMT or as the zone had no standard time
+	// yet.
+	MeanTime bool `json:"mean_time,omitempty"`
+}
+
+// describe returns the zone and where it came from, in words.
+func (l *LocalInfo) describe() string {
+	if l.MeanTime && l.Zone == "LMT" {
+		return "local mean time of the longitude, from --tz"
+	}
+	s := l.Zone
+	switch l.Source {
+	case "tz", "place":
+		s += " from --" + l.Source
+	case "coordinates":
+		s += " from the coordinates"
+		if l.Near != "" {
+			s += ", near " + l.Near
+		}
+	}
+	if l.MeanTime {
+		s += "; local mean time of the longitude, before standard time"
+	}
+	return s
+}
+
+// SolarTimeInfo gives a chart's moment as local mean time, the clock of
+// the mean Sun at the chart's longitude, and as local apparent time, the
+// sundial's, by which charts before standard time were often timed. The
+// times are wall-clock readings, without an offset.
+type SolarTimeInfo struct {
+	Mean     string `json:"mean"`     // YYYY-MM-DDThh:mm:ss
+	Apparent string `json:"apparent"` // YYYY-MM-DDThh:mm:ss
+	// Equation is the equation of time, apparent minus mean time, in
+	// minutes.
+	Equation float64 `json:"equation_of_time"`
+}
+
+// describe returns the apparent and mean times and the equation of time,
+// in words.
+func (s *SolarTimeInfo) describe() string {
+	sign, sec := '+', int(math.Round(s.Equation*60))
+	if sec < 0 {
+		sign, sec = '-', -sec
+	}
+	return fmt.Sprintf("apparent %s, mean %s (equation of time %c%dm%02ds)",
+		strings.Replace(s.Apparent, "T", " ", 1), strings.Replace(s.Mean, "T", " ", 1), sign, sec/60, sec%60)
+}
+
+// SolarTime describes the moment whose local mean time is the Julian Day
+// mean and whose local apparent time is apparent.
+func SolarTime(mean, apparent float64) *SolarTimeInfo {
+	const layout = "2006-01-02T15:04:05"
+	return &SolarTimeInfo{
+		Mean:     ephemeris.TimeOf(mean).Format(layout),
+		Apparent: ephemeris.TimeOf(apparent).Format(layout),
+		Equation: (apparent - mean) * 1440,
+	}
+}
+
+// ReturnInfo describes the planetary return a chart was cast for.
+type ReturnInfo struct {
+	Kind      string     `json:"kind"` // "solar", "lunar", or the planet, e.g. "saturn"
+	Planet    string     `json:"planet"`
+	Natal     AngleEntry `json:"natal_position"`
+	Time      time.Time  `json:"time"` // the first exact pass; the chart is cast for this moment
+	Relocated bool       `json:"relocated"`
+	// Passes lists every exact pass when retrograde motion makes the
+	// planet cross its natal degree more than once.
+	Passes []time.Time `json:"passes,omitempty"`
+}
+
+// IngressInfo describes the cardinal ingress a chart was cast for: the
+// moment the Sun enters Aries, Cancer, Libra or Capricorn, which mundane
+// astrology reads for the season, or the year, that follows.
+type IngressInfo struct {
+	Sign   string    `json:"sign"`
+	Season string    `json:"season"` // e.g. "March equinox"
+	Time   time.Time `json:"time"`   // the chart is cast for this moment
+}
+
+// CompositeInfo describes the two charts a composite was built from.
+type CompositeInfo struct {
+	A ChartRef `json:"a"`
+	B ChartRef `json:"b"`
+	// ReferenceLatitude is the latitude the composite houses were cast for.
+	ReferenceLatitude float64 `json:"reference_latitude"`
+	// Method is "midpoint" for a midpoint composite, or "davison" for a
+	// chart cast for the midpoint in time and place of A and B.
+	Method string `json:"method"`
+}
+
+// title returns the name of the kind of chart c is.
+func (c *CompositeInfo) title() string {
+	if c.Method == "davison" {
+		return "Davison chart"
+	}
+	return "Composite chart"
+}
+
+// NodeDivergence reports how far the true lunar node is from the mean node.
+type NodeDivergence struct {
+	Degrees   float64 `json:"degrees"` // true minus mean, signed
+	Threshold float64 `json:"threshold"`
+	Large     bool    `json:"large"` // |Degrees| exceeds Threshold
+}
+
+// SectInfo names a chart's sect and the planets that belong to it.
+type SectInfo struct {
+	Sect            string `json:"-"` // "day" or "night"; the chart's "sect" key in JSON
+	Light           string `json:"light"`
+	Benefic         string `json:"benefic"`
+	Malefic         string `json:"malefic"`
+	ContraryMalefic string `json:"contrary_malefic"` // the malefic of the other sect
+}
+
+// ReceptionEntry is a mutual reception: A is in a sign where B has the
+// dignity AIn, and B in one where A has BIn.
+type ReceptionEntry struct {
+	A    string `json:"a"`
+	B    string `json:"b"`
+	Kind string `json:"kind"` // "domicile", "exaltation" or "mixed"
+	AIn  string `json:"a_in"`
+	BIn  string `json:"b_in"`
+}
+
+// PatternEntry is an aspect pattern among the chart's planets.
+type PatternEntry struct {
+	Kind     string   `json:"kind"` // e.g. "grand trine", "T-square", "stellium"
+	Members  []string `json:"members"`
+	Element  string   `json:"element,omitempty"`  // shared by every member's sign
+	Modality string   `json:"modality,omitempty"` // shared by every member's sign
+	Apex     string   `json:"apex,omitempty"`     // the focus of a T-square or yod
+	Sign     string   `json:"sign,omitempty"`     // the sign of a stellium
+}
+
+// AspectEntry is a major aspect between two of the chart's planets or
+// points.
+type AspectEntry struct {
+	A      string  `json:"a"`
+	Aspect string  `json:"aspect"`
+	B      string  `json:"b"`
+	Orb    float64 `json:"orb"` // from exact, in degrees
+}
+
 // Result holds all computed, presentation-ready chart data. Both PrintText
-// and PrintJSON render from this struct; neither calls swisseph directly.
+// and PrintJSON render from this struct; neither touches the ephemeris.
 type Result struct {
+	Return    *ReturnInfo    // set for return charts
+	Ingress   *IngressInfo   // set for ingress charts
+	Composite *CompositeInfo // set for composite charts
+	Sidereal  *SiderealInfo  // set when positions are sidereal
+	Varga     *VargaInfo     // set for divisional charts
+	Horary    *HoraryInfo    // set for horary charts
+	Rulers    *RulersInfo    // set when the house rulers are asked for
+	Observer  string         // body the positions are seen from, if not Earth; such results have no houses
+	Local     *LocalInfo     // set when the datetime was given in local time
+	SolarTime *SolarTimeInfo // set when the solar time is asked for
+	// Metadata describes how the chart was computed, for the JSON
+	// output; the CLI sets it.
+	Metadata *Metadata
+	// Sources are the ephemerides that computed the planets (see
+	// AddSources), reported in the metadata.
+	Sources []SourceEntry
+	// Warnings are the ephemeris's messages about the positions it
+	// computed all the same, each after the name of the body, for
+	// astro chart --verbose. They are not part of the JSON.
+	Warnings  []string
 	JulianDay float64
 	HouseName string
-	Lat       float64
-	Lon       float64
-	Planets   []PlanetEntry
-	Ascendant AngleEntry
-	MC        AngleEntry
-	Cusps     []CuspEntry // one entry per house, 1-12
+	// HouseFallback names the house system asked for when it could not
+	// be cast at the latitude, and HouseName's was cast in its place (see
+	// SetHouseFallback).
+	HouseFallback string
+	// houseSystem is the English name of the house system, which
+	// HouseName translates, and fallbackSystem that of HouseFallback.
+	houseSystem    string
+	fallbackSystem string
+	Lat            float64
+	Lon            float64
+	Planets        []PlanetEntry
+	// Heliocentric holds Sun-centred positions reported alongside the
+	// geocentric planets in Tychonic mode (see AddHeliocentric).
+	Heliocentric []PlanetEntry
+	// NodeDivergence is set when both the true and mean nodes are shown.
+	NodeDivergence *NodeDivergence
+	Ascendant      AngleEntry
+	MC             AngleEntry
+	Cusps          []CuspEntry // one entry per house, 1-12
+	// angles are the houses as cast, for the points AddPoints takes from
+	// them.
+	angles ephemeris.HouseResult
+	// Points lists the chart points beyond the angles, such as the Vertex
+	// (see AddPoints).
+	Points []PointEntry
+	// Sect is set by Build when the chart includes the Sun.
+	Sect *SectInfo
+	// Aspects lists the major aspects among the planets and points, but
+	// for the lunar nodes' to each other.
+	Aspects []AspectEntry
+	// Receptions lists the mutual receptions among the classical planets.
+	Receptions []ReceptionEntry
+	// Patterns lists the aspect patterns among the planets and points,
+	// nodes excluded.
+	Patterns []PatternEntry
+	// Balance is the element and modality balance; Build sets it
+	// unweighted (see AddBalance).
+	Balance *BalanceInfo
+	// Emphasis places the planets in hemispheres and quadrants.
+	Emphasis *EmphasisInfo
+	// Visibility says which planets can be seen (see AddVisibility).
+	Visibility []VisibilityEntry
 }
 
 // Build computes a full chart result for the given Julian Day, planets, and
-// geographic location. All swisseph calls are concentrated here.
-func Build(jd float64, planets []int, lat, lon float64, hsys byte, hsysName string) (Result, error) {
-	r := Result{JulianDay: jd, HouseName: hsysName, Lat: lat, Lon: lon}
-
-	for _, p := range planets {
-		name := swisseph.PlanetName(p)
-		pos, err := swisseph.CalcPlanet(jd, p)
-		if err != nil {
-			return Result{}, fmt.Errorf("error calculating %s: %w", name, err)
-		}
-		sign, deg := swisseph.ZodiacSign(pos.Longitude)
-		r.Planets = append(r.Planets, PlanetEntry{
-			Name:       name,
-			Longitude:  pos.Longitude,
-			Sign:       sign,
-			SignDegree: deg,
-			Speed:      pos.SpeedLon,
-		})
+// geographic location. All ephemeris calls are concentrated here and go
+// through p, so tests can substitute an ephemeris.MockProvider.
+func Build(p ephemeris.Provider, jd float64, planets []int, lat, lon float64, hsys byte, hsysName string) (Result, error) {
+	r, err := BuildSky(p, jd, planets)
+	if err != nil {
+		return Result{}, err
 	}
+	r.HouseName, r.houseSystem, r.Lat, r.Lon = names.HouseSystem(hsysName), hsysName, lat, lon
+	r.Aspects = findAspects(&r)
+	r.Receptions = receptions(&r)
+	r.Patterns = findPatterns(&r)
 
-	houses, err := swisseph.CalcHouses(jd, lat, lon, hsys)
+	houses, err := p.CalcHouses(jd, lat, lon, hsys)
 	if err != nil {
 		return Result{}, fmt.Errorf("error calculating houses: %w", err)
 	}
 
-	ascSign, ascDeg := swisseph.ZodiacSign(houses.Ascendant)
-	mcSign, mcDeg := swisseph.ZodiacSign(houses.MC)
+	ascSign, ascDeg := names.SignOf(houses.Ascendant)
+	mcSign, mcDeg := names.SignOf(houses.MC)
 	r.Ascendant = AngleEntry{Longitude: houses.Ascendant, Sign: ascSign, SignDegree: ascDeg}
 	r.MC = AngleEntry{Longitude: houses.MC, Sign: mcSign, SignDegree: mcDeg}
+	r.angles = houses
 
 	for i := 1; i <= 12; i++ {
-		sign, deg := swisseph.ZodiacSign(houses.Cusps[i])
+		sign, deg := names.SignOf(houses.Cusps[i])
 		r.Cusps = append(r.Cusps, CuspEntry{
 			House:      i,
 			Longitude:  houses.Cusps[i],
@@ -84,5 +302,197 @@ func Build(jd float64, planets []int, lat, lon float64, hsys byte, hsysName stri
 		})
 	}
 
+	if sun, ok := findPlanet(&r, ephemeris.Sun); ok {
+		r.Sect = sectInfo(sect.Of(sun.Longitude, houses.Ascendant))
+	}
+	AddBalance(&r, false)
+	r.Emphasis = emphasis(&r)
 	return r, nil
 }
+
+// SetHouseFallback records that r's houses were cast in place of the
+// system named hsysName, in English as for Build, which cannot be cast at
+// r's latitude.
+func SetHouseFallback(r *Result, hsysName string) {
+	r.HouseFallback, r.fallbackSystem = names.HouseSystem(hsysName), hsysName
+}
+
+// sectInfo describes sect s.
+func sectInfo(s sect.Sect) *SectInfo {
+	return &SectInfo{
+		Sect:            s.String(),
+		Light:           names.Body(s.Light()),
+		Benefic:         names.Body(s.Benefic()),
+		Malefic:         names.Body(s.Malefic()),
+		ContraryMalefic: names.Body(s.Contrary().Malefic()),
+	}
+}
+
+// receptions finds the mutual receptions among r's classical planets.
+func receptions(r *Result) []ReceptionEntry {
+	lons := make(map[int]float64)
+	for _, body := range dignity.Classical {
+		if p, ok := findPlanet(r, body); ok {
+			lons[body] = p.Longitude
+		}
+	}
+	var out []ReceptionEntry
+	for _, rec := range dignity.Receptions(lons) {
+		kind := rec.AIn.String()
+		if rec.Mixed() {
+			kind = "mixed"
+		}
+		out = append(out, ReceptionEntry{
+			A: names.Body(rec.A), B: names.Body(rec.B), Kind: kind,
+			AIn: rec.AIn.String(), BIn: rec.BIn.String(),
+		})
+	}
+	return out
+}
+
+// findAspects finds the major aspects among r's planets and points. The
+// chart points aspect the planets and one another, as the planets do, but
+// for the nodes' aspects to each other.
+func findAspects(r *Result) []AspectEntry {
+	type aspecting struct {
+		name string
+		lon  float64
+		node bool
+	}
+	var all []aspecting
+	for _, p := range r.Planets {
+		all = append(all, aspecting{p.Name, p.Longitude, isNode(p.Name)})
+	}
+	for _, p := range r.Points {
+		all = append(all, aspecting{p.Name, p.Longitude, p.Key == names.NorthNode || p.Key == names.SouthNode})
+	}
+	var out []AspectEntry
+	for i, p := range all {
+		for _, q := range all[i+1:] {
+			if p.node && q.node {
+				continue
+			}
+			if a, orb, ok := aspects.Between(p.lon, q.lon, aspects.Major); ok {
+				out = append(out, AspectEntry{A: p.name, Aspect: names.Aspect(a.Name), B: q.name, Orb: orb})
+			}
+		}
+	}
+	return out
+}
+
+// findPatterns finds the aspect patterns among r's planets and points,
+// leaving out the lunar nodes.
+func findPatterns(r *Result) []PatternEntry {
+	var points []patterns.Point
+	for _, p := range r.Planets {
+		if !isNode(p.Name) {
+			points = append(points, patterns.Point{Name: p.Name, Longitude: p.Longitude})
+		}
+	}
+	for _, p := range r.Points {
+		if p.Key != names.NorthNode && p.Key != names.SouthNode {
+			points = append(points, patterns.Point{Name: p.Name, Longitude: p.Longitude})
+		}
+	}
+	var out []PatternEntry
+	for _, pat := range patterns.Find(points) {
+		e := PatternEntry{
+			Kind: pat.Kind.String(), Members: pat.Members,
+			Element: pat.Element, Modality: pat.Modality, Apex: pat.Apex,
+		}
+		if pat.Kind == patterns.Stellium {
+			e.Sign = names.Default.Sign(pat.Sign)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// isNode reports whether name is that of a lunar node.
+func isNode(name string) bool {
+	return name == names.Body(ephemeris.MeanNode) || name == names.Body(ephemeris.TrueNode)
+}
+
+// findPlanet returns r's entry for body, if r includes it.
+func findPlanet(r *Result, body int) (PlanetEntry, bool) {
+	name := names.Body(body)
+	for _, p := range r.Planets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return PlanetEntry{}, false
+}
+
+// houseResult rebuilds the houses of r for the ephemeris helpers.
+func houseResult(r *Result) ephemeris.HouseResult {
+	h := ephemeris.HouseResult{Ascendant: r.Ascendant.Longitude, MC: r.MC.Longitude}
+	for _, c := range r.Cusps {
+		h.Cusps[c.House] = c.Longitude
+	}
+	return h
+}
+
+// BuildSky computes planet positions only, with no houses. It serves
+// observers away from Earth (see ephemeris/swiss.CentricProvider), for whom
+// houses have no meaning; the caller sets Result.Observer.
+func BuildSky(p ephemeris.Provider, jd float64, planets []int) (Result, error) {
+	r := Result{JulianDay: jd}
+	for _, body := range planets {
+		name := names.Body(body)
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return Result{}, fmt.Errorf("error calculating %s: %w", name, err)
+		}
+		r.warn(name, pos)
+		r.Planets = append(r.Planets, planetEntry(body, pos))
+	}
+	return r, nil
+}
+
+// AddHeliocentric appends the heliocentric positions of bodies, computed by
+// helio, to r. Pairing the geocentric chart with the heliocentric Earth
+// gives the hybrid "Tychonic" view some researchers use to compare frames.
+func AddHeliocentric(r *Result, helio ephemeris.Provider, bodies []int) error {
+	for _, body := range bodies {
+		name := names.Body(body)
+		pos, err := helio.CalcPlanet(r.JulianDay, body)
+		if err != nil {
+			return fmt.Errorf("error calculating heliocentric %s: %w", name, err)
+		}
+		r.warn("heliocentric "+name, pos)
+		r.Heliocentric = append(r.Heliocentric, planetEntry(body, pos))
+	}
+	return nil
+}
+
+// AddNodeDivergence records the separation between the true and mean nodes
+// at the chart time, flagging it as large beyond threshold degrees.
+func AddNodeDivergence(r *Result, p ephemeris.Provider, threshold float64) error {
+	d, err := nodes.Divergence(p, r.JulianDay)
+	if err != nil {
+		return err
+	}
+	r.NodeDivergence = &NodeDivergence{Degrees: d, Threshold: threshold, Large: math.Abs(d) > threshold}
+	return nil
+}
+
+// warn adds the warning of the position of the body named name, if it has
+// one, to r's.
+func (r *Result) warn(name string, pos ephemeris.PlanetPos) {
+	if pos.Warning != "" {
+		r.Warnings = append(r.Warnings, name+": "+pos.Warning)
+	}
+}
+
+func planetEntry(body int, pos ephemeris.PlanetPos) PlanetEntry {
+	sign, deg := names.SignOf(pos.Longitude)
+	return PlanetEntry{
+		Body:       body,
+		Name:       names.Body(body),
+		Longitude:  pos.Longitude,
+		Sign:       sign,
+		SignDegree: deg,
+		Speed:      pos.SpeedLon,
+	}
+}
@@ -0,0 +1,1352 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/rectify"
+)
+
+func TestBuild_MockProvider(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = float64(i-1) * 30
+	}
+	houses.Ascendant = 0
+	houses.MC = 270
+
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 280.5, SpeedLon: 1.02},
+			ephemeris.Moon: {Longitude: 45.25, SpeedLon: 13.1},
+		},
+		Houses: houses,
+	}
+
+	r, err := Build(p, 0, []int{ephemeris.Sun, ephemeris.Moon}, 51.5, -0.1, 'P', "Placidus")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if len(r.Planets) != 2 {
+		t.Fatalf("got %d planets, want 2", len(r.Planets))
+	}
+	sun := r.Planets[0]
+	if sun.Name != "Sun" || sun.Sign != "Capricorn" || sun.SignDegree != 10.5 || sun.Speed != 1.02 {
+		t.Errorf("Sun entry = %+v", sun)
+	}
+	if moon := r.Planets[1]; moon.Sign != "Taurus" || moon.SignDegree != 15.25 {
+		t.Errorf("Moon entry = %+v", moon)
+	}
+	if r.MC.Sign != "Capricorn" {
+		t.Errorf("MC sign = %q, want Capricorn", r.MC.Sign)
+	}
+	if len(r.Cusps) != 12 || r.Cusps[6].Sign != "Libra" {
+		t.Errorf("cusps = %+v", r.Cusps)
+	}
+
+	if _, err := Build(p, 0, []int{ephemeris.Mars}, 0, 0, 'P', "Placidus"); err == nil {
+		t.Error("expected error for body without mock data")
+	}
+}
+
+func TestBuildSky_Warnings(t *testing.T) {
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:  {Longitude: 10, Warning: "using Moshier eph."},
+		ephemeris.Moon: {Longitude: 100},
+	}}
+	r, err := BuildSky(p, 2451545, []int{ephemeris.Sun, ephemeris.Moon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Sun: using Moshier eph."}; !reflect.DeepEqual(r.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", r.Warnings, want)
+	}
+	var b strings.Builder
+	if err := WriteJSON(&b, r, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "Moshier") {
+		t.Errorf("JSON has the warning:\n%s", b.String())
+	}
+}
+
+func TestAddHeliocentric(t *testing.T) {
+	helio := &ephemeris.MockProvider{
+		Epoch:   2451545.0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Earth: {Longitude: 180.25, SpeedLon: 0.99}},
+	}
+	r := Result{JulianDay: 2451545.0}
+	if err := AddHeliocentric(&r, helio, []int{ephemeris.Earth}); err != nil {
+		t.Fatalf("AddHeliocentric: %v", err)
+	}
+	if len(r.Heliocentric) != 1 {
+		t.Fatalf("got %d heliocentric entries, want 1", len(r.Heliocentric))
+	}
+	if e := r.Heliocentric[0]; e.Name != "Earth" || e.Sign != "Libra" || e.SignDegree != 0.25 {
+		t.Errorf("Earth entry = %+v", e)
+	}
+}
+
+func TestBuildSky_NoHouses(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Earth: {Longitude: 148.0}},
+	}
+	r, err := BuildSky(p, 0, []int{ephemeris.Earth})
+	if err != nil {
+		t.Fatalf("BuildSky: %v", err)
+	}
+	if r.Cusps != nil || r.HouseName != "" {
+		t.Errorf("BuildSky produced houses: %+v", r)
+	}
+	if len(r.Planets) != 1 || r.Planets[0].Sign != "Leo" {
+		t.Errorf("planets = %+v", r.Planets)
+	}
+}
+
+func TestBuildSky_NameOverrides(t *testing.T) {
+	defer func(old *names.Registry) { names.Default = old }(names.Default)
+	names.Default = names.New()
+	names.Default.SetBody(ephemeris.TrueNode, "Rahu")
+	names.Default.SetSign(0, "Mesha")
+
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.TrueNode: {Longitude: 15}},
+	}
+	r, err := BuildSky(p, 0, []int{ephemeris.TrueNode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Planets[0]; got.Name != "Rahu" || got.Sign != "Mesha" {
+		t.Errorf("planet = %s in %s, want Rahu in Mesha", got.Name, got.Sign)
+	}
+}
+
+func TestAddNakshatras(t *testing.T) {
+	r := Result{Planets: []PlanetEntry{{Name: "Moon", Longitude: 125}}}
+	AddNakshatras(&r)
+	n := r.Planets[0].Nakshatra
+	if n == nil || n.Name != "Magha" || n.Pada != 2 || n.Lord != "Ketu" {
+		t.Errorf("nakshatra of 125° = %+v, want Magha pada 2 (Ketu)", n)
+	}
+}
+
+func TestApplyVarga(t *testing.T) {
+	r := Result{
+		Planets:   []PlanetEntry{{Name: "Sun", Longitude: 256.5, Nakshatra: &NakshatraEntry{}}},
+		Ascendant: AngleEntry{Longitude: 76},
+		MC:        AngleEntry{Longitude: 333},
+		Cusps:     make([]CuspEntry, 12),
+		HouseName: "Placidus",
+	}
+	ApplyVarga(&r, 9)
+
+	// Sagittarius 16.5° is in its fifth navamsha, counted from Aries: Leo.
+	if p := r.Planets[0]; p.Sign != "Leo" || p.Nakshatra != nil {
+		t.Errorf("D9 Sun = %+v, want in Leo with no nakshatra", p)
+	}
+	// Gemini 16° (Asc) is in its fifth navamsha, counted from Libra: Aquarius.
+	if r.Ascendant.Sign != "Aquarius" || r.HouseName != "Whole Sign" {
+		t.Errorf("D9 Asc in %s with %s houses, want Aquarius, Whole Sign", r.Ascendant.Sign, r.HouseName)
+	}
+	if c := r.Cusps[0]; c.Longitude != 300 || c.House != 1 {
+		t.Errorf("first cusp = %+v, want house 1 at 300°", c)
+	}
+	if r.Varga == nil || r.Varga.Code != "D9" || r.Varga.Name != "Navamsha" {
+		t.Errorf("Varga = %+v", r.Varga)
+	}
+}
+
+func TestAddHorary(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = 1 + float64(i-1)*30
+	}
+	houses.Ascendant, houses.MC = 1, 271
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:     {Longitude: 10, SpeedLon: 1},
+			ephemeris.Moon:    {Longitude: 50, SpeedLon: 13},
+			ephemeris.Mercury: {Longitude: 20, SpeedLon: 1.2},
+			ephemeris.Venus:   {Longitude: 30, SpeedLon: 1.1},
+			ephemeris.Mars:    {Longitude: 300, SpeedLon: 0.7},
+			ephemeris.Jupiter: {Longitude: 110, SpeedLon: 0.1},
+			ephemeris.Saturn:  {Longitude: 185, SpeedLon: 0.05},
+		},
+		Houses: houses,
+	}
+	r, err := Build(p, 0, []int{ephemeris.Sun, ephemeris.Moon}, 51.5, -0.1, 'P', "Placidus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddHorary(&r, p, ephemeris.Mars); err != nil {
+		t.Fatal(err)
+	}
+	h := r.Horary
+	if h == nil || h.Radical || h.AscendantRuler != "Mars" || h.HourAgreement != "same planet" {
+		t.Fatalf("Horary = %+v", h)
+	}
+	want := map[string]bool{
+		"ascendant":           false, // 1° Aries: early
+		"moon_void_of_course": true,
+		"saturn_in_seventh":   false,
+		"via_combusta":        true,
+		"hour_agreement":      true,
+	}
+	for _, c := range h.Checks {
+		if ok, found := want[c.Consideration]; !found || ok != c.OK {
+			t.Errorf("check %s ok = %v (%s)", c.Consideration, c.OK, c.Detail)
+		}
+	}
+	if len(h.Checks) != len(want) {
+		t.Errorf("got %d checks, want %d", len(h.Checks), len(want))
+	}
+}
+
+func TestBuild_Sect(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = float64(i-1) * 30
+	}
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 45}}, // 2nd house: below the horizon
+		Houses:  houses,
+	}
+	r, err := Build(p, 0, []int{ephemeris.Sun}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SectInfo{Sect: "night", Light: "Moon", Benefic: "Venus", Malefic: "Mars", ContraryMalefic: "Saturn"}
+	if r.Sect == nil || *r.Sect != want {
+		t.Errorf("Sect = %+v, want %+v", r.Sect, want)
+	}
+
+	// Without the Sun there is no sect.
+	p.Planets[ephemeris.Moon] = ephemeris.PlanetPos{Longitude: 10}
+	if r, _ := Build(p, 0, []int{ephemeris.Moon}, 0, 0, 'E', "Equal"); r.Sect != nil {
+		t.Errorf("Sect = %+v without the Sun, want nil", r.Sect)
+	}
+}
+
+func TestBuild_Receptions(t *testing.T) {
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Mercury: {Longitude: 340}, // Pisces: Venus exalted
+		ephemeris.Venus:   {Longitude: 160}, // Virgo: Mercury's domicile
+		ephemeris.Mars:    {Longitude: 10},
+	}}
+	r, err := Build(p, 0, []int{ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ReceptionEntry{A: "Mercury", B: "Venus", Kind: "mixed", AIn: "exaltation", BIn: "domicile"}
+	if len(r.Receptions) != 1 || r.Receptions[0] != want {
+		t.Errorf("Receptions = %+v, want %+v", r.Receptions, want)
+	}
+}
+
+func TestAddVisibility(t *testing.T) {
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:   {Longitude: 10},
+		ephemeris.Moon:  {Longitude: 190, Latitude: 5},
+		ephemeris.Venus: {Longitude: 55},
+		ephemeris.Mars:  {Longitude: 300},
+	}}
+	r, err := Build(p, 0, []int{ephemeris.Sun, ephemeris.Moon, ephemeris.Venus, ephemeris.Mars}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sky := map[int]Sighting{
+		ephemeris.Moon:  {Altitude: 30, Risen: true, Magnitude: -12, Limit: 3},
+		ephemeris.Venus: {Altitude: 10, Risen: true, Magnitude: -4, Limit: -5},
+		ephemeris.Mars:  {Altitude: -20},
+	}
+	err = AddVisibility(&r, p, func(body int) (Sighting, error) { return sky[body], nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Visibility) != 3 {
+		t.Fatalf("Visibility = %+v, want the Moon, Venus and Mars", r.Visibility)
+	}
+	for i, want := range []struct {
+		elongation float64
+		visible    bool
+		text       string
+	}{
+		{175, true, "magnitude -12.0, limit 3.0: visible"},
+		{45, false, "magnitude -4.0, limit -5.0: too faint for the sky"},
+		{70, false, "below the horizon"},
+	} {
+		v := r.Visibility[i]
+		if math.Abs(v.Elongation-want.elongation) > 1e-9 || v.Visible != want.visible || v.describe() != want.text {
+			t.Errorf("%s: elongation %v, visible %v, %q; want %v, %v, %q", v.Name, v.Elongation, v.Visible, v.describe(), want.elongation, want.visible, want.text)
+		}
+	}
+	if r.Visibility[2].Magnitude != nil {
+		t.Errorf("Mars below the horizon has a magnitude")
+	}
+}
+
+func TestAddRulers(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = 15 + float64(i-1)*30
+	}
+	houses.Ascendant = 15 // Aries
+	planets := map[int]ephemeris.PlanetPos{}
+	for _, body := range []int{ephemeris.Sun, ephemeris.Moon, ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars, ephemeris.Jupiter, ephemeris.Neptune, ephemeris.Pluto} {
+		planets[body] = ephemeris.PlanetPos{Longitude: 200}
+	}
+	planets[ephemeris.Saturn] = ephemeris.PlanetPos{Longitude: 100} // Cancer, 3rd house
+	planets[ephemeris.Uranus] = ephemeris.PlanetPos{Longitude: 50}  // Taurus, 2nd house
+	p := &ephemeris.MockProvider{Planets: planets, Houses: houses}
+	r, err := Build(p, 0, []int{ephemeris.Sun}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		modern bool
+		want   HouseRuler
+	}{
+		{false, HouseRuler{House: 11, Sign: "Aquarius", Ruler: "Saturn", RulerSign: "Cancer", RulerSignDegree: 10, RulerHouse: 3}},
+		{true, HouseRuler{House: 11, Sign: "Aquarius", Ruler: "Uranus", RulerSign: "Taurus", RulerSignDegree: 20, RulerHouse: 2}},
+	} {
+		if err := AddRulers(&r, p, tc.modern); err != nil {
+			t.Fatal(err)
+		}
+		if r.Rulers.ChartRuler != "Mars" || len(r.Rulers.Houses) != 12 {
+			t.Fatalf("modern=%v: Rulers = %+v", tc.modern, r.Rulers)
+		}
+		if got := r.Rulers.Houses[10]; got != tc.want {
+			t.Errorf("modern=%v: house 11 = %+v, want %+v", tc.modern, got, tc.want)
+		}
+	}
+}
+
+func TestAddPoints(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = 15 + float64(i-1)*30
+	}
+	houses.Ascendant, houses.Vertex, houses.EastPoint = 15, 220, 20
+	p := &ephemeris.MockProvider{Houses: houses, Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:        {Longitude: 100}, // below the horizon: a night chart
+		ephemeris.Moon:       {Longitude: 160},
+		ephemeris.MeanNode:   {Longitude: 50},
+		ephemeris.MeanApogee: {Longitude: 340},
+		ephemeris.Cupido:     {Longitude: 75},
+	}}
+	r, err := Build(p, 0, []int{ephemeris.Sun}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddPoints(&r, p, []string{names.Vertex, names.EastPoint, names.NorthNode, names.Lilith, names.Fortune, names.Cupido}); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pt := range r.Points {
+		got = append(got, fmt.Sprintf("%s %g %d", pt.Name, pt.Longitude, pt.House))
+	}
+	want := []string{"Vertex 220 7", "East Point 20 1", "North Node 50 2", "South Node 230 8", "Lilith 340 11", "Part of Fortune 315 11", "Cupido 75 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Points = %q, want %q", got, want)
+	}
+	// The Sun, the Vertex and Lilith make a grand trine in water.
+	if len(r.Patterns) != 1 || r.Patterns[0].Kind != "grand trine" || strings.Join(r.Patterns[0].Members, ",") != "Sun,Vertex,Lilith" {
+		t.Errorf("Patterns = %+v, want the grand trine of the Sun, Vertex and Lilith", r.Patterns)
+	}
+
+	if err := AddPoints(&r, p, []string{"Antivertex"}); err == nil {
+		t.Error("AddPoints(Antivertex): expected error")
+	}
+}
+
+func TestAddBalance(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = 100 + float64(i-1)*30
+	}
+	houses.Ascendant = 100 // Cancer: water, cardinal
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:      {Longitude: 5},   // Aries: fire, cardinal
+			ephemeris.Moon:     {Longitude: 40},  // Taurus: earth, fixed
+			ephemeris.Mars:     {Longitude: 250}, // Sagittarius: fire, mutable
+			ephemeris.MeanNode: {Longitude: 70},  // not counted
+		},
+		Houses: houses,
+	}
+	r, err := Build(p, 0, []int{ephemeris.Sun, ephemeris.Moon, ephemeris.Mars, ephemeris.MeanNode}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BalanceInfo{
+		Elements:   ElementCounts{Fire: 2, Earth: 1, Water: 1},
+		Modalities: ModalityCounts{Cardinal: 2, Fixed: 1, Mutable: 1},
+	}
+	if r.Balance == nil || *r.Balance != want {
+		t.Errorf("Balance = %+v, want %+v", r.Balance, want)
+	}
+
+	AddBalance(&r, true)
+	want = BalanceInfo{
+		Weighted:   true,
+		Elements:   ElementCounts{Fire: 3, Earth: 2, Water: 2},
+		Modalities: ModalityCounts{Cardinal: 4, Fixed: 2, Mutable: 1},
+	}
+	if *r.Balance != want {
+		t.Errorf("weighted Balance = %+v, want %+v", r.Balance, want)
+	}
+}
+
+func TestBuild_Emphasis(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = float64(i-1) * 30
+	}
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:      {Longitude: 15},  // 1st house
+			ephemeris.Moon:     {Longitude: 100}, // 4th
+			ephemeris.Mars:     {Longitude: 200}, // 7th
+			ephemeris.Jupiter:  {Longitude: 290}, // 10th
+			ephemeris.Saturn:   {Longitude: 350}, // 12th
+			ephemeris.TrueNode: {Longitude: 160}, // not counted
+		},
+		Houses: houses,
+	}
+	r, err := Build(p, 0, []int{ephemeris.Sun, ephemeris.Moon, ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn, ephemeris.TrueNode}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &EmphasisInfo{
+		East:      []string{"Sun", "Jupiter", "Saturn"},
+		West:      []string{"Moon", "Mars"},
+		North:     []string{"Sun", "Moon"},
+		South:     []string{"Mars", "Jupiter", "Saturn"},
+		Quadrants: [4][]string{{"Sun"}, {"Moon"}, {"Mars"}, {"Jupiter", "Saturn"}},
+	}
+	if !reflect.DeepEqual(r.Emphasis, want) {
+		t.Errorf("Emphasis = %+v, want %+v", r.Emphasis, want)
+	}
+}
+
+// The JSON structure is a contract versioned by SchemaVersion: these keys
+// must stay, and the metadata must not follow the output language.
+func TestWriteJSON_Metadata(t *testing.T) {
+	defer func(old *names.Registry) { names.Default = old }(names.Default)
+	names.Default = names.New()
+	if err := names.Default.Load("de"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 10}}}
+	r, err := Build(p, 2451545, []int{ephemeris.Sun}, 0, 0, 'W', "Whole Sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Metadata = &Metadata{Ephemeris: "moshier", Input: &InputInfo{Command: "chart", Args: []string{"--json"}}}
+	var b strings.Builder
+	if err := WriteJSON(&b, r, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"metadata", "julian_day", "planets", "houses", "sect", "balance", "emphasis"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("no %q key", key)
+		}
+	}
+	want := map[string]any{
+		"schema_version": SchemaVersion,
+		"ephemeris":      "moshier",
+		"zodiac":         "tropical",
+		"house_system":   "Whole Sign",
+		"input":          map[string]any{"command": "chart", "args": []any{"--json"}},
+	}
+	if !reflect.DeepEqual(got["metadata"], want) {
+		t.Errorf("metadata = %v, want %v", got["metadata"], want)
+	}
+	if sys := got["houses"].(map[string]any)["system"]; sys != "Ganzzeichen" {
+		t.Errorf("houses.system = %v, want the translated name", sys)
+	}
+}
+
+// sourceProvider is a MockProvider that reports the Moon as served by the
+// Moshier ephemeris in place of the Swiss Ephemeris.
+type sourceProvider struct{ ephemeris.MockProvider }
+
+func (p *sourceProvider) Source(jd float64, body int) (ephemeris.Source, bool, error) {
+	if body == ephemeris.Moon {
+		return ephemeris.Source{Ephemeris: "moshier", Fallback: true}, true, nil
+	}
+	return ephemeris.Source{Ephemeris: "swiss", File: "sepl_18.se1", Start: 2378496.5, End: 2597641.5}, true, nil
+}
+
+func TestAddSources(t *testing.T) {
+	p := &sourceProvider{ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun: {Longitude: 10}, ephemeris.Moon: {Longitude: 100},
+	}}}
+	r, err := Build(p, 2451545, []int{ephemeris.Sun, ephemeris.Moon}, 0, 0, 'W', "Whole Sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddSources(&r, p); err != nil {
+		t.Fatal(err)
+	}
+	want := []SourceEntry{
+		{Body: ephemeris.Sun, Name: "Sun", Ephemeris: "swiss", File: "sepl_18.se1", From: "1800-01-01", To: "2400-01-01"},
+		{Body: ephemeris.Moon, Name: "Moon", Ephemeris: "moshier", Fallback: true},
+	}
+	if !reflect.DeepEqual(r.Sources, want) {
+		t.Errorf("Sources = %+v, want %+v", r.Sources, want)
+	}
+
+	var b strings.Builder
+	if err := WriteJSON(&b, r, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"file": "sepl_18.se1"`) || !strings.Contains(b.String(), `"fallback": true`) {
+		t.Errorf("JSON has no sources:\n%s", b.String())
+	}
+	b.Reset()
+	if err := WriteText(&b, r, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "(Moon computed with the Moshier ephemeris") || strings.Contains(b.String(), "Sun computed") {
+		t.Errorf("text does not note the Moon's fallback alone:\n%s", b.String())
+	}
+	if strings.Contains(b.String(), "Ephemeris Sources") {
+		t.Errorf("text lists the sources without being asked:\n%s", b.String())
+	}
+	b.Reset()
+	if err := WriteText(&b, r, TextOptions{Sources: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"=== Ephemeris Sources ===",
+		"Sun         Swiss Ephemeris  sepl_18.se1, 1800-01-01 to 2400-01-01",
+		"Moon        Moshier (fallback)\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("verbose text has no %q:\n%s", want, b.String())
+		}
+	}
+
+	// A provider that cannot tell adds nothing.
+	r.Sources = nil
+	if err := AddSources(&r, &p.MockProvider); err != nil || r.Sources != nil {
+		t.Errorf("AddSources(MockProvider) = %v, Sources %v", err, r.Sources)
+	}
+}
+
+// Build finds the aspects once, and every renderer shows them.
+func TestBuild_Aspects(t *testing.T) {
+	p := &ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{
+		ephemeris.Sun: {Longitude: 10}, ephemeris.Moon: {Longitude: 102}, ephemeris.Mars: {Longitude: 192},
+		ephemeris.MeanNode: {Longitude: 72}, ephemeris.TrueNode: {Longitude: 73},
+	}}
+	r, err := Build(p, 2451545, []int{ephemeris.Sun, ephemeris.Moon, ephemeris.Mars, ephemeris.MeanNode, ephemeris.TrueNode}, 0, 0, 'W', "Whole Sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The nodes, a degree apart, are not aspected to each other.
+	meanNode, trueNode := names.Body(ephemeris.MeanNode), names.Body(ephemeris.TrueNode)
+	want := []AspectEntry{
+		{A: "Sun", Aspect: "square", B: "Moon", Orb: 2},
+		{A: "Sun", Aspect: "opposition", B: "Mars", Orb: 2},
+		{A: "Sun", Aspect: "sextile", B: meanNode, Orb: 2},
+		{A: "Sun", Aspect: "sextile", B: trueNode, Orb: 3},
+		{A: "Moon", Aspect: "square", B: "Mars", Orb: 0},
+		{A: "Mars", Aspect: "trine", B: meanNode, Orb: 0},
+		{A: "Mars", Aspect: "trine", B: trueNode, Orb: 1},
+	}
+	if !reflect.DeepEqual(r.Aspects, want) {
+		t.Fatalf("Aspects = %+v, want %+v", r.Aspects, want)
+	}
+
+	var b strings.Builder
+	if err := WriteJSON(&b, r, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Aspects []AspectEntry `json:"aspects"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Aspects, want) {
+		t.Errorf("JSON aspects = %+v, want %+v", got.Aspects, want)
+	}
+	for name, write := range map[string]func(io.Writer, Result) error{
+		"text":     func(w io.Writer, r Result) error { return WriteText(w, r, TextOptions{}) },
+		"yaml":     WriteYAML,
+		"markdown": WriteMarkdown,
+	} {
+		b.Reset()
+		if err := write(&b, r); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), "square") || !strings.Contains(b.String(), "trine") {
+			t.Errorf("%s has no aspects:\n%s", name, b.String())
+		}
+	}
+}
+
+func TestWriteJSON_Compact(t *testing.T) {
+	r := Result{JulianDay: 2451545, Planets: []PlanetEntry{{Name: "Sun", Longitude: 280.5}}}
+	var pretty, compact strings.Builder
+	if err := WriteJSON(&pretty, r, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSON(&compact, r, JSONOptions{Compact: true}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(compact.String(), "\n"); n != 1 || strings.Contains(compact.String(), "  ") {
+		t.Errorf("compact JSON is not one line:\n%s", compact.String())
+	}
+	var a, b any
+	if err := json.Unmarshal([]byte(pretty.String()), &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(compact.String()), &b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("compact JSON differs from indented:\n%s\n%s", compact.String(), pretty.String())
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	v := struct {
+		Name    string     `json:"name"`
+		Degree  float64    `json:"degree"`
+		Flag    bool       `json:"flag"`
+		Quoted  []string   `json:"quoted"`
+		Empty   []string   `json:"empty"`
+		Nested  [][]string `json:"nested"`
+		Entries []struct {
+			House int    `json:"house"`
+			Sign  string `json:"sign"`
+		} `json:"entries"`
+		Omitted *int `json:"omitted,omitempty"`
+	}{
+		Name:   "Placidus",
+		Degree: 12.5,
+		Flag:   true,
+		Quoted: []string{"yes", "2024-01-01", "a: b", "<x>"},
+		Empty:  []string{},
+		Nested: [][]string{{}, {"Sun", "Moon"}},
+	}
+	v.Entries = append(v.Entries, struct {
+		House int    `json:"house"`
+		Sign  string `json:"sign"`
+	}{1, "Aries"})
+	data, err := toYAML(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+name: Placidus
+degree: 12.5
+flag: true
+quoted:
+  - "yes"
+  - "2024-01-01"
+  - "a: b"
+  - "<x>"
+empty: []
+nested:
+  - []
+  - - Sun
+    - Moon
+entries:
+  - house: 1
+    sign: Aries
+`
+	if string(data) != want {
+		t.Errorf("toYAML =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestDignityRows(t *testing.T) {
+	r := Result{
+		Sect: &SectInfo{Sect: "night"},
+		Planets: []PlanetEntry{
+			planetEntry(ephemeris.Saturn, ephemeris.PlanetPos{Longitude: 295}), // Capricorn 25°: domicile, term
+			planetEntry(ephemeris.Moon, ephemeris.PlanetPos{Longitude: 40}),    // Taurus 10°: exaltation, triplicity by night, face
+			planetEntry(ephemeris.Venus, ephemeris.PlanetPos{Longitude: 185}),  // Libra 5°: domicile
+			planetEntry(ephemeris.Sun, ephemeris.PlanetPos{Longitude: 190}),    // Libra 10°: fall
+			planetEntry(ephemeris.Uranus, ephemeris.PlanetPos{Longitude: 10}),  // not classical
+		},
+	}
+	rows := dignityRows(r)
+	want := [][]string{
+		{"Saturn", "Capricorn", "domicile, term", "", "Saturn", "Sun", "7"},
+		{"Moon", "Taurus", "exaltation, triplicity, face", "", "Mercury", "Moon", "8"},
+		{"Venus", "Libra", "domicile", "", "Saturn", "Moon", "5"},
+		{"Sun", "Libra", "peregrine", "fall", "Mercury", "Saturn", "0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("dignityRows =\n%v\nwant\n%v", rows, want)
+	}
+}
+
+func TestMdTable(t *testing.T) {
+	var b strings.Builder
+	mdTable(&b, []string{"Name", "Score"}, "lr", [][]string{{"a|b", "1"}})
+	want := "| Name | Score |\n|---|---:|\n| a\\|b | 1 |\n"
+	if b.String() != want {
+		t.Errorf("mdTable = %q, want %q", b.String(), want)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf strings.Builder
+	n := NewNDJSON(&buf)
+	for _, v := range []NodePeriodEntry{{Peak: 1.5}, {Peak: -2}} {
+		if err := n.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.WriteChart(Result{JulianDay: 2451545, Planets: []PlanetEntry{{Name: "Sun <&>"}}}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[1], `"peak":-2`) {
+		t.Errorf("line 2 = %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], `{"metadata":{"schema_version":"`+SchemaVersion+`"`) ||
+		!strings.Contains(lines[2], `"julian_day":2451545,"planets":[{"name":"Sun <&>"`) {
+		t.Errorf("chart line = %s", lines[2])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = float64(i-1) * 30
+	}
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 45.5, SpeedLon: 1}},
+		Houses:  houses,
+	}
+	r, err := Build(p, 0, []int{ephemeris.Sun}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := WriteCSV(&b, r); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 1+1+2+12 {
+		t.Fatalf("got %d lines:\n%s", len(lines), b.String())
+	}
+	for i, want := range map[int]string{
+		0:  "kind,name,longitude,sign,sign_degree,speed,house",
+		1:  "planet,Sun,45.5,Taurus,15.5,1,2",
+		2:  "angle,Ascendant,0,Aries,0,,1",
+		15: "cusp,House 12,330,Pisces,0,,12",
+	} {
+		if lines[i] != want {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	var houses ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		houses.Cusps[i] = float64(i-1) * 30
+	}
+	houses.MC = 280
+	p := &ephemeris.MockProvider{
+		Epoch: 2451545,
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:  {Longitude: 54.4965},
+			ephemeris.Moon: {Longitude: 298.45, SpeedLon: -1},
+		},
+		Houses: houses,
+	}
+	r, err := Build(p, 2451545, []int{ephemeris.Sun, ephemeris.Moon}, 0, 0, 'E', "Equal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "short.tmpl")
+	text := `{{(time .JulianDay).Format "2006-01-02"}}{{range .Planets}} {{bodyGlyph .Body}}{{zodiacal .Longitude}}{{retro .Speed}} h{{house .Longitude}}{{end}} | {{dms .Ascendant.SignDegree}} {{signGlyph .MC.Longitude}}`
+	if err := os.WriteFile(file, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ParseTemplate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := WriteTemplate(&b, tmpl, r); err != nil {
+		t.Fatal(err)
+	}
+	want := "2000-01-01 ☉24Ta30 h2 ☽28Cp27℞ h10 | 0°00′00″ ♑"
+	if b.String() != want {
+		t.Errorf("WriteTemplate = %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteText_Glyphs(t *testing.T) {
+	r := Result{Planets: []PlanetEntry{planetEntry(ephemeris.Sun, ephemeris.PlanetPos{Longitude: 54.5})}}
+	var b strings.Builder
+	if err := WriteText(&b, r, TextOptions{Glyphs: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "☉ Sun") || !strings.Contains(b.String(), "(♉ 24.50°)") {
+		t.Errorf("no glyphs in:\n%s", b.String())
+	}
+	b.Reset()
+	if err := WriteText(&b, r, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "☉") || !strings.Contains(b.String(), "(Taurus 24.50°)") {
+		t.Errorf("glyphs without the option:\n%s", b.String())
+	}
+}
+
+func TestWriteText_Local(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Result{Local: &LocalInfo{Time: time.Date(1990, 1, 9, 15, 30, 0, 0, berlin), Offset: "+01:00", Zone: "Europe/Berlin", Source: "coordinates", Near: "Berlin, Germany"}}
+	var b strings.Builder
+	if err := WriteText(&b, r, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Local time: 1990-01-09 15:30:00 +01:00 (Europe/Berlin from the coordinates, near Berlin, Germany)\n"
+	if !strings.HasPrefix(b.String(), want) {
+		t.Errorf("text begins %q, want %q", b.String(), want)
+	}
+}
+
+func TestSolarTime(t *testing.T) {
+	mean := ephemeris.JulianDay(time.Date(2024, 11, 3, 23, 50, 0, 0, time.UTC))
+	s := SolarTime(mean, mean+(16*60+26)/86400.0)
+	if s.Mean != "2024-11-03T23:50:00" || s.Apparent != "2024-11-04T00:06:26" || math.Abs(s.Equation-16.4333) > 1e-3 {
+		t.Fatalf("SolarTime = %+v", s)
+	}
+	var b strings.Builder
+	if err := WriteText(&b, Result{SolarTime: s}, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Solar time: apparent 2024-11-04 00:06:26, mean 2024-11-03 23:50:00 (equation of time +16m26s)\n"
+	if !strings.HasPrefix(b.String(), want) {
+		t.Errorf("text begins %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteOneLine(t *testing.T) {
+	r := Result{
+		Planets: []PlanetEntry{
+			planetEntry(ephemeris.Sun, ephemeris.PlanetPos{Longitude: 280.45, SpeedLon: 1}),
+			planetEntry(ephemeris.Moon, ephemeris.PlanetPos{Longitude: 124.9, SpeedLon: -1}),
+		},
+		Ascendant: AngleEntry{Longitude: 172.24},
+		MC:        AngleEntry{Longitude: 80.69},
+		Cusps:     make([]CuspEntry, 12),
+	}
+	for opt, want := range map[TextOptions]string{
+		{}:             "Sun 10Cp27 | Moon 04Le54℞ | ASC 22Vi14 | MC 20Ge41\n",
+		{Glyphs: true}: "☉ 10♑27 | ☽ 04♌54℞ | ASC 22♍14 | MC 20♊41\n",
+	} {
+		var b strings.Builder
+		if err := WriteOneLine(&b, r, opt); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != want {
+			t.Errorf("WriteOneLine(%+v) = %q, want %q", opt, b.String(), want)
+		}
+	}
+
+	// Without houses, the angles are left out.
+	r.Cusps = nil
+	var b strings.Builder
+	WriteOneLine(&b, r, TextOptions{})
+	if strings.Contains(b.String(), "ASC") {
+		t.Errorf("WriteOneLine without houses = %q", b.String())
+	}
+}
+
+func TestBuildEphemeris(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:     {Longitude: 28.5, SpeedLon: 1},
+			ephemeris.Mercury: {Longitude: 100.25, SpeedLon: -0.5},
+		},
+	}
+	tab, err := BuildEphemeris(p, []int{ephemeris.Sun, ephemeris.Mercury}, 0, 3, 1, 0)
+	if err != nil {
+		t.Fatalf("BuildEphemeris: %v", err)
+	}
+	if len(tab.Rows) != 4 {
+		t.Fatalf("got %d rows, want 4 (both ends included)", len(tab.Rows))
+	}
+	var ingress []bool
+	for _, row := range tab.Rows {
+		ingress = append(ingress, row.Positions[0].Ingress)
+		if !row.Positions[1].Retrograde {
+			t.Errorf("Mercury at day %v not retrograde", row.JulianDay)
+		}
+	}
+	// The Sun enters Taurus between days 1 and 2.
+	if want := []bool{false, false, true, false}; !reflect.DeepEqual(ingress, want) {
+		t.Errorf("Sun ingress = %v, want %v", ingress, want)
+	}
+
+	var b strings.Builder
+	if err := WriteEphemerisText(&b, tab); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"every 1d", "28°ARI30'", " 0°TAU30'*", "10°CAN15'R"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("text output lacks %q:\n%s", want, b.String())
+		}
+	}
+
+	g := EphemerisGraph(tab, 90, nil)
+	if len(g.Series) != 2 || g.Series[1].Label != "ME" || len(g.Series[1].Longitudes) != 4 || g.Series[1].Longitudes[0] != 100.25 {
+		t.Errorf("graph series = %+v", g.Series)
+	}
+	if !g.To.Equal(tab.Rows[3].Time) || g.Modulus != 90 || g.Natal != nil {
+		t.Errorf("graph = %+v", g)
+	}
+
+	if _, err := BuildEphemeris(p, []int{ephemeris.Mars}, 0, 3, 1, 0); err == nil {
+		t.Error("expected error for body without mock data")
+	}
+}
+
+// heldProvider holds back its positions at the Julian Day last until held
+// is closed.
+type heldProvider struct {
+	ephemeris.MockProvider
+	last float64
+	held chan struct{}
+}
+
+func (p *heldProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	if jd == p.last {
+		select {
+		case <-p.held:
+		case <-time.After(5 * time.Second):
+			return ephemeris.PlanetPos{}, fmt.Errorf("held at JD %v", jd)
+		}
+	}
+	return p.MockProvider.CalcPlanet(jd, body)
+}
+
+func TestEphemerisRows(t *testing.T) {
+	// The last row waits for the first to be emitted, which it can only be
+	// if rows are passed on as they are ready.
+	p := &heldProvider{
+		MockProvider: ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 28.5, SpeedLon: 1}}},
+		last:         3,
+		held:         make(chan struct{}),
+	}
+	var days []float64
+	err := EphemerisRows(p, []int{ephemeris.Sun}, 0, 3, 1, 2, func(row EphemerisRow) error {
+		if len(days) == 0 {
+			close(p.held)
+		}
+		days = append(days, row.JulianDay)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EphemerisRows: %v", err)
+	}
+	if want := []float64{0, 1, 2, 3}; !reflect.DeepEqual(days, want) {
+		t.Errorf("rows at %v, want %v", days, want)
+	}
+}
+
+func TestBuildCalendar(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	first := time.Date(2025, 7, 1, 0, 0, 0, 0, berlin)
+	// 22:30 UT on 31 July is 00:30 on 1 August in Berlin, past the month.
+	late := ephemeris.JulianDay(time.Date(2025, 7, 31, 22, 30, 0, 0, time.UTC))
+	early := ephemeris.JulianDay(time.Date(2025, 6, 30, 22, 30, 0, 0, time.UTC))
+	sky := []mundane.Event{
+		{JD: early, Kind: mundane.Ingress, Body: ephemeris.Venus, Sign: 2, Longitude: 60},
+		{JD: early + 1, Kind: mundane.Lunation, Body: ephemeris.Moon, Phase: mundane.FullMoon, Eclipse: mundane.TotalLunar, Longitude: 285.5},
+		{JD: late, Kind: mundane.Station, Body: ephemeris.Mercury, Retrograde: true, Longitude: 135.5},
+	}
+	c := BuildCalendar(first, 0, sky, nil)
+	if len(c.Days) != 31 || c.Month != "2025-07" || c.Natal != nil {
+		t.Fatalf("calendar of %d days for %s", len(c.Days), c.Month)
+	}
+	got := c.Days[0].Events
+	if len(got) != 1 || got[0].Summary != "Venus enters Gemini" || got[0].Time.Hour() != 0 {
+		t.Errorf("1 July = %+v, want Venus entering Gemini at 00:30", got)
+	}
+	want := "Full moon in Capricorn 15°30', total lunar eclipse"
+	if got := c.Days[1].Events; len(got) != 1 || got[0].Summary != want || got[0].Eclipse != "total lunar eclipse" {
+		t.Errorf("2 July = %+v, want %q", got, want)
+	}
+	if got := c.Days[30].Events; len(got) != 0 {
+		t.Errorf("31 July = %+v, want the station left to August", got)
+	}
+
+	var b strings.Builder
+	if err := WriteCalendarICS(&b, c, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	ics := b.String()
+	for _, s := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20250630T223000Z\r\n",
+		`SUMMARY:Full moon in Capricorn 15°30'\, total lunar eclipse` + "\r\n",
+		"DTSTAMP:20250601T000000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, s) {
+			t.Errorf("ICS lacks %q:\n%s", s, ics)
+		}
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("ICS line of %d octets: %q", len(line), line)
+		}
+	}
+}
+
+func TestBuildMoonYear(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	jd := ephemeris.JulianDay(time.Date(2025, 3, 14, 6, 54, 0, 0, time.UTC))
+	events := []mundane.Event{
+		{JD: jd - 3, Kind: mundane.Ingress, Body: ephemeris.Venus, Sign: 0},
+		{JD: jd, Kind: mundane.Lunation, Body: ephemeris.Moon, Phase: mundane.FullMoon, Eclipse: mundane.TotalLunar, Longitude: 173.75},
+	}
+	y := BuildMoonYear(2025, tokyo, events)
+	if len(y.Lunations) != 1 || y.TimeZone != "Asia/Tokyo" {
+		t.Fatalf("MoonYear = %+v, want the full moon alone", y)
+	}
+	if e := y.Lunations[0]; e.Time.Hour() != 15 || e.Phase != "full moon" || e.Eclipse != "total lunar eclipse" {
+		t.Errorf("lunation = %+v", e)
+	}
+
+	var b strings.Builder
+	if err := WriteMoonYearText(&b, y); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Fri 14 Mar 15:54  full moon      23°45' Virgo         total lunar eclipse\n"; !strings.HasSuffix(b.String(), want) {
+		t.Errorf("text =\n%s\nwant it to end %q", b.String(), want)
+	}
+	b.Reset()
+	if err := WriteMoonYearCSV(&b, y); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,utc,phase,longitude,sign,sign_degree,eclipse\n" +
+		"2025-03-14T15:54:00+09:00,2025-03-14T06:54:00Z,full moon,173.75,Virgo,23.75,total lunar eclipse\n"
+	if b.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	events[1].Supermoon = true
+	y = BuildMoonYear(2025, tokyo, events)
+	y.Supermoons = true
+	if e := y.Lunations[0]; !e.Supermoon || !strings.HasSuffix(e.Summary, "total lunar eclipse, supermoon") {
+		t.Errorf("supermoon = %+v", e)
+	}
+	b.Reset()
+	WriteMoonYearText(&b, y)
+	if want := "  total lunar eclipse, supermoon\n"; !strings.HasSuffix(b.String(), want) {
+		t.Errorf("text =\n%s\nwant it to end %q", b.String(), want)
+	}
+	b.Reset()
+	WriteMoonYearCSV(&b, y)
+	if want := "eclipse,supermoon\n"; !strings.Contains(b.String(), want) || !strings.HasSuffix(b.String(), ",total lunar eclipse,true\n") {
+		t.Errorf("CSV with supermoons =\n%s", b.String())
+	}
+}
+
+func TestBuildRetrogradeYear(t *testing.T) {
+	jd := ephemeris.JulianDay(time.Date(2025, 3, 15, 6, 46, 0, 0, time.UTC))
+	r := mundane.Retrograde{
+		Body:        ephemeris.Mercury,
+		ShadowStart: mundane.Event{JD: jd - 14, Kind: mundane.Station, Body: ephemeris.Mercury, Retrograde: true, Longitude: 356.75},
+		Station:     mundane.Event{JD: jd, Kind: mundane.Station, Body: ephemeris.Mercury, Retrograde: true, Longitude: 9.5},
+		Direct:      mundane.Event{JD: jd + 23, Kind: mundane.Station, Body: ephemeris.Mercury, Longitude: 356.75},
+		ShadowEnd:   mundane.Event{JD: jd + 42, Kind: mundane.Station, Body: ephemeris.Mercury, Longitude: 9.5},
+	}
+	y := BuildRetrogradeYear(2025, time.UTC, []mundane.Retrograde{r})
+	if len(y.Periods) != 1 {
+		t.Fatalf("Periods = %+v", y.Periods)
+	}
+	p := y.Periods[0]
+	if p.ShadowStart.Kind != "shadow" || p.ShadowStart.Station != "" || p.ShadowStart.Summary != "Mercury enters its retrograde shadow at 26°45' Pisces" {
+		t.Errorf("shadow start = %+v", p.ShadowStart)
+	}
+	if p.Retrograde.Station != "retrograde" || p.Direct.Station != "direct" || p.ShadowEnd.Summary != "Mercury leaves its retrograde shadow at 9°30' Aries" {
+		t.Errorf("period = %+v", p)
+	}
+
+	var b strings.Builder
+	if err := WriteRetrogradeYearText(&b, y); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\nMercury\n  shadow begins  Sat 01 Mar 2025 06:46  26°45' Pisces\n  retrograde     Sat 15 Mar 2025 06:46   9°30' Aries\n"; !strings.Contains(b.String(), want) {
+		t.Errorf("text =\n%s\nwant it to contain\n%s", b.String(), want)
+	}
+}
+
+func TestBuildIngressYear(t *testing.T) {
+	jd := ephemeris.JulianDay(time.Date(2025, 3, 20, 9, 1, 0, 0, time.UTC))
+	events := []mundane.Event{
+		{JD: jd, Kind: mundane.Ingress, Body: ephemeris.Sun, Sign: 0, Longitude: 0},
+		{JD: jd + 30, Kind: mundane.Ingress, Body: ephemeris.Sun, Sign: 1, Longitude: 30},
+		{JD: jd + 10, Kind: mundane.Ingress, Body: ephemeris.Mercury, Sign: 11, Retrograde: true, Longitude: 359.99},
+		{JD: jd + 40, Kind: mundane.Station, Body: ephemeris.Mercury},
+	}
+	y := BuildIngressYear(2025, time.UTC, events)
+	if len(y.Ingresses) != 3 {
+		t.Fatalf("Ingresses = %+v, want the three ingresses", y.Ingresses)
+	}
+	if e := y.Ingresses[0]; e.Season != "March equinox" || e.Summary != "Sun enters Aries, the March equinox" {
+		t.Errorf("equinox = %+v", e)
+	}
+	if e := y.Ingresses[1]; e.Season != "" || e.Summary != "Sun enters Taurus" {
+		t.Errorf("Taurus ingress = %+v", e)
+	}
+
+	var b strings.Builder
+	if err := WriteIngressYearText(&b, y); err != nil {
+		t.Fatal(err)
+	}
+	want := "\nSun\n  Thu 20 Mar 2025 09:01  Sun enters Aries, the March equinox\n  Sat 19 Apr 2025 09:01  Sun enters Taurus\n\nMercury\n  Sun 30 Mar 2025 09:01  Mercury re-enters Pisces, retrograde\n"
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("text =\n%s\nwant it to contain\n%s", b.String(), want)
+	}
+}
+
+func TestCardinalIngress(t *testing.T) {
+	jd := ephemeris.JulianDay(time.Date(2025, 6, 21, 2, 42, 0, 0, time.UTC))
+	if in := CardinalIngress(mundane.Event{JD: jd, Kind: mundane.Ingress, Body: ephemeris.Mars, Sign: 3}); in != nil {
+		t.Errorf("Mars into Cancer = %+v, want nil", in)
+	}
+	if in := CardinalIngress(mundane.Event{JD: jd, Kind: mundane.Ingress, Body: ephemeris.Sun, Sign: 4}); in != nil {
+		t.Errorf("Sun into Leo = %+v, want nil", in)
+	}
+	in := CardinalIngress(mundane.Event{JD: jd, Kind: mundane.Ingress, Body: ephemeris.Sun, Sign: 3})
+	if in == nil || in.Sign != "Cancer" || in.Season != "June solstice" {
+		t.Fatalf("Sun into Cancer = %+v", in)
+	}
+
+	var b strings.Builder
+	if err := WriteText(&b, Result{Ingress: in}, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Cancer ingress, the June solstice: the Sun enters Cancer on 2025-06-21 02:42:00 UTC\n"
+	if !strings.HasPrefix(b.String(), want) {
+		t.Errorf("text begins %q, want %q", b.String(), want)
+	}
+}
+
+func TestBuildRectify(t *testing.T) {
+	jd := ephemeris.JulianDay(time.Date(1990, 1, 9, 6, 0, 0, 0, time.UTC))
+	houses := func(asc float64) ephemeris.HouseResult {
+		h := ephemeris.HouseResult{Ascendant: asc, MC: math.Mod(asc+270, 360)}
+		for i := 1; i <= 12; i++ {
+			h.Cusps[i] = math.Mod(asc+30*float64(i-1), 360)
+		}
+		return h
+	}
+	planets := map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 289}}
+	first := rectify.Chart{JD: jd, Planets: planets, Houses: houses(265)}
+	second := rectify.Chart{JD: jd + 1.0/24, Planets: planets, Houses: houses(275)}
+	spans := []rectify.Span{
+		{First: first, Last: first},
+		{First: second, Last: second, Changes: []rectify.Change{
+			{Kind: rectify.AscendantSign, Sign: 9},
+			{Kind: rectify.HouseRuler, Body: ephemeris.Saturn, House: 1},
+		}},
+	}
+	events := []rectify.Event{{JD: jd + 8000, Name: "married"}}
+	candidates := []rectify.Candidate{{Chart: second, Score: 0.5, Hits: []rectify.Hit{
+		{Event: 0, Technique: rectify.DirectedAngle, Body: ephemeris.Sun, Angle: rectify.MC, Aspect: aspects.Major[2], Orb: 0.5},
+	}}}
+	rep := BuildRectify(spans, candidates, events, []int{ephemeris.Sun}, 1, time.UTC, jd, jd+1.0/24, 1.0/1440, 51.5, 0, "Equal", 10)
+	if len(rep.Spans) != 2 || rep.Spans[0].Houses["Sun"] != 1 || rep.Spans[0].Rulers[0] != "Jupiter" {
+		t.Fatalf("spans = %+v", rep.Spans)
+	}
+	if got := strings.Join(rep.Spans[1].Changes, "; "); got != "Ascendant enters Capricorn; ruler of house 1 becomes Saturn" {
+		t.Errorf("changes = %q", got)
+	}
+	if len(rep.Candidates) != 1 || rep.Candidates[0].Hits[0].Contact != "directed MC square natal Sun" {
+		t.Errorf("candidates = %+v", rep.Candidates)
+	}
+
+	var b strings.Builder
+	if err := WriteRectifyText(&b, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"06:00-06:00  Ascendant 25Sg00  MC 25Vi00\n             houses: Sun 1\n",
+		"07:00-07:00  Ascendant 05Cp00  MC 05Li00  Ascendant enters Capricorn; ruler of house 1 becomes Saturn\n",
+		"  1  07:00  score 0.50  Ascendant 05Cp00  MC 05Li00\n       married: directed MC square natal Sun (0°30')\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("text =\n%s\nwant it to contain\n%s", b.String(), want)
+		}
+	}
+}
+
+func TestBuildSkyReport(t *testing.T) {
+	planets := map[int]ephemeris.PlanetPos{
+		ephemeris.Sun:  {Longitude: 0, SpeedLon: 1},
+		ephemeris.Moon: {Longitude: 100, SpeedLon: 12},
+	}
+	for _, body := range []int{ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn} {
+		planets[body] = ephemeris.PlanetPos{Longitude: 345}
+	}
+	p := &ephemeris.MockProvider{Epoch: ephemeris.JulianDay(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), Planets: planets}
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rep, err := BuildSkyReport(p, at, []int{ephemeris.Sun, ephemeris.Moon}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rep.Moon
+	if m.Phase != "first quarter" || math.Abs(m.Illumination-0.5868) > 1e-4 || m.NextSign != "Leo" {
+		t.Errorf("Moon = %+v, want first quarter, 58.68%% lit, next in Leo", m)
+	}
+	// 20° to go at 12° a day.
+	if want := at.Add(40 * time.Hour); !m.LeavesSign.Equal(want) {
+		t.Errorf("LeavesSign = %v, want %v", m.LeavesSign, want)
+	}
+	// The trine to the planets at 345° is 15° short, reached in 10 hours.
+	if a := m.NextAspect; m.VoidOfCourse || a == nil || a.Aspect != "trine" || a.Other != "Mercury" || !a.Time.Equal(at.Add(10*time.Hour)) {
+		t.Errorf("NextAspect = %+v, void %v; want trine Mercury at 10:00", a, m.VoidOfCourse)
+	}
+	if rep.Hour != nil || len(rep.Aspects) != 0 || len(rep.Planets) != 2 {
+		t.Errorf("report = %+v, want two planets and no hour or aspects", rep)
+	}
+
+	for _, body := range []int{ephemeris.Mercury, ephemeris.Venus, ephemeris.Mars, ephemeris.Jupiter, ephemeris.Saturn} {
+		planets[body] = ephemeris.PlanetPos{Longitude: 5}
+	}
+	if rep, err = BuildSkyReport(p, at, nil, nil, nil); err != nil || !rep.Moon.VoidOfCourse || rep.Moon.NextAspect != nil {
+		t.Errorf("Moon = %+v, %v; want void of course", rep.Moon, err)
+	}
+}
+
+func TestCompareHouses(t *testing.T) {
+	planets := map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 25}}
+	var equal, whole ephemeris.HouseResult
+	for i := 1; i <= 12; i++ {
+		equal.Cusps[i] = 20 + float64(i-1)*30
+		whole.Cusps[i] = float64(i-1) * 30
+	}
+	equal.Ascendant, whole.Ascendant = 20, 20
+	var charts []Result
+	for _, c := range []struct {
+		houses ephemeris.HouseResult
+		name   string
+	}{{equal, "Equal"}, {whole, "Whole Sign"}} {
+		r, err := Build(&ephemeris.MockProvider{Planets: planets, Houses: c.houses}, 0, []int{ephemeris.Sun}, 10, 20, 'E', c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		charts = append(charts, r)
+	}
+	c := CompareHouses(charts)
+	if len(c.Systems) != 2 || c.Systems[0].Houses["Sun"] != 1 || c.Systems[1].Houses["Sun"] != 1 {
+		t.Fatalf("Systems = %+v", c.Systems)
+	}
+	if c.Systems[1].Key != "whole-sign" || c.Systems[1].Cusps[1].Longitude != 30 {
+		t.Errorf("whole sign = %+v", c.Systems[1])
+	}
+	for name, want := range map[string]string{"Polich/Page": "polich-page", "Equal (MC)": "equal-mc", "Sunshine/alt.": "sunshine-alt"} {
+		if got := systemKey(name); got != want {
+			t.Errorf("systemKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	// A Sun at 15° is in the 1st whole-sign house but the equal 12th.
+	charts[0].Planets[0].Longitude, charts[1].Planets[0].Longitude = 15, 15
+	c = CompareHouses(charts)
+	var b strings.Builder
+	if err := WriteHouseComparisonJSON(&b, c, JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Metadata struct {
+			HouseSystem string `json:"house_system"`
+		} `json:"metadata"`
+		HouseSystems map[string]struct {
+			Houses map[string]int `json:"planet_houses"`
+		} `json:"house_systems"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Metadata.HouseSystem != "" || got.HouseSystems["equal"].Houses["Sun"] != 12 || got.HouseSystems["whole-sign"].Houses["Sun"] != 1 {
+		t.Errorf("JSON = %s", b.String())
+	}
+
+	b.Reset()
+	if err := WriteHouseComparisonText(&b, c); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Sun 15Ar00  12      1\n") {
+		t.Errorf("text =\n%s", b.String())
+	}
+
+	// Systems that cannot be cast at the latitude are named, not compared.
+	c.Skipped = []string{"Placidus", "Polich/Page"}
+	b.Reset()
+	if err := WriteHouseComparisonText(&b, c); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "(Placidus, Polich/Page houses cannot be cast within the polar circle: left out;") {
+		t.Errorf("text does not name the skipped systems:\n%s", b.String())
+	}
+	b.Reset()
+	if err := WriteHouseComparisonJSON(&b, c, JSONOptions{Compact: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"skipped":["placidus","polich-page"]`) {
+		t.Errorf("JSON does not list the skipped systems: %s", b.String())
+	}
+}
+
+func TestWriteAyanamsaText(t *testing.T) {
+	rep := AyanamsaReport{
+		Time:      time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC),
+		JulianDay: 2451545,
+		Ayanamsas: []SiderealInfo{{"Lahiri", 23.853222}, {"Fagan-Bradley", 24.73643}},
+	}
+	var b strings.Builder
+	if err := WriteAyanamsaText(&b, rep); err != nil {
+		t.Fatal(err)
+	}
+	want := "=== Ayanamsas at 2000-01-01 12:00 UTC (JD 2451545.000000) ===\n" +
+		"Lahiri           23.8532°  23°51′12″\n" +
+		"Fagan-Bradley    24.7364°  24°44′11″  +0.8832° from Lahiri\n"
+	if b.String() != want {
+		t.Errorf("text =\n%s\nwant\n%s", b.String(), want)
+	}
+}
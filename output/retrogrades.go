@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/names"
+)
+
+// RetrogradePeriod is a planet's retrograde period with its shadow, each
+// moment an event with the planet's position.
+type RetrogradePeriod struct {
+	Planet      string        `json:"planet"`
+	ShadowStart CalendarEvent `json:"shadow_start"`
+	Retrograde  CalendarEvent `json:"station_retrograde"`
+	Direct      CalendarEvent `json:"station_direct"`
+	ShadowEnd   CalendarEvent `json:"shadow_end"`
+}
+
+// events returns the period's moments in order.
+func (r RetrogradePeriod) events() []CalendarEvent {
+	return []CalendarEvent{r.ShadowStart, r.Retrograde, r.Direct, r.ShadowEnd}
+}
+
+// RetrogradeYear is the retrograde periods of a year, for "astro
+// retrogrades".
+type RetrogradeYear struct {
+	Year     int                `json:"year"`
+	TimeZone string             `json:"timezone"`
+	Periods  []RetrogradePeriod `json:"periods"` // by planet, then in time
+}
+
+// BuildRetrogradeYear describes periods, found for year, with their times
+// in loc.
+func BuildRetrogradeYear(year int, loc *time.Location, periods []mundane.Retrograde) RetrogradeYear {
+	y := RetrogradeYear{Year: year, TimeZone: loc.String(), Periods: []RetrogradePeriod{}}
+	for _, r := range periods {
+		body := names.Body(r.Body)
+		event := func(e mundane.Event, kind, summary string) CalendarEvent {
+			ce := mundaneEvent(e)
+			ce.Time = ephemeris.TimeOf(e.JD).In(loc)
+			ce.Kind = kind
+			if summary != "" {
+				ce.Station = ""
+				ce.Summary = fmt.Sprintf(summary, body, degrees(ce.Position.SignDegree), ce.Position.Sign)
+			}
+			return ce
+		}
+		y.Periods = append(y.Periods, RetrogradePeriod{
+			Planet:      body,
+			ShadowStart: event(r.ShadowStart, "shadow", "%s enters its retrograde shadow at %s %s"),
+			Retrograde:  event(r.Station, "station", ""),
+			Direct:      event(r.Direct, "station", ""),
+			ShadowEnd:   event(r.ShadowEnd, "shadow", "%s leaves its retrograde shadow at %s %s"),
+		})
+	}
+	return y
+}
+
+// WriteRetrogradeYearText writes the periods to w, under each planet's
+// name the four moments of each of its periods.
+func WriteRetrogradeYearText(w io.Writer, y RetrogradeYear) error {
+	fmt.Fprintf(w, "=== Retrograde periods of %d (%s) ===\n", y.Year, y.TimeZone)
+	if len(y.Periods) == 0 {
+		fmt.Fprintln(w, "\nnone")
+	}
+	planet := ""
+	for _, r := range y.Periods {
+		if r.Planet != planet {
+			planet = r.Planet
+			fmt.Fprintf(w, "\n%s\n", planet)
+		} else {
+			fmt.Fprintln(w)
+		}
+		for i, label := range []string{"shadow begins", "retrograde", "direct", "shadow ends"} {
+			e := r.events()[i]
+			fmt.Fprintf(w, "  %-14s %s  %6s %s\n", label, e.Time.Format("Mon 02 Jan 2006 15:04"), degrees(e.Position.SignDegree), e.Position.Sign)
+		}
+	}
+	return nil
+}
+
+// WriteRetrogradeYearICS writes the periods' moments to w as an iCalendar
+// file, as WriteCalendarICS does a month's events.
+func WriteRetrogradeYearICS(w io.Writer, y RetrogradeYear, stamp time.Time) error {
+	var events []CalendarEvent
+	for _, r := range y.Periods {
+		events = append(events, r.events()...)
+	}
+	return writeICS(w, events, stamp)
+}
+
+// PrintRetrogradeYearJSON writes the periods as JSON to stdout, laid out
+// as opt says.
+func PrintRetrogradeYearJSON(y RetrogradeYear, opt JSONOptions) error {
+	return writeJSON(os.Stdout, y, opt)
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// HouseRuler is the ruler of the sign on one house cusp and where that
+// ruler stands.
+type HouseRuler struct {
+	House           int     `json:"house"`
+	Sign            string  `json:"sign"` // the sign on the cusp
+	Ruler           string  `json:"ruler"`
+	RulerSign       string  `json:"ruler_sign"`
+	RulerSignDegree float64 `json:"ruler_sign_degree"`
+	RulerHouse      int     `json:"ruler_house"`
+}
+
+// RulersInfo is the chart ruler and the ruler of every house.
+type RulersInfo struct {
+	Scheme     string       `json:"scheme"`      // "traditional" or "modern"
+	ChartRuler string       `json:"chart_ruler"` // the ruler of the Ascendant's sign
+	Houses     []HouseRuler `json:"houses"`
+}
+
+// AddRulers attaches the chart ruler and the house rulers of r, by the
+// traditional rulerships or, if modern, with Uranus, Neptune and Pluto
+// ruling Aquarius, Pisces and Scorpio. The rulers are computed by p, since
+// the outer planets need not be among r's planets.
+func AddRulers(r *Result, p ephemeris.Provider, modern bool) error {
+	ruler, scheme := dignity.Ruler, "traditional"
+	if modern {
+		ruler, scheme = dignity.ModernRuler, "modern"
+	}
+	houses := houseResult(r)
+	positions := make(map[int]ephemeris.PlanetPos)
+	info := &RulersInfo{
+		Scheme:     scheme,
+		ChartRuler: names.Body(ruler(dignity.Sign(r.Ascendant.Longitude))),
+	}
+	for _, c := range r.Cusps {
+		body := ruler(dignity.Sign(c.Longitude))
+		pos, ok := positions[body]
+		if !ok {
+			var err error
+			if pos, err = p.CalcPlanet(r.JulianDay, body); err != nil {
+				return fmt.Errorf("error calculating %s: %w", names.Body(body), err)
+			}
+			positions[body] = pos
+		}
+		sign, deg := names.SignOf(pos.Longitude)
+		info.Houses = append(info.Houses, HouseRuler{
+			House:           c.House,
+			Sign:            c.Sign,
+			Ruler:           names.Body(body),
+			RulerSign:       sign,
+			RulerSignDegree: deg,
+			RulerHouse:      houses.HouseOf(pos.Longitude),
+		})
+	}
+	r.Rulers = info
+	return nil
+}
@@ -0,0 +1,189 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/hours"
+	"github.com/dcccxiii/astro/internal/search"
+	"github.com/dcccxiii/astro/lunar"
+	"github.com/dcccxiii/astro/mundane"
+	"github.com/dcccxiii/astro/names"
+)
+
+// SkyReport is the state of the sky at a moment, for "astro sky".
+type SkyReport struct {
+	Time     time.Time     `json:"time"` // in TimeZone
+	TimeZone string        `json:"timezone"`
+	Lat      *float64      `json:"lat,omitempty"`
+	Lon      *float64      `json:"lon,omitempty"`
+	Planets  []PlanetEntry `json:"planets"`
+	Moon     SkyMoon       `json:"moon"`
+	// Hour is the planetary hour at Time; it needs a place.
+	Hour *SkyHour `json:"planetary_hour,omitempty"`
+	// Aspects are the exact aspects between the planets on the local day
+	// of Time, before and after it.
+	Aspects []SkyAspect `json:"aspects_today"`
+}
+
+// SkyMoon is the Moon's phase and course.
+type SkyMoon struct {
+	Phase        string  `json:"phase"`
+	Illumination float64 `json:"illumination"` // the fraction of the disc lit, 0 to 1
+	VoidOfCourse bool    `json:"void_of_course"`
+	// NextAspect is the next major aspect the Moon perfects with a
+	// classical planet before it leaves its sign; nil when it is void.
+	NextAspect *SkyAspect `json:"next_aspect,omitempty"`
+	LeavesSign time.Time  `json:"leaves_sign"`
+	NextSign   string     `json:"next_sign"`
+}
+
+// SkyHour is a planetary hour.
+type SkyHour struct {
+	Number   int       `json:"number"` // 1-12 day, 13-24 night
+	Ruler    string    `json:"ruler"`
+	DayRuler string    `json:"day_ruler"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+// SkyAspect is an exact aspect between two planets.
+type SkyAspect struct {
+	Time   time.Time `json:"time"`
+	Planet string    `json:"planet"`
+	Aspect string    `json:"aspect"`
+	Other  string    `json:"other"`
+}
+
+// BuildSkyReport reports the positions of planets at t, with the Moon's
+// phase and course, in t's time zone. day
+// is the planetary day containing t, if the place is known; aspects are
+// the exact aspects of the day (see mundane.Aspects).
+func BuildSkyReport(p ephemeris.Provider, t time.Time, planets []int, day *hours.Day, aspects []mundane.Event) (SkyReport, error) {
+	loc := t.Location()
+	jd := ephemeris.JulianDay(t)
+	sky, err := BuildSky(p, jd, planets)
+	if err != nil {
+		return SkyReport{}, err
+	}
+	rep := SkyReport{Time: t, TimeZone: loc.String(), Planets: sky.Planets, Aspects: []SkyAspect{}}
+	if rep.Planets == nil {
+		rep.Planets = []PlanetEntry{}
+	}
+
+	pos := map[int]ephemeris.PlanetPos{}
+	for _, body := range append([]int{ephemeris.Moon}, lunar.VoidPlanets...) {
+		if pos[body], err = p.CalcPlanet(jd, body); err != nil {
+			return SkyReport{}, fmt.Errorf("error calculating %s: %w", names.Body(body), err)
+		}
+	}
+	moon, sun := pos[ephemeris.Moon], pos[ephemeris.Sun]
+	delete(pos, ephemeris.Moon)
+	// The end of the sign, which ends any void of course, is searched for;
+	// lunar.NextAspect takes the Moon's motion until then to be uniform,
+	// which is near enough for the order of its aspects.
+	sign := int(moon.Longitude / 30)
+	moonLon := func(jd float64) (float64, error) {
+		pos, err := p.CalcPlanet(jd, ephemeris.Moon)
+		return pos.Longitude, err
+	}
+	leaves, ok, err := search.Next(search.Offset(moonLon, float64(sign+1)*30), jd, 0.25, 3)
+	if err != nil {
+		return SkyReport{}, fmt.Errorf("error calculating %s: %w", names.Body(ephemeris.Moon), err)
+	}
+	if !ok {
+		leaves = jd + (float64(sign+1)*30-moon.Longitude)/moon.SpeedLon
+	}
+	rep.Moon = SkyMoon{
+		Phase:        lunar.PhaseName(moon.Longitude, sun.Longitude),
+		Illumination: lunar.Illumination(moon.Longitude, sun.Longitude),
+		LeavesSign:   ephemeris.TimeOf(leaves).In(loc),
+		NextSign:     names.Default.Sign((sign + 1) % 12),
+	}
+	if pa, ok := lunar.NextAspect(moon, pos); ok {
+		rep.Moon.NextAspect = &SkyAspect{
+			Time:   ephemeris.TimeOf(jd + pa.Days).In(loc),
+			Planet: names.Body(ephemeris.Moon),
+			Aspect: names.Aspect(pa.Aspect.Name),
+			Other:  names.Body(pa.Body),
+		}
+	} else {
+		rep.Moon.VoidOfCourse = true
+	}
+
+	if day != nil {
+		if h, ok := day.At(jd); ok {
+			rep.Hour = &SkyHour{
+				Number:   h.Number,
+				Ruler:    names.Body(h.Ruler),
+				DayRuler: names.Body(day.Ruler),
+				Start:    ephemeris.TimeOf(h.Start).In(loc),
+				End:      ephemeris.TimeOf(h.End).In(loc),
+			}
+		}
+	}
+	for _, e := range aspects {
+		rep.Aspects = append(rep.Aspects, SkyAspect{
+			Time:   ephemeris.TimeOf(e.JD).In(loc),
+			Planet: names.Body(e.Body),
+			Aspect: names.Aspect(e.Aspect.Name),
+			Other:  names.Body(e.Other),
+		})
+	}
+	return rep, nil
+}
+
+// WriteSkyText writes the report to w.
+func WriteSkyText(w io.Writer, rep SkyReport) error {
+	fmt.Fprintf(w, "=== Sky at %s ===\n", rep.Time.Format("2006-01-02 15:04 MST"))
+	width := 10
+	for _, p := range rep.Planets {
+		width = max(width, len([]rune(p.Name)))
+	}
+	for _, p := range rep.Planets {
+		retro := ""
+		if p.Speed < 0 {
+			retro = "  retrograde"
+		}
+		fmt.Fprintf(w, "%-*s  %-11s %6s%s\n", width, p.Name, p.Sign, degrees(p.SignDegree), retro)
+	}
+
+	m := rep.Moon
+	fmt.Fprintf(w, "\nMoon: %s, %.0f%% lit\n", m.Phase, m.Illumination*100)
+	if a := m.NextAspect; a != nil {
+		fmt.Fprintf(w, "Next aspect: %s %s at %s; the Moon enters %s at %s\n",
+			a.Aspect, a.Other, skyTime(rep.Time, a.Time), m.NextSign, skyTime(rep.Time, m.LeavesSign))
+	} else {
+		fmt.Fprintf(w, "Void of course until it enters %s at %s\n", m.NextSign, skyTime(rep.Time, m.LeavesSign))
+	}
+	if h := rep.Hour; h != nil {
+		fmt.Fprintf(w, "Planetary hour: %s (hour %d of the day of %s, %s to %s)\n",
+			h.Ruler, h.Number, h.DayRuler, h.Start.Format("15:04"), h.End.Format("15:04"))
+	}
+
+	fmt.Fprintln(w, "\nExact aspects today:")
+	if len(rep.Aspects) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, a := range rep.Aspects {
+		fmt.Fprintf(w, "  %s  %s %s %s\n", a.Time.Format("15:04"), a.Planet, a.Aspect, a.Other)
+	}
+	return nil
+}
+
+// skyTime formats t, the time of a coming event, with its date when that
+// is not the date of now.
+func skyTime(now, t time.Time) string {
+	if t.Format("2006-01-02") == now.Format("2006-01-02") {
+		return t.Format("15:04")
+	}
+	return t.Format("Mon 15:04")
+}
+
+// PrintSkyJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintSkyJSON(rep SkyReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// SourceEntry says which ephemeris computed one of the chart's planets,
+// and the span of the file it read.
+type SourceEntry struct {
+	Body      int    `json:"-"`
+	Name      string `json:"name"`
+	Ephemeris string `json:"ephemeris"` // swiss, moshier or jpl
+	// Fallback is set when Ephemeris is not the one asked for: the Swiss
+	// Ephemeris files were missing or did not cover the date, and the
+	// position is less precise.
+	Fallback bool   `json:"fallback,omitempty"`
+	File     string `json:"file,omitempty"`
+	From     string `json:"from,omitempty"` // the first date File covers
+	To       string `json:"to,omitempty"`   // and the last
+}
+
+// ephemerisNames are the names of the ephemerides in text output.
+var ephemerisNames = map[string]string{"swiss": "Swiss Ephemeris", "moshier": "Moshier", "jpl": "JPL"}
+
+// AddSources attaches to r the ephemeris that computed each of its
+// planets, for the metadata of the JSON output. It adds nothing when p
+// cannot tell, as a MockProvider cannot.
+func AddSources(r *Result, p ephemeris.Provider) error {
+	sp, ok := p.(ephemeris.SourceProvider)
+	if !ok {
+		return nil
+	}
+	var sources []SourceEntry
+	for _, pl := range r.Planets {
+		s, ok, err := sp.Source(r.JulianDay, pl.Body)
+		if err != nil {
+			return fmt.Errorf("error calculating %s: %w", names.Body(pl.Body), err)
+		}
+		if !ok {
+			return nil
+		}
+		e := SourceEntry{Body: pl.Body, Name: pl.Name, Ephemeris: s.Ephemeris, Fallback: s.Fallback, File: s.File}
+		if s.File != "" {
+			e.From = ephemeris.TimeOf(s.Start).Format("2006-01-02")
+			e.To = ephemeris.TimeOf(s.End).Format("2006-01-02")
+		}
+		sources = append(sources, e)
+	}
+	r.Sources = sources
+	return nil
+}
+
+// writeFallbacks notes under the planets of the text output those that an
+// ephemeris other than the one asked for computed.
+func writeFallbacks(w io.Writer, sources []SourceEntry) {
+	by := map[string][]string{}
+	var order []string
+	for _, s := range sources {
+		if !s.Fallback {
+			continue
+		}
+		if by[s.Ephemeris] == nil {
+			order = append(order, s.Ephemeris)
+		}
+		by[s.Ephemeris] = append(by[s.Ephemeris], s.Name)
+	}
+	for _, e := range order {
+		fmt.Fprintf(w, "(%s computed with the %s ephemeris, as no ephemeris file covers the date: less precise)\n", strings.Join(by[e], ", "), ephemerisNames[e])
+	}
+}
+
+// writeSources lists, for the verbose text output, the ephemeris that
+// computed each planet and the file it read with the dates that file
+// covers, the names padded to width.
+func writeSources(w io.Writer, sources []SourceEntry, width int) {
+	if len(sources) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n=== Ephemeris Sources ===")
+	for _, s := range sources {
+		fmt.Fprintf(w, "%-*s  %s", width, s.Name, ephemerisNames[s.Ephemeris])
+		if s.Fallback {
+			fmt.Fprint(w, " (fallback)")
+		}
+		if s.File != "" {
+			fmt.Fprintf(w, "  %s, %s to %s", s.File, s.From, s.To)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/synastry"
+)
+
+// ChartRef identifies one of the charts being compared.
+type ChartRef struct {
+	Time time.Time `json:"time"`
+	Lat  float64   `json:"lat"`
+	Lon  float64   `json:"lon"`
+}
+
+// InterAspectEntry is one aspect between the two charts.
+type InterAspectEntry struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Aspect string  `json:"aspect"`
+	Orb    float64 `json:"orb"`
+}
+
+// OverlayEntry places a planet of one chart in a house of the other.
+type OverlayEntry struct {
+	Planet string `json:"planet"`
+	House  int    `json:"house"`
+}
+
+// SynastryGrid is the aspect grid: Cells[i][j] is the aspect between
+// Rows[i] (chart A) and Cols[j] (chart B), or "" for none.
+type SynastryGrid struct {
+	Rows  []string   `json:"rows"`
+	Cols  []string   `json:"cols"`
+	Cells [][]string `json:"cells"`
+}
+
+// SynastryReport is the comparison of two charts.
+type SynastryReport struct {
+	A       ChartRef           `json:"a"`
+	B       ChartRef           `json:"b"`
+	Aspects []InterAspectEntry `json:"aspects"`
+	AInB    []OverlayEntry     `json:"a_in_b_houses"`
+	BInA    []OverlayEntry     `json:"b_in_a_houses"`
+	Grid    SynastryGrid       `json:"grid"`
+}
+
+// BuildSynastry converts a synastry.Result into a report.
+func BuildSynastry(a, b ChartRef, ca, cb synastry.Chart, res synastry.Result) SynastryReport {
+	rep := SynastryReport{A: a, B: b, Aspects: []InterAspectEntry{}}
+	for _, ia := range res.Aspects {
+		rep.Aspects = append(rep.Aspects, InterAspectEntry{A: ia.A.Name, B: ia.B.Name, Aspect: names.Aspect(ia.Aspect.Name), Orb: ia.Orb})
+	}
+	rep.AInB = overlayEntries(res.AInB)
+	rep.BInA = overlayEntries(res.BInA)
+
+	for _, body := range ca.Bodies {
+		rep.Grid.Rows = append(rep.Grid.Rows, body.Name)
+	}
+	for _, body := range cb.Bodies {
+		rep.Grid.Cols = append(rep.Grid.Cols, body.Name)
+	}
+	for _, row := range res.Grid {
+		cells := make([]string, len(row))
+		for j, ia := range row {
+			if ia != nil {
+				cells[j] = names.Aspect(ia.Aspect.Name)
+			}
+		}
+		rep.Grid.Cells = append(rep.Grid.Cells, cells)
+	}
+	return rep
+}
+
+func overlayEntries(ovs []synastry.Overlay) []OverlayEntry {
+	out := make([]OverlayEntry, len(ovs))
+	for i, o := range ovs {
+		out[i] = OverlayEntry{Planet: o.Body.Name, House: o.House}
+	}
+	return out
+}
+
+// PrintSynastryText writes the report to stdout: the inter-aspects, both
+// house overlays and the aspect grid.
+func PrintSynastryText(rep SynastryReport) error {
+	fmt.Printf("=== Synastry: A %s  /  B %s ===\n",
+		rep.A.Time.Format("2006-01-02 15:04"), rep.B.Time.Format("2006-01-02 15:04"))
+
+	fmt.Println("\nInter-aspects:")
+	if len(rep.Aspects) == 0 {
+		fmt.Println("  none")
+	}
+	for _, ia := range rep.Aspects {
+		fmt.Printf("  A %-10s  %-11s  B %-10s  orb %5.2f°\n", ia.A, ia.Aspect, ia.B, ia.Orb)
+	}
+
+	fmt.Println("\nA's planets in B's houses:")
+	printOverlays(rep.AInB)
+	fmt.Println("\nB's planets in A's houses:")
+	printOverlays(rep.BInA)
+
+	fmt.Println("\nGrid (rows A, columns B):")
+	var line strings.Builder
+	fmt.Fprintf(&line, "%-10s", "")
+	for _, c := range rep.Grid.Cols {
+		fmt.Fprintf(&line, " %-4s", abbreviate(c))
+	}
+	fmt.Println(strings.TrimRight(line.String(), " "))
+	for i, r := range rep.Grid.Rows {
+		line.Reset()
+		fmt.Fprintf(&line, "%-10s", r)
+		for _, cell := range rep.Grid.Cells[i] {
+			mark := "."
+			if cell != "" {
+				mark = aspectAbbrev(cell)
+			}
+			fmt.Fprintf(&line, " %-4s", mark)
+		}
+		fmt.Println(strings.TrimRight(line.String(), " "))
+	}
+	return nil
+}
+
+func printOverlays(ovs []OverlayEntry) {
+	for _, o := range ovs {
+		fmt.Printf("  %-10s  house %2d\n", o.Planet, o.House)
+	}
+}
+
+// aspectAbbrev returns the grid abbreviation of an aspect by its display
+// name.
+func aspectAbbrev(name string) string {
+	for _, a := range aspects.Major {
+		if names.Aspect(a.Name) == name {
+			return a.Abbrev
+		}
+	}
+	return abbreviate(name)
+}
+
+// abbreviate shortens a column heading to fit the grid.
+func abbreviate(name string) string {
+	r := []rune(strings.TrimSpace(name))
+	if len(r) > 4 {
+		r = r[:4]
+	}
+	return string(r)
+}
+
+// PrintSynastryJSON writes the report as JSON to stdout, laid out as opt says.
+func PrintSynastryJSON(rep SynastryReport, opt JSONOptions) error {
+	return writeJSON(os.Stdout, rep, opt)
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// templateFuncs are the helpers available to chart templates besides the
+// text/template builtins. house is bound to the chart when it is executed.
+var templateFuncs = template.FuncMap{
+	"deg":       func(x float64) string { return fmt.Sprintf("%.2f°", x) },
+	"dms":       angle.FormatDMS,
+	"zodiacal":  zodiacal,
+	"signGlyph": func(lon float64) string { return names.Default.SignGlyph(dignity.Sign(lon)) },
+	"bodyGlyph": func(body int) string { return names.Default.BodyGlyph(body) },
+	"retro": func(speed float64) string {
+		if speed < 0 {
+			return "℞"
+		}
+		return ""
+	},
+	"time":  func(jd float64) time.Time { return ephemeris.TimeOf(jd) },
+	"house": func(float64) int { return 0 },
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseTemplate parses the text/template in file for WriteTemplate, with
+// the helper functions of templateFuncs.
+func ParseTemplate(file string) (*template.Template, error) {
+	t, err := template.New(filepath.Base(file)).Funcs(templateFuncs).ParseFiles(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+	return t, nil
+}
+
+// PrintTemplate renders the chart through t to stdout.
+func PrintTemplate(t *template.Template, r Result) error { return WriteTemplate(os.Stdout, t, r) }
+
+// WriteTemplate renders the chart through t, which ParseTemplate returned,
+// to w.
+func WriteTemplate(w io.Writer, t *template.Template, r Result) error {
+	t, err := t.Clone()
+	if err != nil {
+		return err
+	}
+	house := func(float64) int { return 0 }
+	if r.Cusps != nil {
+		h := houseResult(&r)
+		house = h.HouseOf
+	}
+	if err := t.Funcs(template.FuncMap{"house": house}).Execute(w, r); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+	return nil
+}
+
+// signAbbrevs are the customary two-letter abbreviations of the signs.
+var signAbbrevs = [12]string{"Ar", "Ta", "Ge", "Cn", "Le", "Vi", "Li", "Sc", "Sg", "Cp", "Aq", "Pi"}
+
+// zodiacal formats a longitude in the compact zodiacal notation: degrees,
+// the sign's abbreviation and minutes, e.g. 24Ta29.
+func zodiacal(lon float64) string {
+	return zodiacalWith(lon, func(sign int) string { return signAbbrevs[sign] })
+}
+
+// zodiacalWith is zodiacal with the sign, by index, written by sign.
+func zodiacalWith(lon float64, sign func(int) string) string {
+	m := int(math.Round(angle.Norm360(lon)*60)) % (360 * 60)
+	return fmt.Sprintf("%02d%s%02d", m/60%30, sign(m/(30*60)), m%60)
+}
@@ -1,25 +1,303 @@
 package output
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
 
 // PrintText writes a human-readable report of planetary positions and house
 // cusps to stdout.
-func PrintText(r Result) error {
-	fmt.Printf("Julian Day: %.6f\n\n", r.JulianDay)
+func PrintText(r Result) error { return WriteText(os.Stdout, r, TextOptions{}) }
+
+// TextOptions adjust the text report.
+type TextOptions struct {
+	// Glyphs shows the planet and sign glyphs of names.Default in the
+	// position lists: each planet's glyph before its name, and each
+	// sign's glyph in place of its name.
+	Glyphs bool
+	// Sources lists after the planets the ephemeris and file that
+	// computed each, as --verbose asks.
+	Sources bool
+}
+
+// WriteText writes the report PrintText prints to w.
+func WriteText(w io.Writer, r Result, opt TextOptions) error {
+	planet := func(p PlanetEntry) string {
+		if g := names.Default.BodyGlyph(p.Body); opt.Glyphs && g != p.Name {
+			return g + " " + p.Name
+		}
+		return p.Name
+	}
+	point := func(p PointEntry) string {
+		if g := names.Default.PointGlyph(p.Key); opt.Glyphs && g != p.Name {
+			return g + " " + p.Name
+		}
+		return p.Name
+	}
+	// The name column fits the longest name, translated or with a glyph.
+	width := 10
+	for _, ps := range [][]PlanetEntry{r.Planets, r.Heliocentric} {
+		for _, p := range ps {
+			width = max(width, utf8.RuneCountInString(planet(p)))
+		}
+	}
+	for _, p := range r.Points {
+		width = max(width, utf8.RuneCountInString(point(p)))
+	}
+	sign := func(name string, lon float64) string {
+		if opt.Glyphs {
+			return names.Default.SignGlyph(dignity.Sign(lon))
+		}
+		return name
+	}
+
+	if ret := r.Return; ret != nil {
+		where := ""
+		if ret.Relocated {
+			where = " (relocated)"
+		}
+		fmt.Fprintf(w, "%s return%s: %s at %.4f° (%s %.2f°) on %s\n",
+			strings.ToUpper(ret.Kind[:1])+ret.Kind[1:], where, ret.Planet,
+			ret.Natal.Longitude, ret.Natal.Sign, ret.Natal.SignDegree,
+			ret.Time.Format("2006-01-02 15:04:05 MST"))
+		if len(ret.Passes) > 1 {
+			fmt.Fprintf(w, "Exact passes:")
+			for _, t := range ret.Passes {
+				fmt.Fprintf(w, "  %s", t.Format("2006-01-02 15:04"))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if in := r.Ingress; in != nil {
+		fmt.Fprintf(w, "%s ingress, the %s: the Sun enters %s on %s\n", in.Sign, in.Season, in.Sign, in.Time.Format("2006-01-02 15:04:05 MST"))
+	}
+	if c := r.Composite; c != nil && c.Method == "davison" {
+		fmt.Fprintf(w, "Davison chart of %s (%.4f, %.4f) and %s (%.4f, %.4f); cast for %s at (%.4f, %.4f)\n",
+			c.A.Time.Format("2006-01-02 15:04 MST"), c.A.Lat, c.A.Lon,
+			c.B.Time.Format("2006-01-02 15:04 MST"), c.B.Lat, c.B.Lon,
+			ephemeris.TimeOf(r.JulianDay).Format("2006-01-02 15:04 MST"), r.Lat, r.Lon)
+	} else if c != nil {
+		fmt.Fprintf(w, "Composite of %s (%.4f, %.4f) and %s (%.4f, %.4f); houses at latitude %.4f\n",
+			c.A.Time.Format("2006-01-02 15:04 MST"), c.A.Lat, c.A.Lon,
+			c.B.Time.Format("2006-01-02 15:04 MST"), c.B.Lat, c.B.Lon, c.ReferenceLatitude)
+	}
+	if l := r.Local; l != nil {
+		fmt.Fprintf(w, "Local time: %s %s (%s)\n", l.Time.Format("2006-01-02 15:04:05"), l.Offset, l.describe())
+	}
+	if s := r.SolarTime; s != nil {
+		fmt.Fprintf(w, "Solar time: %s\n", s.describe())
+	}
+	fmt.Fprintf(w, "Julian Day: %.6f\n", r.JulianDay)
+	if sid := r.Sidereal; sid != nil {
+		fmt.Fprintf(w, "Zodiac: sidereal, %s ayanamsa %.4f°\n", sid.Ayanamsa, sid.Degrees)
+	}
+	if v := r.Varga; v != nil {
+		fmt.Fprintf(w, "Divisional chart: %s (%s)\n", v.Code, v.Name)
+	}
+	if r.Observer != "" {
+		fmt.Fprintf(w, "Observer: %s (experimental planetocentric positions; houses omitted)\n", r.Observer)
+	}
+	fmt.Fprintln(w)
 
-	fmt.Println("=== Planetary Positions ===")
+	fmt.Fprintln(w, "=== Planetary Positions ===")
 	for _, p := range r.Planets {
-		fmt.Printf("%-10s  %9.4f°  (%s %5.2f°)  speed: %+.4f°/day\n",
-			p.Name, p.Longitude, p.Sign, p.SignDegree, p.Speed)
+		fmt.Fprintf(w, "%-*s  %9.4f°  (%s %5.2f°)  speed: %+.4f°/day",
+			width, planet(p), p.Longitude, sign(p.Sign, p.Longitude), p.SignDegree, p.Speed)
+		if n := p.Nakshatra; n != nil {
+			fmt.Fprintf(w, "  %s pada %d (%s)", n.Name, n.Pada, n.Lord)
+		}
+		fmt.Fprintln(w)
+	}
+	writeFallbacks(w, r.Sources)
+	if opt.Sources {
+		writeSources(w, r.Sources, width)
 	}
 
-	fmt.Printf("\n=== Houses (%s) for (%.4f°, %.4f°) ===\n", r.HouseName, r.Lat, r.Lon)
-	fmt.Printf("Ascendant:  %9.4f°  (%s %.2f°)\n", r.Ascendant.Longitude, r.Ascendant.Sign, r.Ascendant.SignDegree)
-	fmt.Printf("MC:         %9.4f°  (%s %.2f°)\n", r.MC.Longitude, r.MC.Sign, r.MC.SignDegree)
+	if len(r.Points) > 0 {
+		fmt.Fprintln(w, "\n=== Chart Points ===")
+		for _, p := range r.Points {
+			fmt.Fprintf(w, "%-*s  %9.4f°  (%s %5.2f°)  house %d\n",
+				width, point(p), p.Longitude, sign(p.Sign, p.Longitude), p.SignDegree, p.House)
+		}
+	}
+
+	if nd := r.NodeDivergence; nd != nil {
+		flag := ""
+		if nd.Large {
+			flag = fmt.Sprintf("  [large: over %.2f°]", nd.Threshold)
+		}
+		fmt.Fprintf(w, "\nNode divergence (true − mean): %+.4f°%s\n", nd.Degrees, flag)
+	}
+
+	if len(r.Aspects) > 0 {
+		fmt.Fprintln(w, "\n=== Aspects ===")
+		for _, a := range r.Aspects {
+			fmt.Fprintf(w, "%-*s  %-11s  %-*s  orb %5.2f°\n", width, a.A, a.Aspect, width, a.B, a.Orb)
+		}
+	}
+
+	if len(r.Receptions) > 0 {
+		fmt.Fprintln(w, "\n=== Mutual Receptions ===")
+		for _, rec := range r.Receptions {
+			if rec.Kind == "mixed" {
+				fmt.Fprintf(w, "%s and %s: mixed (%s in %s's %s, %s in %s's %s)\n",
+					rec.A, rec.B, rec.A, rec.B, rec.AIn, rec.B, rec.A, rec.BIn)
+			} else {
+				fmt.Fprintf(w, "%s and %s: by %s\n", rec.A, rec.B, rec.Kind)
+			}
+		}
+	}
 
-	fmt.Println("\nHouse cusps:")
+	if len(r.Patterns) > 0 {
+		fmt.Fprintln(w, "\n=== Aspect Patterns ===")
+		for _, pat := range r.Patterns {
+			kind := strings.ToUpper(pat.Kind[:1]) + pat.Kind[1:]
+			if pat.Sign != "" {
+				kind += " in " + pat.Sign
+			}
+			var notes []string
+			for _, n := range []string{pat.Element, pat.Modality} {
+				if n != "" {
+					notes = append(notes, n)
+				}
+			}
+			if pat.Apex != "" {
+				notes = append(notes, "apex "+pat.Apex)
+			}
+			fmt.Fprintf(w, "%s: %s", kind, strings.Join(pat.Members, ", "))
+			if len(notes) > 0 {
+				fmt.Fprintf(w, " (%s)", strings.Join(notes, ", "))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if len(r.Heliocentric) > 0 {
+		fmt.Fprintln(w, "\n=== Heliocentric Positions ===")
+		for _, p := range r.Heliocentric {
+			fmt.Fprintf(w, "%-*s  %9.4f°  (%s %5.2f°)  speed: %+.4f°/day\n",
+				width, planet(p), p.Longitude, sign(p.Sign, p.Longitude), p.SignDegree, p.Speed)
+		}
+	}
+
+	if r.Cusps == nil {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\n=== Houses (%s) for (%.4f°, %.4f°) ===\n", r.HouseName, r.Lat, r.Lon)
+	if r.HouseFallback != "" {
+		fmt.Fprintf(w, "(%s houses cannot be cast within the polar circle: %s in their place)\n", r.HouseFallback, r.HouseName)
+	}
+	fmt.Fprintf(w, "%-11s %9.4f°  (%s %.2f°)\n", names.Point(names.Ascendant)+":", r.Ascendant.Longitude, sign(r.Ascendant.Sign, r.Ascendant.Longitude), r.Ascendant.SignDegree)
+	fmt.Fprintf(w, "%-11s %9.4f°  (%s %.2f°)\n", names.Point(names.MC)+":", r.MC.Longitude, sign(r.MC.Sign, r.MC.Longitude), r.MC.SignDegree)
+	if s := r.Sect; s != nil {
+		fmt.Fprintf(w, "%-11s %s (light %s, benefic %s, malefic %s)\n", "Sect:", s.Sect, s.Light, s.Benefic, s.Malefic)
+	}
+
+	fmt.Fprintln(w, "\nHouse cusps:")
 	for _, c := range r.Cusps {
-		fmt.Printf("  House %2d: %9.4f°  (%s %.2f°)\n", c.House, c.Longitude, c.Sign, c.SignDegree)
+		fmt.Fprintf(w, "  House %2d: %9.4f°  (%s %.2f°)\n", c.House, c.Longitude, sign(c.Sign, c.Longitude), c.SignDegree)
+	}
+
+	if b := r.Balance; b != nil {
+		fmt.Fprintln(w, "\n=== Chart Summary ===")
+		e, m := b.Elements, b.Modalities
+		fmt.Fprintf(w, "Elements:    fire %g, earth %g, air %g, water %g\n", e.Fire, e.Earth, e.Air, e.Water)
+		fmt.Fprintf(w, "Modalities:  cardinal %g, fixed %g, mutable %g\n", m.Cardinal, m.Fixed, m.Mutable)
+		if b.Weighted {
+			fmt.Fprintf(w, "(weighted: Sun, Moon and Ascendant count %d)\n", LuminaryWeight)
+		}
+	}
+	if e := r.Emphasis; e != nil {
+		fmt.Fprintf(w, "Hemispheres: east %d, west %d; north %d (below the horizon), south %d (above)\n",
+			len(e.East), len(e.West), len(e.North), len(e.South))
+		fmt.Fprintf(w, "Quadrants:   ")
+		for i, q := range e.Quadrants {
+			fmt.Fprintf(w, "%s %d", ordinal(i+1), len(q))
+			if len(q) > 0 {
+				fmt.Fprintf(w, " (%s)", strings.Join(q, ", "))
+			}
+			if i < 3 {
+				fmt.Fprint(w, "; ")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if ru := r.Rulers; ru != nil {
+		fmt.Fprintf(w, "\n=== House Rulers (%s) ===\n", ru.Scheme)
+		fmt.Fprintf(w, "Chart ruler: %s\n", ru.ChartRuler)
+		for _, h := range ru.Houses {
+			fmt.Fprintf(w, "  House %2d  %-12s %-8s in %-11s %5.2f°  (house %d)\n",
+				h.House, h.Sign, h.Ruler, h.RulerSign, h.RulerSignDegree, h.RulerHouse)
+		}
+	}
+
+	if len(r.Visibility) > 0 {
+		fmt.Fprintln(w, "\n=== Visibility ===")
+		for _, v := range r.Visibility {
+			fmt.Fprintf(w, "%-10s altitude %+6.1f°  elongation %5.1f°  %s\n", v.Name, v.Altitude, v.Elongation, v.describe())
+		}
+	}
+
+	if h := r.Horary; h != nil {
+		fmt.Fprintln(w, "\n=== Horary considerations ===")
+		for _, c := range h.Checks {
+			mark := "[x]"
+			if !c.OK {
+				mark = "[ ]"
+			}
+			fmt.Fprintf(w, "%s %s\n", mark, c.Detail)
+		}
+		if h.Radical {
+			fmt.Fprintln(w, "Radical: yes")
+		} else {
+			fmt.Fprintln(w, "Radical: no; judge with caution")
+		}
 	}
 	return nil
 }
+
+// WriteOneLine writes the chart to w on a single line, for status bars,
+// bots and shell prompts: each planet's position in zodiacal notation, with
+// ℞ when retrograde, then any chart points and the Ascendant and MC,
+// separated by " | ", e.g.
+// "Sun 10Cp27 | Moon 04Le55 | ... | ASC 22Vi14 | MC 20Ge41". With
+// opt.Glyphs, planet and sign glyphs replace the names and abbreviations.
+func WriteOneLine(w io.Writer, r Result, opt TextOptions) error {
+	pos := zodiacal
+	if opt.Glyphs {
+		pos = func(lon float64) string { return zodiacalWith(lon, names.Default.SignGlyph) }
+	}
+	var parts []string
+	for _, p := range r.Planets {
+		name := p.Name
+		if opt.Glyphs {
+			name = names.Default.BodyGlyph(p.Body)
+		}
+		s := name + " " + pos(p.Longitude)
+		if p.Speed < 0 {
+			s += "℞"
+		}
+		parts = append(parts, s)
+	}
+	for _, p := range r.Points {
+		name := p.Name
+		if opt.Glyphs {
+			name = names.Default.PointGlyph(p.Key)
+		}
+		parts = append(parts, name+" "+pos(p.Longitude))
+	}
+	if r.Cusps != nil {
+		parts = append(parts, "ASC "+pos(r.Ascendant.Longitude), "MC "+pos(r.MC.Longitude))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(parts, " | "))
+	return err
+}
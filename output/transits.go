@@ -0,0 +1,142 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/transits"
+)
+
+// TransitEntry holds presentation-ready data for one transit event.
+type TransitEntry struct {
+	Time      time.Time  `json:"time"`
+	JulianDay float64    `json:"julian_day"`
+	Planet    string     `json:"planet"` // the transiting planet
+	Aspect    string     `json:"aspect"`
+	Natal     string     `json:"natal"` // the natal point aspected
+	Event     string     `json:"event"` // ingress, exact or egress
+	Position  AngleEntry `json:"position"`
+}
+
+// TransitList is the chronological list of transits to a natal chart.
+type TransitList struct {
+	Natal   time.Time      `json:"natal"`
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Entries []TransitEntry `json:"events"`
+}
+
+// BuildTransits converts scanned events into a list. events should already
+// be in chronological order.
+func BuildTransits(natal, from, to float64, events []transits.Event) TransitList {
+	tl := TransitList{
+		Natal:   ephemeris.TimeOf(natal),
+		From:    ephemeris.TimeOf(from),
+		To:      ephemeris.TimeOf(to),
+		Entries: []TransitEntry{},
+	}
+	for _, e := range events {
+		tl.Entries = append(tl.Entries, TransitEntry{
+			Time:      ephemeris.TimeOf(e.JD),
+			JulianDay: e.JD,
+			Planet:    names.Body(e.Body),
+			Aspect:    names.Aspect(e.Aspect.Name),
+			Natal:     e.Point.Name,
+			Event:     e.Kind.String(),
+			Position:  angleEntry(e.Longitude),
+		})
+	}
+	return tl
+}
+
+// PrintTransitsText writes the list as a table to stdout.
+func PrintTransitsText(tl TransitList) error {
+	fmt.Printf("=== Transits to %s natal chart, %s to %s ===\n",
+		tl.Natal.Format("2006-01-02 15:04"), tl.From.Format("2006-01-02"), tl.To.Format("2006-01-02"))
+	if len(tl.Entries) == 0 {
+		fmt.Println("No transits.")
+		return nil
+	}
+	for _, e := range tl.Entries {
+		fmt.Printf("%s  %-8s  %-11s  natal %-10s  %-7s  (%s %5.2f°)\n",
+			e.Time.Format("2006-01-02 15:04"), e.Planet, e.Aspect, e.Natal, e.Event,
+			e.Position.Sign, e.Position.SignDegree)
+	}
+	return nil
+}
+
+// ActiveEntry holds presentation-ready data for one transit in orb.
+type ActiveEntry struct {
+	Planet    string     `json:"planet"`
+	Aspect    string     `json:"aspect"`
+	Natal     string     `json:"natal"`
+	Orb       float64    `json:"orb"` // signed: positive when the planet is ahead of the exact point in longitude
+	Status    string     `json:"status"`
+	Position  AngleEntry `json:"position"`
+	NextExact *time.Time `json:"next_exact,omitempty"`
+	DaysToGo  *float64   `json:"days_until_exact,omitempty"`
+}
+
+// TransitSnapshot lists the transits in orb at one moment.
+type TransitSnapshot struct {
+	Natal   time.Time     `json:"natal"`
+	Time    time.Time     `json:"time"`
+	Entries []ActiveEntry `json:"transits"`
+}
+
+// BuildTransitSnapshot converts the transits active at jd into a snapshot.
+func BuildTransitSnapshot(natal, jd float64, active []transits.Active) TransitSnapshot {
+	snap := TransitSnapshot{Natal: ephemeris.TimeOf(natal), Time: ephemeris.TimeOf(jd), Entries: []ActiveEntry{}}
+	for _, a := range active {
+		e := ActiveEntry{
+			Planet:   names.Body(a.Body),
+			Aspect:   names.Aspect(a.Aspect.Name),
+			Natal:    a.Point.Name,
+			Orb:      a.Orb,
+			Status:   "separating",
+			Position: angleEntry(a.Longitude),
+		}
+		if a.Applying {
+			e.Status = "applying"
+		}
+		if a.NextExact != 0 {
+			t := ephemeris.TimeOf(a.NextExact)
+			days := a.NextExact - jd
+			e.NextExact, e.DaysToGo = &t, &days
+		}
+		snap.Entries = append(snap.Entries, e)
+	}
+	return snap
+}
+
+// PrintTransitSnapshotText writes the snapshot as a table to stdout.
+func PrintTransitSnapshotText(snap TransitSnapshot) error {
+	fmt.Printf("=== Transits in orb to %s natal chart at %s ===\n",
+		snap.Natal.Format("2006-01-02 15:04"), snap.Time.Format("2006-01-02 15:04 MST"))
+	if len(snap.Entries) == 0 {
+		fmt.Println("No transits in orb.")
+		return nil
+	}
+	for _, e := range snap.Entries {
+		exact := "no exact hit before leaving orb"
+		if e.NextExact != nil {
+			exact = fmt.Sprintf("exact in %.1f days (%s)", *e.DaysToGo, e.NextExact.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("%-8s  %-11s  natal %-10s  orb %+5.2f°  %-10s  %s\n",
+			e.Planet, e.Aspect, e.Natal, e.Orb, e.Status, exact)
+	}
+	return nil
+}
+
+// PrintTransitSnapshotJSON writes the snapshot as JSON to stdout, laid out as opt says.
+func PrintTransitSnapshotJSON(snap TransitSnapshot, opt JSONOptions) error {
+	return writeJSON(os.Stdout, snap, opt)
+}
+
+// PrintTransitsJSON writes the list as JSON to stdout, laid out as opt says.
+func PrintTransitsJSON(tl TransitList, opt JSONOptions) error {
+	return writeJSON(os.Stdout, tl, opt)
+}
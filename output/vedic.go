@@ -0,0 +1,93 @@
+package output
+
+import (
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/vedic"
+)
+
+// SiderealInfo describes the sidereal zodiac a chart was cast in.
+type SiderealInfo struct {
+	Ayanamsa string  `json:"ayanamsa"` // e.g. "Lahiri"
+	Degrees  float64 `json:"degrees"`  // tropical minus sidereal longitude at the chart time
+}
+
+// NakshatraEntry is the lunar mansion a planet occupies.
+type NakshatraEntry struct {
+	Name string `json:"name"`
+	Pada int    `json:"pada"`
+	Lord string `json:"lord"`
+}
+
+// AddNakshatras annotates every planet of r with its nakshatra and pada.
+// The positions must be sidereal.
+func AddNakshatras(r *Result) {
+	for i := range r.Planets {
+		n := vedic.NakshatraOf(r.Planets[i].Longitude)
+		r.Planets[i].Nakshatra = &NakshatraEntry{Name: n.Name(), Pada: n.Pada, Lord: grahaName(n.Lord)}
+	}
+}
+
+// grahaName returns the display name of a graha, taking the planets'
+// names from the names registry like every other renderer.
+func grahaName(g vedic.Graha) string {
+	switch g {
+	case vedic.Rahu:
+		return names.Point(names.Rahu)
+	case vedic.Ketu:
+		return names.Point(names.Ketu)
+	}
+	id, _ := g.Body()
+	return names.Body(id)
+}
+
+// VargaInfo identifies the divisional chart a result shows.
+type VargaInfo struct {
+	Code string `json:"code"` // e.g. "D9"
+	Name string `json:"name"` // e.g. "Navamsha"
+}
+
+// ApplyVarga turns the sidereal chart r into divisional chart v: every
+// planet and point, the Ascendant and the MC move to their varga positions, and the
+// houses become whole signs counted from the varga Ascendant. Speeds are
+// left as in the birth chart. Nakshatras are dropped, as they belong to
+// the birth chart's longitudes.
+func ApplyVarga(r *Result, v vedic.Varga) {
+	r.Varga = &VargaInfo{Code: v.String(), Name: v.Name()}
+	for i := range r.Planets {
+		p := &r.Planets[i]
+		p.Longitude = v.Longitude(p.Longitude)
+		p.Sign, p.SignDegree = names.SignOf(p.Longitude)
+		p.Nakshatra = nil
+	}
+	for i := range r.Points {
+		p := &r.Points[i]
+		p.Longitude = v.Longitude(p.Longitude)
+		p.Sign, p.SignDegree = names.SignOf(p.Longitude)
+	}
+	r.Aspects = findAspects(r)
+	r.Receptions = receptions(r)
+	r.Patterns = findPatterns(r)
+	if r.Cusps == nil {
+		return
+	}
+	r.Ascendant = angleEntry(v.Longitude(r.Ascendant.Longitude))
+	r.MC = angleEntry(v.Longitude(r.MC.Longitude))
+	r.HouseName, r.houseSystem = names.HouseSystem("Whole Sign"), "Whole Sign"
+	r.HouseFallback, r.fallbackSystem = "", ""
+	first := math.Floor(r.Ascendant.Longitude/30) * 30
+	for i := range r.Cusps {
+		lon := angle.Norm360(first + float64(i)*30)
+		sign, deg := names.SignOf(lon)
+		r.Cusps[i] = CuspEntry{House: i + 1, Longitude: lon, Sign: sign, SignDegree: deg}
+	}
+	for i := range r.Points {
+		r.Points[i].House = 1 + int(angle.Norm360(r.Points[i].Longitude-first)/30)
+	}
+	if r.Balance != nil {
+		AddBalance(r, r.Balance.Weighted)
+	}
+	r.Emphasis = emphasis(r)
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+)
+
+// VisibilityEntry says whether a planet can be seen with the naked eye at
+// the chart's moment and place.
+type VisibilityEntry struct {
+	Body       int     `json:"-"`
+	Name       string  `json:"name"`
+	Altitude   float64 `json:"altitude"`   // apparent, in degrees; negative below the horizon
+	Elongation float64 `json:"elongation"` // the angle from the Sun, in degrees
+	// Magnitude is the planet's, and Limit the faintest magnitude the eye
+	// can see at its place in the sky; both are absent below the horizon.
+	Magnitude *float64 `json:"magnitude,omitempty"`
+	Limit     *float64 `json:"limiting_magnitude,omitempty"`
+	Visible   bool     `json:"visible"`
+}
+
+// Sighting is what the sky shows of a body at the chart's moment and
+// place. Risen is false for a body below the horizon, which has no
+// Magnitude or Limit.
+type Sighting struct {
+	Altitude  float64 // apparent, in degrees
+	Risen     bool
+	Magnitude float64
+	Limit     float64 // the faintest magnitude visible at the body's place
+}
+
+// Sight returns the Sighting of body; the CLI takes it from the Swiss
+// Ephemeris, which the output package does not call.
+type Sight func(body int) (Sighting, error)
+
+// visibilityBodies are the planets whose visibility the Swiss Ephemeris
+// can judge; the Sun, the nodes and the minor bodies are left out.
+var visibilityBodies = map[int]bool{
+	ephemeris.Moon: true, ephemeris.Mercury: true, ephemeris.Venus: true, ephemeris.Mars: true,
+	ephemeris.Jupiter: true, ephemeris.Saturn: true, ephemeris.Uranus: true, ephemeris.Neptune: true,
+}
+
+// AddVisibility attaches whether each of r's planets, from the Moon to
+// Neptune, is visible, from its Sighting by sight and its elongation from
+// the Sun, computed by p. A planet is visible when it has risen and is
+// brighter than the limit the sky's brightness sets there.
+func AddVisibility(r *Result, p ephemeris.Provider, sight Sight) error {
+	sun, err := p.CalcPlanet(r.JulianDay, ephemeris.Sun)
+	if err != nil {
+		return fmt.Errorf("error calculating the Sun: %w", err)
+	}
+	r.Visibility = []VisibilityEntry{}
+	for _, pl := range r.Planets {
+		if !visibilityBodies[pl.Body] {
+			continue
+		}
+		pos, err := p.CalcPlanet(r.JulianDay, pl.Body)
+		if err != nil {
+			return fmt.Errorf("error calculating %s: %w", names.Body(pl.Body), err)
+		}
+		s, err := sight(pl.Body)
+		if err != nil {
+			return fmt.Errorf("error judging the visibility of %s: %w", names.Body(pl.Body), err)
+		}
+		e := VisibilityEntry{Body: pl.Body, Name: pl.Name, Altitude: s.Altitude, Elongation: elongation(sun, pos)}
+		if s.Risen {
+			e.Magnitude, e.Limit = &s.Magnitude, &s.Limit
+			e.Visible = s.Magnitude < s.Limit
+		}
+		r.Visibility = append(r.Visibility, e)
+	}
+	return nil
+}
+
+// elongation returns the angle between the Sun and a body, in degrees,
+// from their ecliptic longitudes and latitudes.
+func elongation(sun, body ephemeris.PlanetPos) float64 {
+	rad := math.Pi / 180
+	cos := math.Sin(sun.Latitude*rad)*math.Sin(body.Latitude*rad) +
+		math.Cos(sun.Latitude*rad)*math.Cos(body.Latitude*rad)*math.Cos((body.Longitude-sun.Longitude)*rad)
+	return math.Acos(max(-1, min(1, cos))) / rad
+}
+
+// describe returns whether the planet is visible, and why not, in words.
+func (e VisibilityEntry) describe() string {
+	switch {
+	case e.Magnitude == nil:
+		return "below the horizon"
+	case e.Visible:
+		return fmt.Sprintf("magnitude %.1f, limit %.1f: visible", *e.Magnitude, *e.Limit)
+	}
+	return fmt.Sprintf("magnitude %.1f, limit %.1f: too faint for the sky", *e.Magnitude, *e.Limit)
+}
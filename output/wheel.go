@@ -0,0 +1,105 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/names"
+	"github.com/dcccxiii/astro/wheel"
+)
+
+// bodyLabels are the ASCII labels of the bodies whose names do not begin
+// with a distinctive pair of letters.
+var bodyLabels = map[int]string{
+	ephemeris.MeanNode: "NN", ephemeris.TrueNode: "NN",
+}
+
+// pointLabels are the ASCII labels of the chart points.
+var pointLabels = map[string]string{
+	names.Vertex: "VX", names.EastPoint: "EP", names.CoAscendant: "CA", names.PolarAscendant: "PA",
+	names.Lilith: "LI", names.NorthNode: "NN", names.SouthNode: "SN", names.Fortune: "PF",
+	names.Cupido: "CU", names.Hades: "HA", names.Zeus: "ZE", names.Kronos: "KR", names.Apollon: "AP",
+	names.Admetos: "AD", names.Vulkanus: "VU", names.Poseidon: "PO", names.Transpluto: "TP",
+}
+
+// Wheel returns the chart of r for drawing as a wheel, with glyphs and
+// labels from the names registry.
+func Wheel(r Result) wheel.Chart {
+	c := wheel.Chart{Ascendant: r.Ascendant.Longitude, MC: r.MC.Longitude}
+	for i := 0; i < 12; i++ {
+		c.SignGlyphs[i] = names.Default.SignGlyph(i)
+		c.SignLabels[i] = label(names.Default.Sign(i), 3)
+	}
+	c.Points = wheelPoints(r)
+	for _, p := range r.Points {
+		c.Points = append(c.Points, wheel.Point{
+			Glyph:     names.Default.PointGlyph(p.Key),
+			Label:     pointLabels[p.Key],
+			Longitude: p.Longitude,
+		})
+	}
+	for _, cusp := range r.Cusps {
+		c.Cusps = append(c.Cusps, cusp.Longitude)
+	}
+	return c
+}
+
+// WheelRing returns the planets of r as a ring around another chart.
+func WheelRing(name string, r Result) wheel.Ring {
+	return wheel.Ring{Name: name, Points: wheelPoints(r)}
+}
+
+// EphemerisGraph returns table t for drawing as a graphic ephemeris, with
+// the longitude axis running to modulus, e.g. 360 or 90. The planets of
+// natal, if not nil, are drawn across it.
+func EphemerisGraph(t EphemerisTable, modulus float64, natal *Result) wheel.Graph {
+	g := wheel.Graph{From: t.From, To: t.To, Modulus: modulus}
+	if n := len(t.Rows); n > 0 {
+		g.From, g.To = t.Rows[0].Time, t.Rows[n-1].Time
+	}
+	for i := 0; i < 12; i++ {
+		g.SignGlyphs[i] = names.Default.SignGlyph(i)
+		g.SignLabels[i] = label(names.Default.Sign(i), 3)
+	}
+	for i, body := range t.bodies {
+		s := wheel.Series{Glyph: names.Default.BodyGlyph(body), Label: bodyLabel(body, t.Bodies[i])}
+		for _, row := range t.Rows {
+			s.Longitudes = append(s.Longitudes, row.Positions[i].Longitude)
+		}
+		g.Series = append(g.Series, s)
+	}
+	if natal != nil {
+		g.Natal = wheelPoints(*natal)
+	}
+	return g
+}
+
+func wheelPoints(r Result) []wheel.Point {
+	var points []wheel.Point
+	for _, p := range r.Planets {
+		points = append(points, wheel.Point{
+			Glyph:      names.Default.BodyGlyph(p.Body),
+			Label:      bodyLabel(p.Body, p.Name),
+			Longitude:  p.Longitude,
+			Retrograde: p.Speed < 0,
+		})
+	}
+	return points
+}
+
+// bodyLabel returns the ASCII label of a body called name.
+func bodyLabel(body int, name string) string {
+	if l, ok := bodyLabels[body]; ok {
+		return l
+	}
+	return label(name, 2)
+}
+
+// label returns the first n letters of name in capitals.
+func label(name string, n int) string {
+	r := []rune(strings.ToUpper(strings.TrimSpace(name)))
+	if len(r) > n {
+		r = r[:n]
+	}
+	return string(r)
+}
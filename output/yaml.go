@@ -0,0 +1,161 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PrintYAML writes the chart as YAML to stdout, with the same structure
+// and keys as PrintJSON.
+func PrintYAML(r Result) error { return WriteYAML(os.Stdout, r) }
+
+// WriteYAML writes the YAML PrintYAML prints to w.
+func WriteYAML(w io.Writer, r Result) error {
+	data, err := toYAML(wire(r))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// toYAML renders v as a YAML document by way of its JSON encoding, so the
+// json struct tags, omitempty included, govern both formats and keys keep
+// their order.
+func toYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling YAML: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	n, err := readNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling YAML: %w", err)
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAML(&b, n, 0)
+	return []byte(b.String()), nil
+}
+
+// yamlNode is a decoded JSON value: an object with ordered keys, an array,
+// or a scalar already rendered as YAML.
+type yamlNode struct {
+	keys   []string
+	values []yamlNode // the object's values or the array's elements
+	object bool
+	array  bool
+	scalar string
+}
+
+func readNode(dec *json.Decoder) (yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return yamlNode{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := yamlNode{object: t == '{', array: t == '['}
+		for dec.More() {
+			if n.object {
+				k, err := dec.Token()
+				if err != nil {
+					return yamlNode{}, err
+				}
+				n.keys = append(n.keys, yamlString(k.(string)))
+			}
+			v, err := readNode(dec)
+			if err != nil {
+				return yamlNode{}, err
+			}
+			n.values = append(n.values, v)
+		}
+		if _, err := dec.Token(); err != nil { // the closing delimiter
+			return yamlNode{}, err
+		}
+		return n, nil
+	case string:
+		return yamlNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return yamlNode{scalar: t.String()}, nil
+	case bool:
+		return yamlNode{scalar: fmt.Sprint(t)}, nil
+	case nil:
+		return yamlNode{scalar: "null"}, nil
+	}
+	return yamlNode{}, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// empty reports whether n is an empty object or array, written inline.
+func (n yamlNode) empty() bool { return (n.object || n.array) && len(n.values) == 0 }
+
+func (n yamlNode) inline() string {
+	switch {
+	case n.object:
+		return "{}"
+	case n.array:
+		return "[]"
+	}
+	return n.scalar
+}
+
+// writeYAML writes n in block style, indented by indent levels.
+func writeYAML(w io.StringWriter, n yamlNode, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch {
+	case n.empty() || (!n.object && !n.array):
+		w.WriteString(pad + n.inline() + "\n")
+	case n.object:
+		for i, k := range n.keys {
+			v := n.values[i]
+			if v.empty() || (!v.object && !v.array) {
+				w.WriteString(pad + k + ": " + v.inline() + "\n")
+				continue
+			}
+			w.WriteString(pad + k + ":\n")
+			writeYAML(w, v, indent+1)
+		}
+	case n.array:
+		for _, v := range n.values {
+			if v.empty() || (!v.object && !v.array) {
+				w.WriteString(pad + "- " + v.inline() + "\n")
+				continue
+			}
+			// The first line of a nested block follows the dash.
+			var sub strings.Builder
+			writeYAML(&sub, v, indent+1)
+			w.WriteString(pad + "- " + strings.TrimPrefix(sub.String(), pad+"  "))
+		}
+	}
+}
+
+// plainString matches strings that YAML reads back unchanged without
+// quotes.
+var plainString = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./()+-]*$`)
+
+// yamlString returns s as a YAML scalar, double-quoted, with JSON's
+// escapes, unless it is safe to leave plain.
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return quote(s)
+	}
+	if plainString.MatchString(s) && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	return quote(s)
+}
+
+func quote(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // a string always encodes
+	return strings.TrimSuffix(b.String(), "\n")
+}
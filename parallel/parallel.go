@@ -0,0 +1,113 @@
+// Package parallel spreads independent computations, such as the charts of
+// a batch or the rows of an ephemeris table, over a pool of goroutines and
+// hands their results back in order.
+//
+// The Swiss Ephemeris is not reentrant, and the swisseph package holds a
+// lock around every call into it, so workers may share any provider: their
+// calls into the library take turns, and what runs in parallel is the Go
+// work around them, from houses and aspects to rendering.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ahead bounds how far Ordered runs ahead of its output: the indexes handed
+// to workers but not yet emitted are at most ahead times the workers.
+const ahead = 2
+
+// Workers returns n if it is positive, else the number of CPUs.
+func Workers(n int) int {
+	if n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// Ordered calls f for each index from 0 to n-1 on workers goroutines (see
+// Workers), and passes each result to emit, on the calling goroutine, in
+// the order of the indexes. It stops at the first error of f or emit and
+// returns it.
+//
+// Workers run at most ahead times their number of indexes ahead of the next
+// to emit, so a slow index holds back the rest, rather than leaving their
+// results to pile up waiting for it.
+func Ordered[T any](n, workers int, f func(i int) (T, error), emit func(i int, v T) error) error {
+	type result struct {
+		i   int
+		v   T
+		err error
+	}
+	w := min(Workers(workers), max(n, 1))
+	jobs, results, stop := make(chan int), make(chan result), make(chan struct{})
+	// An index takes a slot of window before it is handed out, and gives
+	// it back once emitted.
+	window := make(chan struct{}, ahead*w)
+	defer close(stop)
+	go func() {
+		defer close(jobs)
+		for i := range n {
+			select {
+			case window <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for range w {
+		wg.Go(func() {
+			for i := range jobs {
+				v, err := f(i)
+				select {
+				case results <- result{i, v, err}:
+				case <-stop:
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results arrive in any order; hold them until their turn.
+	pending := map[int]T{}
+	next := 0
+	for res := range results {
+		if res.err != nil {
+			return res.err
+		}
+		pending[res.i] = res.v
+		for v, ok := pending[next]; ok; v, ok = pending[next] {
+			delete(pending, next)
+			if err := emit(next, v); err != nil {
+				return err
+			}
+			<-window
+			next++
+		}
+	}
+	return nil
+}
+
+// Map returns the results of f for each index from 0 to n-1, computed on
+// workers goroutines as Ordered computes them.
+func Map[T any](n, workers int, f func(i int) (T, error)) ([]T, error) {
+	out := make([]T, 0, n)
+	err := Ordered(n, workers, f, func(_ int, v T) error {
+		out = append(out, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package parallel
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrdered(t *testing.T) {
+	var got []int
+	err := Ordered(50, 8, func(i int) (int, error) {
+		// Finish out of order.
+		time.Sleep(time.Duration(rand.IntN(100)) * time.Microsecond)
+		return i * i, nil
+	}, func(i, v int) error {
+		if v != i*i {
+			t.Errorf("emit(%d, %d), want %d", i, v, i*i)
+		}
+		got = append(got, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Ordered: %v", err)
+	}
+	if want := 50; len(got) != want || !slices.IsSorted(got) {
+		t.Errorf("emitted %v, want 0 to %d in order", got, want-1)
+	}
+}
+
+func TestOrderedAhead(t *testing.T) {
+	// Index 0 is slow; the others may not run more than ahead times the
+	// workers ahead of it.
+	const workers = 3
+	var emitted atomic.Int64
+	err := Ordered(100, workers, func(i int) (int, error) {
+		if i == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if e := int(emitted.Load()); i >= e+ahead*workers {
+			t.Errorf("f(%d) ran with only %d emitted", i, e)
+		}
+		return i, nil
+	}, func(i, v int) error {
+		emitted.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Ordered: %v", err)
+	}
+	if emitted.Load() != 100 {
+		t.Errorf("emitted %d, want 100", emitted.Load())
+	}
+}
+
+func TestOrderedError(t *testing.T) {
+	bad := errors.New("bad")
+	var emitted []int
+	err := Ordered(100, 4, func(i int) (int, error) {
+		if i == 10 {
+			return 0, bad
+		}
+		return i, nil
+	}, func(i, v int) error {
+		emitted = append(emitted, i)
+		return nil
+	})
+	if !errors.Is(err, bad) {
+		t.Fatalf("Ordered error %v, want %v", err, bad)
+	}
+	if slices.Contains(emitted, 10) || len(emitted) > 10 {
+		t.Errorf("emitted %v after the error", emitted)
+	}
+
+	stop := errors.New("stop")
+	err = Ordered(100, 4, func(i int) (int, error) { return i, nil }, func(i, v int) error {
+		if i == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Ordered error %v, want the error of emit", err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	got, err := Map(5, 0, func(i int) (string, error) { return string(rune('a' + i)), nil })
+	if err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !slices.Equal(got, want) {
+		t.Errorf("Map = %v, want %v", got, want)
+	}
+	if got, err := Map(0, 1, func(i int) (int, error) { return i, nil }); err != nil || len(got) != 0 {
+		t.Errorf("Map of nothing = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,267 @@
+// Package patterns recognises aspect configurations among the planets of a
+// chart: grand trines, T-squares, grand crosses, yods, kites, mystic
+// rectangles and stelliums.
+package patterns
+
+import (
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/zodiac"
+)
+
+// Kind is a type of configuration.
+type Kind int
+
+const (
+	GrandTrine Kind = iota
+	TSquare
+	GrandCross
+	Yod
+	Kite
+	MysticRectangle
+	Stellium
+)
+
+var kindNames = [...]string{"grand trine", "T-square", "grand cross", "yod", "kite", "mystic rectangle", "stellium"}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// Point is a named position on the ecliptic.
+type Point struct {
+	Name      string
+	Longitude float64
+}
+
+// Pattern is one configuration found among the points.
+type Pattern struct {
+	Kind    Kind
+	Members []string // in the order the points were given
+	// Element and Modality are those shared by every member's sign, or
+	// empty if the signs differ.
+	Element  string
+	Modality string
+	// Apex is the focal planet of a T-square or yod: the one squaring, or
+	// quincunx to, both of the others.
+	Apex string
+	// Sign is the index in zodiac.Signs of a stellium's sign; it is 0 for
+	// the other kinds.
+	Sign int
+}
+
+// StelliumSize is the number of planets in one sign that make a stellium.
+const StelliumSize = 3
+
+// aspectSet are the aspects the configurations are built from, with the
+// orbs of aspects.Major.
+var aspectSet = append(append([]aspects.Aspect(nil), aspects.Major...), aspects.Quincunx)
+
+// Indices into aspectSet.
+const (
+	conjunction = iota
+	sextile
+	square
+	trine
+	opposition
+	quincunx
+)
+
+// signature counts the aspects among a group of points, indexed as
+// aspectSet. Any unaspected pair rules the group out.
+type signature [6]int
+
+// shapes gives the aspects among the members of each configuration of
+// three or four planets; the counts fix the geometry.
+var shapes = []struct {
+	kind Kind
+	size int
+	sig  signature
+}{
+	{GrandTrine, 3, signature{trine: 3}},
+	{TSquare, 3, signature{square: 2, opposition: 1}},
+	{Yod, 3, signature{sextile: 1, quincunx: 2}},
+	{GrandCross, 4, signature{square: 4, opposition: 2}},
+	{Kite, 4, signature{sextile: 2, trine: 3, opposition: 1}},
+	{MysticRectangle, 4, signature{sextile: 2, trine: 2, opposition: 2}},
+}
+
+// Find returns the configurations among points. A T-square that is part of
+// a grand cross, and a grand trine that is part of a kite, are reported
+// only as the larger figure. Patterns are ordered by kind, then by the
+// position of their first member in points.
+func Find(points []Point) []Pattern {
+	n := len(points)
+	asp := make([][]int, n)
+	for i := range asp {
+		asp[i] = make([]int, n)
+		for j := range asp[i] {
+			asp[i][j] = -1
+			if i == j {
+				continue
+			}
+			if a, _, ok := aspects.Between(points[i].Longitude, points[j].Longitude, aspectSet); ok {
+				asp[i][j] = indexOf(a)
+			}
+		}
+	}
+
+	var found [][]int
+	var kinds []Kind
+	for _, sh := range shapes {
+		combinations(n, sh.size, func(idx []int) {
+			var sig signature
+			for a := 0; a < len(idx); a++ {
+				for b := a + 1; b < len(idx); b++ {
+					k := asp[idx[a]][idx[b]]
+					if k < 0 {
+						return
+					}
+					sig[k]++
+				}
+			}
+			if sig == sh.sig {
+				found = append(found, append([]int(nil), idx...))
+				kinds = append(kinds, sh.kind)
+			}
+		})
+	}
+
+	var out []Pattern
+	for i, idx := range found {
+		if subsumed(kinds[i], idx, kinds, found) {
+			continue
+		}
+		p := Pattern{Kind: kinds[i]}
+		for _, k := range idx {
+			p.Members = append(p.Members, points[k].Name)
+		}
+		p.Element, p.Modality = shared(points, idx)
+		switch p.Kind {
+		case TSquare:
+			p.Apex = points[apex(idx, asp, square)].Name
+		case Yod:
+			p.Apex = points[apex(idx, asp, quincunx)].Name
+		}
+		out = append(out, p)
+	}
+	out = append(out, stelliums(points)...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
+
+// subsumed reports whether the group idx of kind k lies within a larger
+// figure that contains it: a T-square in a grand cross or a grand trine in
+// a kite.
+func subsumed(k Kind, idx []int, kinds []Kind, found [][]int) bool {
+	var within Kind
+	switch k {
+	case TSquare:
+		within = GrandCross
+	case GrandTrine:
+		within = Kite
+	default:
+		return false
+	}
+	for i, other := range found {
+		if kinds[i] == within && subset(idx, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// apex returns the member of idx that forms aspect with each of the
+// others: the focus of a T-square or yod.
+func apex(idx []int, asp [][]int, aspect int) int {
+	for _, a := range idx {
+		count := 0
+		for _, b := range idx {
+			if a != b && asp[a][b] == aspect {
+				count++
+			}
+		}
+		if count == len(idx)-1 {
+			return a
+		}
+	}
+	return idx[0] // unreachable for a matching signature
+}
+
+// stelliums finds the signs holding StelliumSize or more points.
+func stelliums(points []Point) []Pattern {
+	var bySign [12][]string
+	for _, p := range points {
+		i := min(int(angle.Norm360(p.Longitude)/30), 11)
+		bySign[i] = append(bySign[i], p.Name)
+	}
+	var out []Pattern
+	for i, members := range bySign {
+		if len(members) < StelliumSize {
+			continue
+		}
+		lon := float64(i) * 30
+		out = append(out, Pattern{
+			Kind: Stellium, Members: members, Sign: i,
+			Element: zodiac.Element(lon), Modality: zodiac.Modality(lon),
+		})
+	}
+	return out
+}
+
+// shared returns the element and modality common to the signs of the
+// points in idx, each empty if they differ.
+func shared(points []Point, idx []int) (element, modality string) {
+	element, modality = zodiac.Element(points[idx[0]].Longitude), zodiac.Modality(points[idx[0]].Longitude)
+	for _, k := range idx[1:] {
+		if zodiac.Element(points[k].Longitude) != element {
+			element = ""
+		}
+		if zodiac.Modality(points[k].Longitude) != modality {
+			modality = ""
+		}
+	}
+	return element, modality
+}
+
+// combinations calls f with every increasing sequence of k indices below n.
+func combinations(n, k int, f func([]int)) {
+	idx := make([]int, k)
+	var rec func(start, depth int)
+	rec = func(start, depth int) {
+		if depth == k {
+			f(idx)
+			return
+		}
+		for i := start; i < n; i++ {
+			idx[depth] = i
+			rec(i+1, depth+1)
+		}
+	}
+	rec(0, 0)
+}
+
+func subset(a, b []int) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOf(a aspects.Aspect) int {
+	for i, x := range aspectSet {
+		if x.Name == a.Name {
+			return i
+		}
+	}
+	return -1
+}
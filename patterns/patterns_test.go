@@ -0,0 +1,62 @@
+package patterns_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dcccxiii/astro/patterns"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []patterns.Point
+		want   []patterns.Pattern
+	}{
+		{
+			name:   "grand trine",
+			points: []patterns.Point{{"Sun", 2}, {"Moon", 125}, {"Mars", 242}},
+			want:   []patterns.Pattern{{Kind: patterns.GrandTrine, Members: []string{"Sun", "Moon", "Mars"}, Element: "fire"}},
+		},
+		{
+			name:   "kite absorbs its grand trine",
+			points: []patterns.Point{{"Sun", 2}, {"Moon", 125}, {"Mars", 242}, {"Venus", 181}},
+			want:   []patterns.Pattern{{Kind: patterns.Kite, Members: []string{"Sun", "Moon", "Mars", "Venus"}}},
+		},
+		{
+			name:   "T-square",
+			points: []patterns.Point{{"Sun", 10}, {"Moon", 190}, {"Saturn", 95}},
+			want:   []patterns.Pattern{{Kind: patterns.TSquare, Members: []string{"Sun", "Moon", "Saturn"}, Modality: "cardinal", Apex: "Saturn"}},
+		},
+		{
+			name:   "grand cross absorbs its T-squares",
+			points: []patterns.Point{{"Sun", 10}, {"Moon", 190}, {"Saturn", 95}, {"Mars", 275}},
+			want:   []patterns.Pattern{{Kind: patterns.GrandCross, Members: []string{"Sun", "Moon", "Saturn", "Mars"}, Modality: "cardinal"}},
+		},
+		{
+			name:   "yod",
+			points: []patterns.Point{{"Venus", 10}, {"Jupiter", 70}, {"Pluto", 220}},
+			want:   []patterns.Pattern{{Kind: patterns.Yod, Members: []string{"Venus", "Jupiter", "Pluto"}, Apex: "Pluto"}},
+		},
+		{
+			name:   "mystic rectangle",
+			points: []patterns.Point{{"Sun", 0}, {"Moon", 60}, {"Mars", 180}, {"Venus", 240}},
+			want:   []patterns.Pattern{{Kind: patterns.MysticRectangle, Members: []string{"Sun", "Moon", "Mars", "Venus"}}},
+		},
+		{
+			name:   "stellium",
+			points: []patterns.Point{{"Sun", 301}, {"Mercury", 310}, {"Venus", 325}, {"Mars", 100}},
+			want: []patterns.Pattern{{Kind: patterns.Stellium, Members: []string{"Sun", "Mercury", "Venus"},
+				Element: "air", Modality: "fixed", Sign: 10}},
+		},
+		{
+			name:   "none",
+			points: []patterns.Point{{"Sun", 0}, {"Moon", 45}},
+		},
+	}
+	for _, tt := range tests {
+		if got := patterns.Find(tt.points); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Find = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
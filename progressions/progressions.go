@@ -0,0 +1,13 @@
+// Package progressions computes secondary progressions, in which each day
+// after birth stands for the corresponding year of life.
+package progressions
+
+// TropicalYear is the length of the year, in days, that one day of
+// progression stands for.
+const TropicalYear = 365.24219
+
+// Secondary returns the Julian Day whose sky is the secondary progressed
+// chart, for a birth at natal, at Julian Day jd.
+func Secondary(natal, jd float64) float64 {
+	return natal + (jd-natal)/TropicalYear
+}
@@ -0,0 +1,18 @@
+package progressions_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/progressions"
+)
+
+func TestSecondary(t *testing.T) {
+	natal := 2447000.5
+	if got := progressions.Secondary(natal, natal+30*progressions.TropicalYear); math.Abs(got-(natal+30)) > 1e-9 {
+		t.Errorf("30 years on: progressed JD %v, want %v", got, natal+30)
+	}
+	if got := progressions.Secondary(natal, natal); got != natal {
+		t.Errorf("at birth: progressed JD %v, want %v", got, natal)
+	}
+}
@@ -0,0 +1,287 @@
+// Package rectify helps find an unknown birth time: it sweeps the candidate
+// times of a birth date, reports where the angles, the planets' houses and
+// the house rulers change, and ranks the candidates by how well transits
+// and solar arc directions to their angles time the dated events of the
+// native's life.
+package rectify
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/dignity"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/progressions"
+)
+
+// Chart is the chart of one candidate birth time.
+type Chart struct {
+	JD      float64
+	Planets map[int]ephemeris.PlanetPos
+	Houses  ephemeris.HouseResult
+}
+
+// House returns the house of body in c.
+func (c Chart) House(body int) int {
+	return c.Houses.HouseOf(c.Planets[body].Longitude)
+}
+
+// Ruler returns the traditional ruler of the sign on the cusp of house, 1
+// to 12.
+func (c Chart) Ruler(house int) int {
+	return dignity.RulerOf(c.Houses.Cusps[house])
+}
+
+// Sweep casts the chart every step days from from to to, at the given
+// place and house system, with the positions of bodies.
+func Sweep(p ephemeris.Provider, bodies []int, from, to, step, lat, lon float64, hsys byte) ([]Chart, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %v", step)
+	}
+	var charts []Chart
+	for i := 0; ; i++ {
+		jd := from + float64(i)*step
+		if jd > to {
+			break
+		}
+		c := Chart{JD: jd, Planets: make(map[int]ephemeris.PlanetPos, len(bodies))}
+		for _, body := range bodies {
+			pos, err := p.CalcPlanet(jd, body)
+			if err != nil {
+				return nil, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+			}
+			c.Planets[body] = pos
+		}
+		h, err := p.CalcHouses(jd, lat, lon, hsys)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating houses: %w", err)
+		}
+		c.Houses = h
+		charts = append(charts, c)
+	}
+	return charts, nil
+}
+
+// ChangeKind distinguishes the changes between one span of candidates and
+// the next.
+type ChangeKind int
+
+const (
+	AscendantSign ChangeKind = iota // the Ascendant enters a sign
+	MCSign                          // the MC enters a sign
+	PlanetHouse                     // a planet moves to another house
+	HouseRuler                      // another planet rules a house
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case AscendantSign:
+		return "ascendant sign"
+	case MCSign:
+		return "mc sign"
+	case PlanetHouse:
+		return "planet house"
+	case HouseRuler:
+		return "house ruler"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is one difference between a span and the one before it.
+type Change struct {
+	Kind  ChangeKind
+	Sign  int // the sign the Ascendant or MC enters, 0 for Aries
+	Body  int // the planet that moves, or the new ruler
+	House int // the house the planet moves to, or whose ruler changes
+}
+
+// Span is a run of consecutive candidates whose charts agree on the signs
+// of the angles, the planets' houses and the house rulers.
+type Span struct {
+	First, Last Chart
+	// Changes are how the span differs from the one before; the first
+	// span has none.
+	Changes []Change
+}
+
+// Spans groups charts, in time order as Sweep returns them, into spans.
+// The moment of each change is accurate to the step of the sweep.
+func Spans(charts []Chart, bodies []int) []Span {
+	var spans []Span
+	for _, c := range charts {
+		var changes []Change
+		if n := len(spans); n > 0 {
+			if changes = compare(spans[n-1].Last, c, bodies); len(changes) == 0 {
+				spans[n-1].Last = c
+				continue
+			}
+		}
+		spans = append(spans, Span{First: c, Last: c, Changes: changes})
+	}
+	return spans
+}
+
+// compare returns the changes from chart a to chart b.
+func compare(a, b Chart, bodies []int) []Change {
+	var changes []Change
+	if s := dignity.Sign(b.Houses.Ascendant); s != dignity.Sign(a.Houses.Ascendant) {
+		changes = append(changes, Change{Kind: AscendantSign, Sign: s})
+	}
+	if s := dignity.Sign(b.Houses.MC); s != dignity.Sign(a.Houses.MC) {
+		changes = append(changes, Change{Kind: MCSign, Sign: s})
+	}
+	for _, body := range bodies {
+		if h := b.House(body); h != a.House(body) {
+			changes = append(changes, Change{Kind: PlanetHouse, Body: body, House: h})
+		}
+	}
+	for h := 1; h <= 12; h++ {
+		if r := b.Ruler(h); r != a.Ruler(h) {
+			changes = append(changes, Change{Kind: HouseRuler, Body: r, House: h})
+		}
+	}
+	return changes
+}
+
+// Event is a dated event of the native's life, such as a marriage or a
+// move, that candidates are tested against.
+type Event struct {
+	JD   float64
+	Name string
+}
+
+// Angle names the chart angles that events are timed by.
+type Angle int
+
+const (
+	Ascendant Angle = iota
+	MC
+)
+
+func (a Angle) String() string {
+	if a == MC {
+		return "MC"
+	}
+	return "Ascendant"
+}
+
+// Technique is how a hit times an event.
+type Technique int
+
+const (
+	Transit       Technique = iota // a transiting planet aspects a natal angle
+	DirectedAngle                  // a solar arc directed angle aspects a natal planet
+	DirectedBody                   // a solar arc directed planet aspects a natal angle
+)
+
+func (t Technique) String() string {
+	switch t {
+	case Transit:
+		return "transit"
+	case DirectedAngle:
+		return "directed angle"
+	case DirectedBody:
+		return "directed planet"
+	}
+	return fmt.Sprintf("Technique(%d)", int(t))
+}
+
+// Hit is one contact that times an event in a candidate's chart.
+type Hit struct {
+	Event     int // index into the events
+	Technique Technique
+	Body      int // the transiting, directed or natal planet
+	Angle     Angle
+	Aspect    aspects.Aspect
+	Orb       float64 // distance from exact, in degrees, non-negative
+}
+
+// Candidate is a chart with the hits that time the events and its score:
+// for each hit, one less the fraction of the orb it is from exact, so an
+// exact hit scores 1.
+type Candidate struct {
+	Chart
+	Hits  []Hit
+	Score float64
+}
+
+// Aspects are the aspects hits are looked for in: the conjunction and the
+// hard aspects, which rectification leans on.
+var Aspects = []aspects.Aspect{aspects.Major[0], aspects.Major[2], aspects.Major[4]}
+
+// Transiting are the planets whose transits to the angles are looked for:
+// the slow ones, whose contacts last long enough to mark an event.
+var Transiting = []int{ephemeris.Jupiter, ephemeris.Saturn, ephemeris.Uranus, ephemeris.Neptune, ephemeris.Pluto}
+
+// Rank scores each chart against events, allowing orb degrees either way,
+// and returns the candidates best first, earlier first among equals. The
+// directed planets are the bodies of the charts; the solar arc is the
+// distance the secondary progressed Sun has moved.
+func Rank(p ephemeris.Provider, charts []Chart, events []Event, orb float64) ([]Candidate, error) {
+	as := aspects.WithOrb(Aspects, orb)
+	transits := make([]map[int]float64, len(events))
+	for i, e := range events {
+		transits[i] = make(map[int]float64, len(Transiting))
+		for _, body := range Transiting {
+			pos, err := p.CalcPlanet(e.JD, body)
+			if err != nil {
+				return nil, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+			}
+			transits[i][body] = pos.Longitude
+		}
+	}
+
+	candidates := make([]Candidate, len(charts))
+	for ci, c := range charts {
+		cand := Candidate{Chart: c}
+		angles := [2]float64{c.Houses.Ascendant, c.Houses.MC}
+		hit := func(event int, t Technique, body int, angle Angle, a, b float64) {
+			if asp, d, ok := aspects.Between(a, b, as); ok {
+				cand.Hits = append(cand.Hits, Hit{Event: event, Technique: t, Body: body, Angle: angle, Aspect: asp, Orb: d})
+				cand.Score += 1 - d/orb
+			}
+		}
+		sun, ok := c.Planets[ephemeris.Sun]
+		if !ok {
+			var err error
+			if sun, err = p.CalcPlanet(c.JD, ephemeris.Sun); err != nil {
+				return nil, fmt.Errorf("error calculating Sun: %w", err)
+			}
+		}
+		for i, e := range events {
+			for _, body := range Transiting {
+				for a, lon := range angles {
+					hit(i, Transit, body, Angle(a), transits[i][body], lon)
+				}
+			}
+			progressed, err := p.CalcPlanet(progressions.Secondary(c.JD, e.JD), ephemeris.Sun)
+			if err != nil {
+				return nil, fmt.Errorf("error calculating Sun: %w", err)
+			}
+			arc := angle.Norm360(progressed.Longitude - sun.Longitude)
+			for _, body := range sortedBodies(c.Planets) {
+				natal := c.Planets[body].Longitude
+				for a, lon := range angles {
+					hit(i, DirectedAngle, body, Angle(a), lon+arc, natal)
+					hit(i, DirectedBody, body, Angle(a), natal+arc, lon)
+				}
+			}
+		}
+		candidates[ci] = cand
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates, nil
+}
+
+// sortedBodies returns the bodies of planets in order, for a stable order
+// of hits.
+func sortedBodies(planets map[int]ephemeris.PlanetPos) []int {
+	bodies := make([]int, 0, len(planets))
+	for body := range planets {
+		bodies = append(bodies, body)
+	}
+	sort.Ints(bodies)
+	return bodies
+}
@@ -0,0 +1,114 @@
+package rectify_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/rectify"
+)
+
+// turning is a mock sky whose equal houses turn once a day, the Ascendant
+// at 11° at the epoch, with the MC a quadrant behind it.
+type turning struct{ *ephemeris.MockProvider }
+
+func (t turning) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	asc := math.Mod(11+360*(jd-t.Epoch), 360)
+	h := ephemeris.HouseResult{Ascendant: asc, MC: math.Mod(asc+270, 360)}
+	for i := 1; i <= 12; i++ {
+		h.Cusps[i] = math.Mod(asc+30*float64(i-1), 360)
+	}
+	return h, nil
+}
+
+func newTurning() turning {
+	return turning{&ephemeris.MockProvider{
+		Epoch: 2451545,
+		Planets: map[int]ephemeris.PlanetPos{
+			ephemeris.Sun:     {Longitude: 0},
+			ephemeris.Jupiter: {Longitude: 160},
+			ephemeris.Saturn:  {Longitude: 41},
+			ephemeris.Uranus:  {Longitude: 163},
+			ephemeris.Neptune: {Longitude: 166},
+			ephemeris.Pluto:   {Longitude: 169},
+		},
+	}}
+}
+
+const minute = 1.0 / 1440
+
+func TestSpans(t *testing.T) {
+	p := newTurning()
+	bodies := []int{ephemeris.Sun}
+	// The Ascendant runs from 11° to 41° Aries, entering Taurus at 80
+	// minutes, when the Sun moves from the 12th house to the 11th.
+	charts, err := rectify.Sweep(p, bodies, p.Epoch, p.Epoch+120*minute, 10*minute, 0, 0, 'E')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(charts) != 13 {
+		t.Fatalf("Sweep gave %d charts, want 13", len(charts))
+	}
+	spans := rectify.Spans(charts, bodies)
+	if len(spans) != 2 {
+		t.Fatalf("Spans = %+v, want 2", spans)
+	}
+	if got := (spans[0].Last.JD - p.Epoch) / minute; math.Abs(got-70) > 1e-6 {
+		t.Errorf("first span ends at %g minutes, want 70", got)
+	}
+	if len(spans[0].Changes) != 0 || spans[0].First.House(ephemeris.Sun) != 12 {
+		t.Errorf("first span = %+v", spans[0])
+	}
+	want := map[rectify.Change]bool{
+		{Kind: rectify.AscendantSign, Sign: 1}:                        true,
+		{Kind: rectify.MCSign, Sign: 10}:                              true,
+		{Kind: rectify.PlanetHouse, Body: ephemeris.Sun, House: 11}:   true,
+		{Kind: rectify.HouseRuler, Body: ephemeris.Venus, House: 1}:   true,
+		{Kind: rectify.HouseRuler, Body: ephemeris.Saturn, House: 10}: false, // Capricorn to Aquarius
+		{Kind: rectify.HouseRuler, Body: ephemeris.Mars, House: 12}:   true,  // Pisces to Aries
+	}
+	got := map[rectify.Change]bool{}
+	for _, c := range spans[1].Changes {
+		got[c] = true
+	}
+	for c, in := range want {
+		if got[c] != in {
+			t.Errorf("change %+v present = %v, want %v; changes %+v", c, got[c], in, spans[1].Changes)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	p := newTurning()
+	bodies := []int{ephemeris.Sun}
+	charts, err := rectify.Sweep(p, bodies, p.Epoch, p.Epoch+120*minute, 10*minute, 0, 0, 'E')
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the last candidate, rising at 41° with Saturn, times the event:
+	// Saturn conjunct the Ascendant, and square the MC a quadrant behind.
+	events := []rectify.Event{{JD: p.Epoch + 10000, Name: "married"}}
+	ranked, err := rectify.Rank(p, charts, events, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	best := ranked[0]
+	if got := (best.JD - p.Epoch) / minute; math.Abs(got-120) > 1e-6 {
+		t.Errorf("best candidate at %g minutes, want 120", got)
+	}
+	if len(best.Hits) != 2 || math.Abs(best.Score-2) > 1e-6 {
+		t.Fatalf("best = %+v, want two exact hits", best)
+	}
+	for i, want := range []struct {
+		angle  rectify.Angle
+		aspect string
+	}{{rectify.Ascendant, "conjunction"}, {rectify.MC, "square"}} {
+		h := best.Hits[i]
+		if h.Technique != rectify.Transit || h.Body != ephemeris.Saturn || h.Angle != want.angle || h.Aspect.Name != want.aspect {
+			t.Errorf("hit %d = %+v, want transiting Saturn %s %s", i, h, want.aspect, want.angle)
+		}
+	}
+	if ranked[1].Score != 0 {
+		t.Errorf("runner-up = %+v, want no hits", ranked[1])
+	}
+}
@@ -0,0 +1,142 @@
+// Package returns finds the moments when a transiting planet comes back to
+// the longitude it held at birth, the basis of return charts.
+package returns
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// precision is the longitude tolerance in degrees at which a crossing is
+// considered exact (well under a second of time for the Sun).
+const precision = 1e-7
+
+// maxIter bounds the Newton iteration; it normally converges in 3-4 steps.
+const maxIter = 50
+
+// meanSolarSpeed is the Sun's mean daily motion in degrees.
+const meanSolarSpeed = 360.0 / 365.2422
+
+// Solar returns the first Julian Day (UT) at or after from when the Sun's
+// longitude equals natalLon.
+func Solar(p ephemeris.Provider, natalLon, from float64) (float64, error) {
+	pos, err := p.CalcPlanet(from, ephemeris.Sun)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating Sun: %w", err)
+	}
+	jd := from + angle.Norm360(natalLon-pos.Longitude)/meanSolarSpeed
+	for i := 0; i < maxIter; i++ {
+		pos, err := p.CalcPlanet(jd, ephemeris.Sun)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating Sun: %w", err)
+		}
+		diff := angle.Diff(natalLon, pos.Longitude)
+		if math.Abs(diff) < precision {
+			return jd, nil
+		}
+		if pos.SpeedLon <= 0 {
+			return 0, fmt.Errorf("solar return search: Sun speed %.4f°/day is not positive", pos.SpeedLon)
+		}
+		jd += diff / pos.SpeedLon
+	}
+	return 0, fmt.Errorf("solar return search did not converge near JD %.2f", from)
+}
+
+// motion holds the mean geocentric daily motion in longitude and the
+// synodic period (days between successive retrograde loops) of a body.
+type motion struct {
+	speed   float64
+	synodic float64
+}
+
+// motions covers the bodies Find supports. The inner planets share the
+// Sun's mean motion; the Moon never retrogrades, so its synodic period only
+// sets the window size.
+var motions = map[int]motion{
+	ephemeris.Moon:    {13.1764, 29.53},
+	ephemeris.Mercury: {0.9856, 115.88},
+	ephemeris.Venus:   {0.9856, 583.92},
+	ephemeris.Mars:    {0.5240, 779.94},
+	ephemeris.Jupiter: {0.08309, 398.88},
+	ephemeris.Saturn:  {0.03346, 378.09},
+	ephemeris.Uranus:  {0.01173, 369.66},
+	ephemeris.Neptune: {0.005981, 367.49},
+	ephemeris.Pluto:   {0.003968, 366.73},
+}
+
+// Supported reports whether Find can search for returns of body.
+func Supported(body int) bool {
+	_, ok := motions[body]
+	return ok || body == ephemeris.Sun
+}
+
+// Find returns the exact passes of body over natalLon making up its first
+// return at or after from. Planets that retrograde near the natal degree
+// pass it three times in one return; all passes are returned, in order.
+//
+// Rather than stepping through the whole orbital period, the search jumps to
+// the time mean motion predicts and scans a window of two synodic periods
+// either side, which always contains the true crossing.
+func Find(p ephemeris.Provider, body int, natalLon, from float64) ([]float64, error) {
+	if body == ephemeris.Sun {
+		jd, err := Solar(p, natalLon, from)
+		if err != nil {
+			return nil, err
+		}
+		return []float64{jd}, nil
+	}
+	m, ok := motions[body]
+	if !ok {
+		return nil, fmt.Errorf("returns are not supported for %s", p.PlanetName(body))
+	}
+
+	pos, err := p.CalcPlanet(from, body)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+	}
+	est := from + angle.Norm360(natalLon-pos.Longitude)/m.speed
+	window := 2 * m.synodic
+	step := m.synodic / 24
+	// A return's passes all fall within one synodic period of the first,
+	// and within half an orbit, so the Moon's next return is not mistaken
+	// for a second pass.
+	span := math.Min(m.synodic, 180/m.speed)
+
+	// Scan from the later of from and the window start; if the estimate was
+	// off by more than the window, keep scanning forward (bounded by two
+	// full orbits).
+	start := math.Max(from, est-window)
+	limit := est + 2*360/m.speed
+	offset := func(jd float64) (float64, error) {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		return angle.Diff(pos.Longitude, natalLon), nil
+	}
+	var passes []float64
+	err = search.Scan(offset, start, limit, step, func(t0, o0, t1, o1 float64) error {
+		if search.Crossed(o0, o1) {
+			jd, err := search.Root(offset, t0, t1, o0, o1)
+			if err != nil {
+				return err
+			}
+			passes = append(passes, jd)
+		}
+		if len(passes) > 0 && t1 > passes[0]+span {
+			return search.Stop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(passes) > 0 {
+		return passes, nil
+	}
+	return nil, fmt.Errorf("no return of %s to %.4f° found after JD %.2f", p.PlanetName(body), natalLon, from)
+}
@@ -0,0 +1,80 @@
+package returns_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/returns"
+)
+
+func TestSolar_UniformMotion(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 350, SpeedLon: 1}},
+	}
+
+	cases := []struct {
+		natal, from, want float64
+	}{
+		{10, 0, 20},   // crosses 0° Aries on the way
+		{350, 1, 360}, // just missed it: next pass a full circle later
+		{355, 0, 5},
+	}
+	for _, tc := range cases {
+		got, err := returns.Solar(p, tc.natal, tc.from)
+		if err != nil {
+			t.Fatalf("Solar(%v, %v): %v", tc.natal, tc.from, err)
+		}
+		if math.Abs(got-tc.want) > 1e-6 {
+			t.Errorf("Solar(%v, %v) = %.7f, want %.7f", tc.natal, tc.from, got, tc.want)
+		}
+	}
+}
+
+func TestFind_Direct(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Saturn: {Longitude: 100, SpeedLon: 0.0335}},
+	}
+	passes, err := returns.Find(p, ephemeris.Saturn, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 360 / 0.0335
+	if len(passes) != 1 || math.Abs(passes[0]-want) > 1e-4 {
+		t.Errorf("passes = %v, want [%.4f]", passes, want)
+	}
+}
+
+// retroProvider moves Jupiter forward 0.2°/day with a ±8° wobble of period
+// 100 days, so it periodically runs backwards and crosses some degrees
+// three times.
+type retroProvider struct{ ephemeris.MockProvider }
+
+func (retroProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	lon := 0.2*jd + 8*math.Sin(2*math.Pi*jd/100)
+	return ephemeris.PlanetPos{Longitude: math.Mod(math.Mod(lon, 360)+360, 360)}, nil
+}
+
+func TestFind_RetrogradeTriplePass(t *testing.T) {
+	passes, err := returns.Find(&retroProvider{}, ephemeris.Jupiter, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passes) != 3 {
+		t.Fatalf("got %d passes, want 3: %v", len(passes), passes)
+	}
+	for _, jd := range passes {
+		pos, _ := (&retroProvider{}).CalcPlanet(jd, ephemeris.Jupiter)
+		if math.Abs(pos.Longitude-10) > 1e-3 {
+			t.Errorf("pass at %.4f has longitude %.5f, want 10", jd, pos.Longitude)
+		}
+	}
+}
+
+func TestFind_Unsupported(t *testing.T) {
+	if _, err := returns.Find(&ephemeris.MockProvider{}, ephemeris.MeanNode, 0, 0); err == nil {
+		t.Error("expected error for unsupported body")
+	}
+}
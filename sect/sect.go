@@ -0,0 +1,66 @@
+// Package sect determines whether a chart is diurnal or nocturnal, and the
+// planets that belong to its sect: the luminary, benefic and malefic that
+// are at their best in it.
+package sect
+
+import (
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Sect is the sect of a chart.
+type Sect int
+
+const (
+	Day   Sect = iota // the Sun above the horizon
+	Night             // the Sun below the horizon
+)
+
+func (s Sect) String() string {
+	if s == Day {
+		return "day"
+	}
+	return "night"
+}
+
+// Of returns the sect of a chart whose Sun is at ecliptic longitude sun and
+// whose Ascendant is at asc. The Sun is above the horizon when it lies in
+// houses 7 to 12, on the arc running from the Descendant forwards to the
+// Ascendant. The Sun stays on the ecliptic, so this agrees with its
+// altitude, refraction aside.
+func Of(sun, asc float64) Sect {
+	if angle.Norm360(sun-(asc+180)) < 180 {
+		return Day
+	}
+	return Night
+}
+
+// Light returns the luminary of the sect: the Sun by day, the Moon by
+// night.
+func (s Sect) Light() int {
+	if s == Day {
+		return ephemeris.Sun
+	}
+	return ephemeris.Moon
+}
+
+// Benefic returns the benefic of the sect: Jupiter by day, Venus by night.
+func (s Sect) Benefic() int {
+	if s == Day {
+		return ephemeris.Jupiter
+	}
+	return ephemeris.Venus
+}
+
+// Malefic returns the malefic of the sect, which its sect restrains:
+// Saturn by day, Mars by night.
+func (s Sect) Malefic() int {
+	if s == Day {
+		return ephemeris.Saturn
+	}
+	return ephemeris.Mars
+}
+
+// Contrary returns the other sect. Its malefic, Mars in a day chart and
+// Saturn in a night chart, is the more difficult.
+func (s Sect) Contrary() Sect { return 1 - s }
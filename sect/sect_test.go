@@ -0,0 +1,39 @@
+package sect_test
+
+import (
+	"testing"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/sect"
+)
+
+func TestOf(t *testing.T) {
+	cases := []struct {
+		sun, asc float64
+		want     sect.Sect
+	}{
+		{270, 0, sect.Day},   // Sun on the MC
+		{90, 0, sect.Night},  // Sun on the IC
+		{350, 0, sect.Day},   // twelfth house
+		{10, 0, sect.Night},  // first house
+		{100, 120, sect.Day}, // wraps through 0°
+	}
+	for _, tc := range cases {
+		if got := sect.Of(tc.sun, tc.asc); got != tc.want {
+			t.Errorf("Of(%v, %v) = %v, want %v", tc.sun, tc.asc, got, tc.want)
+		}
+	}
+}
+
+func TestPlanets(t *testing.T) {
+	if d := sect.Day; d.Light() != ephemeris.Sun || d.Benefic() != ephemeris.Jupiter || d.Malefic() != ephemeris.Saturn {
+		t.Errorf("day sect: light %d, benefic %d, malefic %d", d.Light(), d.Benefic(), d.Malefic())
+	}
+	n := sect.Day.Contrary()
+	if n != sect.Night || n.Light() != ephemeris.Moon || n.Benefic() != ephemeris.Venus || n.Malefic() != ephemeris.Mars {
+		t.Errorf("night sect %v: light %d, benefic %d, malefic %d", n, n.Light(), n.Benefic(), n.Malefic())
+	}
+	if sect.Night.String() != "night" || sect.Day.String() != "day" {
+		t.Errorf("String() = %q, %q", sect.Day, sect.Night)
+	}
+}
@@ -0,0 +1,136 @@
+// Package store keeps named charts in a JSON file, so that a chart saved
+// once can be given by its name instead of its birth data.
+//
+// The file is a JSON array of objects with the keys name, datetime, lat
+// and lon, which astro batch also reads:
+//
+//	[
+//	  {"name": "Alice", "datetime": "1990-01-09T15:30:00+01:00[Europe/Berlin]", "lat": 52.52, "lon": 13.405}
+//	]
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Chart is a saved birth moment and place.
+type Chart struct {
+	Name string `json:"name"`
+	// DateTime is the moment as input.ParseDateTime reads it, in the zone
+	// it was given in, e.g. 1990-01-09T15:30:00+01:00[Europe/Berlin].
+	DateTime string  `json:"datetime"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+}
+
+// Store is a set of charts with distinct names, in the order they were
+// first saved.
+type Store struct {
+	charts []Chart
+}
+
+// Read reads a store from r.
+func Read(r io.Reader) (*Store, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var charts []Chart
+	if err := dec.Decode(&charts); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid chart store: %w", err)
+	}
+	s := &Store{}
+	for _, c := range charts {
+		if _, ok := s.Lookup(c.Name); ok {
+			return nil, fmt.Errorf("invalid chart store: %q is saved twice", c.Name)
+		}
+		s.charts = append(s.charts, c)
+	}
+	return s, nil
+}
+
+// Write writes s to w as an indented JSON array.
+func (s *Store) Write(w io.Writer) error {
+	charts := s.charts
+	if charts == nil {
+		charts = []Chart{}
+	}
+	data, err := json.MarshalIndent(charts, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// Load reads the store in the file at path. A missing file is an empty
+// store.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Store{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes s to the file at path, creating its directory if need be.
+// The file is replaced whole, so a failed save leaves the old one intact.
+func (s *Store) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Charts returns the saved charts.
+func (s *Store) Charts() []Chart { return slices.Clone(s.charts) }
+
+// Lookup returns the chart called name, whatever its case.
+func (s *Store) Lookup(name string) (Chart, bool) {
+	if i := s.index(name); i >= 0 {
+		return s.charts[i], true
+	}
+	return Chart{}, false
+}
+
+// Put saves c, in place of any chart of the same name, and reports whether
+// it replaced one.
+func (s *Store) Put(c Chart) bool {
+	if i := s.index(c.Name); i >= 0 {
+		s.charts[i] = c
+		return true
+	}
+	s.charts = append(s.charts, c)
+	return false
+}
+
+func (s *Store) index(name string) int {
+	return slices.IndexFunc(s.charts, func(c Chart) bool { return strings.EqualFold(c.Name, name) })
+}
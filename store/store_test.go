@@ -0,0 +1,65 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "astro", "charts.json")
+	s, err := Load(path)
+	if err != nil || len(s.Charts()) != 0 {
+		t.Fatalf("Load(missing) = %v, %v; want an empty store", s.Charts(), err)
+	}
+	alice := Chart{Name: "Alice", DateTime: "1990-01-09T15:30:00+01:00[Europe/Berlin]", Lat: 52.52, Lon: 13.405}
+	if s.Put(alice) {
+		t.Error("Put(Alice) replaced a chart in an empty store")
+	}
+	s.Put(Chart{Name: "Bob", DateTime: "1985-06-01T08:00:00Z", Lat: 40.7, Lon: -74})
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := s.Lookup("ALICE"); !ok || c != alice {
+		t.Errorf("Lookup(ALICE) = %+v, %v; want %+v", c, ok, alice)
+	}
+	if !s.Put(Chart{Name: "alice", DateTime: "1990-01-09T14:30:00Z"}) {
+		t.Error("Put(alice) did not replace Alice")
+	}
+	var names []string
+	for _, c := range s.Charts() {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "alice,Bob" {
+		t.Errorf("Charts = %v, want alice, Bob", names)
+	}
+	if _, ok := s.Lookup("Carol"); ok {
+		t.Error("Lookup(Carol) found a chart")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("the store's directory holds %d files, want only charts.json", len(entries))
+	}
+}
+
+func TestRead(t *testing.T) {
+	for _, bad := range []string{
+		`{"name": "Alice"}`,
+		`[{"name": "Alice", "place": "Berlin"}]`,
+		`[{"name": "Alice"}, {"name": "alice"}]`,
+	} {
+		if _, err := Read(strings.NewReader(bad)); err == nil {
+			t.Errorf("Read(%s): expected error", bad)
+		}
+	}
+	s, err := Read(strings.NewReader(""))
+	if err != nil || len(s.Charts()) != 0 {
+		t.Errorf("Read(empty) = %v, %v; want an empty store", s.Charts(), err)
+	}
+}
@@ -0,0 +1,122 @@
+package swisseph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AsteroidFile returns the name of the ephemeris file of numbered asteroid
+// n, e.g. se00433.se1 for Eros, or with short that of its short file,
+// se00433s.se1, which covers fewer centuries. Numbers above 99999 take the
+// form s100000.se1.
+func AsteroidFile(n int, short bool) string {
+	name := fmt.Sprintf("se%05d", n)
+	if n > 99999 {
+		name = fmt.Sprintf("s%06d", n)
+	}
+	if short {
+		name += "s"
+	}
+	return name + ".se1"
+}
+
+// AsteroidDir returns the subdirectory of the ephemeris path that holds
+// the file of numbered asteroid n, one for each thousand: ast0 for Eros,
+// ast136 for Eris.
+func AsteroidDir(n int) string {
+	return "ast" + strconv.Itoa(n/1000)
+}
+
+// AsteroidPaths returns where the library looks for the file of numbered
+// asteroid n, relative to the ephemeris path and in the order it looks:
+// the long file, then the short one, first in AsteroidDir and then in the
+// path itself. It opens the first it finds.
+func AsteroidPaths(n int) []string {
+	long, short := AsteroidFile(n, false), AsteroidFile(n, true)
+	dir := AsteroidDir(n)
+	return []string{filepath.Join(dir, long), filepath.Join(dir, short), long, short}
+}
+
+// AsteroidEphe is the ephemeris file of a numbered asteroid, as
+// ListAsteroids finds it.
+type AsteroidEphe struct {
+	Number int    // the asteroid's number, e.g. 433 for Eros
+	Path   string // relative to the ephemeris directory, e.g. ast0/se00433s.se1
+	Short  bool   // the short file, covering fewer centuries than the long
+	// Used is false for a file the library passes over for another of the
+	// same asteroid that it finds first (see AsteroidPaths).
+	Used bool
+}
+
+// asteroidFileName matches the names AsteroidFile gives.
+var asteroidFileName = regexp.MustCompile(`^(?:se(\d{5})|s(\d{6}))(s?)\.se1$`)
+
+// ListAsteroids returns the numbered-asteroid files in the ephemeris
+// directory dir and in its astN subdirectories, by number and then in the
+// order the library looks for them. A file in the wrong subdirectory, which
+// the library would not find, is left out.
+func ListAsteroids(dir string) ([]AsteroidEphe, error) {
+	list := []AsteroidEphe{}
+	add := func(sub string) error {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			m := asteroidFileName.FindStringSubmatch(e.Name())
+			if m == nil || e.IsDir() {
+				continue
+			}
+			n, _ := strconv.Atoi(m[1] + m[2])
+			if n == 0 || (sub != "" && sub != AsteroidDir(n)) {
+				continue
+			}
+			list = append(list, AsteroidEphe{Number: n, Path: filepath.Join(sub, e.Name()), Short: m[3] == "s"})
+		}
+		return nil
+	}
+	if err := add(""); err != nil {
+		return nil, err
+	}
+	subs, _ := filepath.Glob(filepath.Join(dir, "ast*"))
+	for _, sub := range subs {
+		if info, err := os.Stat(sub); err == nil && info.IsDir() {
+			if err := add(filepath.Base(sub)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	rank := func(a AsteroidEphe) int { return slices.Index(AsteroidPaths(a.Number), a.Path) }
+	slices.SortFunc(list, func(a, b AsteroidEphe) int {
+		if a.Number != b.Number {
+			return a.Number - b.Number
+		}
+		return rank(a) - rank(b)
+	})
+	for i := range list {
+		list[i].Used = i == 0 || list[i-1].Number != list[i].Number
+	}
+	return list, nil
+}
+
+// calcError returns the error of fn failing to compute planet, from the
+// library's message. Of the four files it looked for a numbered asteroid
+// in, the library names only the last; the error names the two files it
+// would take, and where it looked. mu must be held.
+func calcError(fn string, planet int, msg string) error {
+	n := planet - AstOffset
+	if n <= 0 || !strings.Contains(msg, "not found") {
+		return errorf("%s: %s", fn, msg)
+	}
+	where := "the ephemeris path"
+	if ephePath != "" {
+		where = ephePath
+	}
+	return errorf("%s: asteroid %d needs its ephemeris file, %s or the short %s, in %s or its %s subdirectory",
+		fn, n, AsteroidFile(n, false), AsteroidFile(n, true), where, AsteroidDir(n))
+}
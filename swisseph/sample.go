@@ -0,0 +1,201 @@
+package swisseph
+
+/*
+#include "swephexp.h"
+
+// calc_times runs swe_calc_ut for one planet at n times, storing six values
+// per time in xx, each return flag in ret and each message in the 256 bytes
+// of serr for the time. Like calc_many, it stops at the first time that
+// fails, or falls back from a requested JPL ephemeris, and returns its
+// index; it returns -1 if all succeed.
+static int calc_times(double *tjd, int n, int ipl, int flags, double *xx, int *ret, char *serr) {
+	for (int i = 0; i < n; i++) {
+		serr[256*i] = '\0';
+		ret[i] = swe_calc_ut(tjd[i], ipl, flags, xx + 6*i, serr + 256*i);
+		if (ret[i] < 0 || ((flags & SEFLG_JPLEPH) && !(ret[i] & SEFLG_JPLEPH))) {
+			return i;
+		}
+	}
+	return -1;
+}
+*/
+import "C"
+import (
+	"cmp"
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// maxSampleStep is the widest interval Sample starts from, in days: the
+// Moon moves less than 180° in it, so the direction of travel between two
+// samples is never in doubt.
+const maxSampleStep = 10.0
+
+// minSampleStep is the narrowest interval Sample splits, in days (about a
+// tenth of a second), which bounds the work for a very small maxErr.
+const minSampleStep = 1e-6
+
+// Samples are the positions of one planet at increasing times, chosen by
+// Sample so that At interpolates between them to within its error.
+type Samples struct {
+	Planet int
+	JD     []float64   // the times, in Julian Days (UT)
+	Pos    []PlanetPos // the positions, with speeds, at JD
+}
+
+// Sample computes the positions of planet from from to to (Julian Days,
+// UT), at times chosen so that interpolating between them with At is
+// within maxErr degrees of the library's longitude and latitude. The
+// samples are dense where the motion bends, near stations and for the
+// Moon, and sparse where it is steady, so a search or a graph over a long
+// range can read many positions from few calls into the library. The
+// flags are as for CalcPlanetFlags; FlagSpeed is always added. Each
+// sample keeps the library's Warning, such as a fallback to the Moshier
+// ephemeris.
+func Sample(planet int, from, to, maxErr float64, flags int) (*Samples, error) {
+	if !(to > from) {
+		return nil, errorf("sample: the end %v is not after the start %v", to, from)
+	}
+	if !(maxErr > 0) {
+		return nil, errorf("sample: the error bound must be positive, got %v", maxErr)
+	}
+	flags |= FlagSpeed
+
+	n := int(math.Ceil((to - from) / maxSampleStep))
+	jd := make([]float64, n+1)
+	for i := range jd {
+		jd[i] = from + (to-from)*float64(i)/float64(n)
+	}
+	pos, err := calcTimes(jd, planet, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each round checks the middle of every interval still in question,
+	// in one call, and splits those where the interpolation misses: the
+	// error of a cubic Hermite interpolant is greatest near the middle.
+	type interval struct{ a, b int } // indexes into jd and pos
+	var pending []interval
+	for i := range n {
+		pending = append(pending, interval{i, i + 1})
+	}
+	for len(pending) > 0 {
+		mids := make([]float64, 0, len(pending))
+		var checked []interval
+		for _, iv := range pending {
+			if jd[iv.b]-jd[iv.a] > minSampleStep {
+				mids = append(mids, (jd[iv.a]+jd[iv.b])/2)
+				checked = append(checked, iv)
+			}
+		}
+		if len(mids) == 0 {
+			break
+		}
+		got, err := calcTimes(mids, planet, flags)
+		if err != nil {
+			return nil, err
+		}
+		pending = pending[:0]
+		for k, iv := range checked {
+			est := interpolate(jd[iv.a], pos[iv.a], jd[iv.b], pos[iv.b], mids[k])
+			dLon := math.Abs(math.Remainder(est.Longitude-got[k].Longitude, 360))
+			dLat := math.Abs(est.Latitude - got[k].Latitude)
+			if max(dLon, dLat) <= maxErr {
+				continue
+			}
+			m := len(jd)
+			jd = append(jd, mids[k])
+			pos = append(pos, got[k])
+			pending = append(pending, interval{iv.a, m}, interval{m, iv.b})
+		}
+	}
+
+	idx := make([]int, len(jd))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return jd[idx[i]] < jd[idx[j]] })
+	s := &Samples{Planet: planet, JD: make([]float64, len(jd)), Pos: make([]PlanetPos, len(jd))}
+	for i, k := range idx {
+		s.JD[i], s.Pos[i] = jd[k], pos[k]
+	}
+	return s, nil
+}
+
+// At returns the position at jd, interpolated between the samples either
+// side of it, with speeds and the Warning of either. ok is false if jd is
+// outside the sampled range.
+func (s *Samples) At(jd float64) (pos PlanetPos, ok bool) {
+	n := len(s.JD)
+	if n == 0 || jd < s.JD[0] || jd > s.JD[n-1] {
+		return PlanetPos{}, false
+	}
+	i := sort.SearchFloat64s(s.JD, jd)
+	if s.JD[i] == jd {
+		return s.Pos[i], true
+	}
+	return interpolate(s.JD[i-1], s.Pos[i-1], s.JD[i], s.Pos[i], jd), true
+}
+
+// interpolate returns the position at t between the positions p0 at t0 and
+// p1 at t1 by cubic Hermite interpolation, which matches both positions
+// and speeds. The longitude is taken the short way round the circle. The
+// warning is p0's, or p1's if p0 has none.
+func interpolate(t0 float64, p0 PlanetPos, t1 float64, p1 PlanetPos, t float64) PlanetPos {
+	lon1 := p0.Longitude + math.Remainder(p1.Longitude-p0.Longitude, 360)
+	lon, speedLon := hermite(t0, p0.Longitude, p0.SpeedLon, t1, lon1, p1.SpeedLon, t)
+	lat, speedLat := hermite(t0, p0.Latitude, p0.SpeedLat, t1, p1.Latitude, p1.SpeedLat, t)
+	dist, speedDist := hermite(t0, p0.Distance, p0.SpeedDistance, t1, p1.Distance, p1.SpeedDistance, t)
+	return PlanetPos{
+		Longitude:     angle.Norm360(lon),
+		Latitude:      lat,
+		Distance:      dist,
+		SpeedLon:      speedLon,
+		SpeedLat:      speedLat,
+		SpeedDistance: speedDist,
+		Warning:       cmp.Or(p0.Warning, p1.Warning),
+	}
+}
+
+// hermite evaluates at t the cubic through y0 at t0 and y1 at t1 with
+// slopes d0 and d1 there, and its slope.
+func hermite(t0, y0, d0, t1, y1, d1, t float64) (y, dy float64) {
+	h := t1 - t0
+	u := (t - t0) / h
+	u2, u3 := u*u, u*u*u
+	y = (2*u3-3*u2+1)*y0 + (u3-2*u2+u)*h*d0 + (-2*u3+3*u2)*y1 + (u3-u2)*h*d1
+	dy = ((6*u2-6*u)*y0 + (3*u2-4*u+1)*h*d0 + (-6*u2+6*u)*y1 + (3*u2-2*u)*h*d1) / h
+	return y, dy
+}
+
+// calcTimes calculates planet at each of times in one call into the
+// library, failing as CalcPlanets does. Each position carries the
+// library's warning, if any.
+func calcTimes(times []float64, planet, flags int) ([]PlanetPos, error) {
+	tjd := make([]C.double, len(times))
+	for i, t := range times {
+		tjd[i] = C.double(t)
+	}
+	xx := make([]C.double, 6*len(times))
+	ret := make([]C.int, len(times))
+	serr := make([]C.char, 256*len(times))
+
+	mu.Lock()
+	defer mu.Unlock()
+	failed := int(C.calc_times(&tjd[0], C.int(len(times)), C.int(planet), C.int(flags), &xx[0], &ret[0], &serr[0]))
+
+	if failed >= 0 {
+		if int(ret[failed]) < 0 {
+			return nil, calcError("swe_calc_ut", planet, C.GoString(&serr[256*failed]))
+		}
+		return nil, checkJPL(flags, int(ret[failed]), &serr[256*failed])
+	}
+	pos := make([]PlanetPos, len(times))
+	for i := range pos {
+		pos[i] = toPlanetPos([6]C.double(xx[6*i : 6*i+6]))
+		pos[i].Warning = warning(serr[256*i : 256*i+256])
+	}
+	return pos, nil
+}
@@ -0,0 +1,80 @@
+package swisseph
+
+/*
+#include "swephexp.h"
+*/
+import "C"
+import "path/filepath"
+
+// Source says which ephemeris computed a position: the library falls back
+// from the .se1 files to the Moshier ephemeris, without an error, when the
+// file a body needs is missing or does not cover the date.
+type Source struct {
+	// Ephemeris is FlagSwissEph, FlagMoshier or FlagJPL: the ephemeris
+	// that served the position, whatever the flags asked for.
+	Ephemeris int
+	// File is the name of the .se1 file read, and Start and End the
+	// Julian Days (ET) it covers. File is empty for the Moshier and JPL
+	// ephemerides, and for bodies computed without a file, such as the
+	// mean node or the Uranian planets.
+	File       string
+	Start, End float64
+}
+
+// CalcPlanetSource is CalcPlanetFlags that also reports the ephemeris that
+// served the position.
+func CalcPlanetSource(tjdUT float64, planet, flags int) (PlanetPos, Source, error) {
+	var xx [6]C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	defer mu.Unlock()
+	ret := C.swe_calc_ut(C.double(tjdUT), C.int(planet), C.int(flags), &xx[0], &serr[0])
+	if int(ret) < 0 {
+		return PlanetPos{}, Source{}, calcError("swe_calc_ut", planet, C.GoString(&serr[0]))
+	}
+	if err := checkJPL(flags, int(ret), &serr[0]); err != nil {
+		return PlanetPos{}, Source{}, err
+	}
+
+	var src Source
+	switch {
+	case int(ret)&FlagJPL != 0:
+		src.Ephemeris = FlagJPL
+	case int(ret)&FlagMoshier != 0:
+		src.Ephemeris = FlagMoshier
+	default:
+		src.Ephemeris = FlagSwissEph
+		if ifno := fileIndex(planet); ifno >= 0 {
+			var start, end C.double
+			var denum C.int
+			if name := C.swe_get_current_file_data(C.int(ifno), &start, &end, &denum); name != nil {
+				src.File = filepath.Base(C.GoString(name))
+				src.Start, src.End = float64(start), float64(end)
+			}
+		}
+	}
+	pos := toPlanetPos(xx)
+	pos.Warning = warning(serr[:])
+	return pos, src, nil
+}
+
+// fileIndex returns the library's index of the kind of .se1 file planet is
+// read from, for swe_get_current_file_data: 0 for the planets, 1 for the
+// Moon and the points derived from its orbit, 2 for the main asteroids and
+// 3 for the numbered ones. It returns -1 for the bodies the library
+// computes without a file.
+func fileIndex(planet int) int {
+	switch {
+	case planet > AstOffset:
+		return 3
+	case planet >= Chiron && planet <= Vesta:
+		return 2
+	case planet == Moon, planet == TrueNode, planet == C.SE_OSCU_APOG,
+		planet == C.SE_INTP_APOG, planet == C.SE_INTP_PERG:
+		return 1
+	case planet == Sun, planet >= Mercury && planet <= Pluto, planet == Earth:
+		return 0
+	}
+	return -1
+}
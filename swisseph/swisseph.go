@@ -4,20 +4,41 @@
 package swisseph
 
 /*
-#cgo CFLAGS: -w
+#cgo CFLAGS: -w -DTLSOFF
 #cgo LDFLAGS: -lm
 #include "swephexp.h"
 #include <stdlib.h>
+
+// calc_many runs swe_calc_ut for n planets, storing six values per planet
+// in xx, each return flag in ret and each message in the 256 bytes of serr
+// for the planet. It stops at the first planet that fails, or that falls
+// back from a requested JPL ephemeris, and returns its index; it returns -1
+// if all succeed.
+static int calc_many(double tjd, int *ipl, int n, int flags, double *xx, int *ret, char *serr) {
+	for (int i = 0; i < n; i++) {
+		serr[256*i] = '\0';
+		ret[i] = swe_calc_ut(tjd, ipl[i], flags, xx + 6*i, serr + 256*i);
+		if (ret[i] < 0 || ((flags & SEFLG_JPLEPH) && !(ret[i] & SEFLG_JPLEPH))) {
+			return i;
+		}
+	}
+	return -1;
+}
 */
 import "C"
 import (
+	"errors"
 	"fmt"
-	"math"
+	"strings"
 	"sync"
+	"time"
 	"unsafe"
+
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/zodiac"
 )
 
-// Planet identifiers for the traditional planets.
+// Planet identifiers for the traditional and modern planets.
 const (
 	Sun     = C.SE_SUN
 	Moon    = C.SE_MOON
@@ -26,6 +47,40 @@ const (
 	Mars    = C.SE_MARS
 	Jupiter = C.SE_JUPITER
 	Saturn  = C.SE_SATURN
+	Uranus  = C.SE_URANUS
+	Neptune = C.SE_NEPTUNE
+	Pluto   = C.SE_PLUTO
+	Earth   = C.SE_EARTH // only meaningful with FlagHeliocentric
+
+	MeanNode = C.SE_MEAN_NODE // mean lunar node (North Node)
+	TrueNode = C.SE_TRUE_NODE // true (osculating) lunar node
+	// MeanApogee is the mean lunar apogee, the Black Moon Lilith.
+	MeanApogee = C.SE_MEAN_APOG
+
+	Chiron = C.SE_CHIRON
+	Pholus = C.SE_PHOLUS
+	Ceres  = C.SE_CERES
+	Pallas = C.SE_PALLAS
+	Juno   = C.SE_JUNO
+	Vesta  = C.SE_VESTA
+
+	// The hypothetical planets of the Hamburg school, the Uranian planets,
+	// and Transpluto (Isis), computed from fictitious orbital elements
+	// built into the library.
+	Cupido     = C.SE_CUPIDO
+	Hades      = C.SE_HADES
+	Zeus       = C.SE_ZEUS
+	Kronos     = C.SE_KRONOS
+	Apollon    = C.SE_APOLLON
+	Admetos    = C.SE_ADMETOS
+	Vulkanus   = C.SE_VULKANUS
+	Poseidon   = C.SE_POSEIDON
+	Transpluto = C.SE_ISIS
+
+	// AstOffset plus its number identifies a numbered asteroid, e.g.
+	// AstOffset+433 for Eros. Each needs its own ephemeris file, such as
+	// se00433s.se1, in the ephemeris path (see AsteroidPaths).
+	AstOffset = C.SE_AST_OFFSET
 )
 
 // House system codes (passed as a single character).
@@ -38,9 +93,75 @@ const (
 	HouseCampanus      = 'C'
 )
 
-// mu protects the Swiss Ephemeris global state from concurrent access.
+// Calculation flags for CalcPlanetFlags. Combine with bitwise OR; exactly
+// one of the ephemeris source flags should be set.
+const (
+	FlagSwissEph = C.SEFLG_SWIEPH // use the .se1 files (falls back to Moshier if absent)
+	FlagMoshier  = C.SEFLG_MOSEPH // use the built-in Moshier approximation, no files needed
+	FlagJPL      = C.SEFLG_JPLEPH // use a JPL DE file (de431.eph) from the ephemeris path
+	FlagSpeed    = C.SEFLG_SPEED  // also compute daily speeds
+
+	FlagHeliocentric = C.SEFLG_HELCTR   // positions as seen from the Sun
+	FlagBarycentric  = C.SEFLG_BARYCTR  // positions as seen from the barycentre of the solar system
+	FlagSidereal     = C.SEFLG_SIDEREAL // sidereal zodiac, using the mode set by SetSidMode
+
+	FlagTruePos      = C.SEFLG_TRUEPOS // geometric position, without light-time
+	FlagNoAberration = C.SEFLG_NOABERR // without the aberration of light
+	FlagNoDeflection = C.SEFLG_NOGDEFL // without the gravitational deflection of light
+	FlagJ2000        = C.SEFLG_J2000   // referred to the equinox of J2000 rather than of date
+	FlagNoNutation   = C.SEFLG_NONUT   // mean equinox of date, without nutation
+)
+
+// Sidereal modes (ayanamsas) for SetSidMode.
+const (
+	SidmFaganBradley = C.SE_SIDM_FAGAN_BRADLEY
+	SidmLahiri       = C.SE_SIDM_LAHIRI
+	SidmRaman        = C.SE_SIDM_RAMAN
+	SidmKrishnamurti = C.SE_SIDM_KRISHNAMURTI
+	SidmUser         = C.SE_SIDM_USER // set with SetSidModeUser
+)
+
+// Error is a failure reported by the library, such as a date outside the
+// range of the ephemeris files or a missing JPL file.
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string { return e.msg }
+
+func errorf(format string, args ...any) error {
+	return &Error{fmt.Sprintf(format, args...)}
+}
+
+// mu protects the Swiss Ephemeris global state from concurrent access. The
+// library is built with TLSOFF, so that its state is global rather than
+// per thread: a goroutine may call in from any thread, and one that had not
+// seen SetEphePath would silently fall back to the Moshier ephemeris.
 var mu sync.Mutex
 
+// ephePath is the path last given to SetEphePath, for the errors that name
+// a missing file. mu guards it.
+var ephePath string
+
+// scratch is the memory the planet calculations pass to the library, kept
+// from one call to the next so that they allocate nothing. mu guards it.
+var scratch struct {
+	xx   []C.double
+	ret  []C.int
+	ipl  []C.int
+	serr []C.char // 256 bytes per planet
+}
+
+// grow makes scratch hold n planets.
+func grow(n int) {
+	if cap(scratch.ipl) < n {
+		scratch.xx = make([]C.double, 6*n)
+		scratch.ret = make([]C.int, n)
+		scratch.ipl = make([]C.int, n)
+		scratch.serr = make([]C.char, 256*n)
+	}
+}
+
 // SetEphePath tells the library where to find the .se1 ephemeris data files.
 // If path is empty, the library falls back to the Moshier ephemeris (lower
 // precision but needs no external files).
@@ -50,6 +171,7 @@ func SetEphePath(path string) {
 	mu.Lock()
 	defer mu.Unlock()
 	C.swe_set_ephe_path(cpath)
+	ephePath = path
 }
 
 // Close frees all resources allocated by the library. Call this when done.
@@ -59,6 +181,14 @@ func Close() {
 	C.swe_close()
 }
 
+// Version returns the version of the bundled Swiss Ephemeris library, e.g.
+// "2.10.03".
+func Version() string {
+	var buf [256]C.char
+	C.swe_version(&buf[0])
+	return C.GoString(&buf[0])
+}
+
 // PlanetName returns the human-readable name for a planet ID.
 func PlanetName(planet int) string {
 	var buf [256]C.char
@@ -76,36 +206,302 @@ func JulDay(year, month, day int, hour float64) float64 {
 	))
 }
 
+// JulDayFromTime converts t, in any zone, to a Julian Day number (UT). It
+// is ephemeris.JulianDay, which the pure-Go packages use, so that both
+// sides of the cgo boundary convert times alike.
+func JulDayFromTime(t time.Time) float64 {
+	return ephemeris.JulianDay(t)
+}
+
+// TimeFromJulDay converts a Julian Day number (UT) to the time in loc,
+// rounded to the nearest millisecond, as ephemeris.TimeOf does. A nil loc
+// means UTC.
+func TimeFromJulDay(jd float64, loc *time.Location) time.Time {
+	t := ephemeris.TimeOf(jd)
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// The Julian Days the ephemerides cover (sweph.h): the Moshier ephemeris
+// about 3000 BC to 3000 AD, and the Swiss Ephemeris files, like the JPL
+// DE431 they are made from, about 13000 BC to 17000 AD.
+const (
+	MoshierStart = 625000.5
+	MoshierEnd   = 2818000.5
+	SwissStart   = -3027215.5
+	SwissEnd     = 7930192.5
+)
+
+// CheckDate returns an *Error naming the range of the ephemeris flags
+// selects when tjdUT is outside it, before any position is computed. The
+// range of a JPL file depends on the file, which the library checks.
+func CheckDate(tjdUT float64, flags int) error {
+	name, start, end := "Swiss Ephemeris files", SwissStart, SwissEnd
+	switch {
+	case flags&FlagJPL != 0:
+		return nil
+	case flags&FlagMoshier != 0:
+		name, start, end = "Moshier ephemeris", MoshierStart, MoshierEnd
+	}
+	if tjdUT >= start && tjdUT <= end {
+		return nil
+	}
+	return errorf("JD %.6f (%s) is outside the range of the %s, %s to %s (JD %.1f to %.1f)", tjdUT,
+		TimeFromJulDay(tjdUT, nil).Format("2006-01-02"), name,
+		TimeFromJulDay(start, nil).Format("2006-01-02"), TimeFromJulDay(end, nil).Format("2006-01-02"), start, end)
+}
+
 // PlanetPos holds the result of a planetary position calculation.
 type PlanetPos struct {
 	Longitude     float64 // ecliptic longitude in degrees (0-360)
 	Latitude      float64 // ecliptic latitude in degrees
-	Distance      float64 // distance from Earth in AU
+	Distance      float64 // distance from Earth (or the observing body) in AU
 	SpeedLon      float64 // daily speed in longitude (degrees/day)
 	SpeedLat      float64 // daily speed in latitude (degrees/day)
 	SpeedDistance float64 // daily speed in distance (AU/day)
+	// Warning is the library's message about a position it computed all
+	// the same, such as the .se1 file it could not find before falling
+	// back to the Moshier ephemeris. It is empty when there is none.
+	Warning string
+}
+
+// CalcOptions chooses the ephemeris, the centre and the corrections of a
+// planet calculation. The zero value is an apparent geocentric position
+// from the Swiss Ephemeris files, without speeds.
+type CalcOptions struct {
+	Ephemeris int  // FlagSwissEph, FlagMoshier or FlagJPL; 0 means FlagSwissEph
+	Speed     bool // also compute daily speeds
+
+	Heliocentric bool // as seen from the Sun
+	Barycentric  bool // as seen from the barycentre of the solar system
+	Sidereal     bool // sidereal zodiac, using the mode set by SetSidMode
+
+	TruePos      bool // geometric position, without light-time
+	NoAberration bool // without the aberration of light
+	NoDeflection bool // without the gravitational deflection of light
+	J2000        bool // referred to the equinox of J2000 rather than of date
+	NoNutation   bool // mean equinox of date, without nutation
+}
+
+// Flags returns the Flag* calculation flags of o, for CalcPlanetFlags and
+// the functions that take flags.
+func (o CalcOptions) Flags() int {
+	flags := o.Ephemeris
+	if flags == 0 {
+		flags = FlagSwissEph
+	}
+	for _, f := range []struct {
+		set  bool
+		flag int
+	}{
+		{o.Speed, FlagSpeed},
+		{o.Heliocentric, FlagHeliocentric},
+		{o.Barycentric, FlagBarycentric},
+		{o.Sidereal, FlagSidereal},
+		{o.TruePos, FlagTruePos},
+		{o.NoAberration, FlagNoAberration},
+		{o.NoDeflection, FlagNoDeflection},
+		{o.J2000, FlagJ2000},
+		{o.NoNutation, FlagNoNutation},
+	} {
+		if f.set {
+			flags |= f.flag
+		}
+	}
+	return flags
 }
 
 // CalcPlanet calculates the position of a planet at the given Julian Day (UT).
 // Use the planet constants (Sun, Moon, Mercury, etc.) for the planet argument.
 func CalcPlanet(tjdUT float64, planet int) (PlanetPos, error) {
-	var xx [6]C.double
-	var serr [256]C.char
+	return CalcPlanetOptions(tjdUT, planet, CalcOptions{Speed: true})
+}
+
+// CalcPlanetOptions is like CalcPlanet with the ephemeris and corrections
+// of opts. It fails without calling the library if opts asks for two
+// ephemerides, or for both a heliocentric and a barycentric position.
+func CalcPlanetOptions(tjdUT float64, planet int, opts CalcOptions) (PlanetPos, error) {
+	switch opts.Ephemeris {
+	case 0, FlagSwissEph, FlagMoshier, FlagJPL:
+	default:
+		return PlanetPos{}, errorf("CalcPlanetOptions: ephemeris %d is not one of FlagSwissEph, FlagMoshier, FlagJPL", opts.Ephemeris)
+	}
+	if opts.Heliocentric && opts.Barycentric {
+		return PlanetPos{}, errorf("CalcPlanetOptions: a position cannot be both heliocentric and barycentric")
+	}
+	return CalcPlanetFlags(tjdUT, planet, opts.Flags())
+}
+
+// CalcPlanetFlags is like CalcPlanet but lets the caller choose the
+// calculation flags (see the Flag* constants).
+func CalcPlanetFlags(tjdUT float64, planet int, flags int) (PlanetPos, error) {
+	var pos PlanetPos
+	if err := CalcPlanetInto(tjdUT, planet, flags, &pos); err != nil {
+		return PlanetPos{}, err
+	}
+	return pos, nil
+}
 
+// CalcPlanetInto is CalcPlanetFlags storing the position in *pos. It
+// allocates nothing unless it fails, for loops that make millions of
+// calls.
+func CalcPlanetInto(tjdUT float64, planet, flags int, pos *PlanetPos) error {
 	mu.Lock()
+	defer mu.Unlock()
+	grow(1)
+	scratch.serr[0] = 0
 	ret := C.swe_calc_ut(
 		C.double(tjdUT),
 		C.int(planet),
-		C.SEFLG_SWIEPH|C.SEFLG_SPEED,
+		C.int(flags),
+		&scratch.xx[0],
+		&scratch.serr[0],
+	)
+
+	if int(ret) < 0 {
+		return calcError("swe_calc_ut", planet, C.GoString(&scratch.serr[0]))
+	}
+	if err := checkJPL(flags, int(ret), &scratch.serr[0]); err != nil {
+		return err
+	}
+	*pos = toPlanetPos([6]C.double(scratch.xx[:6]))
+	pos.Warning = warning(scratch.serr[:256])
+	return nil
+}
+
+// CalcPlanets calculates the positions of several planets at the same time
+// with the same flags, in the order of planets. It crosses into the library
+// once for them all, so it is faster than calling CalcPlanetFlags for each
+// when computing many rows of positions.
+func CalcPlanets(tjdUT float64, planets []int, flags int) ([]PlanetPos, error) {
+	if len(planets) == 0 {
+		return nil, nil
+	}
+	pos := make([]PlanetPos, len(planets))
+	if err := CalcPlanetsInto(tjdUT, planets, flags, pos); err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
+
+// CalcPlanetsInto is CalcPlanets storing the positions in pos, which must
+// be as long as planets. Reusing pos from row to row, it allocates nothing
+// unless it fails.
+func CalcPlanetsInto(tjdUT float64, planets []int, flags int, pos []PlanetPos) error {
+	if len(pos) != len(planets) {
+		return errorf("CalcPlanetsInto: %d positions for %d planets", len(pos), len(planets))
+	}
+	if len(planets) == 0 {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	grow(len(planets))
+	for i, pl := range planets {
+		scratch.ipl[i] = C.int(pl)
+	}
+	failed := int(C.calc_many(C.double(tjdUT), &scratch.ipl[0], C.int(len(planets)), C.int(flags), &scratch.xx[0], &scratch.ret[0], &scratch.serr[0]))
+
+	if failed >= 0 {
+		serr := &scratch.serr[256*failed]
+		if int(scratch.ret[failed]) < 0 {
+			return calcError("swe_calc_ut", planets[failed], C.GoString(serr))
+		}
+		return checkJPL(flags, int(scratch.ret[failed]), serr)
+	}
+	for i := range pos {
+		pos[i] = toPlanetPos([6]C.double(scratch.xx[6*i : 6*i+6]))
+		pos[i].Warning = warning(scratch.serr[256*i : 256*i+256])
+	}
+	return nil
+}
+
+// CalcPlanetCentric calculates the position of planet as seen from the
+// centre of another planet (planetocentric), e.g. the sky from Mars. The
+// flags are as for CalcPlanetFlags; FlagHeliocentric is ignored.
+func CalcPlanetCentric(tjdUT float64, planet, center int, flags int) (PlanetPos, error) {
+	var xx [6]C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	defer mu.Unlock()
+	// swe_calc_pctr works in Ephemeris Time.
+	tjdET := C.double(tjdUT) + C.swe_deltat_ex(C.double(tjdUT), C.int32(flags&(FlagSwissEph|FlagMoshier)), &serr[0])
+	serr[0] = 0
+	ret := C.swe_calc_pctr(
+		tjdET,
+		C.int32(planet),
+		C.int32(center),
+		C.int32(flags),
 		&xx[0],
 		&serr[0],
 	)
+	if int(ret) < 0 {
+		return PlanetPos{}, calcError("swe_calc_pctr", planet, C.GoString(&serr[0]))
+	}
+	if err := checkJPL(flags, int(ret), &serr[0]); err != nil {
+		return PlanetPos{}, err
+	}
+	pos := toPlanetPos(xx)
+	pos.Warning = warning(serr[:])
+	return pos, nil
+}
+
+// Distances are the greatest, least and present distances of a body, in
+// AU, as OrbitDistances returns them.
+type Distances struct {
+	Max, Min, True float64
+}
+
+// OrbitDistances returns the greatest and least distances that planet
+// can have from the Earth on its present osculating orbit, and its true
+// distance now, at the given Julian Day (UT). For the Moon these are its
+// apogee and perigee distances in the month around tjdUT, which say how
+// near its perigee a new or full moon falls. The flags select the
+// ephemeris as for CalcPlanetFlags; with FlagHeliocentric the distances
+// are from the Sun.
+func OrbitDistances(tjdUT float64, planet, flags int) (Distances, error) {
+	var dmax, dmin, dtrue C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	// swe_orbit_max_min_true_distance works in Ephemeris Time.
+	tjdET := C.double(tjdUT) + C.swe_deltat_ex(C.double(tjdUT), C.int32(flags&(FlagSwissEph|FlagMoshier)), &serr[0])
+	ret := C.swe_orbit_max_min_true_distance(tjdET, C.int32(planet), C.int32(flags), &dmax, &dmin, &dtrue, &serr[0])
 	mu.Unlock()
 
 	if int(ret) < 0 {
-		return PlanetPos{}, fmt.Errorf("swe_calc_ut: %s", C.GoString(&serr[0]))
+		return Distances{}, errorf("swe_orbit_max_min_true_distance: %s", C.GoString(&serr[0]))
 	}
+	return Distances{Max: float64(dmax), Min: float64(dmin), True: float64(dtrue)}, nil
+}
+
+// checkJPL reports an error when FlagJPL was requested but the library fell
+// back to another ephemeris, which it does silently when the JPL file is
+// missing or does not cover the date.
+func checkJPL(flags, ret int, serr *C.char) error {
+	if flags&FlagJPL == 0 || ret&FlagJPL != 0 {
+		return nil
+	}
+	if msg := C.GoString(serr); msg != "" {
+		return errorf("JPL ephemeris unavailable: %s", msg)
+	}
+	return errorf("JPL ephemeris unavailable: is de431.eph in the ephemeris path?")
+}
+
+// warning returns the message the library left in serr after a calculation
+// that succeeded, on one line and without the ";" ending its parts. It
+// allocates only when there is one.
+func warning(serr []C.char) string {
+	if serr[0] == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Join(strings.Fields(C.GoString(&serr[0])), " "), ";")
+}
 
+func toPlanetPos(xx [6]C.double) PlanetPos {
 	return PlanetPos{
 		Longitude:     float64(xx[0]),
 		Latitude:      float64(xx[1]),
@@ -113,7 +509,7 @@ func CalcPlanet(tjdUT float64, planet int) (PlanetPos, error) {
 		SpeedLon:      float64(xx[3]),
 		SpeedLat:      float64(xx[4]),
 		SpeedDistance: float64(xx[5]),
-	}, nil
+	}
 }
 
 // HouseResult holds the result of a house calculation.
@@ -123,6 +519,13 @@ type HouseResult struct {
 	MC        float64     // Midheaven (Medium Coeli) in degrees
 	ARMC      float64     // sidereal time in degrees
 	Vertex    float64     // Vertex in degrees
+	// EastPoint is the equatorial Ascendant, the degree rising at the
+	// equator.
+	EastPoint float64
+	// CoAscendant is Walter Koch's co-Ascendant and PolarAscendant the
+	// Ascendant of the polar house circle, Michael Munkasey's.
+	CoAscendant    float64
+	PolarAscendant float64
 }
 
 // CalcHouses calculates house cusps and angles for a given time and location.
@@ -130,24 +533,141 @@ type HouseResult struct {
 // (north and east are positive). hsys is a house system code (use the
 // House* constants).
 func CalcHouses(tjdUT float64, geoLat, geoLon float64, hsys byte) (HouseResult, error) {
+	return CalcHousesFlags(tjdUT, geoLat, geoLon, hsys, 0)
+}
+
+// CalcHousesFlags is like CalcHouses but takes calculation flags. Only
+// FlagSidereal has an effect: it returns sidereal cusps and angles.
+func CalcHousesFlags(tjdUT float64, geoLat, geoLon float64, hsys byte, flags int) (HouseResult, error) {
+	if isGauquelin(hsys) {
+		return HouseResult{}, errGauquelin
+	}
 	var cusps [13]C.double
 	var ascmc [10]C.double
+	var serr [256]C.char
 
 	mu.Lock()
-	ret := C.swe_houses(
+	ret := C.swe_houses_ex2(
 		C.double(tjdUT),
+		C.int32(flags&FlagSidereal),
 		C.double(geoLat),
 		C.double(geoLon),
 		C.int(hsys),
 		&cusps[0],
 		&ascmc[0],
+		nil,
+		nil,
+		&serr[0],
 	)
 	mu.Unlock()
 
 	if int(ret) < 0 {
-		return HouseResult{}, fmt.Errorf("swe_houses failed (return code %d)", int(ret))
+		return HouseResult{}, housesError("swe_houses_ex2", int(ret), hsys, geoLat, C.GoString(&serr[0]))
 	}
 
+	return toHouseResult(cusps, ascmc), nil
+}
+
+// CalcHousesARMC calculates house cusps and angles from sidereal time
+// instead of a date: armc is the right ascension of the MC, geoLat the
+// geographic latitude and eps the obliquity of the ecliptic, all in
+// degrees. It serves charts with no single moment, such as composites.
+func CalcHousesARMC(armc, geoLat, eps float64, hsys byte) (HouseResult, error) {
+	if isGauquelin(hsys) {
+		return HouseResult{}, errGauquelin
+	}
+	var cusps [13]C.double
+	var ascmc [10]C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_houses_armc_ex2(
+		C.double(armc),
+		C.double(geoLat),
+		C.double(eps),
+		C.int(hsys),
+		&cusps[0],
+		&ascmc[0],
+		nil,
+		nil,
+		&serr[0],
+	)
+	mu.Unlock()
+
+	if int(ret) < 0 {
+		return HouseResult{}, housesError("swe_houses_armc_ex2", int(ret), hsys, geoLat, C.GoString(&serr[0]))
+	}
+	return toHouseResult(cusps, ascmc), nil
+}
+
+// HouseName returns the Swiss Ephemeris's name for a house system code,
+// e.g. "Koch" for 'K' or "Porphyry" for 'O'. Like the library, which
+// casts Placidus houses for a code it does not know, it names any unknown
+// code "Placidus".
+func HouseName(hsys byte) string {
+	mu.Lock()
+	defer mu.Unlock()
+	return C.GoString(C.swe_house_name(C.int(hsys)))
+}
+
+// HouseSystems returns the codes of the house systems CalcHouses casts, in
+// the order of their codes: every system the library names, once each,
+// less the Gauquelin sectors, which have 36 cusps, not 12. Of the codes
+// that share a name, the first is kept, so the equal houses are
+// HouseEqual, not its alias 'E', and lower-case aliases are dropped.
+func HouseSystems() []byte {
+	var codes []byte
+	seen := make(map[string]bool)
+	for c := byte('A'); c <= 'z'; c++ {
+		name := HouseName(c)
+		switch {
+		case c > 'Z' && c < 'a', isGauquelin(c):
+		case name == "Placidus" && c != HousePlacidus:
+		case seen[name]:
+		default:
+			seen[name] = true
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// isGauquelin reports whether hsys is the code of the Gauquelin sectors,
+// in either case, as the library reads it.
+func isGauquelin(hsys byte) bool { return hsys == 'G' || hsys == 'g' }
+
+// PolarError is the failure of a house system that cannot be cast within
+// a polar circle, where some degrees of the ecliptic never rise: Placidus,
+// Koch and the other systems that divide the time a degree takes to rise.
+// In its place the library casts Porphyry houses, which CalcHouses does
+// not return.
+type PolarError struct {
+	System byte    // the house system asked for
+	Lat    float64 // the geographic latitude, in degrees
+}
+
+func (e *PolarError) Error() string {
+	return fmt.Sprintf("%s houses cannot be cast at latitude %.4f°, within the polar circle", HouseName(e.System), e.Lat)
+}
+
+// housesError is the error of a house calculation that returned ret < 0
+// with the message msg: a *PolarError when the library gave up on hsys at
+// geoLat, and an *Error otherwise.
+func housesError(fn string, ret int, hsys byte, geoLat float64, msg string) error {
+	if strings.Contains(msg, "polar circle") {
+		return &PolarError{System: hsys, Lat: geoLat}
+	}
+	if msg == "" {
+		return errorf("%s failed (return code %d)", fn, ret)
+	}
+	return errorf("%s failed: %s", fn, msg)
+}
+
+// errGauquelin refuses the Gauquelin sectors, whose 36 cusps a HouseResult
+// cannot hold.
+var errGauquelin = errorf("the Gauquelin sectors have 36 cusps, not 12 houses")
+
+func toHouseResult(cusps [13]C.double, ascmc [10]C.double) HouseResult {
 	var result HouseResult
 	for i := 0; i < 13; i++ {
 		result.Cusps[i] = float64(cusps[i])
@@ -156,7 +676,203 @@ func CalcHouses(tjdUT float64, geoLat, geoLon float64, hsys byte) (HouseResult,
 	result.MC = float64(ascmc[1])
 	result.ARMC = float64(ascmc[2])
 	result.Vertex = float64(ascmc[3])
-	return result, nil
+	result.EastPoint = float64(ascmc[4])
+	result.CoAscendant = float64(ascmc[5])
+	result.PolarAscendant = float64(ascmc[7])
+	return result
+}
+
+// Obliquity returns the true obliquity of the ecliptic (including
+// nutation) at the given Julian Day (UT), in degrees.
+func Obliquity(tjdUT float64) (float64, error) {
+	var xx [6]C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_calc_ut(C.double(tjdUT), C.SE_ECL_NUT, 0, &xx[0], &serr[0])
+	mu.Unlock()
+
+	if int(ret) < 0 {
+		return 0, errorf("swe_calc_ut: %s", C.GoString(&serr[0]))
+	}
+	return float64(xx[0]), nil
+}
+
+// Cotrans rotates a point between ecliptic and equatorial coordinates by
+// eps, the obliquity of the ecliptic in degrees. As in the C library, the
+// sign of eps gives the direction: negative turns a longitude and latitude
+// into right ascension and declination, positive turns them back. The
+// first coordinate returned is in [0, 360).
+func Cotrans(lon, lat, eps float64) (float64, float64) {
+	xpo := [3]C.double{C.double(lon), C.double(lat), 1}
+	var xpn [3]C.double
+
+	mu.Lock()
+	C.swe_cotrans(&xpo[0], &xpn[0], C.double(eps))
+	mu.Unlock()
+
+	return float64(xpn[0]), float64(xpn[1])
+}
+
+// CotransSpeed is Cotrans for a position with its daily speeds, as
+// CalcPlanet returns it: Longitude and Latitude come back as right
+// ascension and declination, or the other way, and SpeedLon and SpeedLat
+// as their speeds. The distance and its speed are unchanged. The rotation
+// is fixed, so eps should be the obliquity at the position's date.
+func CotransSpeed(pos PlanetPos, eps float64) PlanetPos {
+	xpo := [6]C.double{
+		C.double(pos.Longitude), C.double(pos.Latitude), C.double(pos.Distance),
+		C.double(pos.SpeedLon), C.double(pos.SpeedLat), C.double(pos.SpeedDistance),
+	}
+	var xpn [6]C.double
+
+	mu.Lock()
+	C.swe_cotrans_sp(&xpo[0], &xpn[0], C.double(eps))
+	mu.Unlock()
+
+	return toPlanetPos(xpn)
+}
+
+// Event codes for RiseTrans.
+const (
+	CalcRise = C.SE_CALC_RISE
+	CalcSet  = C.SE_CALC_SET
+)
+
+// ErrNoRiseSet is returned by RiseTrans when the body stays above or below
+// the horizon all day at the location (e.g. the midnight sun).
+var ErrNoRiseSet = errors.New("swisseph: body does not rise or set")
+
+// RiseTrans returns the Julian Day (UT) of the next rising or setting of
+// planet after tjdUT at the given location, per event (CalcRise or
+// CalcSet). It uses the upper limb and standard refraction, as almanacs do.
+// flags selects the ephemeris as for CalcPlanetFlags.
+func RiseTrans(tjdUT float64, planet int, geoLat, geoLon float64, event, flags int) (float64, error) {
+	geopos := [3]C.double{C.double(geoLon), C.double(geoLat), 0}
+	var tret C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_rise_trans(
+		C.double(tjdUT),
+		C.int32(planet),
+		nil,
+		C.int32(flags),
+		C.int32(event),
+		&geopos[0],
+		0, 0,
+		&tret,
+		&serr[0],
+	)
+	mu.Unlock()
+
+	switch {
+	case int(ret) == -2:
+		return 0, ErrNoRiseSet
+	case int(ret) < 0:
+		return 0, errorf("swe_rise_trans: %s", C.GoString(&serr[0]))
+	}
+	return float64(tret), nil
+}
+
+// SolCross returns the Julian Day (UT) of the Sun's next crossing of
+// ecliptic longitude x2cross after tjdUT, e.g. 0 for the March equinox.
+// flags selects the ephemeris as for CalcPlanetFlags; with FlagSidereal
+// the longitude is sidereal.
+func SolCross(x2cross, tjdUT float64, flags int) (float64, error) {
+	var serr [256]C.char
+
+	mu.Lock()
+	jd := C.swe_solcross_ut(C.double(x2cross), C.double(tjdUT), C.int32(flags), &serr[0])
+	mu.Unlock()
+
+	if float64(jd) < tjdUT {
+		return 0, errorf("swe_solcross_ut: %s", C.GoString(&serr[0]))
+	}
+	return float64(jd), nil
+}
+
+// MoonCross is SolCross for the Moon.
+func MoonCross(x2cross, tjdUT float64, flags int) (float64, error) {
+	var serr [256]C.char
+
+	mu.Lock()
+	jd := C.swe_mooncross_ut(C.double(x2cross), C.double(tjdUT), C.int32(flags), &serr[0])
+	mu.Unlock()
+
+	if float64(jd) < tjdUT {
+		return 0, errorf("swe_mooncross_ut: %s", C.GoString(&serr[0]))
+	}
+	return float64(jd), nil
+}
+
+// LMTToLAT converts local mean time to local apparent time, the time a
+// sundial shows, at east longitude geolon (degrees). Both times are Julian
+// Days of the local clock: UT plus geolon/15 hours for mean time, mean
+// time plus the equation of time for apparent time.
+func LMTToLAT(tjdLMT, geolon float64) (float64, error) {
+	var tjdLAT C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_lmt_to_lat(C.double(tjdLMT), C.double(geolon), &tjdLAT, &serr[0])
+	mu.Unlock()
+
+	if int(ret) < 0 {
+		return 0, errorf("swe_lmt_to_lat: %s", C.GoString(&serr[0]))
+	}
+	return float64(tjdLAT), nil
+}
+
+// LATToLMT converts local apparent time back to local mean time, as
+// LMTToLAT converts it.
+func LATToLMT(tjdLAT, geolon float64) (float64, error) {
+	var tjdLMT C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_lat_to_lmt(C.double(tjdLAT), C.double(geolon), &tjdLMT, &serr[0])
+	mu.Unlock()
+
+	if int(ret) < 0 {
+		return 0, errorf("swe_lat_to_lmt: %s", C.GoString(&serr[0]))
+	}
+	return float64(tjdLMT), nil
+}
+
+// SetSidMode selects the ayanamsa used by calculations with FlagSidereal
+// (use the Sidm* constants). Like SetEphePath it sets library-wide state.
+func SetSidMode(mode int) {
+	mu.Lock()
+	C.swe_set_sid_mode(C.int32(mode), 0, 0)
+	mu.Unlock()
+}
+
+// SetSidModeUser selects an ayanamsa of one's own for FlagSidereal:
+// ayanT0 degrees at the Julian Day t0, in Terrestrial Time as the library
+// takes it, carried to other dates by precession. Like SetSidMode it sets
+// library-wide state.
+func SetSidModeUser(t0, ayanT0 float64) {
+	mu.Lock()
+	C.swe_set_sid_mode(C.SE_SIDM_USER, C.double(t0), C.double(ayanT0))
+	mu.Unlock()
+}
+
+// Ayanamsa returns the ayanamsa of the current sidereal mode at the given
+// Julian Day (UT), in degrees: the tropical longitude minus the sidereal.
+// flags selects the ephemeris as for CalcPlanetFlags.
+func Ayanamsa(tjdUT float64, flags int) (float64, error) {
+	var daya C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_get_ayanamsa_ex_ut(C.double(tjdUT), C.int32(flags), &daya, &serr[0])
+	mu.Unlock()
+
+	if int(ret) < 0 {
+		return 0, errorf("swe_get_ayanamsa_ex_ut: %s", C.GoString(&serr[0]))
+	}
+	return float64(daya), nil
 }
 
 // ZodiacSign returns the zodiac sign name and degree within that sign
@@ -164,18 +880,5 @@ func CalcHouses(tjdUT float64, geoLat, geoLon float64, hsys byte) (HouseResult,
 // before computation, so values outside that range (including negative
 // values from retrograde offset arithmetic) are handled correctly.
 func ZodiacSign(longitude float64) (sign string, degrees float64) {
-	signs := [12]string{
-		"Aries", "Taurus", "Gemini", "Cancer",
-		"Leo", "Virgo", "Libra", "Scorpio",
-		"Sagittarius", "Capricorn", "Aquarius", "Pisces",
-	}
-	longitude = math.Mod(longitude, 360.0)
-	if longitude < 0 {
-		longitude += 360.0
-	}
-	idx := int(longitude / 30.0)
-	if idx >= 12 {
-		idx = 11
-	}
-	return signs[idx], longitude - float64(idx)*30.0
+	return zodiac.Sign(longitude)
 }
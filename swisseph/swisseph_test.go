@@ -1,11 +1,16 @@
 package swisseph_test
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/dcccxiii/astro/ephemeris"
 	"github.com/dcccxiii/astro/swisseph"
 )
 
@@ -101,6 +106,110 @@ func TestJulDay_KnownEpochs(t *testing.T) {
 	}
 }
 
+// TestJulDayFromTime checks the conversions of time.Time across the
+// daylight saving changes of New York: the moment, not the wall clock,
+// gives the Julian Day, and the Julian Day gives back the moment.
+func TestJulDayFromTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cases := []struct {
+		name string
+		t    time.Time
+		want float64
+	}{
+		{"J2000.0 in UTC", time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 2451545.0},
+		{"J2000.0 in New York", time.Date(2000, 1, 1, 7, 0, 0, 0, ny), 2451545.0},
+		// 2024-03-10 02:00 EST became 03:00 EDT: the minute before and
+		// the minute after are two minutes apart.
+		{"before spring forward", time.Date(2024, 3, 10, 1, 59, 0, 0, ny), swisseph.JulDay(2024, 3, 10, 6+59.0/60)},
+		{"after spring forward", time.Date(2024, 3, 10, 3, 1, 0, 0, ny), swisseph.JulDay(2024, 3, 10, 7+1.0/60)},
+		// 01:30 came twice on 2024-11-03, first in EDT, then in EST.
+		{"first 01:30 of fall back", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC).In(ny), swisseph.JulDay(2024, 11, 3, 5.5)},
+		{"second 01:30 of fall back", time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC).In(ny), swisseph.JulDay(2024, 11, 3, 6.5)},
+		{"across midnight UTC", time.Date(2024, 6, 30, 22, 15, 30, 500e6, ny), swisseph.JulDay(2024, 7, 1, 2+15.0/60+30.5/3600)},
+		// Like Go, proleptic Gregorian before 1582: the Julian calendar's
+		// 1000-01-01 is JD 2086307.5.
+		{"before the Gregorian reform", time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC), 2086302.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jd := swisseph.JulDayFromTime(tc.t)
+			if math.Abs(jd-tc.want) > 1e-8 {
+				t.Errorf("JulDayFromTime(%v) = %.8f, want %.8f", tc.t, jd, tc.want)
+			}
+			back := swisseph.TimeFromJulDay(jd, tc.t.Location())
+			if !back.Equal(tc.t) || back.Format(time.RFC3339Nano) != tc.t.Format(time.RFC3339Nano) {
+				t.Errorf("TimeFromJulDay(%.8f) = %v, want %v", jd, back, tc.t)
+			}
+		})
+	}
+	if got := swisseph.TimeFromJulDay(2451545.0, nil); got.Location() != time.UTC || got.Hour() != 12 {
+		t.Errorf("TimeFromJulDay(J2000.0, nil) = %v, want 12:00 UTC", got)
+	}
+
+	// The conversions are ephemeris.JulianDay and TimeOf, in Go; they
+	// agree with the library's calendar over the ephemeris's range.
+	for _, tc := range []time.Time{
+		time.Date(-3000, 3, 1, 6, 0, 0, 0, time.UTC),
+		time.Date(0, 2, 29, 23, 59, 59, 0, time.UTC),
+		time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(1900, 2, 28, 12, 30, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 18, 45, 15, 250e6, time.UTC),
+		time.Date(2999, 12, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(16000, 7, 4, 3, 0, 0, 0, time.UTC),
+	} {
+		hour := float64(tc.Hour()) + float64(tc.Minute())/60 + (float64(tc.Second())+float64(tc.Nanosecond())/1e9)/3600
+		lib := swisseph.JulDay(tc.Year(), int(tc.Month()), tc.Day(), hour)
+		if jd := swisseph.JulDayFromTime(tc); jd != ephemeris.JulianDay(tc) || math.Abs(jd-lib) > 1e-8 {
+			t.Errorf("JulDayFromTime(%v) = %.8f, want the library's %.8f", tc, jd, lib)
+		}
+		if back := swisseph.TimeFromJulDay(lib, nil); !back.Equal(tc) {
+			t.Errorf("TimeFromJulDay(%.8f) = %v, want %v", lib, back, tc)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Version
+// ---------------------------------------------------------------------------
+
+func TestCheckDate(t *testing.T) {
+	year5000 := swisseph.JulDay(5000, 1, 1, 12)
+	for _, c := range []struct {
+		jd    float64
+		flags int
+		ok    bool
+	}{
+		{2451545, swisseph.FlagMoshier, true},
+		{year5000, swisseph.FlagSwissEph, true},
+		{year5000, swisseph.FlagMoshier, false},
+		{year5000, swisseph.FlagJPL, true}, // the library checks the file's range
+		{swisseph.SwissEnd + 1, swisseph.FlagSwissEph, false},
+		{swisseph.SwissStart - 1, 0, false},
+	} {
+		err := swisseph.CheckDate(c.jd, c.flags)
+		if (err == nil) != c.ok {
+			t.Errorf("CheckDate(%v, %d) = %v, want ok %v", c.jd, c.flags, err, c.ok)
+		}
+	}
+	err := swisseph.CheckDate(year5000, swisseph.FlagMoshier)
+	var se *swisseph.Error
+	if !errors.As(err, &se) || !strings.Contains(err.Error(), "5000-01-01") || !strings.Contains(err.Error(), "Moshier") {
+		t.Errorf("error %v, want a *swisseph.Error naming the date and the ephemeris", err)
+	}
+	if _, err := swisseph.CalcPlanetFlags(year5000, swisseph.Sun, swisseph.FlagMoshier); err == nil {
+		t.Error("the library computes the Moshier ephemeris beyond MoshierEnd")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	if v := swisseph.Version(); !strings.HasPrefix(v, "2.") {
+		t.Errorf("Version() = %q, want 2.x", v)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // PlanetName
 // ---------------------------------------------------------------------------
@@ -117,6 +226,9 @@ func TestPlanetName(t *testing.T) {
 		{swisseph.Mars, "Mars"},
 		{swisseph.Jupiter, "Jupiter"},
 		{swisseph.Saturn, "Saturn"},
+		{swisseph.Cupido, "Cupido"},
+		{swisseph.Poseidon, "Poseidon"},
+		{swisseph.Transpluto, "Isis-Transpluto"},
 	}
 
 	for _, tc := range cases {
@@ -196,6 +308,74 @@ func TestCalcPlanet_AllPlanets(t *testing.T) {
 	}
 }
 
+// TestCalcPlanets checks that the batch call returns, in order, the same
+// positions as one CalcPlanetFlags call per planet, and fails like it
+// without a JPL file.
+func TestCalcPlanets(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	planets := []int{swisseph.Pluto, swisseph.Sun, swisseph.Moon, swisseph.TrueNode}
+	flags := swisseph.FlagSwissEph | swisseph.FlagSpeed
+
+	got, err := swisseph.CalcPlanets(jd, planets, flags)
+	if err != nil {
+		t.Fatalf("CalcPlanets error: %v", err)
+	}
+	if len(got) != len(planets) {
+		t.Fatalf("CalcPlanets returned %d positions, want %d", len(got), len(planets))
+	}
+	for i, pl := range planets {
+		want, err := swisseph.CalcPlanetFlags(jd, pl, flags)
+		if err != nil {
+			t.Fatalf("CalcPlanetFlags(%d) error: %v", pl, err)
+		}
+		if got[i] != want {
+			t.Errorf("CalcPlanets[%d] = %+v, want %+v", i, got[i], want)
+		}
+	}
+
+	if _, err := swisseph.CalcPlanets(jd, planets, swisseph.FlagJPL); err == nil {
+		t.Error("expected error: no JPL file in ../ephe")
+	}
+}
+
+func TestCalcPlanetsInto(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	planets := []int{swisseph.Sun, swisseph.Moon, swisseph.Mars}
+	flags := swisseph.FlagSwissEph | swisseph.FlagSpeed
+	want, err := swisseph.CalcPlanets(jd, planets, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := make([]swisseph.PlanetPos, len(planets))
+	if err := swisseph.CalcPlanetsInto(jd, planets, flags, pos); err != nil {
+		t.Fatalf("CalcPlanetsInto error: %v", err)
+	}
+	var one swisseph.PlanetPos
+	if err := swisseph.CalcPlanetInto(jd, swisseph.Mars, flags, &one); err != nil {
+		t.Fatalf("CalcPlanetInto error: %v", err)
+	}
+	for i := range want {
+		if pos[i] != want[i] {
+			t.Errorf("CalcPlanetsInto[%d] = %+v, want %+v", i, pos[i], want[i])
+		}
+	}
+	if one != want[2] {
+		t.Errorf("CalcPlanetInto = %+v, want %+v", one, want[2])
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		swisseph.CalcPlanetsInto(jd, planets, flags, pos)
+		swisseph.CalcPlanetInto(jd, swisseph.Mars, flags, &one)
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per call, want none", allocs)
+	}
+	if err := swisseph.CalcPlanetsInto(jd, planets, flags, pos[:2]); err == nil {
+		t.Error("expected error: fewer positions than planets")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // CalcHouses
 // ---------------------------------------------------------------------------
@@ -276,3 +456,662 @@ func TestCalcHouses_ASCMatchesCusp1(t *testing.T) {
 		t.Errorf("Ascendant (%.6f°) does not match Cusps[1] (%.6f°)", res.Ascendant, res.Cusps[1])
 	}
 }
+
+// ---------------------------------------------------------------------------
+// CalcPlanetCentric
+// ---------------------------------------------------------------------------
+
+// TestCalcPlanetCentric_EarthFromMars checks the planetocentric geometry:
+// Earth seen from Mars must lie opposite Mars seen from Earth, at the same
+// distance, to within light-time and aberration effects.
+func TestCalcPlanetCentric_EarthFromMars(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	flags := swisseph.FlagSwissEph | swisseph.FlagSpeed
+
+	earthFromMars, err := swisseph.CalcPlanetCentric(jd, swisseph.Earth, swisseph.Mars, flags)
+	if err != nil {
+		t.Fatalf("CalcPlanetCentric(Earth, Mars): %v", err)
+	}
+	marsFromEarth, err := swisseph.CalcPlanet(jd, swisseph.Mars)
+	if err != nil {
+		t.Fatalf("CalcPlanet(Mars): %v", err)
+	}
+
+	diff := math.Mod(earthFromMars.Longitude-marsFromEarth.Longitude+360, 360)
+	if math.Abs(diff-180) > 0.1 {
+		t.Errorf("Earth from Mars %.4f° vs Mars from Earth %.4f°: separation %.4f°, want ~180°",
+			earthFromMars.Longitude, marsFromEarth.Longitude, diff)
+	}
+	if math.Abs(earthFromMars.Distance-marsFromEarth.Distance) > 1e-3 {
+		t.Errorf("distances differ: %.6f AU vs %.6f AU", earthFromMars.Distance, marsFromEarth.Distance)
+	}
+
+	if _, err := swisseph.CalcPlanetCentric(jd, swisseph.Mars, swisseph.Mars, flags); err == nil {
+		t.Error("expected error when planet and centre are the same")
+	}
+}
+
+// TestCalcPlanetOptions checks that the options give the flags they name,
+// that each correction moves the Moon, and that contradictory options fail.
+func TestCalcPlanetOptions(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	if got, want := (swisseph.CalcOptions{}).Flags(), swisseph.FlagSwissEph; got != want {
+		t.Errorf("CalcOptions{}.Flags() = %#x, want %#x", got, want)
+	}
+	opts := swisseph.CalcOptions{Ephemeris: swisseph.FlagMoshier, Speed: true, TruePos: true, NoNutation: true}
+	if got, want := opts.Flags(), swisseph.FlagMoshier|swisseph.FlagSpeed|swisseph.FlagTruePos|swisseph.FlagNoNutation; got != want {
+		t.Errorf("Flags() = %#x, want %#x", got, want)
+	}
+
+	base, err := swisseph.CalcPlanetOptions(jd, swisseph.Moon, swisseph.CalcOptions{Speed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, _ := swisseph.CalcPlanet(jd, swisseph.Moon); plain != base {
+		t.Errorf("CalcPlanet = %+v, CalcPlanetOptions{Speed} = %+v", plain, base)
+	}
+	for name, opts := range map[string]swisseph.CalcOptions{
+		"TruePos":      {TruePos: true},
+		"NoAberration": {NoAberration: true},
+		"J2000":        {J2000: true},
+		"NoNutation":   {NoNutation: true},
+	} {
+		pos, err := swisseph.CalcPlanetOptions(jd, swisseph.Moon, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if pos.Longitude == base.Longitude {
+			t.Errorf("%s: longitude %.6f°, the same as without it", name, pos.Longitude)
+		}
+	}
+
+	for _, opts := range []swisseph.CalcOptions{
+		{Heliocentric: true, Barycentric: true},
+		{Ephemeris: swisseph.FlagMoshier | swisseph.FlagJPL},
+	} {
+		if _, err := swisseph.CalcPlanetOptions(jd, swisseph.Mars, opts); err == nil {
+			t.Errorf("CalcPlanetOptions(%+v): expected error", opts)
+		}
+	}
+}
+
+// TestCalcPlanetFlags_JPLMissing checks that requesting the JPL ephemeris
+// without a DE file is an error rather than a silent fallback.
+func TestCalcPlanetFlags_JPLMissing(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	_, err := swisseph.CalcPlanetFlags(jd, swisseph.Sun, swisseph.FlagJPL|swisseph.FlagSpeed)
+	if err == nil {
+		t.Fatal("expected error: no JPL file in ../ephe")
+	}
+	var se *swisseph.Error
+	if !errors.As(err, &se) {
+		t.Errorf("error %v is a %T, want *swisseph.Error", err, err)
+	}
+}
+
+// TestCalcPlanetSource checks that the source names the file a position
+// was read from, and reports the Moshier fallback when there is none.
+func TestCalcPlanetSource(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	for _, c := range []struct {
+		planet int
+		file   string
+	}{
+		{swisseph.Sun, "sepl_18.se1"},
+		{swisseph.Moon, "semo_18.se1"},
+		{swisseph.Chiron, "seas_18.se1"},
+		{swisseph.MeanNode, ""},
+	} {
+		_, src, err := swisseph.CalcPlanetSource(jd, c.planet, swisseph.FlagSwissEph|swisseph.FlagSpeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src.Ephemeris != swisseph.FlagSwissEph || src.File != c.file {
+			t.Errorf("planet %d: source %+v, want %s", c.planet, src, c.file)
+		}
+		if c.file != "" && (jd < src.Start || jd > src.End) {
+			t.Errorf("planet %d: %s covers %f to %f, not %f", c.planet, src.File, src.Start, src.End, jd)
+		}
+	}
+
+	swisseph.SetEphePath(t.TempDir())
+	defer swisseph.SetEphePath("../ephe")
+	_, src, err := swisseph.CalcPlanetSource(jd, swisseph.Sun, swisseph.FlagSwissEph|swisseph.FlagSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Ephemeris != swisseph.FlagMoshier || src.File != "" {
+		t.Errorf("without files: source %+v, want the Moshier ephemeris", src)
+	}
+}
+
+// TestCalcPlanet_Warning checks that a position computed in spite of a
+// missing file carries the library's warning, one at a time or in a batch,
+// and that one read from the files carries none.
+func TestCalcPlanet_Warning(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	planets := []int{swisseph.Sun, swisseph.Moon}
+	pos, err := swisseph.CalcPlanets(jd, planets, swisseph.FlagSwissEph|swisseph.FlagSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range pos {
+		if p.Warning != "" {
+			t.Errorf("planet %d: warning %q with the files", planets[i], p.Warning)
+		}
+	}
+
+	swisseph.SetEphePath(t.TempDir())
+	defer swisseph.SetEphePath("../ephe")
+	one, err := swisseph.CalcPlanetFlags(jd, swisseph.Sun, swisseph.FlagSwissEph|swisseph.FlagSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos, err = swisseph.CalcPlanets(jd, planets, swisseph.FlagSwissEph|swisseph.FlagSpeed); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{one.Warning, pos[0].Warning, pos[1].Warning} {
+		if !strings.Contains(w, "sepl_18.se1") || !strings.Contains(w, "Moshier") || strings.Contains(w, "\n") {
+			t.Errorf("warning %q, want one line naming the file and the Moshier fallback", w)
+		}
+	}
+}
+
+// TestCalcPlanet_AsteroidMissing checks that an asteroid without its file
+// fails naming both the files it could take and the subdirectory.
+func TestCalcPlanet_AsteroidMissing(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	for _, calc := range []func() error{
+		func() error { _, err := swisseph.CalcPlanet(jd, swisseph.AstOffset+433); return err },
+		func() error {
+			_, err := swisseph.CalcPlanets(jd, []int{swisseph.Sun, swisseph.AstOffset + 433}, swisseph.FlagSwissEph)
+			return err
+		},
+	} {
+		err := calc()
+		if err == nil {
+			t.Fatal("expected error: no file for asteroid 433 in ../ephe")
+		}
+		for _, want := range []string{"asteroid 433", "se00433.se1", "se00433s.se1", "../ephe", "ast0"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not name %s", err, want)
+			}
+		}
+	}
+}
+
+// TestAsteroidFiles checks the names of the asteroid files, and that
+// ListAsteroids finds them where the library looks.
+func TestAsteroidFiles(t *testing.T) {
+	for _, c := range []struct {
+		n           int
+		short, want string
+	}{
+		{433, "se00433s.se1", "se00433.se1"}, {136199, "s136199s.se1", "s136199.se1"},
+	} {
+		if got := swisseph.AsteroidFile(c.n, false); got != c.want {
+			t.Errorf("AsteroidFile(%d, false) = %q, want %q", c.n, got, c.want)
+		}
+		if got := swisseph.AsteroidFile(c.n, true); got != c.short {
+			t.Errorf("AsteroidFile(%d, true) = %q, want %q", c.n, got, c.short)
+		}
+	}
+	if got := swisseph.AsteroidDir(136199); got != "ast136" {
+		t.Errorf("AsteroidDir(136199) = %q, want ast136", got)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"se00433s.se1", "ast0/se00433.se1", "ast1/se01566s.se1", "ast0/se01862.se1", "se00433.txt", "seas_18.se1"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := swisseph.ListAsteroids(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []swisseph.AsteroidEphe{
+		{Number: 433, Path: filepath.Join("ast0", "se00433.se1"), Used: true},
+		{Number: 433, Path: "se00433s.se1", Short: true},
+		{Number: 1566, Path: filepath.Join("ast1", "se01566s.se1"), Short: true, Used: true},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ListAsteroids = %v, want %v", got, want)
+	}
+	if _, err := swisseph.ListAsteroids(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ListAsteroids of a missing directory: no error")
+	}
+}
+
+// TestHouseSystems checks the library's names, and that every system
+// HouseSystems lists casts twelve cusps and has a name of its own.
+func TestHouseSystems(t *testing.T) {
+	for code, want := range map[byte]string{'K': "Koch", 'O': "Porphyry", 'i': "Sunshine/alt.", 'Z': "Placidus"} {
+		if got := swisseph.HouseName(code); got != want {
+			t.Errorf("HouseName(%q) = %q, want %q", code, got, want)
+		}
+	}
+	codes := swisseph.HouseSystems()
+	has := map[byte]bool{}
+	names := map[string]byte{}
+	for _, c := range codes {
+		has[c] = true
+		if prev, ok := names[swisseph.HouseName(c)]; ok {
+			t.Errorf("HouseSystems() = %q: %q and %q are both %s", codes, prev, c, swisseph.HouseName(c))
+		}
+		names[swisseph.HouseName(c)] = c
+		h, err := swisseph.CalcHouses(swisseph.JulDay(2000, 1, 1, 12), 51.5, -0.12, c)
+		if err != nil {
+			t.Errorf("CalcHouses(%q): %v", c, err)
+			continue
+		}
+		for i := 1; i <= 12; i++ {
+			if !(h.Cusps[i] >= 0 && h.Cusps[i] < 360) {
+				t.Errorf("CalcHouses(%q): cusp %d = %v", c, i, h.Cusps[i])
+			}
+		}
+	}
+	for _, c := range []byte{'P', 'K', 'W', 'R', 'A', 'C', 'O', 'B', 'i'} {
+		if !has[c] {
+			t.Errorf("HouseSystems() = %q, missing %q", codes, c)
+		}
+	}
+	for _, c := range []byte{'G', 'Z', 'p', 'E'} {
+		if has[c] {
+			t.Errorf("HouseSystems() = %q, includes %q", codes, c)
+		}
+	}
+	if _, err := swisseph.CalcHouses(swisseph.JulDay(2000, 1, 1, 12), 51.5, -0.12, 'G'); err == nil {
+		t.Error("CalcHouses('G'): no error for the Gauquelin sectors")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// CalcHousesARMC / Obliquity
+// ---------------------------------------------------------------------------
+
+// TestCalcHouses_Polar checks that Placidus and Koch fail with a
+// *PolarError within the polar circle, where Porphyry succeeds.
+func TestCalcHouses_Polar(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	for _, hsys := range []byte{swisseph.HousePlacidus, swisseph.HouseKoch} {
+		_, err := swisseph.CalcHouses(jd, 75, 20, hsys)
+		var polar *swisseph.PolarError
+		if !errors.As(err, &polar) || polar.System != hsys || polar.Lat != 75 {
+			t.Errorf("CalcHouses(%c) at 75°N: error %v, want a *PolarError", hsys, err)
+		}
+		if _, err := swisseph.CalcHouses(jd, 60, 20, hsys); err != nil {
+			t.Errorf("CalcHouses(%c) at 60°N: %v", hsys, err)
+		}
+	}
+	if _, err := swisseph.CalcHouses(jd, 75, 20, 'O'); err != nil {
+		t.Errorf("CalcHouses(Porphyry) at 75°N: %v", err)
+	}
+}
+
+// TestCalcHousesARMC_MatchesCalcHouses recasts a dated chart from its own
+// ARMC and obliquity and expects the same angles.
+func TestCalcHousesARMC_MatchesCalcHouses(t *testing.T) {
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+	eps, err := swisseph.Obliquity(jd)
+	if err != nil {
+		t.Fatalf("Obliquity: %v", err)
+	}
+	if math.Abs(eps-23.44) > 0.01 {
+		t.Errorf("Obliquity(J2000) = %.4f°, want about 23.44°", eps)
+	}
+
+	want, err := swisseph.CalcHouses(jd, 51.5, -0.12, swisseph.HousePlacidus)
+	if err != nil {
+		t.Fatalf("CalcHouses: %v", err)
+	}
+	got, err := swisseph.CalcHousesARMC(want.ARMC, 51.5, eps, swisseph.HousePlacidus)
+	if err != nil {
+		t.Fatalf("CalcHousesARMC: %v", err)
+	}
+	if math.Abs(got.Ascendant-want.Ascendant) > 1e-6 || math.Abs(got.MC-want.MC) > 1e-6 {
+		t.Errorf("ARMC chart Asc %.6f MC %.6f, dated chart Asc %.6f MC %.6f",
+			got.Ascendant, got.MC, want.Ascendant, want.MC)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Sidereal mode
+// ---------------------------------------------------------------------------
+
+// TestSidereal checks that sidereal planets and houses are the tropical
+// ones less the ayanamsa.
+func TestSidereal(t *testing.T) {
+	swisseph.SetSidMode(swisseph.SidmLahiri)
+	jd := swisseph.JulDay(2000, 1, 1, 12.0)
+
+	aya, err := swisseph.Ayanamsa(jd, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("Ayanamsa: %v", err)
+	}
+	if math.Abs(aya-23.85) > 0.01 {
+		t.Errorf("Lahiri ayanamsa at J2000 = %.4f°, want about 23.85°", aya)
+	}
+
+	trop, err := swisseph.CalcPlanetFlags(jd, swisseph.Sun, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := swisseph.CalcPlanetFlags(jd, swisseph.Sun, swisseph.FlagSwissEph|swisseph.FlagSidereal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := math.Mod(trop.Longitude-sid.Longitude+360, 360); math.Abs(d-aya) > 1e-3 {
+		t.Errorf("tropical - sidereal Sun = %.4f°, want ayanamsa %.4f°", d, aya)
+	}
+
+	th, err := swisseph.CalcHouses(jd, 51.5, -0.12, swisseph.HousePlacidus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := swisseph.CalcHousesFlags(jd, 51.5, -0.12, swisseph.HousePlacidus, swisseph.FlagSidereal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := math.Mod(th.Ascendant-sh.Ascendant+360, 360); math.Abs(d-aya) > 1e-3 {
+		t.Errorf("tropical - sidereal Ascendant = %.4f°, want ayanamsa %.4f°", d, aya)
+	}
+
+	// A user-defined ayanamsa is its value at its epoch, and grows by
+	// precession, about 50″ a year, from there.
+	swisseph.SetSidModeUser(2451545.0, 24.83)
+	defer swisseph.SetSidMode(swisseph.SidmLahiri)
+	if aya, err := swisseph.Ayanamsa(jd, swisseph.FlagSwissEph); err != nil || math.Abs(aya-24.83) > 0.01 {
+		t.Errorf("user ayanamsa at J2000 = %.4f°, %v; want 24.83°", aya, err)
+	}
+	later := swisseph.JulDay(2100, 1, 1, 12.0)
+	if aya, err := swisseph.Ayanamsa(later, swisseph.FlagSwissEph); err != nil || math.Abs(aya-24.83-1.397) > 0.01 {
+		t.Errorf("user ayanamsa in 2100 = %.4f°, %v; want about 26.23°", aya, err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// RiseTrans
+// ---------------------------------------------------------------------------
+
+// TestRiseTrans checks London sunrise and sunset on the 2024 March equinox
+// (06:03 and 18:14 GMT per almanacs) and the midnight sun.
+func TestRiseTrans(t *testing.T) {
+	jd := swisseph.JulDay(2024, 3, 20, 0)
+	rise, err := swisseph.RiseTrans(jd, swisseph.Sun, 51.5, -0.13, swisseph.CalcRise, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("RiseTrans rise: %v", err)
+	}
+	set, err := swisseph.RiseTrans(jd, swisseph.Sun, 51.5, -0.13, swisseph.CalcSet, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("RiseTrans set: %v", err)
+	}
+	if h := (rise - jd) * 24; math.Abs(h-(6+3.0/60)) > 3.0/60 {
+		t.Errorf("sunrise at %.3fh UT, want about 06:03", h)
+	}
+	if h := (set - jd) * 24; math.Abs(h-(18+14.0/60)) > 3.0/60 {
+		t.Errorf("sunset at %.3fh UT, want about 18:14", h)
+	}
+
+	midsummer := swisseph.JulDay(2024, 6, 21, 0)
+	if _, err := swisseph.RiseTrans(midsummer, swisseph.Sun, 78.2, 15.6, swisseph.CalcSet, swisseph.FlagSwissEph); err != swisseph.ErrNoRiseSet {
+		t.Errorf("Svalbard sunset at midsummer: err = %v, want ErrNoRiseSet", err)
+	}
+}
+
+func TestSolCross(t *testing.T) {
+	// The March equinox of 2024 was at 03:06 UT on 20 March.
+	jd, err := swisseph.SolCross(0, swisseph.JulDay(2024, 1, 1, 0), swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("SolCross: %v", err)
+	}
+	if want := swisseph.JulDay(2024, 3, 20, 3+6.0/60); math.Abs(jd-want) > 2.0/1440 {
+		t.Errorf("equinox at JD %.5f, want %.5f", jd, want)
+	}
+
+	start := swisseph.JulDay(2024, 3, 1, 0)
+	jd, err = swisseph.MoonCross(90, start, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("MoonCross: %v", err)
+	}
+	pos, err := swisseph.CalcPlanet(jd, swisseph.Moon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jd <= start || jd > start+28 || math.Abs(pos.Longitude-90) > 1e-4 {
+		t.Errorf("Moon at %.5f° at JD %.5f, want 90° within a month of %.1f", pos.Longitude, jd, start)
+	}
+}
+
+func TestLMTToLAT(t *testing.T) {
+	// Early in November a sundial is about 16.4 minutes ahead of the
+	// mean Sun; in mid-February it is about 14.2 minutes behind.
+	for _, c := range []struct {
+		month, day int
+		want       float64 // the equation of time, in minutes
+	}{
+		{11, 3, 16.4},
+		{2, 11, -14.2},
+	} {
+		lmt := swisseph.JulDay(2024, c.month, c.day, 12)
+		lat, err := swisseph.LMTToLAT(lmt, 13.4)
+		if err != nil {
+			t.Fatalf("LMTToLAT: %v", err)
+		}
+		if eot := (lat - lmt) * 1440; math.Abs(eot-c.want) > 0.2 {
+			t.Errorf("equation of time on %d/%d = %+.2f min, want %+.1f", c.month, c.day, eot, c.want)
+		}
+		back, err := swisseph.LATToLMT(lat, 13.4)
+		if err != nil {
+			t.Fatalf("LATToLMT: %v", err)
+		}
+		if math.Abs(back-lmt) > 1.0/86400 {
+			t.Errorf("LATToLMT(LMTToLAT(%.5f)) = %.5f", lmt, back)
+		}
+	}
+}
+
+func TestOrbitDistances(t *testing.T) {
+	// The full moon of 18 September 2024 fell a few hours after perigee,
+	// at 357,490 km, with the apogees either side over 400,000 km away.
+	jd := swisseph.JulDay(2024, 9, 18, 2+34.0/60)
+	d, err := swisseph.OrbitDistances(jd, swisseph.Moon, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("OrbitDistances: %v", err)
+	}
+	const km = 1.495978707e8
+	if !(d.Min <= d.True && d.True < d.Max) || math.Abs(d.True*km-357490) > 100 || d.Max*km < 400000 {
+		t.Errorf("Moon distances = %.0f, %.0f, %.0f km", d.Min*km, d.True*km, d.Max*km)
+	}
+	pos, err := swisseph.CalcPlanet(jd, swisseph.Moon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(d.True-pos.Distance) > 1e-8 {
+		t.Errorf("true distance %.9f AU, CalcPlanet %.9f", d.True, pos.Distance)
+	}
+}
+
+func TestCotrans(t *testing.T) {
+	const eps = 23.44
+	// The summer solstice point has the obliquity for its declination.
+	if ra, dec := swisseph.Cotrans(90, 0, -eps); math.Abs(ra-90) > 1e-9 || math.Abs(dec-eps) > 1e-9 {
+		t.Errorf("Cotrans(90, 0) = %v, %v; want 90, %v", ra, dec, eps)
+	}
+	ra, dec := swisseph.Cotrans(200, -3, -eps)
+	if lon, lat := swisseph.Cotrans(ra, dec, eps); math.Abs(lon-200) > 1e-9 || math.Abs(lat+3) > 1e-9 {
+		t.Errorf("Cotrans back = %v, %v; want 200, -3", lon, lat)
+	}
+
+	// At the equinox a degree of longitude is cos ε of right ascension and
+	// sin ε of declination; at the solstice it is 1/cos ε of right
+	// ascension and none of declination.
+	rad := math.Pi / 180
+	for _, c := range []struct{ lon, speedRA, speedDec float64 }{
+		{0, math.Cos(eps * rad), math.Sin(eps * rad)},
+		{90, 1 / math.Cos(eps*rad), 0},
+	} {
+		pos := swisseph.PlanetPos{Longitude: c.lon, Distance: 1, SpeedLon: 1, SpeedDistance: 0.01}
+		got := swisseph.CotransSpeed(pos, -eps)
+		if math.Abs(got.SpeedLon-c.speedRA) > 1e-6 || math.Abs(got.SpeedLat-c.speedDec) > 1e-6 {
+			t.Errorf("CotransSpeed at %v° = %+v, want speeds %.4f, %.4f", c.lon, got, c.speedRA, c.speedDec)
+		}
+		if got.Distance != 1 || got.SpeedDistance != 0.01 {
+			t.Errorf("CotransSpeed changed the distance: %+v", got)
+		}
+	}
+}
+
+func TestVisLimitMag(t *testing.T) {
+	// London on 10 January 2025, Venus at its greatest elongation in the
+	// evening sky: lost in daylight at noon, bright in the dusk at 17:30,
+	// when Mercury, a morning star, has set.
+	noon := swisseph.JulDay(2025, 1, 10, 12)
+	dusk := swisseph.JulDay(2025, 1, 10, 17.5)
+	v, err := swisseph.VisLimitMag(noon, swisseph.Venus, 51.5, -0.13, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("VisLimitMag: %v", err)
+	}
+	if v.Visible() || v.SunAltitude < 10 || math.Abs(v.Magnitude+4.5) > 0.2 {
+		t.Errorf("Venus at noon: %+v, want it too faint for the sky", v)
+	}
+	v, err = swisseph.VisLimitMag(dusk, swisseph.Venus, 51.5, -0.13, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("VisLimitMag: %v", err)
+	}
+	if !v.Visible() || v.SunAltitude > -6 {
+		t.Errorf("Venus at dusk: %+v, want it visible", v)
+	}
+	az, alt, err := swisseph.AzAlt(dusk, swisseph.Venus, 51.5, -0.13, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatalf("AzAlt: %v", err)
+	}
+	if math.Abs(az-v.Azimuth) > 0.1 || alt < v.Altitude || alt-v.Altitude > 0.1 {
+		t.Errorf("AzAlt = %.2f°, %.2f°; VisLimitMag %.2f°, %.2f° and a little refraction", az, alt, v.Azimuth, v.Altitude)
+	}
+
+	if _, err := swisseph.VisLimitMag(dusk, swisseph.Mercury, 51.5, -0.13, swisseph.FlagSwissEph); err != swisseph.ErrBelowHorizon {
+		t.Errorf("Mercury at dusk: err = %v, want ErrBelowHorizon", err)
+	}
+	if _, alt, err := swisseph.AzAlt(dusk, swisseph.Mercury, 51.5, -0.13, swisseph.FlagSwissEph); err != nil || alt > -20 {
+		t.Errorf("Mercury at dusk: altitude %.2f°, err %v; want well below the horizon", alt, err)
+	}
+}
+
+func TestSample(t *testing.T) {
+	from, to := swisseph.JulDay(2024, 1, 1, 0), swisseph.JulDay(2025, 1, 1, 0)
+	const maxErr = 1e-4
+	for _, pl := range []int{swisseph.Moon, swisseph.Mercury, swisseph.TrueNode} {
+		s, err := swisseph.Sample(pl, from, to, maxErr, swisseph.FlagSwissEph)
+		if err != nil {
+			t.Fatalf("Sample(%d): %v", pl, err)
+		}
+		if n := len(s.JD); s.JD[0] != from || s.JD[n-1] != to || n > 2000 {
+			t.Errorf("Sample(%d): %d samples from %v to %v, want a few hundred from %v to %v", pl, n, s.JD[0], s.JD[n-1], from, to)
+		}
+		t.Logf("%s: %d samples", swisseph.PlanetName(pl), len(s.JD))
+		// Check between the samples, at times no sample falls on.
+		for jd := from + 0.123; jd < to; jd += 0.77 {
+			got, ok := s.At(jd)
+			if !ok {
+				t.Fatalf("At(%v) outside the samples", jd)
+			}
+			want, err := swisseph.CalcPlanet(jd, pl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := math.Abs(math.Remainder(got.Longitude-want.Longitude, 360)); d > 2*maxErr {
+				t.Errorf("%s at %v: longitude %v, want %v", swisseph.PlanetName(pl), jd, got.Longitude, want.Longitude)
+				break
+			}
+			if d := math.Abs(got.SpeedLon - want.SpeedLon); d > 0.01 {
+				t.Errorf("%s at %v: speed %v, want %v", swisseph.PlanetName(pl), jd, got.SpeedLon, want.SpeedLon)
+				break
+			}
+		}
+	}
+
+	s, err := swisseph.Sample(swisseph.Sun, from, from+1, 1e-3, swisseph.FlagSwissEph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.At(from + 2); ok {
+		t.Error("At beyond the samples: ok, want not ok")
+	}
+	if got, _ := s.At(from + 0.5); got.Warning != "" {
+		t.Errorf("At with the files: warning %q", got.Warning)
+	}
+
+	// Without the files, the samples keep the Moshier fallback's warning.
+	swisseph.SetEphePath(t.TempDir())
+	s, err = swisseph.Sample(swisseph.Sun, from, from+1, 1e-3, swisseph.FlagSwissEph)
+	swisseph.SetEphePath("../ephe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jd := range []float64{from, from + 0.5} {
+		if got, _ := s.At(jd); !strings.Contains(got.Warning, "Moshier") {
+			t.Errorf("At(%v) without the files: warning %q, want the Moshier fallback", jd, got.Warning)
+		}
+	}
+	if _, err := swisseph.Sample(swisseph.Sun, from, from, 1e-3, swisseph.FlagSwissEph); err == nil {
+		t.Error("Sample of an empty range: no error")
+	}
+	if _, err := swisseph.Sample(swisseph.Sun, from, to, 0, swisseph.FlagSwissEph); err == nil {
+		t.Error("Sample with no error bound: no error")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Benchmarks
+// ---------------------------------------------------------------------------
+
+var benchPlanets = []int{
+	swisseph.Sun, swisseph.Moon, swisseph.Mercury, swisseph.Venus, swisseph.Mars,
+	swisseph.Jupiter, swisseph.Saturn, swisseph.Uranus, swisseph.Neptune, swisseph.Pluto,
+}
+
+// A day at a time over a few years, as a scan steps.
+func benchJD(i int) float64 { return 2451545.0 + float64(i%2000) }
+
+func BenchmarkCalcPlanet(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		if _, err := swisseph.CalcPlanet(benchJD(i), swisseph.Mars); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalcPlanetInto(b *testing.B) {
+	b.ReportAllocs()
+	var pos swisseph.PlanetPos
+	for i := 0; b.Loop(); i++ {
+		if err := swisseph.CalcPlanetInto(benchJD(i), swisseph.Mars, swisseph.FlagSwissEph|swisseph.FlagSpeed, &pos); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalcPlanets(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		if _, err := swisseph.CalcPlanets(benchJD(i), benchPlanets, swisseph.FlagSwissEph|swisseph.FlagSpeed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalcPlanetsInto(b *testing.B) {
+	b.ReportAllocs()
+	pos := make([]swisseph.PlanetPos, len(benchPlanets))
+	for i := 0; b.Loop(); i++ {
+		if err := swisseph.CalcPlanetsInto(benchJD(i), benchPlanets, swisseph.FlagSwissEph|swisseph.FlagSpeed, pos); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package swisseph
+
+/*
+#include "swephexp.h"
+#include <stdlib.h>
+
+// az_alt computes planet's topocentric position at geopos (longitude,
+// latitude, height) and turns it into azimuth and altitude in xaz, with
+// the library's default refraction. It returns swe_calc_ut's flag.
+static int az_alt(double tjd, int ipl, int flags, double *geopos, double *xaz, char *serr) {
+	double xx[6];
+	int ret;
+	swe_set_topo(geopos[0], geopos[1], geopos[2]);
+	ret = swe_calc_ut(tjd, ipl, flags | SEFLG_TOPOCTR, xx, serr);
+	if (ret < 0) {
+		return ret;
+	}
+	swe_azalt(tjd, SE_ECL2HOR, geopos, 0, 15, xx, xaz);
+	return ret;
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// ErrBelowHorizon is returned by VisLimitMag when the body is below the
+// horizon, where it cannot be seen whatever its brightness.
+var ErrBelowHorizon = errors.New("swisseph: body is below the horizon")
+
+// visNames are the names swe_vis_limit_mag knows the planets by.
+var visNames = map[int]string{
+	Moon: "moon", Mercury: "mercury", Venus: "venus", Mars: "mars",
+	Jupiter: "jupiter", Saturn: "saturn", Uranus: "uranus", Neptune: "neptune",
+}
+
+// Visibility is the sight of a body in the sky at a moment and place, as
+// VisLimitMag reports it.
+type Visibility struct {
+	// LimitMag is the faintest magnitude the eye can see at the body's
+	// place in the sky, given the brightness of the sky there from the
+	// Sun, the Moon and twilight.
+	LimitMag  float64
+	Magnitude float64 // the body's apparent magnitude
+	Altitude  float64 // above the horizon, without refraction, degrees
+	Azimuth   float64 // degrees east of north
+	// SunAltitude and MoonAltitude are those of the Sun and Moon, which
+	// light the sky.
+	SunAltitude  float64
+	MoonAltitude float64
+	// Scotopic is set when the sky is dark enough for night vision, which
+	// sees fainter bodies.
+	Scotopic bool
+}
+
+// Visible reports whether the body is bright enough to be seen: brighter,
+// that is of a lower magnitude, than the limit.
+func (v Visibility) Visible() bool { return v.Magnitude < v.LimitMag }
+
+// VisLimitMag returns the visibility of planet (Moon to Neptune) to the
+// naked eye at the given Julian Day (UT) and place, for an observer of 36
+// with normal sight and a standard atmosphere at sea level. It returns
+// ErrBelowHorizon if the planet's true altitude is negative, even if
+// refraction lifts it into view. flags selects the ephemeris as for
+// CalcPlanetFlags.
+func VisLimitMag(tjdUT float64, planet int, geoLat, geoLon float64, flags int) (Visibility, error) {
+	name, ok := visNames[planet]
+	if !ok {
+		return Visibility{}, errorf("swe_vis_limit_mag: no visibility for planet %d", planet)
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	geopos := [3]C.double{C.double(geoLon), C.double(geoLat), 0}
+	var datm [4]C.double
+	var dobs [6]C.double
+	var dret [10]C.double
+	var serr [256]C.char
+
+	mu.Lock()
+	ret := C.swe_vis_limit_mag(C.double(tjdUT), &geopos[0], &datm[0], &dobs[0], cname,
+		C.int32(flags&ephemerisFlags), &dret[0], &serr[0])
+	mu.Unlock()
+
+	switch {
+	case int(ret) == -2:
+		return Visibility{}, ErrBelowHorizon
+	case int(ret) < 0:
+		return Visibility{}, errorf("swe_vis_limit_mag: %s", C.GoString(&serr[0]))
+	}
+	return Visibility{
+		LimitMag:     float64(dret[0]),
+		Magnitude:    float64(dret[7]),
+		Altitude:     float64(dret[1]),
+		Azimuth:      float64(dret[2]),
+		SunAltitude:  float64(dret[3]),
+		MoonAltitude: float64(dret[5]),
+		Scotopic:     int(ret)&C.SE_SCOTOPIC_FLAG != 0,
+	}, nil
+}
+
+// AzAlt returns the azimuth (degrees east of north) and apparent altitude
+// of planet, as seen from the surface of the Earth at the given place at
+// the Julian Day (UT), with standard refraction. flags selects the
+// ephemeris as for CalcPlanetFlags.
+func AzAlt(tjdUT float64, planet int, geoLat, geoLon float64, flags int) (azimuth, altitude float64, err error) {
+	geopos := [3]C.double{C.double(geoLon), C.double(geoLat), 0}
+	var xaz [3]C.double
+	var serr [256]C.char
+	flags &= ephemerisFlags
+
+	mu.Lock()
+	defer mu.Unlock()
+	ret := C.az_alt(C.double(tjdUT), C.int(planet), C.int(flags), &geopos[0], &xaz[0], &serr[0])
+
+	if int(ret) < 0 {
+		return 0, 0, calcError("swe_calc_ut", planet, C.GoString(&serr[0]))
+	}
+	if err := checkJPL(flags, int(ret), &serr[0]); err != nil {
+		return 0, 0, err
+	}
+	return fromSouth(float64(xaz[0])), float64(xaz[2]), nil
+}
+
+// ephemerisFlags are the flags that select the ephemeris, the only ones
+// the horizon calculations take: their positions are topocentric and
+// tropical whatever else a caller's flags ask for.
+const ephemerisFlags = FlagSwissEph | FlagMoshier | FlagJPL
+
+// fromSouth turns the library's azimuth, measured from the south through
+// the west, into the usual one from the north through the east.
+func fromSouth(az float64) float64 {
+	return angle.Norm360(az + 180)
+}
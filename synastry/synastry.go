@@ -0,0 +1,94 @@
+// Package synastry compares two natal charts: the aspects between one
+// person's planets and the other's, and where each person's planets fall in
+// the other's houses.
+package synastry
+
+import (
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+)
+
+// Body is a named point of a chart with its ecliptic longitude.
+type Body struct {
+	Name      string
+	Longitude float64
+}
+
+// Chart is the part of a natal chart synastry needs. Cusps uses indexes
+// 1-12 for houses 1-12, as in ephemeris.HouseResult.
+type Chart struct {
+	Bodies []Body
+	Cusps  [13]float64
+}
+
+// InterAspect is an aspect between a body of chart A and a body of chart B.
+type InterAspect struct {
+	A, B   Body
+	Aspect aspects.Aspect
+	Orb    float64 // distance from exact, in degrees
+}
+
+// Overlay places a body of one chart in a house of the other.
+type Overlay struct {
+	Body  Body
+	House int
+}
+
+// Result is the comparison of chart A with chart B.
+type Result struct {
+	Aspects []InterAspect // ordered by A's bodies, then B's
+	AInB    []Overlay     // A's bodies in B's houses
+	BInA    []Overlay     // B's bodies in A's houses
+	// Grid[i][j] is the aspect between A.Bodies[i] and B.Bodies[j], or
+	// nil if they are not in aspect.
+	Grid [][]*InterAspect
+}
+
+// Compare computes the inter-aspects, house overlays and aspect grid of
+// two charts using the aspects as (with their orbs).
+func Compare(a, b Chart, as []aspects.Aspect) Result {
+	var r Result
+	var cells [][2]int
+	for i, ba := range a.Bodies {
+		for j, bb := range b.Bodies {
+			asp, orb, ok := aspects.Between(ba.Longitude, bb.Longitude, as)
+			if !ok {
+				continue
+			}
+			r.Aspects = append(r.Aspects, InterAspect{A: ba, B: bb, Aspect: asp, Orb: orb})
+			cells = append(cells, [2]int{i, j})
+		}
+	}
+	// Point the grid into Aspects only once the slice has stopped growing.
+	r.Grid = make([][]*InterAspect, len(a.Bodies))
+	for i := range r.Grid {
+		r.Grid[i] = make([]*InterAspect, len(b.Bodies))
+	}
+	for k, c := range cells {
+		r.Grid[c[0]][c[1]] = &r.Aspects[k]
+	}
+	r.AInB = overlays(a.Bodies, b.Cusps)
+	r.BInA = overlays(b.Bodies, a.Cusps)
+	return r
+}
+
+func overlays(bodies []Body, cusps [13]float64) []Overlay {
+	out := make([]Overlay, len(bodies))
+	for i, b := range bodies {
+		out[i] = Overlay{Body: b, House: House(b.Longitude, cusps)}
+	}
+	return out
+}
+
+// House returns the house (1-12) containing an ecliptic longitude, given
+// cusps at indexes 1-12. A longitude exactly on a cusp belongs to the house
+// that cusp begins.
+func House(lon float64, cusps [13]float64) int {
+	for h := 1; h <= 12; h++ {
+		next := cusps[h%12+1]
+		if angle.Norm360(lon-cusps[h]) < angle.Norm360(next-cusps[h]) {
+			return h
+		}
+	}
+	return 12 // unreachable for distinct cusps
+}
@@ -0,0 +1,73 @@
+package synastry_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/synastry"
+)
+
+// equalCusps returns cusps of 30° houses starting at asc.
+func equalCusps(asc float64) [13]float64 {
+	var c [13]float64
+	for h := 1; h <= 12; h++ {
+		c[h] = math.Mod(asc+float64(h-1)*30, 360)
+	}
+	return c
+}
+
+func TestHouse(t *testing.T) {
+	cusps := equalCusps(350)
+	cases := []struct {
+		lon  float64
+		want int
+	}{
+		{350, 1}, {5, 1}, {19.99, 1}, {20, 2}, {349.9, 12}, {170, 7},
+	}
+	for _, tc := range cases {
+		if got := synastry.House(tc.lon, cusps); got != tc.want {
+			t.Errorf("House(%v) = %d, want %d", tc.lon, got, tc.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := synastry.Chart{
+		Bodies: []synastry.Body{{"Sun", 10}, {"Moon", 100}},
+		Cusps:  equalCusps(0),
+	}
+	b := synastry.Chart{
+		Bodies: []synastry.Body{{"Venus", 13}, {"Mars", 190}},
+		Cusps:  equalCusps(90),
+	}
+	r := synastry.Compare(a, b, aspects.Major)
+
+	// Sun-Venus conjunction (3°), Sun-Mars opposition (0°), Moon-Venus
+	// square (3°), Moon-Mars square (0°).
+	if len(r.Aspects) != 4 {
+		t.Fatalf("got %d aspects, want 4: %+v", len(r.Aspects), r.Aspects)
+	}
+	if g := r.Grid[0][1]; g == nil || g.Aspect.Name != "opposition" || g.Orb != 0 {
+		t.Errorf("Grid[Sun][Mars] = %+v, want exact opposition", g)
+	}
+	if g := r.Grid[1][0]; g == nil || g.Aspect.Name != "square" || math.Abs(g.Orb-3) > 1e-9 {
+		t.Errorf("Grid[Moon][Venus] = %+v, want square orb 3", g)
+	}
+
+	if r.AInB[0].House != 10 || r.AInB[1].House != 1 {
+		t.Errorf("A in B houses = %+v, want Sun 10, Moon 1", r.AInB)
+	}
+	if r.BInA[0].House != 1 || r.BInA[1].House != 7 {
+		t.Errorf("B in A houses = %+v, want Venus 1, Mars 7", r.BInA)
+	}
+}
+
+func TestCompare_EmptyGridCells(t *testing.T) {
+	a := synastry.Chart{Bodies: []synastry.Body{{"Sun", 0}}, Cusps: equalCusps(0)}
+	b := synastry.Chart{Bodies: []synastry.Body{{"Moon", 150}}, Cusps: equalCusps(0)}
+	r := synastry.Compare(a, b, aspects.Major)
+	if len(r.Aspects) != 0 || r.Grid[0][0] != nil {
+		t.Errorf("expected no aspects, got %+v", r.Aspects)
+	}
+}
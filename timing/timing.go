@@ -0,0 +1,198 @@
+// Package timing measures where a command spends its time: argument
+// parsing, ephemeris calls, computation and rendering. It exists to help
+// diagnose slow batch jobs and to compare ephemeris backends.
+package timing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Ephemeris is the phase that collects time spent inside provider calls.
+const Ephemeris = "ephemeris"
+
+// Phase is the accumulated duration of one named phase.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+	Calls    int // provider calls; only set for the Ephemeris phase
+}
+
+// Recorder accumulates phase durations. A command calls Mark as it
+// finishes each phase; the phase is charged the time since the previous
+// Mark, less any time spent meanwhile in calls to a wrapped provider, which
+// is reported under Ephemeris instead. Mark and Wrap accept a nil *Recorder
+// and record nothing, so callers need not check whether timing is on.
+type Recorder struct {
+	mu     sync.Mutex
+	start  time.Time
+	last   time.Time
+	eph    time.Duration // Ephemeris time as of last
+	phases []Phase
+	now    func() time.Time
+}
+
+// New returns a Recorder whose first phase and total run from start, which
+// is normally the moment the command began.
+func New(start time.Time) *Recorder {
+	return &Recorder{start: start, last: start, now: time.Now}
+}
+
+// Mark ends the named phase. Marks of the same name accumulate.
+func (r *Recorder) Mark(name string) {
+	if r == nil {
+		return
+	}
+	now := r.now()
+	eph := r.ephemeris()
+	r.add(name, now.Sub(r.last)-(eph-r.eph), 0)
+	r.last, r.eph = now, eph
+}
+
+// Wrap returns a provider that forwards to p and records the time of every
+// call under the Ephemeris phase. A nil Recorder returns p unchanged.
+func (r *Recorder) Wrap(p ephemeris.Provider) ephemeris.Provider {
+	if r == nil {
+		return p
+	}
+	return &timedProvider{p: p, r: r}
+}
+
+// Phases returns the phases recorded so far, in the order first seen.
+func (r *Recorder) Phases() []Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Phase(nil), r.phases...)
+}
+
+func (r *Recorder) add(name string, d time.Duration, calls int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.phases {
+		if r.phases[i].Name == name {
+			r.phases[i].Duration += d
+			r.phases[i].Calls += calls
+			return
+		}
+	}
+	r.phases = append(r.phases, Phase{Name: name, Duration: d, Calls: calls})
+}
+
+// ephemeris returns the time recorded under Ephemeris so far.
+func (r *Recorder) ephemeris() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ph := range r.phases {
+		if ph.Name == Ephemeris {
+			return ph.Duration
+		}
+	}
+	return 0
+}
+
+type phaseJSON struct {
+	Phase string  `json:"phase"`
+	MS    float64 `json:"ms"`
+	Calls int     `json:"calls,omitempty"`
+}
+
+type reportJSON struct {
+	Command string      `json:"command"`
+	Backend string      `json:"backend"`
+	Phases  []phaseJSON `json:"phases"`
+	TotalMS float64     `json:"total_ms"`
+}
+
+// WriteJSON writes the phases and the total elapsed time since New as a
+// single JSON object. command and backend label the report so that runs
+// can be compared.
+func (r *Recorder) WriteJSON(w io.Writer, command, backend string) error {
+	out := reportJSON{Command: command, Backend: backend, Phases: []phaseJSON{}}
+	for _, ph := range r.Phases() {
+		out.Phases = append(out.Phases, phaseJSON{Phase: ph.Name, MS: ms(ph.Duration), Calls: ph.Calls})
+	}
+	out.TotalMS = ms(r.now().Sub(r.start))
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("error marshalling timings: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// ms converts d to fractional milliseconds, rounded to the microsecond.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// timedProvider records the duration of each call to p.
+type timedProvider struct {
+	p ephemeris.Provider
+	r *Recorder
+}
+
+// CalcPlanet implements ephemeris.Provider.
+func (t *timedProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	start := t.r.now()
+	pos, err := t.p.CalcPlanet(jd, body)
+	t.r.add(Ephemeris, t.r.now().Sub(start), 1)
+	return pos, err
+}
+
+// CalcHouses implements ephemeris.Provider.
+func (t *timedProvider) CalcHouses(jd, lat, lon float64, hsys byte) (ephemeris.HouseResult, error) {
+	start := t.r.now()
+	h, err := t.p.CalcHouses(jd, lat, lon, hsys)
+	t.r.add(Ephemeris, t.r.now().Sub(start), 1)
+	return h, err
+}
+
+// CalcPlanets implements ephemeris.BatchProvider. A batch counts as one
+// call when p computes it in one.
+func (t *timedProvider) CalcPlanets(jd float64, bodies []int) ([]ephemeris.PlanetPos, error) {
+	calls := len(bodies)
+	if _, ok := t.p.(ephemeris.BatchProvider); ok {
+		calls = 1
+	}
+	start := t.r.now()
+	pos, err := ephemeris.CalcPlanets(t.p, jd, bodies)
+	t.r.add(Ephemeris, t.r.now().Sub(start), calls)
+	return pos, err
+}
+
+// Crossing implements ephemeris.CrossingProvider, reporting ok false when p
+// is not one.
+func (t *timedProvider) Crossing(jd float64, body int, lon float64) (float64, bool, error) {
+	c, ok := t.p.(ephemeris.CrossingProvider)
+	if !ok {
+		return 0, false, nil
+	}
+	start := t.r.now()
+	jx, ok, err := c.Crossing(jd, body, lon)
+	t.r.add(Ephemeris, t.r.now().Sub(start), 1)
+	return jx, ok, err
+}
+
+// Source implements ephemeris.SourceProvider, reporting ok false when p
+// cannot tell.
+func (t *timedProvider) Source(jd float64, body int) (ephemeris.Source, bool, error) {
+	sp, ok := t.p.(ephemeris.SourceProvider)
+	if !ok {
+		return ephemeris.Source{}, false, nil
+	}
+	start := t.r.now()
+	s, ok, err := sp.Source(jd, body)
+	t.r.add(Ephemeris, t.r.now().Sub(start), 1)
+	return s, ok, err
+}
+
+// PlanetName implements ephemeris.Provider.
+func (t *timedProvider) PlanetName(body int) string {
+	return t.p.PlanetName(body)
+}
@@ -0,0 +1,97 @@
+package timing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// fakeClock advances by one millisecond every time it is read.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(time.Millisecond)
+	return c.t
+}
+
+func newTestRecorder() *Recorder {
+	c := &fakeClock{}
+	r := New(c.now())
+	r.now = c.now
+	return r
+}
+
+func TestMark_ExcludesEphemerisTime(t *testing.T) {
+	r := newTestRecorder()
+	p := r.Wrap(&ephemeris.MockProvider{Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {}}})
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.CalcPlanet(0, ephemeris.Sun); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Mark("compute")
+
+	got := map[string]Phase{}
+	for _, ph := range r.Phases() {
+		got[ph.Name] = ph
+	}
+	// Each call reads the clock twice (1ms each); Mark reads it once more:
+	// 7ms since New, of which 3ms were in calls.
+	if eph := got[Ephemeris]; eph.Duration != 3*time.Millisecond || eph.Calls != 3 {
+		t.Errorf("ephemeris = %v over %d calls, want 3ms over 3", eph.Duration, eph.Calls)
+	}
+	if c := got["compute"]; c.Duration != 4*time.Millisecond {
+		t.Errorf("compute = %v, want 4ms", c.Duration)
+	}
+}
+
+func TestMark_Accumulates(t *testing.T) {
+	r := newTestRecorder()
+	r.Mark("render")
+	r.Mark("parse")
+	r.Mark("render")
+
+	phases := r.Phases()
+	if len(phases) != 2 || phases[0].Name != "render" || phases[1].Name != "parse" {
+		t.Fatalf("phases = %+v, want render then parse", phases)
+	}
+	if phases[0].Duration != 2*time.Millisecond {
+		t.Errorf("render = %v, want 2ms", phases[0].Duration)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	r.Mark("parse")
+	p := &ephemeris.MockProvider{}
+	if r.Wrap(p) != ephemeris.Provider(p) {
+		t.Error("nil Recorder should return the provider unchanged")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	r := newTestRecorder()
+	r.Mark("parse")
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf, "chart", "moshier"); err != nil {
+		t.Fatal(err)
+	}
+	var got reportJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if got.Command != "chart" || got.Backend != "moshier" {
+		t.Errorf("labels = %q/%q, want chart/moshier", got.Command, got.Backend)
+	}
+	if len(got.Phases) != 1 || got.Phases[0].Phase != "parse" || got.Phases[0].MS != 1 {
+		t.Errorf("phases = %+v, want parse 1ms", got.Phases)
+	}
+	if got.TotalMS != 2 {
+		t.Errorf("total_ms = %v, want 2", got.TotalMS)
+	}
+}
@@ -0,0 +1,247 @@
+// Package transits finds the moments when transiting planets form aspects
+// to the points of a natal chart: the exact hits, and when each aspect
+// comes within and leaves its orb.
+package transits
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/internal/search"
+)
+
+// Point is a fixed natal longitude that transits are measured against.
+type Point struct {
+	Name      string
+	Longitude float64
+}
+
+// Kind distinguishes the events of one transit.
+type Kind int
+
+const (
+	Ingress Kind = iota // the aspect comes within orb
+	Exact               // the aspect is exact
+	Egress              // the aspect leaves orb
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Ingress:
+		return "ingress"
+	case Exact:
+		return "exact"
+	case Egress:
+		return "egress"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Event is one moment in a transit. A planet that stations within orb can
+// produce several exact hits between one ingress and its egress.
+type Event struct {
+	JD        float64
+	Body      int // the transiting body
+	Point     Point
+	Aspect    aspects.Aspect
+	Kind      Kind
+	Longitude float64 // the transiting body's longitude at JD
+}
+
+// step returns the sampling interval in days for body: short enough that
+// it cannot cross an orb of a degree, or reverse through an exact hit,
+// between samples.
+func step(body int) float64 {
+	if body == ephemeris.Moon {
+		return 0.1
+	}
+	return 1
+}
+
+// target is one longitude a body must reach to form an aspect to a point.
+// Aspects other than the conjunction and opposition have two, one on each
+// side of the point.
+type target struct {
+	point  Point
+	aspect aspects.Aspect
+	lon    float64
+}
+
+// targetsFor expands each aspect to each point into the longitudes that
+// form it.
+func targetsFor(points []Point, as []aspects.Aspect) []target {
+	var targets []target
+	for _, pt := range points {
+		for _, a := range as {
+			targets = append(targets, target{pt, a, angle.Norm360(pt.Longitude + a.Angle)})
+			if a.Angle != 0 && a.Angle != 180 {
+				targets = append(targets, target{pt, a, angle.Norm360(pt.Longitude - a.Angle)})
+			}
+		}
+	}
+	return targets
+}
+
+// Scan returns the transits of bodies to points within the Julian Day range
+// [from, to], in chronological order. Each aspect's orb is taken from the
+// aspect. Transits already in orb at from have no ingress; those still in
+// orb at to have no egress.
+func Scan(p ephemeris.Provider, bodies []int, points []Point, as []aspects.Aspect, from, to float64) ([]Event, error) {
+	targets := targetsFor(points, as)
+	var events []Event
+	for _, body := range bodies {
+		found, err := scanBody(p, body, targets, from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, found...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].JD < events[j].JD })
+	return events, nil
+}
+
+// scanBody samples body once per step and checks every target in each
+// interval, so each sample serves all targets.
+func scanBody(p ephemeris.Provider, body int, targets []target, from, to float64) ([]Event, error) {
+	lon := longitude(p, body)
+
+	var events []Event
+	add := func(jd float64, tg target, kind Kind) error {
+		l, err := lon(jd)
+		if err != nil {
+			return err
+		}
+		events = append(events, Event{JD: jd, Body: body, Point: tg.point, Aspect: tg.aspect, Kind: kind, Longitude: l})
+		return nil
+	}
+
+	err := search.Scan(lon, from, to, step(body), func(t0, l0, t1, l1 float64) error {
+		for _, tg := range targets {
+			offset := search.Offset(lon, tg.lon)
+			d0, d1 := angle.Diff(l0, tg.lon), angle.Diff(l1, tg.lon)
+			if search.Crossed(d0, d1) {
+				jd, err := search.Root(offset, t0, t1, d0, d1)
+				if err != nil {
+					return err
+				}
+				if err := add(jd, tg, Exact); err != nil {
+					return err
+				}
+			}
+			orb := tg.aspect.Orb
+			in0, in1 := math.Abs(d0) < orb, math.Abs(d1) < orb
+			if in0 != in1 {
+				edge := func(jd float64) (float64, error) {
+					d, err := offset(jd)
+					return math.Abs(d) - orb, err
+				}
+				jd, err := search.Root(edge, t0, t1, math.Abs(d0)-orb, math.Abs(d1)-orb)
+				if err != nil {
+					return err
+				}
+				kind := Ingress
+				if in0 {
+					kind = Egress
+				}
+				if err := add(jd, tg, kind); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Active is a transit in orb at a given moment.
+type Active struct {
+	Body      int
+	Point     Point
+	Aspect    aspects.Aspect
+	Orb       float64 // the transiting longitude minus the exact aspect point, in degrees
+	Applying  bool    // the orb is shrinking
+	Longitude float64 // the transiting body's longitude
+	// NextExact is the Julian Day of the next exact hit before the transit
+	// leaves orb, or 0 if it separates without perfecting again.
+	NextExact float64
+}
+
+// maxLinger bounds the forward search for the next exact hit, in days. A
+// slow planet stationing within a small orb can stay in it for about two
+// years.
+const maxLinger = 1000
+
+// Snapshot returns the transits of bodies to points that are within orb at
+// jd, ordered by body and then by orb size.
+func Snapshot(p ephemeris.Provider, bodies []int, points []Point, as []aspects.Aspect, jd float64) ([]Active, error) {
+	targets := targetsFor(points, as)
+	var active []Active
+	for _, body := range bodies {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		var found []Active
+		for _, tg := range targets {
+			d := angle.Diff(pos.Longitude, tg.lon)
+			if math.Abs(d) >= tg.aspect.Orb {
+				continue
+			}
+			a := Active{
+				Body:      body,
+				Point:     tg.point,
+				Aspect:    tg.aspect,
+				Orb:       d,
+				Applying:  angle.IsApplying(d, pos.SpeedLon),
+				Longitude: pos.Longitude,
+			}
+			if a.NextExact, err = nextExact(p, body, tg, jd); err != nil {
+				return nil, err
+			}
+			found = append(found, a)
+		}
+		sort.SliceStable(found, func(i, j int) bool { return math.Abs(found[i].Orb) < math.Abs(found[j].Orb) })
+		active = append(active, found...)
+	}
+	return active, nil
+}
+
+// nextExact returns the first exact hit of tg by body after from, or 0 if
+// the body leaves orb first.
+func nextExact(p ephemeris.Provider, body int, tg target, from float64) (float64, error) {
+	offset := search.Offset(longitude(p, body), tg.lon)
+	var exact float64
+	err := search.Scan(offset, from, from+maxLinger, step(body), func(t0, d0, t1, d1 float64) error {
+		if search.Crossed(d0, d1) {
+			jd, err := search.Root(offset, t0, t1, d0, d1)
+			if err != nil {
+				return err
+			}
+			exact = jd
+			return search.Stop
+		}
+		if math.Abs(d1) >= tg.aspect.Orb {
+			return search.Stop
+		}
+		return nil
+	})
+	return exact, err
+}
+
+// longitude returns a function giving body's longitude at a Julian Day.
+func longitude(p ephemeris.Provider, body int) search.Func {
+	return func(jd float64) (float64, error) {
+		pos, err := p.CalcPlanet(jd, body)
+		if err != nil {
+			return 0, fmt.Errorf("error calculating %s: %w", p.PlanetName(body), err)
+		}
+		return pos.Longitude, nil
+	}
+}
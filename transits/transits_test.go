@@ -0,0 +1,123 @@
+package transits_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dcccxiii/astro/aspects"
+	"github.com/dcccxiii/astro/ephemeris"
+	"github.com/dcccxiii/astro/transits"
+)
+
+func TestScan_DirectConjunction(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Mars: {Longitude: 0, SpeedLon: 1}},
+	}
+	points := []transits.Point{{Name: "Sun", Longitude: 10}}
+	conj := aspects.WithOrb(aspects.Major[:1], 1)
+
+	events, err := transits.Scan(p, []int{ephemeris.Mars}, points, conj, 0, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		jd   float64
+		kind transits.Kind
+	}{{9, transits.Ingress}, {10, transits.Exact}, {11, transits.Egress}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Kind != w.kind || math.Abs(e.JD-w.jd) > 1e-4 {
+			t.Errorf("event %d = %v at %.5f, want %v at %v", i, e.Kind, e.JD, w.kind, w.jd)
+		}
+	}
+}
+
+func TestScan_BothSidesOfSquare(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Sun: {Longitude: 0, SpeedLon: 1}},
+	}
+	points := []transits.Point{{Name: "Moon", Longitude: 180}}
+	square := aspects.WithOrb(aspects.Major[2:3], 1)
+
+	events, err := transits.Scan(p, []int{ephemeris.Sun}, points, square, 0, 360)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exact []float64
+	for _, e := range events {
+		if e.Kind == transits.Exact {
+			exact = append(exact, e.JD)
+		}
+	}
+	if len(exact) != 2 || math.Abs(exact[0]-90) > 1e-4 || math.Abs(exact[1]-270) > 1e-4 {
+		t.Errorf("exact squares at %v, want [90 270]", exact)
+	}
+}
+
+// retroProvider moves Jupiter forward 0.2°/day with a ±8° wobble of period
+// 100 days, so it periodically runs backwards over the same degrees.
+type retroProvider struct{ ephemeris.MockProvider }
+
+func (retroProvider) CalcPlanet(jd float64, body int) (ephemeris.PlanetPos, error) {
+	lon := 0.2*jd + 8*math.Sin(2*math.Pi*jd/100)
+	return ephemeris.PlanetPos{Longitude: math.Mod(math.Mod(lon, 360)+360, 360)}, nil
+}
+
+func TestScan_RetrogradeStationInOrb(t *testing.T) {
+	points := []transits.Point{{Name: "Venus", Longitude: 10}}
+	conj := aspects.WithOrb(aspects.Major[:1], 5)
+
+	events, err := transits.Scan(&retroProvider{}, []int{ephemeris.Jupiter}, points, conj, 0, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[transits.Kind]int{}
+	for _, e := range events {
+		counts[e.Kind]++
+		if e.Kind == transits.Exact && math.Abs(e.Longitude-10) > 1e-3 {
+			t.Errorf("exact hit at %.4f has longitude %.5f, want 10", e.JD, e.Longitude)
+		}
+	}
+	if counts[transits.Exact] != 3 {
+		t.Errorf("got %d exact hits, want 3: %+v", counts[transits.Exact], events)
+	}
+	if counts[transits.Ingress] != counts[transits.Egress] {
+		t.Errorf("ingresses %d != egresses %d", counts[transits.Ingress], counts[transits.Egress])
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].JD < events[i-1].JD {
+			t.Fatalf("events out of order at %d", i)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	p := &ephemeris.MockProvider{
+		Epoch:   0,
+		Planets: map[int]ephemeris.PlanetPos{ephemeris.Mars: {Longitude: 0, SpeedLon: 1}},
+	}
+	points := []transits.Point{{Name: "Sun", Longitude: 1.5}, {Name: "Moon", Longitude: 359}, {Name: "Venus", Longitude: 40}}
+	conj := aspects.WithOrb(aspects.Major[:1], 2)
+
+	active, err := transits.Snapshot(p, []int{ephemeris.Mars}, points, conj, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("got %d active transits, want 2: %+v", len(active), active)
+	}
+	// Ordered by orb: the Moon (1° behind, separating) before the Sun
+	// (1.5° ahead, applying).
+	moon, sun := active[0], active[1]
+	if moon.Point.Name != "Moon" || moon.Applying || moon.NextExact != 0 || math.Abs(moon.Orb-1) > 1e-9 {
+		t.Errorf("Moon transit = %+v, want separating 1° with no next exact", moon)
+	}
+	if sun.Point.Name != "Sun" || !sun.Applying || math.Abs(sun.NextExact-1.5) > 1e-4 {
+		t.Errorf("Sun transit = %+v, want applying, exact at 1.5", sun)
+	}
+}
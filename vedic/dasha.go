@@ -0,0 +1,110 @@
+package vedic
+
+import (
+	"math"
+	"time"
+)
+
+// DashaCycle is the length of the Vimshottari cycle in years.
+const DashaCycle = 120
+
+// dashaYear is the year Vimshottari periods are measured in.
+const dashaYear = 365.25 * 24 * time.Hour
+
+// dashaYears gives each lord's mahadasha length in years. The lords follow
+// the order of nakshatraLords.
+var dashaYears = map[Graha]float64{
+	Ketu: 7, Venus: 20, Sun: 6, Moon: 10, Mars: 7,
+	Rahu: 18, Jupiter: 16, Saturn: 19, Mercury: 17,
+}
+
+// Dasha is a period of the Vimshottari system: a mahadasha, or at deeper
+// levels an antardasha or pratyantardasha within its parent.
+type Dasha struct {
+	Lord       Graha
+	Start, End time.Time
+	Sub        []Dasha // the next level down, if requested
+}
+
+// DashaYears returns the length of lord's mahadasha in years.
+func DashaYears(lord Graha) float64 { return dashaYears[lord] }
+
+// Vimshottari returns the 120-year dasha sequence for a birth at birth with
+// the Moon at sidereal longitude moon. The first mahadasha is that of the
+// Moon's nakshatra lord, with only the part still to run at birth (the
+// balance); periods are nested to the given depth (1 for mahadashas only,
+// 3 down to pratyantardashas). Each sub-period takes the same share of its
+// parent as its lord's mahadasha takes of the whole cycle, starting with
+// the parent's own lord.
+func Vimshottari(birth time.Time, moon float64, levels int) []Dasha {
+	n := NakshatraOf(moon)
+	first := lordIndex(n.Lord)
+	// The first mahadasha began before birth, when the Moon entered its
+	// nakshatra.
+	start := birth.Add(-years(n.Frac * dashaYears[n.Lord]))
+
+	var out []Dasha
+	t := start
+	for i := 0; i < 9; i++ {
+		lord := nakshatraLords[(first+i)%9]
+		end := t.Add(years(dashaYears[lord]))
+		d := subdivide(Dasha{Lord: lord, Start: t, End: end}, levels-1)
+		if clipped, ok := clip(d, birth); ok {
+			out = append(out, clipped)
+		}
+		t = end
+	}
+	return out
+}
+
+// subdivide fills in depth levels of sub-periods under d.
+func subdivide(d Dasha, depth int) Dasha {
+	if depth <= 0 {
+		return d
+	}
+	span := d.End.Sub(d.Start)
+	first := lordIndex(d.Lord)
+	t := d.Start
+	for i := 0; i < 9; i++ {
+		lord := nakshatraLords[(first+i)%9]
+		end := t.Add(time.Duration(math.Round(float64(span) * dashaYears[lord] / DashaCycle)))
+		if i == 8 {
+			end = d.End
+		}
+		d.Sub = append(d.Sub, subdivide(Dasha{Lord: lord, Start: t, End: end}, depth-1))
+		t = end
+	}
+	return d
+}
+
+// clip drops the parts of d that ended before birth. ok is false if all
+// of d did.
+func clip(d Dasha, birth time.Time) (Dasha, bool) {
+	if !d.End.After(birth) {
+		return Dasha{}, false
+	}
+	if d.Start.Before(birth) {
+		d.Start = birth
+	}
+	subs := d.Sub
+	d.Sub = nil
+	for _, s := range subs {
+		if c, ok := clip(s, birth); ok {
+			d.Sub = append(d.Sub, c)
+		}
+	}
+	return d, true
+}
+
+func lordIndex(g Graha) int {
+	for i, l := range nakshatraLords {
+		if l == g {
+			return i
+		}
+	}
+	return 0
+}
+
+func years(y float64) time.Duration {
+	return time.Duration(math.Round(y * float64(dashaYear)))
+}
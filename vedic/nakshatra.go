@@ -0,0 +1,48 @@
+package vedic
+
+import "github.com/dcccxiii/astro/angle"
+
+// NakshatraSpan is the width of one nakshatra in degrees (13°20′), and
+// PadaSpan the width of one of its four padas (3°20′).
+const (
+	NakshatraSpan = 360.0 / 27
+	PadaSpan      = NakshatraSpan / 4
+)
+
+// Nakshatras are the 27 lunar mansions in order from 0° sidereal Aries.
+var Nakshatras = [27]string{
+	"Ashwini", "Bharani", "Krittika", "Rohini", "Mrigashira", "Ardra",
+	"Punarvasu", "Pushya", "Ashlesha", "Magha", "Purva Phalguni",
+	"Uttara Phalguni", "Hasta", "Chitra", "Swati", "Vishakha", "Anuradha",
+	"Jyeshtha", "Mula", "Purva Ashadha", "Uttara Ashadha", "Shravana",
+	"Dhanishta", "Shatabhisha", "Purva Bhadrapada", "Uttara Bhadrapada",
+	"Revati",
+}
+
+// nakshatraLords is the cycle of lords, repeated three times around the
+// zodiac from Ashwini. It is also the order of the Vimshottari dashas.
+var nakshatraLords = [9]Graha{Ketu, Venus, Sun, Moon, Mars, Rahu, Jupiter, Saturn, Mercury}
+
+// Nakshatra locates a sidereal longitude among the lunar mansions.
+type Nakshatra struct {
+	Index int     // 0 (Ashwini) to 26 (Revati)
+	Pada  int     // quarter, 1 to 4
+	Lord  Graha   // ruler of the nakshatra
+	Frac  float64 // fraction of the nakshatra already traversed, in [0, 1)
+}
+
+// Name returns the nakshatra's name.
+func (n Nakshatra) Name() string { return Nakshatras[n.Index] }
+
+// NakshatraOf returns the nakshatra and pada of a sidereal longitude.
+func NakshatraOf(lon float64) Nakshatra {
+	lon = angle.Norm360(lon)
+	i := min(int(lon/NakshatraSpan), 26)
+	within := lon - float64(i)*NakshatraSpan
+	return Nakshatra{
+		Index: i,
+		Pada:  min(int(within/PadaSpan), 3) + 1,
+		Lord:  nakshatraLords[i%9],
+		Frac:  within / NakshatraSpan,
+	}
+}
@@ -0,0 +1,132 @@
+package vedic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// Varga is a divisional chart, identified by the number of parts each sign
+// is divided into: 9 is the navamsha (D-9).
+type Varga int
+
+// Vargas lists the supported divisional charts, from the Parashari set.
+var Vargas = []Varga{1, 2, 3, 4, 7, 9, 10, 12, 16, 20, 24, 27, 30, 40, 45, 60}
+
+var vargaNames = map[Varga]string{
+	1: "Rashi", 2: "Hora", 3: "Drekkana", 4: "Chaturthamsha", 7: "Saptamsha",
+	9: "Navamsha", 10: "Dashamsha", 12: "Dwadashamsha", 16: "Shodashamsha",
+	20: "Vimshamsha", 24: "Chaturvimshamsha", 27: "Saptavimshamsha",
+	30: "Trimshamsha", 40: "Khavedamsha", 45: "Akshavedamsha", 60: "Shashtiamsha",
+}
+
+// String returns the conventional code, e.g. "D9".
+func (v Varga) String() string { return "D" + strconv.Itoa(int(v)) }
+
+// Name returns the Sanskrit name, e.g. "Navamsha".
+func (v Varga) Name() string { return vargaNames[v] }
+
+// ParseVarga parses a divisional chart code such as "d9", "D-10" or "12".
+func ParseVarga(s string) (Varga, error) {
+	t := strings.TrimPrefix(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "D"), "-")
+	if n, err := strconv.Atoi(t); err == nil {
+		if _, ok := vargaNames[Varga(n)]; ok {
+			return Varga(n), nil
+		}
+	}
+	codes := make([]string, len(Vargas))
+	for i, v := range Vargas {
+		codes[i] = strings.ToLower(v.String())
+	}
+	return 0, fmt.Errorf("unknown varga %q: valid values are %s", s, strings.Join(codes, ", "))
+}
+
+// The trimshamsha (D-30) divides each sign unequally among the five
+// non-luminary planets, mapping each part to a sign that planet rules. Odd
+// and even signs use different boundaries (in degrees) and signs.
+var (
+	oddTrimshamshaBounds  = []float64{5, 10, 18, 25, 30}
+	oddTrimshamsha        = []int{0, 10, 8, 2, 6} // Aries, Aquarius, Sagittarius, Gemini, Libra
+	evenTrimshamshaBounds = []float64{5, 12, 20, 25, 30}
+	evenTrimshamsha       = []int{1, 5, 11, 9, 7} // Taurus, Virgo, Pisces, Capricorn, Scorpio
+)
+
+// Longitude maps a sidereal longitude into the divisional chart. The sign
+// comes from the varga's rule; the degree within it is the position within
+// the part, scaled to 30°.
+func (v Varga) Longitude(lon float64) float64 {
+	lon = angle.Norm360(lon)
+	sign := min(int(lon/30), 11)
+	deg := lon - float64(sign)*30
+	odd := sign%2 == 0 // Aries, the first sign, is odd
+	mode := sign % 3   // 0 movable, 1 fixed, 2 dual
+
+	if v == 30 {
+		bounds, signs := oddTrimshamshaBounds, oddTrimshamsha
+		if !odd {
+			bounds, signs = evenTrimshamshaBounds, evenTrimshamsha
+		}
+		lo := 0.0
+		for i, hi := range bounds {
+			if deg < hi || i == len(bounds)-1 {
+				return float64(signs[i])*30 + (deg-lo)/(hi-lo)*30
+			}
+			lo = hi
+		}
+	}
+
+	span := 30 / float64(v)
+	part := min(int(deg/span), int(v)-1)
+	within := (deg - float64(part)*span) / span * 30
+
+	var start int // sign of the first part
+	switch v {
+	case 1, 12, 60:
+		start = sign
+	case 2:
+		// Odd signs: Sun's hora (Leo) then Moon's (Cancer); even signs the
+		// reverse.
+		leo, cancer := 4, 3
+		h := []int{leo, cancer}
+		if !odd {
+			h = []int{cancer, leo}
+		}
+		return float64(h[part])*30 + within
+	case 3:
+		return float64((sign+4*part)%12)*30 + within // same, 5th, 9th
+	case 4:
+		return float64((sign+3*part)%12)*30 + within // same, 4th, 7th, 10th
+	case 7:
+		start = sign
+		if !odd {
+			start = sign + 6
+		}
+	case 9, 27:
+		// Counting on continuously from Aries gives the element rule:
+		// fire signs start from Aries, earth from Capricorn (D-9) or
+		// Cancer (D-27), and so on.
+		start = sign * int(v)
+	case 10:
+		start = sign
+		if !odd {
+			start = sign + 8
+		}
+	case 16, 45:
+		start = []int{0, 4, 8}[mode] // Aries, Leo, Sagittarius
+	case 20:
+		start = []int{0, 8, 4}[mode] // Aries, Sagittarius, Leo
+	case 24:
+		start = 4 // Leo
+		if !odd {
+			start = 3 // Cancer
+		}
+	case 40:
+		start = 0
+		if !odd {
+			start = 6 // Libra
+		}
+	}
+	return float64((start+part)%12)*30 + within
+}
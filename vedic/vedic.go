@@ -0,0 +1,49 @@
+// Package vedic holds the building blocks of jyotish (Vedic astrology):
+// the nine grahas and the 27 nakshatras. It works on sidereal longitudes;
+// the tropical zodiac gives meaningless results.
+package vedic
+
+import (
+	"fmt"
+
+	"github.com/dcccxiii/astro/ephemeris"
+)
+
+// Graha is one of the nine Vedic "planets": the seven visible planets and
+// the lunar nodes Rahu (north) and Ketu (south).
+type Graha int
+
+const (
+	Sun Graha = iota
+	Moon
+	Mars
+	Mercury
+	Jupiter
+	Venus
+	Saturn
+	Rahu
+	Ketu
+)
+
+var grahaNames = [...]string{"Sun", "Moon", "Mars", "Mercury", "Jupiter", "Venus", "Saturn", "Rahu", "Ketu"}
+
+func (g Graha) String() string {
+	if g < Sun || g > Ketu {
+		return fmt.Sprintf("Graha(%d)", int(g))
+	}
+	return grahaNames[g]
+}
+
+var grahaBodies = [...]int{
+	ephemeris.Sun, ephemeris.Moon, ephemeris.Mars, ephemeris.Mercury,
+	ephemeris.Jupiter, ephemeris.Venus, ephemeris.Saturn,
+}
+
+// Body returns the ephemeris body ID of a planet. ok is false for Rahu and
+// Ketu, which are points rather than bodies.
+func (g Graha) Body() (id int, ok bool) {
+	if g < Sun || g > Saturn {
+		return 0, false
+	}
+	return grahaBodies[g], true
+}
@@ -0,0 +1,127 @@
+package vedic_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dcccxiii/astro/vedic"
+)
+
+func TestNakshatraOf(t *testing.T) {
+	cases := []struct {
+		lon  float64
+		name string
+		pada int
+		lord vedic.Graha
+	}{
+		{0, "Ashwini", 1, vedic.Ketu},
+		{3.5, "Ashwini", 2, vedic.Ketu},
+		{13.34, "Bharani", 1, vedic.Venus},
+		{125, "Magha", 2, vedic.Ketu},       // 120°–133°20′, second pada from 123°20′
+		{200, "Vishakha", 1, vedic.Jupiter}, // 200° starts Vishakha
+		{359.99, "Revati", 4, vedic.Mercury},
+		{-10, "Revati", 1, vedic.Mercury}, // 350°
+	}
+	for _, tc := range cases {
+		n := vedic.NakshatraOf(tc.lon)
+		if n.Name() != tc.name || n.Pada != tc.pada || n.Lord != tc.lord {
+			t.Errorf("NakshatraOf(%v) = %s %d (%v), want %s %d (%v)", tc.lon, n.Name(), n.Pada, n.Lord, tc.name, tc.pada, tc.lord)
+		}
+	}
+	if f := vedic.NakshatraOf(vedic.NakshatraSpan * 1.25).Frac; math.Abs(f-0.25) > 1e-9 {
+		t.Errorf("Frac = %v, want 0.25", f)
+	}
+}
+
+func TestGrahaBody(t *testing.T) {
+	if id, ok := vedic.Venus.Body(); !ok || id != 3 {
+		t.Errorf("Venus.Body() = %d, %v; want 3, true", id, ok)
+	}
+	if _, ok := vedic.Rahu.Body(); ok {
+		t.Error("Rahu should have no body ID")
+	}
+}
+
+func TestVimshottari(t *testing.T) {
+	birth := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Halfway through Magha (Ketu): 3.5 of Ketu's 7 years remain.
+	moon := 9*vedic.NakshatraSpan + vedic.NakshatraSpan/2
+	ds := vedic.Vimshottari(birth, moon, 3)
+
+	if len(ds) != 9 || ds[0].Lord != vedic.Ketu || ds[1].Lord != vedic.Venus || ds[8].Lord != vedic.Mercury {
+		t.Fatalf("mahadashas = %v", ds)
+	}
+	if !ds[0].Start.Equal(birth) {
+		t.Errorf("first mahadasha starts %v, want birth", ds[0].Start)
+	}
+	if got := ds[0].End.Sub(birth).Hours() / 24 / 365.25; math.Abs(got-3.5) > 1e-6 {
+		t.Errorf("balance of Ketu = %.4f years, want 3.5", got)
+	}
+	if got := ds[8].End.Sub(ds[0].Start).Hours() / 24 / 365.25; math.Abs(got-(vedic.DashaCycle-3.5)) > 1e-6 {
+		t.Errorf("sequence runs %.4f years, want %v", got, vedic.DashaCycle-3.5)
+	}
+
+	// Venus mahadasha: Venus/Venus antardasha is 20·20/120 years, and its
+	// first pratyantardasha is Venus again.
+	venus := ds[1]
+	if len(venus.Sub) != 9 || venus.Sub[0].Lord != vedic.Venus || venus.Sub[1].Lord != vedic.Sun {
+		t.Fatalf("Venus antardashas = %v", venus.Sub)
+	}
+	if got := venus.Sub[0].End.Sub(venus.Sub[0].Start).Hours() / 24 / 365.25; math.Abs(got-20.0*20/120) > 1e-6 {
+		t.Errorf("Venus/Venus = %.4f years, want %.4f", got, 20.0*20/120)
+	}
+	if len(venus.Sub[0].Sub) != 9 || venus.Sub[0].Sub[0].Lord != vedic.Venus {
+		t.Errorf("Venus/Venus pratyantardashas = %v", venus.Sub[0].Sub)
+	}
+
+	// Half of Ketu has passed, so its antardashas before birth are gone.
+	ketu := ds[0]
+	if ketu.Sub[0].Lord == vedic.Ketu || !ketu.Sub[0].Start.Equal(birth) {
+		t.Errorf("first Ketu antardasha = %v from %v, want a later lord from birth", ketu.Sub[0].Lord, ketu.Sub[0].Start)
+	}
+}
+
+func TestVimshottari_Levels(t *testing.T) {
+	ds := vedic.Vimshottari(time.Now(), 0, 1)
+	if len(ds[0].Sub) != 0 {
+		t.Error("levels 1 should give mahadashas only")
+	}
+}
+
+func TestVargaLongitude(t *testing.T) {
+	cases := []struct {
+		v    vedic.Varga
+		lon  float64
+		want float64
+	}{
+		{1, 45, 45},
+		{9, 1, 9},          // Aries navamsha 1 is Aries, 1° → 9°
+		{9, 30, 270},       // Taurus starts from Capricorn
+		{9, 95, 4*30 + 15}, // Cancer 5°: Cancer's second navamsha, Leo, halfway through
+		{9, 359, 11*30 + 21},
+		{2, 10, 4*30 + 20},        // Aries 10°: Sun's hora, Leo
+		{2, 40, 3*30 + 20},        // Taurus 10°: Moon's hora, Cancer
+		{3, 75, 6*30 + 15},        // Gemini 15°: 2nd drekkana, 5th from Gemini = Libra
+		{10, 31.5, 9*30 + 15},     // Taurus 1.5°: even sign starts 9th from Taurus = Capricorn
+		{30, 3, 18},               // Aries 3°: Mars, Aries 18°
+		{30, 36, 5*30 + 1*30/7.0}, // Taurus 6°: Mercury, Virgo
+		{60, 0.75, 30 + 15},
+	}
+	for _, tc := range cases {
+		if got := tc.v.Longitude(tc.lon); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%v.Longitude(%v) = %.4f, want %.4f", tc.v, tc.lon, got, tc.want)
+		}
+	}
+}
+
+func TestParseVarga(t *testing.T) {
+	for _, s := range []string{"d9", "D9", "D-9", "9"} {
+		if v, err := vedic.ParseVarga(s); err != nil || v != 9 {
+			t.Errorf("ParseVarga(%q) = %v, %v", s, v, err)
+		}
+	}
+	if _, err := vedic.ParseVarga("d11"); err == nil {
+		t.Error("ParseVarga(\"d11\"): expected error")
+	}
+}
@@ -0,0 +1,114 @@
+package wheel
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// Drawing is a laid-out wheel or graph: shapes on a canvas, painted in
+// order over the background.
+type Drawing struct {
+	Width, Height int // in pixels
+	Background    color.RGBA
+	shapes        []shape
+}
+
+// shape is something a Drawing can render both ways.
+type shape interface {
+	svg(w io.Writer)
+	raster(c *canvas)
+}
+
+func (d *Drawing) add(s shape) { d.shapes = append(d.shapes, s) }
+
+type vec struct{ x, y float64 }
+
+// circle is an unfilled circle.
+type circle struct {
+	c      vec
+	r      float64
+	width  float64
+	stroke color.RGBA
+}
+
+// segment is a straight line.
+type segment struct {
+	a, b   vec
+	width  float64
+	stroke color.RGBA
+}
+
+// sector is a filled band of an annulus between radii r0 and r1, from
+// screen angle a0 anticlockwise to a1, in degrees.
+type sector struct {
+	c      vec
+	r0, r1 float64
+	a0, a1 float64
+	fill   color.RGBA
+}
+
+// text is a label centred on at, or starting there if left is set: glyph
+// in SVG, label in PNG.
+type text struct {
+	at    vec
+	size  float64 // height in pixels
+	glyph string
+	label string
+	fill  color.RGBA
+	left  bool
+}
+
+// SVG writes the drawing as an SVG document.
+func (d Drawing) SVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", d.Width, d.Height, d.Width, d.Height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(d.Background))
+	for _, s := range d.shapes {
+		s.svg(bw)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func (s circle) svg(w io.Writer) {
+	fmt.Fprintf(w, `<circle cx="%.2f" cy="%.2f" r="%.2f" fill="none" stroke="%s" stroke-width="%.2f"/>`+"\n",
+		s.c.x, s.c.y, s.r, hex(s.stroke), s.width)
+}
+
+func (s segment) svg(w io.Writer) {
+	fmt.Fprintf(w, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="%.2f"/>`+"\n",
+		s.a.x, s.a.y, s.b.x, s.b.y, hex(s.stroke), s.width)
+}
+
+func (s sector) svg(w io.Writer) {
+	p := func(r, a float64) vec {
+		a *= math.Pi / 180
+		return vec{s.c.x + r*math.Cos(a), s.c.y - r*math.Sin(a)}
+	}
+	large := 0
+	if angle.Norm360(s.a1-s.a0) > 180 {
+		large = 1
+	}
+	o0, o1, i1, i0 := p(s.r1, s.a0), p(s.r1, s.a1), p(s.r0, s.a1), p(s.r0, s.a0)
+	fmt.Fprintf(w, `<path d="M%.2f %.2f A%.2f %.2f 0 %d 0 %.2f %.2f L%.2f %.2f A%.2f %.2f 0 %d 1 %.2f %.2fZ" fill="%s"/>`+"\n",
+		o0.x, o0.y, s.r1, s.r1, large, o1.x, o1.y, i1.x, i1.y, s.r0, s.r0, large, i0.x, i0.y, hex(s.fill))
+}
+
+func (s text) svg(w io.Writer) {
+	anchor := "middle"
+	if s.left {
+		anchor = "start"
+	}
+	fmt.Fprintf(w, `<text x="%.2f" y="%.2f" font-size="%.2f" font-family="sans-serif" text-anchor="%s" dominant-baseline="central" fill="%s">`,
+		s.at.x, s.at.y, s.size, anchor, hex(s.fill))
+	xml.EscapeText(w, []byte(s.glyph))
+	fmt.Fprintln(w, "</text>")
+}
+
+func hex(c color.RGBA) string { return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B) }
@@ -0,0 +1,211 @@
+package wheel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Series is a body's longitude sampled over the span of a Graph.
+type Series struct {
+	Glyph string // drawn in SVG
+	Label string // drawn in PNG
+	// Longitudes are sampled at even steps from the graph's From to its
+	// To, both included.
+	Longitudes []float64
+}
+
+// Graph is what a graphic ephemeris shows: longitude, reduced by a
+// modulus, against time. Bodies in the same degree of a 90° graph are in
+// hard aspect, so the graph shows at a glance when one planet crosses
+// another's aspects.
+type Graph struct {
+	From, To time.Time
+	// Modulus is the range of the longitude axis: 360 for the zodiac, or
+	// 90 or 45 to fold the hard aspects onto one line.
+	Modulus float64
+	Series  []Series
+	// Natal are fixed positions, such as a birth chart's, drawn as
+	// horizontal lines across the graph.
+	Natal      []Point
+	SignGlyphs [12]string // with a modulus of 360, drawn in SVG by the axis
+	SignLabels [12]string // drawn in PNG
+}
+
+// Margins of the plot within the drawing, as fractions of its height. The
+// right margin holds the bodies' labels and, with a modulus of 360, the
+// signs beyond them.
+const (
+	graphLeft      = 0.09
+	graphRight     = 0.07
+	graphRightSign = 0.15
+	graphTop       = 0.05
+	graphBottom    = 0.08
+)
+
+// DrawGraph lays out graph g on width by height pixels.
+func DrawGraph(g Graph, width, height int, t Theme) Drawing {
+	d := Drawing{Width: width, Height: height, Background: t.Background}
+	w, h := float64(width), float64(height)
+	m := g.Modulus
+	right := graphRight
+	if m == 360 {
+		right = graphRightSign
+	}
+	x0, x1 := h*graphLeft, w-h*right
+	y0, y1 := h*graphTop, h*(1-graphBottom)
+	span := g.To.Sub(g.From).Hours()
+	x := func(at time.Time) float64 {
+		if span == 0 {
+			return x0
+		}
+		return x0 + (x1-x0)*at.Sub(g.From).Hours()/span
+	}
+	y := func(lon float64) float64 { return y1 - (y1-y0)*math.Mod(math.Mod(lon, m)+m, m)/m }
+	fg := t.Foreground
+	line := h * 0.002
+	size := h * 0.025
+
+	// The longitude axis, with a line at each sign or, folded, at each
+	// division of the modulus.
+	step := map[float64]float64{360: 30, 90: 15, 45: 5}[m]
+	if step == 0 {
+		step = m / 6
+	}
+	for v := 0.0; v <= m+1e-9; v += step {
+		at := y1 - (y1-y0)*v/m
+		d.add(segment{a: vec{x0, at}, b: vec{x1, at}, width: line / 2, stroke: t.Grid})
+		n := fmt.Sprintf("%g°", v)
+		d.add(text{at: vec{x0 - size*1.4, at}, size: size * 0.8, glyph: n, label: n, fill: fg})
+	}
+	if m == 360 {
+		for i := 0; i < 12; i++ {
+			at := y1 - (y1-y0)*(float64(i)*30+15)/360
+			d.add(text{at: vec{x1 + size*4.2, at}, size: size, glyph: g.SignGlyphs[i], label: g.SignLabels[i], fill: fg})
+		}
+	}
+
+	// The time axis.
+	for _, tick := range dateTicks(g.From, g.To) {
+		at := x(tick.at)
+		d.add(segment{a: vec{at, y0}, b: vec{at, y1}, width: line / 2, stroke: t.Grid})
+		d.add(text{at: vec{at, y1 + size}, size: size * 0.8, glyph: tick.label, label: tick.label, fill: fg})
+	}
+	d.add(segment{a: vec{x0, y1}, b: vec{x1, y1}, width: line, stroke: fg})
+	d.add(segment{a: vec{x0, y0}, b: vec{x0, y1}, width: line, stroke: fg})
+
+	var natal []float64
+	for _, p := range g.Natal {
+		at := y(p.Longitude)
+		d.add(segment{a: vec{x0, at}, b: vec{x1, at}, width: line, stroke: fg})
+		natal = append(natal, at-size*0.6)
+	}
+	for i, at := range spreadY(natal, size*0.9, y1-size*0.6) {
+		p := g.Natal[i]
+		d.add(text{at: vec{x0 + size*0.2, at}, size: size * 0.8, glyph: p.Glyph, label: p.Label, fill: fg, left: true})
+	}
+
+	var ends []float64
+	for k, s := range g.Series {
+		col := t.Series[k%len(t.Series)]
+		n := len(s.Longitudes)
+		at := func(i int) vec {
+			if n == 1 {
+				return vec{x0, y(s.Longitudes[i])}
+			}
+			return vec{x0 + (x1-x0)*float64(i)/float64(n-1), y(s.Longitudes[i])}
+		}
+		for i := 1; i < n; i++ {
+			a, b := at(i-1), at(i)
+			// A body passing 0° of the modulus jumps from the top of the
+			// graph to the bottom or back; the line breaks there.
+			if math.Abs(b.y-a.y) > (y1-y0)/2 {
+				continue
+			}
+			d.add(segment{a: a, b: b, width: line * 1.5, stroke: col})
+		}
+		ends = append(ends, y1)
+		if n > 0 {
+			ends[k] = at(n - 1).y
+		}
+	}
+	for k, at := range spreadY(ends, size*1.1, y1) {
+		s := g.Series[k]
+		d.add(text{at: vec{x1 + size*1.2, at}, size: size, glyph: s.Glyph, label: s.Label, fill: t.Series[k%len(t.Series)]})
+	}
+	return d
+}
+
+// spreadY returns the heights at which to draw labels meant for heights
+// ys so that no two are closer than gap: crowded labels move down, and
+// back up where that would take them below bottom.
+func spreadY(ys []float64, gap, bottom float64) []float64 {
+	order := make([]int, len(ys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return ys[order[a]] < ys[order[b]] })
+	out := append([]float64(nil), ys...)
+	for k := 1; k < len(order); k++ {
+		out[order[k]] = math.Max(out[order[k]], out[order[k-1]]+gap)
+	}
+	for k := len(order) - 1; k >= 0; k-- {
+		limit := bottom
+		if k < len(order)-1 {
+			limit = out[order[k+1]] - gap
+		}
+		out[order[k]] = math.Min(out[order[k]], limit)
+	}
+	return out
+}
+
+// tick is a labelled time on the time axis.
+type tick struct {
+	at    time.Time
+	label string
+}
+
+// maxTicks bounds the labels on the time axis so that they do not
+// overlap.
+const maxTicks = 12
+
+// dateTicks returns evenly spaced round dates between from and to: days,
+// months or years, whichever gives a handful. Months are labelled by
+// name, with the year instead at January and at the first tick.
+func dateTicks(from, to time.Time) []tick {
+	days := to.Sub(from).Hours() / 24
+	var first time.Time
+	var next func(time.Time, int) time.Time
+	label := func(t time.Time, i int) string { return t.Format("2006") }
+	var n int
+	switch {
+	case days <= 3*maxTicks:
+		first = from.Truncate(24 * time.Hour)
+		next = func(t time.Time, k int) time.Time { return t.AddDate(0, 0, k) }
+		label = func(t time.Time, i int) string { return t.Format("Jan 2") }
+		n = int(math.Ceil(days / maxTicks))
+	case days <= 366*3:
+		first = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time, k int) time.Time { return t.AddDate(0, k, 0) }
+		label = func(t time.Time, i int) string {
+			if t.Month() == time.January || i == 0 {
+				return t.Format("Jan 2006")
+			}
+			return t.Format("Jan")
+		}
+		n = int(math.Ceil(days / 30.44 / maxTicks))
+	default:
+		first = time.Date(from.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time, k int) time.Time { return t.AddDate(k, 0, 0) }
+		n = int(math.Ceil(days / 365.25 / maxTicks))
+	}
+	n = max(n, 1)
+	var ticks []tick
+	for t := first; !t.After(to); t = next(t, n) {
+		if !t.Before(from) {
+			ticks = append(ticks, tick{t, label(t, len(ticks))})
+		}
+	}
+	return ticks
+}
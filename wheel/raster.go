@@ -0,0 +1,151 @@
+package wheel
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/dcccxiii/astro/angle"
+)
+
+// canvas is an image drawn at supersample times the final size, so that
+// averaging down to the final size smooths the edges.
+type canvas struct {
+	img         *image.RGBA
+	supersample float64
+}
+
+// supersampling returns how many samples per pixel edge to draw an image
+// whose longer side is size pixels with, fewer for large images to bound
+// memory.
+func supersampling(size int) int {
+	switch {
+	case size <= 1200:
+		return 3
+	case size <= 2400:
+		return 2
+	}
+	return 1
+}
+
+// PNG writes the drawing as a PNG image. Glyphs are drawn as their ASCII
+// labels in a built-in bitmap font, since the standard library has no
+// font rasterizer.
+func (d Drawing) PNG(w io.Writer) error {
+	ss := supersampling(max(d.Width, d.Height))
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, d.Width*ss, d.Height*ss)), supersample: float64(ss)}
+	for i := 0; i < len(c.img.Pix); i += 4 {
+		c.img.Pix[i], c.img.Pix[i+1], c.img.Pix[i+2], c.img.Pix[i+3] = d.Background.R, d.Background.G, d.Background.B, 0xff
+	}
+	for _, s := range d.shapes {
+		s.raster(c)
+	}
+	return png.Encode(w, downsample(c.img, ss))
+}
+
+// downsample averages each ss×ss block of img into one pixel.
+func downsample(img *image.RGBA, ss int) *image.RGBA {
+	if ss == 1 {
+		return img
+	}
+	w, h := img.Bounds().Dx()/ss, img.Bounds().Dy()/ss
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]int
+			for dy := 0; dy < ss; dy++ {
+				i := img.PixOffset(x*ss, y*ss+dy)
+				for dx := 0; dx < ss; dx++ {
+					for k := 0; k < 4; k++ {
+						sum[k] += int(img.Pix[i+dx*4+k])
+					}
+				}
+			}
+			o := out.PixOffset(x, y)
+			for k := 0; k < 4; k++ {
+				out.Pix[o+k] = uint8(sum[k] / (ss * ss))
+			}
+		}
+	}
+	return out
+}
+
+// fill paints every sample whose centre, in drawing coordinates, lies in
+// the box [x0, x1]×[y0, y1] and satisfies inside.
+func (c *canvas) fill(x0, y0, x1, y1 float64, col color.RGBA, inside func(x, y float64) bool) {
+	ss := c.supersample
+	b := c.img.Bounds()
+	ix0, iy0 := max(int(math.Floor(x0*ss)), b.Min.X), max(int(math.Floor(y0*ss)), b.Min.Y)
+	ix1, iy1 := min(int(math.Ceil(x1*ss)), b.Max.X-1), min(int(math.Ceil(y1*ss)), b.Max.Y-1)
+	for iy := iy0; iy <= iy1; iy++ {
+		for ix := ix0; ix <= ix1; ix++ {
+			if inside((float64(ix)+0.5)/ss, (float64(iy)+0.5)/ss) {
+				c.img.SetRGBA(ix, iy, col)
+			}
+		}
+	}
+}
+
+func (s circle) raster(c *canvas) {
+	h := s.width / 2
+	c.fill(s.c.x-s.r-h, s.c.y-s.r-h, s.c.x+s.r+h, s.c.y+s.r+h, s.stroke, func(x, y float64) bool {
+		return math.Abs(math.Hypot(x-s.c.x, y-s.c.y)-s.r) <= h
+	})
+}
+
+func (s segment) raster(c *canvas) {
+	h := s.width / 2
+	dx, dy := s.b.x-s.a.x, s.b.y-s.a.y
+	l2 := dx*dx + dy*dy
+	c.fill(math.Min(s.a.x, s.b.x)-h, math.Min(s.a.y, s.b.y)-h, math.Max(s.a.x, s.b.x)+h, math.Max(s.a.y, s.b.y)+h, s.stroke, func(x, y float64) bool {
+		t := 0.0
+		if l2 > 0 {
+			t = math.Max(0, math.Min(1, ((x-s.a.x)*dx+(y-s.a.y)*dy)/l2))
+		}
+		return math.Hypot(x-(s.a.x+t*dx), y-(s.a.y+t*dy)) <= h
+	})
+}
+
+func (s sector) raster(c *canvas) {
+	span := angle.Norm360(s.a1 - s.a0)
+	c.fill(s.c.x-s.r1, s.c.y-s.r1, s.c.x+s.r1, s.c.y+s.r1, s.fill, func(x, y float64) bool {
+		if r := math.Hypot(x-s.c.x, y-s.c.y); r < s.r0 || r > s.r1 {
+			return false
+		}
+		a := math.Atan2(s.c.y-y, x-s.c.x) * 180 / math.Pi
+		return angle.Norm360(a-s.a0) <= span
+	})
+}
+
+// raster draws the label in the bitmap font.
+func (s text) raster(c *canvas) {
+	label := strings.ToUpper(s.label)
+	cell := s.size / glyphHeight // the side of one font pixel
+	chars := []rune(label)
+	width := (float64(len(chars))*(glyphWidth+1) - 1) * cell
+	x0, y0 := s.at.x-width/2, s.at.y-s.size/2
+	if s.left {
+		x0 = s.at.x
+	}
+	for i, ch := range chars {
+		rows, ok := font[ch]
+		if !ok {
+			continue
+		}
+		left := x0 + float64(i)*(glyphWidth+1)*cell
+		for row, bits := range rows {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				px, py := left+float64(col)*cell, y0+float64(row)*cell
+				c.fill(px, py, px+cell, py+cell, s.fill, func(x, y float64) bool {
+					return x >= px && x < px+cell && y >= py && y < py+cell
+				})
+			}
+		}
+	}
+}
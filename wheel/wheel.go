@@ -0,0 +1,307 @@
+// Package wheel draws a chart as the traditional wheel: the zodiac around
+// the rim, the house cusps, the planets inside, and the major aspects
+// between them across the centre. Up to two further rings of planets, such
+// as transits or a progressed chart, can circle the inner chart to make a
+// biwheel or triwheel. Draw lays a chart out as a Drawing, which renders as
+// SVG or as a PNG image. DrawGraph lays out a graphic ephemeris, the
+// planets' longitudes against time, on the same kind of Drawing.
+package wheel
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dcccxiii/astro/angle"
+	"github.com/dcccxiii/astro/aspects"
+)
+
+// Point is a body placed on the wheel.
+type Point struct {
+	Glyph      string // drawn in SVG, e.g. "☉"
+	Label      string // short ASCII form drawn in PNG, e.g. "SU"
+	Longitude  float64
+	Retrograde bool
+}
+
+// Ring is a set of planets drawn around the inner chart.
+type Ring struct {
+	Name   string // for the legend, e.g. "Transits"
+	Points []Point
+}
+
+// MaxOuter is the number of outer rings Draw draws.
+const MaxOuter = 2
+
+// Chart is what the wheel shows.
+type Chart struct {
+	Name   string // the inner chart's name in the legend, shown when there are outer rings
+	Points []Point
+	// Outer are rings around the inner chart, from the inside out; Draw
+	// draws the first MaxOuter.
+	Outer     []Ring
+	Ascendant float64
+	MC        float64
+	// Cusps are the twelve house cusps, 1-12 in order; nil for a chart
+	// without houses, which is drawn with 0° Aries at the left.
+	Cusps      []float64
+	SignGlyphs [12]string // drawn in SVG
+	SignLabels [12]string // drawn in PNG
+}
+
+// Theme is the palette of a wheel.
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Foreground color.RGBA    // lines and text
+	Elements   [4]color.RGBA // sign backgrounds for fire, earth, air, water
+	Harmonious color.RGBA    // sextiles and trines
+	Tense      color.RGBA    // squares and oppositions
+	// Rings colours the planets of the inner chart and of each outer
+	// ring in turn.
+	Rings [1 + MaxOuter]color.RGBA
+	// Grid and Series colour a graph's grid lines and, in turn, its
+	// bodies.
+	Grid   color.RGBA
+	Series []color.RGBA
+}
+
+// The themes ParseTheme accepts.
+var (
+	Light = Theme{
+		Name:       "light",
+		Background: rgb(0xff, 0xff, 0xff),
+		Foreground: rgb(0x22, 0x22, 0x22),
+		Elements:   [4]color.RGBA{rgb(0xfb, 0xe3, 0xdc), rgb(0xe6, 0xf0, 0xdc), rgb(0xfc, 0xf6, 0xd8), rgb(0xdd, 0xe9, 0xf7)},
+		Harmonious: rgb(0x1f, 0x63, 0xc6),
+		Tense:      rgb(0xc8, 0x32, 0x2b),
+		Rings:      [1 + MaxOuter]color.RGBA{rgb(0x22, 0x22, 0x22), rgb(0x1b, 0x7f, 0x3b), rgb(0x8a, 0x3f, 0xb0)},
+		Grid:       rgb(0xe0, 0xe0, 0xe0),
+		Series: []color.RGBA{
+			rgb(0xd6, 0x8a, 0x00), rgb(0x5a, 0x6b, 0x7d), rgb(0x1b, 0x7f, 0x3b), rgb(0xc2, 0x3b, 0x8c), rgb(0xc8, 0x32, 0x2b),
+			rgb(0x1f, 0x63, 0xc6), rgb(0x5b, 0x45, 0x1f), rgb(0x00, 0x8b, 0x8b), rgb(0x3d, 0x2f, 0x9e), rgb(0x8a, 0x3f, 0xb0),
+		},
+	}
+	Dark = Theme{
+		Name:       "dark",
+		Background: rgb(0x16, 0x18, 0x1d),
+		Foreground: rgb(0xe4, 0xe4, 0xe4),
+		Elements:   [4]color.RGBA{rgb(0x4a, 0x27, 0x22), rgb(0x2a, 0x3d, 0x26), rgb(0x45, 0x40, 0x22), rgb(0x22, 0x33, 0x4a)},
+		Harmonious: rgb(0x6c, 0xa6, 0xff),
+		Tense:      rgb(0xff, 0x6b, 0x5e),
+		Rings:      [1 + MaxOuter]color.RGBA{rgb(0xe4, 0xe4, 0xe4), rgb(0x5f, 0xd3, 0x8a), rgb(0xc7, 0x92, 0xea)},
+		Grid:       rgb(0x33, 0x36, 0x3d),
+		Series: []color.RGBA{
+			rgb(0xff, 0xc1, 0x4d), rgb(0xb8, 0xc4, 0xd1), rgb(0x5f, 0xd3, 0x8a), rgb(0xf2, 0x7f, 0xc4), rgb(0xff, 0x6b, 0x5e),
+			rgb(0x6c, 0xa6, 0xff), rgb(0xd9, 0xa8, 0x6c), rgb(0x4d, 0xd9, 0xd9), rgb(0x9d, 0x8f, 0xff), rgb(0xc7, 0x92, 0xea),
+		},
+	}
+)
+
+// ParseTheme returns the named theme.
+func ParseTheme(name string) (Theme, error) {
+	for _, t := range []Theme{Light, Dark} {
+		if strings.EqualFold(name, t.Name) {
+			return t, nil
+		}
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q: valid values are light, dark", name)
+}
+
+func rgb(r, g, b uint8) color.RGBA { return color.RGBA{r, g, b, 0xff} }
+
+// Radii of the rings, as fractions of the wheel's radius. The inner
+// chart's rings are measured inwards from the innermost outer ring, or
+// from the zodiac if there is none.
+const (
+	signInner   = 0.84 // the zodiac band runs from here to the rim
+	outerBand   = 0.10 // the width of each outer ring
+	planetRing  = 0.12
+	degreeRing  = 0.22
+	houseNumber = 0.32
+	aspectRing  = 0.38 // aspect lines end on this circle
+)
+
+// minSeparation is the least angle, in degrees, between two planet glyphs
+// on a ring of radius 0.72; closer planets are spread apart, with a tick
+// marking the true position. Smaller rings spread their planets further.
+const minSeparation = 9
+
+// Draw lays out chart c on a square of size pixels.
+func Draw(c Chart, size int, t Theme) Drawing {
+	d := Drawing{Width: size, Height: size, Background: t.Background}
+	s := float64(size)
+	g := geometry{cx: s / 2, cy: s / 2, r: s / 2 * 0.96, asc: c.Ascendant}
+	if c.Cusps == nil {
+		g.asc = 0
+	}
+	fg := t.Foreground
+	line := g.r * 0.004
+
+	for i := 0; i < 12; i++ {
+		from := float64(i) * 30
+		d.add(sector{c: g.at(0, 0), r0: g.r * signInner, r1: g.r, a0: g.angle(from), a1: g.angle(from + 30), fill: t.Elements[i%4]})
+		d.add(segment{a: g.at(from, signInner), b: g.at(from, 1), width: line, stroke: fg})
+		d.add(text{at: g.at(from+15, (1+signInner)/2), size: g.r * 0.06, glyph: c.SignGlyphs[i], label: c.SignLabels[i], fill: fg})
+	}
+	d.add(circle{c: g.at(0, 0), r: g.r, width: line * 1.5, stroke: fg})
+	d.add(circle{c: g.at(0, 0), r: g.r * signInner, width: line, stroke: fg})
+
+	outer := c.Outer[:min(len(c.Outer), MaxOuter)]
+	edge := signInner
+	for k, ring := range outer {
+		col := t.Rings[k+1]
+		shown := spread(ring.Points, minSeparation*0.72/(edge-outerBand/2))
+		for i, p := range ring.Points {
+			d.add(segment{a: g.at(p.Longitude, edge), b: g.at(p.Longitude, edge-0.02), width: line * 1.5, stroke: col})
+			d.add(text{at: g.at(shown[i], edge-outerBand/2), size: g.r * 0.05, glyph: p.Glyph, label: p.Label, fill: col})
+		}
+		edge -= outerBand
+		d.add(circle{c: g.at(0, 0), r: g.r * edge, width: line, stroke: fg})
+	}
+	hub := edge - aspectRing
+	d.add(circle{c: g.at(0, 0), r: g.r * hub, width: line, stroke: fg})
+
+	if c.Cusps != nil {
+		for i, cusp := range c.Cusps {
+			w := line
+			if i%3 == 0 { // the angles
+				w = line * 2.5
+			}
+			d.add(segment{a: g.at(cusp, hub), b: g.at(cusp, signInner), width: w, stroke: fg})
+			next := c.Cusps[(i+1)%12]
+			mid := cusp + angle.Norm360(next-cusp)/2
+			n := fmt.Sprint(i + 1)
+			d.add(text{at: g.at(mid, edge-houseNumber), size: g.r * 0.04, glyph: n, label: n, fill: fg})
+		}
+	}
+
+	col := t.Rings[0]
+	scale := edge / signInner // the inner chart shrinks to make room for outer rings
+	shown := spread(c.Points, minSeparation*0.72/(edge-planetRing))
+	for i, p := range c.Points {
+		d.add(segment{a: g.at(p.Longitude, edge), b: g.at(p.Longitude, edge-0.04), width: line * 1.5, stroke: col})
+		d.add(text{at: g.at(shown[i], edge-planetRing), size: g.r * 0.065 * scale, glyph: p.Glyph, label: p.Label, fill: col})
+		deg := fmt.Sprintf("%d°", int(math.Mod(p.Longitude, 30)))
+		if p.Retrograde {
+			deg += "R"
+		}
+		d.add(text{at: g.at(shown[i], edge-degreeRing), size: g.r * 0.035 * scale, glyph: deg, label: deg, fill: col})
+	}
+
+	// The aspects are those within the inner chart, or, with outer rings,
+	// those between it and the outermost ring.
+	others := c.Points
+	if len(outer) > 0 {
+		others = outer[len(outer)-1].Points
+	}
+	for i, p := range c.Points {
+		for j, q := range others {
+			if len(outer) == 0 && j <= i {
+				continue
+			}
+			a, _, ok := aspects.Between(p.Longitude, q.Longitude, aspects.Major)
+			if !ok || a.Angle == 0 {
+				continue
+			}
+			stroke := t.Harmonious
+			if a.Angle == 90 || a.Angle == 180 {
+				stroke = t.Tense
+			}
+			d.add(segment{a: g.at(p.Longitude, hub), b: g.at(q.Longitude, hub), width: line, stroke: stroke})
+		}
+	}
+
+	if len(outer) > 0 {
+		size := g.r * 0.035
+		legend := append([]Ring{{Name: c.Name}}, outer...)
+		for k, ring := range legend {
+			at := vec{s * 0.02, s*0.02 + size*1.6*float64(k) + size/2}
+			d.add(text{at: at, size: size, glyph: ring.Name, label: ring.Name, fill: t.Rings[k], left: true})
+		}
+	}
+	return d
+}
+
+// geometry places ecliptic longitudes on the wheel, with the Ascendant at
+// the left and the signs running anticlockwise.
+type geometry struct {
+	cx, cy, r float64
+	asc       float64
+}
+
+// angle returns the screen angle of longitude lon, in degrees
+// anticlockwise from the right.
+func (g geometry) angle(lon float64) float64 { return 180 + lon - g.asc }
+
+// at returns the point at longitude lon and radius frac of the wheel's.
+func (g geometry) at(lon, frac float64) vec {
+	a := g.angle(lon) * math.Pi / 180
+	return vec{g.cx + g.r*frac*math.Cos(a), g.cy - g.r*frac*math.Sin(a)}
+}
+
+// spread returns the longitudes at which to draw points so that no two
+// are closer than sep degrees. Crowded points are gathered into clusters,
+// each spaced sep apart and centred on its members' mean longitude.
+func spread(points []Point, sep float64) []float64 {
+	n := len(points)
+	shown := make([]float64, n)
+	for i, p := range points {
+		shown[i] = p.Longitude
+	}
+	if n < 2 || float64(n)*sep > 360 {
+		return shown
+	}
+
+	// Unwrap the longitudes into increasing order, starting after the
+	// widest gap so that no cluster straddles the cut.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return angle.Norm360(shown[order[a]]) < angle.Norm360(shown[order[b]]) })
+	start, widest := 0, -1.0
+	for k := range order {
+		prev := angle.Norm360(shown[order[(k+n-1)%n]])
+		if gap := angle.Norm360(angle.Norm360(shown[order[k]]) - prev); gap > widest {
+			start, widest = k, gap
+		}
+	}
+	xs := make([]float64, n)
+	for k := range xs {
+		xs[k] = angle.Norm360(shown[order[(start+k)%n]])
+		if k > 0 && xs[k] < xs[k-1] {
+			xs[k] += 360
+		}
+	}
+
+	type cluster struct {
+		first, count int
+		centre       float64
+	}
+	var cs []cluster
+	for k, x := range xs {
+		cs = append(cs, cluster{k, 1, x})
+		// Merge with the previous cluster while the two overlap.
+		for len(cs) > 1 {
+			a, b := cs[len(cs)-2], cs[len(cs)-1]
+			if (b.centre-float64(b.count-1)*sep/2)-(a.centre+float64(a.count-1)*sep/2) >= sep {
+				break
+			}
+			m := cluster{a.first, a.count + b.count, 0}
+			for _, x := range xs[m.first : m.first+m.count] {
+				m.centre += x
+			}
+			m.centre /= float64(m.count)
+			cs = append(cs[:len(cs)-2], m)
+		}
+	}
+	for _, c := range cs {
+		for k := 0; k < c.count; k++ {
+			shown[order[(start+c.first+k)%n]] = angle.Norm360(c.centre + (float64(k)-float64(c.count-1)/2)*sep)
+		}
+	}
+	return shown
+}
@@ -0,0 +1,157 @@
+package wheel
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testChart() Chart {
+	c := Chart{
+		Points: []Point{
+			{Glyph: "☉", Label: "SU", Longitude: 10},
+			{Glyph: "☽", Label: "MO", Longitude: 12},
+			{Glyph: "♂", Label: "MA", Longitude: 100, Retrograde: true},
+		},
+		Ascendant: 95,
+		MC:        5,
+	}
+	for i := 0; i < 12; i++ {
+		c.Cusps = append(c.Cusps, math.Mod(95+float64(i)*30, 360))
+		c.SignGlyphs[i] = string(rune('♈' + i))
+		c.SignLabels[i] = "S" + string(rune('A'+i))
+	}
+	return c
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Draw(testChart(), 400, Light).SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`<svg xmlns="http://www.w3.org/2000/svg" width="400"`, ">☉<", ">♈<", ">10°R<", `stroke="#c8322b"`, "</svg>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %q", want)
+		}
+	}
+}
+
+func TestSVG_Rings(t *testing.T) {
+	c := testChart()
+	c.Name = "Natal"
+	c.Outer = []Ring{{Name: "Transits", Points: []Point{{Glyph: "♄", Label: "SA", Longitude: 190}}}}
+	var buf bytes.Buffer
+	if err := Draw(c, 400, Light).SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`fill="#1b7f3b">♄<`, `text-anchor="start" dominant-baseline="central" fill="#1b7f3b">Transits<`, ">Natal<"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %q", want)
+		}
+	}
+	// Saturn opposes the Sun and Moon and squares Mars; the Sun–Mars
+	// square within the inner chart is not drawn.
+	if n := strings.Count(svg, `stroke="#c8322b"`); n != 3 {
+		t.Errorf("%d tense aspect lines, want 3", n)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Draw(testChart(), 300, Dark).PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Fatalf("size %v, want 300×300", b)
+	}
+	if r, g, b, _ := img.At(2, 2).RGBA(); r>>8 != 0x16 || g>>8 != 0x18 || b>>8 != 0x1d {
+		t.Errorf("corner = %x %x %x, want the dark background", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	points := []Point{{Longitude: 358}, {Longitude: 1}, {Longitude: 3}, {Longitude: 90}}
+	shown := spread(points, minSeparation)
+	for i := range shown {
+		for j := i + 1; j < len(shown); j++ {
+			d := math.Abs(math.Mod(shown[i]-shown[j]+540, 360) - 180)
+			if d < minSeparation-1e-6 {
+				t.Errorf("points %d and %d drawn %.2f° apart", i, j, d)
+			}
+		}
+	}
+	if shown[3] != 90 {
+		t.Errorf("an uncrowded point moved to %v", shown[3])
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	if th, err := ParseTheme("Dark"); err != nil || th.Name != "dark" {
+		t.Errorf("ParseTheme(Dark) = %v, %v", th.Name, err)
+	}
+	if _, err := ParseTheme("sepia"); err == nil {
+		t.Error("ParseTheme(sepia): expected error")
+	}
+}
+
+func TestDrawGraph(t *testing.T) {
+	g := Graph{
+		From:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		Modulus: 90,
+		Series: []Series{
+			// Crosses 0° of the modulus between the second and third samples.
+			{Glyph: "☽", Label: "MO", Longitudes: []float64{60, 75, 90 + 5, 90 + 20, 90 + 35}},
+		},
+		Natal: []Point{{Glyph: "♄", Label: "SA", Longitude: 200}},
+	}
+	var buf bytes.Buffer
+	if err := DrawGraph(g, 600, 300, Light).SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`width="600" height="300"`, ">☽<", ">♄<", ">90°<", ">Jan 3<"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %q", want)
+		}
+	}
+	// Four steps, less the one across 0°.
+	if n := strings.Count(svg, `stroke="#d68a00"`); n != 3 {
+		t.Errorf("%d segments of the Moon's line, want 3", n)
+	}
+
+	buf.Reset()
+	if err := DrawGraph(g, 600, 300, Dark).PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 600 || b.Dy() != 300 {
+		t.Errorf("size %v, want 600×300", b)
+	}
+}
+
+func TestDateTicks(t *testing.T) {
+	from := time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC)
+	var labels []string
+	for _, tk := range dateTicks(from, from.AddDate(0, 4, 0)) {
+		labels = append(labels, tk.label)
+	}
+	if got, want := strings.Join(labels, ","), "Dec 2024,Jan 2025,Feb,Mar"; got != want {
+		t.Errorf("ticks = %s, want %s", got, want)
+	}
+	if n := len(dateTicks(from, from.AddDate(100, 0, 0))); n > maxTicks+1 {
+		t.Errorf("%d ticks over a century, want at most %d", n, maxTicks+1)
+	}
+}
@@ -0,0 +1,38 @@
+// Package zodiac converts ecliptic longitudes to positions in the tropical
+// zodiac. It is pure Go so that chart code can use it without pulling in
+// the cgo bindings.
+package zodiac
+
+import "github.com/dcccxiii/astro/angle"
+
+// Signs lists the twelve zodiac signs in order, starting from Aries at 0°.
+var Signs = [12]string{
+	"Aries", "Taurus", "Gemini", "Cancer",
+	"Leo", "Virgo", "Libra", "Scorpio",
+	"Sagittarius", "Capricorn", "Aquarius", "Pisces",
+}
+
+// Sign returns the zodiac sign name and degree within that sign for a given
+// ecliptic longitude. The input is normalised to [0, 360) first, so values
+// outside that range (including negative values) are handled correctly.
+func Sign(longitude float64) (sign string, degrees float64) {
+	idx := index(longitude)
+	return Signs[idx], angle.Norm360(longitude) - float64(idx)*30.0
+}
+
+// Elements lists the four elements; sign i belongs to Elements[i%4].
+var Elements = [4]string{"fire", "earth", "air", "water"}
+
+// Modalities lists the three modalities; sign i belongs to Modalities[i%3].
+var Modalities = [3]string{"cardinal", "fixed", "mutable"}
+
+// Element returns the element of the sign containing longitude.
+func Element(longitude float64) string { return Elements[index(longitude)%4] }
+
+// Modality returns the modality of the sign containing longitude.
+func Modality(longitude float64) string { return Modalities[index(longitude)%3] }
+
+// index returns the index in Signs of the sign containing longitude.
+func index(longitude float64) int {
+	return min(int(angle.Norm360(longitude)/30.0), 11)
+}